@@ -25,6 +25,7 @@ import (
 	"github.com/grafana/mimir/pkg/alertmanager"
 	"github.com/grafana/mimir/pkg/alertmanager/alertmanagerpb"
 	"github.com/grafana/mimir/pkg/compactor"
+	"github.com/grafana/mimir/pkg/compactor/compactorschedulerpb"
 	"github.com/grafana/mimir/pkg/distributor"
 	"github.com/grafana/mimir/pkg/distributor/distributorpb"
 	frontendv1 "github.com/grafana/mimir/pkg/frontend/v1"
@@ -229,6 +230,8 @@ func (a *API) RegisterRuntimeConfig(runtimeConfigHandler http.HandlerFunc, userL
 
 const PrometheusPushEndpoint = "/api/v1/push"
 const OTLPPushEndpoint = "/otlp/v1/metrics"
+const InfluxPushEndpoint = "/api/v1/push/influx/write"
+const GraphitePushEndpoint = "/api/v1/push/graphite"
 
 // RegisterDistributor registers the endpoints associated with the distributor.
 func (a *API) RegisterDistributor(d *distributor.Distributor, pushConfig distributor.Config, reg prometheus.Registerer, limits *validation.Overrides) {
@@ -236,6 +239,8 @@ func (a *API) RegisterDistributor(d *distributor.Distributor, pushConfig distrib
 
 	a.RegisterRoute(PrometheusPushEndpoint, distributor.Handler(pushConfig.MaxRecvMsgSize, a.sourceIPs, a.cfg.SkipLabelNameValidationHeader, limits, d.PushWithMiddlewares), true, false, "POST")
 	a.RegisterRoute(OTLPPushEndpoint, distributor.OTLPHandler(pushConfig.MaxRecvMsgSize, a.sourceIPs, a.cfg.SkipLabelNameValidationHeader, a.cfg.EnableOtelMetadataStorage, limits, reg, d.PushWithMiddlewares), true, false, "POST")
+	a.RegisterRoute(InfluxPushEndpoint, distributor.InfluxHandler(pushConfig.MaxRecvMsgSize, a.sourceIPs, limits, reg, d.PushWithMiddlewares), true, false, "POST")
+	a.RegisterRoute(GraphitePushEndpoint, distributor.GraphiteHandler(pushConfig.MaxRecvMsgSize, a.sourceIPs, limits, reg, d.PushWithMiddlewares), true, false, "POST")
 
 	a.indexPage.AddLinks(defaultWeight, "Distributor", []IndexPageLink{
 		{Desc: "Ring status", Path: "/distributor/ring"},
@@ -246,6 +251,7 @@ func (a *API) RegisterDistributor(d *distributor.Distributor, pushConfig distrib
 	a.RegisterRoute("/distributor/ring", d, false, true, "GET", "POST")
 	a.RegisterRoute("/distributor/all_user_stats", http.HandlerFunc(d.AllUserStatsHandler), false, true, "GET")
 	a.RegisterRoute("/distributor/ha_tracker", d.HATracker, false, true, "GET")
+	a.RegisterRoute("/distributor/ha_tracker/elect", http.HandlerFunc(d.HATracker.ForceElectHandler), false, true, "POST")
 }
 
 // Ingester is defined as an interface to allow for alternative implementations
@@ -259,6 +265,7 @@ type Ingester interface {
 	UserRegistryHandler(http.ResponseWriter, *http.Request)
 	TenantsHandler(http.ResponseWriter, *http.Request)
 	TenantTSDBHandler(http.ResponseWriter, *http.Request)
+	TenantSeriesChurnHandler(http.ResponseWriter, *http.Request)
 }
 
 // RegisterIngester registers the ingester HTTP and gRPC services.
@@ -281,6 +288,7 @@ func (a *API) RegisterIngester(i Ingester) {
 
 	a.RegisterRoute("/ingester/tenants", http.HandlerFunc(i.TenantsHandler), false, true, "GET")
 	a.RegisterRoute("/ingester/tsdb/{tenant}", http.HandlerFunc(i.TenantTSDBHandler), false, true, "GET")
+	a.RegisterRoute("/ingester/tsdb/{tenant}/churn", http.HandlerFunc(i.TenantSeriesChurnHandler), false, true, "GET")
 }
 
 // RegisterRuler registers routes associated with the Ruler service.
@@ -353,6 +361,10 @@ func (a *API) RegisterCompactor(c *compactor.MultitenantCompactor) {
 	a.RegisterRoute("/api/v1/upload/block/{block}/check", http.HandlerFunc(c.GetBlockUploadStateHandler), true, false, http.MethodGet)
 	a.RegisterRoute("/compactor/delete_tenant", http.HandlerFunc(c.DeleteTenant), true, true, "POST")
 	a.RegisterRoute("/compactor/delete_tenant_status", http.HandlerFunc(c.DeleteTenantStatus), true, true, "GET")
+	a.RegisterRoute("/compactor/delete_series", http.HandlerFunc(c.CreateSeriesDeletionRequest), true, true, "POST")
+	a.RegisterRoute("/compactor/delete_series", http.HandlerFunc(c.ListSeriesDeletionRequests), true, true, "GET")
+	a.RegisterRoute("/compactor/delete_series", http.HandlerFunc(c.CancelSeriesDeletionRequest), true, true, "DELETE")
+	a.RegisterRoute("/compactor/planned_jobs", http.HandlerFunc(c.PlannedJobs), true, true, "GET")
 }
 
 func (a *API) DisableServerHTTPTimeouts(next http.Handler) http.Handler {
@@ -380,6 +392,15 @@ type Distributor interface {
 
 // RegisterQueryable registers the default routes associated with the querier
 // module.
+func (a *API) RegisterCompactorScheduler(s *compactor.Scheduler) {
+	a.indexPage.AddLinks(defaultWeight, "Compactor-scheduler", []IndexPageLink{
+		{Desc: "Compaction jobs", Path: "/compactor-scheduler/jobs"},
+	})
+	a.RegisterRoute("/compactor-scheduler/jobs", http.HandlerFunc(s.JobsHandler), false, true, "GET")
+
+	compactorschedulerpb.RegisterCompactorSchedulerServer(a.server.GRPC, s)
+}
+
 func (a *API) RegisterQueryable(distributor Distributor) {
 	// these routes are always registered to the default server
 	a.RegisterRoute("/api/v1/user_stats", http.HandlerFunc(distributor.UserStatsHandler), true, true, "GET")
@@ -399,14 +420,16 @@ func (a *API) RegisterQueryAPI(handler http.Handler, buildInfoHandler http.Handl
 	a.RegisterRoute(path.Join(a.cfg.PrometheusHTTPPrefix, "/api/v1/cardinality/label_names"), handler, true, true, "GET", "POST")
 	a.RegisterRoute(path.Join(a.cfg.PrometheusHTTPPrefix, "/api/v1/cardinality/label_values"), handler, true, true, "GET", "POST")
 	a.RegisterRoute(path.Join(a.cfg.PrometheusHTTPPrefix, "/api/v1/cardinality/active_series"), handler, true, true, "GET", "POST")
+	a.RegisterRoute(path.Join(a.cfg.PrometheusHTTPPrefix, "/api/v1/cardinality/blocks"), handler, true, true, "GET", "POST")
 	a.RegisterRoute(path.Join(a.cfg.PrometheusHTTPPrefix, "/api/v1/format_query"), handler, true, true, "GET", "POST")
 }
 
 // RegisterQueryFrontendHandler registers the Prometheus routes supported by the
 // Mimir querier service. Currently, this can not be registered simultaneously
 // with the Querier.
-func (a *API) RegisterQueryFrontendHandler(h http.Handler, buildInfoHandler http.Handler) {
+func (a *API) RegisterQueryFrontendHandler(h http.Handler, buildInfoHandler http.Handler, activeQueriesHandler http.Handler) {
 	a.RegisterQueryAPI(h, buildInfoHandler)
+	a.RegisterRoute(path.Join(a.cfg.PrometheusHTTPPrefix, "/api/v1/status/active_queries"), activeQueriesHandler, true, true, "GET", "DELETE")
 }
 
 func (a *API) RegisterQueryFrontend1(f *frontendv1.Frontend) {
@@ -3,7 +3,12 @@
 package api
 
 import (
+	"encoding/binary"
 	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
 
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/promql"
@@ -29,23 +34,11 @@ func (c protobufCodec) CanEncode(resp *v1.Response) bool {
 }
 
 func (c protobufCodec) Encode(resp *v1.Response) ([]byte, error) {
-	status, err := mimirpb.StatusFromPrometheusString(string(resp.Status))
-	if err != nil {
-		return nil, err
-	}
-
-	errorType, err := mimirpb.ErrorTypeFromPrometheusString(string(resp.ErrorType))
+	p, err := c.encodeResponse(resp)
 	if err != nil {
 		return nil, err
 	}
 
-	p := mimirpb.QueryResponse{
-		Status:    status,
-		ErrorType: errorType,
-		Error:     resp.Error,
-		Warnings:  resp.Warnings,
-	}
-
 	if resp.Data != nil {
 		data := resp.Data.(*v1.QueryData)
 
@@ -74,6 +67,26 @@ func (c protobufCodec) Encode(resp *v1.Response) ([]byte, error) {
 	return p.Marshal()
 }
 
+// encodeResponse returns the QueryResponse with the status, error and warnings of resp, but without its data.
+func (c protobufCodec) encodeResponse(resp *v1.Response) (mimirpb.QueryResponse, error) {
+	status, err := mimirpb.StatusFromPrometheusString(string(resp.Status))
+	if err != nil {
+		return mimirpb.QueryResponse{}, err
+	}
+
+	errorType, err := mimirpb.ErrorTypeFromPrometheusString(string(resp.ErrorType))
+	if err != nil {
+		return mimirpb.QueryResponse{}, err
+	}
+
+	return mimirpb.QueryResponse{
+		Status:    status,
+		ErrorType: errorType,
+		Error:     resp.Error,
+		Warnings:  resp.Warnings,
+	}, nil
+}
+
 func (c protobufCodec) encodeString(s promql.String) mimirpb.StringData {
 	return mimirpb.StringData{
 		TimestampMs: s.T,
@@ -172,3 +185,174 @@ func labelsToStringArray(l labels.Labels) []string {
 
 	return strings
 }
+
+// streamingProtobufBatchSize is the target size of the batches of series encoded by streamingProtobufCodec.
+const streamingProtobufBatchSize = 1024 * 1024
+
+// streamedMatrixPlaceholderPrefix is the prefix of the placeholder returned by streamingProtobufCodec.Encode
+// for matrices, followed by the ID of the matrix response. A protobuf query response stream can't start with it,
+// because it would be an empty message followed by garbage.
+const streamedMatrixPlaceholderPrefix = "\x00mimir-streamed-matrix-"
+
+// streamingProtobufCodec encodes query responses as a sequence of length-delimited QueryResponse messages
+// (see mimirpb.QueryResponseStreamMimeType). Matrices are encoded in batches of series, each written to the
+// response on its own so that it can be sent back to the query-frontend while the next batch is encoded,
+// while any other response is encoded as a single message.
+//
+// Since Encode can only return the whole encoded response, it returns a placeholder for matrices, which the
+// handler returned by Wrap replaces with the batches of series when it's written to the response. The codec
+// must therefore only be installed in APIs whose handler is wrapped.
+type streamingProtobufCodec struct {
+	protobufCodec
+
+	matrices *streamedMatrices
+}
+
+func newStreamingProtobufCodec() streamingProtobufCodec {
+	return streamingProtobufCodec{
+		matrices: &streamedMatrices{responses: map[uint64]streamedMatrix{}},
+	}
+}
+
+func (c streamingProtobufCodec) ContentType() v1.MIMEType {
+	return v1.MIMEType{Type: mimirpb.QueryResponseMimeTypeType, SubType: mimirpb.QueryResponseStreamMimeTypeSubType}
+}
+
+func (c streamingProtobufCodec) Encode(resp *v1.Response) ([]byte, error) {
+	data, ok := resp.Data.(*v1.QueryData)
+	if !ok || data.ResultType != parser.ValueTypeMatrix {
+		b, err := c.protobufCodec.Encode(resp)
+		if err != nil {
+			return nil, err
+		}
+		buf := binary.AppendUvarint(make([]byte, 0, len(b)+binary.MaxVarintLen64), uint64(len(b)))
+		return append(buf, b...), nil
+	}
+
+	p, err := c.encodeResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.matrices.add(streamedMatrix{response: p, matrix: data.Result.(promql.Matrix)}), nil
+}
+
+// Wrap returns a handler writing the matrices encoded by the codec to the response in batches of series.
+func (c streamingProtobufCodec) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(&streamingProtobufResponseWriter{ResponseWriter: w, codec: c}, r)
+	})
+}
+
+// writeMatrix encodes the matrix in batches of series, and writes each batch to w with a single call to Write.
+func (c streamingProtobufCodec) writeMatrix(w io.Writer, m streamedMatrix) error {
+	var (
+		p         = m.response
+		buf       []byte
+		batch     []mimirpb.MatrixSeries
+		batchSize int
+		written   bool
+	)
+
+	flush := func() error {
+		p.Data = &mimirpb.QueryResponse_Matrix{Matrix: &mimirpb.MatrixData{Series: batch}}
+
+		size := p.Size()
+		buf = binary.AppendUvarint(buf[:0], uint64(size))
+		buf = slices.Grow(buf, size)
+		if _, err := p.MarshalToSizedBuffer(buf[len(buf) : len(buf)+size]); err != nil {
+			return err
+		}
+		if _, err := w.Write(buf[:len(buf)+size]); err != nil {
+			return err
+		}
+
+		// Warnings are only sent with the first batch.
+		p.Warnings = nil
+		batch = batch[:0]
+		batchSize = 0
+		written = true
+		return nil
+	}
+
+	for _, s := range m.matrix {
+		series := c.encodeMatrixSeries(s)
+		batch = append(batch, series)
+		batchSize += series.Size()
+
+		if batchSize >= streamingProtobufBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Always encode at least one message, so that the response status and warnings are sent.
+	if len(batch) > 0 || !written {
+		return flush()
+	}
+	return nil
+}
+
+// streamedMatrix is a matrix response encoded by streamingProtobufCodec, waiting to be written.
+type streamedMatrix struct {
+	// response is the response without its data.
+	response mimirpb.QueryResponse
+	matrix   promql.Matrix
+}
+
+// streamedMatrices are the matrix responses encoded by streamingProtobufCodec, by ID.
+type streamedMatrices struct {
+	mtx       sync.Mutex
+	nextID    uint64
+	responses map[uint64]streamedMatrix
+}
+
+// add adds the matrix response and returns its placeholder.
+func (m *streamedMatrices) add(resp streamedMatrix) []byte {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.nextID++
+	m.responses[m.nextID] = resp
+	return binary.BigEndian.AppendUint64([]byte(streamedMatrixPlaceholderPrefix), m.nextID)
+}
+
+// take removes and returns the matrix response of the placeholder, if b is one.
+func (m *streamedMatrices) take(b []byte) (streamedMatrix, bool) {
+	if len(b) != len(streamedMatrixPlaceholderPrefix)+8 || string(b[:len(streamedMatrixPlaceholderPrefix)]) != streamedMatrixPlaceholderPrefix {
+		return streamedMatrix{}, false
+	}
+	id := binary.BigEndian.Uint64(b[len(streamedMatrixPlaceholderPrefix):])
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	resp, ok := m.responses[id]
+	delete(m.responses, id)
+	return resp, ok
+}
+
+// streamingProtobufResponseWriter is a http.ResponseWriter replacing the placeholders of the matrices encoded
+// by streamingProtobufCodec with their batches of series, so that the encoded matrix is never buffered whole.
+type streamingProtobufResponseWriter struct {
+	http.ResponseWriter
+
+	codec streamingProtobufCodec
+}
+
+func (w *streamingProtobufResponseWriter) Write(p []byte) (int, error) {
+	if w.Header().Get("Content-Type") != mimirpb.QueryResponseStreamMimeType {
+		return w.ResponseWriter.Write(p)
+	}
+
+	m, ok := w.codec.matrices.take(p)
+	if !ok {
+		return w.ResponseWriter.Write(p)
+	}
+
+	if err := w.codec.writeMatrix(w.ResponseWriter, m); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
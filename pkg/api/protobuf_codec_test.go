@@ -3,6 +3,11 @@
 package api
 
 import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/prometheus/prometheus/model/histogram"
@@ -896,6 +901,124 @@ func TestProtobufCodec_Encode(t *testing.T) {
 	}
 }
 
+func TestStreamingProtobufCodec_Encode(t *testing.T) {
+	codec := newStreamingProtobufCodec()
+
+	// encode encodes the response and writes it like the Prometheus API does, returning the bytes of each Write
+	// to the underlying response writer.
+	encode := func(t *testing.T, resp *v1.Response) [][]byte {
+		handler := codec.Wrap(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			b, err := codec.Encode(resp)
+			require.NoError(t, err)
+
+			w.Header().Set("Content-Type", codec.ContentType().String())
+			w.WriteHeader(http.StatusOK)
+			_, err = w.Write(b)
+			require.NoError(t, err)
+		}))
+
+		w := &writesRecorder{ResponseRecorder: httptest.NewRecorder()}
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		require.Empty(t, codec.matrices.responses)
+		return w.writes
+	}
+
+	decode := func(t *testing.T, b []byte) []mimirpb.QueryResponse {
+		var messages []mimirpb.QueryResponse
+		for len(b) > 0 {
+			size, n := binary.Uvarint(b)
+			require.Greater(t, n, 0)
+			b = b[n:]
+
+			m := mimirpb.QueryResponse{}
+			require.NoError(t, m.Unmarshal(b[:size]))
+			messages = append(messages, m)
+			b = b[size:]
+		}
+		return messages
+	}
+
+	for name, scenario := range protobufCodecScenarios {
+		t.Run(name, func(t *testing.T) {
+			writes := encode(t, scenario.response)
+			require.Len(t, writes, 1)
+
+			messages := decode(t, writes[0])
+			require.Len(t, messages, 1)
+			require.Equal(t, scenario.expectedPayload, messages[0])
+		})
+	}
+
+	t.Run("large matrix should be written one batch of series at a time", func(t *testing.T) {
+		matrix := promql.Matrix{}
+		for i := 0; i < 10000; i++ {
+			s := promql.Series{Metric: labels.FromStrings("__name__", fmt.Sprintf("series_%d", i))}
+			for ts := int64(0); ts < 100; ts++ {
+				s.Floats = append(s.Floats, promql.FPoint{T: ts, F: float64(ts)})
+			}
+			matrix = append(matrix, s)
+		}
+
+		writes := encode(t, &v1.Response{
+			Status:   "success",
+			Data:     &v1.QueryData{ResultType: parser.ValueTypeMatrix, Result: matrix},
+			Warnings: []string{"some warning"},
+		})
+		require.Greater(t, len(writes), 1)
+
+		var series []mimirpb.MatrixSeries
+		for i, w := range writes {
+			// Each write is a single batch, so the whole encoded matrix is never buffered.
+			require.Less(t, len(w), 2*streamingProtobufBatchSize)
+
+			messages := decode(t, w)
+			require.Len(t, messages, 1)
+
+			m := messages[0]
+			require.Equal(t, mimirpb.QueryResponse_SUCCESS, m.Status)
+			if i == 0 {
+				require.Equal(t, []string{"some warning"}, m.Warnings)
+			} else {
+				require.Empty(t, m.Warnings)
+			}
+
+			require.NotNil(t, m.GetMatrix())
+			series = append(series, m.GetMatrix().Series...)
+		}
+
+		require.Len(t, series, len(matrix))
+		for i, s := range series {
+			require.Equal(t, labelsToStringArray(matrix[i].Metric), s.Metric)
+			require.Len(t, s.Samples, len(matrix[i].Floats))
+		}
+	})
+
+	t.Run("empty matrix should be written as a single message", func(t *testing.T) {
+		writes := encode(t, &v1.Response{
+			Status: "success",
+			Data:   &v1.QueryData{ResultType: parser.ValueTypeMatrix, Result: promql.Matrix{}},
+		})
+		require.Len(t, writes, 1)
+
+		messages := decode(t, writes[0])
+		require.Len(t, messages, 1)
+		require.Equal(t, mimirpb.QueryResponse_SUCCESS, messages[0].Status)
+		require.Empty(t, messages[0].GetMatrix().Series)
+	})
+}
+
+// writesRecorder is a httptest.ResponseRecorder recording the bytes of each call to Write.
+type writesRecorder struct {
+	*httptest.ResponseRecorder
+
+	writes [][]byte
+}
+
+func (w *writesRecorder) Write(p []byte) (int, error) {
+	w.writes = append(w.writes, bytes.Clone(p))
+	return w.ResponseRecorder.Write(p)
+}
+
 func BenchmarkProtobufCodec_Encode(b *testing.B) {
 	codec := protobufCodec{}
 
@@ -14,6 +14,8 @@ import (
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/promql/parser"
 	"golang.org/x/exp/slices"
+
+	"github.com/grafana/mimir/pkg/util"
 )
 
 type CountMethod string
@@ -310,3 +312,96 @@ func (r *ActiveSeriesRequest) String() string {
 
 	return b.String()
 }
+
+type BlocksRequest struct {
+	Matchers []*labels.Matcher
+	Start    int64
+	End      int64
+	Limit    int
+}
+
+// String returns a full representation of the request. The returned string can be
+// used to uniquely identify the request.
+func (r *BlocksRequest) String() string {
+	b := strings.Builder{}
+
+	// Add matchers.
+	for idx, matcher := range r.Matchers {
+		if idx > 0 {
+			b.WriteRune(stringValueSeparator)
+		}
+		b.WriteString(matcher.String())
+	}
+
+	// Add time range.
+	b.WriteRune(stringParamSeparator)
+	b.WriteString(strconv.FormatInt(r.Start, 10))
+	b.WriteRune(stringParamSeparator)
+	b.WriteString(strconv.FormatInt(r.End, 10))
+
+	// Add limit.
+	b.WriteRune(stringParamSeparator)
+	b.WriteString(strconv.Itoa(r.Limit))
+
+	return b.String()
+}
+
+// DecodeBlocksRequest decodes the input http.Request into a BlocksRequest.
+// The input http.Request can either be a GET or POST with URL-encoded parameters.
+func DecodeBlocksRequest(r *http.Request) (*BlocksRequest, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+
+	return DecodeBlocksRequestFromValues(r.Form)
+}
+
+// DecodeBlocksRequestFromValues is like DecodeBlocksRequest but takes url.Values in input.
+func DecodeBlocksRequestFromValues(values url.Values) (*BlocksRequest, error) {
+	var (
+		parsed = &BlocksRequest{}
+		err    error
+	)
+
+	parsed.Matchers, err = extractSelector(values)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed.Start, err = extractTime(values, "start")
+	if err != nil {
+		return nil, err
+	}
+
+	parsed.End, err = extractTime(values, "end")
+	if err != nil {
+		return nil, err
+	}
+
+	if parsed.End < parsed.Start {
+		return nil, fmt.Errorf("'end' param cannot be before 'start' param")
+	}
+
+	parsed.Limit, err = extractLimit(values)
+	if err != nil {
+		return nil, err
+	}
+
+	return parsed, nil
+}
+
+// extractTime parses and gets the required time request param, in milliseconds since epoch.
+func extractTime(values url.Values, name string) (int64, error) {
+	params := values[name]
+	if len(params) == 0 {
+		return 0, fmt.Errorf("'%s' param is required", name)
+	}
+	if len(params) > 1 {
+		return 0, fmt.Errorf("multiple '%s' params are not allowed", name)
+	}
+	t, err := util.ParseTime(params[0])
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid '%s' param", name)
+	}
+	return t, nil
+}
@@ -144,3 +144,69 @@ func TestActiveSeriesRequest_String(t *testing.T) {
 
 	assert.Equal(t, "first=\"1\"\x01second!=\"2\"", req.String())
 }
+
+func TestDecodeBlocksRequest(t *testing.T) {
+	var (
+		params = url.Values{
+			"selector": []string{`{second!="2",first="1"}`},
+			"start":    []string{"1000"},
+			"end":      []string{"2000.5"},
+			"limit":    []string{"100"},
+		}
+
+		expected = &BlocksRequest{
+			Matchers: []*labels.Matcher{
+				labels.MustNewMatcher(labels.MatchEqual, "first", "1"),
+				labels.MustNewMatcher(labels.MatchNotEqual, "second", "2"),
+			},
+			Start: 1000000,
+			End:   2000500,
+			Limit: 100,
+		}
+	)
+
+	t.Run("DecodeBlocksRequest() with GET request", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "http://localhost?"+params.Encode(), nil)
+		require.NoError(t, err)
+
+		actual, err := DecodeBlocksRequest(req)
+		require.NoError(t, err)
+
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("DecodeBlocksRequest() with POST request", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "http://localhost/", strings.NewReader(params.Encode()))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		actual, err := DecodeBlocksRequest(req)
+		require.NoError(t, err)
+
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("DecodeBlocksRequestFromValues() with missing end", func(t *testing.T) {
+		_, err := DecodeBlocksRequestFromValues(url.Values{"start": []string{"1000"}})
+		require.EqualError(t, err, "'end' param is required")
+	})
+
+	t.Run("DecodeBlocksRequestFromValues() with end before start", func(t *testing.T) {
+		_, err := DecodeBlocksRequestFromValues(url.Values{"start": []string{"2000"}, "end": []string{"1000"}})
+		require.EqualError(t, err, "'end' param cannot be before 'start' param")
+	})
+}
+
+func TestBlocksRequest_String(t *testing.T) {
+	req := &BlocksRequest{
+		Matchers: []*labels.Matcher{
+			labels.MustNewMatcher(labels.MatchEqual, "first", "1"),
+			labels.MustNewMatcher(labels.MatchNotEqual, "second", "2"),
+		},
+		Start: 1000,
+		End:   2000,
+		Limit: 100,
+	}
+
+	assert.Equal(t, "first=\"1\"\x01second!=\"2\"\x001000\x002000\x00100", req.String())
+}
@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package compactor
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/runutil"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/tsdb/index"
+
+	"github.com/grafana/mimir/pkg/storage/tsdb/block"
+)
+
+// readBlocksExemplars reads and merges the exemplars of the blocks in the given directories.
+func readBlocksExemplars(blockDirs []string) ([]exemplar.QueryResult, error) {
+	var sets [][]exemplar.QueryResult
+	for _, dir := range blockDirs {
+		res, err := block.ReadExemplarsFromDir(dir)
+		if err != nil {
+			return nil, errors.Wrapf(err, "read exemplars of block %s", dir)
+		}
+		sets = append(sets, res)
+	}
+	return block.MergeExemplars(sets...), nil
+}
+
+// writeCompactedBlockExemplars writes, in the compacted block directory, the exemplars of the source blocks which are
+// within the compacted block time range and whose series are in the compacted block. The series of the exemplars
+// may not be in the compacted block because they're in another shard of a split compaction, or they were deleted.
+func writeCompactedBlockExemplars(ctx context.Context, logger log.Logger, bdir string, meta *block.Meta, sourceExemplars []exemplar.QueryResult) (err error) {
+	if len(sourceExemplars) == 0 {
+		return nil
+	}
+
+	ir, err := index.NewFileReader(filepath.Join(bdir, block.IndexFilename))
+	if err != nil {
+		return errors.Wrap(err, "open index")
+	}
+	defer runutil.CloseWithErrCapture(&err, ir, "close index")
+
+	var result []exemplar.QueryResult
+	for _, s := range sourceExemplars {
+		var exemplars []exemplar.Exemplar
+		for _, e := range s.Exemplars {
+			// The block max time is exclusive.
+			if e.Ts >= meta.MinTime && e.Ts < meta.MaxTime {
+				exemplars = append(exemplars, e)
+			}
+		}
+		if len(exemplars) == 0 {
+			continue
+		}
+
+		found, err := indexHasSeries(ctx, ir, s.SeriesLabels)
+		if err != nil {
+			return errors.Wrapf(err, "look up series %s", s.SeriesLabels)
+		}
+		if found {
+			result = append(result, exemplar.QueryResult{SeriesLabels: s.SeriesLabels, Exemplars: exemplars})
+		}
+	}
+
+	return block.WriteExemplarsFile(logger, bdir, result)
+}
+
+// indexHasSeries returns whether the index has the series with exactly the given labels.
+func indexHasSeries(ctx context.Context, ir *index.Reader, lbls labels.Labels) (bool, error) {
+	var postings []index.Postings
+	var err error
+	lbls.Range(func(l labels.Label) {
+		if err != nil {
+			return
+		}
+		var p index.Postings
+		p, err = ir.Postings(ctx, l.Name, l.Value)
+		postings = append(postings, p)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	var (
+		p       = index.Intersect(postings...)
+		builder labels.ScratchBuilder
+	)
+	for p.Next() {
+		if err := ir.Series(p.At(), &builder, nil); err != nil {
+			return false, err
+		}
+		if labels.Equal(builder.Labels(), lbls) {
+			return true, nil
+		}
+	}
+	return false, p.Err()
+}
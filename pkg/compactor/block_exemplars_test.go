@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package compactor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/mimir/pkg/storage/tsdb/block"
+)
+
+func TestWriteCompactedBlockExemplars(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	series := []labels.Labels{
+		labels.FromStrings("__name__", "metric", "series_id", "1"),
+		labels.FromStrings("__name__", "metric", "series_id", "2"),
+		labels.FromStrings("__name__", "metric", "series_id", "3"),
+	}
+
+	blockID, err := block.CreateBlock(ctx, dir, series, 10, 1000, 2000, labels.EmptyLabels())
+	require.NoError(t, err)
+
+	bdir := filepath.Join(dir, blockID.String())
+	meta, err := block.ReadMetaFromDir(bdir)
+	require.NoError(t, err)
+
+	trace1 := labels.FromStrings("trace_id", "1")
+	trace2 := labels.FromStrings("trace_id", "2")
+
+	// Exemplars of two source blocks.
+	sourceDirs := []string{filepath.Join(dir, "source-1"), filepath.Join(dir, "source-2")}
+	for _, d := range sourceDirs {
+		require.NoError(t, os.MkdirAll(d, 0o750))
+	}
+	require.NoError(t, block.WriteExemplarsFile(log.NewNopLogger(), sourceDirs[0], []exemplar.QueryResult{
+		{SeriesLabels: series[0], Exemplars: []exemplar.Exemplar{{Labels: trace1, Value: 1, Ts: 1000}}},
+		// The exemplar at the block max time is out of the block, because the max time is exclusive.
+		{SeriesLabels: series[1], Exemplars: []exemplar.Exemplar{{Labels: trace1, Value: 1, Ts: 1500}, {Labels: trace2, Value: 2, Ts: 2000}}},
+	}))
+	require.NoError(t, block.WriteExemplarsFile(log.NewNopLogger(), sourceDirs[1], []exemplar.QueryResult{
+		// The same exemplar is in both the source blocks.
+		{SeriesLabels: series[0], Exemplars: []exemplar.Exemplar{{Labels: trace1, Value: 1, Ts: 1000}}},
+		// The series isn't in the compacted block, like when it's in another shard.
+		{SeriesLabels: labels.FromStrings("__name__", "metric", "series_id", "4"), Exemplars: []exemplar.Exemplar{{Labels: trace1, Value: 1, Ts: 1500}}},
+		// The series labels are a subset of the ones of a series in the compacted block.
+		{SeriesLabels: labels.FromStrings("__name__", "metric"), Exemplars: []exemplar.Exemplar{{Labels: trace1, Value: 1, Ts: 1500}}},
+	}))
+
+	sourceExemplars, err := readBlocksExemplars(sourceDirs)
+	require.NoError(t, err)
+	require.NoError(t, writeCompactedBlockExemplars(ctx, log.NewNopLogger(), bdir, meta, sourceExemplars))
+
+	actual, err := block.ReadExemplarsFromDir(bdir)
+	require.NoError(t, err)
+	require.Equal(t, []exemplar.QueryResult{
+		{SeriesLabels: series[0], Exemplars: []exemplar.Exemplar{{Labels: trace1, Value: 1, Ts: 1000}}},
+		{SeriesLabels: series[1], Exemplars: []exemplar.Exemplar{{Labels: trace1, Value: 1, Ts: 1500}}},
+	}, actual)
+}
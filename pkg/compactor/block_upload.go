@@ -230,7 +230,7 @@ func (c *MultitenantCompactor) createBlockUpload(ctx context.Context, meta *bloc
 	}
 
 	// validate data is within the retention period
-	retention := c.cfgProvider.CompactorBlocksRetentionPeriod(tenantID)
+	retention := maxRetentionPeriod(c.cfgProvider.CompactorBlocksRetentionPeriod(tenantID), c.cfgProvider.CompactorRetentionRules(tenantID))
 	if retention > 0 {
 		threshold := time.Now().Add(-retention)
 		if time.UnixMilli(meta.MaxTime).Before(threshold) {
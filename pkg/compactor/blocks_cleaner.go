@@ -412,8 +412,10 @@ func (c *BlocksCleaner) cleanUser(ctx context.Context, userID string, userLogger
 	if idx != nil {
 		// We do not want to stop the remaining work in the cleaner if an
 		// error occurs here. Errors are logged in the function.
-		retention := c.cfgProvider.CompactorBlocksRetentionPeriod(userID)
-		c.applyUserRetentionPeriod(ctx, idx, retention, userBucket, userLogger)
+		for _, resolution := range mimir_tsdb.DownsampleResolutions {
+			retention := c.userRetentionPeriod(userID, resolution)
+			c.applyUserRetentionPeriod(ctx, idx, resolution, retention, userBucket, userLogger)
+		}
 	}
 
 	// Generate an updated in-memory version of the bucket index.
@@ -566,14 +568,38 @@ func (c *BlocksCleaner) cleanUserPartialBlocks(ctx context.Context, partials map
 	}
 }
 
-// applyUserRetentionPeriod marks blocks for deletion which have aged past the retention period.
-func (c *BlocksCleaner) applyUserRetentionPeriod(ctx context.Context, idx *bucketindex.Index, retention time.Duration, userBucket objstore.Bucket, userLogger log.Logger) {
+// userRetentionPeriod returns the retention period of the tenant's blocks with the input resolution.
+func (c *BlocksCleaner) userRetentionPeriod(userID string, resolution int64) time.Duration {
+	var retention time.Duration
+	switch resolution {
+	case mimir_tsdb.ResolutionRaw:
+		retention = c.cfgProvider.CompactorRawBlocksRetentionPeriod(userID)
+	case mimir_tsdb.Resolution5m:
+		retention = c.cfgProvider.Compactor5mBlocksRetentionPeriod(userID)
+	}
+
+	if retention > 0 {
+		return retention
+	}
+
+	// The series matching a retention rule may be kept longer than the tenant's retention period, in which case
+	// the other series are deleted by rewriting the blocks, and the blocks are deleted after the longest period.
+	return maxRetentionPeriod(c.cfgProvider.CompactorBlocksRetentionPeriod(userID), c.cfgProvider.CompactorRetentionRules(userID))
+}
+
+// applyUserRetentionPeriod marks blocks with the input resolution for deletion which have aged past the retention period.
+func (c *BlocksCleaner) applyUserRetentionPeriod(ctx context.Context, idx *bucketindex.Index, resolution int64, retention time.Duration, userBucket objstore.Bucket, userLogger log.Logger) {
 	// The retention period of zero is a special value indicating to never delete.
 	if retention <= 0 {
 		return
 	}
 
-	blocks := listBlocksOutsideRetentionPeriod(idx, time.Now().Add(-retention))
+	var blocks bucketindex.Blocks
+	for _, b := range listBlocksOutsideRetentionPeriod(idx, time.Now().Add(-retention)) {
+		if b.Resolution == resolution {
+			blocks = append(blocks, b)
+		}
+	}
 
 	// Attempt to mark all blocks. It is not critical if a marking fails, as
 	// the cleaner will retry applying the retention in its next cycle.
@@ -583,7 +609,7 @@ func (c *BlocksCleaner) applyUserRetentionPeriod(ctx context.Context, idx *bucke
 			level.Warn(userLogger).Log("msg", "failed to mark block for deletion", "block", b.ID, "err", err)
 		}
 	}
-	level.Info(userLogger).Log("msg", "marked blocks for deletion", "num_blocks", len(blocks), "retention", retention.String())
+	level.Info(userLogger).Log("msg", "marked blocks for deletion", "num_blocks", len(blocks), "retention", retention.String(), "resolution", resolution)
 }
 
 // listBlocksOutsideRetentionPeriod determines the blocks which have aged past
@@ -23,6 +23,7 @@ import (
 	"github.com/oklog/ulid"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/thanos-io/objstore"
@@ -34,6 +35,7 @@ import (
 	mimir_testutil "github.com/grafana/mimir/pkg/storage/tsdb/testutil"
 	"github.com/grafana/mimir/pkg/util"
 	"github.com/grafana/mimir/pkg/util/test"
+	"github.com/grafana/mimir/pkg/util/validation"
 )
 
 type testBlocksCleanerOptions struct {
@@ -512,6 +514,37 @@ func TestBlocksCleaner_ListBlocksOutsideRetentionPeriod(t *testing.T) {
 	assert.ElementsMatch(t, []ulid.ULID{id3}, result.GetULIDs())
 }
 
+func TestBlocksCleaner_UserRetentionPeriod(t *testing.T) {
+	cfgProvider := newMockConfigProvider()
+	cfgProvider.userRetentionPeriods["user-1"] = 400 * 24 * time.Hour
+	cfgProvider.rawRetentionPeriods["user-1"] = 30 * 24 * time.Hour
+	cfgProvider.userRetentionPeriods["user-2"] = 400 * 24 * time.Hour
+	cfgProvider.retentionPeriods5m["user-2"] = 90 * 24 * time.Hour
+	cfgProvider.userRetentionPeriods["user-3"] = 30 * 24 * time.Hour
+	cfgProvider.retentionRules["user-3"] = []*validation.CompactorRetentionRule{
+		{Selector: `{__name__=~"debug_.*"}`, Period: model.Duration(7 * 24 * time.Hour)},
+		{Selector: `{env="prod"}`, Period: model.Duration(400 * 24 * time.Hour)},
+	}
+	cfgProvider.userRetentionPeriods["user-4"] = 30 * 24 * time.Hour
+	cfgProvider.retentionRules["user-4"] = []*validation.CompactorRetentionRule{
+		{Selector: `{env="prod"}`, Period: 0},
+	}
+
+	cleaner := &BlocksCleaner{cfgProvider: cfgProvider}
+
+	assert.Equal(t, 30*24*time.Hour, cleaner.userRetentionPeriod("user-1", tsdb.ResolutionRaw))
+	assert.Equal(t, 400*24*time.Hour, cleaner.userRetentionPeriod("user-1", tsdb.Resolution5m))
+	assert.Equal(t, 400*24*time.Hour, cleaner.userRetentionPeriod("user-1", tsdb.Resolution1h))
+
+	assert.Equal(t, 400*24*time.Hour, cleaner.userRetentionPeriod("user-2", tsdb.ResolutionRaw))
+	assert.Equal(t, 90*24*time.Hour, cleaner.userRetentionPeriod("user-2", tsdb.Resolution5m))
+	assert.Equal(t, 400*24*time.Hour, cleaner.userRetentionPeriod("user-2", tsdb.Resolution1h))
+
+	// The blocks are deleted after the longest retention period of the retention rules.
+	assert.Equal(t, 400*24*time.Hour, cleaner.userRetentionPeriod("user-3", tsdb.ResolutionRaw))
+	assert.Equal(t, time.Duration(0), cleaner.userRetentionPeriod("user-4", tsdb.ResolutionRaw))
+}
+
 func TestBlocksCleaner_ShouldRemoveBlocksOutsideRetentionPeriod(t *testing.T) {
 	bucketClient, _ := mimir_testutil.PrepareFilesystemBucket(t)
 	bucketClient = block.BucketWithGlobalMarkers(bucketClient)
@@ -1035,6 +1068,11 @@ type mockConfigProvider struct {
 	userPartialBlockDelay        map[string]time.Duration
 	userPartialBlockDelayInvalid map[string]bool
 	verifyChunks                 map[string]bool
+	rawRetentionPeriods          map[string]time.Duration
+	retentionPeriods5m           map[string]time.Duration
+	downsampling5mDelays         map[string]time.Duration
+	downsampling1hDelays         map[string]time.Duration
+	retentionRules               map[string][]*validation.CompactorRetentionRule
 }
 
 func newMockConfigProvider() *mockConfigProvider {
@@ -1048,6 +1086,11 @@ func newMockConfigProvider() *mockConfigProvider {
 		userPartialBlockDelay:        make(map[string]time.Duration),
 		userPartialBlockDelayInvalid: make(map[string]bool),
 		verifyChunks:                 make(map[string]bool),
+		rawRetentionPeriods:          make(map[string]time.Duration),
+		retentionPeriods5m:           make(map[string]time.Duration),
+		downsampling5mDelays:         make(map[string]time.Duration),
+		downsampling1hDelays:         make(map[string]time.Duration),
+		retentionRules:               make(map[string][]*validation.CompactorRetentionRule),
 	}
 }
 
@@ -1058,6 +1101,26 @@ func (m *mockConfigProvider) CompactorBlocksRetentionPeriod(user string) time.Du
 	return 0
 }
 
+func (m *mockConfigProvider) CompactorRetentionRules(user string) []*validation.CompactorRetentionRule {
+	return m.retentionRules[user]
+}
+
+func (m *mockConfigProvider) CompactorRawBlocksRetentionPeriod(user string) time.Duration {
+	return m.rawRetentionPeriods[user]
+}
+
+func (m *mockConfigProvider) Compactor5mBlocksRetentionPeriod(user string) time.Duration {
+	return m.retentionPeriods5m[user]
+}
+
+func (m *mockConfigProvider) CompactorDownsampling5mDelay(user string) time.Duration {
+	return m.downsampling5mDelays[user]
+}
+
+func (m *mockConfigProvider) CompactorDownsampling1hDelay(user string) time.Duration {
+	return m.downsampling1hDelays[user]
+}
+
 func (m *mockConfigProvider) CompactorSplitAndMergeShards(user string) int {
 	if result, ok := m.splitAndMergeShards[user]; ok {
 		return result
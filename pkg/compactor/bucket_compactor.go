@@ -360,6 +360,12 @@ func (c *BucketCompactor) runCompactionJob(ctx context.Context, job *Job) (shoul
 	elapsed = time.Since(compactionBegin)
 	level.Info(jobLogger).Log("msg", "compacted blocks", "new", fmt.Sprintf("%v", compIDs), "blocks", fmt.Sprintf("%v", blocksToCompactDirs), "duration", elapsed, "duration_ms", elapsed.Milliseconds())
 
+	// The exemplars of the source blocks are carried over to the compacted blocks.
+	sourceExemplars, err := readBlocksExemplars(blocksToCompactDirs)
+	if err != nil {
+		return false, nil, err
+	}
+
 	uploadBegin := time.Now()
 	uploadedBlocks := atomic.NewInt64(0)
 
@@ -382,11 +388,20 @@ func (c *BucketCompactor) runCompactionJob(ctx context.Context, job *Job) (shoul
 			newLabels[mimir_tsdb.CompactorShardIDExternalLabel] = sharding.FormatShardIDLabelValue(uint64(blockToUpload.shardIndex), uint64(job.SplittingShards()))
 		}
 
+		var indexStats *block.IndexStats
+		if c.indexStatsMaxNames > 0 {
+			if indexStats, err = block.GatherIndexStats(ctx, bdir, c.indexStatsMaxNames); err != nil {
+				return errors.Wrapf(err, "failed to gather the index stats of the block %s", bdir)
+			}
+		}
+
 		newMeta, err := block.InjectThanosMeta(jobLogger, bdir, block.ThanosMeta{
-			Labels:       newLabels,
-			Downsample:   block.ThanosDownsample{Resolution: job.Resolution()},
-			Source:       block.CompactorSource,
-			SegmentFiles: block.GetSegmentFiles(bdir),
+			Labels:                 newLabels,
+			Downsample:             block.ThanosDownsample{Resolution: job.Resolution()},
+			Source:                 block.CompactorSource,
+			SegmentFiles:           block.GetSegmentFiles(bdir),
+			SeriesDeletionRequests: appliedSeriesDeletionRequests(toCompact),
+			IndexStats:             indexStats,
 		}, nil)
 		if err != nil {
 			return errors.Wrapf(err, "failed to finalize the block %s", bdir)
@@ -396,6 +411,10 @@ func (c *BucketCompactor) runCompactionJob(ctx context.Context, job *Job) (shoul
 			return errors.Wrap(err, "remove tombstones")
 		}
 
+		if err := writeCompactedBlockExemplars(ctx, jobLogger, bdir, newMeta, sourceExemplars); err != nil {
+			return errors.Wrapf(err, "failed to write the exemplars of the block %s", bdir)
+		}
+
 		// Ensure the compacted block is valid.
 		if err := block.VerifyBlock(ctx, jobLogger, bdir, newMeta.MinTime, newMeta.MaxTime, false); err != nil {
 			return errors.Wrapf(err, "invalid result block %s", bdir)
@@ -685,6 +704,7 @@ type BucketCompactor struct {
 	sortJobs                       JobsOrderFunc
 	waitPeriod                     time.Duration
 	blockSyncConcurrency           int
+	indexStatsMaxNames             int
 	metrics                        *BucketCompactorMetrics
 }
 
@@ -703,6 +723,7 @@ func NewBucketCompactor(
 	sortJobs JobsOrderFunc,
 	waitPeriod time.Duration,
 	blockSyncConcurrency int,
+	indexStatsMaxNames int,
 	metrics *BucketCompactorMetrics,
 ) (*BucketCompactor, error) {
 	if concurrency <= 0 {
@@ -722,6 +743,7 @@ func NewBucketCompactor(
 		sortJobs:                       sortJobs,
 		waitPeriod:                     waitPeriod,
 		blockSyncConcurrency:           blockSyncConcurrency,
+		indexStatsMaxNames:             indexStatsMaxNames,
 		metrics:                        metrics,
 	}, nil
 }
@@ -776,95 +798,25 @@ func (c *BucketCompactor) Compact(ctx context.Context, maxCompactionTime time.Du
 						continue
 					}
 
-					c.metrics.groupCompactionRunsStarted.Inc()
-
-					shouldRerunJob, compactedBlockIDs, err := c.runCompactionJob(workCtx, g)
-					if err == nil {
-						c.metrics.groupCompactionRunsCompleted.Inc()
-						if hasNonZeroULIDs(compactedBlockIDs) {
-							c.metrics.groupCompactions.Inc()
-						}
-
-						if shouldRerunJob {
-							mtx.Lock()
-							finishedAllJobs = false
-							mtx.Unlock()
-						}
-						continue
+					shouldRerunJob, err := c.runJob(workCtx, g)
+					if err != nil {
+						errChan <- errors.Wrapf(err, "group %s", g.Key())
+						return
 					}
-
-					// At this point the compaction has failed.
-					c.metrics.groupCompactionRunsFailed.Inc()
-
-					if IsIssue347Error(err) {
-						if err := RepairIssue347(workCtx, c.logger, c.bkt, c.sy.metrics.blocksMarkedForDeletion, err); err == nil {
-							mtx.Lock()
-							finishedAllJobs = false
-							mtx.Unlock()
-							continue
-						}
+					if shouldRerunJob {
+						mtx.Lock()
+						finishedAllJobs = false
+						mtx.Unlock()
 					}
-					// If block has out of order chunk and it has been configured to skip it,
-					// then we can mark the block for no compaction so that the next compaction run
-					// will skip it.
-					if IsOutOfOrderChunkError(err) && c.skipBlocksWithOutOfOrderChunks {
-						if err := block.MarkForNoCompact(
-							ctx,
-							c.logger,
-							c.bkt,
-							err.(OutOfOrderChunksError).id,
-							block.OutOfOrderChunksNoCompactReason,
-							"OutofOrderChunk: marking block with out-of-order series/chunks to as no compact to unblock compaction", c.metrics.blocksMarkedForNoCompact); err == nil {
-							mtx.Lock()
-							finishedAllJobs = false
-							mtx.Unlock()
-							continue
-						}
-					}
-					errChan <- errors.Wrapf(err, "group %s", g.Key())
-					return
 				}
 			}()
 		}
 
-		level.Info(c.logger).Log("msg", "start sync of metas")
-		if err := c.sy.SyncMetas(ctx); err != nil {
-			return errors.Wrap(err, "sync")
-		}
-
-		level.Info(c.logger).Log("msg", "start of GC")
-		// Blocks that were compacted are garbage collected after each Compaction.
-		// However if compactor crashes we need to resolve those on startup.
-		if err := c.sy.GarbageCollect(ctx); err != nil {
-			return errors.Wrap(err, "blocks garbage collect")
-		}
-
-		jobs, err := c.grouper.Groups(c.sy.Metas())
-		if err != nil {
-			return errors.Wrap(err, "build compaction jobs")
-		}
-
-		// There is another check just before we start processing the job, but we can avoid sending it
-		// to the goroutine in the first place.
-		jobs, err = c.filterOwnJobs(jobs)
+		jobs, err := c.planJobs(ctx)
 		if err != nil {
 			return err
 		}
 
-		// Record the difference between now and the max time for a block being compacted. This
-		// is used to detect compactors not being able to keep up with the rate of blocks being
-		// created. The idea is that most blocks should be for within 24h or 48h.
-		now := time.Now()
-		for _, delta := range c.blockMaxTimeDeltas(now, jobs) {
-			c.metrics.blocksMaxTimeDelta.Observe(delta)
-		}
-
-		// Skip jobs for which the wait period hasn't been honored yet.
-		jobs = c.filterJobsByWaitPeriod(ctx, jobs)
-
-		// Sort jobs based on the configured ordering algorithm.
-		jobs = c.sortJobs(jobs)
-
 		ignoreDirs := []string{}
 		for _, gr := range jobs {
 			for _, grID := range gr.IDs() {
@@ -917,6 +869,89 @@ func (c *BucketCompactor) Compact(ctx context.Context, maxCompactionTime time.Du
 	return nil
 }
 
+// planJobs syncs the metas of the blocks in the bucket and returns the compaction jobs owned by the
+// compactor, sorted by the configured order.
+func (c *BucketCompactor) planJobs(ctx context.Context) ([]*Job, error) {
+	level.Info(c.logger).Log("msg", "start sync of metas")
+	if err := c.sy.SyncMetas(ctx); err != nil {
+		return nil, errors.Wrap(err, "sync")
+	}
+
+	level.Info(c.logger).Log("msg", "start of GC")
+	// Blocks that were compacted are garbage collected after each Compaction.
+	// However if compactor crashes we need to resolve those on startup.
+	if err := c.sy.GarbageCollect(ctx); err != nil {
+		return nil, errors.Wrap(err, "blocks garbage collect")
+	}
+
+	jobs, err := c.grouper.Groups(c.sy.Metas())
+	if err != nil {
+		return nil, errors.Wrap(err, "build compaction jobs")
+	}
+
+	// There is another check just before we start processing the job, but we can avoid sending it
+	// to the goroutine in the first place.
+	jobs, err = c.filterOwnJobs(jobs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Record the difference between now and the max time for a block being compacted. This
+	// is used to detect compactors not being able to keep up with the rate of blocks being
+	// created. The idea is that most blocks should be for within 24h or 48h.
+	now := time.Now()
+	for _, delta := range c.blockMaxTimeDeltas(now, jobs) {
+		c.metrics.blocksMaxTimeDelta.Observe(delta)
+	}
+
+	// Skip jobs for which the wait period hasn't been honored yet.
+	jobs = c.filterJobsByWaitPeriod(ctx, jobs)
+
+	// Sort jobs based on the configured ordering algorithm.
+	jobs = c.sortJobs(jobs)
+
+	return jobs, nil
+}
+
+// runJob runs a compaction job. A failure fixed by repairing or marking the input blocks isn't
+// returned as an error, but requires the job to be rerun.
+func (c *BucketCompactor) runJob(ctx context.Context, job *Job) (shouldRerun bool, _ error) {
+	c.metrics.groupCompactionRunsStarted.Inc()
+
+	shouldRerunJob, compactedBlockIDs, err := c.runCompactionJob(ctx, job)
+	if err == nil {
+		c.metrics.groupCompactionRunsCompleted.Inc()
+		if hasNonZeroULIDs(compactedBlockIDs) {
+			c.metrics.groupCompactions.Inc()
+		}
+		return shouldRerunJob, nil
+	}
+
+	// At this point the compaction has failed.
+	c.metrics.groupCompactionRunsFailed.Inc()
+
+	if IsIssue347Error(err) {
+		if err := RepairIssue347(ctx, c.logger, c.bkt, c.metrics.blocksMarkedForDeletion, err); err == nil {
+			return true, nil
+		}
+	}
+	// If block has out of order chunk and it has been configured to skip it,
+	// then we can mark the block for no compaction so that the next compaction run
+	// will skip it.
+	if IsOutOfOrderChunkError(err) && c.skipBlocksWithOutOfOrderChunks {
+		if err := block.MarkForNoCompact(
+			ctx,
+			c.logger,
+			c.bkt,
+			err.(OutOfOrderChunksError).id,
+			block.OutOfOrderChunksNoCompactReason,
+			"OutofOrderChunk: marking block with out-of-order series/chunks to as no compact to unblock compaction", c.metrics.blocksMarkedForNoCompact); err == nil {
+			return true, nil
+		}
+	}
+	return false, err
+}
+
 // blockMaxTimeDeltas returns a slice of the difference between now and the MaxTime of each
 // block that will be compacted as part of the provided jobs, in seconds.
 func (c *BucketCompactor) blockMaxTimeDeltas(now time.Time, jobs []*Job) []float64 {
@@ -242,7 +242,7 @@ func TestGroupCompactE2E(t *testing.T) {
 		planner := NewSplitAndMergePlanner([]int64{1000, 3000})
 		grouper := NewSplitAndMergeGrouper("user-1", []int64{1000, 3000}, 0, 0, logger)
 		metrics := NewBucketCompactorMetrics(blocksMarkedForDeletion, prometheus.NewPedanticRegistry())
-		bComp, err := NewBucketCompactor(logger, sy, grouper, planner, comp, dir, bkt, 2, true, ownAllJobs, sortJobsByNewestBlocksFirst, 0, 4, metrics)
+		bComp, err := NewBucketCompactor(logger, sy, grouper, planner, comp, dir, bkt, 2, true, ownAllJobs, sortJobsByNewestBlocksFirst, 0, 4, 10, metrics)
 		require.NoError(t, err)
 
 		// Compaction on empty should not fail.
@@ -408,6 +408,9 @@ func TestGroupCompactE2E(t *testing.T) {
 			assert.True(t, labels.Equal(extLabels, labels.FromMap(meta.Thanos.Labels)), "ext labels does not match")
 			assert.Equal(t, int64(124), meta.Thanos.Downsample.Resolution)
 			assert.True(t, len(meta.Thanos.SegmentFiles) > 0, "compacted blocks have segment files set")
+			require.NotNil(t, meta.Thanos.IndexStats)
+			assert.Equal(t, []string{"a", "b"}, meta.Thanos.IndexStats.LabelNames)
+			assert.Equal(t, 0, meta.Thanos.IndexStats.NumMetricNames)
 		}
 		{
 			meta, ok := others[defaultGroupKey(124, extLabels2)]
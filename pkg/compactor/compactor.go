@@ -15,6 +15,7 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-kit/log"
@@ -29,12 +30,15 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/thanos-io/objstore"
 	"go.uber.org/atomic"
+	"google.golang.org/grpc"
 
+	"github.com/grafana/mimir/pkg/compactor/compactorschedulerpb"
 	"github.com/grafana/mimir/pkg/storage/bucket"
 	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
 	"github.com/grafana/mimir/pkg/storage/tsdb/block"
 	"github.com/grafana/mimir/pkg/util"
 	util_log "github.com/grafana/mimir/pkg/util/log"
+	"github.com/grafana/mimir/pkg/util/validation"
 )
 
 const (
@@ -58,7 +62,14 @@ var (
 	errInvalidMaxClosingBlocksConcurrency         = fmt.Errorf("invalid max-closing-blocks-concurrency value, must be positive")
 	errInvalidSymbolFlushersConcurrency           = fmt.Errorf("invalid symbols-flushers-concurrency value, must be positive")
 	errInvalidMaxBlockUploadValidationConcurrency = fmt.Errorf("invalid max-block-upload-validation-concurrency value, can't be negative")
+	errInvalidBlockIndexStatsMaxNames             = fmt.Errorf("invalid block-index-stats-max-names value, can't be negative")
 	RingOp                                        = ring.NewOp([]ring.InstanceState{ring.ACTIVE}, nil)
+
+	// compactionRemovedExternalLabels are the external labels removed from the blocks metas before grouping them.
+	compactionRemovedExternalLabels = []string{
+		mimir_tsdb.DeprecatedTenantIDExternalLabel,
+		mimir_tsdb.DeprecatedIngesterIDExternalLabel,
+	}
 )
 
 // BlocksGrouperFactory builds and returns the grouper to use to compact a tenant's blocks.
@@ -95,6 +106,8 @@ type Config struct {
 	TenantCleanupDelay         time.Duration           `yaml:"tenant_cleanup_delay" category:"advanced"`
 	MaxCompactionTime          time.Duration           `yaml:"max_compaction_time" category:"advanced"`
 	NoBlocksFileCleanupEnabled bool                    `yaml:"no_blocks_file_cleanup_enabled" category:"experimental"`
+	SeriesDeletionDelay        time.Duration           `yaml:"series_deletion_delay" category:"experimental"`
+	BlockIndexStatsMaxNames    int                     `yaml:"block_index_stats_max_names" category:"experimental"`
 
 	// Compactor concurrency options
 	MaxOpeningBlocksConcurrency         int `yaml:"max_opening_blocks_concurrency" category:"advanced"`          // Number of goroutines opening blocks before compaction.
@@ -108,6 +121,9 @@ type Config struct {
 	// Compactors sharding.
 	ShardingRing RingConfig `yaml:"sharding_ring"`
 
+	// Compaction jobs planned by the compactor-scheduler.
+	Scheduler SchedulerConfig `yaml:"scheduler"`
+
 	CompactionJobsOrder string `yaml:"compaction_jobs_order" category:"advanced"`
 
 	// No need to add options to customize the retry backoff,
@@ -124,6 +140,7 @@ type Config struct {
 // RegisterFlags registers the MultitenantCompactor flags.
 func (cfg *Config) RegisterFlags(f *flag.FlagSet, logger log.Logger) {
 	cfg.ShardingRing.RegisterFlags(f, logger)
+	cfg.Scheduler.RegisterFlags(f)
 
 	cfg.BlockRanges = mimir_tsdb.DurationList{2 * time.Hour, 12 * time.Hour, 24 * time.Hour}
 	cfg.retryMinBackoff = 10 * time.Second
@@ -146,6 +163,8 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet, logger log.Logger) {
 		"If 0, blocks will be deleted straight away. Note that deleting blocks immediately can cause query failures.")
 	f.DurationVar(&cfg.TenantCleanupDelay, "compactor.tenant-cleanup-delay", 6*time.Hour, "For tenants marked for deletion, this is time between deleting of last block, and doing final cleanup (marker files, debug files) of the tenant.")
 	f.BoolVar(&cfg.NoBlocksFileCleanupEnabled, "compactor.no-blocks-file-cleanup-enabled", false, "If enabled, will delete the bucket-index, markers and debug files in the tenant bucket when there are no blocks left in the index.")
+	f.DurationVar(&cfg.SeriesDeletionDelay, "compactor.series-deletion-delay", 24*time.Hour, "Time after a series deletion request has been created before the compactor rewrites the blocks to permanently remove the matching samples. The request can be cancelled during this period. Matching samples are filtered out at query time once the bucket index includes the request.")
+	f.IntVar(&cfg.BlockIndexStatsMaxNames, "compactor.block-index-stats-max-names", 0, "Maximum number of metric names and label names recorded in the statistics of the compacted blocks, which are copied to the bucket index and used by queriers and store-gateways to skip the blocks that can't contain the queried series. The metric names with the most series are recorded. 0 to disable the statistics.")
 	// compactor concurrency options
 	f.IntVar(&cfg.MaxOpeningBlocksConcurrency, "compactor.max-opening-blocks-concurrency", 1, "Number of goroutines opening blocks before compaction.")
 	f.IntVar(&cfg.MaxClosingBlocksConcurrency, "compactor.max-closing-blocks-concurrency", 1, "Max number of blocks that can be closed concurrently during split compaction. Note that closing of newly compacted block uses a lot of memory for writing index.")
@@ -176,9 +195,15 @@ func (cfg *Config) Validate() error {
 	if cfg.MaxBlockUploadValidationConcurrency < 0 {
 		return errInvalidMaxBlockUploadValidationConcurrency
 	}
+	if cfg.BlockIndexStatsMaxNames < 0 {
+		return errInvalidBlockIndexStatsMaxNames
+	}
 	if !util.StringsContain(CompactionOrders, cfg.CompactionJobsOrder) {
 		return errInvalidCompactionOrder
 	}
+	if err := cfg.Scheduler.Validate(); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -190,6 +215,26 @@ type ConfigProvider interface {
 	// CompactorBlocksRetentionPeriod returns the retention period for a given user.
 	CompactorBlocksRetentionPeriod(user string) time.Duration
 
+	// CompactorRetentionRules returns the retention periods of the series matching a selector for a given user.
+	// The series matching no rule are deleted after CompactorBlocksRetentionPeriod.
+	CompactorRetentionRules(user string) []*validation.CompactorRetentionRule
+
+	// CompactorRawBlocksRetentionPeriod returns the retention period of the raw blocks for a given user.
+	// 0 means that the raw blocks are deleted after CompactorBlocksRetentionPeriod.
+	CompactorRawBlocksRetentionPeriod(user string) time.Duration
+
+	// Compactor5mBlocksRetentionPeriod returns the retention period of the blocks downsampled to 5 minutes for a given user.
+	// 0 means that these blocks are deleted after CompactorBlocksRetentionPeriod.
+	Compactor5mBlocksRetentionPeriod(user string) time.Duration
+
+	// CompactorDownsampling5mDelay returns the minimum age of the blocks before they're downsampled to 5 minutes
+	// for a given user. 0 means that the blocks aren't downsampled to 5 minutes.
+	CompactorDownsampling5mDelay(user string) time.Duration
+
+	// CompactorDownsampling1hDelay returns the minimum age of the blocks before they're downsampled to 1 hour
+	// for a given user. 0 means that the blocks aren't downsampled to 1 hour.
+	CompactorDownsampling1hDelay(user string) time.Duration
+
 	// CompactorSplitAndMergeShards returns the number of shards to use when splitting blocks.
 	CompactorSplitAndMergeShards(userID string) int
 
@@ -254,6 +299,10 @@ type MultitenantCompactor struct {
 	shardingStrategy shardingStrategy
 	jobsOrder        JobsOrderFunc
 
+	// Client of the compactor-scheduler, if configured.
+	schedulerConn   *grpc.ClientConn
+	schedulerClient compactorschedulerpb.CompactorSchedulerClient
+
 	// Metrics.
 	compactionRunsStarted          prometheus.Counter
 	compactionRunsCompleted        prometheus.Counter
@@ -267,6 +316,19 @@ type MultitenantCompactor struct {
 	compactionRunInterval          prometheus.Gauge
 	blocksMarkedForDeletion        prometheus.Counter
 
+	// Series deletion metrics.
+	seriesDeletionBlocksRewritten         prometheus.Counter
+	seriesDeletionBlocksMarkedForDeletion prometheus.Counter
+	seriesDeletionRequestsProcessed       prometheus.Counter
+
+	// Retention rules metrics.
+	retentionRulesBlocksRewritten         prometheus.Counter
+	retentionRulesBlocksMarkedForDeletion prometheus.Counter
+
+	// Downsampling metrics.
+	downsampledBlocks       *prometheus.CounterVec
+	downsamplingFailedTotal prometheus.Counter
+
 	// Metrics shared across all BucketCompactor instances.
 	bucketCompactorMetrics *BucketCompactorMetrics
 
@@ -371,6 +433,36 @@ func newMultitenantCompactor(
 			Help:        blocksMarkedForDeletionHelp,
 			ConstLabels: prometheus.Labels{"reason": "compaction"},
 		}),
+		seriesDeletionBlocksRewritten: promauto.With(registerer).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_compactor_series_deletion_blocks_rewritten_total",
+			Help: "Total number of blocks rewritten by the compactor to apply series deletion requests.",
+		}),
+		seriesDeletionBlocksMarkedForDeletion: promauto.With(registerer).NewCounter(prometheus.CounterOpts{
+			Name:        blocksMarkedForDeletionName,
+			Help:        blocksMarkedForDeletionHelp,
+			ConstLabels: prometheus.Labels{"reason": "series-deletion"},
+		}),
+		seriesDeletionRequestsProcessed: promauto.With(registerer).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_compactor_series_deletion_requests_processed_total",
+			Help: "Total number of series deletion requests fully applied to the blocks in the storage.",
+		}),
+		retentionRulesBlocksRewritten: promauto.With(registerer).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_compactor_retention_rules_blocks_rewritten_total",
+			Help: "Total number of blocks rewritten by the compactor to delete the series exceeding their retention period.",
+		}),
+		retentionRulesBlocksMarkedForDeletion: promauto.With(registerer).NewCounter(prometheus.CounterOpts{
+			Name:        blocksMarkedForDeletionName,
+			Help:        blocksMarkedForDeletionHelp,
+			ConstLabels: prometheus.Labels{"reason": "retention-rules"},
+		}),
+		downsampledBlocks: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_compactor_downsampled_blocks_total",
+			Help: "Total number of downsampled blocks written by the compactor.",
+		}, []string{"resolution"}),
+		downsamplingFailedTotal: promauto.With(registerer).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_compactor_downsampling_failed_total",
+			Help: "Total number of blocks the compactor failed to downsample.",
+		}),
 		blockUploadBlocks: promauto.With(registerer).NewGaugeVec(prometheus.GaugeOpts{
 			Name: "cortex_block_upload_api_blocks_total",
 			Help: "Total number of blocks successfully uploaded and validated using the block upload API.",
@@ -485,6 +577,13 @@ func (c *MultitenantCompactor) starting(ctx context.Context) error {
 	allowedTenants := util.NewAllowedTenants(c.compactorCfg.EnabledTenants, c.compactorCfg.DisabledTenants)
 	c.shardingStrategy = newSplitAndMergeShardingStrategy(allowedTenants, c.ring, c.ringLifecycler, c.cfgProvider)
 
+	if c.compactorCfg.Scheduler.enabled() {
+		if err := c.connectToScheduler(); err != nil {
+			c.ringSubservices.StopAsync()
+			return err
+		}
+	}
+
 	// Create the blocks cleaner (service).
 	c.blocksCleaner = NewBlocksCleaner(BlocksCleanerConfig{
 		DeletionDelay:              c.compactorCfg.DeletionDelay,
@@ -538,6 +637,9 @@ func (c *MultitenantCompactor) stopping(_ error) error {
 	ctx := context.Background()
 
 	services.StopAndAwaitTerminated(ctx, c.blocksCleaner) //nolint:errcheck
+	if c.schedulerConn != nil {
+		_ = c.schedulerConn.Close()
+	}
 	if c.ringSubservices != nil {
 		return services.StopManagerAndAwaitStopped(ctx, c.ringSubservices)
 	}
@@ -545,6 +647,23 @@ func (c *MultitenantCompactor) stopping(_ error) error {
 }
 
 func (c *MultitenantCompactor) running(ctx context.Context) error {
+	// Run the compaction jobs planned by the compactor-scheduler, if configured.
+	if c.compactorCfg.Scheduler.enabled() {
+		workersCtx, cancelWorkers := context.WithCancel(ctx)
+		wg := &sync.WaitGroup{}
+		for i := 0; i < c.compactorCfg.CompactionConcurrency; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				c.runScheduledJobs(workersCtx)
+			}()
+		}
+		defer func() {
+			cancelWorkers()
+			wg.Wait()
+		}()
+	}
+
 	// Run an initial compaction before starting the interval.
 	c.compactUsers(ctx)
 
@@ -711,11 +830,66 @@ func (c *MultitenantCompactor) compactUserWithRetries(ctx context.Context, userI
 
 func (c *MultitenantCompactor) compactUser(ctx context.Context, userID string) error {
 	userBucket := bucket.NewUserBucketClient(userID, c.bucketClient, c.cfgProvider)
+	userLogger := util_log.WithUserID(userID, c.logger)
+
+	// The compaction jobs planned by the compactor-scheduler are run by runScheduledJobs.
+	if !c.compactorCfg.Scheduler.enabled() {
+		if err := c.compactUserBlocks(ctx, userID, userBucket, userLogger); err != nil {
+			return errors.Wrap(err, "compaction")
+		}
+	}
+
+	if err := c.applySeriesDeletionRequests(ctx, userID, userBucket, userLogger); err != nil {
+		return errors.Wrap(err, "apply series deletion requests")
+	}
+
+	if err := c.applyRetentionRules(ctx, userID, userBucket, userLogger); err != nil {
+		return errors.Wrap(err, "apply retention rules")
+	}
+
+	if err := c.downsampleBlocks(ctx, userID, userBucket, userLogger); err != nil {
+		return errors.Wrap(err, "downsample blocks")
+	}
+
+	return nil
+}
+
+// compactUserBlocks plans and runs the compaction jobs of a tenant.
+func (c *MultitenantCompactor) compactUserBlocks(ctx context.Context, userID string, userBucket objstore.InstrumentedBucket, userLogger log.Logger) error {
 	reg := prometheus.NewRegistry()
 	defer c.syncerMetrics.gatherThanosSyncerMetrics(reg)
 
-	userLogger := util_log.WithUserID(userID, c.logger)
+	syncer, err := newUserMetaSyncer(userBucket, c.metaSyncDirForUser(userID), c.compactorCfg.MetaSyncConcurrency, c.blocksMarkedForDeletion, userLogger, reg)
+	if err != nil {
+		return err
+	}
 
+	compactor, err := NewBucketCompactor(
+		userLogger,
+		syncer,
+		c.blocksGrouperFactory(ctx, c.compactorCfg, c.cfgProvider, userID, userLogger, reg),
+		c.blocksPlanner,
+		c.blocksCompactor,
+		path.Join(c.compactorCfg.DataDir, "compact"),
+		userBucket,
+		c.compactorCfg.CompactionConcurrency,
+		true, // Skip blocks with out of order chunks, and mark them for no-compaction.
+		c.shardingStrategy.ownJob,
+		c.jobsOrder,
+		c.compactorCfg.CompactionWaitPeriod,
+		c.compactorCfg.BlockSyncConcurrency,
+		c.compactorCfg.BlockIndexStatsMaxNames,
+		c.bucketCompactorMetrics,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create bucket compactor")
+	}
+
+	return compactor.Compact(ctx, c.compactorCfg.MaxCompactionTime)
+}
+
+// newUserMetaSyncer returns the syncer of the metas of the tenant blocks to compact, caching the metas in metaSyncDir.
+func newUserMetaSyncer(userBucket objstore.InstrumentedBucket, metaSyncDir string, metaSyncConcurrency int, blocksMarkedForDeletion prometheus.Counter, userLogger log.Logger, reg prometheus.Registerer) (*Syncer, error) {
 	// Filters out duplicate blocks that can be formed from two or more overlapping
 	// blocks that fully submatches the source blocks of the older blocks.
 	deduplicateBlocksFilter := NewShardAwareDeduplicateFilter()
@@ -726,10 +900,7 @@ func (c *MultitenantCompactor) compactUser(ctx context.Context, userID string) e
 		// honoring the shard ID if sharding was done in the past.
 		// Remove TenantID external label to make sure that we compact blocks with and without the label
 		// together.
-		NewLabelRemoverFilter([]string{
-			mimir_tsdb.DeprecatedTenantIDExternalLabel,
-			mimir_tsdb.DeprecatedIngesterIDExternalLabel,
-		}),
+		NewLabelRemoverFilter(compactionRemovedExternalLabels),
 		deduplicateBlocksFilter,
 		// removes blocks that should not be compacted due to being marked so.
 		NewNoCompactionMarkFilter(userBucket, true),
@@ -737,14 +908,14 @@ func (c *MultitenantCompactor) compactUser(ctx context.Context, userID string) e
 
 	fetcher, err := block.NewMetaFetcher(
 		userLogger,
-		c.compactorCfg.MetaSyncConcurrency,
+		metaSyncConcurrency,
 		userBucket,
-		c.metaSyncDirForUser(userID),
+		metaSyncDir,
 		reg,
 		fetcherFilters,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	syncer, err := NewMetaSyncer(
@@ -753,37 +924,12 @@ func (c *MultitenantCompactor) compactUser(ctx context.Context, userID string) e
 		userBucket,
 		fetcher,
 		deduplicateBlocksFilter,
-		c.blocksMarkedForDeletion,
+		blocksMarkedForDeletion,
 	)
 	if err != nil {
-		return errors.Wrap(err, "failed to create syncer")
+		return nil, errors.Wrap(err, "failed to create syncer")
 	}
-
-	compactor, err := NewBucketCompactor(
-		userLogger,
-		syncer,
-		c.blocksGrouperFactory(ctx, c.compactorCfg, c.cfgProvider, userID, userLogger, reg),
-		c.blocksPlanner,
-		c.blocksCompactor,
-		path.Join(c.compactorCfg.DataDir, "compact"),
-		userBucket,
-		c.compactorCfg.CompactionConcurrency,
-		true, // Skip blocks with out of order chunks, and mark them for no-compaction.
-		c.shardingStrategy.ownJob,
-		c.jobsOrder,
-		c.compactorCfg.CompactionWaitPeriod,
-		c.compactorCfg.BlockSyncConcurrency,
-		c.bucketCompactorMetrics,
-	)
-	if err != nil {
-		return errors.Wrap(err, "failed to create bucket compactor")
-	}
-
-	if err := compactor.Compact(ctx, c.compactorCfg.MaxCompactionTime); err != nil {
-		return errors.Wrap(err, "compaction")
-	}
-
-	return nil
+	return syncer, nil
 }
 
 func (c *MultitenantCompactor) discoverUsersWithRetries(ctx context.Context) ([]string, error) {
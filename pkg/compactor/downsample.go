@@ -0,0 +1,559 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package compactor
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/runutil"
+	"github.com/oklog/ulid"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/histogram"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"github.com/thanos-io/objstore"
+
+	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
+	"github.com/grafana/mimir/pkg/storage/tsdb/block"
+)
+
+// downsamplingLevel is a resolution the tenant's blocks are downsampled to, and the resolution
+// of the blocks they're downsampled from.
+type downsamplingLevel struct {
+	source, target int64
+	delay          time.Duration
+}
+
+// downsamplingLevels returns the enabled downsampling levels for the tenant, from the finest to the coarsest.
+func downsamplingLevels(cfgProvider ConfigProvider, userID string) []downsamplingLevel {
+	var levels []downsamplingLevel
+
+	source := mimir_tsdb.ResolutionRaw
+	if delay := cfgProvider.CompactorDownsampling5mDelay(userID); delay > 0 {
+		levels = append(levels, downsamplingLevel{source: source, target: mimir_tsdb.Resolution5m, delay: delay})
+		source = mimir_tsdb.Resolution5m
+	}
+	if delay := cfgProvider.CompactorDownsampling1hDelay(userID); delay > 0 {
+		levels = append(levels, downsamplingLevel{source: source, target: mimir_tsdb.Resolution1h, delay: delay})
+	}
+
+	return levels
+}
+
+// downsampleBlocks writes the downsampled blocks of the tenant's blocks older than the downsampling delays.
+// The downsampled blocks are additional blocks: the blocks they're downsampled from are deleted by the
+// retention of their resolution.
+//
+// A block is downsampled if its sources aren't covered by the blocks already downsampled to the
+// target resolution with the same external labels. The blocks downsampled to 1 hour from the blocks
+// downsampled to 5 minutes are written at the next run after the latter have been uploaded.
+//
+// Only the compactor running the blocks cleaner for the tenant downsamples the blocks, so that
+// a block is never downsampled by multiple compactors concurrently.
+func (c *MultitenantCompactor) downsampleBlocks(ctx context.Context, userID string, userBucket objstore.InstrumentedBucket, logger log.Logger) error {
+	levels := downsamplingLevels(c.cfgProvider, userID)
+	if len(levels) == 0 {
+		return nil
+	}
+
+	if owned, err := c.shardingStrategy.blocksCleanerOwnUser(userID); err != nil {
+		// Blocks will be downsampled at the next compaction run.
+		level.Warn(logger).Log("msg", "unable to check if user is owned by this shard for downsampling", "err", err)
+		return nil
+	} else if !owned {
+		return nil
+	}
+
+	fetcher, err := block.NewMetaFetcher(logger, c.compactorCfg.MetaSyncConcurrency, userBucket, c.metaSyncDirForUser(userID), nil, []block.MetadataFilter{NewShardAwareDeduplicateFilter()})
+	if err != nil {
+		return err
+	}
+
+	metas, _, err := fetcher.FetchWithoutMarkedForDeletion(ctx)
+	if err != nil {
+		return errors.Wrap(err, "fetch metas")
+	}
+
+	now := time.Now()
+	for _, lvl := range levels {
+		for _, meta := range blocksToDownsample(metas, lvl, now) {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			blockLogger := log.With(logger, "block", meta.ULID.String(), "resolution", lvl.target)
+			if err := c.downsampleBlock(ctx, userBucket, meta, lvl.target, blockLogger); err != nil {
+				c.downsamplingFailedTotal.Inc()
+				return errors.Wrapf(err, "downsample block %s", meta.ULID.String())
+			}
+		}
+	}
+
+	return nil
+}
+
+// blocksToDownsample returns the blocks at the source resolution of the level, older than its delay, whose
+// sources aren't covered by the blocks already downsampled to its target resolution with the same external
+// labels, or without the compactor shard ID label, because such blocks hold the series of all shards.
+// The returned blocks are sorted by min time.
+func blocksToDownsample(metas map[ulid.ULID]*block.Meta, lvl downsamplingLevel, now time.Time) []*block.Meta {
+	// Sources of the blocks at the target resolution, by external labels.
+	downsampledSources := map[string]map[ulid.ULID]struct{}{}
+	for _, meta := range metas {
+		if meta.Thanos.Downsample.Resolution != lvl.target {
+			continue
+		}
+
+		key := labels.FromMap(meta.Thanos.Labels).String()
+		if downsampledSources[key] == nil {
+			downsampledSources[key] = map[ulid.ULID]struct{}{}
+		}
+		for _, id := range meta.Compaction.Sources {
+			downsampledSources[key][id] = struct{}{}
+		}
+	}
+
+	coveredBy := func(meta *block.Meta, lset labels.Labels) bool {
+		sources := downsampledSources[lset.String()]
+		if len(sources) == 0 {
+			return false
+		}
+		for _, id := range meta.Compaction.Sources {
+			if _, ok := sources[id]; !ok {
+				return false
+			}
+		}
+		return true
+	}
+
+	maxTime := now.Add(-lvl.delay).UnixMilli()
+
+	var out []*block.Meta
+	for _, meta := range metas {
+		if meta.Thanos.Downsample.Resolution != lvl.source || meta.MaxTime > maxTime {
+			continue
+		}
+
+		lset := labels.FromMap(meta.Thanos.Labels)
+		if coveredBy(meta, lset) {
+			continue
+		}
+		if lset.Has(mimir_tsdb.CompactorShardIDExternalLabel) && coveredBy(meta, labels.NewBuilder(lset).Del(mimir_tsdb.CompactorShardIDExternalLabel).Labels()) {
+			continue
+		}
+
+		out = append(out, meta)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].MinTime != out[j].MinTime {
+			return out[i].MinTime < out[j].MinTime
+		}
+		return out[i].ULID.Compare(out[j].ULID) < 0
+	})
+
+	return out
+}
+
+// downsampleBlock downloads the block, downsamples it to the input resolution and uploads the resulting block.
+// The downsampled block keeps the time range, the external labels and the compaction sources of the block.
+func (c *MultitenantCompactor) downsampleBlock(ctx context.Context, userBucket objstore.Bucket, meta *block.Meta, resolution int64, logger log.Logger) error {
+	baseDir := filepath.Join(c.compactorCfg.DataDir, "downsample")
+	if err := os.MkdirAll(baseDir, os.ModePerm); err != nil {
+		return errors.Wrap(err, "create downsample directory")
+	}
+
+	tmpDir, err := os.MkdirTemp(baseDir, meta.ULID.String()+"-")
+	if err != nil {
+		return errors.Wrap(err, "create temporary directory")
+	}
+	defer func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			level.Warn(logger).Log("msg", "failed to remove downsample temporary directory", "dir", tmpDir, "err", err)
+		}
+	}()
+
+	bdir := filepath.Join(tmpDir, meta.ULID.String())
+	if err := block.Download(ctx, logger, userBucket, meta.ULID, bdir); err != nil {
+		return errors.Wrap(err, "download block")
+	}
+
+	outDir := filepath.Join(tmpDir, "out")
+	newID, err := writeDownsampledBlock(ctx, bdir, outDir, meta, resolution, logger)
+	if err != nil {
+		return err
+	}
+
+	// The block has no sample to downsample, like when all its series have been deleted.
+	if newID == (ulid.ULID{}) {
+		level.Info(logger).Log("msg", "block has no sample to downsample")
+		return nil
+	}
+
+	newDir := filepath.Join(outDir, newID.String())
+	newMeta, err := block.InjectThanosMeta(logger, newDir, block.ThanosMeta{
+		Labels:                 meta.Thanos.Labels,
+		Downsample:             block.ThanosDownsample{Resolution: resolution},
+		Source:                 block.CompactorSource,
+		SegmentFiles:           block.GetSegmentFiles(newDir),
+		SeriesDeletionRequests: meta.Thanos.SeriesDeletionRequests,
+	}, &meta.BlockMeta)
+	if err != nil {
+		return errors.Wrapf(err, "failed to finalize the block %s", newDir)
+	}
+
+	if err = os.Remove(filepath.Join(newDir, "tombstones")); err != nil {
+		return errors.Wrap(err, "remove tombstones")
+	}
+
+	if err := block.VerifyBlock(ctx, logger, newDir, newMeta.MinTime, newMeta.MaxTime, false); err != nil {
+		return errors.Wrapf(err, "invalid downsampled block %s", newDir)
+	}
+
+	if err := block.Upload(ctx, logger, userBucket, newDir, nil); err != nil {
+		return errors.Wrapf(err, "upload of %s failed", newID)
+	}
+
+	c.downsampledBlocks.WithLabelValues(strconv.FormatInt(resolution, 10)).Inc()
+	level.Info(logger).Log("msg", "downsampled block", "new_block", newID.String())
+
+	return nil
+}
+
+// writeDownsampledBlock writes to outDir the block in bdir downsampled to the input resolution, and returns
+// its ID. A zero ID is returned if the block has no sample.
+func writeDownsampledBlock(ctx context.Context, bdir, outDir string, meta *block.Meta, resolution int64, logger log.Logger) (_ ulid.ULID, returnErr error) {
+	b, err := tsdb.OpenBlock(logger, bdir, nil)
+	if err != nil {
+		return ulid.ULID{}, errors.Wrap(err, "open block")
+	}
+	defer runutil.CloseWithErrCapture(&returnErr, b, "close block")
+
+	q, err := tsdb.NewBlockQuerier(b, meta.MinTime, meta.MaxTime)
+	if err != nil {
+		return ulid.ULID{}, errors.Wrap(err, "create block querier")
+	}
+	defer runutil.CloseWithErrCapture(&returnErr, q, "close block querier")
+
+	headOpts := tsdb.DefaultHeadOptions()
+	headOpts.ChunkDirRoot = filepath.Join(outDir, "chunks")
+	headOpts.ChunkRange = math.MaxInt64
+	headOpts.EnableNativeHistograms.Store(true)
+	h, err := tsdb.NewHead(nil, logger, nil, nil, headOpts, nil)
+	if err != nil {
+		return ulid.ULID{}, errors.Wrap(err, "create head")
+	}
+	defer func() {
+		runutil.CloseWithErrCapture(&returnErr, h, "close head")
+		if err := os.RemoveAll(headOpts.ChunkDirRoot); err != nil && returnErr == nil {
+			returnErr = errors.Wrap(err, "delete chunks dir")
+		}
+	}()
+
+	if meta.Thanos.Downsample.Resolution == mimir_tsdb.ResolutionRaw {
+		err = downsampleRawSeries(ctx, q, h, resolution)
+	} else {
+		err = downsampleDownsampledSeries(ctx, q, h, resolution)
+	}
+	if err != nil {
+		return ulid.ULID{}, err
+	}
+
+	comp, err := tsdb.NewLeveledCompactor(ctx, nil, logger, []int64{meta.MaxTime - meta.MinTime}, nil, nil, true)
+	if err != nil {
+		return ulid.ULID{}, errors.Wrap(err, "create compactor")
+	}
+
+	id, err := comp.Write(outDir, h, meta.MinTime, meta.MaxTime, nil)
+	return id, errors.Wrap(err, "write downsampled block")
+}
+
+// downsampleRawSeries aggregates the samples of the raw series selected by q, and appends the aggregates to h.
+func downsampleRawSeries(ctx context.Context, q storage.Querier, h *tsdb.Head, resolution int64) error {
+	set := q.Select(ctx, true, nil, labels.MustNewMatcher(labels.MatchRegexp, labels.MetricName, ".*"))
+
+	var it chunkenc.Iterator
+	for set.Next() {
+		series := set.At()
+		agg := newDownsampleAggregator(series.Labels(), resolution)
+
+		it = series.Iterator(it)
+		for typ := it.Next(); typ != chunkenc.ValNone; typ = it.Next() {
+			switch typ {
+			case chunkenc.ValFloat:
+				t, v := it.At()
+				if value.IsStaleNaN(v) {
+					continue
+				}
+				agg.addFloat(t, v, v, v, 1)
+			case chunkenc.ValHistogram:
+				t, hist := it.AtHistogram()
+				agg.addHistogram(t, hist, nil)
+			case chunkenc.ValFloatHistogram:
+				t, fh := it.AtFloatHistogram()
+				agg.addHistogram(t, nil, fh)
+			}
+		}
+		if err := it.Err(); err != nil {
+			return errors.Wrapf(err, "iterate series %s", series.Labels())
+		}
+
+		if err := agg.append(ctx, h); err != nil {
+			return err
+		}
+	}
+
+	return errors.Wrap(set.Err(), "select series")
+}
+
+// downsampleDownsampledSeries aggregates the samples of the downsampled series selected by q, and appends
+// the aggregates to h. The series holding the min, max, sum and count aggregates are selected in lockstep
+// with the series holding the average, which are selected for their histograms only, because the average
+// of the floats is computed again from the sum and count.
+func downsampleDownsampledSeries(ctx context.Context, q storage.Querier, h *tsdb.Head, resolution int64) error {
+	selectAggregate := func(aggregate string) storage.SeriesSet {
+		return q.Select(ctx, true, nil,
+			labels.MustNewMatcher(labels.MatchRegexp, labels.MetricName, ".*"),
+			labels.MustNewMatcher(labels.MatchEqual, mimir_tsdb.DownsampleAggregateLabel, aggregate),
+		)
+	}
+
+	plain := selectAggregate("")
+	aggregates := []storage.SeriesSet{
+		selectAggregate(mimir_tsdb.DownsampleAggregateMin),
+		selectAggregate(mimir_tsdb.DownsampleAggregateMax),
+		selectAggregate(mimir_tsdb.DownsampleAggregateSum),
+		selectAggregate(mimir_tsdb.DownsampleAggregateCount),
+	}
+
+	// Series of the aggregate sets which haven't been consumed yet.
+	current := make([]storage.Series, len(aggregates))
+	next := func() (bool, error) {
+		for i, set := range aggregates {
+			if !set.Next() {
+				if err := set.Err(); err != nil {
+					return false, errors.Wrap(err, "select aggregate series")
+				}
+				current[i] = nil
+				continue
+			}
+			current[i] = set.At()
+		}
+
+		for _, s := range current[1:] {
+			if (s == nil) != (current[0] == nil) || (s != nil && !labels.Equal(downsampleBaseLabels(s.Labels()), downsampleBaseLabels(current[0].Labels()))) {
+				return false, errors.New("the downsampled block has inconsistent aggregate series")
+			}
+		}
+		return current[0] != nil, nil
+	}
+
+	hasAggregates, err := next()
+	if err != nil {
+		return err
+	}
+
+	var it chunkenc.Iterator
+	iterators := make([]chunkenc.Iterator, len(aggregates))
+	for plain.Next() {
+		series := plain.At()
+		agg := newDownsampleAggregator(series.Labels(), resolution)
+
+		// The histograms are stored in the series holding the average only.
+		it = series.Iterator(it)
+		for typ := it.Next(); typ != chunkenc.ValNone; typ = it.Next() {
+			switch typ {
+			case chunkenc.ValHistogram:
+				t, hist := it.AtHistogram()
+				agg.addHistogram(t, hist, nil)
+			case chunkenc.ValFloatHistogram:
+				t, fh := it.AtFloatHistogram()
+				agg.addHistogram(t, nil, fh)
+			}
+		}
+		if err := it.Err(); err != nil {
+			return errors.Wrapf(err, "iterate series %s", series.Labels())
+		}
+
+		// Series with floats have the aggregate series, which are sorted the same as the series holding the average.
+		if hasAggregates && labels.Equal(downsampleBaseLabels(current[0].Labels()), series.Labels()) {
+			for i, s := range current {
+				iterators[i] = s.Iterator(iterators[i])
+			}
+
+			for iterators[0].Next() == chunkenc.ValFloat {
+				var values [4]float64
+				var t int64
+				for i, ait := range iterators {
+					if i > 0 && ait.Next() != chunkenc.ValFloat {
+						return errors.Errorf("the downsampled series %s has inconsistent aggregates", series.Labels())
+					}
+					var at int64
+					at, values[i] = ait.At()
+					if i > 0 && at != t {
+						return errors.Errorf("the downsampled series %s has inconsistent aggregates", series.Labels())
+					}
+					t = at
+				}
+				agg.addFloat(t, values[0], values[1], values[2], values[3])
+			}
+			for i, ait := range iterators {
+				if err := ait.Err(); err != nil {
+					return errors.Wrapf(err, "iterate series %s", current[i].Labels())
+				}
+			}
+
+			if hasAggregates, err = next(); err != nil {
+				return err
+			}
+		}
+
+		if err := agg.append(ctx, h); err != nil {
+			return err
+		}
+	}
+
+	if err := plain.Err(); err != nil {
+		return errors.Wrap(err, "select series")
+	}
+	if hasAggregates {
+		return errors.Errorf("the downsampled block has aggregate series without the average series %s", downsampleBaseLabels(current[0].Labels()))
+	}
+	return nil
+}
+
+// downsampleBaseLabels returns the input labels without the downsampling aggregate label.
+func downsampleBaseLabels(lset labels.Labels) labels.Labels {
+	return labels.NewBuilder(lset).Del(mimir_tsdb.DownsampleAggregateLabel).Labels()
+}
+
+type downsampledFloat struct {
+	t                                   int64
+	avg, minValue, maxValue, sum, count float64
+}
+
+type downsampledHistogram struct {
+	t  int64
+	h  *histogram.Histogram
+	fh *histogram.FloatHistogram
+}
+
+// downsampleAggregator aggregates the samples of a series by window of the downsampling resolution. The samples
+// must be added in timestamp order. The floats of each window are aggregated to their minimum, maximum, sum, count
+// and average, at the timestamp of the last float of the window, while only the last histogram of each window is kept.
+type downsampleAggregator struct {
+	lset       labels.Labels
+	resolution int64
+
+	floats     []downsampledFloat
+	histograms []downsampledHistogram
+}
+
+func newDownsampleAggregator(lset labels.Labels, resolution int64) *downsampleAggregator {
+	return &downsampleAggregator{lset: lset, resolution: resolution}
+}
+
+func (a *downsampleAggregator) window(t int64) int64 {
+	return t - t%a.resolution
+}
+
+// addFloat adds the aggregates of the floats in [t-resolution, t], which is a single float for the raw series.
+func (a *downsampleAggregator) addFloat(t int64, minValue, maxValue, sum, count float64) {
+	if n := len(a.floats); n > 0 && a.window(a.floats[n-1].t) == a.window(t) {
+		last := &a.floats[n-1]
+		last.t = t
+		last.minValue = math.Min(last.minValue, minValue)
+		last.maxValue = math.Max(last.maxValue, maxValue)
+		last.sum += sum
+		last.count += count
+		last.avg = last.sum / last.count
+		return
+	}
+
+	a.floats = append(a.floats, downsampledFloat{t: t, avg: sum / count, minValue: minValue, maxValue: maxValue, sum: sum, count: count})
+}
+
+func (a *downsampleAggregator) addHistogram(t int64, h *histogram.Histogram, fh *histogram.FloatHistogram) {
+	if n := len(a.histograms); n > 0 && a.window(a.histograms[n-1].t) == a.window(t) {
+		a.histograms[n-1] = downsampledHistogram{t: t, h: h, fh: fh}
+		return
+	}
+
+	a.histograms = append(a.histograms, downsampledHistogram{t: t, h: h, fh: fh})
+}
+
+// append appends the aggregated samples to h.
+func (a *downsampleAggregator) append(ctx context.Context, h *tsdb.Head) error {
+	if len(a.floats) == 0 && len(a.histograms) == 0 {
+		return nil
+	}
+
+	app := h.Appender(ctx)
+	if err := a.appendTo(app); err != nil {
+		if rerr := app.Rollback(); rerr != nil {
+			err = errors.Wrapf(err, "rollback failed: %v", rerr)
+		}
+		return errors.Wrapf(err, "append downsampled series %s", a.lset)
+	}
+	return errors.Wrap(app.Commit(), "commit")
+}
+
+func (a *downsampleAggregator) appendTo(app storage.Appender) error {
+	aggregateLabels := func(aggregate string) labels.Labels {
+		return labels.NewBuilder(a.lset).Set(mimir_tsdb.DownsampleAggregateLabel, aggregate).Labels()
+	}
+	minLabels := aggregateLabels(mimir_tsdb.DownsampleAggregateMin)
+	maxLabels := aggregateLabels(mimir_tsdb.DownsampleAggregateMax)
+	sumLabels := aggregateLabels(mimir_tsdb.DownsampleAggregateSum)
+	countLabels := aggregateLabels(mimir_tsdb.DownsampleAggregateCount)
+
+	var (
+		err                                        error
+		plainRef, minRef, maxRef, sumRef, countRef storage.SeriesRef
+	)
+
+	// The floats and the histograms are both appended to the series with the input labels, in timestamp order.
+	hi := 0
+	appendHistogramsBefore := func(t int64) error {
+		for ; hi < len(a.histograms) && a.histograms[hi].t < t; hi++ {
+			if plainRef, err = app.AppendHistogram(plainRef, a.lset, a.histograms[hi].t, a.histograms[hi].h, a.histograms[hi].fh); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, f := range a.floats {
+		if err := appendHistogramsBefore(f.t); err != nil {
+			return err
+		}
+		if plainRef, err = app.Append(plainRef, a.lset, f.t, f.avg); err != nil {
+			return err
+		}
+		if minRef, err = app.Append(minRef, minLabels, f.t, f.minValue); err != nil {
+			return err
+		}
+		if maxRef, err = app.Append(maxRef, maxLabels, f.t, f.maxValue); err != nil {
+			return err
+		}
+		if sumRef, err = app.Append(sumRef, sumLabels, f.t, f.sum); err != nil {
+			return err
+		}
+		if countRef, err = app.Append(countRef, countLabels, f.t, f.count); err != nil {
+			return err
+		}
+	}
+
+	return appendHistogramsBefore(math.MaxInt64)
+}
@@ -0,0 +1,265 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package compactor
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/services"
+	"github.com/oklog/ulid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/prometheus/model/histogram"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"github.com/prometheus/prometheus/tsdb/tsdbutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/grafana/mimir/pkg/storage/bucket"
+	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
+	"github.com/grafana/mimir/pkg/storage/tsdb/block"
+)
+
+func TestMultitenantCompactor_ShouldDownsampleBlocks(t *testing.T) {
+	const userID = "user-1"
+
+	bkt := objstore.NewInMemBucket()
+	userBkt := bucket.NewUserBucketClient(userID, bkt, nil)
+
+	// A float sample per minute, whose value is the minute, for 110 minutes.
+	sourceID := createCustomTSDBBlock(t, bkt, userID, map[string]string{"external": "1"}, func(db *tsdb.DB) {
+		app := db.Appender(context.Background())
+		for i := int64(0); i < 110; i++ {
+			_, err := app.Append(0, labels.FromStrings(labels.MetricName, "series_a"), i*time.Minute.Milliseconds(), float64(i))
+			require.NoError(t, err)
+		}
+		require.NoError(t, app.Commit())
+	})
+
+	cfgProvider := newMockConfigProvider()
+	cfgProvider.downsampling5mDelays[userID] = time.Hour
+	cfgProvider.downsampling1hDelays[userID] = time.Hour
+
+	c, _, tsdbPlanner, _, registry := prepareWithConfigProvider(t, prepareConfig(t), bkt, cfgProvider)
+
+	// Compaction jobs are planned but no block is compacted.
+	tsdbPlanner.On("Plan", mock.Anything, mock.Anything).Return([]*block.Meta{}, nil)
+
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), c))
+	t.Cleanup(stopServiceFn(t, c))
+
+	// Wait until the first compaction run has completed.
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(c.compactionRunsCompleted) > 0
+	}, 10*time.Second, 100*time.Millisecond)
+
+	// The raw block is downsampled to 5 minutes by the first run, and the block downsampled to 5 minutes
+	// is downsampled to 1 hour by the next run.
+	require.NoError(t, c.downsampleBlocks(context.Background(), userID, userBkt, log.NewNopLogger()))
+
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+		# HELP cortex_compactor_downsampled_blocks_total Total number of downsampled blocks written by the compactor.
+		# TYPE cortex_compactor_downsampled_blocks_total counter
+		cortex_compactor_downsampled_blocks_total{resolution="300000"} 1
+		cortex_compactor_downsampled_blocks_total{resolution="3600000"} 1
+
+		# HELP cortex_compactor_downsampling_failed_total Total number of blocks the compactor failed to downsample.
+		# TYPE cortex_compactor_downsampling_failed_total counter
+		cortex_compactor_downsampling_failed_total 0
+	`), "cortex_compactor_downsampled_blocks_total", "cortex_compactor_downsampling_failed_total"))
+
+	sourceMeta, err := block.DownloadMeta(context.Background(), log.NewNopLogger(), userBkt, sourceID)
+	require.NoError(t, err)
+
+	downsampled := map[int64]ulid.ULID{}
+	require.NoError(t, userBkt.Iter(context.Background(), "", func(name string) error {
+		id, ok := block.IsBlockDir(name)
+		if !ok || id == sourceID {
+			return nil
+		}
+
+		meta, err := block.DownloadMeta(context.Background(), log.NewNopLogger(), userBkt, id)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"external": "1"}, meta.Thanos.Labels)
+		assert.Equal(t, sourceMeta.Compaction.Sources, meta.Compaction.Sources)
+		assert.Equal(t, sourceMeta.MinTime, meta.MinTime)
+		assert.Equal(t, sourceMeta.MaxTime, meta.MaxTime)
+
+		downsampled[meta.Thanos.Downsample.Resolution] = id
+		return nil
+	}))
+	require.Len(t, downsampled, 2)
+
+	minutes := func(values ...int64) []int64 {
+		for i := range values {
+			values[i] *= time.Minute.Milliseconds()
+		}
+		return values
+	}
+
+	samples5m := readBlockFloatSamples(t, userBkt, downsampled[mimir_tsdb.Resolution5m])
+	require.Len(t, samples5m, 5)
+	assert.Equal(t, minutes(4, 9, 14, 19, 24, 29, 34, 39, 44, 49, 54, 59, 64, 69, 74, 79, 84, 89, 94, 99, 104, 109), samples5m[`{__name__="series_a"}`].timestamps)
+	assert.Equal(t, []float64{2, 7}, samples5m[`{__name__="series_a"}`].values[:2])
+	assert.Equal(t, []float64{0, 5}, samples5m[`{__aggregate__="min", __name__="series_a"}`].values[:2])
+	assert.Equal(t, []float64{4, 9}, samples5m[`{__aggregate__="max", __name__="series_a"}`].values[:2])
+	assert.Equal(t, []float64{10, 35}, samples5m[`{__aggregate__="sum", __name__="series_a"}`].values[:2])
+	assert.Equal(t, []float64{5, 5}, samples5m[`{__aggregate__="count", __name__="series_a"}`].values[:2])
+
+	samples1h := readBlockFloatSamples(t, userBkt, downsampled[mimir_tsdb.Resolution1h])
+	assert.Equal(t, map[string]testFloatSamples{
+		`{__name__="series_a"}`:                        {timestamps: minutes(59, 109), values: []float64{29.5, 84.5}},
+		`{__aggregate__="min", __name__="series_a"}`:   {timestamps: minutes(59, 109), values: []float64{0, 60}},
+		`{__aggregate__="max", __name__="series_a"}`:   {timestamps: minutes(59, 109), values: []float64{59, 109}},
+		`{__aggregate__="sum", __name__="series_a"}`:   {timestamps: minutes(59, 109), values: []float64{1770, 4225}},
+		`{__aggregate__="count", __name__="series_a"}`: {timestamps: minutes(59, 109), values: []float64{60, 50}},
+	}, samples1h)
+
+	// The blocks have already been downsampled, so the next run doesn't downsample them again.
+	require.NoError(t, c.downsampleBlocks(context.Background(), userID, userBkt, log.NewNopLogger()))
+	assert.Equal(t, float64(1), testutil.ToFloat64(c.downsampledBlocks.WithLabelValues("3600000")))
+}
+
+func TestBlocksToDownsample(t *testing.T) {
+	now := time.Now()
+	old := now.Add(-2 * time.Hour).UnixMilli()
+	recent := now.Add(-time.Minute).UnixMilli()
+
+	newMeta := func(id ulid.ULID, maxTime, resolution int64, shard string, sources ...ulid.ULID) *block.Meta {
+		meta := &block.Meta{}
+		meta.ULID = id
+		meta.MaxTime = maxTime
+		meta.Compaction.Sources = sources
+		meta.Thanos.Downsample.Resolution = resolution
+		if shard != "" {
+			meta.Thanos.Labels = map[string]string{mimir_tsdb.CompactorShardIDExternalLabel: shard}
+		}
+		return meta
+	}
+
+	var (
+		id1 = ulid.MustNew(1, nil)
+		id2 = ulid.MustNew(2, nil)
+		id3 = ulid.MustNew(3, nil)
+		id4 = ulid.MustNew(4, nil)
+		id5 = ulid.MustNew(5, nil)
+		id6 = ulid.MustNew(6, nil)
+		id7 = ulid.MustNew(7, nil)
+		id8 = ulid.MustNew(8, nil)
+	)
+
+	metas := map[ulid.ULID]*block.Meta{
+		// Raw block already downsampled.
+		id1: newMeta(id1, old, mimir_tsdb.ResolutionRaw, "", id1),
+		id2: newMeta(id2, old, mimir_tsdb.Resolution5m, "", id1),
+		// Raw block compacted from a source which hasn't been downsampled.
+		id3: newMeta(id3, old, mimir_tsdb.ResolutionRaw, "", id1, id3),
+		// Raw block of a shard, covered by the block downsampled without compactor shard ID.
+		id4: newMeta(id4, old, mimir_tsdb.ResolutionRaw, "1_of_2", id1),
+		// Raw block newer than the delay.
+		id5: newMeta(id5, recent, mimir_tsdb.ResolutionRaw, "", id5),
+		// Block downsampled to 1 hour.
+		id6: newMeta(id6, old, mimir_tsdb.Resolution1h, "", id6),
+		// Raw block without compactor shard ID, not covered by the block downsampled from a shard.
+		id7: newMeta(id7, old, mimir_tsdb.ResolutionRaw, "", id7),
+		id8: newMeta(id8, old, mimir_tsdb.Resolution5m, "1_of_2", id7),
+	}
+
+	actual := blocksToDownsample(metas, downsamplingLevel{source: mimir_tsdb.ResolutionRaw, target: mimir_tsdb.Resolution5m, delay: time.Hour}, now)
+	assert.Equal(t, []*block.Meta{metas[id3], metas[id7]}, actual)
+
+	actual = blocksToDownsample(metas, downsamplingLevel{source: mimir_tsdb.Resolution5m, target: mimir_tsdb.Resolution1h, delay: time.Hour}, now)
+	assert.Equal(t, []*block.Meta{metas[id2], metas[id8]}, actual)
+}
+
+func TestDownsampleAggregator(t *testing.T) {
+	lset := labels.FromStrings(labels.MetricName, "series")
+	h1, h2, h3 := tsdbutil.GenerateTestHistogram(1), tsdbutil.GenerateTestHistogram(2), tsdbutil.GenerateTestHistogram(3)
+
+	agg := newDownsampleAggregator(lset, 10)
+	agg.addFloat(1, 1, 1, 1, 1)
+	agg.addHistogram(2, h1, nil)
+	agg.addFloat(5, 3, 3, 3, 1)
+	agg.addHistogram(8, h2, nil)
+	agg.addHistogram(12, h3, nil)
+	agg.addFloat(25, 2, 4, 6, 2)
+
+	assert.Equal(t, []downsampledFloat{
+		{t: 5, avg: 2, minValue: 1, maxValue: 3, sum: 4, count: 2},
+		{t: 25, avg: 3, minValue: 2, maxValue: 4, sum: 6, count: 2},
+	}, agg.floats)
+	assert.Equal(t, []downsampledHistogram{{t: 8, h: h2}, {t: 12, h: h3}}, agg.histograms)
+
+	// The floats and the histograms are appended in timestamp order.
+	app := &appendedSamplesRecorder{}
+	require.NoError(t, agg.appendTo(app))
+	assert.Equal(t, []int64{5, 8, 12, 25}, app.timestamps[lset.String()])
+	assert.Equal(t, []int64{5, 25}, app.timestamps[`{__aggregate__="min", __name__="series"}`])
+}
+
+type testFloatSamples struct {
+	timestamps []int64
+	values     []float64
+}
+
+func readBlockFloatSamples(t *testing.T, bkt objstore.Bucket, id ulid.ULID) map[string]testFloatSamples {
+	dir := filepath.Join(t.TempDir(), id.String())
+	require.NoError(t, block.Download(context.Background(), log.NewNopLogger(), bkt, id, dir))
+
+	b, err := tsdb.OpenBlock(log.NewNopLogger(), dir, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, b.Close()) })
+
+	q, err := tsdb.NewBlockQuerier(b, b.MinTime(), b.MaxTime())
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, q.Close()) })
+
+	out := map[string]testFloatSamples{}
+	set := q.Select(context.Background(), true, nil, labels.MustNewMatcher(labels.MatchRegexp, labels.MetricName, ".+"))
+	for set.Next() {
+		var samples testFloatSamples
+		it := set.At().Iterator(nil)
+		for it.Next() == chunkenc.ValFloat {
+			t, v := it.At()
+			samples.timestamps = append(samples.timestamps, t)
+			samples.values = append(samples.values, v)
+		}
+		require.NoError(t, it.Err())
+		out[set.At().Labels().String()] = samples
+	}
+	require.NoError(t, set.Err())
+
+	return out
+}
+
+// appendedSamplesRecorder is a storage.Appender recording the timestamps of the appended samples by series.
+type appendedSamplesRecorder struct {
+	storage.Appender
+
+	timestamps map[string][]int64
+}
+
+func (a *appendedSamplesRecorder) record(l labels.Labels, t int64) (storage.SeriesRef, error) {
+	if a.timestamps == nil {
+		a.timestamps = map[string][]int64{}
+	}
+	a.timestamps[l.String()] = append(a.timestamps[l.String()], t)
+	return 0, nil
+}
+
+func (a *appendedSamplesRecorder) Append(_ storage.SeriesRef, l labels.Labels, t int64, _ float64) (storage.SeriesRef, error) {
+	return a.record(l, t)
+}
+
+func (a *appendedSamplesRecorder) AppendHistogram(_ storage.SeriesRef, l labels.Labels, t int64, _ *histogram.Histogram, _ *histogram.FloatHistogram) (storage.SeriesRef, error) {
+	return a.record(l, t)
+}
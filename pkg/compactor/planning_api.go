@@ -0,0 +1,279 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package compactor
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/concurrency"
+	"github.com/grafana/dskit/tenant"
+	"github.com/oklog/ulid"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/thanos-io/objstore"
+
+	"github.com/grafana/mimir/pkg/storage/bucket"
+	"github.com/grafana/mimir/pkg/storage/sharding"
+	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
+	"github.com/grafana/mimir/pkg/storage/tsdb/block"
+	"github.com/grafana/mimir/pkg/storage/tsdb/bucketindex"
+	"github.com/grafana/mimir/pkg/util"
+	"github.com/grafana/mimir/pkg/util/extprom"
+	util_log "github.com/grafana/mimir/pkg/util/log"
+)
+
+// Reasons why a block isn't compacted by any of the planned jobs.
+const (
+	skipReasonMarkedForDeletion     = "marked-for-deletion"
+	skipReasonMarkedForNoCompaction = "marked-for-no-compaction"
+	skipReasonDuplicate             = "duplicate"
+	skipReasonMetaNotFound          = "meta-not-found"
+	skipReasonShardMismatch         = "shard-mismatch"
+	skipReasonWaitPeriod            = "compaction-wait-period"
+)
+
+// PlannedJobsResponse is the compaction plan of a tenant as returned by the HTTP API.
+type PlannedJobsResponse struct {
+	// BucketIndexUpdatedAt is the unix timestamp (seconds precision) of the bucket index the plan is based on.
+	BucketIndexUpdatedAt int64 `json:"bucket_index_updated_at"`
+
+	// Jobs are the compaction jobs in the order the compactors run them.
+	Jobs []PlannedJob `json:"jobs"`
+
+	// SkippedBlocks are the blocks that are not compacted, along with the reason why.
+	SkippedBlocks []SkippedBlock `json:"skipped_blocks"`
+}
+
+// PlannedJob is a compaction job as returned by the HTTP API.
+type PlannedJob struct {
+	Key     string   `json:"key"`
+	Stage   string   `json:"stage"`
+	MinTime int64    `json:"min_time"`
+	MaxTime int64    `json:"max_time"`
+	Blocks  []string `json:"blocks"`
+
+	// EstimatedOutputSizeBytes is the sum of the sizes of the input blocks, which is an upper bound of the
+	// size of the blocks produced by the job, since the compaction deduplicates the samples.
+	EstimatedOutputSizeBytes int64 `json:"estimated_output_size_bytes"`
+
+	// Error is set if the planner rejected the job, in which case the compactor fails to run it.
+	Error string `json:"error,omitempty"`
+}
+
+// SkippedBlock is a block that is not compacted by any of the planned jobs.
+type SkippedBlock struct {
+	ID     string `json:"block_id"`
+	Reason string `json:"reason"`
+}
+
+// PlannedJobs returns the compaction jobs of the tenant, planned from its bucket index with the grouper and
+// planner used by the compactor, without running them. The jobs of all the compactors are returned.
+func (c *MultitenantCompactor) PlannedJobs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, err := tenant.TenantID(ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	idx, err := bucketindex.ReadIndex(ctx, c.bucketClient, userID, c.cfgProvider, c.logger)
+	if errors.Is(err, bucketindex.ErrIndexNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	userBucket := bucket.NewUserBucketClient(userID, c.bucketClient, c.cfgProvider)
+	resp, err := c.planJobsFromIndex(ctx, userID, userBucket, idx)
+	if err != nil {
+		level.Error(c.logger).Log("msg", "failed to plan compaction jobs", "user", userID, "err", err)
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	util.WriteJSONResponse(w, resp)
+}
+
+// planJobsFromIndex plans the compaction jobs of the blocks in the bucket index, applying the same filters,
+// wait period and sorting as the compactor.
+func (c *MultitenantCompactor) planJobsFromIndex(ctx context.Context, userID string, userBucket objstore.InstrumentedBucket, idx *bucketindex.Index) (*PlannedJobsResponse, error) {
+	userLogger := util_log.WithUserID(userID, c.logger)
+	resp := &PlannedJobsResponse{
+		BucketIndexUpdatedAt: idx.UpdatedAt,
+		Jobs:                 []PlannedJob{},
+		SkippedBlocks:        []SkippedBlock{},
+	}
+	skip := func(id ulid.ULID, reason string) {
+		resp.SkippedBlocks = append(resp.SkippedBlocks, SkippedBlock{ID: id.String(), Reason: reason})
+	}
+
+	deleted := make(map[ulid.ULID]bool, len(idx.BlockDeletionMarks))
+	for _, id := range idx.BlockDeletionMarks.GetULIDs() {
+		deleted[id] = true
+	}
+
+	var ids []ulid.ULID
+	for _, b := range idx.Blocks {
+		if deleted[b.ID] {
+			skip(b.ID, skipReasonMarkedForDeletion)
+			continue
+		}
+		ids = append(ids, b.ID)
+	}
+
+	// The bucket index doesn't have the external labels, compaction sources and files of the blocks,
+	// so their meta.json is downloaded.
+	metas, notFound, err := downloadMetas(ctx, userBucket, userLogger, ids, c.compactorCfg.MetaSyncConcurrency)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range notFound {
+		skip(id, skipReasonMetaNotFound)
+	}
+
+	// The filters count the filtered blocks in an unregistered gauge.
+	synced := extprom.NewTxGaugeVec(nil, prometheus.GaugeOpts{Name: "synced", Help: "Number of block metadata synced"}, []string{"state"})
+	deduplicateBlocksFilter := NewShardAwareDeduplicateFilter()
+	noCompactionMarkFilter := NewNoCompactionMarkFilter(userBucket, true)
+	for _, f := range []block.MetadataFilter{
+		NewLabelRemoverFilter(compactionRemovedExternalLabels),
+		deduplicateBlocksFilter,
+		noCompactionMarkFilter,
+	} {
+		if err := f.Filter(ctx, metas, synced); err != nil {
+			return nil, errors.Wrap(err, "filter blocks")
+		}
+	}
+	for _, id := range deduplicateBlocksFilter.DuplicateIDs() {
+		skip(id, skipReasonDuplicate)
+	}
+	for id := range noCompactionMarkFilter.NoCompactMarkedBlocks() {
+		skip(id, skipReasonMarkedForNoCompaction)
+	}
+
+	grouper := c.blocksGrouperFactory(ctx, c.compactorCfg, c.cfgProvider, userID, userLogger, prometheus.NewRegistry())
+	jobs, err := grouper.Groups(metas)
+	if err != nil {
+		return nil, errors.Wrap(err, "build compaction jobs")
+	}
+
+	if c.jobsOrder != nil {
+		jobs = c.jobsOrder(jobs)
+	}
+
+	planned := make(map[ulid.ULID]bool, len(metas))
+	for _, job := range jobs {
+		elapsed, notElapsedBlock, err := jobWaitPeriodElapsed(ctx, job, c.compactorCfg.CompactionWaitPeriod, userBucket)
+		if err != nil {
+			// The compactor doesn't enforce the wait period if the check fails.
+			level.Warn(userLogger).Log("msg", "not enforcing compaction wait period because the check if compaction job contains recently uploaded blocks has failed", "groupKey", job.Key(), "err", err)
+		} else if !elapsed {
+			for _, id := range job.IDs() {
+				planned[id] = true
+				skip(id, skipReasonWaitPeriod)
+			}
+			level.Debug(userLogger).Log("msg", "skipping compaction job because blocks in this job were uploaded too recently (within wait period)", "groupKey", job.Key(), "waitPeriodNotElapsedFor", notElapsedBlock.String())
+			continue
+		}
+
+		resp.Jobs = append(resp.Jobs, c.plannedJob(ctx, job))
+		for _, id := range job.IDs() {
+			planned[id] = true
+		}
+	}
+
+	// The blocks of a shard not matching the configured number of shards are only compacted with the blocks
+	// of the same shard, so they may never be.
+	shardCount := uint64(c.cfgProvider.CompactorSplitAndMergeShards(userID))
+	for id, meta := range metas {
+		if planned[id] {
+			continue
+		}
+		if shardID, ok := meta.Thanos.Labels[mimir_tsdb.CompactorShardIDExternalLabel]; ok {
+			if _, count, err := sharding.ParseShardIDLabelValue(shardID); err != nil || count != shardCount {
+				skip(id, skipReasonShardMismatch)
+			}
+		}
+	}
+
+	sort.SliceStable(resp.SkippedBlocks, func(i, j int) bool {
+		return resp.SkippedBlocks[i].ID < resp.SkippedBlocks[j].ID
+	})
+
+	return resp, nil
+}
+
+// plannedJob runs the planner of the compactor on the job, and returns the job as returned by the HTTP API.
+func (c *MultitenantCompactor) plannedJob(ctx context.Context, job *Job) PlannedJob {
+	stage := stageMerge
+	if job.UseSplitting() {
+		stage = stageSplit
+	}
+
+	res := PlannedJob{
+		Key:     job.Key(),
+		Stage:   string(stage),
+		MinTime: job.MinTime(),
+		MaxTime: job.MaxTime(),
+		Blocks:  []string{},
+	}
+
+	toCompact, err := c.blocksPlanner.Plan(ctx, job.Metas())
+	if err != nil {
+		res.Error = err.Error()
+		toCompact = job.Metas()
+	}
+
+	for _, meta := range toCompact {
+		res.Blocks = append(res.Blocks, meta.ULID.String())
+		for _, f := range meta.Thanos.Files {
+			res.EstimatedOutputSizeBytes += f.SizeBytes
+		}
+	}
+
+	// The blocks with the same min time are in random order.
+	slices.Sort(res.Blocks)
+
+	return res
+}
+
+// downloadMetas downloads the meta.json of the blocks, returning the IDs of the blocks without meta.json separately.
+func downloadMetas(ctx context.Context, userBucket objstore.Bucket, logger log.Logger, ids []ulid.ULID, concurrencyLimit int) (map[ulid.ULID]*block.Meta, []ulid.ULID, error) {
+	var (
+		mtx      sync.Mutex
+		metas    = make(map[ulid.ULID]*block.Meta, len(ids))
+		notFound []ulid.ULID
+	)
+
+	err := concurrency.ForEachJob(ctx, len(ids), concurrencyLimit, func(ctx context.Context, i int) error {
+		meta, err := block.DownloadMeta(ctx, logger, userBucket, ids[i])
+
+		mtx.Lock()
+		defer mtx.Unlock()
+
+		if userBucket.IsObjNotFoundErr(errors.Cause(err)) {
+			notFound = append(notFound, ids[i])
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "download meta of block %s", ids[i])
+		}
+		metas[ids[i]] = &meta
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return metas, notFound, nil
+}
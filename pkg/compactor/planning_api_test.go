@@ -0,0 +1,132 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package compactor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/user"
+	"github.com/oklog/ulid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/grafana/mimir/pkg/storage/bucket"
+	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
+	"github.com/grafana/mimir/pkg/storage/tsdb/block"
+	"github.com/grafana/mimir/pkg/storage/tsdb/bucketindex"
+)
+
+func TestMultitenantCompactor_PlannedJobs(t *testing.T) {
+	const userID = "user-1"
+
+	bkt := block.BucketWithGlobalMarkers(objstore.NewInMemBucket())
+	userBkt := bucket.NewUserBucketClient(userID, bkt, nil)
+
+	cfgProvider := newMockConfigProvider()
+	cfgProvider.splitAndMergeShards[userID] = 2
+	cfgProvider.splitGroups[userID] = 1
+
+	// The compactor isn't started, to not compact the blocks, so the clients it creates when starting are set here.
+	cfg := prepareConfig(t)
+	c, _, _, _, _ := prepareWithConfigProvider(t, cfg, bkt, cfgProvider)
+	c.bucketClient = bkt
+	c.blocksPlanner = NewSplitAndMergePlanner(cfg.BlockRanges.ToMilliseconds())
+
+	ctx := user.InjectOrgID(context.Background(), userID)
+
+	plannedJobs := func() (int, PlannedJobsResponse) {
+		resp := httptest.NewRecorder()
+		c.PlannedJobs(resp, httptest.NewRequest(http.MethodGet, "/compactor/planned_jobs", nil).WithContext(ctx))
+
+		var res PlannedJobsResponse
+		if resp.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &res))
+		}
+		return resp.Code, res
+	}
+
+	t.Run("should return 404 if the tenant has no bucket index", func(t *testing.T) {
+		code, _ := plannedJobs()
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+
+	var (
+		toSplit1        = uploadPlanningTestMeta(t, userBkt, "01H0000000000000000000000A", 0, 2*time.Hour, 100, nil)
+		toSplit2        = uploadPlanningTestMeta(t, userBkt, "01H0000000000000000000000B", 0, 2*time.Hour, 200, nil)
+		deleted         = uploadPlanningTestMeta(t, userBkt, "01H0000000000000000000000C", 0, 2*time.Hour, 300, nil)
+		noCompact       = uploadPlanningTestMeta(t, userBkt, "01H0000000000000000000000D", 0, 2*time.Hour, 400, nil)
+		shardMismatch   = uploadPlanningTestMeta(t, userBkt, "01H0000000000000000000000E", 2*time.Hour, 4*time.Hour, 500, map[string]string{mimir_tsdb.CompactorShardIDExternalLabel: "1_of_4"})
+		withoutMetaJSON = ulid.MustParse("01H0000000000000000000000F")
+	)
+	require.NoError(t, block.MarkForNoCompact(context.Background(), log.NewNopLogger(), userBkt, noCompact, block.ManualNoCompactReason, "", prometheus.NewCounter(prometheus.CounterOpts{})))
+
+	idx := &bucketindex.Index{
+		Version:            bucketindex.IndexVersion3,
+		BlockDeletionMarks: bucketindex.BlockDeletionMarks{{ID: deleted, DeletionTime: time.Now().Unix()}},
+		UpdatedAt:          time.Now().Unix(),
+	}
+	for _, id := range []ulid.ULID{toSplit1, toSplit2, deleted, noCompact, shardMismatch, withoutMetaJSON} {
+		idx.Blocks = append(idx.Blocks, &bucketindex.Block{ID: id})
+	}
+	require.NoError(t, bucketindex.WriteIndex(context.Background(), bkt, userID, nil, idx))
+
+	t.Run("should return the planned jobs and the skipped blocks", func(t *testing.T) {
+		code, res := plannedJobs()
+		require.Equal(t, http.StatusOK, code)
+
+		assert.Equal(t, idx.UpdatedAt, res.BucketIndexUpdatedAt)
+		assert.Equal(t, []PlannedJob{{
+			Key:                      "0@17241709254077376921-split-1_of_1-0-7200000",
+			Stage:                    "split",
+			MinTime:                  0,
+			MaxTime:                  2 * time.Hour.Milliseconds(),
+			Blocks:                   []string{toSplit1.String(), toSplit2.String()},
+			EstimatedOutputSizeBytes: 300,
+		}}, res.Jobs)
+		assert.Equal(t, []SkippedBlock{
+			{ID: deleted.String(), Reason: skipReasonMarkedForDeletion},
+			{ID: noCompact.String(), Reason: skipReasonMarkedForNoCompaction},
+			{ID: shardMismatch.String(), Reason: skipReasonShardMismatch},
+			{ID: withoutMetaJSON.String(), Reason: skipReasonMetaNotFound},
+		}, res.SkippedBlocks)
+	})
+
+	t.Run("should skip the jobs whose wait period hasn't elapsed", func(t *testing.T) {
+		c.compactorCfg.CompactionWaitPeriod = time.Hour
+		t.Cleanup(func() { c.compactorCfg.CompactionWaitPeriod = 0 })
+
+		code, res := plannedJobs()
+		require.Equal(t, http.StatusOK, code)
+
+		assert.Empty(t, res.Jobs)
+		assert.Equal(t, []SkippedBlock{
+			{ID: toSplit1.String(), Reason: skipReasonWaitPeriod},
+			{ID: toSplit2.String(), Reason: skipReasonWaitPeriod},
+			{ID: deleted.String(), Reason: skipReasonMarkedForDeletion},
+			{ID: noCompact.String(), Reason: skipReasonMarkedForNoCompaction},
+			{ID: shardMismatch.String(), Reason: skipReasonShardMismatch},
+			{ID: withoutMetaJSON.String(), Reason: skipReasonMetaNotFound},
+		}, res.SkippedBlocks)
+	})
+}
+
+func uploadPlanningTestMeta(t *testing.T, userBkt objstore.Bucket, id string, minT, maxT time.Duration, sizeBytes int64, lbls map[string]string) ulid.ULID {
+	meta := blockMeta(id, minT.Milliseconds(), maxT.Milliseconds(), lbls)
+	meta.Thanos.Files = []block.File{{RelPath: block.IndexFilename, SizeBytes: sizeBytes}}
+
+	data, err := json.Marshal(meta)
+	require.NoError(t, err)
+	require.NoError(t, userBkt.Upload(context.Background(), path.Join(id, block.MetaFilename), bytes.NewReader(data)))
+
+	return meta.ULID
+}
@@ -0,0 +1,369 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package compactor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/oklog/ulid"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/prometheus/prometheus/tsdb/index"
+	"github.com/prometheus/prometheus/tsdb/tombstones"
+	"github.com/thanos-io/objstore"
+
+	"github.com/grafana/mimir/pkg/storage/tsdb/block"
+	"github.com/grafana/mimir/pkg/util/validation"
+)
+
+const (
+	retentionRulesRewriteReason = "retention rules"
+
+	// retentionRulesStatePath is the location of the state of the retention rules applied to the tenant's blocks,
+	// relative to the user-specific prefix.
+	retentionRulesStatePath = "markers/retention-rules-state.json"
+)
+
+// retentionPolicy computes the retention period of each series of a tenant from its retention rules.
+type retentionPolicy struct {
+	rules         []retentionRule
+	defaultPeriod time.Duration
+
+	// key identifies the policy, so that the blocks are checked again when the policy changes.
+	key string
+}
+
+type retentionRule struct {
+	matchers []*labels.Matcher
+	period   time.Duration
+}
+
+func newRetentionPolicy(rules []*validation.CompactorRetentionRule, defaultPeriod time.Duration) (*retentionPolicy, error) {
+	p := &retentionPolicy{defaultPeriod: defaultPeriod}
+
+	var key strings.Builder
+	for _, r := range rules {
+		matchers, err := parser.ParseMetricSelector(r.Selector)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse retention rule selector %q", r.Selector)
+		}
+		p.rules = append(p.rules, retentionRule{matchers: matchers, period: time.Duration(r.Period)})
+
+		key.WriteString(r.Selector)
+		key.WriteString("=")
+		key.WriteString(r.Period.String())
+		key.WriteString(";")
+	}
+	key.WriteString(defaultPeriod.String())
+	p.key = key.String()
+
+	return p, nil
+}
+
+// period returns the retention period of the series, 0 if the series is kept forever.
+func (p *retentionPolicy) period(lset labels.Labels) time.Duration {
+	for _, r := range p.rules {
+		if matchesAll(r.matchers, lset) {
+			return r.period
+		}
+	}
+	return p.defaultPeriod
+}
+
+// exceededBetween returns whether a retention period of the policy is exceeded by a block of age age, but wasn't
+// by the same block of age prevAge.
+func (p *retentionPolicy) exceededBetween(prevAge, age time.Duration) bool {
+	exceeded := func(period time.Duration) bool {
+		return period > 0 && prevAge <= period && period < age
+	}
+
+	for _, r := range p.rules {
+		if exceeded(r.period) {
+			return true
+		}
+	}
+	return exceeded(p.defaultPeriod)
+}
+
+func matchesAll(matchers []*labels.Matcher, lset labels.Labels) bool {
+	for _, m := range matchers {
+		if !m.Matches(lset.Get(m.Name)) {
+			return false
+		}
+	}
+	return true
+}
+
+// maxRetentionPeriod returns the longest retention period of the tenant's series, given the retention period of
+// the series not matching any retention rule. It returns 0 if some series are kept forever.
+func maxRetentionPeriod(retention time.Duration, rules []*validation.CompactorRetentionRule) time.Duration {
+	if retention <= 0 {
+		return 0
+	}
+
+	for _, r := range rules {
+		if r.Period <= 0 {
+			return 0
+		}
+		if period := time.Duration(r.Period); period > retention {
+			retention = period
+		}
+	}
+	return retention
+}
+
+// retentionRulesState records when the retention policy has been applied to each block of the tenant, so that a
+// block is only downloaded again once it exceeds another retention period of the policy.
+type retentionRulesState struct {
+	// Policy is the key of the retention policy applied to the blocks.
+	Policy string `json:"policy"`
+
+	// Blocks maps the ID of each block to the time, in milliseconds, when the policy was last applied to it.
+	Blocks map[string]int64 `json:"blocks"`
+}
+
+func readRetentionRulesState(ctx context.Context, userBucket objstore.InstrumentedBucket) (*retentionRulesState, error) {
+	state := &retentionRulesState{Blocks: map[string]int64{}}
+
+	r, err := userBucket.ReaderWithExpectedErrs(userBucket.IsObjNotFoundErr).Get(ctx, retentionRulesStatePath)
+	if userBucket.IsObjNotFoundErr(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "read retention rules state")
+	}
+	defer func() { _ = r.Close() }()
+
+	if err := json.NewDecoder(r).Decode(state); err != nil {
+		return nil, errors.Wrap(err, "decode retention rules state")
+	}
+	if state.Blocks == nil {
+		state.Blocks = map[string]int64{}
+	}
+	return state, nil
+}
+
+func writeRetentionRulesState(ctx context.Context, userBucket objstore.Bucket, state *retentionRulesState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return errors.Wrap(err, "serialize retention rules state")
+	}
+
+	return errors.Wrap(userBucket.Upload(ctx, retentionRulesStatePath, bytes.NewReader(data)), "upload retention rules state")
+}
+
+// applyRetentionRules rewrites the tenant's blocks to delete the series exceeding the retention period of their
+// retention rule, or of the tenant if they don't match any rule.
+//
+// A block is checked when it exceeds a retention period of the tenant's retention policy it didn't exceed when
+// the policy was last applied to it, or when the policy changes. The blocks exceeding the longest retention period
+// are skipped, because they are deleted as a whole by the blocks cleaner.
+//
+// Only the compactor running the blocks cleaner for the tenant applies the retention rules, so that a block is
+// never rewritten by multiple compactors concurrently.
+func (c *MultitenantCompactor) applyRetentionRules(ctx context.Context, userID string, userBucket objstore.InstrumentedBucket, logger log.Logger) error {
+	rules := c.cfgProvider.CompactorRetentionRules(userID)
+	if len(rules) == 0 {
+		return nil
+	}
+
+	if owned, err := c.shardingStrategy.blocksCleanerOwnUser(userID); err != nil {
+		// Retention rules will be applied at the next compaction run.
+		level.Warn(logger).Log("msg", "unable to check if user is owned by this shard for retention rules", "err", err)
+		return nil
+	} else if !owned {
+		return nil
+	}
+
+	retention := c.cfgProvider.CompactorBlocksRetentionPeriod(userID)
+	policy, err := newRetentionPolicy(rules, retention)
+	if err != nil {
+		return err
+	}
+	maxPeriod := maxRetentionPeriod(retention, rules)
+
+	state, err := readRetentionRulesState(ctx, userBucket)
+	if err != nil {
+		return err
+	}
+	if state.Policy != policy.key {
+		state = &retentionRulesState{Policy: policy.key, Blocks: map[string]int64{}}
+	}
+
+	// Like the series deletion, we need to look at all blocks, including the ones marked for no-compaction.
+	fetcher, err := block.NewMetaFetcher(logger, c.compactorCfg.MetaSyncConcurrency, userBucket, c.metaSyncDirForUser(userID), nil, nil)
+	if err != nil {
+		return err
+	}
+
+	metas, _, err := fetcher.FetchWithoutMarkedForDeletion(ctx)
+	if err != nil {
+		return errors.Wrap(err, "fetch metas")
+	}
+
+	for id := range state.Blocks {
+		if parsed, err := ulid.Parse(id); err != nil || metas[parsed] == nil {
+			delete(state.Blocks, id)
+		}
+	}
+
+	now := time.Now()
+	var rewriteErr error
+
+	for _, meta := range metas {
+		if ctx.Err() != nil {
+			rewriteErr = ctx.Err()
+			break
+		}
+
+		maxTime := time.UnixMilli(meta.MaxTime)
+		age := now.Sub(maxTime)
+		if maxPeriod > 0 && age > maxPeriod {
+			continue
+		}
+
+		var prevAge time.Duration
+		if appliedAt, ok := state.Blocks[meta.ULID.String()]; ok {
+			prevAge = time.UnixMilli(appliedAt).Sub(maxTime)
+		}
+		if !policy.exceededBetween(prevAge, age) {
+			continue
+		}
+
+		newID, err := c.rewriteBlockWithRetentionRules(ctx, userBucket, meta, policy, age, log.With(logger, "block", meta.ULID.String()))
+		if err != nil {
+			rewriteErr = errors.Wrapf(err, "rewrite block %s", meta.ULID.String())
+			break
+		}
+
+		delete(state.Blocks, meta.ULID.String())
+		if newID != (ulid.ULID{}) {
+			state.Blocks[newID.String()] = now.UnixMilli()
+		}
+	}
+
+	// Record the blocks the policy has been applied to even if a block failed, not to check them again.
+	if err := writeRetentionRulesState(ctx, userBucket, state); err != nil {
+		return err
+	}
+	return rewriteErr
+}
+
+// rewriteBlockWithRetentionRules downloads the block, removes the series exceeding their retention period and
+// uploads the resulting block, marking the original one for deletion. It returns the ID of the resulting block,
+// which is the ID of the input block if it has no series to remove, or the zero ULID if all series have been removed.
+func (c *MultitenantCompactor) rewriteBlockWithRetentionRules(ctx context.Context, userBucket objstore.Bucket, meta *block.Meta, policy *retentionPolicy, age time.Duration, logger log.Logger) (ulid.ULID, error) {
+	baseDir := filepath.Join(c.compactorCfg.DataDir, "retention-rules")
+	if err := os.MkdirAll(baseDir, os.ModePerm); err != nil {
+		return ulid.ULID{}, errors.Wrap(err, "create retention rules directory")
+	}
+
+	tmpDir, err := os.MkdirTemp(baseDir, meta.ULID.String()+"-")
+	if err != nil {
+		return ulid.ULID{}, errors.Wrap(err, "create temporary directory")
+	}
+	defer func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			level.Warn(logger).Log("msg", "failed to remove retention rules temporary directory", "dir", tmpDir, "err", err)
+		}
+	}()
+
+	bdir := filepath.Join(tmpDir, meta.ULID.String())
+	if err := block.Download(ctx, logger, userBucket, meta.ULID, bdir); err != nil {
+		return ulid.ULID{}, errors.Wrap(err, "download block")
+	}
+
+	numTombstones, err := writeRetentionRulesTombstones(ctx, bdir, meta, policy, age, logger)
+	if err != nil {
+		return ulid.ULID{}, err
+	}
+
+	if numTombstones == 0 {
+		level.Debug(logger).Log("msg", "block has no series exceeding their retention period")
+		return meta.ULID, nil
+	}
+
+	comp, err := tsdb.NewLeveledCompactor(ctx, nil, logger, []int64{meta.MaxTime - meta.MinTime}, nil, nil, true)
+	if err != nil {
+		return ulid.ULID{}, errors.Wrap(err, "create compactor")
+	}
+
+	newID, err := comp.Compact(tmpDir, []string{bdir}, nil)
+	if err != nil {
+		return ulid.ULID{}, errors.Wrap(err, "compact block")
+	}
+
+	// All series of the block have exceeded their retention period, so no block has been written.
+	if newID == (ulid.ULID{}) {
+		level.Info(logger).Log("msg", "all series of the block have exceeded their retention period")
+		return ulid.ULID{}, markRewrittenSourceBlockForDeletion(userBucket, meta.ULID, retentionRulesRewriteReason, c.retentionRulesBlocksMarkedForDeletion, logger)
+	}
+
+	if err := uploadRewrittenBlock(ctx, userBucket, filepath.Join(tmpDir, newID.String()), meta, nil, logger); err != nil {
+		return ulid.ULID{}, err
+	}
+
+	c.retentionRulesBlocksRewritten.Inc()
+	level.Info(logger).Log("msg", "rewritten block applying retention rules", "new_block", newID.String(), "deleted_series", numTombstones)
+
+	return newID, markRewrittenSourceBlockForDeletion(userBucket, meta.ULID, retentionRulesRewriteReason, c.retentionRulesBlocksMarkedForDeletion, logger)
+}
+
+// writeRetentionRulesTombstones writes the tombstones deleting the series exceeding their retention period to the
+// block in bdir, and returns the number of series deleted.
+func writeRetentionRulesTombstones(ctx context.Context, bdir string, meta *block.Meta, policy *retentionPolicy, age time.Duration, logger log.Logger) (_ uint64, returnErr error) {
+	ir, err := index.NewFileReader(filepath.Join(bdir, block.IndexFilename))
+	if err != nil {
+		return 0, errors.Wrap(err, "open block index")
+	}
+	defer func() {
+		if err := ir.Close(); err != nil && returnErr == nil {
+			returnErr = errors.Wrap(err, "close block index")
+		}
+	}()
+
+	name, value := index.AllPostingsKey()
+	postings, err := ir.Postings(ctx, name, value)
+	if err != nil {
+		return 0, errors.Wrap(err, "read postings")
+	}
+
+	var (
+		stones  = tombstones.NewMemTombstones()
+		builder labels.ScratchBuilder
+		deleted uint64
+	)
+
+	for postings.Next() {
+		if err := ir.Series(postings.At(), &builder, nil); err != nil {
+			return 0, errors.Wrap(err, "read series")
+		}
+
+		if period := policy.period(builder.Labels()); period > 0 && age > period {
+			stones.AddInterval(postings.At(), tombstones.Interval{Mint: meta.MinTime, Maxt: meta.MaxTime})
+			deleted++
+		}
+	}
+	if err := postings.Err(); err != nil {
+		return 0, errors.Wrap(err, "iterate postings")
+	}
+
+	if deleted == 0 {
+		return 0, nil
+	}
+
+	if _, err := tombstones.WriteFile(logger, bdir, stones); err != nil {
+		return 0, errors.Wrap(err, "write tombstones")
+	}
+	return deleted, nil
+}
@@ -0,0 +1,160 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package compactor
+
+import (
+	"context"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/services"
+	"github.com/oklog/ulid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/grafana/mimir/pkg/storage/bucket"
+	"github.com/grafana/mimir/pkg/storage/tsdb/block"
+	"github.com/grafana/mimir/pkg/util/validation"
+)
+
+func TestMultitenantCompactor_ShouldApplyRetentionRules(t *testing.T) {
+	const userID = "user-1"
+
+	bkt := objstore.NewInMemBucket()
+	userBkt := bucket.NewUserBucketClient(userID, bkt, nil)
+
+	createBlock := func(age time.Duration, series ...labels.Labels) ulid.ULID {
+		ts := time.Now().Add(-age).UnixMilli()
+		return createCustomTSDBBlock(t, bkt, userID, map[string]string{"external": "1"}, func(db *tsdb.DB) {
+			app := db.Appender(context.Background())
+			for _, lbls := range series {
+				_, err := app.Append(0, lbls, ts, 1)
+				require.NoError(t, err)
+			}
+			require.NoError(t, app.Commit())
+		})
+	}
+
+	var (
+		debugSeries = labels.FromStrings(labels.MetricName, "debug_requests")
+		prodSeries  = labels.FromStrings(labels.MetricName, "up", "env", "prod")
+		devSeries   = labels.FromStrings(labels.MetricName, "up", "env", "dev")
+	)
+
+	// The block of the last day doesn't exceed any retention period.
+	recentBlockID := createBlock(24*time.Hour, debugSeries, prodSeries, devSeries)
+	// The block of 10 days ago exceeds the retention period of the debug series.
+	debugExpiredBlockID := createBlock(10*24*time.Hour, debugSeries, prodSeries, devSeries)
+	// The block of 60 days ago exceeds the retention period of the tenant too.
+	tenantExpiredBlockID := createBlock(60*24*time.Hour, debugSeries, prodSeries, devSeries)
+
+	cfgProvider := newMockConfigProvider()
+	cfgProvider.userRetentionPeriods[userID] = 30 * 24 * time.Hour
+	cfgProvider.retentionRules[userID] = []*validation.CompactorRetentionRule{
+		{Selector: `{__name__=~"debug_.*"}`, Period: model.Duration(7 * 24 * time.Hour)},
+		{Selector: `{env="prod"}`, Period: model.Duration(400 * 24 * time.Hour)},
+	}
+
+	c, _, tsdbPlanner, _, registry := prepareWithConfigProvider(t, prepareConfig(t), bkt, cfgProvider)
+
+	// Compaction jobs are planned but no block is compacted.
+	tsdbPlanner.On("Plan", mock.Anything, mock.Anything).Return([]*block.Meta{}, nil)
+
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), c))
+	t.Cleanup(stopServiceFn(t, c))
+
+	// Wait until the first compaction run has completed.
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(c.compactionRunsCompleted) > 0
+	}, 10*time.Second, 100*time.Millisecond)
+
+	// Running again doesn't rewrite the blocks already rewritten.
+	require.NoError(t, c.applyRetentionRules(context.Background(), userID, userBkt, log.NewNopLogger()))
+
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+		# HELP cortex_compactor_retention_rules_blocks_rewritten_total Total number of blocks rewritten by the compactor to delete the series exceeding their retention period.
+		# TYPE cortex_compactor_retention_rules_blocks_rewritten_total counter
+		cortex_compactor_retention_rules_blocks_rewritten_total 2
+	`), "cortex_compactor_retention_rules_blocks_rewritten_total"))
+
+	for id, expectedMarked := range map[ulid.ULID]bool{recentBlockID: false, debugExpiredBlockID: true, tenantExpiredBlockID: true} {
+		exists, err := userBkt.Exists(context.Background(), path.Join(id.String(), block.DeletionMarkFilename))
+		require.NoError(t, err)
+		assert.Equal(t, expectedMarked, exists, id.String())
+	}
+
+	// Find the rewritten blocks by their max time.
+	rewritten := map[int64][]string{}
+	require.NoError(t, userBkt.Iter(context.Background(), "", func(name string) error {
+		id, ok := block.IsBlockDir(name)
+		if !ok || id == recentBlockID || id == debugExpiredBlockID || id == tenantExpiredBlockID {
+			return nil
+		}
+
+		meta, err := block.DownloadMeta(context.Background(), log.NewNopLogger(), userBkt, id)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"external": "1"}, meta.Thanos.Labels)
+
+		for lbls := range readBlockSamplesTimestamps(t, userBkt, id) {
+			rewritten[meta.MaxTime] = append(rewritten[meta.MaxTime], lbls)
+		}
+		return nil
+	}))
+
+	readMaxTime := func(id ulid.ULID) int64 {
+		meta, err := block.DownloadMeta(context.Background(), log.NewNopLogger(), userBkt, id)
+		require.NoError(t, err)
+		return meta.MaxTime
+	}
+
+	assert.ElementsMatch(t, []string{prodSeries.String(), devSeries.String()}, rewritten[readMaxTime(debugExpiredBlockID)])
+	assert.ElementsMatch(t, []string{prodSeries.String()}, rewritten[readMaxTime(tenantExpiredBlockID)])
+
+	state, err := readRetentionRulesState(context.Background(), userBkt)
+	require.NoError(t, err)
+	assert.Len(t, state.Blocks, 2)
+	assert.NotContains(t, state.Blocks, recentBlockID.String())
+}
+
+func TestRetentionPolicy(t *testing.T) {
+	const day = 24 * time.Hour
+
+	policy, err := newRetentionPolicy([]*validation.CompactorRetentionRule{
+		{Selector: `{__name__=~"debug_.*"}`, Period: model.Duration(7 * day)},
+		{Selector: `{env="prod"}`, Period: model.Duration(400 * day)},
+		{Selector: `{env="audit"}`, Period: 0},
+	}, 30*day)
+	require.NoError(t, err)
+
+	// The first matching rule applies.
+	assert.Equal(t, 7*day, policy.period(labels.FromStrings(labels.MetricName, "debug_requests", "env", "prod")))
+	assert.Equal(t, 400*day, policy.period(labels.FromStrings(labels.MetricName, "up", "env", "prod")))
+	assert.Equal(t, time.Duration(0), policy.period(labels.FromStrings(labels.MetricName, "up", "env", "audit")))
+	assert.Equal(t, 30*day, policy.period(labels.FromStrings(labels.MetricName, "up", "env", "dev")))
+
+	assert.False(t, policy.exceededBetween(0, 5*day))
+	assert.True(t, policy.exceededBetween(0, 8*day))
+	assert.False(t, policy.exceededBetween(8*day, 20*day))
+	assert.True(t, policy.exceededBetween(8*day, 31*day))
+	assert.False(t, policy.exceededBetween(31*day, 399*day))
+	assert.True(t, policy.exceededBetween(31*day, 401*day))
+}
+
+func TestMaxRetentionPeriod(t *testing.T) {
+	const day = 24 * time.Hour
+
+	assert.Equal(t, 30*day, maxRetentionPeriod(30*day, nil))
+	assert.Equal(t, 30*day, maxRetentionPeriod(30*day, []*validation.CompactorRetentionRule{{Selector: "debug", Period: model.Duration(7 * day)}}))
+	assert.Equal(t, 400*day, maxRetentionPeriod(30*day, []*validation.CompactorRetentionRule{{Selector: "up", Period: model.Duration(400 * day)}}))
+	assert.Equal(t, time.Duration(0), maxRetentionPeriod(30*day, []*validation.CompactorRetentionRule{{Selector: "up", Period: 0}}))
+	assert.Equal(t, time.Duration(0), maxRetentionPeriod(0, []*validation.CompactorRetentionRule{{Selector: "debug", Period: model.Duration(7 * day)}}))
+}
@@ -0,0 +1,613 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package compactor
+
+import (
+	"context"
+	"crypto/rand"
+	_ "embed" // Used to embed html template
+	"flag"
+	"html/template"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/grpcclient"
+	"github.com/grafana/dskit/services"
+	"github.com/oklog/ulid"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/thanos-io/objstore"
+
+	"github.com/grafana/mimir/pkg/compactor/compactorschedulerpb"
+	"github.com/grafana/mimir/pkg/storage/bucket"
+	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
+	"github.com/grafana/mimir/pkg/storage/tsdb/block"
+	"github.com/grafana/mimir/pkg/util"
+	util_log "github.com/grafana/mimir/pkg/util/log"
+)
+
+var (
+	errInvalidSchedulerJobLeaseDuration = errors.New("invalid compactor-scheduler job lease duration, must be positive")
+	errInvalidSchedulerMaxJobAttempts   = errors.New("invalid compactor-scheduler max job attempts, must be positive")
+	errJobNotLeased                     = errors.New("the compaction job is not leased to the compactor")
+)
+
+// SchedulerConfig configures the compactor-scheduler, and the compactors running the jobs it plans.
+type SchedulerConfig struct {
+	Address          string            `yaml:"address" category:"experimental"`
+	GRPCClientConfig grpcclient.Config `yaml:"grpc_client_config" doc:"description=Configures the gRPC client used to communicate between the compactors and the compactor-scheduler."`
+	JobLeaseDuration time.Duration     `yaml:"job_lease_duration" category:"experimental"`
+	MaxJobAttempts   int               `yaml:"max_job_attempts" category:"experimental"`
+
+	// How long the compactors wait before asking for a job again when there's no job to run.
+	// No need to make it configurable, but allow to override it in tests.
+	jobPollInterval time.Duration `yaml:"-"`
+}
+
+// RegisterFlags registers the SchedulerConfig flags.
+func (cfg *SchedulerConfig) RegisterFlags(f *flag.FlagSet) {
+	cfg.jobPollInterval = 5 * time.Second
+
+	f.StringVar(&cfg.Address, "compactor.scheduler.address", "", "Address of the compactor-scheduler. If set, the compactors don't plan the compaction jobs, and run the jobs leased by the compactor-scheduler instead.")
+	cfg.GRPCClientConfig.RegisterFlagsWithPrefix("compactor.scheduler.grpc-client-config", f)
+	f.DurationVar(&cfg.JobLeaseDuration, "compactor.scheduler.job-lease-duration", 2*time.Minute, "How long the compactor-scheduler leases a compaction job to a compactor. The compactor renews the lease while running the job, and the job is leased to another compactor if the lease expires.")
+	f.IntVar(&cfg.MaxJobAttempts, "compactor.scheduler.max-job-attempts", 3, "Maximum number of times the compactor-scheduler leases a compaction job that failed or whose lease expired. Once reached, the job is dropped until the next planning.")
+}
+
+// Validate the SchedulerConfig.
+func (cfg *SchedulerConfig) Validate() error {
+	if cfg.JobLeaseDuration <= 0 {
+		return errInvalidSchedulerJobLeaseDuration
+	}
+	if cfg.MaxJobAttempts <= 0 {
+		return errInvalidSchedulerMaxJobAttempts
+	}
+	return cfg.GRPCClientConfig.Validate()
+}
+
+// enabled returns whether the compaction jobs are planned by the compactor-scheduler.
+func (cfg *SchedulerConfig) enabled() bool {
+	return cfg.Address != ""
+}
+
+// scheduledJob is a compaction job planned by the Scheduler.
+type scheduledJob struct {
+	id       string
+	job      *Job
+	attempts int
+	queuedAt time.Time
+
+	// Set while the job is leased.
+	workerID       string
+	leasedAt       time.Time
+	leaseExpiresAt time.Time
+}
+
+// Scheduler is the compactor-scheduler. It plans the compaction jobs of all the tenants and leases them to
+// the compactors, which run the jobs without planning them. The jobs of the tenants are leased in round-robin.
+type Scheduler struct {
+	services.Service
+
+	cfg         Config
+	cfgProvider ConfigProvider
+	logger      log.Logger
+
+	bucketClientFactory  func(ctx context.Context) (objstore.Bucket, error)
+	blocksGrouperFactory BlocksGrouperFactory
+	bucketClient         objstore.Bucket
+	allowedTenants       *util.AllowedTenants
+	jobsOrder            JobsOrderFunc
+
+	// The tenants to plan again because all their jobs have been run.
+	replanCh chan string
+
+	mtx sync.Mutex
+	// Queued jobs by tenant, in the order they're leased.
+	queues map[string][]*scheduledJob
+	// Tenants with queued jobs, in the order their next job is leased.
+	tenants []string
+	// Leased jobs by ID.
+	runningJobs map[string]*scheduledJob
+
+	// Metrics.
+	tenantPlanningFailed    prometheus.Counter
+	jobsLeased              prometheus.Counter
+	jobsCompleted           *prometheus.CounterVec
+	jobsLeaseExpired        prometheus.Counter
+	jobsDropped             prometheus.Counter
+	blocksMarkedForDeletion prometheus.Counter
+
+	// The BucketCompactor metrics tracked while planning aren't registered, because they would collide
+	// with the compactor ones if both run in the same process.
+	bucketCompactorMetrics *BucketCompactorMetrics
+}
+
+// NewScheduler makes a new Scheduler.
+func NewScheduler(compactorCfg Config, storageCfg mimir_tsdb.BlocksStorageConfig, cfgProvider ConfigProvider, logger log.Logger, registerer prometheus.Registerer) (*Scheduler, error) {
+	bucketClientFactory := func(ctx context.Context) (objstore.Bucket, error) {
+		return bucket.NewClient(ctx, storageCfg.Bucket, "compactor-scheduler", logger, registerer)
+	}
+
+	// Configure the grouper factory only if it wasn't already set by a downstream project.
+	if compactorCfg.BlocksGrouperFactory == nil {
+		configureSplitAndMergeCompactor(&compactorCfg)
+	}
+
+	return newScheduler(compactorCfg, cfgProvider, logger, registerer, bucketClientFactory)
+}
+
+func newScheduler(
+	compactorCfg Config,
+	cfgProvider ConfigProvider,
+	logger log.Logger,
+	registerer prometheus.Registerer,
+	bucketClientFactory func(ctx context.Context) (objstore.Bucket, error),
+) (*Scheduler, error) {
+	s := &Scheduler{
+		cfg:                  compactorCfg,
+		cfgProvider:          cfgProvider,
+		logger:               log.With(logger, "component", "compactor-scheduler"),
+		bucketClientFactory:  bucketClientFactory,
+		blocksGrouperFactory: compactorCfg.BlocksGrouperFactory,
+		allowedTenants:       util.NewAllowedTenants(compactorCfg.EnabledTenants, compactorCfg.DisabledTenants),
+		jobsOrder:            GetJobsOrderFunction(compactorCfg.CompactionJobsOrder),
+		replanCh:             make(chan string, 1024),
+		queues:               map[string][]*scheduledJob{},
+		runningJobs:          map[string]*scheduledJob{},
+
+		tenantPlanningFailed: promauto.With(registerer).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_compactor_scheduler_tenant_planning_failed_total",
+			Help: "Total number of times the compactor-scheduler failed to plan the compaction jobs of a tenant.",
+		}),
+		jobsLeased: promauto.With(registerer).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_compactor_scheduler_jobs_leased_total",
+			Help: "Total number of compaction jobs leased to the compactors.",
+		}),
+		jobsCompleted: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_compactor_scheduler_jobs_completed_total",
+			Help: "Total number of compaction jobs reported as completed by the compactors.",
+		}, []string{"outcome"}),
+		jobsLeaseExpired: promauto.With(registerer).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_compactor_scheduler_jobs_lease_expired_total",
+			Help: "Total number of compaction jobs whose lease expired before the compactor completed them.",
+		}),
+		jobsDropped: promauto.With(registerer).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_compactor_scheduler_jobs_dropped_total",
+			Help: "Total number of compaction jobs dropped because they reached the maximum number of attempts.",
+		}),
+		blocksMarkedForDeletion: promauto.With(registerer).NewCounter(prometheus.CounterOpts{
+			Name:        blocksMarkedForDeletionName,
+			Help:        blocksMarkedForDeletionHelp,
+			ConstLabels: prometheus.Labels{"reason": "compactor-scheduler"},
+		}),
+	}
+
+	if s.jobsOrder == nil {
+		return nil, errInvalidCompactionOrder
+	}
+
+	s.bucketCompactorMetrics = NewBucketCompactorMetrics(s.blocksMarkedForDeletion, nil)
+
+	promauto.With(registerer).NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "cortex_compactor_scheduler_jobs_queued",
+		Help: "Number of compaction jobs waiting to be leased to a compactor.",
+	}, func() float64 {
+		s.mtx.Lock()
+		defer s.mtx.Unlock()
+
+		queued := 0
+		for _, queue := range s.queues {
+			queued += len(queue)
+		}
+		return float64(queued)
+	})
+	promauto.With(registerer).NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "cortex_compactor_scheduler_jobs_running",
+		Help: "Number of compaction jobs leased to a compactor.",
+	}, func() float64 {
+		s.mtx.Lock()
+		defer s.mtx.Unlock()
+		return float64(len(s.runningJobs))
+	})
+
+	s.Service = services.NewBasicService(s.starting, s.running, nil)
+	return s, nil
+}
+
+func (s *Scheduler) starting(ctx context.Context) error {
+	bucketClient, err := s.bucketClientFactory(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to create bucket client")
+	}
+
+	// Wrap the bucket client to write block deletion marks in the global location too.
+	s.bucketClient = block.BucketWithGlobalMarkers(bucketClient)
+	return nil
+}
+
+func (s *Scheduler) running(ctx context.Context) error {
+	// Plan the jobs before starting the interval.
+	s.planUsers(ctx)
+
+	ticker := time.NewTicker(util.DurationWithJitter(s.cfg.CompactionInterval, 0.05))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.planUsers(ctx)
+		case userID := <-s.replanCh:
+			if err := s.planUser(ctx, userID); err != nil {
+				s.tenantPlanningFailed.Inc()
+				level.Error(s.logger).Log("msg", "failed to plan compaction jobs", "user", userID, "err", err)
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// planUsers plans the compaction jobs of all the tenants.
+func (s *Scheduler) planUsers(ctx context.Context) {
+	users, err := mimir_tsdb.ListUsers(ctx, s.bucketClient)
+	if err != nil {
+		level.Error(s.logger).Log("msg", "failed to discover users from bucket", "err", err)
+		return
+	}
+
+	for _, userID := range users {
+		if ctx.Err() != nil {
+			return
+		}
+		if !s.allowedTenants.IsAllowed(userID) {
+			continue
+		}
+
+		if markedForDeletion, err := mimir_tsdb.TenantDeletionMarkExists(ctx, s.bucketClient, userID); err != nil {
+			level.Warn(s.logger).Log("msg", "unable to check if user is marked for deletion", "user", userID, "err", err)
+			continue
+		} else if markedForDeletion {
+			s.enqueueJobs(userID, nil)
+			continue
+		}
+
+		if err := s.planUser(ctx, userID); err != nil {
+			s.tenantPlanningFailed.Inc()
+			level.Error(s.logger).Log("msg", "failed to plan compaction jobs", "user", userID, "err", err)
+		}
+	}
+}
+
+// planUser plans the compaction jobs of a tenant, replacing its queued jobs.
+func (s *Scheduler) planUser(ctx context.Context, userID string) error {
+	userBucket := bucket.NewUserBucketClient(userID, s.bucketClient, s.cfgProvider)
+	userLogger := util_log.WithUserID(userID, s.logger)
+
+	// The syncer metrics aren't exported: the compactors track the blocks they compact.
+	reg := prometheus.NewRegistry()
+
+	syncer, err := newUserMetaSyncer(userBucket, s.metaSyncDirForUser(userID), s.cfg.MetaSyncConcurrency, s.blocksMarkedForDeletion, userLogger, reg)
+	if err != nil {
+		return err
+	}
+
+	planner, err := NewBucketCompactor(
+		userLogger,
+		syncer,
+		s.blocksGrouperFactory(ctx, s.cfg, s.cfgProvider, userID, userLogger, reg),
+		nil,
+		nil,
+		"",
+		userBucket,
+		1,
+		false,
+		ownAllJobs,
+		s.jobsOrder,
+		s.cfg.CompactionWaitPeriod,
+		s.cfg.BlockSyncConcurrency,
+		0,
+		s.bucketCompactorMetrics,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create bucket compactor")
+	}
+
+	jobs, err := planner.planJobs(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.enqueueJobs(userID, jobs)
+	level.Info(userLogger).Log("msg", "planned compaction jobs", "jobs", len(jobs))
+	return nil
+}
+
+// metaSyncDirForUser returns the directory to store the cached meta files of a tenant. The directory is different
+// from the compactor one, because the compactor deletes the directories of the tenants it doesn't own.
+func (s *Scheduler) metaSyncDirForUser(userID string) string {
+	return filepath.Join(s.cfg.DataDir, "compactor-scheduler-meta-"+userID)
+}
+
+// enqueueJobs replaces the queued jobs of a tenant. The jobs compacting any block of a running job are skipped.
+func (s *Scheduler) enqueueJobs(userID string, jobs []*Job) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	runningBlocks := map[ulid.ULID]struct{}{}
+	for _, j := range s.runningJobs {
+		if j.job.UserID() != userID {
+			continue
+		}
+		for _, id := range j.job.IDs() {
+			runningBlocks[id] = struct{}{}
+		}
+	}
+
+	now := time.Now()
+	queue := make([]*scheduledJob, 0, len(jobs))
+
+jobsLoop:
+	for _, job := range jobs {
+		for _, id := range job.IDs() {
+			if _, ok := runningBlocks[id]; ok {
+				continue jobsLoop
+			}
+		}
+		queue = append(queue, &scheduledJob{
+			id:       ulid.MustNew(ulid.Now(), rand.Reader).String(),
+			job:      job,
+			queuedAt: now,
+		})
+	}
+
+	_, hadQueue := s.queues[userID]
+	switch {
+	case len(queue) > 0:
+		s.queues[userID] = queue
+		if !hadQueue {
+			s.tenants = append(s.tenants, userID)
+		}
+	case hadQueue:
+		delete(s.queues, userID)
+		s.tenants = removeString(s.tenants, userID)
+	}
+}
+
+// requeueJob queues a job again at the end of its tenant queue.
+// Must be called with the lock held.
+func (s *Scheduler) requeueJob(j *scheduledJob, now time.Time) {
+	if j.attempts >= s.cfg.Scheduler.MaxJobAttempts {
+		s.jobsDropped.Inc()
+		level.Warn(s.logger).Log("msg", "dropped compaction job because it reached the maximum number of attempts", "user", j.job.UserID(), "groupKey", j.job.Key(), "attempts", j.attempts)
+		return
+	}
+
+	j.workerID = ""
+	j.leasedAt = time.Time{}
+	j.leaseExpiresAt = time.Time{}
+	j.queuedAt = now
+
+	userID := j.job.UserID()
+	if _, ok := s.queues[userID]; !ok {
+		s.tenants = append(s.tenants, userID)
+	}
+	s.queues[userID] = append(s.queues[userID], j)
+}
+
+// requeueExpiredJobs queues again the running jobs whose lease expired.
+// Must be called with the lock held.
+func (s *Scheduler) requeueExpiredJobs(now time.Time) {
+	for id, j := range s.runningJobs {
+		if now.Before(j.leaseExpiresAt) {
+			continue
+		}
+
+		s.jobsLeaseExpired.Inc()
+		level.Warn(s.logger).Log("msg", "compaction job lease expired", "user", j.job.UserID(), "groupKey", j.job.Key(), "worker", j.workerID)
+
+		delete(s.runningJobs, id)
+		s.requeueJob(j, now)
+	}
+}
+
+// LeaseJob implements compactorschedulerpb.CompactorSchedulerServer.
+func (s *Scheduler) LeaseJob(_ context.Context, req *compactorschedulerpb.LeaseJobRequest) (*compactorschedulerpb.LeaseJobResponse, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	now := time.Now()
+	s.requeueExpiredJobs(now)
+
+	if len(s.tenants) == 0 {
+		return &compactorschedulerpb.LeaseJobResponse{}, nil
+	}
+
+	// Lease the first job of the next tenant, and move the tenant at the end of the round-robin.
+	userID := s.tenants[0]
+	s.tenants = s.tenants[1:]
+
+	queue := s.queues[userID]
+	j := queue[0]
+	if len(queue) > 1 {
+		s.queues[userID] = queue[1:]
+		s.tenants = append(s.tenants, userID)
+	} else {
+		delete(s.queues, userID)
+	}
+
+	j.attempts++
+	j.workerID = req.WorkerId
+	j.leasedAt = now
+	j.leaseExpiresAt = now.Add(s.cfg.Scheduler.JobLeaseDuration)
+	s.runningJobs[j.id] = j
+	s.jobsLeased.Inc()
+
+	blockIDs := make([]string, 0, len(j.job.IDs()))
+	for _, id := range j.job.IDs() {
+		blockIDs = append(blockIDs, id.String())
+	}
+
+	return &compactorschedulerpb.LeaseJobResponse{
+		JobId: j.id,
+		Job: compactorschedulerpb.CompactionJob{
+			UserId:         userID,
+			Key:            j.job.Key(),
+			Resolution:     j.job.Resolution(),
+			BlockIds:       blockIDs,
+			UseSplitting:   j.job.UseSplitting(),
+			SplitNumShards: j.job.SplittingShards(),
+		},
+		LeaseDuration: s.cfg.Scheduler.JobLeaseDuration,
+	}, nil
+}
+
+// RenewJobLease implements compactorschedulerpb.CompactorSchedulerServer.
+func (s *Scheduler) RenewJobLease(_ context.Context, req *compactorschedulerpb.RenewJobLeaseRequest) (*compactorschedulerpb.RenewJobLeaseResponse, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	j, ok := s.runningJobs[req.JobId]
+	if !ok || j.workerID != req.WorkerId {
+		return nil, errJobNotLeased
+	}
+
+	j.leaseExpiresAt = time.Now().Add(s.cfg.Scheduler.JobLeaseDuration)
+	return &compactorschedulerpb.RenewJobLeaseResponse{}, nil
+}
+
+// CompleteJob implements compactorschedulerpb.CompactorSchedulerServer.
+func (s *Scheduler) CompleteJob(_ context.Context, req *compactorschedulerpb.CompleteJobRequest) (*compactorschedulerpb.CompleteJobResponse, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	j, ok := s.runningJobs[req.JobId]
+	if !ok || j.workerID != req.WorkerId {
+		return nil, errJobNotLeased
+	}
+	delete(s.runningJobs, req.JobId)
+
+	userID := j.job.UserID()
+	if req.Error != "" {
+		s.jobsCompleted.WithLabelValues("failure").Inc()
+		level.Warn(s.logger).Log("msg", "compaction job failed", "user", userID, "groupKey", j.job.Key(), "worker", req.WorkerId, "attempts", j.attempts, "err", req.Error)
+		s.requeueJob(j, time.Now())
+		return &compactorschedulerpb.CompleteJobResponse{}, nil
+	}
+
+	s.jobsCompleted.WithLabelValues("success").Inc()
+
+	// Once all the jobs of the tenant have been run, the compacted blocks may be compacted again.
+	if !s.hasJobs(userID) {
+		select {
+		case s.replanCh <- userID:
+		default:
+			// The tenant is planned again at the next compaction interval.
+		}
+	}
+	return &compactorschedulerpb.CompleteJobResponse{}, nil
+}
+
+// hasJobs returns whether the tenant has queued or running jobs.
+// Must be called with the lock held.
+func (s *Scheduler) hasJobs(userID string) bool {
+	if _, ok := s.queues[userID]; ok {
+		return true
+	}
+	for _, j := range s.runningJobs {
+		if j.job.UserID() == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func removeString(values []string, value string) []string {
+	for i, v := range values {
+		if v == value {
+			return append(values[:i], values[i+1:]...)
+		}
+	}
+	return values
+}
+
+//go:embed scheduler_status.gohtml
+var schedulerStatusPageHTML string
+var schedulerStatusPageTemplate = template.Must(template.New("compactor-scheduler").Parse(schedulerStatusPageHTML))
+
+type schedulerStatusPageContents struct {
+	Now     time.Time            `json:"now"`
+	Running []schedulerJobStatus `json:"running"`
+	Queued  []schedulerJobStatus `json:"queued"`
+}
+
+type schedulerJobStatus struct {
+	ID             string    `json:"id"`
+	UserID         string    `json:"user_id"`
+	Key            string    `json:"key"`
+	Blocks         int       `json:"blocks"`
+	MinTime        time.Time `json:"min_time"`
+	MaxTime        time.Time `json:"max_time"`
+	Attempts       int       `json:"attempts"`
+	QueuedAt       time.Time `json:"queued_at"`
+	WorkerID       string    `json:"worker_id,omitempty"`
+	LeasedAt       time.Time `json:"leased_at,omitempty"`
+	LeaseExpiresAt time.Time `json:"lease_expires_at,omitempty"`
+}
+
+func newSchedulerJobStatus(j *scheduledJob) schedulerJobStatus {
+	return schedulerJobStatus{
+		ID:             j.id,
+		UserID:         j.job.UserID(),
+		Key:            j.job.Key(),
+		Blocks:         len(j.job.Metas()),
+		MinTime:        time.UnixMilli(j.job.MinTime()).UTC(),
+		MaxTime:        time.UnixMilli(j.job.MaxTime()).UTC(),
+		Attempts:       j.attempts,
+		QueuedAt:       j.queuedAt,
+		WorkerID:       j.workerID,
+		LeasedAt:       j.leasedAt,
+		LeaseExpiresAt: j.leaseExpiresAt,
+	}
+}
+
+// JobsHandler shows the running and queued compaction jobs.
+func (s *Scheduler) JobsHandler(w http.ResponseWriter, req *http.Request) {
+	contents := schedulerStatusPageContents{Now: time.Now()}
+
+	s.mtx.Lock()
+	for _, j := range s.runningJobs {
+		contents.Running = append(contents.Running, newSchedulerJobStatus(j))
+	}
+	// The queued jobs are listed in the order they're leased.
+	queues := make(map[string][]*scheduledJob, len(s.queues))
+	for userID, queue := range s.queues {
+		queues[userID] = queue
+	}
+	for len(queues) > 0 {
+		for _, userID := range s.tenants {
+			queue, ok := queues[userID]
+			if !ok {
+				continue
+			}
+			contents.Queued = append(contents.Queued, newSchedulerJobStatus(queue[0]))
+			if len(queue) > 1 {
+				queues[userID] = queue[1:]
+			} else {
+				delete(queues, userID)
+			}
+		}
+	}
+	s.mtx.Unlock()
+
+	sort.Slice(contents.Running, func(i, j int) bool {
+		return contents.Running[i].LeasedAt.Before(contents.Running[j].LeasedAt)
+	})
+
+	util.RenderHTTPResponse(w, contents, schedulerStatusPageTemplate, req)
+}
@@ -0,0 +1,230 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package compactor
+
+import (
+	"context"
+	"net"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/flagext"
+	"github.com/grafana/dskit/services"
+	"github.com/grafana/dskit/test"
+	"github.com/oklog/ulid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+	"google.golang.org/grpc"
+
+	"github.com/grafana/mimir/pkg/compactor/compactorschedulerpb"
+	"github.com/grafana/mimir/pkg/storage/bucket"
+	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
+	"github.com/grafana/mimir/pkg/storage/tsdb/block"
+)
+
+func TestScheduler_LeaseJob(t *testing.T) {
+	s, registry := prepareScheduler(t, prepareConfig(t), objstore.NewInMemBucket())
+
+	s.enqueueJobs("user-1", []*Job{
+		newTestJob(t, "user-1", "job-1", "01H0000000000000000000000A"),
+		newTestJob(t, "user-1", "job-2", "01H0000000000000000000000B"),
+	})
+	s.enqueueJobs("user-2", []*Job{
+		newTestJob(t, "user-2", "job-1", "01H0000000000000000000000C"),
+	})
+
+	// The jobs of the tenants are leased in round-robin.
+	var leased []string
+	for {
+		resp, err := s.LeaseJob(context.Background(), &compactorschedulerpb.LeaseJobRequest{WorkerId: "compactor-1"})
+		require.NoError(t, err)
+		if resp.JobId == "" {
+			break
+		}
+
+		assert.Equal(t, s.cfg.Scheduler.JobLeaseDuration, resp.LeaseDuration)
+		leased = append(leased, resp.Job.UserId+"/"+resp.Job.Key+"/"+strings.Join(resp.Job.BlockIds, ","))
+	}
+
+	assert.Equal(t, []string{
+		"user-1/job-1/01H0000000000000000000000A",
+		"user-2/job-1/01H0000000000000000000000C",
+		"user-1/job-2/01H0000000000000000000000B",
+	}, leased)
+
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+		# HELP cortex_compactor_scheduler_jobs_leased_total Total number of compaction jobs leased to the compactors.
+		# TYPE cortex_compactor_scheduler_jobs_leased_total counter
+		cortex_compactor_scheduler_jobs_leased_total 3
+
+		# HELP cortex_compactor_scheduler_jobs_queued Number of compaction jobs waiting to be leased to a compactor.
+		# TYPE cortex_compactor_scheduler_jobs_queued gauge
+		cortex_compactor_scheduler_jobs_queued 0
+
+		# HELP cortex_compactor_scheduler_jobs_running Number of compaction jobs leased to a compactor.
+		# TYPE cortex_compactor_scheduler_jobs_running gauge
+		cortex_compactor_scheduler_jobs_running 3
+	`), "cortex_compactor_scheduler_jobs_leased_total", "cortex_compactor_scheduler_jobs_queued", "cortex_compactor_scheduler_jobs_running"))
+}
+
+func TestScheduler_ShouldRetryFailedAndExpiredJobs(t *testing.T) {
+	cfg := prepareConfig(t)
+	cfg.Scheduler.MaxJobAttempts = 3
+	s, registry := prepareScheduler(t, cfg, objstore.NewInMemBucket())
+
+	s.enqueueJobs("user-1", []*Job{newTestJob(t, "user-1", "job-1", "01H0000000000000000000000A")})
+
+	lease := func() *compactorschedulerpb.LeaseJobResponse {
+		resp, err := s.LeaseJob(context.Background(), &compactorschedulerpb.LeaseJobRequest{WorkerId: "compactor-1"})
+		require.NoError(t, err)
+		return resp
+	}
+
+	// The failed job is queued again.
+	resp := lease()
+	require.NotEmpty(t, resp.JobId)
+	_, err := s.CompleteJob(context.Background(), &compactorschedulerpb.CompleteJobRequest{JobId: resp.JobId, WorkerId: "compactor-1", Error: "failed"})
+	require.NoError(t, err)
+
+	// The job whose lease expired is queued again.
+	resp = lease()
+	require.NotEmpty(t, resp.JobId)
+	s.mtx.Lock()
+	s.runningJobs[resp.JobId].leaseExpiresAt = time.Now().Add(-time.Second)
+	s.mtx.Unlock()
+
+	// The compactor can't renew or complete the job whose lease expired.
+	resp = lease()
+	require.NotEmpty(t, resp.JobId)
+	_, err = s.RenewJobLease(context.Background(), &compactorschedulerpb.RenewJobLeaseRequest{JobId: resp.JobId, WorkerId: "compactor-2"})
+	require.ErrorIs(t, err, errJobNotLeased)
+	_, err = s.RenewJobLease(context.Background(), &compactorschedulerpb.RenewJobLeaseRequest{JobId: resp.JobId, WorkerId: "compactor-1"})
+	require.NoError(t, err)
+
+	// The job is dropped once it reaches the maximum number of attempts.
+	_, err = s.CompleteJob(context.Background(), &compactorschedulerpb.CompleteJobRequest{JobId: resp.JobId, WorkerId: "compactor-1", Error: "failed"})
+	require.NoError(t, err)
+	assert.Empty(t, lease().JobId)
+
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+		# HELP cortex_compactor_scheduler_jobs_completed_total Total number of compaction jobs reported as completed by the compactors.
+		# TYPE cortex_compactor_scheduler_jobs_completed_total counter
+		cortex_compactor_scheduler_jobs_completed_total{outcome="failure"} 2
+
+		# HELP cortex_compactor_scheduler_jobs_dropped_total Total number of compaction jobs dropped because they reached the maximum number of attempts.
+		# TYPE cortex_compactor_scheduler_jobs_dropped_total counter
+		cortex_compactor_scheduler_jobs_dropped_total 1
+
+		# HELP cortex_compactor_scheduler_jobs_lease_expired_total Total number of compaction jobs whose lease expired before the compactor completed them.
+		# TYPE cortex_compactor_scheduler_jobs_lease_expired_total counter
+		cortex_compactor_scheduler_jobs_lease_expired_total 1
+	`), "cortex_compactor_scheduler_jobs_completed_total", "cortex_compactor_scheduler_jobs_dropped_total", "cortex_compactor_scheduler_jobs_lease_expired_total"))
+}
+
+func TestScheduler_EnqueueJobsShouldSkipJobsOverlappingRunningJobs(t *testing.T) {
+	s, _ := prepareScheduler(t, prepareConfig(t), objstore.NewInMemBucket())
+
+	s.enqueueJobs("user-1", []*Job{newTestJob(t, "user-1", "job-1", "01H0000000000000000000000A")})
+	resp, err := s.LeaseJob(context.Background(), &compactorschedulerpb.LeaseJobRequest{WorkerId: "compactor-1"})
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.JobId)
+
+	// Planning again while the job is running replaces the queued jobs, skipping the ones compacting its blocks.
+	s.enqueueJobs("user-1", []*Job{
+		newTestJob(t, "user-1", "job-1", "01H0000000000000000000000A", "01H0000000000000000000000B"),
+		newTestJob(t, "user-1", "job-2", "01H0000000000000000000000C"),
+	})
+
+	resp, err = s.LeaseJob(context.Background(), &compactorschedulerpb.LeaseJobRequest{WorkerId: "compactor-2"})
+	require.NoError(t, err)
+	assert.Equal(t, "job-2", resp.Job.Key)
+
+	resp, err = s.LeaseJob(context.Background(), &compactorschedulerpb.LeaseJobRequest{WorkerId: "compactor-2"})
+	require.NoError(t, err)
+	assert.Empty(t, resp.JobId)
+}
+
+func TestMultitenantCompactor_ShouldRunJobsPlannedByTheScheduler(t *testing.T) {
+	const userID = "user-1"
+
+	bkt := objstore.NewInMemBucket()
+	userBkt := bucket.NewUserBucketClient(userID, bkt, nil)
+
+	// Two overlapping blocks to compact together.
+	block1 := createTSDBBlock(t, bkt, userID, 0, 2*time.Hour.Milliseconds(), 10, nil)
+	block2 := createTSDBBlock(t, bkt, userID, 0, 2*time.Hour.Milliseconds(), 10, nil)
+
+	cfg := prepareConfig(t)
+	cfg.BlocksGrouperFactory = splitAndMergeGrouperFactory
+	cfg.DataDir = t.TempDir()
+	cfgProvider := newMockConfigProvider()
+
+	scheduler, schedulerRegistry := prepareSchedulerWithConfigProvider(t, cfg, bkt, cfgProvider)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), scheduler))
+	t.Cleanup(stopServiceFn(t, scheduler))
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	compactorschedulerpb.RegisterCompactorSchedulerServer(server, scheduler)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	storageCfg := mimir_tsdb.BlocksStorageConfig{}
+	flagext.DefaultValues(&storageCfg)
+
+	cfg.Scheduler.Address = listener.Addr().String()
+	cfg.Scheduler.jobPollInterval = 100 * time.Millisecond
+	c, err := newMultitenantCompactor(cfg, storageCfg, cfgProvider, log.NewNopLogger(), prometheus.NewRegistry(), func(context.Context) (objstore.Bucket, error) {
+		return bkt, nil
+	}, splitAndMergeGrouperFactory, splitAndMergeCompactorFactory)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), c))
+	t.Cleanup(stopServiceFn(t, c))
+
+	test.Poll(t, 10*time.Second, 1.0, func() interface{} {
+		return testutil.ToFloat64(scheduler.jobsCompleted.WithLabelValues("success"))
+	})
+
+	// The compacted blocks are marked for deletion.
+	for _, id := range []ulid.ULID{block1, block2} {
+		exists, err := userBkt.Exists(context.Background(), path.Join(id.String(), block.DeletionMarkFilename))
+		require.NoError(t, err)
+		assert.True(t, exists, id.String())
+	}
+
+	assert.NoError(t, testutil.GatherAndCompare(schedulerRegistry, strings.NewReader(`
+		# HELP cortex_compactor_scheduler_jobs_leased_total Total number of compaction jobs leased to the compactors.
+		# TYPE cortex_compactor_scheduler_jobs_leased_total counter
+		cortex_compactor_scheduler_jobs_leased_total 1
+	`), "cortex_compactor_scheduler_jobs_leased_total"))
+}
+
+func prepareScheduler(t *testing.T, cfg Config, bkt objstore.Bucket) (*Scheduler, *prometheus.Registry) {
+	cfg.BlocksGrouperFactory = splitAndMergeGrouperFactory
+	return prepareSchedulerWithConfigProvider(t, cfg, bkt, newMockConfigProvider())
+}
+
+func prepareSchedulerWithConfigProvider(t *testing.T, cfg Config, bkt objstore.Bucket, cfgProvider ConfigProvider) (*Scheduler, *prometheus.Registry) {
+	registry := prometheus.NewRegistry()
+	s, err := newScheduler(cfg, cfgProvider, log.NewNopLogger(), registry, func(context.Context) (objstore.Bucket, error) {
+		return bkt, nil
+	})
+	require.NoError(t, err)
+	return s, registry
+}
+
+func newTestJob(t *testing.T, userID, key string, blockIDs ...string) *Job {
+	job := NewJob(userID, key, labels.EmptyLabels(), 0, false, 0, "")
+	for _, id := range blockIDs {
+		require.NoError(t, job.AppendMeta(blockMeta(id, 0, 2*time.Hour.Milliseconds(), nil)))
+	}
+	return job
+}
@@ -0,0 +1,176 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package compactor
+
+import (
+	"context"
+	"path"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/backoff"
+	"github.com/oklog/ulid"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/thanos-io/objstore"
+	"google.golang.org/grpc"
+
+	"github.com/grafana/mimir/pkg/compactor/compactorschedulerpb"
+	"github.com/grafana/mimir/pkg/storage/bucket"
+	"github.com/grafana/mimir/pkg/storage/tsdb/block"
+	util_log "github.com/grafana/mimir/pkg/util/log"
+)
+
+// connectToScheduler creates the client of the compactor-scheduler.
+func (c *MultitenantCompactor) connectToScheduler() error {
+	opts, err := c.compactorCfg.Scheduler.GRPCClientConfig.DialOption(nil, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to configure the compactor-scheduler client")
+	}
+
+	c.schedulerConn, err = grpc.Dial(c.compactorCfg.Scheduler.Address, opts...)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to the compactor-scheduler")
+	}
+
+	c.schedulerClient = compactorschedulerpb.NewCompactorSchedulerClient(c.schedulerConn)
+	return nil
+}
+
+// runScheduledJobs leases the compaction jobs planned by the compactor-scheduler and runs them,
+// until the context is canceled.
+func (c *MultitenantCompactor) runScheduledJobs(ctx context.Context) {
+	workerID := c.ringLifecycler.GetInstanceID()
+
+	retries := backoff.New(ctx, backoff.Config{
+		MinBackoff: c.compactorCfg.retryMinBackoff,
+		MaxBackoff: c.compactorCfg.retryMaxBackoff,
+	})
+
+	for ctx.Err() == nil {
+		resp, err := c.schedulerClient.LeaseJob(ctx, &compactorschedulerpb.LeaseJobRequest{WorkerId: workerID})
+		if err != nil {
+			if ctx.Err() == nil {
+				level.Warn(c.logger).Log("msg", "failed to lease a compaction job from the compactor-scheduler", "err", err)
+			}
+			retries.Wait()
+			continue
+		}
+		retries.Reset()
+
+		if resp.JobId == "" {
+			select {
+			case <-time.After(c.compactorCfg.Scheduler.jobPollInterval):
+			case <-ctx.Done():
+			}
+			continue
+		}
+
+		req := &compactorschedulerpb.CompleteJobRequest{JobId: resp.JobId, WorkerId: workerID}
+		if err := c.runScheduledJob(ctx, workerID, resp); err != nil {
+			req.Error = err.Error()
+		}
+
+		if _, err := c.schedulerClient.CompleteJob(ctx, req); err != nil && ctx.Err() == nil {
+			level.Warn(c.logger).Log("msg", "failed to report the completed compaction job to the compactor-scheduler", "user", resp.Job.UserId, "groupKey", resp.Job.Key, "err", err)
+		}
+	}
+}
+
+// runScheduledJob runs a compaction job leased by the compactor-scheduler, renewing the lease while
+// the job runs. The job is canceled if the lease can't be renewed.
+func (c *MultitenantCompactor) runScheduledJob(ctx context.Context, workerID string, resp *compactorschedulerpb.LeaseJobResponse) error {
+	jobCtx, cancelJob := context.WithCancel(ctx)
+	defer cancelJob()
+
+	go func() {
+		ticker := time.NewTicker(resp.LeaseDuration / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := c.schedulerClient.RenewJobLease(jobCtx, &compactorschedulerpb.RenewJobLeaseRequest{JobId: resp.JobId, WorkerId: workerID}); err != nil {
+					level.Warn(c.logger).Log("msg", "failed to renew the lease of the compaction job, canceling it", "user", resp.Job.UserId, "groupKey", resp.Job.Key, "err", err)
+					cancelJob()
+					return
+				}
+			case <-jobCtx.Done():
+				return
+			}
+		}
+	}()
+
+	userID := resp.Job.UserId
+	userBucket := bucket.NewUserBucketClient(userID, c.bucketClient, c.cfgProvider)
+	userLogger := util_log.WithUserID(userID, c.logger)
+
+	job, err := newScheduledJob(jobCtx, userBucket, userLogger, resp.Job)
+	if err != nil {
+		return err
+	}
+
+	compactor, err := NewBucketCompactor(
+		userLogger,
+		nil,
+		nil,
+		c.blocksPlanner,
+		c.blocksCompactor,
+		// The jobs of different tenants may have the same key and run concurrently.
+		path.Join(c.compactorCfg.DataDir, "compact", userID),
+		userBucket,
+		1,
+		true, // Skip blocks with out of order chunks, and mark them for no-compaction.
+		ownAllJobs,
+		nil,
+		0,
+		c.compactorCfg.BlockSyncConcurrency,
+		c.compactorCfg.BlockIndexStatsMaxNames,
+		c.bucketCompactorMetrics,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create bucket compactor")
+	}
+
+	// The compactor-scheduler plans the tenant again once all its jobs have been run.
+	_, err = compactor.runJob(jobCtx, job)
+	return err
+}
+
+// newScheduledJob returns the Job of a compaction job planned by the compactor-scheduler, downloading the metas of its blocks.
+func newScheduledJob(ctx context.Context, userBucket objstore.Bucket, logger log.Logger, j compactorschedulerpb.CompactionJob) (*Job, error) {
+	if len(j.BlockIds) == 0 {
+		return nil, errors.New("the compaction job has no blocks")
+	}
+
+	metas := make(map[ulid.ULID]*block.Meta, len(j.BlockIds))
+	for _, id := range j.BlockIds {
+		blockID, err := ulid.Parse(id)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse block ID %s", id)
+		}
+
+		meta, err := block.DownloadMeta(ctx, logger, userBucket, blockID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "download meta of block %s", blockID)
+		}
+		metas[blockID] = &meta
+	}
+
+	// Remove the same external labels the compactor-scheduler removed when planning the job.
+	if err := NewLabelRemoverFilter(compactionRemovedExternalLabels).Filter(ctx, metas, nil); err != nil {
+		return nil, err
+	}
+
+	var job *Job
+	for _, meta := range metas {
+		if job == nil {
+			job = NewJob(j.UserId, j.Key, labels.FromMap(meta.Thanos.Labels), j.Resolution, j.UseSplitting, j.SplitNumShards, "")
+		}
+		if err := job.AppendMeta(meta); err != nil {
+			return nil, errors.Wrapf(err, "add block %s to the compaction job", meta.ULID)
+		}
+	}
+	return job, nil
+}
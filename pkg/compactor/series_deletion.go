@@ -0,0 +1,325 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package compactor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/oklog/ulid"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/thanos-io/objstore"
+	"golang.org/x/exp/slices"
+
+	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
+	"github.com/grafana/mimir/pkg/storage/tsdb/block"
+)
+
+const seriesDeletionRewriteReason = "series deletion"
+
+// applySeriesDeletionRequests rewrites the tenant's blocks to permanently remove the samples
+// matching the series deletion requests whose deletion delay has elapsed.
+//
+// A request is applied to a block if the block's meta.json doesn't list the request as applied
+// and the request doesn't list the block as unaffected. Blocks keep being checked after the
+// request has been processed, because ingesters may upload blocks overlapping its time range
+// later, and split and merge compactions running concurrently on other compactors may produce
+// blocks from sources the request hasn't been applied to yet. A request is marked as processed
+// once a run finds no block left to apply it to.
+//
+// Only the compactor running the blocks cleaner for the tenant applies the requests, so that
+// a block is never rewritten by multiple compactors concurrently.
+func (c *MultitenantCompactor) applySeriesDeletionRequests(ctx context.Context, userID string, userBucket objstore.InstrumentedBucket, logger log.Logger) error {
+	if owned, err := c.shardingStrategy.blocksCleanerOwnUser(userID); err != nil {
+		// Series deletion requests will be applied at the next compaction run.
+		level.Warn(logger).Log("msg", "unable to check if user is owned by this shard for series deletion", "err", err)
+		return nil
+	} else if !owned {
+		return nil
+	}
+
+	requests, err := mimir_tsdb.ListSeriesDeletionRequests(ctx, userBucket)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var active []*mimir_tsdb.SeriesDeletionRequest
+	for _, req := range requests {
+		if req.State(now, c.compactorCfg.SeriesDeletionDelay) != mimir_tsdb.SeriesDeletionRequestPending {
+			active = append(active, req)
+		}
+	}
+
+	if len(active) == 0 {
+		return nil
+	}
+
+	// Unlike the compaction, we need to look at all blocks, including the ones marked for no-compaction,
+	// and the ones compacted by other compactors in the meanwhile.
+	fetcher, err := block.NewMetaFetcher(logger, c.compactorCfg.MetaSyncConcurrency, userBucket, c.metaSyncDirForUser(userID), nil, nil)
+	if err != nil {
+		return err
+	}
+
+	metas, _, err := fetcher.FetchWithoutMarkedForDeletion(ctx)
+	if err != nil {
+		return errors.Wrap(err, "fetch metas")
+	}
+
+	// Requests to write back to the bucket, because their list of unaffected blocks or their state changed.
+	updated := map[*mimir_tsdb.SeriesDeletionRequest]bool{}
+	pendingBlocks := map[*mimir_tsdb.SeriesDeletionRequest]int{}
+
+	for _, req := range active {
+		if unaffected := pruneUnaffectedBlocks(req.UnaffectedBlocks, metas); len(unaffected) != len(req.UnaffectedBlocks) {
+			req.UnaffectedBlocks = unaffected
+			updated[req] = true
+		}
+	}
+
+	for _, meta := range metas {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		var toApply []*mimir_tsdb.SeriesDeletionRequest
+		for _, req := range active {
+			if seriesDeletionRequestAppliesTo(req, meta) {
+				toApply = append(toApply, req)
+				pendingBlocks[req]++
+			}
+		}
+
+		if len(toApply) == 0 {
+			continue
+		}
+
+		rewritten, err := c.rewriteBlockWithSeriesDeletions(ctx, userBucket, meta, toApply, log.With(logger, "block", meta.ULID.String()))
+		if err != nil {
+			return errors.Wrapf(err, "rewrite block %s", meta.ULID.String())
+		}
+
+		if !rewritten {
+			for _, req := range toApply {
+				req.UnaffectedBlocks = append(req.UnaffectedBlocks, meta.ULID.String())
+				updated[req] = true
+			}
+		}
+	}
+
+	for _, req := range active {
+		// The request is processed only if all blocks were already clean when this run started: blocks rewritten
+		// by this run, or compacted concurrently by other compactors, are checked again at the next run.
+		if req.ProcessedAt == 0 && pendingBlocks[req] == 0 {
+			req.ProcessedAt = now.Unix()
+			updated[req] = true
+
+			c.seriesDeletionRequestsProcessed.Inc()
+			level.Info(logger).Log("msg", "series deletion request processed", "request_id", req.RequestID)
+		}
+
+		if !updated[req] {
+			continue
+		}
+
+		// The request may have been cancelled in the meanwhile, in which case we don't want to recreate it.
+		if exists, err := mimir_tsdb.ReadSeriesDeletionRequest(ctx, userBucket, req.RequestID); err != nil {
+			return err
+		} else if exists == nil {
+			continue
+		}
+
+		if err := mimir_tsdb.WriteSeriesDeletionRequest(ctx, userBucket, req); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// seriesDeletionRequestAppliesTo returns whether the request still has to be applied to the block.
+func seriesDeletionRequestAppliesTo(req *mimir_tsdb.SeriesDeletionRequest, meta *block.Meta) bool {
+	// Block's MaxTime is exclusive.
+	if !req.Overlaps(meta.MinTime, meta.MaxTime-1) {
+		return false
+	}
+
+	return !slices.Contains(meta.Thanos.SeriesDeletionRequests, req.RequestID) && !slices.Contains(req.UnaffectedBlocks, meta.ULID.String())
+}
+
+// pruneUnaffectedBlocks returns the input unaffected blocks which still exist in metas.
+func pruneUnaffectedBlocks(unaffected []string, metas map[ulid.ULID]*block.Meta) []string {
+	var out []string
+	for _, id := range unaffected {
+		if parsed, err := ulid.Parse(id); err == nil && metas[parsed] != nil {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// appliedSeriesDeletionRequests returns the IDs of the series deletion requests applied to all the
+// input blocks, which are therefore applied to a block compacted from them too.
+func appliedSeriesDeletionRequests(metas []*block.Meta) []string {
+	if len(metas) == 0 {
+		return nil
+	}
+
+	var out []string
+	for _, id := range metas[0].Thanos.SeriesDeletionRequests {
+		appliedToAll := true
+		for _, meta := range metas[1:] {
+			if !slices.Contains(meta.Thanos.SeriesDeletionRequests, id) {
+				appliedToAll = false
+				break
+			}
+		}
+		if appliedToAll {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// rewriteBlockWithSeriesDeletions downloads the block, removes the samples matching the input
+// requests and uploads the resulting block, marking the original one for deletion. Blocks are
+// immutable, so a block not containing any sample matching the requests is left untouched,
+// in which case false is returned.
+func (c *MultitenantCompactor) rewriteBlockWithSeriesDeletions(ctx context.Context, userBucket objstore.Bucket, meta *block.Meta, requests []*mimir_tsdb.SeriesDeletionRequest, logger log.Logger) (bool, error) {
+	baseDir := filepath.Join(c.compactorCfg.DataDir, "series-deletion")
+	if err := os.MkdirAll(baseDir, os.ModePerm); err != nil {
+		return false, errors.Wrap(err, "create series deletion directory")
+	}
+
+	tmpDir, err := os.MkdirTemp(baseDir, meta.ULID.String()+"-")
+	if err != nil {
+		return false, errors.Wrap(err, "create temporary directory")
+	}
+	defer func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			level.Warn(logger).Log("msg", "failed to remove series deletion temporary directory", "dir", tmpDir, "err", err)
+		}
+	}()
+
+	bdir := filepath.Join(tmpDir, meta.ULID.String())
+	if err := block.Download(ctx, logger, userBucket, meta.ULID, bdir); err != nil {
+		return false, errors.Wrap(err, "download block")
+	}
+
+	numTombstones, err := writeSeriesDeletionTombstones(ctx, bdir, requests, logger)
+	if err != nil {
+		return false, err
+	}
+
+	if numTombstones == 0 {
+		level.Debug(logger).Log("msg", "block has no series matching the series deletion requests")
+		return false, nil
+	}
+
+	comp, err := tsdb.NewLeveledCompactor(ctx, nil, logger, []int64{meta.MaxTime - meta.MinTime}, nil, nil, true)
+	if err != nil {
+		return false, errors.Wrap(err, "create compactor")
+	}
+
+	newID, err := comp.Compact(tmpDir, []string{bdir}, nil)
+	if err != nil {
+		return false, errors.Wrap(err, "compact block")
+	}
+
+	// All samples of the block have been deleted, so no block has been written.
+	if newID == (ulid.ULID{}) {
+		level.Info(logger).Log("msg", "all samples of the block have been deleted by series deletion requests")
+		return true, markRewrittenSourceBlockForDeletion(userBucket, meta.ULID, seriesDeletionRewriteReason, c.seriesDeletionBlocksMarkedForDeletion, logger)
+	}
+
+	if err := uploadRewrittenBlock(ctx, userBucket, filepath.Join(tmpDir, newID.String()), meta, requests, logger); err != nil {
+		return false, err
+	}
+
+	c.seriesDeletionBlocksRewritten.Inc()
+	level.Info(logger).Log("msg", "rewritten block applying series deletion requests", "new_block", newID.String(), "tombstones", numTombstones)
+
+	return true, markRewrittenSourceBlockForDeletion(userBucket, meta.ULID, seriesDeletionRewriteReason, c.seriesDeletionBlocksMarkedForDeletion, logger)
+}
+
+// writeSeriesDeletionTombstones writes the tombstones for the series deletion requests to the block
+// in bdir and returns the number of tombstones written.
+func writeSeriesDeletionTombstones(ctx context.Context, bdir string, requests []*mimir_tsdb.SeriesDeletionRequest, logger log.Logger) (_ uint64, returnErr error) {
+	b, err := tsdb.OpenBlock(logger, bdir, nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "open block")
+	}
+	defer func() {
+		if err := b.Close(); err != nil && returnErr == nil {
+			returnErr = errors.Wrap(err, "close block")
+		}
+	}()
+
+	for _, req := range requests {
+		selectors, err := req.Matchers()
+		if err != nil {
+			return 0, errors.Wrapf(err, "series deletion request %s", req.RequestID)
+		}
+
+		for _, ms := range selectors {
+			if err := b.Delete(ctx, req.StartTime, req.EndTime, ms...); err != nil {
+				return 0, errors.Wrapf(err, "delete series for request %s", req.RequestID)
+			}
+		}
+	}
+
+	return b.Meta().Stats.NumTombstones, nil
+}
+
+// uploadRewrittenBlock uploads the block rewritten from meta's block, recording in its meta.json that
+// the input series deletion requests have been applied, in addition to the ones applied to meta's block.
+func uploadRewrittenBlock(ctx context.Context, userBucket objstore.Bucket, bdir string, meta *block.Meta, requests []*mimir_tsdb.SeriesDeletionRequest, logger log.Logger) error {
+	applied := slices.Clone(meta.Thanos.SeriesDeletionRequests)
+	for _, req := range requests {
+		applied = append(applied, req.RequestID)
+	}
+
+	newMeta, err := block.InjectThanosMeta(logger, bdir, block.ThanosMeta{
+		Labels:                 meta.Thanos.Labels,
+		Downsample:             meta.Thanos.Downsample,
+		Source:                 block.CompactorSource,
+		SegmentFiles:           block.GetSegmentFiles(bdir),
+		SeriesDeletionRequests: applied,
+		// The index stats of meta's block are a superset of the rewritten block ones, so they are still
+		// safe to use to skip the blocks that can't contain the queried series.
+		IndexStats: meta.Thanos.IndexStats,
+	}, nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to finalize the block %s", bdir)
+	}
+
+	if err = os.Remove(filepath.Join(bdir, "tombstones")); err != nil {
+		return errors.Wrap(err, "remove tombstones")
+	}
+
+	if err := block.VerifyBlock(ctx, logger, bdir, newMeta.MinTime, newMeta.MaxTime, false); err != nil {
+		return errors.Wrapf(err, "invalid rewritten block %s", bdir)
+	}
+
+	return errors.Wrapf(block.Upload(ctx, logger, userBucket, bdir, nil), "upload of %s failed", newMeta.ULID)
+}
+
+// markRewrittenSourceBlockForDeletion marks the block which has been rewritten, for the input reason, for deletion.
+func markRewrittenSourceBlockForDeletion(userBucket objstore.Bucket, id ulid.ULID, reason string, markedForDeletion prometheus.Counter, logger log.Logger) error {
+	// Spawn a new context so we always mark a block for deletion in full on shutdown.
+	delCtx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	level.Info(logger).Log("msg", "marking rewritten block for deletion", "reason", reason)
+	return errors.Wrapf(
+		block.MarkForDeletion(delCtx, logger, userBucket, id, "source of block rewritten by "+reason, markedForDeletion),
+		"mark block %s for deletion", id,
+	)
+}
@@ -0,0 +1,155 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package compactor
+
+import (
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/tenant"
+	"github.com/pkg/errors"
+
+	"github.com/grafana/mimir/pkg/storage/bucket"
+	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
+	"github.com/grafana/mimir/pkg/util"
+)
+
+// SeriesDeletionRequestResponse is a series deletion request as returned by the HTTP API.
+type SeriesDeletionRequestResponse struct {
+	*mimir_tsdb.SeriesDeletionRequest
+
+	State mimir_tsdb.SeriesDeletionRequestState `json:"state"`
+
+	// UnaffectedBlocks shadows the internal bookkeeping of the compactor, which isn't exposed by the API.
+	UnaffectedBlocks []string `json:"unaffected_blocks,omitempty"`
+}
+
+// CreateSeriesDeletionRequest creates a request to delete the samples of the series matching any
+// of the match[] selectors, between the start and end time (both inclusive). Samples are filtered out
+// at query time once the queriers have loaded a bucket index including the request, and permanently
+// deleted by the compactor once the series deletion delay has elapsed.
+func (c *MultitenantCompactor) CreateSeriesDeletionRequest(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, err := tenant.TenantID(ctx)
+	if err != nil {
+		// When Mimir is running, it uses Auth Middleware for checking X-Scope-OrgID and injecting tenant into context.
+		// Auth Middleware sends http.StatusUnauthorized if X-Scope-OrgID is missing, so we do too here, for consistency.
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	startTime, endTime := int64(math.MinInt64), util.TimeToMillis(now)
+	if v := r.FormValue("start"); v != "" {
+		if startTime, err = util.ParseTime(v); err != nil {
+			http.Error(w, errors.Wrap(err, "invalid start time").Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if v := r.FormValue("end"); v != "" {
+		if endTime, err = util.ParseTime(v); err != nil {
+			http.Error(w, errors.Wrap(err, "invalid end time").Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	req := mimir_tsdb.NewSeriesDeletionRequest(r.Form["match[]"], startTime, endTime, now)
+	if err := req.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userBucket := bucket.NewUserBucketClient(userID, c.bucketClient, c.cfgProvider)
+	if err := mimir_tsdb.WriteSeriesDeletionRequest(ctx, userBucket, req); err != nil {
+		level.Error(c.logger).Log("msg", "failed to write series deletion request", "user", userID, "err", err)
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	level.Info(c.logger).Log("msg", "series deletion request created", "user", userID, "request_id", req.RequestID, "selectors", len(req.Selectors))
+
+	util.WriteJSONResponse(w, SeriesDeletionRequestResponse{
+		SeriesDeletionRequest: req,
+		State:                 req.State(now, c.compactorCfg.SeriesDeletionDelay),
+	})
+}
+
+// ListSeriesDeletionRequests returns all the series deletion requests of the tenant.
+func (c *MultitenantCompactor) ListSeriesDeletionRequests(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, err := tenant.TenantID(ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	userBucket := bucket.NewUserBucketClient(userID, c.bucketClient, c.cfgProvider)
+	requests, err := mimir_tsdb.ListSeriesDeletionRequests(ctx, userBucket)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	result := make([]SeriesDeletionRequestResponse, 0, len(requests))
+	for _, req := range requests {
+		result = append(result, SeriesDeletionRequestResponse{
+			SeriesDeletionRequest: req,
+			State:                 req.State(now, c.compactorCfg.SeriesDeletionDelay),
+		})
+	}
+
+	util.WriteJSONResponse(w, result)
+}
+
+// CancelSeriesDeletionRequest cancels the series deletion request with the given request_id.
+// Only requests whose series deletion delay hasn't elapsed yet can be cancelled.
+func (c *MultitenantCompactor) CancelSeriesDeletionRequest(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, err := tenant.TenantID(ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	requestID := r.FormValue("request_id")
+	userBucket := bucket.NewUserBucketClient(userID, c.bucketClient, c.cfgProvider)
+
+	req, err := mimir_tsdb.ReadSeriesDeletionRequest(ctx, userBucket, requestID)
+	if errors.Is(err, mimir_tsdb.ErrInvalidSeriesDeletionRequestID) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if req == nil {
+		http.Error(w, "series deletion request not found", http.StatusNotFound)
+		return
+	}
+
+	if state := req.State(time.Now(), c.compactorCfg.SeriesDeletionDelay); state != mimir_tsdb.SeriesDeletionRequestPending {
+		http.Error(w, "series deletion request can't be cancelled because it is "+string(state), http.StatusBadRequest)
+		return
+	}
+
+	if err := mimir_tsdb.DeleteSeriesDeletionRequest(ctx, userBucket, requestID); err != nil {
+		level.Error(c.logger).Log("msg", "failed to delete series deletion request", "user", userID, "request_id", requestID, "err", err)
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	level.Info(c.logger).Log("msg", "series deletion request cancelled", "user", userID, "request_id", requestID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package compactor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/dskit/services"
+	"github.com/grafana/dskit/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/grafana/mimir/pkg/storage/bucket"
+	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
+)
+
+func TestSeriesDeletionRequestsAPI(t *testing.T) {
+	bkt := objstore.NewInMemBucket()
+	cfg := prepareConfig(t)
+	cfg.SeriesDeletionDelay = time.Hour
+	c, _, _, _, _ := prepare(t, cfg, bkt)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), c))
+	t.Cleanup(stopServiceFn(t, c))
+
+	ctx := user.InjectOrgID(context.Background(), "fake")
+
+	create := func(form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/compactor/delete_series", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		resp := httptest.NewRecorder()
+		c.CreateSeriesDeletionRequest(resp, req.WithContext(ctx))
+		return resp
+	}
+
+	list := func() []SeriesDeletionRequestResponse {
+		resp := httptest.NewRecorder()
+		c.ListSeriesDeletionRequests(resp, httptest.NewRequest(http.MethodGet, "/compactor/delete_series", nil).WithContext(ctx))
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		var out []SeriesDeletionRequestResponse
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &out))
+		return out
+	}
+
+	cancel := func(requestID string) *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		c.CancelSeriesDeletionRequest(resp, httptest.NewRequest(http.MethodDelete, "/compactor/delete_series?request_id="+url.QueryEscape(requestID), nil).WithContext(ctx))
+		return resp
+	}
+
+	t.Run("should fail without tenant", func(t *testing.T) {
+		resp := httptest.NewRecorder()
+		c.CreateSeriesDeletionRequest(resp, httptest.NewRequest(http.MethodPost, "/compactor/delete_series", nil))
+		require.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+
+	t.Run("should fail on invalid input", func(t *testing.T) {
+		require.Equal(t, http.StatusBadRequest, create(url.Values{}).Code)
+		require.Equal(t, http.StatusBadRequest, create(url.Values{"match[]": {"{"}}).Code)
+		require.Equal(t, http.StatusBadRequest, create(url.Values{"match[]": {"up"}, "start": {"2"}, "end": {"1"}}).Code)
+		require.Equal(t, http.StatusBadRequest, create(url.Values{"match[]": {"up"}, "start": {"invalid"}}).Code)
+		require.Empty(t, list())
+	})
+
+	var requestID string
+
+	t.Run("should create a series deletion request", func(t *testing.T) {
+		resp := create(url.Values{"match[]": {`up{job="a"}`, `down`}, "start": {"1"}, "end": {"2"}})
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		var created SeriesDeletionRequestResponse
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &created))
+		assert.Equal(t, []string{`up{job="a"}`, `down`}, created.Selectors)
+		assert.Equal(t, int64(1000), created.StartTime)
+		assert.Equal(t, int64(2000), created.EndTime)
+		assert.Equal(t, mimir_tsdb.SeriesDeletionRequestPending, created.State)
+
+		stored, err := mimir_tsdb.ReadSeriesDeletionRequest(context.Background(), bucket.NewUserBucketClient("fake", bkt, nil), created.RequestID)
+		require.NoError(t, err)
+		assert.Equal(t, created.SeriesDeletionRequest, stored)
+
+		listed := list()
+		require.Len(t, listed, 1)
+		assert.Equal(t, created, listed[0])
+
+		requestID = created.RequestID
+	})
+
+	t.Run("should fail to cancel an invalid or missing request", func(t *testing.T) {
+		require.Equal(t, http.StatusBadRequest, cancel("invalid").Code)
+		require.Equal(t, http.StatusNotFound, cancel("01EQK4QKFHVSZYVJ908Y7HH9E0").Code)
+	})
+
+	t.Run("should fail to cancel a request being processed", func(t *testing.T) {
+		userBkt := bucket.NewUserBucketClient("fake", bkt, nil)
+		processing := mimir_tsdb.NewSeriesDeletionRequest([]string{"up"}, 1, 2, time.Now().Add(-2*time.Hour))
+		require.NoError(t, mimir_tsdb.WriteSeriesDeletionRequest(context.Background(), userBkt, processing))
+		t.Cleanup(func() {
+			require.NoError(t, mimir_tsdb.DeleteSeriesDeletionRequest(context.Background(), userBkt, processing.RequestID))
+		})
+
+		require.Equal(t, http.StatusBadRequest, cancel(processing.RequestID).Code)
+	})
+
+	t.Run("should cancel a pending request", func(t *testing.T) {
+		require.Equal(t, http.StatusNoContent, cancel(requestID).Code)
+		require.Empty(t, list())
+	})
+}
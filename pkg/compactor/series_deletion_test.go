@@ -0,0 +1,201 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package compactor
+
+import (
+	"context"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/services"
+	"github.com/oklog/ulid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/grafana/mimir/pkg/storage/bucket"
+	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
+	"github.com/grafana/mimir/pkg/storage/tsdb/block"
+)
+
+func TestMultitenantCompactor_ShouldApplySeriesDeletionRequests(t *testing.T) {
+	const userID = "user-1"
+
+	bkt := objstore.NewInMemBucket()
+	userBkt := bucket.NewUserBucketClient(userID, bkt, nil)
+
+	appendSeries := func(db *tsdb.DB, lbls labels.Labels) {
+		app := db.Appender(context.Background())
+		for ts := int64(10); ts <= 50; ts += 10 {
+			_, err := app.Append(0, lbls, ts, float64(ts))
+			require.NoError(t, err)
+		}
+		require.NoError(t, app.Commit())
+	}
+
+	// Block containing series matching the series deletion request.
+	matchingBlockID := createCustomTSDBBlock(t, bkt, userID, map[string]string{"external": "1"}, func(db *tsdb.DB) {
+		appendSeries(db, labels.FromStrings(labels.MetricName, "series_a", "pod", "1"))
+		appendSeries(db, labels.FromStrings(labels.MetricName, "series_a", "pod", "2"))
+		appendSeries(db, labels.FromStrings(labels.MetricName, "series_b", "pod", "2"))
+	})
+
+	// Block not containing any series matching the series deletion request.
+	notMatchingBlockID := createCustomTSDBBlock(t, bkt, userID, nil, func(db *tsdb.DB) {
+		appendSeries(db, labels.FromStrings(labels.MetricName, "series_c", "pod", "2"))
+	})
+
+	processingReq := mimir_tsdb.NewSeriesDeletionRequest([]string{`series_a{pod="2"}`, `series_b`}, 20, 40, time.Now().Add(-2*time.Hour))
+	require.NoError(t, mimir_tsdb.WriteSeriesDeletionRequest(context.Background(), userBkt, processingReq))
+
+	pendingReq := mimir_tsdb.NewSeriesDeletionRequest([]string{`series_a`}, 0, 100, time.Now())
+	require.NoError(t, mimir_tsdb.WriteSeriesDeletionRequest(context.Background(), userBkt, pendingReq))
+
+	cfg := prepareConfig(t)
+	cfg.SeriesDeletionDelay = time.Hour
+	c, _, tsdbPlanner, _, registry := prepare(t, cfg, bkt)
+
+	// Compaction jobs are planned but no block is compacted.
+	tsdbPlanner.On("Plan", mock.Anything, mock.Anything).Return([]*block.Meta{}, nil)
+
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), c))
+	t.Cleanup(stopServiceFn(t, c))
+
+	// Wait until the first compaction run has completed.
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(c.compactionRunsCompleted) > 0
+	}, 10*time.Second, 100*time.Millisecond)
+
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+		# HELP cortex_compactor_series_deletion_blocks_rewritten_total Total number of blocks rewritten by the compactor to apply series deletion requests.
+		# TYPE cortex_compactor_series_deletion_blocks_rewritten_total counter
+		cortex_compactor_series_deletion_blocks_rewritten_total 1
+
+		# HELP cortex_compactor_series_deletion_requests_processed_total Total number of series deletion requests fully applied to the blocks in the storage.
+		# TYPE cortex_compactor_series_deletion_requests_processed_total counter
+		cortex_compactor_series_deletion_requests_processed_total 0
+	`), "cortex_compactor_series_deletion_blocks_rewritten_total", "cortex_compactor_series_deletion_requests_processed_total"))
+
+	// The block containing matching series should have been marked for deletion, while the other one not.
+	exists, err := userBkt.Exists(context.Background(), path.Join(matchingBlockID.String(), block.DeletionMarkFilename))
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = userBkt.Exists(context.Background(), path.Join(notMatchingBlockID.String(), block.DeletionMarkFilename))
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// Find the rewritten block.
+	var rewrittenBlockID ulid.ULID
+	require.NoError(t, userBkt.Iter(context.Background(), "", func(name string) error {
+		if id, ok := block.IsBlockDir(name); ok && id != matchingBlockID && id != notMatchingBlockID {
+			rewrittenBlockID = id
+		}
+		return nil
+	}))
+	require.NotEqual(t, ulid.ULID{}, rewrittenBlockID)
+
+	meta, err := block.DownloadMeta(context.Background(), log.NewNopLogger(), userBkt, rewrittenBlockID)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"external": "1"}, meta.Thanos.Labels)
+	assert.Equal(t, []string{processingReq.RequestID}, meta.Thanos.SeriesDeletionRequests)
+
+	assert.Equal(t, map[string][]int64{
+		`{__name__="series_a", pod="1"}`: {10, 20, 30, 40, 50},
+		`{__name__="series_a", pod="2"}`: {10, 50},
+		`{__name__="series_b", pod="2"}`: {10, 50},
+	}, readBlockSamplesTimestamps(t, userBkt, rewrittenBlockID))
+
+	readStates := func() map[string]mimir_tsdb.SeriesDeletionRequestState {
+		reqs, err := mimir_tsdb.ListSeriesDeletionRequests(context.Background(), userBkt)
+		require.NoError(t, err)
+
+		states := map[string]mimir_tsdb.SeriesDeletionRequestState{}
+		for _, req := range reqs {
+			states[req.RequestID] = req.State(time.Now(), cfg.SeriesDeletionDelay)
+		}
+		return states
+	}
+
+	// The request isn't processed yet, because blocks have been rewritten by this run.
+	assert.Equal(t, map[string]mimir_tsdb.SeriesDeletionRequestState{
+		processingReq.RequestID: mimir_tsdb.SeriesDeletionRequestProcessing,
+		pendingReq.RequestID:    mimir_tsdb.SeriesDeletionRequestPending,
+	}, readStates())
+
+	// The next run finds the request applied to all blocks: only the request whose deletion delay has elapsed should have been processed.
+	require.NoError(t, c.applySeriesDeletionRequests(context.Background(), userID, userBkt, log.NewNopLogger()))
+	assert.Equal(t, map[string]mimir_tsdb.SeriesDeletionRequestState{
+		processingReq.RequestID: mimir_tsdb.SeriesDeletionRequestProcessed,
+		pendingReq.RequestID:    mimir_tsdb.SeriesDeletionRequestPending,
+	}, readStates())
+
+	// A block uploaded after the request has been processed is rewritten too.
+	lateBlockID := createCustomTSDBBlock(t, bkt, userID, nil, func(db *tsdb.DB) {
+		appendSeries(db, labels.FromStrings(labels.MetricName, "series_b", "pod", "3"))
+	})
+	require.NoError(t, c.applySeriesDeletionRequests(context.Background(), userID, userBkt, log.NewNopLogger()))
+
+	exists, err = userBkt.Exists(context.Background(), path.Join(lateBlockID.String(), block.DeletionMarkFilename))
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+		# HELP cortex_compactor_series_deletion_blocks_rewritten_total Total number of blocks rewritten by the compactor to apply series deletion requests.
+		# TYPE cortex_compactor_series_deletion_blocks_rewritten_total counter
+		cortex_compactor_series_deletion_blocks_rewritten_total 2
+
+		# HELP cortex_compactor_series_deletion_requests_processed_total Total number of series deletion requests fully applied to the blocks in the storage.
+		# TYPE cortex_compactor_series_deletion_requests_processed_total counter
+		cortex_compactor_series_deletion_requests_processed_total 1
+	`), "cortex_compactor_series_deletion_blocks_rewritten_total", "cortex_compactor_series_deletion_requests_processed_total"))
+}
+
+func TestAppliedSeriesDeletionRequests(t *testing.T) {
+	metaWithRequests := func(ids ...string) *block.Meta {
+		return &block.Meta{Thanos: block.ThanosMeta{SeriesDeletionRequests: ids}}
+	}
+
+	assert.Nil(t, appliedSeriesDeletionRequests(nil))
+	assert.Equal(t, []string{"a", "b"}, appliedSeriesDeletionRequests([]*block.Meta{metaWithRequests("a", "b")}))
+	assert.Equal(t, []string{"b"}, appliedSeriesDeletionRequests([]*block.Meta{metaWithRequests("a", "b"), metaWithRequests("b", "c")}))
+	assert.Nil(t, appliedSeriesDeletionRequests([]*block.Meta{metaWithRequests("a", "b"), metaWithRequests()}))
+}
+
+func readBlockSamplesTimestamps(t *testing.T, bkt objstore.Bucket, id ulid.ULID) map[string][]int64 {
+	dir := filepath.Join(t.TempDir(), id.String())
+	require.NoError(t, block.Download(context.Background(), log.NewNopLogger(), bkt, id, dir))
+
+	b, err := tsdb.OpenBlock(log.NewNopLogger(), dir, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, b.Close()) })
+
+	q, err := tsdb.NewBlockQuerier(b, b.MinTime(), b.MaxTime())
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, q.Close()) })
+
+	out := map[string][]int64{}
+	set := q.Select(context.Background(), true, nil, labels.MustNewMatcher(labels.MatchRegexp, labels.MetricName, ".+"))
+	for set.Next() {
+		var ts []int64
+		it := set.At().Iterator(nil)
+		for it.Next() != chunkenc.ValNone {
+			t, _ := it.At()
+			ts = append(ts, t)
+		}
+		require.NoError(t, it.Err())
+		out[set.At().Labels().String()] = ts
+	}
+	require.NoError(t, set.Err())
+
+	return out
+}
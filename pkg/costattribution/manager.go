@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package costattribution
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/grafana/dskit/services"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const cleanupInterval = 3 * time.Minute
+
+// Limits provides the per-tenant cost attribution configuration.
+type Limits interface {
+	CostAttributionLabels(userID string) []string
+	MaxCostAttributionCardinalityPerUser(userID string) int
+}
+
+// Manager holds the cost attribution trackers of the tenants, exports their metrics, and periodically
+// stops tracking the attribution label values which haven't been seen for a while.
+type Manager struct {
+	services.Service
+
+	limits          Limits
+	inactiveTimeout time.Duration
+
+	mtx      sync.RWMutex
+	trackers map[string]*Tracker
+}
+
+// NewManager makes a new Manager, which stops tracking the attribution label values after they haven't
+// been seen for inactiveTimeout, and registers it as a metrics collector to reg.
+func NewManager(inactiveTimeout time.Duration, limits Limits, reg prometheus.Registerer) *Manager {
+	m := &Manager{
+		limits:          limits,
+		inactiveTimeout: inactiveTimeout,
+		trackers:        map[string]*Tracker{},
+	}
+
+	m.Service = services.NewTimerService(cleanupInterval, nil, m.iteration, nil).WithName("cost attribution cleanup")
+
+	if reg != nil {
+		reg.MustRegister(m)
+	}
+	return m
+}
+
+// Tracker returns the cost attribution tracker of the tenant, or nil if cost attribution is disabled
+// for the tenant. A new tracker is returned whenever the cost attribution configuration of the tenant changes.
+// It's safe to call Tracker on a nil Manager.
+func (m *Manager) Tracker(userID string) *Tracker {
+	if m == nil {
+		return nil
+	}
+
+	names := m.limits.CostAttributionLabels(userID)
+	maxCardinality := m.limits.MaxCostAttributionCardinalityPerUser(userID)
+
+	m.mtx.RLock()
+	t := m.trackers[userID]
+	m.mtx.RUnlock()
+
+	if t != nil && t.hasConfig(names, maxCardinality) {
+		return t
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if len(names) == 0 {
+		delete(m.trackers, userID)
+		return nil
+	}
+
+	// Check again, as the tracker could have been replaced while we were waiting for the lock.
+	if t = m.trackers[userID]; t != nil && t.hasConfig(names, maxCardinality) {
+		return t
+	}
+
+	t = newTracker(userID, names, maxCardinality)
+	m.trackers[userID] = t
+	return t
+}
+
+func (m *Manager) iteration(_ context.Context) error {
+	m.purgeInactive(time.Now().Add(-m.inactiveTimeout))
+	return nil
+}
+
+// purgeInactive stops tracking the attribution label values which haven't been seen since the deadline,
+// and removes the trackers left empty or whose tenant doesn't have cost attribution enabled anymore.
+func (m *Manager) purgeInactive(deadline time.Time) {
+	m.mtx.RLock()
+	userIDs := make([]string, 0, len(m.trackers))
+	for userID := range m.trackers {
+		userIDs = append(userIDs, userID)
+	}
+	m.mtx.RUnlock()
+
+	for _, userID := range userIDs {
+		m.mtx.RLock()
+		t := m.trackers[userID]
+		m.mtx.RUnlock()
+		if t == nil {
+			continue
+		}
+
+		empty := t.purgeInactive(deadline)
+		if !empty && len(m.limits.CostAttributionLabels(userID)) > 0 {
+			continue
+		}
+
+		m.mtx.Lock()
+		// The tracker could have been replaced in the meantime.
+		if m.trackers[userID] == t {
+			delete(m.trackers, userID)
+		}
+		m.mtx.Unlock()
+	}
+}
+
+// Describe implements prometheus.Collector. The metrics of the trackers depend on the tenants' cost attribution
+// labels, so they're not described upfront, making Manager an unchecked collector.
+func (m *Manager) Describe(chan<- *prometheus.Desc) {}
+
+// Collect implements prometheus.Collector.
+func (m *Manager) Collect(out chan<- prometheus.Metric) {
+	m.mtx.RLock()
+	trackers := make([]*Tracker, 0, len(m.trackers))
+	for _, t := range m.trackers {
+		trackers = append(trackers, t)
+	}
+	m.mtx.RUnlock()
+
+	for _, t := range trackers {
+		t.collect(out)
+	}
+}
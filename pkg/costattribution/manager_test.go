@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package costattribution
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockLimits struct {
+	labels         map[string][]string
+	maxCardinality int
+}
+
+func (l *mockLimits) CostAttributionLabels(userID string) []string {
+	return l.labels[userID]
+}
+
+func (l *mockLimits) MaxCostAttributionCardinalityPerUser(string) int {
+	return l.maxCardinality
+}
+
+func TestManager_Tracker(t *testing.T) {
+	limits := &mockLimits{labels: map[string][]string{"user-1": {"team"}}}
+	m := NewManager(time.Minute, limits, nil)
+
+	assert.Nil(t, m.Tracker("user-2"))
+
+	tracker := m.Tracker("user-1")
+	require.NotNil(t, tracker)
+	assert.Same(t, tracker, m.Tracker("user-1"))
+
+	// A new tracker is returned when the configuration changes.
+	limits.maxCardinality = 10
+	updated := m.Tracker("user-1")
+	require.NotNil(t, updated)
+	assert.NotSame(t, tracker, updated)
+
+	limits.labels["user-1"] = nil
+	assert.Nil(t, m.Tracker("user-1"))
+	assert.Empty(t, m.trackers)
+
+	var nilManager *Manager
+	assert.Nil(t, nilManager.Tracker("user-1"))
+}
+
+func TestManager_PurgeInactiveAndCollect(t *testing.T) {
+	limits := &mockLimits{labels: map[string][]string{"user-1": {"team"}, "user-2": {"team"}}}
+	reg := prometheus.NewPedanticRegistry()
+	m := NewManager(time.Minute, limits, reg)
+
+	now := time.Now()
+	m.Tracker("user-1").IncrementReceivedSamples(labels.FromStrings("team", "a"), 1, now.Add(-time.Hour))
+	m.Tracker("user-2").IncrementReceivedSamples(labels.FromStrings("team", "a"), 3, now)
+
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+		# HELP cortex_distributor_received_attributed_samples_total The total number of received samples, attributed by the tenant's cost attribution labels.
+		# TYPE cortex_distributor_received_attributed_samples_total counter
+		cortex_distributor_received_attributed_samples_total{team="a",user="user-1"} 1
+		cortex_distributor_received_attributed_samples_total{team="a",user="user-2"} 3
+	`)))
+
+	m.purgeInactive(now.Add(-time.Minute))
+	assert.Len(t, m.trackers, 1)
+
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+		# HELP cortex_distributor_received_attributed_samples_total The total number of received samples, attributed by the tenant's cost attribution labels.
+		# TYPE cortex_distributor_received_attributed_samples_total counter
+		cortex_distributor_received_attributed_samples_total{team="a",user="user-2"} 3
+	`)))
+
+	// The tracker of a tenant which disabled cost attribution is removed.
+	delete(limits.labels, "user-2")
+	m.purgeInactive(now.Add(-time.Minute))
+	assert.Empty(t, m.trackers)
+}
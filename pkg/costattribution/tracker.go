@@ -0,0 +1,256 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package costattribution
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/model/labels"
+	"go.uber.org/atomic"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	// OverflowValue is the value of all the cost attribution labels of the series attributed
+	// once the tenant's maximum cost attribution cardinality is reached.
+	OverflowValue = "__overflow__"
+
+	// keySeparator separates the label values in the key of an observation.
+	keySeparator = '\xff'
+)
+
+// Tracker tracks the active series, received samples and discarded samples of a tenant,
+// attributed by the values of the tenant's cost attribution labels.
+//
+// All methods are safe to be called on a nil Tracker, which is returned when cost attribution
+// is disabled for a tenant, and do nothing.
+type Tracker struct {
+	userID         string
+	names          []string
+	maxCardinality int
+	overflowKey    string
+
+	activeSeriesDesc     *prometheus.Desc
+	receivedSamplesDesc  *prometheus.Desc
+	discardedSamplesDesc *prometheus.Desc
+
+	mtx          sync.RWMutex
+	observations map[string]*observation
+}
+
+// observation holds the attributed values for a combination of cost attribution label values.
+type observation struct {
+	key    string
+	values []string
+
+	// Unix nanoseconds of the last time the observation has been updated.
+	lastUpdate atomic.Int64
+
+	activeSeries        atomic.Int64
+	activeSeriesTracked atomic.Bool // Whether active series are tracked for this observation, which only happens in ingesters.
+	receivedSamples     atomic.Float64
+
+	discardedMtx     sync.Mutex
+	discardedSamples map[string]float64 // Per reason.
+}
+
+func newTracker(userID string, names []string, maxCardinality int) *Tracker {
+	names = slices.Clone(names)
+
+	overflowValues := make([]string, len(names))
+	for i := range overflowValues {
+		overflowValues[i] = OverflowValue
+	}
+
+	labelNames := append([]string{"user"}, names...)
+
+	return &Tracker{
+		userID:         userID,
+		names:          names,
+		maxCardinality: maxCardinality,
+		overflowKey:    strings.Join(overflowValues, string(keySeparator)),
+		observations:   map[string]*observation{},
+
+		activeSeriesDesc: prometheus.NewDesc(
+			"cortex_ingester_attributed_active_series",
+			"The number of active series, attributed by the tenant's cost attribution labels.",
+			labelNames, nil,
+		),
+		receivedSamplesDesc: prometheus.NewDesc(
+			"cortex_distributor_received_attributed_samples_total",
+			"The total number of received samples, attributed by the tenant's cost attribution labels.",
+			labelNames, nil,
+		),
+		discardedSamplesDesc: prometheus.NewDesc(
+			"cortex_discarded_attributed_samples_total",
+			"The total number of samples that were discarded, attributed by the tenant's cost attribution labels.",
+			append([]string{"reason"}, labelNames...), nil,
+		),
+	}
+}
+
+func (t *Tracker) hasConfig(names []string, maxCardinality int) bool {
+	return t.maxCardinality == maxCardinality && slices.Equal(t.names, names)
+}
+
+// IncrementActiveSeries attributes a new active series, and returns the key of its attribution,
+// which must be passed to DecrementActiveSeries once the series isn't active anymore.
+func (t *Tracker) IncrementActiveSeries(lbls labels.Labels, now time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	var key string
+	t.update(lbls, now, func(o *observation) {
+		o.activeSeriesTracked.Store(true)
+		o.activeSeries.Inc()
+		key = o.key
+	})
+	return key
+}
+
+// DecrementActiveSeries removes an active series from the attribution with the input key, as returned
+// by IncrementActiveSeries.
+func (t *Tracker) DecrementActiveSeries(key string) {
+	if t == nil {
+		return
+	}
+
+	t.mtx.RLock()
+	defer t.mtx.RUnlock()
+
+	// The observation can't be purged while it has active series, so it's always found
+	// unless the tracker has been replaced.
+	if o := t.observations[key]; o != nil {
+		o.activeSeries.Dec()
+	}
+}
+
+// IncrementReceivedSamples attributes count received samples of the series.
+func (t *Tracker) IncrementReceivedSamples(lbls labels.Labels, count float64, now time.Time) {
+	if t == nil || count == 0 {
+		return
+	}
+
+	t.update(lbls, now, func(o *observation) {
+		o.receivedSamples.Add(count)
+	})
+}
+
+// IncrementDiscardedSamples attributes count samples of the series discarded for the input reason.
+func (t *Tracker) IncrementDiscardedSamples(lbls labels.Labels, count float64, reason string, now time.Time) {
+	if t == nil || count == 0 {
+		return
+	}
+
+	t.update(lbls, now, func(o *observation) {
+		o.discardedMtx.Lock()
+		o.discardedSamples[reason] += count
+		o.discardedMtx.Unlock()
+	})
+}
+
+// update calls f with the observation of the series' cost attribution label values, creating it if needed.
+// The observation is updated while holding the read lock, to prevent it from being purged concurrently.
+func (t *Tracker) update(lbls labels.Labels, now time.Time, f func(o *observation)) {
+	var buf [128]byte
+	key := buf[:0]
+	for i, name := range t.names {
+		if i > 0 {
+			key = append(key, keySeparator)
+		}
+		key = append(key, lbls.Get(name)...)
+	}
+
+	t.mtx.RLock()
+	if o := t.observations[string(key)]; o != nil {
+		o.lastUpdate.Store(now.UnixNano())
+		f(o)
+		t.mtx.RUnlock()
+		return
+	}
+	t.mtx.RUnlock()
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	o := t.observations[string(key)]
+	if o == nil {
+		o = t.createObservation(string(key), lbls)
+	}
+	o.lastUpdate.Store(now.UnixNano())
+	f(o)
+}
+
+// createObservation must be called while holding the write lock.
+func (t *Tracker) createObservation(key string, lbls labels.Labels) *observation {
+	cardinality := len(t.observations)
+	if _, ok := t.observations[t.overflowKey]; ok {
+		cardinality--
+	}
+
+	values := make([]string, len(t.names))
+	if t.maxCardinality > 0 && cardinality >= t.maxCardinality {
+		key = t.overflowKey
+		if o := t.observations[key]; o != nil {
+			return o
+		}
+		for i := range values {
+			values[i] = OverflowValue
+		}
+	} else {
+		for i, name := range t.names {
+			// Copy the values, as the labels could reference a request buffer.
+			values[i] = strings.Clone(lbls.Get(name))
+		}
+	}
+
+	o := &observation{
+		key:              key,
+		values:           values,
+		discardedSamples: map[string]float64{},
+	}
+	t.observations[key] = o
+	return o
+}
+
+// purgeInactive removes the observations which haven't been updated since the deadline and don't have
+// active series anymore. It returns whether the tracker is empty.
+func (t *Tracker) purgeInactive(deadline time.Time) bool {
+	deadlineNanos := deadline.UnixNano()
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	for key, o := range t.observations {
+		if o.lastUpdate.Load() <= deadlineNanos && o.activeSeries.Load() <= 0 {
+			delete(t.observations, key)
+		}
+	}
+	return len(t.observations) == 0
+}
+
+func (t *Tracker) collect(out chan<- prometheus.Metric) {
+	t.mtx.RLock()
+	defer t.mtx.RUnlock()
+
+	for _, o := range t.observations {
+		labelValues := append([]string{t.userID}, o.values...)
+
+		if o.activeSeriesTracked.Load() {
+			out <- prometheus.MustNewConstMetric(t.activeSeriesDesc, prometheus.GaugeValue, float64(o.activeSeries.Load()), labelValues...)
+		}
+		if received := o.receivedSamples.Load(); received > 0 {
+			out <- prometheus.MustNewConstMetric(t.receivedSamplesDesc, prometheus.CounterValue, received, labelValues...)
+		}
+
+		o.discardedMtx.Lock()
+		for reason, discarded := range o.discardedSamples {
+			out <- prometheus.MustNewConstMetric(t.discardedSamplesDesc, prometheus.CounterValue, discarded, append([]string{reason}, labelValues...)...)
+		}
+		o.discardedMtx.Unlock()
+	}
+}
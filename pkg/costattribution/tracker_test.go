@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package costattribution
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracker(t *testing.T) {
+	now := time.Now()
+	tracker := newTracker("user-1", []string{"team", "service"}, 0)
+
+	key := tracker.IncrementActiveSeries(labels.FromStrings("__name__", "up", "team", "a", "service", "web"), now)
+	tracker.IncrementActiveSeries(labels.FromStrings("__name__", "up", "team", "a", "service", "web", "pod", "1"), now)
+	tracker.IncrementActiveSeries(labels.FromStrings("__name__", "up", "team", "b"), now)
+	tracker.DecrementActiveSeries(key)
+
+	tracker.IncrementReceivedSamples(labels.FromStrings("__name__", "up", "team", "a", "service", "web"), 5, now)
+	tracker.IncrementDiscardedSamples(labels.FromStrings("__name__", "up", "team", "b"), 2, "rate_limited", now)
+	tracker.IncrementDiscardedSamples(labels.FromStrings("__name__", "up", "team", "b"), 1, "sample-out-of-order", now)
+
+	assert.NoError(t, testutil.CollectAndCompare(collectorFunc(tracker.collect), strings.NewReader(`
+		# HELP cortex_discarded_attributed_samples_total The total number of samples that were discarded, attributed by the tenant's cost attribution labels.
+		# TYPE cortex_discarded_attributed_samples_total counter
+		cortex_discarded_attributed_samples_total{reason="rate_limited",service="",team="b",user="user-1"} 2
+		cortex_discarded_attributed_samples_total{reason="sample-out-of-order",service="",team="b",user="user-1"} 1
+		# HELP cortex_distributor_received_attributed_samples_total The total number of received samples, attributed by the tenant's cost attribution labels.
+		# TYPE cortex_distributor_received_attributed_samples_total counter
+		cortex_distributor_received_attributed_samples_total{service="web",team="a",user="user-1"} 5
+		# HELP cortex_ingester_attributed_active_series The number of active series, attributed by the tenant's cost attribution labels.
+		# TYPE cortex_ingester_attributed_active_series gauge
+		cortex_ingester_attributed_active_series{service="web",team="a",user="user-1"} 1
+		cortex_ingester_attributed_active_series{service="",team="b",user="user-1"} 1
+	`)))
+}
+
+func TestTracker_Overflow(t *testing.T) {
+	now := time.Now()
+	tracker := newTracker("user-1", []string{"team"}, 2)
+
+	for _, team := range []string{"a", "b", "c", "d", "a"} {
+		tracker.IncrementReceivedSamples(labels.FromStrings("team", team), 1, now)
+	}
+
+	assert.NoError(t, testutil.CollectAndCompare(collectorFunc(tracker.collect), strings.NewReader(`
+		# HELP cortex_distributor_received_attributed_samples_total The total number of received samples, attributed by the tenant's cost attribution labels.
+		# TYPE cortex_distributor_received_attributed_samples_total counter
+		cortex_distributor_received_attributed_samples_total{team="a",user="user-1"} 2
+		cortex_distributor_received_attributed_samples_total{team="b",user="user-1"} 1
+		cortex_distributor_received_attributed_samples_total{team="__overflow__",user="user-1"} 2
+	`)))
+}
+
+func TestTracker_PurgeInactive(t *testing.T) {
+	now := time.Now()
+	tracker := newTracker("user-1", []string{"team"}, 0)
+
+	key := tracker.IncrementActiveSeries(labels.FromStrings("team", "a"), now.Add(-time.Hour))
+	tracker.IncrementReceivedSamples(labels.FromStrings("team", "b"), 1, now.Add(-time.Hour))
+	tracker.IncrementReceivedSamples(labels.FromStrings("team", "c"), 1, now)
+
+	// The observation with active series is kept even if it hasn't been updated.
+	require.False(t, tracker.purgeInactive(now.Add(-time.Minute)))
+	assert.Len(t, tracker.observations, 2)
+	assert.Contains(t, tracker.observations, "a")
+	assert.Contains(t, tracker.observations, "c")
+
+	tracker.DecrementActiveSeries(key)
+	require.False(t, tracker.purgeInactive(now.Add(-time.Minute)))
+	assert.Len(t, tracker.observations, 1)
+
+	require.True(t, tracker.purgeInactive(now))
+}
+
+func TestTracker_Nil(t *testing.T) {
+	var tracker *Tracker
+
+	assert.Equal(t, "", tracker.IncrementActiveSeries(labels.FromStrings("team", "a"), time.Now()))
+	tracker.DecrementActiveSeries("a")
+	tracker.IncrementReceivedSamples(labels.FromStrings("team", "a"), 1, time.Now())
+	tracker.IncrementDiscardedSamples(labels.FromStrings("team", "a"), 1, "rate_limited", time.Now())
+}
+
+type collectorFunc func(chan<- prometheus.Metric)
+
+func (f collectorFunc) Describe(chan<- *prometheus.Desc) {}
+
+func (f collectorFunc) Collect(out chan<- prometheus.Metric) { f(out) }
@@ -41,8 +41,12 @@ import (
 	"go.uber.org/atomic"
 	"golang.org/x/exp/slices"
 	"golang.org/x/sync/errgroup"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc/metadata"
 
 	"github.com/grafana/mimir/pkg/cardinality"
+	"github.com/grafana/mimir/pkg/costattribution"
+	"github.com/grafana/mimir/pkg/distributor/distributorpb"
 	ingester_client "github.com/grafana/mimir/pkg/ingester/client"
 	"github.com/grafana/mimir/pkg/mimirpb"
 	"github.com/grafana/mimir/pkg/querier/stats"
@@ -104,6 +108,13 @@ type Distributor struct {
 	requestRateLimiter   *limiter.RateLimiter
 	ingestionRateLimiter *limiter.RateLimiter
 
+	// Per-user rate limiters of the series matching a selector.
+	metricRateLimiters *metricRateLimiters
+
+	// Per-user stream aggregation rules, and the clients used to forward their input series to other distributors.
+	streamAggregators        *streamAggregators
+	streamAggregationClients *ring_client.Pool
+
 	// Manager for subservices (HA Tracker, distributor ring and client pool)
 	subservices        *services.Manager
 	subservicesWatcher *services.FailureWatcher
@@ -111,6 +122,9 @@ type Distributor struct {
 	activeUsers  *util.ActiveUsersCleanupService
 	activeGroups *util.ActiveGroupsCleanupService
 
+	// Cost attribution of the received and discarded samples.
+	costAttribution *costattribution.Manager
+
 	ingestionRate             *util_math.EwmaRate
 	inflightPushRequests      atomic.Int64
 	inflightPushRequestsBytes atomic.Int64
@@ -135,6 +149,7 @@ type Distributor struct {
 	// Metrics for data rejected for hitting per-tenant limits
 	discardedSamplesTooManyHaClusters *prometheus.CounterVec
 	discardedSamplesRateLimited       *prometheus.CounterVec
+	discardedSamplesMetricRateLimited *prometheus.CounterVec
 	discardedRequestsRateLimited      *prometheus.CounterVec
 	discardedExemplarsRateLimited     *prometheus.CounterVec
 	discardedMetadataRateLimited      *prometheus.CounterVec
@@ -225,7 +240,7 @@ const (
 )
 
 // New constructs a new Distributor
-func New(cfg Config, clientConfig ingester_client.Config, limits *validation.Overrides, activeGroupsCleanupService *util.ActiveGroupsCleanupService, ingestersRing ring.ReadRing, canJoinDistributorsRing bool, reg prometheus.Registerer, log log.Logger) (*Distributor, error) {
+func New(cfg Config, clientConfig ingester_client.Config, limits *validation.Overrides, activeGroupsCleanupService *util.ActiveGroupsCleanupService, costAttribution *costattribution.Manager, ingestersRing ring.ReadRing, canJoinDistributorsRing bool, reg prometheus.Registerer, log log.Logger) (*Distributor, error) {
 	clientMetrics := ingester_client.NewMetrics(reg)
 	if cfg.IngesterClientFactory == nil {
 		cfg.IngesterClientFactory = ring_client.PoolInstFunc(func(inst ring.InstanceDesc) (ring_client.PoolClient, error) {
@@ -332,6 +347,7 @@ func New(cfg Config, clientConfig ingester_client.Config, limits *validation.Ove
 
 		discardedSamplesTooManyHaClusters: validation.DiscardedSamplesCounter(reg, reasonTooManyHAClusters),
 		discardedSamplesRateLimited:       validation.DiscardedSamplesCounter(reg, reasonRateLimited),
+		discardedSamplesMetricRateLimited: validation.DiscardedSamplesCounter(reg, reasonMetricRateLimited),
 		discardedRequestsRateLimited:      validation.DiscardedRequestsCounter(reg, reasonRateLimited),
 		discardedExemplarsRateLimited:     validation.DiscardedExemplarsCounter(reg, reasonRateLimited),
 		discardedMetadataRateLimited:      validation.DiscardedMetadataCounter(reg, reasonRateLimited),
@@ -414,6 +430,7 @@ func New(cfg Config, clientConfig ingester_client.Config, limits *validation.Ove
 		subservices = append(subservices, distributorsLifecycler, distributorsRing)
 		requestRateStrategy = newGlobalRateStrategy(newRequestRateStrategy(limits), d)
 		ingestionRateStrategy = newGlobalRateStrategy(newIngestionRateStrategy(limits), d)
+		d.metricRateLimiters = newMetricRateLimiters(limits, d)
 	}
 
 	d.requestRateLimiter = limiter.NewRateLimiter(requestRateStrategy, 10*time.Second)
@@ -424,10 +441,20 @@ func New(cfg Config, clientConfig ingester_client.Config, limits *validation.Ove
 	d.replicationFactor.Set(float64(ingestersRing.ReplicationFactor()))
 	d.activeUsers = util.NewActiveUsersCleanupWithDefaultValues(d.cleanupInactiveUser)
 	d.activeGroups = activeGroupsCleanupService
+	d.costAttribution = costAttribution
 
 	d.PushWithMiddlewares = d.wrapPushWithMiddlewares(d.push)
 
-	subservices = append(subservices, d.ingesterPool, d.activeUsers)
+	// The output series of the stream aggregation rules are sharded across the distributors, if they're in a ring.
+	var streamAggregationRing streamAggregationRing
+	if distributorsRing != nil {
+		streamAggregationRing = distributorsRing
+		d.streamAggregationClients = newStreamAggregationClientPool(clientConfig.GRPCClientConfig, log, reg)
+		subservices = append(subservices, d.streamAggregationClients)
+	}
+	d.streamAggregators = newStreamAggregators(limits, cfg.DistributorRing.Common.InstanceID, streamAggregationRing, d.pushStreamAggregationOutput, d.forwardStreamAggregationInput, log, reg)
+
+	subservices = append(subservices, d.ingesterPool, d.activeUsers, d.streamAggregators)
 	d.subservices, err = services.NewManager(subservices...)
 	if err != nil {
 		return nil, err
@@ -527,6 +554,7 @@ func (d *Distributor) cleanupInactiveUser(userID string) {
 	d.dedupedSamples.DeletePartialMatch(filter)
 	d.discardedSamplesTooManyHaClusters.DeletePartialMatch(filter)
 	d.discardedSamplesRateLimited.DeletePartialMatch(filter)
+	d.discardedSamplesMetricRateLimited.DeletePartialMatch(filter)
 	d.discardedRequestsRateLimited.DeleteLabelValues(userID)
 	d.discardedExemplarsRateLimited.DeleteLabelValues(userID)
 	d.discardedMetadataRateLimited.DeleteLabelValues(userID)
@@ -534,12 +562,16 @@ func (d *Distributor) cleanupInactiveUser(userID string) {
 	d.sampleValidationMetrics.deleteUserMetrics(userID)
 	d.exemplarValidationMetrics.deleteUserMetrics(userID)
 	d.metadataValidationMetrics.deleteUserMetrics(userID)
+
+	d.metricRateLimiters.deleteUser(userID)
+	d.streamAggregators.deleteUser(userID)
 }
 
 func (d *Distributor) RemoveGroupMetricsForUser(userID, group string) {
 	d.dedupedSamples.DeleteLabelValues(userID, group)
 	d.discardedSamplesTooManyHaClusters.DeleteLabelValues(userID, group)
 	d.discardedSamplesRateLimited.DeleteLabelValues(userID, group)
+	d.discardedSamplesMetricRateLimited.DeleteLabelValues(userID, group)
 	d.sampleValidationMetrics.deleteUserMetricsForGroup(userID, group)
 }
 
@@ -583,7 +615,7 @@ func shardByAllLabels(userID string, labels []mimirpb.LabelAdapter) uint32 {
 // Returns a boolean that indicates whether or not we want to remove the replica label going forward,
 // and an error that indicates whether we want to accept samples based on the cluster/replica found in ts.
 // nil for the error means accept the sample.
-func (d *Distributor) checkSample(ctx context.Context, userID, cluster, replica string) (removeReplicaLabel bool, _ error) {
+func (d *Distributor) checkSample(ctx context.Context, userID, cluster, replica string, numSamples int) (removeReplicaLabel bool, _ error) {
 	// If the sample doesn't have either HA label, accept it.
 	// At the moment we want to accept these samples by default.
 	if cluster == "" || replica == "" {
@@ -597,7 +629,7 @@ func (d *Distributor) checkSample(ctx context.Context, userID, cluster, replica
 
 	// At this point we know we have both HA labels, we should lookup
 	// the cluster/instance here to see if we want to accept this sample.
-	err := d.HATracker.checkReplica(ctx, userID, cluster, replica, time.Now())
+	err := d.HATracker.checkReplica(ctx, userID, cluster, replica, numSamples, time.Now())
 	// checkReplica would have returned an error if there was a real error talking to Consul,
 	// or if the replica is not the currently elected replica.
 	if err != nil { // Don't accept the sample.
@@ -610,8 +642,8 @@ func (d *Distributor) checkSample(ctx context.Context, userID, cluster, replica
 // May alter timeseries data in-place.
 // The returned error may retain the series labels.
 // It uses the passed nowt time to observe the delay of sample timestamps.
-func (d *Distributor) validateSeries(nowt time.Time, ts *mimirpb.PreallocTimeseries, userID, group string, skipLabelNameValidation bool, minExemplarTS, maxExemplarTS int64) error {
-	if err := validateLabels(d.sampleValidationMetrics, d.limits, userID, group, ts.Labels, skipLabelNameValidation); err != nil {
+func (d *Distributor) validateSeries(nowt time.Time, ts *mimirpb.PreallocTimeseries, userID, group string, cat *costattribution.Tracker, skipLabelNameValidation bool, minExemplarTS, maxExemplarTS int64) error {
+	if err := validateLabels(d.sampleValidationMetrics, d.limits, userID, group, cat, ts.Labels, skipLabelNameValidation); err != nil {
 		return err
 	}
 
@@ -624,18 +656,19 @@ func (d *Distributor) validateSeries(nowt time.Time, ts *mimirpb.PreallocTimeser
 			d.sampleDelayHistogram.Observe(float64(delta) / 1000)
 		}
 
-		if err := validateSample(d.sampleValidationMetrics, now, d.limits, userID, group, ts.Labels, s); err != nil {
+		if err := validateSample(d.sampleValidationMetrics, now, d.limits, userID, group, cat, ts.Labels, s); err != nil {
 			return err
 		}
 	}
 
-	for _, h := range ts.Histograms {
+	for i := range ts.Histograms {
+		h := &ts.Histograms[i]
 		delta := now - model.Time(h.Timestamp)
 		if delta > 0 {
 			d.sampleDelayHistogram.Observe(float64(delta) / 1000)
 		}
 
-		if err := validateSampleHistogram(d.sampleValidationMetrics, now, d.limits, userID, group, ts.Labels, h); err != nil {
+		if err := validateSampleHistogram(d.sampleValidationMetrics, now, d.limits, userID, group, cat, ts.Labels, h); err != nil {
 			return err
 		}
 	}
@@ -677,6 +710,7 @@ func (d *Distributor) wrapPushWithMiddlewares(next PushFunc) PushFunc {
 	middlewares = append(middlewares, d.prePushHaDedupeMiddleware)
 	middlewares = append(middlewares, d.prePushRelabelMiddleware)
 	middlewares = append(middlewares, d.prePushValidationMiddleware)
+	middlewares = append(middlewares, d.prePushStreamAggregationMiddleware)
 	middlewares = append(middlewares, d.cfg.PushWrappers...)
 
 	for ix := len(middlewares) - 1; ix >= 0; ix-- {
@@ -727,7 +761,7 @@ func (d *Distributor) prePushHaDedupeMiddleware(next PushFunc) PushFunc {
 			numSamples += len(ts.Samples) + len(ts.Histograms)
 		}
 
-		removeReplica, err := d.checkSample(ctx, userID, cluster, replica)
+		removeReplica, err := d.checkSample(ctx, userID, cluster, replica, numSamples)
 		if err != nil {
 			if errors.As(err, &replicasDidNotMatchError{}) {
 				// These samples have been deduped.
@@ -736,6 +770,7 @@ func (d *Distributor) prePushHaDedupeMiddleware(next PushFunc) PushFunc {
 
 			if errors.As(err, &tooManyClustersError{}) {
 				d.discardedSamplesTooManyHaClusters.WithLabelValues(userID, group).Add(float64(numSamples))
+				d.attributeDiscardedSamples(req.Timeseries, userID, reasonTooManyHAClusters, time.Now())
 			}
 
 			return err
@@ -851,6 +886,8 @@ func (d *Distributor) prePushValidationMiddleware(next PushFunc) PushFunc {
 		d.activeUsers.UpdateUserTimestamp(userID, now)
 
 		group := d.activeGroups.UpdateActiveGroupTimestamp(userID, validation.GroupLabel(d.limits, userID, req.Timeseries), now)
+		costAttribution := d.costAttribution.Tracker(userID)
+		metricRateLimiters := d.metricRateLimiters.tenantLimiters(userID, now)
 
 		// A WriteRequest can only contain series or metadata but not both. This might change in the future.
 		validatedMetadata := 0
@@ -887,6 +924,7 @@ func (d *Distributor) prePushValidationMiddleware(next PushFunc) PushFunc {
 
 		var firstPartialErr error
 		var removeIndexes []int
+		var metricRateReservations []*rate.Reservation
 		for tsIdx, ts := range req.Timeseries {
 			if len(ts.Labels) == 0 {
 				removeIndexes = append(removeIndexes, tsIdx)
@@ -897,7 +935,7 @@ func (d *Distributor) prePushValidationMiddleware(next PushFunc) PushFunc {
 
 			skipLabelNameValidation := d.cfg.SkipLabelNameValidation || req.GetSkipLabelNameValidation()
 			// Note that validateSeries may drop some data in ts.
-			validationErr := d.validateSeries(now, &req.Timeseries[tsIdx], userID, group, skipLabelNameValidation, minExemplarTS, maxExemplarTS)
+			validationErr := d.validateSeries(now, &req.Timeseries[tsIdx], userID, group, costAttribution, skipLabelNameValidation, minExemplarTS, maxExemplarTS)
 
 			// Errors in validation are considered non-fatal, as one series in a request may contain
 			// invalid data but all the remaining series could be perfectly valid.
@@ -910,6 +948,26 @@ func (d *Distributor) prePushValidationMiddleware(next PushFunc) PushFunc {
 				continue
 			}
 
+			// Series exceeding the rate limit of the series matching a selector are dropped, so that
+			// a noisy metric doesn't exhaust the tenant ingestion rate limit for all the other series.
+			if len(metricRateLimiters) > 0 {
+				series := mimirpb.FromLabelAdaptersToLabels(ts.Labels)
+				numSamples := len(ts.Samples) + len(ts.Histograms)
+				if l := matchMetricRateLimiter(metricRateLimiters, series); l != nil {
+					r, ok := l.reserve(now, numSamples)
+					if !ok {
+						d.discardedSamplesMetricRateLimited.WithLabelValues(userID, group).Add(float64(numSamples))
+						costAttribution.IncrementDiscardedSamples(series, float64(numSamples), reasonMetricRateLimited, now)
+						if firstPartialErr == nil {
+							firstPartialErr = newValidationError(fmt.Errorf(metricIngestionRateLimitedMsgFormat, l.config.Selector, l.config.Rate, l.config.Burst, series.String()))
+						}
+						removeIndexes = append(removeIndexes, tsIdx)
+						continue
+					}
+					metricRateReservations = append(metricRateReservations, r)
+				}
+			}
+
 			validatedSamples += len(ts.Samples) + len(ts.Histograms)
 			validatedExemplars += len(ts.Exemplars)
 		}
@@ -944,7 +1002,13 @@ func (d *Distributor) prePushValidationMiddleware(next PushFunc) PushFunc {
 
 		totalN := validatedSamples + validatedExemplars + validatedMetadata
 		if !d.ingestionRateLimiter.AllowN(now, userID, totalN) {
+			// The samples aren't ingested, so they're not counted against the rate limits of the series matching a selector.
+			for _, r := range metricRateReservations {
+				r.CancelAt(now)
+			}
+
 			d.discardedSamplesRateLimited.WithLabelValues(userID, group).Add(float64(validatedSamples))
+			d.attributeDiscardedSamples(req.Timeseries, userID, reasonRateLimited, now)
 			d.discardedExemplarsRateLimited.WithLabelValues(userID).Add(float64(validatedExemplars))
 			d.discardedMetadataRateLimited.WithLabelValues(userID).Add(float64(validatedMetadata))
 			return newIngestionRateLimitedError(d.limits.IngestionRate(userID), d.limits.IngestionBurstSize(userID))
@@ -964,6 +1028,77 @@ func (d *Distributor) prePushValidationMiddleware(next PushFunc) PushFunc {
 	}
 }
 
+// prePushStreamAggregationMiddleware aggregates the samples of the series matching the tenant's stream aggregation
+// rules, dropping the series matching the rules which drop their input.
+func (d *Distributor) prePushStreamAggregationMiddleware(next PushFunc) PushFunc {
+	return func(ctx context.Context, pushReq *Request) error {
+		cleanupInDefer := true
+		defer func() {
+			if cleanupInDefer {
+				pushReq.CleanUp()
+			}
+		}()
+
+		// The output series of the stream aggregation rules aren't aggregated again, even if they match a rule.
+		if isStreamAggregationOutput(ctx) {
+			cleanupInDefer = false
+			return next(ctx, pushReq)
+		}
+
+		req, err := pushReq.WriteRequest()
+		if err != nil {
+			return err
+		}
+
+		userID, err := tenant.TenantID(ctx)
+		if err != nil {
+			return err
+		}
+
+		if removeIndexes := d.streamAggregators.aggregate(userID, req.Timeseries, mtime.Now()); len(removeIndexes) > 0 {
+			for _, removeIndex := range removeIndexes {
+				mimirpb.ReusePreallocTimeseries(&req.Timeseries[removeIndex])
+			}
+			req.Timeseries = util.RemoveSliceIndexes(req.Timeseries, removeIndexes)
+		}
+
+		cleanupInDefer = false
+		return next(ctx, pushReq)
+	}
+}
+
+// pushStreamAggregationOutput pushes the output series of the stream aggregation rules of a tenant.
+func (d *Distributor) pushStreamAggregationOutput(ctx context.Context, req *mimirpb.WriteRequest) error {
+	_, err := d.Push(contextWithStreamAggregationOutput(ctx), req)
+	return err
+}
+
+// forwardStreamAggregationInput forwards input series of the stream aggregation rules to the distributor owning their output.
+func (d *Distributor) forwardStreamAggregationInput(ctx context.Context, inst ring.InstanceDesc, req *mimirpb.WriteRequest) error {
+	c, err := d.streamAggregationClients.GetClientForInstance(inst)
+	if err != nil {
+		return err
+	}
+
+	ctx = metadata.AppendToOutgoingContext(ctx, streamAggregationInputMetadataKey, "true")
+	_, err = c.(distributorpb.DistributorClient).Push(ctx, req)
+	return err
+}
+
+// pushStreamAggregationInput aggregates the input series of the stream aggregation rules forwarded by another
+// distributor. The forwarded series have already been validated and ingested by the distributor which received them.
+func (d *Distributor) pushStreamAggregationInput(ctx context.Context, req *mimirpb.WriteRequest) (*mimirpb.WriteResponse, error) {
+	defer mimirpb.ReuseSlice(req.Timeseries)
+
+	userID, err := tenant.TenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	d.streamAggregators.aggregateForwarded(userID, req.Timeseries, mtime.Now())
+	return &mimirpb.WriteResponse{}, nil
+}
+
 // metricsMiddleware updates metrics which are expected to account for all received data,
 // including data that later gets modified or dropped.
 func (d *Distributor) metricsMiddleware(next PushFunc) PushFunc {
@@ -1199,6 +1334,10 @@ func (d *Distributor) limitsMiddleware(next PushFunc) PushFunc {
 
 // Push is gRPC method registered as client.IngesterServer and distributor.DistributorServer.
 func (d *Distributor) Push(ctx context.Context, req *mimirpb.WriteRequest) (*mimirpb.WriteResponse, error) {
+	if isForwardedStreamAggregationInput(ctx) {
+		return d.pushStreamAggregationInput(ctx, req)
+	}
+
 	pushReq := NewParsedRequest(req)
 	pushReq.AddCleanup(func() {
 		mimirpb.ReuseSlice(req.Timeseries)
@@ -1305,6 +1444,13 @@ func (d *Distributor) push(ctx context.Context, pushReq *Request) error {
 		localCtx = ingester_client.WithSlabPool(localCtx, slabPool)
 	}
 
+	// The series are counted before being sent, because the request is cleaned up once sent to all ingesters.
+	rwStats := remoteWriteStatsFromContext(ctx)
+	var rwCounts remoteWriteCounts
+	if rwStats != nil {
+		rwCounts = countRemoteWriteSeries(req.Timeseries)
+	}
+
 	err = ring.DoBatchWithClientError(ctx, ring.WriteNoExtend, subRing, keys,
 		func(ingester ring.InstanceDesc, indexes []int) error {
 			var timeseriesCount, metadataCount int
@@ -1336,6 +1482,9 @@ func (d *Distributor) push(ctx context.Context, pushReq *Request) error {
 		func() { pushReq.CleanUp(); cancel() },
 		isClientError,
 	)
+	if err == nil {
+		rwStats.add(rwCounts)
+	}
 
 	return err
 }
@@ -1370,6 +1519,25 @@ func (d *Distributor) updateReceivedMetrics(req *mimirpb.WriteRequest, userID st
 	d.receivedSamples.WithLabelValues(userID).Add(float64(receivedSamples))
 	d.receivedExemplars.WithLabelValues(userID).Add(float64(receivedExemplars))
 	d.receivedMetadata.WithLabelValues(userID).Add(float64(receivedMetadata))
+
+	if costAttribution := d.costAttribution.Tracker(userID); costAttribution != nil {
+		now := time.Now()
+		for _, ts := range req.Timeseries {
+			costAttribution.IncrementReceivedSamples(mimirpb.FromLabelAdaptersToLabels(ts.Labels), float64(len(ts.Samples)+len(ts.Histograms)), now)
+		}
+	}
+}
+
+// attributeDiscardedSamples attributes the samples of the series discarded for the input reason,
+// if cost attribution is enabled for the tenant.
+func (d *Distributor) attributeDiscardedSamples(series []mimirpb.PreallocTimeseries, userID, reason string, now time.Time) {
+	costAttribution := d.costAttribution.Tracker(userID)
+	if costAttribution == nil {
+		return
+	}
+	for _, ts := range series {
+		costAttribution.IncrementDiscardedSamples(mimirpb.FromLabelAdaptersToLabels(ts.Labels), float64(len(ts.Samples)+len(ts.Histograms)), reason, now)
+	}
 }
 
 func copyString(s string) string {
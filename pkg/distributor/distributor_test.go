@@ -643,6 +643,74 @@ func TestDistributor_PushIngestionRateLimiter(t *testing.T) {
 	}
 }
 
+func TestDistributor_PushMetricIngestionRateLimits(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "user")
+
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.MetricIngestionRateLimits = []*validation.MetricIngestionRateLimit{
+		{Selector: `{__name__="noisy"}`, Rate: 1, Burst: 2},
+		{Selector: `{__name__=~"noisy|foo"}`, Rate: 1000, Burst: 1000},
+	}
+
+	distributors, _, regs := prepare(t, prepConfig{
+		numIngesters:    3,
+		happyIngesters:  3,
+		numDistributors: 1,
+		limits:          limits,
+	})
+
+	// The noisy series are within their burst, so all series are accepted.
+	response, err := distributors[0].Push(ctx, makeWriteRequest(0, 2, 0, false, false, "foo", "noisy"))
+	require.NoError(t, err)
+	assert.Equal(t, emptyResponse, response)
+
+	// The noisy series exceed their rate limit, so they're discarded, while the other series are accepted.
+	response, err = distributors[0].Push(ctx, makeWriteRequest(10, 2, 0, false, false, "foo", "noisy"))
+	assert.Nil(t, response)
+	noisySeries := labels.FromStrings(model.MetricNameLabel, "noisy", "bar", "baz", "sample", "0")
+	expectedErr := status.New(codes.FailedPrecondition, fmt.Sprintf(metricIngestionRateLimitedMsgFormat, `{__name__="noisy"}`, 1.0, 2, noisySeries.String()))
+	checkGRPCError(t, expectedErr, &mimirpb.WriteErrorDetails{Cause: mimirpb.BAD_DATA}, err)
+
+	require.NoError(t, testutil.GatherAndCompare(regs[0], strings.NewReader(`
+		# HELP cortex_discarded_samples_total The total number of samples that were discarded.
+		# TYPE cortex_discarded_samples_total counter
+		cortex_discarded_samples_total{group="",reason="metric_max_ingestion_rate",user="user"} 2
+		# HELP cortex_distributor_received_samples_total The total number of received samples, excluding rejected and deduped samples.
+		# TYPE cortex_distributor_received_samples_total counter
+		cortex_distributor_received_samples_total{user="user"} 6
+	`), "cortex_discarded_samples_total", "cortex_distributor_received_samples_total"))
+}
+
+func TestDistributor_PushMetricIngestionRateLimits_ShouldNotConsumeTokensOfRateLimitedRequests(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "user")
+
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.IngestionRate = 1
+	limits.IngestionBurstSize = 3
+	limits.MetricIngestionRateLimits = []*validation.MetricIngestionRateLimit{
+		{Selector: `{__name__="noisy"}`, Rate: 1, Burst: 2},
+	}
+
+	distributors, _, _ := prepare(t, prepConfig{
+		numIngesters:    3,
+		happyIngesters:  3,
+		numDistributors: 1,
+		limits:          limits,
+	})
+
+	// The request exceeds the tenant ingestion rate limit, so it's rejected as a whole.
+	_, err := distributors[0].Push(ctx, makeWriteRequest(0, 2, 0, false, false, "foo", "noisy"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ingestion rate limit")
+
+	// The noisy series of the rejected request haven't been counted against their rate limit.
+	response, err := distributors[0].Push(ctx, makeWriteRequest(0, 2, 0, false, false, "noisy"))
+	require.NoError(t, err)
+	assert.Equal(t, emptyResponse, response)
+}
+
 func TestDistributor_PushInstanceLimits(t *testing.T) {
 	type testPush struct {
 		samples       int
@@ -902,7 +970,7 @@ func TestDistributor_PushHAInstances(t *testing.T) {
 
 			userID, err := tenant.TenantID(ctx)
 			assert.NoError(t, err)
-			err = d.HATracker.checkReplica(ctx, userID, tc.cluster, tc.acceptedReplica, time.Now())
+			err = d.HATracker.checkReplica(ctx, userID, tc.cluster, tc.acceptedReplica, 1, time.Now())
 			assert.NoError(t, err)
 
 			request := makeWriteRequestForGenerators(tc.samples, labelSetGenWithReplicaAndCluster(tc.testReplica, tc.cluster), nil, nil)
@@ -1601,7 +1669,7 @@ func TestDistributor_ExemplarValidation(t *testing.T) {
 			require.Len(t, regs, 1)
 
 			for _, ts := range tc.req.Timeseries {
-				err := ds[0].validateSeries(now, &ts, "user", "test-group", false, tc.minExemplarTS, tc.maxExemplarTS)
+				err := ds[0].validateSeries(now, &ts, "user", "test-group", nil, false, tc.minExemplarTS, tc.maxExemplarTS)
 				assert.NoError(t, err)
 			}
 
@@ -1843,7 +1911,7 @@ func BenchmarkDistributor_Push(b *testing.B) {
 			require.NoError(b, err)
 
 			// Start the distributor.
-			distributor, err := New(distributorCfg, clientConfig, overrides, nil, ingestersRing, true, nil, log.NewNopLogger())
+			distributor, err := New(distributorCfg, clientConfig, overrides, nil, nil, ingestersRing, true, nil, log.NewNopLogger())
 			require.NoError(b, err)
 			require.NoError(b, services.StartAndAwaitRunning(context.Background(), distributor))
 
@@ -3233,7 +3301,7 @@ func prepare(t *testing.T, cfg prepConfig) ([]*Distributor, []mockIngester, []*p
 		require.NoError(t, err)
 
 		reg := prometheus.NewPedanticRegistry()
-		d, err := New(distributorCfg, clientConfig, overrides, nil, ingestersRing, true, reg, log.NewNopLogger())
+		d, err := New(distributorCfg, clientConfig, overrides, nil, nil, ingestersRing, true, reg, log.NewNopLogger())
 		require.NoError(t, err)
 
 		require.NoError(t, services.StartAndAwaitRunning(context.Background(), d))
@@ -4417,6 +4485,44 @@ func TestDistributor_Push_Relabel(t *testing.T) {
 	}
 }
 
+func TestDistributor_Push_ShouldTrackRemoteWriteStatsAfterRelabeling(t *testing.T) {
+	var limits validation.Limits
+	flagext.DefaultValues(&limits)
+	limits.MetricRelabelConfigs = []*relabel.Config{
+		{
+			SourceLabels: []model.LabelName{"__name__"},
+			Action:       relabel.Drop,
+			Regex:        relabel.MustNewRegexp("dropped"),
+		},
+	}
+
+	ds, _, _ := prepare(t, prepConfig{
+		numIngesters:    2,
+		happyIngesters:  2,
+		numDistributors: 1,
+		limits:          &limits,
+	})
+
+	req := mimirpb.ToWriteRequest(
+		[][]mimirpb.LabelAdapter{
+			mimirpb.FromLabelsToLabelAdapters(labels.FromStrings("__name__", "kept")),
+			mimirpb.FromLabelsToLabelAdapters(labels.FromStrings("__name__", "dropped")),
+		},
+		[]mimirpb.Sample{{TimestampMs: 1, Value: 1}, {TimestampMs: 1, Value: 2}},
+		nil, nil, mimirpb.API,
+	)
+
+	stats := &remoteWriteStats{}
+	ctx := contextWithRemoteWriteStats(user.InjectOrgID(context.Background(), "user"), stats)
+	_, err := ds[0].Push(ctx, req)
+	require.NoError(t, err)
+
+	// Only the samples which have actually been written are counted.
+	assert.Equal(t, int64(1), stats.samples.Load())
+	assert.Equal(t, int64(0), stats.histograms.Load())
+	assert.Equal(t, int64(0), stats.exemplars.Load())
+}
+
 func countMockIngestersCalls(ingesters []mockIngester, name string) int {
 	count := 0
 	for i := 0; i < len(ingesters); i++ {
@@ -5249,7 +5355,7 @@ func TestSendMessageMetadata(t *testing.T) {
 		return mock, nil
 	})
 
-	d, err := New(distributorCfg, clientConfig, validation.MockDefaultOverrides(), nil, ingestersRing, false, nil, log.NewNopLogger())
+	d, err := New(distributorCfg, clientConfig, validation.MockDefaultOverrides(), nil, nil, ingestersRing, false, nil, log.NewNopLogger())
 	require.NoError(t, err)
 	require.NotNil(t, d)
 
@@ -35,6 +35,10 @@ var (
 		validation.IngestionBurstSizeFlag,
 	)
 
+	metricIngestionRateLimitedMsgFormat = globalerror.MetricIngestionRateLimited.Message(
+		"received a series exceeding the ingestion rate limit of the series matching the selector %s, set to %v samples/s across all distributors with a maximum allowed burst of %d. To adjust the related per-tenant limit, configure metric_ingestion_rate_limits, or contact your service administrator. series: '%.200s'",
+	)
+
 	requestRateLimitedMsgFormat = globalerror.RequestRateLimited.MessageWithPerTenantLimitConfig(
 		"the request has been rejected because the tenant exceeded the request rate limit, set to %v requests/s across all distributors with a maximum allowed burst of %d",
 		validation.RequestRateFlag,
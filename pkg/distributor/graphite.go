@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package distributor
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/dskit/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+	"github.com/grafana/mimir/pkg/util/validation"
+)
+
+const graphiteParseError = "graphite_parse_error"
+
+// GraphiteHandler is a http.Handler which accepts Graphite plaintext protocol and sends the resulting
+// samples to the push function.
+//
+// The metric path is converted to a metric name by replacing the characters not allowed in Prometheus
+// metric names, including dots, with underscores. Graphite tags are converted to labels.
+func GraphiteHandler(
+	maxRecvMsgSize int,
+	sourceIPs *middleware.SourceIPExtractor,
+	limits *validation.Overrides,
+	reg prometheus.Registerer,
+	push PushFunc,
+) http.Handler {
+	discardedDueToGraphiteParseError := validation.DiscardedSamplesCounter(reg, graphiteParseError)
+
+	return textPushHandler(maxRecvMsgSize, sourceIPs, limits, "graphite", discardedDueToGraphiteParseError, func(*http.Request) (textLineParser, error) {
+		return parseGraphiteLine, nil
+	}, push)
+}
+
+// parseGraphiteLine parses a line of Graphite plaintext protocol, in the format
+// `path[;tag=value...] value [timestamp]`, and appends the resulting series to dst.
+// The timestamp is in seconds, and a missing or -1 timestamp means now. Each line holds a single sample,
+// which is discarded if the line is invalid.
+func parseGraphiteLine(line []byte, now time.Time, dst []mimirpb.PreallocTimeseries) ([]mimirpb.PreallocTimeseries, int, error) {
+	fields := bytes.Fields(line)
+	if len(fields) != 2 && len(fields) != 3 {
+		return dst, 1, fmt.Errorf("invalid line %q: expected a metric path, a value and an optional timestamp separated by spaces", line)
+	}
+
+	value, err := strconv.ParseFloat(string(fields[1]), 64)
+	if err != nil {
+		return dst, 1, fmt.Errorf("invalid value in line %q: %w", line, err)
+	}
+
+	timestampMs := now.UnixMilli()
+	if len(fields) == 3 {
+		ts, err := strconv.ParseFloat(string(fields[2]), 64)
+		if err != nil || math.IsNaN(ts) || math.IsInf(ts, 0) {
+			return dst, 1, fmt.Errorf("invalid timestamp in line %q", line)
+		}
+		if ts != -1 {
+			timestampMs = int64(ts * 1000)
+		}
+	}
+
+	path := strings.Split(string(fields[0]), ";")
+	if path[0] == "" {
+		return dst, 1, fmt.Errorf("missing metric path in line %q", line)
+	}
+
+	labels := make([]mimirpb.LabelAdapter, 0, len(path))
+	labels = append(labels, mimirpb.LabelAdapter{Name: model.MetricNameLabel, Value: sanitizeMetricName(path[0])})
+	for _, tag := range path[1:] {
+		name, tagValue, ok := strings.Cut(tag, "=")
+		if !ok || name == "" {
+			return dst, 1, fmt.Errorf("invalid tag %q in line %q", tag, line)
+		}
+		// Prometheus doesn't distinguish empty labels from missing ones.
+		if tagValue == "" {
+			continue
+		}
+		labels = append(labels, mimirpb.LabelAdapter{Name: sanitizeLabelName(name), Value: tagValue})
+	}
+
+	return append(dst, newTextPushSeries(labels, value, timestampMs)), 0, nil
+}
@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package distributor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+	"github.com/grafana/mimir/pkg/util/validation"
+)
+
+func TestParseGraphiteLine(t *testing.T) {
+	now := time.UnixMilli(1700000000000)
+
+	tests := map[string]struct {
+		line        string
+		expected    []mimirpb.PreallocTimeseries
+		expectedErr string
+	}{
+		"path, value and timestamp": {
+			line:     "servers.web-01.cpu.load 0.75 1700000100",
+			expected: []mimirpb.PreallocTimeseries{textPushTestSeries(0.75, 1700000100000, "__name__", "servers_web_01_cpu_load")},
+		},
+		"fractional timestamp": {
+			line:     "requests 10 1700000100.5",
+			expected: []mimirpb.PreallocTimeseries{textPushTestSeries(10, 1700000100500, "__name__", "requests")},
+		},
+		"missing or -1 timestamp": {
+			line:     "requests 10 -1",
+			expected: []mimirpb.PreallocTimeseries{textPushTestSeries(10, now.UnixMilli(), "__name__", "requests")},
+		},
+		"tags": {
+			line:     "disk.used;mount=/var;datacenter=dc1;empty= 42",
+			expected: []mimirpb.PreallocTimeseries{textPushTestSeries(42, now.UnixMilli(), "__name__", "disk_used", "datacenter", "dc1", "mount", "/var")},
+		},
+		"path starting with a digit": {
+			line:     "1min.load 3",
+			expected: []mimirpb.PreallocTimeseries{textPushTestSeries(3, now.UnixMilli(), "__name__", "_1min_load")},
+		},
+		"missing value": {
+			line:        "requests",
+			expectedErr: "expected a metric path, a value and an optional timestamp",
+		},
+		"invalid value": {
+			line:        "requests ten",
+			expectedErr: "invalid value",
+		},
+		"invalid timestamp": {
+			line:        "requests 10 now",
+			expectedErr: "invalid timestamp",
+		},
+		"invalid tag": {
+			line:        "requests;mount 10",
+			expectedErr: "invalid tag",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, discarded, err := parseGraphiteLine([]byte(tc.line), now, nil)
+			if tc.expectedErr != "" {
+				require.ErrorContains(t, err, tc.expectedErr)
+				assert.Equal(t, 1, discarded)
+				assert.Empty(t, actual)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, 0, discarded)
+			assert.Equal(t, tc.expected, normalizeTextPushSeries(actual))
+		})
+	}
+}
+
+func TestGraphiteHandler(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	var series []string
+
+	handler := GraphiteHandler(100000, nil, validation.MockDefaultOverrides(), reg, func(ctx context.Context, pushReq *Request) error {
+		request, err := pushReq.WriteRequest()
+		if err != nil {
+			return err
+		}
+		defer pushReq.CleanUp()
+
+		for _, ts := range request.Timeseries {
+			series = append(series, mimirpb.FromLabelAdaptersToLabels(ts.Labels).String())
+		}
+		return nil
+	})
+
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, createTextPushRequest(t, "http://localhost/api/v1/push/graphite", "a.b 1 1700000000\nc.d;env=prod 2\ninvalid\n", false))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, []string{`{__name__="a_b"}`, `{__name__="c_d", env="prod"}`}, series)
+
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+		# HELP cortex_discarded_samples_total The total number of samples that were discarded.
+		# TYPE cortex_discarded_samples_total counter
+		cortex_discarded_samples_total{group="",reason="graphite_parse_error",user="test"} 1
+	`), "cortex_discarded_samples_total"))
+}
@@ -33,8 +33,23 @@ var (
 	errNegativeUpdateTimeoutJitterMax = errors.New("HA tracker max update timeout jitter shouldn't be negative")
 	errInvalidFailoverTimeout         = "HA Tracker failover timeout (%v) must be at least 1s greater than update timeout - max jitter (%v)"
 	errMemberlistUnsupported          = errors.New("memberlist is not supported by the HA tracker since gossip propagation is too slow for HA purposes")
+	errInvalidFailoverPolicy          = fmt.Errorf("HA tracker failover policy must be one of: %s", strings.Join(haTrackerFailoverPolicies, ", "))
+	errInvalidSampleRateWindow        = errors.New("HA tracker failover sample rate window must be greater than 0")
+	errInvalidSampleRateRatio         = errors.New("HA tracker failover sample rate ratio must be greater than 0 and less than 1")
 )
 
+const (
+	// HATrackerFailoverPolicyTimeout fails over to another replica only once the elected replica
+	// hasn't sent samples for the failover timeout.
+	HATrackerFailoverPolicyTimeout = "timeout"
+
+	// HATrackerFailoverPolicySampleRate additionally fails over to another replica when the elected replica
+	// sends considerably fewer samples than it.
+	HATrackerFailoverPolicySampleRate = "sample-rate"
+)
+
+var haTrackerFailoverPolicies = []string{HATrackerFailoverPolicyTimeout, HATrackerFailoverPolicySampleRate}
+
 type haTrackerLimits interface {
 	// MaxHAClusters returns max number of clusters that HA tracker should track for a user.
 	// Samples from additional clusters are rejected.
@@ -66,6 +81,10 @@ type HATrackerConfig struct {
 	// more than this duration
 	FailoverTimeout time.Duration `yaml:"ha_tracker_failover_timeout" category:"advanced"`
 
+	FailoverPolicy           string        `yaml:"ha_tracker_failover_policy" category:"experimental"`
+	FailoverSampleRateWindow time.Duration `yaml:"ha_tracker_failover_sample_rate_window" category:"experimental"`
+	FailoverSampleRateRatio  float64       `yaml:"ha_tracker_failover_sample_rate_ratio" category:"experimental"`
+
 	KVStore kv.Config `yaml:"kvstore" doc:"description=Backend storage to use for the ring. Please be aware that memberlist is not supported by the HA tracker since gossip propagation is too slow for HA purposes."`
 }
 
@@ -75,6 +94,9 @@ func (cfg *HATrackerConfig) RegisterFlags(f *flag.FlagSet) {
 	f.DurationVar(&cfg.UpdateTimeout, "distributor.ha-tracker.update-timeout", 15*time.Second, "Update the timestamp in the KV store for a given cluster/replica only after this amount of time has passed since the current stored timestamp.")
 	f.DurationVar(&cfg.UpdateTimeoutJitterMax, "distributor.ha-tracker.update-timeout-jitter-max", 5*time.Second, "Maximum jitter applied to the update timeout, in order to spread the HA heartbeats over time.")
 	f.DurationVar(&cfg.FailoverTimeout, "distributor.ha-tracker.failover-timeout", 30*time.Second, "If we don't receive any samples from the accepted replica for a cluster in this amount of time we will failover to the next replica we receive a sample from. This value must be greater than the update timeout")
+	f.StringVar(&cfg.FailoverPolicy, "distributor.ha-tracker.failover-policy", HATrackerFailoverPolicyTimeout, fmt.Sprintf("Policy used to fail over to another replica of a cluster. Supported values: %s. With %q, the distributors fail over only when they don't receive samples from the elected replica for the failover timeout. With %q, the distributors also fail over when the elected replica sends fewer samples than the failover sample rate ratio of the samples sent by another replica over the failover sample rate window.", strings.Join(haTrackerFailoverPolicies, ", "), HATrackerFailoverPolicyTimeout, HATrackerFailoverPolicySampleRate))
+	f.DurationVar(&cfg.FailoverSampleRateWindow, "distributor.ha-tracker.failover-sample-rate-window", time.Minute, "Window over which the number of samples received from the replicas of a cluster is compared, when the sample-rate failover policy is used.")
+	f.Float64Var(&cfg.FailoverSampleRateRatio, "distributor.ha-tracker.failover-sample-rate-ratio", 0.5, "When the sample-rate failover policy is used, fail over to another replica if the elected replica sends fewer samples than this ratio of the samples sent by the other replica over the failover sample rate window.")
 
 	// We want the ability to use different Consul instances for the ring and
 	// for HA cluster tracking. We also customize the default keys prefix, in
@@ -98,6 +120,18 @@ func (cfg *HATrackerConfig) Validate() error {
 		return errMemberlistUnsupported
 	}
 
+	if !util.StringsContain(haTrackerFailoverPolicies, cfg.FailoverPolicy) {
+		return errInvalidFailoverPolicy
+	}
+	if cfg.FailoverPolicy == HATrackerFailoverPolicySampleRate {
+		if cfg.FailoverSampleRateWindow <= 0 {
+			return errInvalidSampleRateWindow
+		}
+		if cfg.FailoverSampleRateRatio <= 0 || cfg.FailoverSampleRateRatio >= 1 {
+			return errInvalidSampleRateRatio
+		}
+	}
+
 	return nil
 }
 
@@ -121,6 +155,8 @@ type haTracker struct {
 
 	electedReplicaChanges         *prometheus.CounterVec
 	electedReplicaTimestamp       *prometheus.GaugeVec
+	sampleRateFailovers           *prometheus.CounterVec
+	forcedElections               *prometheus.CounterVec
 	electedReplicaPropagationTime prometheus.Histogram
 	kvCASCalls                    *prometheus.CounterVec
 
@@ -136,6 +172,47 @@ type haClusterInfo struct {
 	electedLastSeenTimestamp    int64
 	nonElectedLastSeenReplica   string
 	nonElectedLastSeenTimestamp int64
+
+	// Samples received from each replica, only tracked with the sample-rate failover policy.
+	replicaSamples map[string]*replicaSamples
+}
+
+// replicaSamples counts the samples received from a replica over consecutive windows.
+type replicaSamples struct {
+	firstSeen   int64 // Unix milliseconds.
+	windowStart int64 // Unix milliseconds.
+	current     int64 // Samples received in the window starting at windowStart.
+	previous    int64 // Samples received in the window before the current one.
+}
+
+func (r *replicaSamples) add(numSamples int, now, window int64) {
+	switch elapsed := now - r.windowStart; {
+	case elapsed < window:
+	case elapsed < 2*window:
+		r.previous, r.current = r.current, 0
+		r.windowStart += window
+	default:
+		r.previous, r.current = 0, 0
+		r.windowStart = now
+	}
+	r.current += int64(numSamples)
+}
+
+// lastWindowSamples returns the number of samples received in the last complete window before now,
+// and whether the replica has been seen for long enough to have a complete window.
+func (r *replicaSamples) lastWindowSamples(now, window int64) (int64, bool) {
+	if now-r.firstSeen < window {
+		return 0, false
+	}
+
+	switch elapsed := now - r.windowStart; {
+	case elapsed < window:
+		return r.previous, true
+	case elapsed < 2*window:
+		return r.current, true
+	default:
+		return 0, true
+	}
 }
 
 // newHATracker returns a new HA cluster tracker using either Consul
@@ -161,6 +238,14 @@ func newHATracker(cfg HATrackerConfig, limits haTrackerLimits, reg prometheus.Re
 			Name: "cortex_ha_tracker_elected_replica_timestamp_seconds",
 			Help: "The timestamp stored for the currently elected replica, from the KVStore.",
 		}, []string{"user", "cluster"}),
+		sampleRateFailovers: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_ha_tracker_sample_rate_failovers_total",
+			Help: "The total number of times the distributor failed over to another replica for a user ID/cluster because the elected replica sent fewer samples.",
+		}, []string{"user", "cluster"}),
+		forcedElections: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_ha_tracker_forced_elections_total",
+			Help: "The total number of replicas elected through the force-elect API for a user ID/cluster.",
+		}, []string{"user", "cluster"}),
 		electedReplicaPropagationTime: promauto.With(reg).NewHistogram(prometheus.HistogramOpts{
 			Name:    "cortex_ha_tracker_elected_replica_change_propagation_time_seconds",
 			Help:    "The time it for the distributor to update the replica change.",
@@ -240,6 +325,8 @@ func (h *haTracker) loop(ctx context.Context) error {
 		if replica.DeletedAt > 0 {
 			h.electedReplicaChanges.DeleteLabelValues(user, cluster)
 			h.electedReplicaTimestamp.DeleteLabelValues(user, cluster)
+			h.sampleRateFailovers.DeleteLabelValues(user, cluster)
+			h.forcedElections.DeleteLabelValues(user, cluster)
 
 			h.electedLock.Lock()
 			defer h.electedLock.Unlock()
@@ -307,6 +394,17 @@ func (h *haTracker) updateKVStoreAll(ctx context.Context, now time.Time) {
 			}
 			var replica string
 			if h.withinUpdateTimeout(now, entry.electedLastSeenTimestamp) {
+				if healthier := h.healthierReplica(entry, now); healthier != "" {
+					// The elected replica sends considerably fewer samples than another one: fail over to the latter.
+					elected := entry.elected.Replica
+					h.electedLock.RUnlock()
+					err := h.electReplica(ctx, userID, cluster, elected, healthier, false, now)
+					h.electedLock.RLock()
+					if err != nil {
+						level.Error(h.logger).Log("msg", "failed to fail over to replica sending more samples", "user", userID, "cluster", cluster, "replica", healthier, "err", err)
+					}
+					continue
+				}
 				// We have seen the elected replica recently; carry on with that choice.
 				replica = entry.elected.Replica
 			} else if h.withinUpdateTimeout(now, entry.nonElectedLastSeenTimestamp) {
@@ -327,6 +425,42 @@ func (h *haTracker) updateKVStoreAll(ctx context.Context, now time.Time) {
 	}
 }
 
+// healthierReplica returns the replica to fail over to with the sample-rate failover policy, if the elected
+// replica sent fewer samples than the configured ratio of the samples sent by another replica in the last window,
+// or an empty string otherwise. Must be called with electedLock held.
+func (h *haTracker) healthierReplica(entry *haClusterInfo, now time.Time) string {
+	if h.cfg.FailoverPolicy != HATrackerFailoverPolicySampleRate || entry.elected.Forced {
+		return ""
+	}
+
+	nowMs, window := timestamp.FromTime(now), h.cfg.FailoverSampleRateWindow.Milliseconds()
+
+	elected := entry.replicaSamples[entry.elected.Replica]
+	if elected == nil {
+		return ""
+	}
+	electedSamples, ok := elected.lastWindowSamples(nowMs, window)
+	if !ok {
+		return ""
+	}
+
+	var best string
+	var bestSamples int64
+	for replica, samples := range entry.replicaSamples {
+		if replica == entry.elected.Replica {
+			continue
+		}
+		if n, ok := samples.lastWindowSamples(nowMs, window); ok && n > bestSamples {
+			best, bestSamples = replica, n
+		}
+	}
+
+	if best == "" || float64(electedSamples) >= h.cfg.FailoverSampleRateRatio*float64(bestSamples) {
+		return ""
+	}
+	return best
+}
+
 // Replicas marked for deletion before deadline will be deleted.
 // Replicas with last-received timestamp before deadline will be marked for deletion.
 func (h *haTracker) cleanupOldReplicas(ctx context.Context, deadline time.Time) {
@@ -407,7 +541,7 @@ func (h *haTracker) cleanupOldReplicas(ctx context.Context, deadline time.Time)
 // Updates to and from the KV store are handled in the background, except
 // if we have no cached data for this cluster in which case we create the
 // record and store it in-band.
-func (h *haTracker) checkReplica(ctx context.Context, userID, cluster, replica string, now time.Time) error {
+func (h *haTracker) checkReplica(ctx context.Context, userID, cluster, replica string, numSamples int, now time.Time) error {
 	// If HA tracking isn't enabled then accept the sample
 	if !h.cfg.EnableHATracker {
 		return nil
@@ -415,6 +549,10 @@ func (h *haTracker) checkReplica(ctx context.Context, userID, cluster, replica s
 
 	h.electedLock.Lock()
 	if entry := h.clusters[userID][cluster]; entry != nil {
+		if h.cfg.FailoverPolicy == HATrackerFailoverPolicySampleRate {
+			h.recordReplicaSamples(entry, replica, numSamples, now)
+		}
+
 		var err error
 		if entry.elected.Replica == replica {
 			// Sample received is from elected replica: update timestamp and carry on.
@@ -443,7 +581,29 @@ func (h *haTracker) checkReplica(ctx context.Context, userID, cluster, replica s
 		return err
 	}
 	// Cache will now have the value - recurse to check it again.
-	return h.checkReplica(ctx, userID, cluster, replica, now)
+	return h.checkReplica(ctx, userID, cluster, replica, numSamples, now)
+}
+
+// Must be called with electedLock held.
+func (h *haTracker) recordReplicaSamples(entry *haClusterInfo, replica string, numSamples int, now time.Time) {
+	nowMs, window := timestamp.FromTime(now), h.cfg.FailoverSampleRateWindow.Milliseconds()
+
+	samples := entry.replicaSamples[replica]
+	if samples == nil {
+		if entry.replicaSamples == nil {
+			entry.replicaSamples = map[string]*replicaSamples{}
+		}
+		// Stop tracking the replicas which haven't sent samples for the last two windows.
+		for r, s := range entry.replicaSamples {
+			if nowMs-s.windowStart >= 2*window {
+				delete(entry.replicaSamples, r)
+			}
+		}
+
+		samples = &replicaSamples{firstSeen: nowMs, windowStart: nowMs}
+		entry.replicaSamples[replica] = samples
+	}
+	samples.add(numSamples, nowMs, window)
 }
 
 func (h *haTracker) withinUpdateTimeout(now time.Time, receivedAt int64) bool {
@@ -473,7 +633,7 @@ func (h *haTracker) updateKVStore(ctx context.Context, userID, cluster, replica
 	key := fmt.Sprintf("%s/%s", userID, cluster)
 	var desc *ReplicaDesc
 	err := h.client.CAS(ctx, key, func(in interface{}) (out interface{}, retry bool, err error) {
-		var ok bool
+		var ok, forced bool
 		if desc, ok = in.(*ReplicaDesc); ok && desc.DeletedAt == 0 {
 			// If the entry in KVStore is up-to-date, just stop the loop.
 			if h.withinUpdateTimeout(now, desc.ReceivedAt) ||
@@ -481,6 +641,8 @@ func (h *haTracker) updateKVStore(ctx context.Context, userID, cluster, replica
 				desc.Replica != replica && now.Sub(timestamp.Time(desc.ReceivedAt)) < h.cfg.FailoverTimeout {
 				return nil, false, nil
 			}
+			// A forced election is kept as long as the same replica is elected.
+			forced = desc.Forced && desc.Replica == replica
 		}
 
 		// Attempt to update KVStore to our timestamp and replica.
@@ -488,6 +650,7 @@ func (h *haTracker) updateKVStore(ctx context.Context, userID, cluster, replica
 			Replica:    replica,
 			ReceivedAt: timestamp.FromTime(now),
 			DeletedAt:  0,
+			Forced:     forced,
 		}
 		return desc, true, nil
 	})
@@ -503,6 +666,48 @@ func (h *haTracker) updateKVStore(ctx context.Context, userID, cluster, replica
 	return err
 }
 
+// electReplica elects the replica for the cluster in the KV store, regardless of the failover timeout,
+// if the replica currently elected is the expected one. An empty expected replica elects the replica regardless
+// of the one currently elected. A forced election can only be overridden by another forced election, or
+// by the failover timeout.
+func (h *haTracker) electReplica(ctx context.Context, userID, cluster, expected, replica string, forced bool, now time.Time) error {
+	key := fmt.Sprintf("%s/%s", userID, cluster)
+	var desc *ReplicaDesc
+	err := h.client.CAS(ctx, key, func(in interface{}) (out interface{}, retry bool, err error) {
+		desc = nil
+		if current, ok := in.(*ReplicaDesc); ok && current.DeletedAt == 0 {
+			// Another distributor may have elected a different replica in the meantime.
+			if (expected != "" && current.Replica != expected) || (current.Forced && !forced) {
+				return nil, false, nil
+			}
+		}
+
+		desc = &ReplicaDesc{
+			Replica:    replica,
+			ReceivedAt: timestamp.FromTime(now),
+			Forced:     forced,
+		}
+		return desc, true, nil
+	})
+	h.kvCASCalls.WithLabelValues(userID, cluster).Inc()
+	if err != nil || desc == nil {
+		return err
+	}
+
+	if forced {
+		h.forcedElections.WithLabelValues(userID, cluster).Inc()
+	} else {
+		h.sampleRateFailovers.WithLabelValues(userID, cluster).Inc()
+	}
+	level.Info(h.logger).Log("msg", "elected replica", "user", userID, "cluster", cluster, "replica", replica, "forced", forced)
+
+	// Update the cache right away, without waiting for the KV store to notify the change.
+	h.electedLock.Lock()
+	h.updateCache(userID, cluster, desc)
+	h.electedLock.Unlock()
+	return nil
+}
+
 func findHALabels(replicaLabel, clusterLabel string, labels []mimirpb.LabelAdapter) (string, string) {
 	var cluster, replica string
 	var pair mimirpb.LabelAdapter
@@ -524,5 +729,7 @@ func (h *haTracker) cleanupHATrackerMetricsForUser(userID string) {
 
 	h.electedReplicaChanges.DeletePartialMatch(filter)
 	h.electedReplicaTimestamp.DeletePartialMatch(filter)
+	h.sampleRateFailovers.DeletePartialMatch(filter)
+	h.forcedElections.DeletePartialMatch(filter)
 	h.kvCASCalls.DeletePartialMatch(filter)
 }
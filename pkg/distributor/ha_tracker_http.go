@@ -7,11 +7,13 @@ package distributor
 
 import (
 	_ "embed" // Used to embed html template
+	"fmt"
 	"html/template"
 	"net/http"
 	"sort"
 	"time"
 
+	"github.com/go-kit/log/level"
 	"github.com/prometheus/prometheus/model/timestamp"
 
 	"github.com/grafana/mimir/pkg/util"
@@ -33,6 +35,7 @@ type haTrackerReplica struct {
 	ElectedAt    time.Time     `json:"electedAt"`
 	UpdateTime   time.Duration `json:"updateDuration"`
 	FailoverTime time.Duration `json:"failoverDuration"`
+	Forced       bool          `json:"forced"`
 }
 
 func (h *haTracker) ServeHTTP(w http.ResponseWriter, req *http.Request) {
@@ -49,6 +52,7 @@ func (h *haTracker) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 				ElectedAt:    timestamp.Time(desc.ReceivedAt),
 				UpdateTime:   time.Until(timestamp.Time(desc.ReceivedAt).Add(h.cfg.UpdateTimeout)),
 				FailoverTime: time.Until(timestamp.Time(desc.ReceivedAt).Add(h.cfg.FailoverTimeout)),
+				Forced:       desc.Forced,
 			})
 		}
 	}
@@ -69,3 +73,27 @@ func (h *haTracker) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		Now:     time.Now(),
 	}, haTrackerStatusPageTemplate, req)
 }
+
+// ForceElectHandler elects the replica of a cluster given in the request, regardless of the currently elected
+// replica and of the failover policy, and stores the election in the KV store. The elected replica is kept
+// until it stops sending samples for the failover timeout.
+func (h *haTracker) ForceElectHandler(w http.ResponseWriter, req *http.Request) {
+	if !h.cfg.EnableHATracker {
+		http.Error(w, "the HA tracker is not enabled", http.StatusNotFound)
+		return
+	}
+
+	userID, cluster, replica := req.FormValue("user"), req.FormValue("cluster"), req.FormValue("replica")
+	if userID == "" || cluster == "" || replica == "" {
+		http.Error(w, "the user, cluster and replica parameters are required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.electReplica(req.Context(), userID, cluster, "", replica, true, time.Now()); err != nil {
+		level.Error(h.logger).Log("msg", "failed to force-elect replica", "user", userID, "cluster", cluster, "replica", replica, "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	util.WriteTextResponse(w, fmt.Sprintf("Replica %q elected for cluster %q of tenant %q\n", replica, cluster, userID))
+}
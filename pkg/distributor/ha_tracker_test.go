@@ -8,6 +8,8 @@ package distributor
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
@@ -125,6 +127,37 @@ func TestHATrackerConfig_Validate(t *testing.T) {
 			}(),
 			expectedErr: errMemberlistUnsupported,
 		},
+		"should fail if the failover policy is unknown": {
+			cfg: func() HATrackerConfig {
+				cfg := HATrackerConfig{}
+				flagext.DefaultValues(&cfg)
+				cfg.FailoverPolicy = "unknown"
+
+				return cfg
+			}(),
+			expectedErr: errInvalidFailoverPolicy,
+		},
+		"should fail if the failover sample rate ratio is invalid with the sample-rate failover policy": {
+			cfg: func() HATrackerConfig {
+				cfg := HATrackerConfig{}
+				flagext.DefaultValues(&cfg)
+				cfg.FailoverPolicy = HATrackerFailoverPolicySampleRate
+				cfg.FailoverSampleRateRatio = 1
+
+				return cfg
+			}(),
+			expectedErr: errInvalidSampleRateRatio,
+		},
+		"should pass with the sample-rate failover policy": {
+			cfg: func() HATrackerConfig {
+				cfg := HATrackerConfig{}
+				flagext.DefaultValues(&cfg)
+				cfg.FailoverPolicy = HATrackerFailoverPolicySampleRate
+
+				return cfg
+			}(),
+			expectedErr: nil,
+		},
 	}
 
 	for testName, testData := range tests {
@@ -158,7 +191,7 @@ func TestWatchPrefixAssignment(t *testing.T) {
 	// Write the first time.
 	now := time.Now()
 
-	err = c.checkReplica(context.Background(), "user", cluster, replica, now)
+	err = c.checkReplica(context.Background(), "user", cluster, replica, 1, now)
 	assert.NoError(t, err)
 
 	// Check to see if the value in the trackers cache is correct.
@@ -183,18 +216,18 @@ func TestCheckReplicaOverwriteTimeout(t *testing.T) {
 	now := time.Now()
 
 	// Write the first time.
-	err = c.checkReplica(context.Background(), "user", "test", replica1, now)
+	err = c.checkReplica(context.Background(), "user", "test", replica1, 1, now)
 	assert.NoError(t, err)
 
 	// Throw away a sample from replica2.
-	err = c.checkReplica(context.Background(), "user", "test", replica2, now)
+	err = c.checkReplica(context.Background(), "user", "test", replica2, 1, now)
 	assert.Error(t, err)
 
 	// Wait more than the overwrite timeout.
 	now = now.Add(1100 * time.Millisecond)
 
 	// Another sample from replica2 to update its timestamp.
-	err = c.checkReplica(context.Background(), "user", "test", replica2, now)
+	err = c.checkReplica(context.Background(), "user", "test", replica2, 1, now)
 	assert.Error(t, err)
 
 	// Update KVStore - this should elect replica 2.
@@ -203,14 +236,127 @@ func TestCheckReplicaOverwriteTimeout(t *testing.T) {
 	checkReplicaTimestamp(t, time.Second, c, "user", "test", replica2, now)
 
 	// Now we should accept from replica 2.
-	err = c.checkReplica(context.Background(), "user", "test", replica2, now)
+	err = c.checkReplica(context.Background(), "user", "test", replica2, 1, now)
 	assert.NoError(t, err)
 
 	// We timed out accepting samples from replica 1 and should now reject them.
-	err = c.checkReplica(context.Background(), "user", "test", replica1, now)
+	err = c.checkReplica(context.Background(), "user", "test", replica1, 1, now)
 	assert.Error(t, err)
 }
 
+func TestCheckReplicaSampleRateFailover(t *testing.T) {
+	replica1 := "replica1"
+	replica2 := "replica2"
+
+	reg := prometheus.NewPedanticRegistry()
+	c, err := newHATracker(HATrackerConfig{
+		EnableHATracker:          true,
+		KVStore:                  kv.Config{Store: "inmemory"},
+		UpdateTimeout:            100 * time.Millisecond,
+		UpdateTimeoutJitterMax:   0,
+		FailoverTimeout:          time.Minute,
+		FailoverPolicy:           HATrackerFailoverPolicySampleRate,
+		FailoverSampleRateWindow: time.Second,
+		FailoverSampleRateRatio:  0.5,
+	}, trackerLimits{maxClusters: 100}, reg, log.NewNopLogger())
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), c))
+	defer services.StopAndAwaitTerminated(context.Background(), c) //nolint:errcheck
+
+	// Use timestamps in the past, so that the background KV store updates don't interfere with the test.
+	start := time.Now().Add(-time.Hour)
+
+	// Both replicas send the same number of samples in the first window.
+	require.NoError(t, c.checkReplica(context.Background(), "user", "sample-rate", replica1, 100, start))
+	require.Error(t, c.checkReplica(context.Background(), "user", "sample-rate", replica2, 100, start))
+
+	// The elected replica sends fewer samples in the second window.
+	now := start.Add(1100 * time.Millisecond)
+	require.NoError(t, c.checkReplica(context.Background(), "user", "sample-rate", replica1, 10, now))
+	require.Error(t, c.checkReplica(context.Background(), "user", "sample-rate", replica2, 100, now))
+
+	// The first window is comparable, so the elected replica is kept.
+	c.updateKVStoreAll(context.Background(), now)
+	checkReplicaTimestamp(t, time.Second, c, "user", "sample-rate", replica1, now)
+
+	now = start.Add(2 * time.Second)
+	require.NoError(t, c.checkReplica(context.Background(), "user", "sample-rate", replica1, 10, now))
+	require.Error(t, c.checkReplica(context.Background(), "user", "sample-rate", replica2, 100, now))
+
+	// In the second window, the elected replica sent less than half of the samples of the other one.
+	now = now.Add(50 * time.Millisecond)
+	c.updateKVStoreAll(context.Background(), now)
+	checkReplicaTimestamp(t, time.Second, c, "user", "sample-rate", replica2, now)
+
+	require.NoError(t, c.checkReplica(context.Background(), "user", "sample-rate", replica2, 100, now))
+	require.Error(t, c.checkReplica(context.Background(), "user", "sample-rate", replica1, 10, now))
+
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+		# HELP cortex_ha_tracker_sample_rate_failovers_total The total number of times the distributor failed over to another replica for a user ID/cluster because the elected replica sent fewer samples.
+		# TYPE cortex_ha_tracker_sample_rate_failovers_total counter
+		cortex_ha_tracker_sample_rate_failovers_total{cluster="sample-rate",user="user"} 1
+	`), "cortex_ha_tracker_sample_rate_failovers_total"))
+}
+
+func TestHATracker_ForceElectHandler(t *testing.T) {
+	replica1 := "replica1"
+	replica2 := "replica2"
+
+	c, err := newHATracker(HATrackerConfig{
+		EnableHATracker:          true,
+		KVStore:                  kv.Config{Store: "inmemory"},
+		UpdateTimeout:            100 * time.Millisecond,
+		UpdateTimeoutJitterMax:   0,
+		FailoverTimeout:          time.Minute,
+		FailoverPolicy:           HATrackerFailoverPolicySampleRate,
+		FailoverSampleRateWindow: time.Second,
+		FailoverSampleRateRatio:  0.5,
+	}, trackerLimits{maxClusters: 100}, nil, log.NewNopLogger())
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), c))
+	defer services.StopAndAwaitTerminated(context.Background(), c) //nolint:errcheck
+
+	start := time.Now()
+	require.NoError(t, c.checkReplica(context.Background(), "user", "force-elect", replica1, 100, start))
+
+	forceElect := func(form string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/distributor/ha_tracker/elect", strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		resp := httptest.NewRecorder()
+		c.ForceElectHandler(resp, req)
+		return resp
+	}
+
+	assert.Equal(t, http.StatusBadRequest, forceElect("user=user&cluster=force-elect").Code)
+
+	resp := forceElect("user=user&cluster=force-elect&replica=" + replica2)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+	c.electedLock.RLock()
+	elected := c.clusters["user"]["force-elect"].elected
+	c.electedLock.RUnlock()
+	assert.Equal(t, replica2, elected.Replica)
+	assert.True(t, elected.Forced)
+
+	val, err := c.client.Get(context.Background(), "user/force-elect")
+	require.NoError(t, err)
+	assert.Equal(t, replica2, val.(*ReplicaDesc).Replica)
+	assert.True(t, val.(*ReplicaDesc).Forced)
+
+	// The forced election isn't overridden by the sample-rate failover policy, even if the other replica sends more samples.
+	for now := start; now.Before(start.Add(2500 * time.Millisecond)); now = now.Add(500 * time.Millisecond) {
+		require.Error(t, c.checkReplica(context.Background(), "user", "force-elect", replica1, 100, now))
+		require.NoError(t, c.checkReplica(context.Background(), "user", "force-elect", replica2, 10, now))
+	}
+	now := start.Add(2050 * time.Millisecond)
+	c.updateKVStoreAll(context.Background(), now)
+	checkReplicaTimestamp(t, time.Second, c, "user", "force-elect", replica2, now)
+
+	c.electedLock.RLock()
+	assert.True(t, c.clusters["user"]["force-elect"].elected.Forced)
+	c.electedLock.RUnlock()
+}
+
 func TestCheckReplicaMultiCluster(t *testing.T) {
 	replica1 := "replica1"
 	replica2 := "replica2"
@@ -230,21 +376,21 @@ func TestCheckReplicaMultiCluster(t *testing.T) {
 	now := time.Now()
 
 	// Write the first time.
-	err = c.checkReplica(context.Background(), "user", "c1", replica1, now)
+	err = c.checkReplica(context.Background(), "user", "c1", replica1, 1, now)
 	assert.NoError(t, err)
-	err = c.checkReplica(context.Background(), "user", "c2", replica1, now)
+	err = c.checkReplica(context.Background(), "user", "c2", replica1, 1, now)
 	assert.NoError(t, err)
 
 	// Reject samples from replica 2 in each cluster.
-	err = c.checkReplica(context.Background(), "user", "c1", replica2, now)
+	err = c.checkReplica(context.Background(), "user", "c1", replica2, 1, now)
 	assert.Error(t, err)
-	err = c.checkReplica(context.Background(), "user", "c2", replica2, now)
+	err = c.checkReplica(context.Background(), "user", "c2", replica2, 1, now)
 	assert.Error(t, err)
 
 	// We should still accept from replica 1.
-	err = c.checkReplica(context.Background(), "user", "c1", replica1, now)
+	err = c.checkReplica(context.Background(), "user", "c1", replica1, 1, now)
 	assert.NoError(t, err)
-	err = c.checkReplica(context.Background(), "user", "c2", replica1, now)
+	err = c.checkReplica(context.Background(), "user", "c2", replica1, 1, now)
 	assert.NoError(t, err)
 
 	// We expect no CAS operation failures.
@@ -280,45 +426,45 @@ func TestCheckReplicaMultiClusterTimeout(t *testing.T) {
 	now := time.Now()
 
 	// Write the first time.
-	err = c.checkReplica(context.Background(), "user", "c1", replica1, now)
+	err = c.checkReplica(context.Background(), "user", "c1", replica1, 1, now)
 	assert.NoError(t, err)
-	err = c.checkReplica(context.Background(), "user", "c2", replica1, now)
+	err = c.checkReplica(context.Background(), "user", "c2", replica1, 1, now)
 	assert.NoError(t, err)
 
 	// Reject samples from replica 2 in each cluster.
-	err = c.checkReplica(context.Background(), "user", "c1", replica2, now)
+	err = c.checkReplica(context.Background(), "user", "c1", replica2, 1, now)
 	assert.Error(t, err)
-	err = c.checkReplica(context.Background(), "user", "c2", replica2, now)
+	err = c.checkReplica(context.Background(), "user", "c2", replica2, 1, now)
 	assert.Error(t, err)
 
 	// Accept a sample for replica1 in C2.
 	now = now.Add(500 * time.Millisecond)
-	err = c.checkReplica(context.Background(), "user", "c2", replica1, now)
+	err = c.checkReplica(context.Background(), "user", "c2", replica1, 1, now)
 	assert.NoError(t, err)
 
 	// Reject samples from replica 2 in each cluster.
-	err = c.checkReplica(context.Background(), "user", "c1", replica2, now)
+	err = c.checkReplica(context.Background(), "user", "c1", replica2, 1, now)
 	assert.Error(t, err)
-	err = c.checkReplica(context.Background(), "user", "c2", replica2, now)
+	err = c.checkReplica(context.Background(), "user", "c2", replica2, 1, now)
 	assert.Error(t, err)
 
 	// Wait more than the failover timeout.
 	now = now.Add(1100 * time.Millisecond)
 
 	// Another sample from c1/replica2 to update its timestamp.
-	err = c.checkReplica(context.Background(), "user", "c1", replica2, now)
+	err = c.checkReplica(context.Background(), "user", "c1", replica2, 1, now)
 	assert.Error(t, err)
 	c.updateKVStoreAll(context.Background(), now)
 	checkReplicaTimestamp(t, time.Second, c, "user", "c1", replica2, now)
 
 	// Accept a sample from c1/replica2.
-	err = c.checkReplica(context.Background(), "user", "c1", replica2, now)
+	err = c.checkReplica(context.Background(), "user", "c1", replica2, 1, now)
 	assert.NoError(t, err)
 
 	// We should still accept from c2/replica1 but reject from c1/replica1.
-	err = c.checkReplica(context.Background(), "user", "c1", replica1, now)
+	err = c.checkReplica(context.Background(), "user", "c1", replica1, 1, now)
 	assert.Error(t, err)
-	err = c.checkReplica(context.Background(), "user", "c2", replica1, now)
+	err = c.checkReplica(context.Background(), "user", "c2", replica1, 1, now)
 	assert.NoError(t, err)
 
 	// We expect no CAS operation failures.
@@ -359,13 +505,13 @@ func TestCheckReplicaUpdateTimeout(t *testing.T) {
 
 	// Write the first time.
 	startTime := time.Now()
-	err = c.checkReplica(context.Background(), user, cluster, replica, startTime)
+	err = c.checkReplica(context.Background(), user, cluster, replica, 1, startTime)
 	assert.NoError(t, err)
 
 	checkReplicaTimestamp(t, time.Second, c, user, cluster, replica, startTime)
 
 	// Timestamp should not update here, since time has not advanced.
-	err = c.checkReplica(context.Background(), user, cluster, replica, startTime)
+	err = c.checkReplica(context.Background(), user, cluster, replica, 1, startTime)
 	assert.NoError(t, err)
 
 	checkReplicaTimestamp(t, time.Second, c, user, cluster, replica, startTime)
@@ -374,7 +520,7 @@ func TestCheckReplicaUpdateTimeout(t *testing.T) {
 	updateTime := time.Unix(0, startTime.UnixNano()).Add(500 * time.Millisecond)
 	c.updateKVStoreAll(context.Background(), updateTime)
 
-	err = c.checkReplica(context.Background(), user, cluster, replica, updateTime)
+	err = c.checkReplica(context.Background(), user, cluster, replica, 1, updateTime)
 	assert.NoError(t, err)
 	checkReplicaTimestamp(t, time.Second, c, user, cluster, replica, startTime)
 
@@ -382,7 +528,7 @@ func TestCheckReplicaUpdateTimeout(t *testing.T) {
 	updateTime = time.Unix(0, startTime.UnixNano()).Add(1100 * time.Millisecond)
 	c.updateKVStoreAll(context.Background(), updateTime)
 
-	err = c.checkReplica(context.Background(), user, cluster, replica, updateTime)
+	err = c.checkReplica(context.Background(), user, cluster, replica, 1, updateTime)
 	assert.NoError(t, err)
 	checkReplicaTimestamp(t, time.Second, c, user, cluster, replica, updateTime)
 }
@@ -411,18 +557,18 @@ func TestCheckReplicaMultiUser(t *testing.T) {
 	now := time.Now()
 
 	// Write the first time for user 1.
-	err = c.checkReplica(context.Background(), "user1", cluster, replica, now)
+	err = c.checkReplica(context.Background(), "user1", cluster, replica, 1, now)
 	assert.NoError(t, err)
 	checkReplicaTimestamp(t, time.Second, c, "user1", cluster, replica, now)
 
 	// Write the first time for user 2.
-	err = c.checkReplica(context.Background(), "user2", cluster, replica, now)
+	err = c.checkReplica(context.Background(), "user2", cluster, replica, 1, now)
 	assert.NoError(t, err)
 	checkReplicaTimestamp(t, time.Second, c, "user2", cluster, replica, now)
 
 	// Now we've waited > 1s, so the timestamp should update.
 	updated := now.Add(1100 * time.Millisecond)
-	err = c.checkReplica(context.Background(), "user1", cluster, replica, updated)
+	err = c.checkReplica(context.Background(), "user1", cluster, replica, 1, updated)
 	assert.NoError(t, err)
 	c.updateKVStoreAll(context.Background(), updated)
 
@@ -499,12 +645,12 @@ func TestCheckReplicaUpdateTimeoutJitter(t *testing.T) {
 			c.updateTimeoutJitter = testData.updateJitter
 
 			// Init the replica in the KV Store
-			err = c.checkReplica(ctx, "user1", "cluster", "replica-1", testData.startTime)
+			err = c.checkReplica(ctx, "user1", "cluster", "replica-1", 1, testData.startTime)
 			require.NoError(t, err)
 			checkReplicaTimestamp(t, time.Second, c, "user1", "cluster", "replica-1", testData.startTime)
 
 			// Refresh the replica in the KV Store
-			err = c.checkReplica(ctx, "user1", "cluster", "replica-1", testData.updateTime)
+			err = c.checkReplica(ctx, "user1", "cluster", "replica-1", 1, testData.updateTime)
 			require.NoError(t, err)
 			c.updateKVStoreAll(context.Background(), testData.updateTime)
 
@@ -595,26 +741,26 @@ func TestHAClustersLimit(t *testing.T) {
 
 	now := time.Now()
 
-	assert.NoError(t, t1.checkReplica(context.Background(), userID, "a", "a1", now))
+	assert.NoError(t, t1.checkReplica(context.Background(), userID, "a", "a1", 1, now))
 	waitForClustersUpdate(t, 1, t1, userID)
 
-	assert.NoError(t, t1.checkReplica(context.Background(), userID, "b", "b1", now))
+	assert.NoError(t, t1.checkReplica(context.Background(), userID, "b", "b1", 1, now))
 	waitForClustersUpdate(t, 2, t1, userID)
 
 	expectedErr := newTooManyClustersError(2)
-	assert.EqualError(t, t1.checkReplica(context.Background(), userID, "c", "c1", now), expectedErr.Error())
+	assert.EqualError(t, t1.checkReplica(context.Background(), userID, "c", "c1", 1, now), expectedErr.Error())
 
 	// Move time forward, and make sure that checkReplica for existing cluster works fine.
 	now = now.Add(5 * time.Second) // higher than "update timeout"
 
 	// Another sample to update internal timestamp.
-	err = t1.checkReplica(context.Background(), userID, "b", "b2", now)
+	err = t1.checkReplica(context.Background(), userID, "b", "b2", 1, now)
 	assert.Error(t, err)
 	// Update KVStore.
 	t1.updateKVStoreAll(context.Background(), now)
 	checkReplicaTimestamp(t, time.Second, t1, userID, "b", "b2", now)
 
-	assert.NoError(t, t1.checkReplica(context.Background(), userID, "b", "b2", now))
+	assert.NoError(t, t1.checkReplica(context.Background(), userID, "b", "b2", 1, now))
 	waitForClustersUpdate(t, 2, t1, userID)
 
 	// Mark cluster "a" for deletion (it was last updated 5 seconds ago)
@@ -623,12 +769,12 @@ func TestHAClustersLimit(t *testing.T) {
 	waitForClustersUpdate(t, 1, t1, userID)
 
 	// Now adding cluster "c" works.
-	assert.NoError(t, t1.checkReplica(context.Background(), userID, "c", "c1", now))
+	assert.NoError(t, t1.checkReplica(context.Background(), userID, "c", "c1", 1, now))
 	waitForClustersUpdate(t, 2, t1, userID)
 
 	// But yet another cluster doesn't.
 	expectedErr = newTooManyClustersError(2)
-	assert.EqualError(t, t1.checkReplica(context.Background(), userID, "a", "a2", now), expectedErr.Error())
+	assert.EqualError(t, t1.checkReplica(context.Background(), userID, "a", "a2", 1, now), expectedErr.Error())
 
 	now = now.Add(5 * time.Second)
 
@@ -637,7 +783,7 @@ func TestHAClustersLimit(t *testing.T) {
 	waitForClustersUpdate(t, 0, t1, userID)
 
 	// Now "a" works again.
-	assert.NoError(t, t1.checkReplica(context.Background(), userID, "a", "a1", now))
+	assert.NoError(t, t1.checkReplica(context.Background(), userID, "a", "a1", 1, now))
 	waitForClustersUpdate(t, 1, t1, userID)
 }
 
@@ -742,7 +888,7 @@ func TestCheckReplicaCleanup(t *testing.T) {
 
 	now := time.Now()
 
-	err = c.checkReplica(context.Background(), userID, cluster, replica, now)
+	err = c.checkReplica(context.Background(), userID, cluster, replica, 1, now)
 	assert.NoError(t, err)
 	checkReplicaTimestamp(t, time.Second, c, userID, cluster, replica, now)
 
@@ -759,7 +905,7 @@ func TestCheckReplicaCleanup(t *testing.T) {
 
 	// This will "revive" the replica.
 	now = time.Now()
-	err = c.checkReplica(context.Background(), userID, cluster, replica, now)
+	err = c.checkReplica(context.Background(), userID, cluster, replica, 1, now)
 	assert.NoError(t, err)
 	checkReplicaTimestamp(t, time.Second, c, userID, cluster, replica, now) // This also checks that entry is not marked for deletion.
 	checkUserClusters(t, time.Second, c, userID, 1)
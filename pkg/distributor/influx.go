@@ -0,0 +1,266 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package distributor
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+	"github.com/grafana/mimir/pkg/util/validation"
+)
+
+const (
+	influxParseError = "influx_parse_error"
+
+	// influxValueField is the name of the field whose metric name is the measurement name alone.
+	influxValueField = "value"
+)
+
+// InfluxHandler is a http.Handler which accepts InfluxDB line protocol and sends the resulting
+// samples to the push function.
+//
+// Each field of a line is converted to a series named after the measurement and the field,
+// labeled with the line tags. String fields are ignored, and boolean fields are converted to 0 and 1. The lines
+// with only string fields are discarded, like the invalid ones.
+func InfluxHandler(
+	maxRecvMsgSize int,
+	sourceIPs *middleware.SourceIPExtractor,
+	limits *validation.Overrides,
+	reg prometheus.Registerer,
+	push PushFunc,
+) http.Handler {
+	discardedDueToInfluxParseError := validation.DiscardedSamplesCounter(reg, influxParseError)
+
+	// InfluxDB clients expect a 204 No Content response on success.
+	return noContentOnSuccess(textPushHandler(maxRecvMsgSize, sourceIPs, limits, "influx", discardedDueToInfluxParseError, func(r *http.Request) (textLineParser, error) {
+		precision, err := influxPrecision(r.URL.Query().Get("precision"))
+		if err != nil {
+			return nil, err
+		}
+		return func(line []byte, now time.Time, dst []mimirpb.PreallocTimeseries) ([]mimirpb.PreallocTimeseries, int, error) {
+			return parseInfluxLine(line, precision, now, dst)
+		}, nil
+	}, push))
+}
+
+// influxPrecision returns the duration of a unit of the input InfluxDB timestamps precision.
+func influxPrecision(precision string) (time.Duration, error) {
+	switch precision {
+	case "", "n", "ns":
+		return time.Nanosecond, nil
+	case "u", "us", "µ":
+		return time.Microsecond, nil
+	case "ms":
+		return time.Millisecond, nil
+	case "s":
+		return time.Second, nil
+	case "m":
+		return time.Minute, nil
+	case "h":
+		return time.Hour, nil
+	default:
+		return 0, httpgrpc.Errorf(http.StatusBadRequest, "unsupported precision %q", precision)
+	}
+}
+
+// parseInfluxLine parses a line of InfluxDB line protocol, in the format
+// `measurement[,tag=value...] field=value[,field=value...] [timestamp]`, and appends a series for each
+// numeric or boolean field to dst. If the line is invalid, it returns the number of its fields, whose samples are discarded.
+func parseInfluxLine(line []byte, precision time.Duration, now time.Time, dst []mimirpb.PreallocTimeseries) ([]mimirpb.PreallocTimeseries, int, error) {
+	sections := influxSplit(line, ' ', true)
+	if len(sections) != 2 && len(sections) != 3 {
+		return dst, 1, fmt.Errorf("invalid line %q: expected a measurement, fields and an optional timestamp separated by spaces", line)
+	}
+	fields := influxSplit(sections[1], ',', true)
+
+	timestampMs := now.UnixMilli()
+	if len(sections) == 3 {
+		ts, err := strconv.ParseInt(string(sections[2]), 10, 64)
+		if err != nil {
+			return dst, len(fields), fmt.Errorf("invalid timestamp in line %q: %w", line, err)
+		}
+		timestampMs = influxTimestampMs(ts, precision)
+	}
+
+	key := influxSplit(sections[0], ',', false)
+	measurement := influxUnescape(key[0])
+	if measurement == "" {
+		return dst, len(fields), fmt.Errorf("missing measurement in line %q", line)
+	}
+
+	// The first label is reserved to the metric name.
+	labels := make([]mimirpb.LabelAdapter, 1, len(key))
+	for _, tag := range key[1:] {
+		name, value, err := influxKeyValue(tag)
+		if err != nil {
+			return dst, len(fields), fmt.Errorf("invalid tag in line %q: %w", line, err)
+		}
+		// Prometheus doesn't distinguish empty labels from missing ones.
+		if value == "" {
+			continue
+		}
+		labels = append(labels, mimirpb.LabelAdapter{Name: sanitizeLabelName(name), Value: value})
+	}
+
+	// Parse all the fields first, so that the line is either fully accepted or fully rejected.
+	type field struct {
+		name  string
+		value float64
+	}
+	parsed := make([]field, 0, len(fields))
+	for _, f := range fields {
+		name, rawValue, err := influxKeyValue(f)
+		if err != nil {
+			return dst, len(fields), fmt.Errorf("invalid field in line %q: %w", line, err)
+		}
+		value, ok, err := parseInfluxFieldValue(rawValue)
+		if err != nil {
+			return dst, len(fields), fmt.Errorf("invalid value of field %q in line %q: %w", name, line, err)
+		}
+		if ok {
+			parsed = append(parsed, field{name: name, value: value})
+		}
+	}
+	if len(parsed) == 0 {
+		return dst, len(fields), fmt.Errorf("no numeric or boolean fields in line %q", line)
+	}
+
+	for _, f := range parsed {
+		metricName := measurement
+		if f.name != influxValueField {
+			metricName = measurement + "_" + f.name
+		}
+
+		seriesLabels := make([]mimirpb.LabelAdapter, len(labels))
+		copy(seriesLabels, labels)
+		seriesLabels[0] = mimirpb.LabelAdapter{Name: model.MetricNameLabel, Value: sanitizeMetricName(metricName)}
+		dst = append(dst, newTextPushSeries(seriesLabels, f.value, timestampMs))
+	}
+	return dst, 0, nil
+}
+
+// parseInfluxFieldValue parses the value of a field. It returns false if the field is a string, which can't be ingested.
+func parseInfluxFieldValue(value string) (float64, bool, error) {
+	if value == "" {
+		return 0, false, fmt.Errorf("empty value")
+	}
+
+	switch value {
+	case "t", "T", "true", "True", "TRUE":
+		return 1, true, nil
+	case "f", "F", "false", "False", "FALSE":
+		return 0, true, nil
+	}
+
+	switch value[len(value)-1] {
+	case '"':
+		if len(value) < 2 || value[0] != '"' {
+			return 0, false, fmt.Errorf("unterminated string %s", value)
+		}
+		return 0, false, nil
+	case 'i':
+		v, err := strconv.ParseInt(value[:len(value)-1], 10, 64)
+		return float64(v), err == nil, err
+	case 'u':
+		v, err := strconv.ParseUint(value[:len(value)-1], 10, 64)
+		return float64(v), err == nil, err
+	}
+
+	v, err := strconv.ParseFloat(value, 64)
+	if err == nil && (math.IsNaN(v) || math.IsInf(v, 0)) {
+		// InfluxDB doesn't support them either, so they're likely a client bug.
+		return 0, false, fmt.Errorf("unsupported value %s", value)
+	}
+	return v, err == nil, err
+}
+
+// influxTimestampMs converts a timestamp of the input precision to milliseconds.
+func influxTimestampMs(ts int64, precision time.Duration) int64 {
+	if precision >= time.Millisecond {
+		return ts * int64(precision/time.Millisecond)
+	}
+	return ts / int64(time.Millisecond/precision)
+}
+
+// influxKeyValue splits a `key=value` pair at the first unescaped equal sign, and unescapes the key and value.
+func influxKeyValue(pair []byte) (string, string, error) {
+	for i := 0; i < len(pair); i++ {
+		switch pair[i] {
+		case '\\':
+			i++
+		case '=':
+			key := influxUnescape(pair[:i])
+			if key == "" {
+				return "", "", fmt.Errorf("empty key in %q", pair)
+			}
+			value := pair[i+1:]
+			if len(value) > 0 && value[0] == '"' {
+				// String field values are left as they are, because they're not ingested anyway.
+				return key, string(value), nil
+			}
+			return key, influxUnescape(value), nil
+		}
+	}
+	return "", "", fmt.Errorf("missing equal sign in %q", pair)
+}
+
+// influxSplit splits s at each unescaped occurrence of sep, skipping empty tokens when sep is a space.
+// If quotes is true, the occurrences of sep in double-quoted strings are ignored.
+func influxSplit(s []byte, sep byte, quotes bool) [][]byte {
+	var (
+		tokens  [][]byte
+		start   int
+		inQuote bool
+	)
+
+	appendToken := func(token []byte) {
+		if sep == ' ' && len(token) == 0 {
+			return
+		}
+		tokens = append(tokens, token)
+	}
+
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\':
+			i++
+		case quotes && s[i] == '"':
+			inQuote = !inQuote
+		case s[i] == sep && !inQuote:
+			appendToken(s[start:i])
+			start = i + 1
+		}
+	}
+	appendToken(s[start:])
+	return tokens
+}
+
+// influxUnescape removes the backslashes escaping the special characters of the line protocol, and
+// returns a copy of the input.
+func influxUnescape(s []byte) string {
+	if bytes.IndexByte(s, '\\') < 0 {
+		return string(s)
+	}
+
+	out := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			switch s[i+1] {
+			case ',', '=', ' ', '"', '\\':
+				i++
+			}
+		}
+		out = append(out, s[i])
+	}
+	return string(out)
+}
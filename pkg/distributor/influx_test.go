@@ -0,0 +1,235 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package distributor
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+	"github.com/grafana/mimir/pkg/util/validation"
+)
+
+func TestParseInfluxLine(t *testing.T) {
+	now := time.UnixMilli(1700000000000)
+
+	tests := map[string]struct {
+		line              string
+		precision         time.Duration
+		expected          []mimirpb.PreallocTimeseries
+		expectedErr       string
+		expectedDiscarded int
+	}{
+		"measurement with tags and multiple fields": {
+			line:      "cpu,host=server01,region=us-west usage_idle=12.5,value=3i 1700000000123456789",
+			precision: time.Nanosecond,
+			expected: []mimirpb.PreallocTimeseries{
+				textPushTestSeries(12.5, 1700000000123, "__name__", "cpu_usage_idle", "host", "server01", "region", "us-west"),
+				textPushTestSeries(3, 1700000000123, "__name__", "cpu", "host", "server01", "region", "us-west"),
+			},
+		},
+		"missing timestamp": {
+			line:      "mem free=1024u",
+			precision: time.Nanosecond,
+			expected:  []mimirpb.PreallocTimeseries{textPushTestSeries(1024, now.UnixMilli(), "__name__", "mem_free")},
+		},
+		"seconds precision": {
+			line:      "mem free=1 1700000000",
+			precision: time.Second,
+			expected:  []mimirpb.PreallocTimeseries{textPushTestSeries(1, 1700000000000, "__name__", "mem_free")},
+		},
+		"booleans, and strings which are skipped": {
+			line:      `service up=true,down=F,status="running, really" 1700000000000000000`,
+			precision: time.Nanosecond,
+			expected: []mimirpb.PreallocTimeseries{
+				textPushTestSeries(1, 1700000000000, "__name__", "service_up"),
+				textPushTestSeries(0, 1700000000000, "__name__", "service_down"),
+			},
+		},
+		"escaped characters and invalid name characters": {
+			line:      `disk\ io,mount\=point=/var\,log,empty= read.bytes=5`,
+			precision: time.Nanosecond,
+			expected:  []mimirpb.PreallocTimeseries{textPushTestSeries(5, now.UnixMilli(), "__name__", "disk_io_read_bytes", "mount_point", "/var,log")},
+		},
+		"only string fields": {
+			line:              `log message="hello world",level="info"`,
+			precision:         time.Nanosecond,
+			expectedErr:       "no numeric or boolean fields",
+			expectedDiscarded: 2,
+		},
+		"missing fields": {
+			line:              "cpu,host=server01",
+			expectedErr:       "expected a measurement, fields and an optional timestamp",
+			expectedDiscarded: 1,
+		},
+		"invalid field value": {
+			line:              "cpu usage=1,idle=abc",
+			expectedErr:       `invalid value of field "idle"`,
+			expectedDiscarded: 2,
+		},
+		"invalid tag": {
+			line:              "cpu,host usage=1",
+			expectedErr:       "invalid tag",
+			expectedDiscarded: 1,
+		},
+		"invalid timestamp": {
+			line:              "cpu usage=1,idle=2,steal=3 yesterday",
+			expectedErr:       "invalid timestamp",
+			expectedDiscarded: 3,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, discarded, err := parseInfluxLine([]byte(tc.line), tc.precision, now, nil)
+			assert.Equal(t, tc.expectedDiscarded, discarded)
+			if tc.expectedErr != "" {
+				require.ErrorContains(t, err, tc.expectedErr)
+				assert.Empty(t, actual)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, normalizeTextPushSeries(actual))
+		})
+	}
+}
+
+func TestInfluxHandler(t *testing.T) {
+	tests := map[string]struct {
+		body            string
+		query           string
+		gzip            bool
+		expectedCode    int
+		expectedSeries  []string
+		expectedDropped string
+	}{
+		"valid request": {
+			body:           "cpu,host=a usage=1 1700000000\ncpu,host=b usage=2 1700000000\n",
+			query:          "?precision=s",
+			gzip:           true,
+			expectedCode:   http.StatusNoContent,
+			expectedSeries: []string{`{__name__="cpu_usage", host="a"}`, `{__name__="cpu_usage", host="b"}`},
+		},
+		"some invalid lines": {
+			body:           "cpu,host=a usage=1\n# comment\n\ncpu,host=b\n",
+			expectedCode:   http.StatusNoContent,
+			expectedSeries: []string{`{__name__="cpu_usage", host="a"}`},
+			expectedDropped: `
+				# HELP cortex_discarded_samples_total The total number of samples that were discarded.
+				# TYPE cortex_discarded_samples_total counter
+				cortex_discarded_samples_total{group="",reason="influx_parse_error",user="test"} 1
+			`,
+		},
+		"all lines invalid": {
+			body:         "cpu,host=a\ncpu usage=abc\n",
+			expectedCode: http.StatusBadRequest,
+			expectedDropped: `
+				# HELP cortex_discarded_samples_total The total number of samples that were discarded.
+				# TYPE cortex_discarded_samples_total counter
+				cortex_discarded_samples_total{group="",reason="influx_parse_error",user="test"} 2
+			`,
+		},
+		"samples of the invalid lines discarded per field": {
+			body:           "cpu,host=a usage=1\ncpu,host=b usage=1,idle=abc\nlog message=\"hello\",level=\"info\"\n",
+			expectedCode:   http.StatusNoContent,
+			expectedSeries: []string{`{__name__="cpu_usage", host="a"}`},
+			expectedDropped: `
+				# HELP cortex_discarded_samples_total The total number of samples that were discarded.
+				# TYPE cortex_discarded_samples_total counter
+				cortex_discarded_samples_total{group="",reason="influx_parse_error",user="test"} 4
+			`,
+		},
+		"unsupported precision": {
+			body:         "cpu usage=1\n",
+			query:        "?precision=d",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			reg := prometheus.NewPedanticRegistry()
+			var series []string
+
+			handler := InfluxHandler(100000, nil, validation.MockDefaultOverrides(), reg, func(ctx context.Context, pushReq *Request) error {
+				request, err := pushReq.WriteRequest()
+				if err != nil {
+					return err
+				}
+				defer pushReq.CleanUp()
+
+				for _, ts := range request.Timeseries {
+					series = append(series, mimirpb.FromLabelAdaptersToLabels(ts.Labels).String())
+				}
+				return nil
+			})
+
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, createTextPushRequest(t, "http://localhost/api/v1/push/influx/write"+tc.query, tc.body, tc.gzip))
+			assert.Equal(t, tc.expectedCode, resp.Code)
+			assert.Equal(t, tc.expectedSeries, series)
+
+			if tc.expectedDropped == "" {
+				dropped, err := testutil.GatherAndCount(reg, "cortex_discarded_samples_total")
+				require.NoError(t, err)
+				assert.Equal(t, 0, dropped)
+			} else {
+				assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(tc.expectedDropped), "cortex_discarded_samples_total"))
+			}
+		})
+	}
+}
+
+func textPushTestSeries(value float64, timestampMs int64, labels ...string) mimirpb.PreallocTimeseries {
+	ts := &mimirpb.TimeSeries{Samples: []mimirpb.Sample{{Value: value, TimestampMs: timestampMs}}}
+	for i := 0; i < len(labels); i += 2 {
+		ts.Labels = append(ts.Labels, mimirpb.LabelAdapter{Name: labels[i], Value: labels[i+1]})
+	}
+	return mimirpb.PreallocTimeseries{TimeSeries: ts}
+}
+
+// normalizeTextPushSeries clears the empty slices of the series taken from the pool, to compare them with the expected ones.
+func normalizeTextPushSeries(series []mimirpb.PreallocTimeseries) []mimirpb.PreallocTimeseries {
+	for _, ts := range series {
+		if len(ts.Exemplars) == 0 {
+			ts.Exemplars = nil
+		}
+		if len(ts.Histograms) == 0 {
+			ts.Histograms = nil
+		}
+	}
+	return series
+}
+
+func createTextPushRequest(t testing.TB, url, body string, compress bool) *http.Request {
+	t.Helper()
+
+	data := []byte(body)
+	if compress {
+		var b bytes.Buffer
+		gz := gzip.NewWriter(&b)
+		_, err := gz.Write(data)
+		require.NoError(t, err)
+		require.NoError(t, gz.Close())
+		data = b.Bytes()
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewReader(data))
+	require.NoError(t, err)
+	if compress {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	return req.WithContext(user.InjectOrgID(req.Context(), "test"))
+}
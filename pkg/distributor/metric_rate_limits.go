@@ -0,0 +1,148 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package distributor
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+	"golang.org/x/time/rate"
+
+	"github.com/grafana/mimir/pkg/util/validation"
+)
+
+// metricRateLimitsRecheckPeriod is how often the per-tenant rate limits of the series matching a selector
+// are checked for configuration changes and for changes of the number of healthy distributors.
+const metricRateLimitsRecheckPeriod = 10 * time.Second
+
+// metricRateLimiters enforces the per-tenant ingestion rate limits of the series matching a selector.
+// Like the tenant ingestion rate limit, each limit is shared across all healthy distributors.
+type metricRateLimiters struct {
+	limits *validation.Overrides
+	ring   ReadLifecycler
+
+	mtx     sync.Mutex
+	tenants map[string]*tenantMetricRateLimiters
+}
+
+type tenantMetricRateLimiters struct {
+	config          []*validation.MetricIngestionRateLimit
+	limiters        []*metricRateLimiter
+	numDistributors int
+	recheckAt       time.Time
+}
+
+// metricRateLimiter is the rate limiter of the series matching a selector.
+type metricRateLimiter struct {
+	config   validation.MetricIngestionRateLimit
+	matchers []*labels.Matcher
+	limiter  *rate.Limiter
+}
+
+func newMetricRateLimiters(limits *validation.Overrides, ring ReadLifecycler) *metricRateLimiters {
+	return &metricRateLimiters{
+		limits:  limits,
+		ring:    ring,
+		tenants: map[string]*tenantMetricRateLimiters{},
+	}
+}
+
+// tenantLimiters returns the rate limiters of the tenant, in the configured order. It's safe to call
+// tenantLimiters on a nil metricRateLimiters, in which case there are no rate limiters.
+func (m *metricRateLimiters) tenantLimiters(userID string, now time.Time) []*metricRateLimiter {
+	if m == nil {
+		return nil
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	t := m.tenants[userID]
+	if t != nil && now.Before(t.recheckAt) {
+		return t.limiters
+	}
+
+	config := m.limits.MetricIngestionRateLimits(userID)
+	if len(config) == 0 {
+		delete(m.tenants, userID)
+		return nil
+	}
+
+	numDistributors := m.ring.HealthyInstancesCount()
+	if t == nil || !reflect.DeepEqual(t.config, config) {
+		t = &tenantMetricRateLimiters{config: config, numDistributors: numDistributors}
+		for _, cfg := range config {
+			matchers, err := parser.ParseMetricSelector(cfg.Selector)
+			if err != nil {
+				// The limits are validated when loaded, so this should never happen.
+				continue
+			}
+			t.limiters = append(t.limiters, &metricRateLimiter{
+				config:   *cfg,
+				matchers: matchers,
+				limiter:  rate.NewLimiter(globalLimit(cfg.Rate, numDistributors), cfg.Burst),
+			})
+		}
+		m.tenants[userID] = t
+	} else if t.numDistributors != numDistributors {
+		t.numDistributors = numDistributors
+		for _, l := range t.limiters {
+			l.limiter.SetLimitAt(now, globalLimit(l.config.Rate, numDistributors))
+		}
+	}
+
+	t.recheckAt = now.Add(metricRateLimitsRecheckPeriod)
+	return t.limiters
+}
+
+// deleteUser removes the rate limiters of the tenant.
+func (m *metricRateLimiters) deleteUser(userID string) {
+	if m == nil {
+		return
+	}
+
+	m.mtx.Lock()
+	delete(m.tenants, userID)
+	m.mtx.Unlock()
+}
+
+// globalLimit returns the share of the limit enforced by each distributor.
+func globalLimit(limit float64, numDistributors int) rate.Limit {
+	if numDistributors == 0 {
+		return rate.Limit(limit)
+	}
+	return rate.Limit(limit / float64(numDistributors))
+}
+
+// matchMetricRateLimiter returns the first rate limiter whose selector matches the series, or nil if none matches.
+func matchMetricRateLimiter(limiters []*metricRateLimiter, series labels.Labels) *metricRateLimiter {
+	for _, l := range limiters {
+		if l.matches(series) {
+			return l
+		}
+	}
+	return nil
+}
+
+// reserve takes n tokens from the rate limiter, if available at now. The returned reservation can be
+// canceled to refund the tokens, if the samples are discarded afterwards.
+func (l *metricRateLimiter) reserve(now time.Time, n int) (*rate.Reservation, bool) {
+	r := l.limiter.ReserveN(now, n)
+	if !r.OK() || r.DelayFrom(now) > 0 {
+		r.CancelAt(now)
+		return nil, false
+	}
+	return r, true
+}
+
+func (l *metricRateLimiter) matches(series labels.Labels) bool {
+	for _, m := range l.matchers {
+		if !m.Matches(series.Get(m.Name)) {
+			return false
+		}
+	}
+	return true
+}
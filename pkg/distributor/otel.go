@@ -8,7 +8,10 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
+	"unicode"
 
 	kitlog "github.com/go-kit/log"
 	"github.com/go-kit/log/level"
@@ -24,6 +27,7 @@ import (
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
 	"go.uber.org/multierr"
+	"golang.org/x/exp/slices"
 
 	"github.com/grafana/mimir/pkg/mimirpb"
 	"github.com/grafana/mimir/pkg/util"
@@ -36,8 +40,9 @@ const (
 	pbContentType   = "application/x-protobuf"
 	jsonContentType = "application/json"
 
-	otelParseError = "otlp_parse_error"
-	maxErrMsgLen   = 1024
+	otelParseError       = "otlp_parse_error"
+	otelDeltaTemporality = "otlp_delta_temporality"
+	maxErrMsgLen         = 1024
 )
 
 func OTLPHandler(
@@ -50,8 +55,18 @@ func OTLPHandler(
 	push PushFunc,
 ) http.Handler {
 	discardedDueToOtelParseError := validation.DiscardedSamplesCounter(reg, otelParseError)
+	discardedDueToOtelDeltaTemporality := validation.DiscardedSamplesCounter(reg, otelDeltaTemporality)
 
-	return handler(maxRecvMsgSize, sourceIPs, allowSkipLabelNameValidation, limits, push, func(ctx context.Context, r *http.Request, maxRecvMsgSize int, dst []byte, req *mimirpb.PreallocWriteRequest) ([]byte, error) {
+	// The samples discarded while translating the request don't prevent the rest of it from being ingested,
+	// but the client is notified of them with an error returned once the rest has been pushed.
+	pushWithDiscardedErr := func(ctx context.Context, req *Request) error {
+		if err := push(ctx, req); err != nil {
+			return err
+		}
+		return otelDiscardedErrFromContext(ctx).get()
+	}
+
+	h := handler(maxRecvMsgSize, sourceIPs, allowSkipLabelNameValidation, limits, pushWithDiscardedErr, func(ctx context.Context, r *http.Request, maxRecvMsgSize int, dst []byte, req *mimirpb.PreallocWriteRequest) ([]byte, error) {
 		var decoderFunc func(buf []byte) (pmetricotlp.ExportRequest, error)
 
 		logger := log.WithContext(ctx, log.Logger)
@@ -128,7 +143,26 @@ func OTLPHandler(
 
 		level.Debug(log).Log("msg", "decoding complete, starting conversion")
 
-		metrics, err := otelMetricsToTimeseries(ctx, discardedDueToOtelParseError, logger, otlpReq.Metrics())
+		tenantID, err := tenant.TenantID(ctx)
+		if err != nil {
+			return body, err
+		}
+
+		md := otlpReq.Metrics()
+		if discarded := handleOTelDeltaTemporality(md, limits.OTelDeltaTemporalityHandling(tenantID)); discarded > 0 {
+			discardedDueToOtelDeltaTemporality.WithLabelValues(tenantID, "").Add(float64(discarded)) // Group is empty here as the metrics haven't been converted yet
+			level.Warn(logger).Log("msg", "discarded OTLP samples of metrics with delta aggregation temporality", "samples", discarded, "handling", limits.OTelDeltaTemporalityHandling(tenantID))
+
+			// Whether all or only some of the samples have been discarded, the rest of the request is ingested.
+			otelDiscardedErrFromContext(ctx).set(httpgrpc.Errorf(http.StatusBadRequest, "%d samples of the request have been discarded because they have delta aggregation temporality, which is not supported (configure -%s to ingest delta sums as gauges)", discarded, validation.OTelDeltaTemporalityHandlingFlag))
+		}
+		promoteOTelResourceAttributes(md, limits.OTelPromoteResourceAttributes(tenantID))
+		downscaleOTelExponentialHistograms(md, limits.OTelExponentialHistogramMaxScale(tenantID))
+		if limits.OTelMetricSuffixesEnabled(tenantID) {
+			normalizeOTelMetricNames(md)
+		}
+
+		metrics, err := otelMetricsToTimeseries(tenantID, discardedDueToOtelParseError, logger, md)
 		if err != nil {
 			return body, err
 		}
@@ -155,12 +189,57 @@ func OTLPHandler(
 		req.Timeseries = metrics
 
 		if enableOtelMetadataStorage {
-			metadata := otelMetricsToMetadata(otlpReq.Metrics())
+			metadata := otelMetricsToMetadata(md)
 			req.Metadata = metadata
 		}
 
 		return body, nil
 	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.ServeHTTP(w, r.WithContext(contextWithOTelDiscardedErr(r.Context(), &otelDiscardedErr{})))
+	})
+}
+
+// otelDiscardedErr holds the error about the samples discarded while translating an OTLP request, which is
+// carried in the request context from the parser to the push function.
+type otelDiscardedErr struct {
+	mtx sync.Mutex
+	err error
+}
+
+type otelDiscardedErrContextKey int
+
+const otelDiscardedErrKey otelDiscardedErrContextKey = 0
+
+func contextWithOTelDiscardedErr(ctx context.Context, e *otelDiscardedErr) context.Context {
+	return context.WithValue(ctx, otelDiscardedErrKey, e)
+}
+
+// otelDiscardedErrFromContext returns the discarded samples error holder carried by the context, or nil if there is none.
+func otelDiscardedErrFromContext(ctx context.Context) *otelDiscardedErr {
+	e, _ := ctx.Value(otelDiscardedErrKey).(*otelDiscardedErr)
+	return e
+}
+
+// set sets the error. Safe to call on a nil holder.
+func (e *otelDiscardedErr) set(err error) {
+	if e == nil {
+		return
+	}
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	e.err = err
+}
+
+// get returns the error, if any. Safe to call on a nil holder.
+func (e *otelDiscardedErr) get() error {
+	if e == nil {
+		return nil
+	}
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	return e.err
 }
 
 func otelMetricTypeToMimirMetricType(otelMetric pmetric.Metric) mimirpb.MetricMetadata_MetricType {
@@ -203,7 +282,7 @@ func otelMetricsToMetadata(md pmetric.Metrics) []*mimirpb.MetricMetadata {
 				metric := scopeMetrics.Metrics().At(k)
 				entry := mimirpb.MetricMetadata{
 					Type:             otelMetricTypeToMimirMetricType(metric),
-					MetricFamilyName: prometheustranslator.BuildCompliantName(metric, "", true), // TODO expose addMetricSuffixes in configuration (https://github.com/grafana/mimir/issues/5967)
+					MetricFamilyName: prometheustranslator.BuildCompliantName(metric, "", false),
 					Help:             metric.Description(),
 					Unit:             metric.Unit(),
 				}
@@ -216,17 +295,14 @@ func otelMetricsToMetadata(md pmetric.Metrics) []*mimirpb.MetricMetadata {
 
 }
 
-func otelMetricsToTimeseries(ctx context.Context, discardedDueToOtelParseError *prometheus.CounterVec, logger kitlog.Logger, md pmetric.Metrics) ([]mimirpb.PreallocTimeseries, error) {
+// otelMetricsToTimeseries translates the OTLP metrics to time series. The names of the metrics are expected to have
+// already been normalized, if the unit and type suffixes have to be added, see normalizeOTelMetricNames.
+func otelMetricsToTimeseries(tenantID string, discardedDueToOtelParseError *prometheus.CounterVec, logger kitlog.Logger, md pmetric.Metrics) ([]mimirpb.PreallocTimeseries, error) {
 	tsMap, errs := prometheusremotewrite.FromMetrics(md, prometheusremotewrite.Settings{})
 
 	if errs != nil {
-		userID, err := tenant.TenantID(ctx)
-		if err != nil {
-			return nil, err
-		}
-
 		dropped := len(multierr.Errors(errs))
-		discardedDueToOtelParseError.WithLabelValues(userID, "").Add(float64(dropped)) // Group is empty here as metrics couldn't be parsed
+		discardedDueToOtelParseError.WithLabelValues(tenantID, "").Add(float64(dropped)) // Group is empty here as metrics couldn't be parsed
 
 		parseErrs := errs.Error()
 		if len(parseErrs) > maxErrMsgLen {
@@ -248,6 +324,269 @@ func otelMetricsToTimeseries(ctx context.Context, discardedDueToOtelParseError *
 	return mimirTs, nil
 }
 
+// handleOTelDeltaTemporality removes or converts, depending on the handling, the metrics with delta aggregation
+// temporality, which can't be ingested as they are. It returns the number of discarded samples.
+func handleOTelDeltaTemporality(md pmetric.Metrics, handling string) int {
+	discarded := 0
+
+	resourceMetricsSlice := md.ResourceMetrics()
+	for i := 0; i < resourceMetricsSlice.Len(); i++ {
+		scopeMetricsSlice := resourceMetricsSlice.At(i).ScopeMetrics()
+		for j := 0; j < scopeMetricsSlice.Len(); j++ {
+			scopeMetricsSlice.At(j).Metrics().RemoveIf(func(metric pmetric.Metric) bool {
+				switch metric.Type() {
+				case pmetric.MetricTypeSum:
+					if metric.Sum().AggregationTemporality() != pmetric.AggregationTemporalityDelta {
+						return false
+					}
+					if handling == validation.OTelDeltaTemporalityConvertToGauge {
+						// Each delta is the increase over its interval, so it's ingested as is, as a gauge.
+						dataPoints := pmetric.NewNumberDataPointSlice()
+						metric.Sum().DataPoints().MoveAndAppendTo(dataPoints)
+						dataPoints.MoveAndAppendTo(metric.SetEmptyGauge().DataPoints())
+						return false
+					}
+					discarded += metric.Sum().DataPoints().Len()
+					return true
+				case pmetric.MetricTypeHistogram:
+					if metric.Histogram().AggregationTemporality() != pmetric.AggregationTemporalityDelta {
+						return false
+					}
+					discarded += metric.Histogram().DataPoints().Len()
+					return true
+				case pmetric.MetricTypeExponentialHistogram:
+					if metric.ExponentialHistogram().AggregationTemporality() != pmetric.AggregationTemporalityDelta {
+						return false
+					}
+					discarded += metric.ExponentialHistogram().DataPoints().Len()
+					return true
+				}
+				return false
+			})
+		}
+	}
+
+	return discarded
+}
+
+// promoteOTelResourceAttributes copies the resource attributes with the input names to the attributes of the data
+// points of the resource metrics, so that they're translated to series labels. Attributes already set on a data
+// point are not overwritten.
+func promoteOTelResourceAttributes(md pmetric.Metrics, names []string) {
+	if len(names) == 0 {
+		return
+	}
+
+	resourceMetricsSlice := md.ResourceMetrics()
+	for i := 0; i < resourceMetricsSlice.Len(); i++ {
+		resourceMetrics := resourceMetricsSlice.At(i)
+
+		promoted := pcommon.NewMap()
+		for _, name := range names {
+			if value, ok := resourceMetrics.Resource().Attributes().Get(name); ok {
+				value.CopyTo(promoted.PutEmpty(name))
+			}
+		}
+		if promoted.Len() == 0 {
+			continue
+		}
+
+		promote := func(attributes pcommon.Map) {
+			promoted.Range(func(name string, value pcommon.Value) bool {
+				if _, ok := attributes.Get(name); !ok {
+					value.CopyTo(attributes.PutEmpty(name))
+				}
+				return true
+			})
+		}
+
+		scopeMetricsSlice := resourceMetrics.ScopeMetrics()
+		for j := 0; j < scopeMetricsSlice.Len(); j++ {
+			metrics := scopeMetricsSlice.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				metric := metrics.At(k)
+				switch metric.Type() {
+				case pmetric.MetricTypeGauge:
+					for x := 0; x < metric.Gauge().DataPoints().Len(); x++ {
+						promote(metric.Gauge().DataPoints().At(x).Attributes())
+					}
+				case pmetric.MetricTypeSum:
+					for x := 0; x < metric.Sum().DataPoints().Len(); x++ {
+						promote(metric.Sum().DataPoints().At(x).Attributes())
+					}
+				case pmetric.MetricTypeHistogram:
+					for x := 0; x < metric.Histogram().DataPoints().Len(); x++ {
+						promote(metric.Histogram().DataPoints().At(x).Attributes())
+					}
+				case pmetric.MetricTypeExponentialHistogram:
+					for x := 0; x < metric.ExponentialHistogram().DataPoints().Len(); x++ {
+						promote(metric.ExponentialHistogram().DataPoints().At(x).Attributes())
+					}
+				case pmetric.MetricTypeSummary:
+					for x := 0; x < metric.Summary().DataPoints().Len(); x++ {
+						promote(metric.Summary().DataPoints().At(x).Attributes())
+					}
+				}
+			}
+		}
+	}
+}
+
+// otelUnits maps the OTLP units, which follow the UCUM notation, to the Prometheus units used as metric name suffixes.
+var otelUnits = map[string]string{
+	// Time
+	"d":   "days",
+	"h":   "hours",
+	"min": "minutes",
+	"s":   "seconds",
+	"ms":  "milliseconds",
+	"us":  "microseconds",
+	"ns":  "nanoseconds",
+
+	// Bytes
+	"By":   "bytes",
+	"KiBy": "kibibytes",
+	"MiBy": "mebibytes",
+	"GiBy": "gibibytes",
+	"TiBy": "tibibytes",
+	"KBy":  "kilobytes",
+	"MBy":  "megabytes",
+	"GBy":  "gigabytes",
+	"TBy":  "terabytes",
+
+	// SI
+	"m": "meters",
+	"V": "volts",
+	"A": "amperes",
+	"J": "joules",
+	"W": "watts",
+	"g": "grams",
+
+	// Misc
+	"Cel": "celsius",
+	"Hz":  "hertz",
+	"1":   "",
+	"%":   "percent",
+}
+
+// otelPerUnits maps the OTLP units following a "/" to the Prometheus units used as metric name suffixes.
+var otelPerUnits = map[string]string{
+	"s":  "second",
+	"m":  "minute",
+	"h":  "hour",
+	"d":  "day",
+	"w":  "week",
+	"mo": "month",
+	"y":  "year",
+}
+
+// normalizeOTelMetricNames renames the OTLP metrics following the Prometheus naming conventions, adding the unit and
+// type suffixes, such as _seconds and _total. It follows the same rules as the OpenTelemetry to Prometheus
+// translator, whose normalization is instead enabled process-wide through a feature gate.
+func normalizeOTelMetricNames(md pmetric.Metrics) {
+	resourceMetricsSlice := md.ResourceMetrics()
+	for i := 0; i < resourceMetricsSlice.Len(); i++ {
+		scopeMetricsSlice := resourceMetricsSlice.At(i).ScopeMetrics()
+		for j := 0; j < scopeMetricsSlice.Len(); j++ {
+			metrics := scopeMetricsSlice.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				metric := metrics.At(k)
+				metric.SetName(normalizedOTelMetricName(metric))
+			}
+		}
+	}
+}
+
+func normalizedOTelMetricName(metric pmetric.Metric) string {
+	isSeparator := func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }
+	nameTokens := strings.FieldsFunc(metric.Name(), isSeparator)
+
+	// The main unit, and the per unit following a "/", are appended unless they're already in the name, or are
+	// annotations in curly braces.
+	unitTokens := strings.SplitN(metric.Unit(), "/", 2)
+	if unit := strings.TrimSpace(unitTokens[0]); unit != "" && !strings.ContainsAny(unit, "{}") {
+		if promUnit, ok := otelUnits[unit]; ok {
+			unit = promUnit
+		}
+		if unit = prometheustranslator.CleanUpString(unit); unit != "" && !slices.Contains(nameTokens, unit) {
+			nameTokens = append(nameTokens, unit)
+		}
+	}
+	if len(unitTokens) > 1 {
+		if perUnit := strings.TrimSpace(unitTokens[1]); perUnit != "" && !strings.ContainsAny(perUnit, "{}") {
+			if promPerUnit, ok := otelPerUnits[perUnit]; ok {
+				perUnit = promPerUnit
+			}
+			if perUnit = prometheustranslator.CleanUpString(perUnit); perUnit != "" && !slices.Contains(nameTokens, perUnit) {
+				nameTokens = append(nameTokens, "per", perUnit)
+			}
+		}
+	}
+
+	// Counters get the _total suffix, and gauges with the unit "1" the _ratio one.
+	if metric.Type() == pmetric.MetricTypeSum && metric.Sum().IsMonotonic() {
+		nameTokens = append(slices.DeleteFunc(nameTokens, func(t string) bool { return t == "total" }), "total")
+	}
+	if metric.Unit() == "1" && metric.Type() == pmetric.MetricTypeGauge {
+		nameTokens = append(slices.DeleteFunc(nameTokens, func(t string) bool { return t == "ratio" }), "ratio")
+	}
+
+	name := strings.Join(nameTokens, "_")
+	if name != "" && unicode.IsDigit(rune(name[0])) {
+		name = "_" + name
+	}
+	return name
+}
+
+// downscaleOTelExponentialHistograms reduces the scale of the data points of the OTLP exponential histograms whose
+// scale is greater than maxScale, by merging their adjacent buckets.
+func downscaleOTelExponentialHistograms(md pmetric.Metrics, maxScale int32) {
+	resourceMetricsSlice := md.ResourceMetrics()
+	for i := 0; i < resourceMetricsSlice.Len(); i++ {
+		scopeMetricsSlice := resourceMetricsSlice.At(i).ScopeMetrics()
+		for j := 0; j < scopeMetricsSlice.Len(); j++ {
+			metrics := scopeMetricsSlice.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				metric := metrics.At(k)
+				if metric.Type() != pmetric.MetricTypeExponentialHistogram {
+					continue
+				}
+
+				dataPoints := metric.ExponentialHistogram().DataPoints()
+				for l := 0; l < dataPoints.Len(); l++ {
+					dp := dataPoints.At(l)
+					if dp.Scale() <= maxScale {
+						continue
+					}
+					scaleDown := dp.Scale() - maxScale
+					downscaleOTelExponentialHistogramBuckets(dp.Positive(), scaleDown)
+					downscaleOTelExponentialHistogramBuckets(dp.Negative(), scaleDown)
+					dp.SetScale(maxScale)
+				}
+			}
+		}
+	}
+}
+
+// downscaleOTelExponentialHistogramBuckets merges each 2^scaleDown adjacent buckets into one. The bucket with index i
+// at the original scale falls into the bucket with index i>>scaleDown at the reduced scale.
+func downscaleOTelExponentialHistogramBuckets(buckets pmetric.ExponentialHistogramDataPointBuckets, scaleDown int32) {
+	counts := buckets.BucketCounts()
+	if counts.Len() == 0 {
+		return
+	}
+
+	offset := buckets.Offset()
+	downscaledOffset := offset >> scaleDown
+	downscaled := make([]uint64, ((offset+int32(counts.Len())-1)>>scaleDown)-downscaledOffset+1)
+	for i := 0; i < counts.Len(); i++ {
+		downscaled[((offset+int32(i))>>scaleDown)-downscaledOffset] += counts.At(i)
+	}
+
+	buckets.SetOffset(downscaledOffset)
+	counts.FromRaw(downscaled)
+}
+
 func promToMimirTimeseries(promTs *prompb.TimeSeries) mimirpb.PreallocTimeseries {
 	labels := make([]mimirpb.LabelAdapter, 0, len(promTs.Labels))
 	for _, label := range promTs.Labels {
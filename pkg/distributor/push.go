@@ -9,14 +9,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"mime"
 	"net/http"
+	"strconv"
 	"sync"
 
 	"github.com/go-kit/log/level"
+	"github.com/gogo/protobuf/proto"
 	"github.com/grafana/dskit/httpgrpc"
 	"github.com/grafana/dskit/httpgrpc/server"
 	"github.com/grafana/dskit/middleware"
 	"github.com/grafana/dskit/tenant"
+	"go.uber.org/atomic"
 
 	"github.com/grafana/mimir/pkg/mimirpb"
 	"github.com/grafana/mimir/pkg/util"
@@ -43,6 +47,15 @@ var bufferPool = sync.Pool{
 const (
 	SkipLabelNameValidationHeader = "X-Mimir-SkipLabelNameValidation"
 	statusClientClosedRequest     = 499
+
+	// The protobuf messages of the Prometheus Remote-Write 1.0 and 2.0 protocols, as specified in the
+	// "proto" parameter of the request content type.
+	remoteWrite1ProtoMessage = "prometheus.WriteRequest"
+	remoteWrite2ProtoMessage = "io.prometheus.write.v2.Request"
+
+	remoteWriteSamplesWrittenHeader    = "X-Prometheus-Remote-Write-Samples-Written"
+	remoteWriteHistogramsWrittenHeader = "X-Prometheus-Remote-Write-Histograms-Written"
+	remoteWriteExemplarsWrittenHeader  = "X-Prometheus-Remote-Write-Exemplars-Written"
 )
 
 // Handler is a http.Handler which accepts WriteRequests.
@@ -54,7 +67,17 @@ func Handler(
 	push PushFunc,
 ) http.Handler {
 	return handler(maxRecvMsgSize, sourceIPs, allowSkipLabelNameValidation, limits, push, func(ctx context.Context, r *http.Request, maxRecvMsgSize int, dst []byte, req *mimirpb.PreallocWriteRequest) ([]byte, error) {
-		res, err := util.ParseProtoReader(ctx, r.Body, int(r.ContentLength), maxRecvMsgSize, dst, req, util.RawSnappy)
+		rw2, err := isRemoteWrite2Request(r)
+		if err != nil {
+			return nil, err
+		}
+
+		var msg proto.Message = req
+		if rw2 {
+			msg = mimirpb.PreallocWriteRequestRW2{PreallocWriteRequest: req}
+		}
+
+		res, err := util.ParseProtoReader(ctx, r.Body, int(r.ContentLength), maxRecvMsgSize, dst, msg, util.RawSnappy)
 		if errors.Is(err, util.MsgSizeTooLargeErr{}) {
 			err = distributorMaxWriteMessageSizeErr{actual: int(r.ContentLength), limit: maxRecvMsgSize}
 		}
@@ -62,6 +85,83 @@ func Handler(
 	})
 }
 
+// isRemoteWrite2Request returns whether the request is a Prometheus Remote-Write 2.0 request, negotiated
+// through the "proto" parameter of the content type. Requests without such parameter are Remote-Write 1.0
+// requests, for backwards compatibility with clients not setting it.
+func isRemoteWrite2Request(r *http.Request) (bool, error) {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		return false, nil
+	}
+
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		// Keep accepting requests with an invalid content type, as we used to.
+		return false, nil
+	}
+
+	switch params["proto"] {
+	case "", remoteWrite1ProtoMessage:
+		return false, nil
+	case remoteWrite2ProtoMessage:
+		return true, nil
+	default:
+		return false, httpgrpc.Errorf(http.StatusUnsupportedMediaType, "unsupported remote-write protobuf message %q, supported messages are %q and %q", params["proto"], remoteWrite1ProtoMessage, remoteWrite2ProtoMessage)
+	}
+}
+
+// remoteWriteStats holds the number of samples, histograms and exemplars of a remote-write request which have
+// been written, which are returned to Remote-Write 2.0 clients in the response headers. The stats are carried in
+// the request context, and updated once the series have been sent to the ingesters, after relabeling and validation.
+type remoteWriteStats struct {
+	samples, histograms, exemplars atomic.Int64
+}
+
+type remoteWriteStatsContextKey int
+
+const remoteWriteStatsKey remoteWriteStatsContextKey = 0
+
+func contextWithRemoteWriteStats(ctx context.Context, stats *remoteWriteStats) context.Context {
+	return context.WithValue(ctx, remoteWriteStatsKey, stats)
+}
+
+// remoteWriteStatsFromContext returns the remote-write stats carried by the context, or nil if there are none.
+func remoteWriteStatsFromContext(ctx context.Context) *remoteWriteStats {
+	stats, _ := ctx.Value(remoteWriteStatsKey).(*remoteWriteStats)
+	return stats
+}
+
+// remoteWriteCounts is the number of samples, histograms and exemplars of some series.
+type remoteWriteCounts struct {
+	samples, histograms, exemplars int
+}
+
+func countRemoteWriteSeries(series []mimirpb.PreallocTimeseries) remoteWriteCounts {
+	var c remoteWriteCounts
+	for _, ts := range series {
+		c.samples += len(ts.Samples)
+		c.histograms += len(ts.Histograms)
+		c.exemplars += len(ts.Exemplars)
+	}
+	return c
+}
+
+// add adds the written counts to the stats. Safe to call on nil stats.
+func (s *remoteWriteStats) add(c remoteWriteCounts) {
+	if s == nil {
+		return
+	}
+	s.samples.Add(int64(c.samples))
+	s.histograms.Add(int64(c.histograms))
+	s.exemplars.Add(int64(c.exemplars))
+}
+
+func (s *remoteWriteStats) setHeaders(h http.Header) {
+	h.Set(remoteWriteSamplesWrittenHeader, strconv.FormatInt(s.samples.Load(), 10))
+	h.Set(remoteWriteHistogramsWrittenHeader, strconv.FormatInt(s.histograms.Load(), 10))
+	h.Set(remoteWriteExemplarsWrittenHeader, strconv.FormatInt(s.exemplars.Load(), 10))
+}
+
 type distributorMaxWriteMessageSizeErr struct {
 	actual, limit int
 }
@@ -92,6 +192,12 @@ func handler(
 				logger = log.WithSourceIPs(source, logger)
 			}
 		}
+		// Set for Remote-Write 2.0 requests, whose response headers contain the number of written samples.
+		var rw2Stats *remoteWriteStats
+		if rw2, _ := isRemoteWrite2Request(r); rw2 {
+			rw2Stats = &remoteWriteStats{}
+			ctx = contextWithRemoteWriteStats(ctx, rw2Stats)
+		}
 		supplier := func() (*mimirpb.WriteRequest, func(), error) {
 			bufHolder := bufferPool.Get().(*bufHolder)
 			var req mimirpb.PreallocWriteRequest
@@ -142,7 +248,16 @@ func handler(
 				level.Error(logger).Log("msg", "push error", "err", err)
 			}
 			addHeaders(w, err)
+			// Some series may have been written even if the request failed, for example if others were invalid.
+			if rw2Stats != nil {
+				rw2Stats.setHeaders(w.Header())
+			}
 			http.Error(w, msg, code)
+			return
+		}
+
+		if rw2Stats != nil {
+			rw2Stats.setHeaders(w.Header())
 		}
 	})
 }
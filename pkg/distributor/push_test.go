@@ -14,6 +14,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -23,6 +24,8 @@ import (
 	"github.com/grafana/dskit/middleware"
 	"github.com/grafana/dskit/tenant"
 	"github.com/grafana/dskit/user"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/prompb"
 	"github.com/prometheus/prometheus/storage/remote"
@@ -32,6 +35,7 @@ import (
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/encoding/protowire"
 
 	"github.com/grafana/mimir/pkg/mimirpb"
 	"github.com/grafana/mimir/pkg/util/test"
@@ -46,6 +50,76 @@ func TestHandler_remoteWrite(t *testing.T) {
 	assert.Equal(t, 200, resp.Code)
 }
 
+func TestHandler_remoteWrite2(t *testing.T) {
+	t.Run("should ingest a remote-write 2.0 request and return the written stats", func(t *testing.T) {
+		req := createRequest(t, createPrometheusRemoteWrite2Protobuf(t))
+		req.Header.Set("Content-Type", "application/x-protobuf;proto=io.prometheus.write.v2.Request")
+		req.Header.Set("X-Prometheus-Remote-Write-Version", "2.0.0")
+		resp := httptest.NewRecorder()
+
+		pushFunc := func(ctx context.Context, pushReq *Request) error {
+			request, err := pushReq.WriteRequest()
+			require.NoError(t, err)
+			require.Len(t, request.Timeseries, 1)
+			assert.Equal(t, []mimirpb.LabelAdapter{{Name: "__name__", Value: "foo"}, {Name: "job", Value: "test"}}, request.Timeseries[0].Labels)
+			assert.Equal(t, []mimirpb.Sample{{Value: 1, TimestampMs: 1000}, {Value: 2, TimestampMs: 2000}}, request.Timeseries[0].Samples)
+			assert.Len(t, request.Timeseries[0].Exemplars, 1)
+			assert.Equal(t, []*mimirpb.MetricMetadata{{Type: mimirpb.COUNTER, MetricFamilyName: "foo", Help: "Foo help."}}, request.Metadata)
+
+			// Simulate the distributor writing only one of the samples.
+			remoteWriteStatsFromContext(ctx).add(remoteWriteCounts{samples: 1, exemplars: 1})
+			pushReq.CleanUp()
+			return nil
+		}
+
+		handler := Handler(100000, nil, false, nil, pushFunc)
+		handler.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, "1", resp.Header().Get("X-Prometheus-Remote-Write-Samples-Written"))
+		assert.Equal(t, "0", resp.Header().Get("X-Prometheus-Remote-Write-Histograms-Written"))
+		assert.Equal(t, "1", resp.Header().Get("X-Prometheus-Remote-Write-Exemplars-Written"))
+	})
+
+	t.Run("should return the written stats along with errors", func(t *testing.T) {
+		req := createRequest(t, createPrometheusRemoteWrite2Protobuf(t))
+		req.Header.Set("Content-Type", "application/x-protobuf;proto=io.prometheus.write.v2.Request")
+		resp := httptest.NewRecorder()
+
+		pushFunc := func(ctx context.Context, pushReq *Request) error {
+			_, err := pushReq.WriteRequest()
+			require.NoError(t, err)
+			pushReq.CleanUp()
+			return httpgrpc.Errorf(http.StatusBadRequest, "invalid series")
+		}
+
+		handler := Handler(100000, nil, false, nil, pushFunc)
+		handler.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Equal(t, "0", resp.Header().Get("X-Prometheus-Remote-Write-Samples-Written"))
+	})
+
+	t.Run("should not return the written stats to remote-write 1.0 clients", func(t *testing.T) {
+		req := createRequest(t, createPrometheusRemoteWriteProtobuf(t))
+		req.Header.Set("Content-Type", "application/x-protobuf;proto=prometheus.WriteRequest")
+		resp := httptest.NewRecorder()
+
+		handler := Handler(100000, nil, false, nil, verifyWritePushFunc(t, mimirpb.API))
+		handler.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Empty(t, resp.Header().Get("X-Prometheus-Remote-Write-Samples-Written"))
+	})
+
+	t.Run("should reject requests with an unsupported protobuf message", func(t *testing.T) {
+		req := createRequest(t, createPrometheusRemoteWrite2Protobuf(t))
+		req.Header.Set("Content-Type", "application/x-protobuf;proto=io.prometheus.write.v3.Request")
+		resp := httptest.NewRecorder()
+
+		handler := Handler(100000, nil, false, nil, readBodyPushFunc(t))
+		handler.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusUnsupportedMediaType, resp.Code)
+	})
+}
+
 func TestOtelMetricsToMetadata(t *testing.T) {
 	otelMetrics := pmetric.NewMetrics()
 	rs := otelMetrics.ResourceMetrics().AppendEmpty()
@@ -265,7 +339,7 @@ func TestHandlerOTLPPush(t *testing.T) {
 				req.Header.Set("Content-Encoding", tt.encoding)
 			}
 
-			handler := OTLPHandler(tt.maxMsgSize, nil, false, tt.enableOtelMetadataStorage, nil, nil, tt.verifyFunc)
+			handler := OTLPHandler(tt.maxMsgSize, nil, false, tt.enableOtelMetadataStorage, validation.MockDefaultOverrides(), nil, tt.verifyFunc)
 
 			resp := httptest.NewRecorder()
 			handler.ServeHTTP(resp, req)
@@ -280,6 +354,229 @@ func TestHandlerOTLPPush(t *testing.T) {
 	}
 }
 
+func TestHandlerOTLPPush_PerTenantTranslation(t *testing.T) {
+	createMetrics := func() pmetric.Metrics {
+		md := pmetric.NewMetrics()
+		rm := md.ResourceMetrics().AppendEmpty()
+		rm.Resource().Attributes().PutStr("service.name", "checkout")
+		rm.Resource().Attributes().PutStr("k8s.namespace.name", "prod")
+		rm.Resource().Attributes().PutStr("deployment.environment", "eu")
+		metrics := rm.ScopeMetrics().AppendEmpty().Metrics()
+
+		cumulative := metrics.AppendEmpty()
+		cumulative.SetName("requests")
+		cumulative.SetEmptySum().SetIsMonotonic(true)
+		cumulative.Sum().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+		dp := cumulative.Sum().DataPoints().AppendEmpty()
+		dp.SetTimestamp(pcommon.Timestamp(time.Millisecond))
+		dp.SetDoubleValue(10)
+		dp.Attributes().PutStr("deployment.environment", "us")
+
+		delta := metrics.AppendEmpty()
+		delta.SetName("delta_requests")
+		delta.SetEmptySum().SetIsMonotonic(true)
+		delta.Sum().SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+		for i := 1; i <= 2; i++ {
+			dp := delta.Sum().DataPoints().AppendEmpty()
+			dp.SetTimestamp(pcommon.Timestamp(time.Duration(i) * time.Millisecond))
+			dp.SetDoubleValue(float64(i))
+		}
+
+		return md
+	}
+
+	tests := map[string]struct {
+		limits          func(*validation.Limits)
+		md              func() pmetric.Metrics
+		verify          func(*testing.T, map[string]mimirpb.PreallocTimeseries)
+		expectedCode    int
+		expectedDropped float64
+	}{
+		"default translation": {
+			limits: func(*validation.Limits) {},
+			md:     createMetrics,
+			verify: func(t *testing.T, series map[string]mimirpb.PreallocTimeseries) {
+				require.Contains(t, series, "requests")
+				assert.NotContains(t, series, "delta_requests")
+
+				lbls := mimirpb.FromLabelAdaptersToLabels(series["requests"].Labels)
+				assert.Equal(t, "us", lbls.Get("deployment_environment"))
+				assert.Equal(t, "", lbls.Get("k8s_namespace_name"))
+			},
+			// The rest of the request is ingested, but the client is notified of the discarded samples.
+			expectedCode:    http.StatusBadRequest,
+			expectedDropped: 2,
+		},
+		"promoted resource attributes and metric suffixes": {
+			limits: func(l *validation.Limits) {
+				l.OTelPromoteResourceAttributes = []string{"k8s.namespace.name", "deployment.environment", "missing"}
+				l.OTelMetricSuffixesEnabled = true
+			},
+			md: createMetrics,
+			verify: func(t *testing.T, series map[string]mimirpb.PreallocTimeseries) {
+				require.Contains(t, series, "requests_total")
+
+				lbls := mimirpb.FromLabelAdaptersToLabels(series["requests_total"].Labels)
+				assert.Equal(t, "prod", lbls.Get("k8s_namespace_name"))
+				// The data point attributes take precedence over the promoted resource attributes.
+				assert.Equal(t, "us", lbls.Get("deployment_environment"))
+				assert.False(t, lbls.Has("missing"))
+			},
+			expectedCode:    http.StatusBadRequest,
+			expectedDropped: 2,
+		},
+		"delta sums converted to gauges": {
+			limits: func(l *validation.Limits) {
+				l.OTelDeltaTemporalityHandling = validation.OTelDeltaTemporalityConvertToGauge
+			},
+			md: createMetrics,
+			verify: func(t *testing.T, series map[string]mimirpb.PreallocTimeseries) {
+				require.Contains(t, series, "delta_requests")
+				assert.Equal(t, []mimirpb.Sample{{TimestampMs: 1, Value: 1}, {TimestampMs: 2, Value: 2}}, series["delta_requests"].Samples)
+			},
+			expectedCode: http.StatusOK,
+		},
+		"request with only delta metrics": {
+			limits: func(*validation.Limits) {},
+			md: func() pmetric.Metrics {
+				md := createMetrics()
+				md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().RemoveIf(func(m pmetric.Metric) bool {
+					return m.Name() == "requests"
+				})
+				return md
+			},
+			verify: func(t *testing.T, series map[string]mimirpb.PreallocTimeseries) {
+				// Only the target_info series of the resource is left.
+				assert.Len(t, series, 1)
+				assert.Contains(t, series, "target_info")
+			},
+			expectedCode:    http.StatusBadRequest,
+			expectedDropped: 2,
+		},
+		"exponential histograms downscaled to the max scale": {
+			limits: func(l *validation.Limits) {
+				l.OTelExponentialHistogramMaxScale = 0
+			},
+			md: func() pmetric.Metrics {
+				md := pmetric.NewMetrics()
+				metric := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+				metric.SetName("latency")
+				metric.SetEmptyExponentialHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+				dp := metric.ExponentialHistogram().DataPoints().AppendEmpty()
+				dp.SetTimestamp(pcommon.Timestamp(time.Millisecond))
+				dp.SetScale(2)
+				dp.SetCount(8)
+				dp.SetSum(20)
+				// Buckets with index -3 to 4 at scale 2 fall into the buckets with index -1 to 1 at scale 0.
+				dp.Positive().SetOffset(-3)
+				dp.Positive().BucketCounts().FromRaw([]uint64{1, 1, 1, 1, 1, 1, 1, 1})
+				return md
+			},
+			verify: func(t *testing.T, series map[string]mimirpb.PreallocTimeseries) {
+				require.Contains(t, series, "latency")
+				require.Len(t, series["latency"].Histograms, 1)
+
+				h := series["latency"].Histograms[0]
+				assert.Equal(t, int32(0), h.Schema)
+				// Prometheus bucket indexes are the OTLP ones plus 1.
+				assert.Equal(t, []mimirpb.BucketSpan{{Offset: 0, Length: 3}}, h.PositiveSpans)
+				assert.Equal(t, []int64{3, 1, -3}, h.PositiveDeltas)
+			},
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			limits := validation.MockOverrides(func(defaults *validation.Limits, _ map[string]*validation.Limits) {
+				tc.limits(defaults)
+			})
+			reg := prometheus.NewPedanticRegistry()
+
+			handler := OTLPHandler(100000, nil, false, false, limits, reg, func(ctx context.Context, pushReq *Request) error {
+				request, err := pushReq.WriteRequest()
+				if err != nil {
+					return err
+				}
+				defer pushReq.CleanUp()
+
+				series := map[string]mimirpb.PreallocTimeseries{}
+				for _, ts := range request.Timeseries {
+					series[mimirpb.FromLabelAdaptersToLabels(ts.Labels).Get(model.MetricNameLabel)] = ts
+				}
+				tc.verify(t, series)
+				return nil
+			})
+
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, createOTLPRequest(t, pmetricotlp.NewExportRequestFromMetrics(tc.md()), false))
+			assert.Equal(t, tc.expectedCode, resp.Code)
+
+			dropped, err := testutil.GatherAndCount(reg, "cortex_discarded_samples_total")
+			require.NoError(t, err)
+			if tc.expectedDropped == 0 {
+				assert.Equal(t, 0, dropped)
+			} else {
+				assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(fmt.Sprintf(`
+					# HELP cortex_discarded_samples_total The total number of samples that were discarded.
+					# TYPE cortex_discarded_samples_total counter
+					cortex_discarded_samples_total{group="",reason="otlp_delta_temporality",user="test"} %v
+				`, tc.expectedDropped)), "cortex_discarded_samples_total"))
+			}
+		})
+	}
+}
+
+func TestNormalizedOTelMetricName(t *testing.T) {
+	tests := map[string]struct {
+		name, unit string
+		setType    func(pmetric.Metric)
+		expected   string
+	}{
+		"counter with unit": {
+			name:     "http.server.duration",
+			unit:     "ms",
+			setType:  func(m pmetric.Metric) { m.SetEmptySum().SetIsMonotonic(true) },
+			expected: "http_server_duration_milliseconds_total",
+		},
+		"counter already with suffixes": {
+			name:     "requests_total_seconds",
+			unit:     "s",
+			setType:  func(m pmetric.Metric) { m.SetEmptySum().SetIsMonotonic(true) },
+			expected: "requests_seconds_total",
+		},
+		"gauge with per unit": {
+			name:     "throughput",
+			unit:     "By/s",
+			setType:  func(m pmetric.Metric) { m.SetEmptyGauge() },
+			expected: "throughput_bytes_per_second",
+		},
+		"gauge with ratio unit": {
+			name:     "cpu.utilization",
+			unit:     "1",
+			setType:  func(m pmetric.Metric) { m.SetEmptyGauge() },
+			expected: "cpu_utilization_ratio",
+		},
+		"annotation unit": {
+			name:     "3xx.responses",
+			unit:     "{response}",
+			setType:  func(m pmetric.Metric) { m.SetEmptyGauge() },
+			expected: "_3xx_responses",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			metric := pmetric.NewMetric()
+			metric.SetName(tc.name)
+			metric.SetUnit(tc.unit)
+			tc.setType(metric)
+
+			assert.Equal(t, tc.expected, normalizedOTelMetricName(metric))
+		})
+	}
+}
+
 func TestHandler_otlpDroppedMetricsPanic(t *testing.T) {
 	// https://github.com/grafana/mimir/issues/3037 is triggered by a single metric
 	// having two different datapoints that correspond to different Prometheus metrics.
@@ -320,7 +617,7 @@ func TestHandler_otlpDroppedMetricsPanic(t *testing.T) {
 
 	req := createOTLPRequest(t, pmetricotlp.NewExportRequestFromMetrics(md), false)
 	resp := httptest.NewRecorder()
-	handler := OTLPHandler(100000, nil, false, true, nil, nil, func(ctx context.Context, pushReq *Request) error {
+	handler := OTLPHandler(100000, nil, false, true, validation.MockDefaultOverrides(), nil, func(ctx context.Context, pushReq *Request) error {
 		request, err := pushReq.WriteRequest()
 		assert.NoError(t, err)
 		assert.Len(t, request.Timeseries, 3)
@@ -360,7 +657,7 @@ func TestHandler_otlpDroppedMetricsPanic2(t *testing.T) {
 
 	req := createOTLPRequest(t, pmetricotlp.NewExportRequestFromMetrics(md), false)
 	resp := httptest.NewRecorder()
-	handler := OTLPHandler(100000, nil, false, true, nil, nil, func(ctx context.Context, pushReq *Request) error {
+	handler := OTLPHandler(100000, nil, false, true, validation.MockDefaultOverrides(), nil, func(ctx context.Context, pushReq *Request) error {
 		request, err := pushReq.WriteRequest()
 		assert.NoError(t, err)
 		assert.Len(t, request.Timeseries, 2)
@@ -386,7 +683,7 @@ func TestHandler_otlpDroppedMetricsPanic2(t *testing.T) {
 
 	req = createOTLPRequest(t, pmetricotlp.NewExportRequestFromMetrics(md), false)
 	resp = httptest.NewRecorder()
-	handler = OTLPHandler(100000, nil, false, true, nil, nil, func(ctx context.Context, pushReq *Request) error {
+	handler = OTLPHandler(100000, nil, false, true, validation.MockDefaultOverrides(), nil, func(ctx context.Context, pushReq *Request) error {
 		request, err := pushReq.WriteRequest()
 		assert.NoError(t, err)
 		assert.Len(t, request.Timeseries, 10) // 6 buckets (including +Inf) + 2 sum/count + 2 from the first case
@@ -415,7 +712,7 @@ func TestHandler_otlpWriteRequestTooBigWithCompression(t *testing.T) {
 
 	resp := httptest.NewRecorder()
 
-	handler := OTLPHandler(140, nil, false, true, nil, nil, readBodyPushFunc(t))
+	handler := OTLPHandler(140, nil, false, true, validation.MockDefaultOverrides(), nil, readBodyPushFunc(t))
 	handler.ServeHTTP(resp, req)
 	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.Code)
 	body, err := io.ReadAll(resp.Body)
@@ -645,6 +942,46 @@ func createPrometheusRemoteWriteProtobuf(t testing.TB) []byte {
 	return inputBytes
 }
 
+// createPrometheusRemoteWrite2Protobuf returns a remote-write 2.0 request with a single series, two samples and an exemplar.
+func createPrometheusRemoteWrite2Protobuf(t testing.TB) []byte {
+	t.Helper()
+
+	var data []byte
+	for _, symbol := range []string{"", "__name__", "foo", "job", "test", "trace_id", "1234", "Foo help."} {
+		data = protowire.AppendTag(data, 4, protowire.BytesType)
+		data = protowire.AppendString(data, symbol)
+	}
+
+	var series []byte
+	series = protowire.AppendTag(series, 1, protowire.BytesType)
+	series = protowire.AppendBytes(series, []byte{1, 2, 3, 4})
+	for _, sample := range []mimirpb.Sample{{Value: 1, TimestampMs: 1000}, {Value: 2, TimestampMs: 2000}} {
+		sampleData, err := sample.Marshal()
+		require.NoError(t, err)
+		series = protowire.AppendTag(series, 2, protowire.BytesType)
+		series = protowire.AppendBytes(series, sampleData)
+	}
+
+	var exemplar []byte
+	exemplar = protowire.AppendTag(exemplar, 1, protowire.BytesType)
+	exemplar = protowire.AppendBytes(exemplar, []byte{5, 6})
+	exemplar = protowire.AppendTag(exemplar, 3, protowire.VarintType)
+	exemplar = protowire.AppendVarint(exemplar, 2000)
+	series = protowire.AppendTag(series, 4, protowire.BytesType)
+	series = protowire.AppendBytes(series, exemplar)
+
+	var metadata []byte
+	metadata = protowire.AppendTag(metadata, 1, protowire.VarintType)
+	metadata = protowire.AppendVarint(metadata, uint64(mimirpb.COUNTER))
+	metadata = protowire.AppendTag(metadata, 3, protowire.VarintType)
+	metadata = protowire.AppendVarint(metadata, 7)
+	series = protowire.AppendTag(series, 5, protowire.BytesType)
+	series = protowire.AppendBytes(series, metadata)
+
+	data = protowire.AppendTag(data, 5, protowire.BytesType)
+	return protowire.AppendBytes(data, series)
+}
+
 func createMimirWriteRequestProtobuf(t *testing.T, skipLabelNameValidation bool) []byte {
 	t.Helper()
 	h := remote.HistogramToHistogramProto(1337, test.GenerateTestHistogram(1))
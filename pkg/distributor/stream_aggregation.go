@@ -0,0 +1,568 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package distributor
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/concurrency"
+	"github.com/grafana/dskit/ring"
+	"github.com/grafana/dskit/services"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+	"golang.org/x/exp/slices"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+	"github.com/grafana/mimir/pkg/util/validation"
+)
+
+const (
+	// streamAggregationFlushPeriod is how often the stream aggregators are checked for ended intervals, and the
+	// input series owned by other distributors are forwarded to them.
+	streamAggregationFlushPeriod = time.Second
+
+	// streamAggregationRulesRecheckPeriod is how often the per-tenant stream aggregation rules are checked for changes.
+	streamAggregationRulesRecheckPeriod = 10 * time.Second
+
+	// streamAggregationSeriesStaleness is the minimum period after which an input series which didn't receive any
+	// sample is removed from the output of the stream aggregation rules.
+	streamAggregationSeriesStaleness = 5 * time.Minute
+
+	// streamAggregationForwardConcurrency is the maximum number of concurrent requests forwarding input series
+	// to other distributors.
+	streamAggregationForwardConcurrency = 16
+
+	// streamAggregationInputMetadataKey is the gRPC metadata key marking the push requests whose series are the
+	// input of stream aggregation rules forwarded by another distributor, which must be aggregated but not ingested.
+	streamAggregationInputMetadataKey = "x-mimir-stream-aggregation-input"
+)
+
+// streamAggregationRingOp is the operation used to find the distributor owning the output series of a stream aggregation rule.
+var streamAggregationRingOp = ring.NewOp([]ring.InstanceState{ring.ACTIVE}, nil)
+
+// streamAggregationRing is the subset of the distributors ring used to shard the stream aggregation rules outputs.
+type streamAggregationRing interface {
+	Get(key uint32, op ring.Operation, bufDescs []ring.InstanceDesc, bufHosts, bufZones []string) (ring.ReplicationSet, error)
+}
+
+// streamAggregationPushFunc pushes the output series of the stream aggregation rules of a tenant.
+type streamAggregationPushFunc func(ctx context.Context, req *mimirpb.WriteRequest) error
+
+// streamAggregationForwardFunc forwards input series of the stream aggregation rules to the distributor owning their output.
+type streamAggregationForwardFunc func(ctx context.Context, inst ring.InstanceDesc, req *mimirpb.WriteRequest) error
+
+// streamAggregators evaluates the per-tenant stream aggregation rules on the samples received by the distributor,
+// and periodically pushes their output series.
+//
+// Each output series is owned by a single distributor, found hashing its labels on the distributors ring, so that
+// all the samples of its input series are aggregated by the same distributor: the input series whose output is owned
+// by another distributor are forwarded to it. When the distributors ring isn't available, each distributor aggregates
+// all the samples it receives.
+type streamAggregators struct {
+	services.Service
+
+	limits     *validation.Overrides
+	instanceID string
+	ring       streamAggregationRing
+	push       streamAggregationPushFunc
+	forward    streamAggregationForwardFunc
+	logger     log.Logger
+
+	aggregatedSamples *prometheus.CounterVec
+	forwardedSamples  *prometheus.CounterVec
+	pushFailures      *prometheus.CounterVec
+	forwardFailures   *prometheus.CounterVec
+
+	mtx     sync.Mutex
+	tenants map[string]*tenantStreamAggregators
+
+	forwardsMtx sync.Mutex
+	forwards    map[streamAggregationForwardKey]*streamAggregationForward
+}
+
+type tenantStreamAggregators struct {
+	config      []*validation.StreamAggregationRule
+	aggregators []*streamAggregator
+	recheckAt   time.Time
+}
+
+type streamAggregationForwardKey struct {
+	userID     string
+	instanceID string
+}
+
+// streamAggregationForward holds the input series to forward to another distributor.
+type streamAggregationForward struct {
+	inst    ring.InstanceDesc
+	series  []mimirpb.PreallocTimeseries
+	samples int
+}
+
+func newStreamAggregators(limits *validation.Overrides, instanceID string, aggregationRing streamAggregationRing, push streamAggregationPushFunc, forward streamAggregationForwardFunc, logger log.Logger, reg prometheus.Registerer) *streamAggregators {
+	s := &streamAggregators{
+		limits:     limits,
+		instanceID: instanceID,
+		ring:       aggregationRing,
+		push:       push,
+		forward:    forward,
+		logger:     logger,
+		tenants:    map[string]*tenantStreamAggregators{},
+		forwards:   map[streamAggregationForwardKey]*streamAggregationForward{},
+
+		aggregatedSamples: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_distributor_stream_aggregated_samples_total",
+			Help: "The total number of samples aggregated by the stream aggregation rules.",
+		}, []string{"user"}),
+		forwardedSamples: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_distributor_stream_aggregation_forwarded_samples_total",
+			Help: "The total number of samples forwarded to the distributors owning the output series of the stream aggregation rules.",
+		}, []string{"user"}),
+		pushFailures: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_distributor_stream_aggregation_push_failures_total",
+			Help: "The total number of failed pushes of the output series of the stream aggregation rules.",
+		}, []string{"user"}),
+		forwardFailures: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_distributor_stream_aggregation_forward_failures_total",
+			Help: "The total number of failed requests forwarding input series of the stream aggregation rules to other distributors.",
+		}, []string{"user"}),
+	}
+
+	s.Service = services.NewTimerService(streamAggregationFlushPeriod, nil, s.iteration, nil).WithName("stream aggregation")
+	return s
+}
+
+func (s *streamAggregators) iteration(ctx context.Context) error {
+	s.forwardInputs(ctx)
+	s.flush(ctx, time.Now())
+	return nil
+}
+
+// tenantAggregators returns the stream aggregators of the tenant, in the configured order.
+func (s *streamAggregators) tenantAggregators(userID string, now time.Time) []*streamAggregator {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	t := s.tenants[userID]
+	if t != nil && now.Before(t.recheckAt) {
+		return t.aggregators
+	}
+
+	config := s.limits.StreamAggregationRules(userID)
+	if len(config) == 0 {
+		// The aggregated samples of the removed rules are discarded.
+		delete(s.tenants, userID)
+		return nil
+	}
+
+	if t == nil || !reflect.DeepEqual(t.config, config) {
+		t = &tenantStreamAggregators{config: config}
+		for _, rule := range config {
+			matchers, err := parser.ParseMetricSelector(rule.Match)
+			if err != nil {
+				// The limits are validated when loaded, so this should never happen.
+				continue
+			}
+			t.aggregators = append(t.aggregators, newStreamAggregator(*rule, matchers, now))
+		}
+		s.tenants[userID] = t
+	}
+
+	t.recheckAt = now.Add(streamAggregationRulesRecheckPeriod)
+	return t.aggregators
+}
+
+// aggregate aggregates the float samples of the series matching the tenant's stream aggregation rules whose output
+// is owned by this distributor, queues the other ones to be forwarded to the distributors owning their output, and
+// returns the indexes of the series which must be dropped because a matching rule drops its input.
+func (s *streamAggregators) aggregate(userID string, series []mimirpb.PreallocTimeseries, now time.Time) []int {
+	return s.aggregateSeries(userID, series, now, true)
+}
+
+// aggregateForwarded aggregates the float samples of the series forwarded by another distributor, matching the
+// tenant's stream aggregation rules whose output is owned by this distributor. The forwarded series are never
+// forwarded again: if the distributors disagree on the owner of an output series while the ring changes, its
+// samples are dropped.
+func (s *streamAggregators) aggregateForwarded(userID string, series []mimirpb.PreallocTimeseries, now time.Time) {
+	s.aggregateSeries(userID, series, now, false)
+}
+
+func (s *streamAggregators) aggregateSeries(userID string, series []mimirpb.PreallocTimeseries, now time.Time, forward bool) []int {
+	aggregators := s.tenantAggregators(userID, now)
+	if len(aggregators) == 0 {
+		return nil
+	}
+
+	var dropIndexes []int
+	var aggregated int
+	var bufDescs [1]ring.InstanceDesc
+	var owners []ring.InstanceDesc
+	for tsIdx, ts := range series {
+		// Native histograms aren't aggregated, so the series with native histogram samples are always kept.
+		if len(ts.Samples) == 0 || len(ts.Histograms) > 0 {
+			continue
+		}
+
+		lbls := mimirpb.FromLabelAdaptersToLabels(ts.Labels)
+		drop := false
+		owners = owners[:0]
+		for _, a := range aggregators {
+			if !a.matches(lbls) {
+				continue
+			}
+			drop = drop || a.rule.DropInput
+
+			outputLabels := a.outputLabels(lbls)
+			owner, local := s.owner(userID, a, outputLabels, bufDescs[:0])
+			if local {
+				a.add(outputLabels, lbls, ts.Samples, now)
+				aggregated += len(ts.Samples)
+			} else if forward && !slices.ContainsFunc(owners, func(o ring.InstanceDesc) bool { return o.Id == owner.Id }) {
+				// The series is forwarded once to each distributor, which evaluates all the rules it owns.
+				owners = append(owners, owner)
+			}
+		}
+		for _, owner := range owners {
+			s.enqueueForward(userID, owner, lbls, ts.Samples)
+		}
+		if drop && forward {
+			dropIndexes = append(dropIndexes, tsIdx)
+		}
+	}
+
+	if aggregated > 0 {
+		s.aggregatedSamples.WithLabelValues(userID).Add(float64(aggregated))
+	}
+	return dropIndexes
+}
+
+// owner returns the distributor owning the output series with the input labels of the aggregator, and whether it's
+// this distributor. When the owner can't be found, the output series are owned by this distributor.
+func (s *streamAggregators) owner(userID string, a *streamAggregator, outputLabels labels.Labels, bufDescs []ring.InstanceDesc) (ring.InstanceDesc, bool) {
+	if s.ring == nil {
+		return ring.InstanceDesc{}, true
+	}
+
+	set, err := s.ring.Get(streamAggregationToken(userID, a.suffix, outputLabels), streamAggregationRingOp, bufDescs, nil, nil)
+	if err != nil || len(set.Instances) == 0 || set.Instances[0].Id == s.instanceID {
+		return ring.InstanceDesc{}, true
+	}
+	return set.Instances[0], false
+}
+
+var streamAggregationTokenSep = []byte("/")
+
+// streamAggregationToken returns the token of the output series of a stream aggregation rule in the distributors ring.
+func streamAggregationToken(userID, suffix string, outputLabels labels.Labels) uint32 {
+	h := fnv.New32a()
+
+	// Hasher never returns err.
+	_, _ = h.Write([]byte(userID))
+	_, _ = h.Write(streamAggregationTokenSep)
+	_, _ = h.Write([]byte(suffix))
+	_, _ = h.Write(streamAggregationTokenSep)
+	_, _ = h.Write(outputLabels.Bytes(nil))
+
+	return h.Sum32()
+}
+
+// enqueueForward queues a copy of the input series, to be forwarded to the distributor owning its output.
+func (s *streamAggregators) enqueueForward(userID string, inst ring.InstanceDesc, series labels.Labels, samples []mimirpb.Sample) {
+	// Copy the labels and samples, as they reference a request buffer.
+	ts := mimirpb.PreallocTimeseries{TimeSeries: &mimirpb.TimeSeries{
+		Labels:  mimirpb.FromLabelsToLabelAdapters(mimirpb.CopyLabels(series)),
+		Samples: slices.Clone(samples),
+	}}
+
+	s.forwardsMtx.Lock()
+	defer s.forwardsMtx.Unlock()
+
+	key := streamAggregationForwardKey{userID: userID, instanceID: inst.Id}
+	f := s.forwards[key]
+	if f == nil {
+		f = &streamAggregationForward{inst: inst}
+		s.forwards[key] = f
+	}
+	f.series = append(f.series, ts)
+	f.samples += len(samples)
+}
+
+// forwardInputs forwards the queued input series to the distributors owning their output.
+func (s *streamAggregators) forwardInputs(ctx context.Context) {
+	s.forwardsMtx.Lock()
+	forwards := make([]streamAggregationForwardKey, 0, len(s.forwards))
+	for key := range s.forwards {
+		forwards = append(forwards, key)
+	}
+	pending := s.forwards
+	s.forwards = map[streamAggregationForwardKey]*streamAggregationForward{}
+	s.forwardsMtx.Unlock()
+
+	_ = concurrency.ForEachJob(ctx, len(forwards), streamAggregationForwardConcurrency, func(ctx context.Context, idx int) error {
+		key := forwards[idx]
+		f := pending[key]
+
+		req := &mimirpb.WriteRequest{Timeseries: f.series, Source: mimirpb.API}
+		if err := s.forward(user.InjectOrgID(ctx, key.userID), f.inst, req); err != nil {
+			s.forwardFailures.WithLabelValues(key.userID).Inc()
+			level.Warn(s.logger).Log("msg", "failed to forward the input series of the stream aggregation rules", "user", key.userID, "distributor", key.instanceID, "err", err)
+			return nil
+		}
+
+		s.forwardedSamples.WithLabelValues(key.userID).Add(float64(f.samples))
+		return nil
+	})
+}
+
+// flush pushes the output series of the stream aggregators whose interval ended.
+func (s *streamAggregators) flush(ctx context.Context, now time.Time) {
+	s.mtx.Lock()
+	tenants := make(map[string][]*streamAggregator, len(s.tenants))
+	for userID, t := range s.tenants {
+		tenants[userID] = t.aggregators
+	}
+	s.mtx.Unlock()
+
+	for userID, aggregators := range tenants {
+		req := &mimirpb.WriteRequest{Source: mimirpb.API}
+		for _, a := range aggregators {
+			req.Timeseries = append(req.Timeseries, a.flush(now)...)
+		}
+		if len(req.Timeseries) == 0 {
+			continue
+		}
+
+		if err := s.push(user.InjectOrgID(ctx, userID), req); err != nil {
+			s.pushFailures.WithLabelValues(userID).Inc()
+			level.Warn(s.logger).Log("msg", "failed to push the output series of the stream aggregation rules", "user", userID, "err", err)
+		}
+	}
+}
+
+func (s *streamAggregators) deleteUser(userID string) {
+	s.mtx.Lock()
+	delete(s.tenants, userID)
+	s.mtx.Unlock()
+
+	s.aggregatedSamples.DeleteLabelValues(userID)
+	s.forwardedSamples.DeleteLabelValues(userID)
+	s.pushFailures.DeleteLabelValues(userID)
+	s.forwardFailures.DeleteLabelValues(userID)
+}
+
+type streamAggregationContextKey int
+
+const (
+	// streamAggregationOutputContextKey marks the push requests of the output series of the stream aggregation rules.
+	streamAggregationOutputContextKey streamAggregationContextKey = iota
+)
+
+// contextWithStreamAggregationOutput returns a context marking the push request as the output series of the
+// stream aggregation rules, which aren't aggregated again even if they match a rule.
+func contextWithStreamAggregationOutput(ctx context.Context) context.Context {
+	return context.WithValue(ctx, streamAggregationOutputContextKey, true)
+}
+
+func isStreamAggregationOutput(ctx context.Context) bool {
+	v, ok := ctx.Value(streamAggregationOutputContextKey).(bool)
+	return ok && v
+}
+
+// isForwardedStreamAggregationInput returns whether the gRPC push request contains the input series of the stream
+// aggregation rules forwarded by another distributor.
+func isForwardedStreamAggregationInput(ctx context.Context) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	return ok && len(md.Get(streamAggregationInputMetadataKey)) > 0
+}
+
+// streamAggregator evaluates a stream aggregation rule.
+type streamAggregator struct {
+	rule     validation.StreamAggregationRule
+	matchers []*labels.Matcher
+	// suffix is appended to the metric name of the input series to get the name of the output series, before the output.
+	suffix string
+	// staleness is the period after which an input series which didn't receive any sample is removed from the groups.
+	staleness time.Duration
+
+	mtx       sync.Mutex
+	windowEnd time.Time
+	groups    map[string]*streamAggregationGroup
+}
+
+// streamAggregationGroup holds the aggregated samples of the input series with the same output labels. The latest
+// values of the input series are kept across intervals, until the series become stale.
+type streamAggregationGroup struct {
+	labels       labels.Labels
+	series       map[uint64]streamAggregationSeries // The input series, keyed by the hash of their labels.
+	sumSamples   float64
+	countSamples int
+}
+
+// streamAggregationSeries holds the latest value of an input series.
+type streamAggregationSeries struct {
+	value    float64
+	lastSeen time.Time
+}
+
+func newStreamAggregator(rule validation.StreamAggregationRule, matchers []*labels.Matcher, now time.Time) *streamAggregator {
+	suffix := ":" + rule.Interval.String()
+	if len(rule.By) > 0 {
+		suffix += "_by_" + strings.Join(rule.By, "_")
+	}
+	if len(rule.Without) > 0 {
+		suffix += "_without_" + strings.Join(rule.Without, "_")
+	}
+
+	staleness := streamAggregationSeriesStaleness
+	if interval := time.Duration(rule.Interval); interval > staleness {
+		staleness = interval
+	}
+
+	return &streamAggregator{
+		rule:      rule,
+		matchers:  matchers,
+		suffix:    suffix,
+		staleness: staleness,
+		windowEnd: streamAggregationWindowEnd(now, time.Duration(rule.Interval)),
+		groups:    map[string]*streamAggregationGroup{},
+	}
+}
+
+// streamAggregationWindowEnd returns the end of the aggregation interval including now. Intervals are aligned to
+// multiples of their duration.
+func streamAggregationWindowEnd(now time.Time, interval time.Duration) time.Time {
+	return now.Truncate(interval).Add(interval)
+}
+
+func (a *streamAggregator) matches(series labels.Labels) bool {
+	for _, m := range a.matchers {
+		if !m.Matches(series.Get(m.Name)) {
+			return false
+		}
+	}
+	return true
+}
+
+// outputLabels returns the labels shared by the output series of the input series, including the input metric name.
+func (a *streamAggregator) outputLabels(series labels.Labels) labels.Labels {
+	if len(a.rule.By) > 0 {
+		b := labels.NewScratchBuilder(len(a.rule.By) + 1)
+		series.Range(func(l labels.Label) {
+			if l.Name == labels.MetricName || slices.Contains(a.rule.By, l.Name) {
+				b.Add(l.Name, l.Value)
+			}
+		})
+		return b.Labels()
+	}
+
+	b := labels.NewBuilder(series)
+	b.Del(a.rule.Without...)
+	return b.Labels()
+}
+
+func (a *streamAggregator) add(outputLabels, series labels.Labels, samples []mimirpb.Sample, now time.Time) {
+	key := outputLabels.String()
+	seriesHash := series.Hash()
+
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	g := a.groups[key]
+	if g == nil {
+		// Copy the labels, as they could reference a request buffer.
+		g = &streamAggregationGroup{labels: mimirpb.CopyLabels(outputLabels), series: map[uint64]streamAggregationSeries{}}
+		a.groups[key] = g
+	}
+
+	for _, s := range samples {
+		g.sumSamples += s.Value
+		g.countSamples++
+	}
+	g.series[seriesHash] = streamAggregationSeries{value: samples[len(samples)-1].Value, lastSeen: now}
+}
+
+// flush returns the output series of the groups if the aggregation interval ended, and starts a new interval.
+// The input series which became stale are removed from the groups before computing their output.
+func (a *streamAggregator) flush(now time.Time) []mimirpb.PreallocTimeseries {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	if now.Before(a.windowEnd) {
+		return nil
+	}
+
+	ts := a.windowEnd.UnixMilli()
+	staleBefore := now.Add(-a.staleness)
+	var out []mimirpb.PreallocTimeseries
+	for key, g := range a.groups {
+		for seriesHash, s := range g.series {
+			if s.lastSeen.Before(staleBefore) {
+				delete(g.series, seriesHash)
+			}
+		}
+		if len(g.series) == 0 {
+			delete(a.groups, key)
+			continue
+		}
+
+		metricName := g.labels.Get(labels.MetricName) + a.suffix
+		for _, output := range a.rule.Outputs {
+			b := labels.NewBuilder(g.labels)
+			b.Set(labels.MetricName, metricName+"_"+output)
+
+			out = append(out, mimirpb.PreallocTimeseries{TimeSeries: &mimirpb.TimeSeries{
+				Labels:  mimirpb.FromLabelsToLabelAdapters(b.Labels()),
+				Samples: []mimirpb.Sample{{TimestampMs: ts, Value: g.value(output)}},
+			}})
+		}
+
+		g.sumSamples = 0
+		g.countSamples = 0
+	}
+
+	a.windowEnd = streamAggregationWindowEnd(now, time.Duration(a.rule.Interval))
+	return out
+}
+
+func (g *streamAggregationGroup) value(output string) float64 {
+	switch output {
+	case validation.StreamAggregationOutputSum:
+		sum := 0.0
+		for _, s := range g.series {
+			sum += s.value
+		}
+		return sum
+	case validation.StreamAggregationOutputCount:
+		return float64(len(g.series))
+	case validation.StreamAggregationOutputMin:
+		minValue := math.Inf(1)
+		for _, s := range g.series {
+			minValue = math.Min(minValue, s.value)
+		}
+		return minValue
+	case validation.StreamAggregationOutputMax:
+		maxValue := math.Inf(-1)
+		for _, s := range g.series {
+			maxValue = math.Max(maxValue, s.value)
+		}
+		return maxValue
+	case validation.StreamAggregationOutputSumSamples:
+		return g.sumSamples
+	case validation.StreamAggregationOutputCountSamples:
+		return float64(g.countSamples)
+	default:
+		// The outputs are validated when the limits are loaded, so this should never happen.
+		return math.NaN()
+	}
+}
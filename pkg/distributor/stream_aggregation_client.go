@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package distributor
+
+import (
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/grpcclient"
+	"github.com/grafana/dskit/ring"
+	"github.com/grafana/dskit/ring/client"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/grafana/mimir/pkg/distributor/distributorpb"
+)
+
+// newStreamAggregationClientPool returns the pool of the clients used to forward the input series of the stream
+// aggregation rules to the distributors owning their output.
+func newStreamAggregationClientPool(clientCfg grpcclient.Config, logger log.Logger, reg prometheus.Registerer) *client.Pool {
+	// We prefer sane defaults instead of exposing further config options.
+	poolCfg := client.PoolConfig{
+		CheckInterval:      10 * time.Second,
+		HealthCheckEnabled: true,
+		HealthCheckTimeout: 10 * time.Second,
+	}
+
+	clientsCount := promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+		Name: "cortex_distributor_stream_aggregation_clients",
+		Help: "The current number of distributor clients used to forward the input series of the stream aggregation rules.",
+	})
+
+	return client.NewPool("distributor", poolCfg, nil, newStreamAggregationClientFactory(clientCfg, reg), clientsCount, logger)
+}
+
+func newStreamAggregationClientFactory(clientCfg grpcclient.Config, reg prometheus.Registerer) client.PoolFactory {
+	requestDuration := promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "cortex_distributor_stream_aggregation_client_request_duration_seconds",
+		Help:    "Time spent forwarding the input series of the stream aggregation rules to other distributors.",
+		Buckets: prometheus.ExponentialBuckets(0.008, 4, 7),
+	}, []string{"operation", "status_code"})
+
+	return client.PoolInstFunc(func(inst ring.InstanceDesc) (client.PoolClient, error) {
+		return dialStreamAggregationClient(clientCfg, inst, requestDuration)
+	})
+}
+
+func dialStreamAggregationClient(clientCfg grpcclient.Config, inst ring.InstanceDesc, requestDuration *prometheus.HistogramVec) (*streamAggregationClient, error) {
+	opts, err := clientCfg.DialOption(grpcclient.Instrument(requestDuration))
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := grpc.Dial(inst.Addr, opts...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to dial distributor %s %s", inst.Id, inst.Addr)
+	}
+
+	return &streamAggregationClient{
+		DistributorClient: distributorpb.NewDistributorClient(conn),
+		HealthClient:      grpc_health_v1.NewHealthClient(conn),
+		conn:              conn,
+	}, nil
+}
+
+type streamAggregationClient struct {
+	distributorpb.DistributorClient
+	grpc_health_v1.HealthClient
+	conn *grpc.ClientConn
+}
+
+func (c *streamAggregationClient) Close() error {
+	return c.conn.Close()
+}
+
+func (c *streamAggregationClient) String() string {
+	return c.RemoteAddress()
+}
+
+func (c *streamAggregationClient) RemoteAddress() string {
+	return c.conn.Target()
+}
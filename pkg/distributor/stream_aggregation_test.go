@@ -0,0 +1,255 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package distributor
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/flagext"
+	"github.com/grafana/dskit/ring"
+	"github.com/grafana/dskit/tenant"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+	"github.com/grafana/mimir/pkg/util/validation"
+)
+
+func TestStreamAggregator(t *testing.T) {
+	now := time.Date(2023, 1, 1, 10, 0, 30, 0, time.UTC)
+
+	tests := map[string]struct {
+		rule                 validation.StreamAggregationRule
+		expected             []string
+		expectedNextInterval []string
+	}{
+		"without": {
+			rule: validation.StreamAggregationRule{Interval: model.Duration(time.Minute), Without: []string{"pod"}, Outputs: []string{"sum", "count", "min", "max", "sum_samples", "count_samples"}},
+			expected: []string{
+				`{__name__="http_requests_total:1m_without_pod_count", job="api"} 2`,
+				`{__name__="http_requests_total:1m_without_pod_count", job="web"} 1`,
+				`{__name__="http_requests_total:1m_without_pod_count_samples", job="api"} 3`,
+				`{__name__="http_requests_total:1m_without_pod_count_samples", job="web"} 1`,
+				`{__name__="http_requests_total:1m_without_pod_max", job="api"} 7`,
+				`{__name__="http_requests_total:1m_without_pod_max", job="web"} 10`,
+				`{__name__="http_requests_total:1m_without_pod_min", job="api"} 5`,
+				`{__name__="http_requests_total:1m_without_pod_min", job="web"} 10`,
+				`{__name__="http_requests_total:1m_without_pod_sum", job="api"} 12`,
+				`{__name__="http_requests_total:1m_without_pod_sum", job="web"} 10`,
+				`{__name__="http_requests_total:1m_without_pod_sum_samples", job="api"} 13`,
+				`{__name__="http_requests_total:1m_without_pod_sum_samples", job="web"} 10`,
+			},
+			// The latest values of the series are kept, while the samples are only aggregated over each interval.
+			expectedNextInterval: []string{
+				`{__name__="http_requests_total:1m_without_pod_count", job="api"} 2`,
+				`{__name__="http_requests_total:1m_without_pod_count", job="web"} 1`,
+				`{__name__="http_requests_total:1m_without_pod_count_samples", job="api"} 0`,
+				`{__name__="http_requests_total:1m_without_pod_count_samples", job="web"} 0`,
+				`{__name__="http_requests_total:1m_without_pod_max", job="api"} 7`,
+				`{__name__="http_requests_total:1m_without_pod_max", job="web"} 10`,
+				`{__name__="http_requests_total:1m_without_pod_min", job="api"} 5`,
+				`{__name__="http_requests_total:1m_without_pod_min", job="web"} 10`,
+				`{__name__="http_requests_total:1m_without_pod_sum", job="api"} 12`,
+				`{__name__="http_requests_total:1m_without_pod_sum", job="web"} 10`,
+				`{__name__="http_requests_total:1m_without_pod_sum_samples", job="api"} 0`,
+				`{__name__="http_requests_total:1m_without_pod_sum_samples", job="web"} 0`,
+			},
+		},
+		"by": {
+			rule: validation.StreamAggregationRule{Interval: model.Duration(time.Minute), By: []string{"pod"}, Outputs: []string{"sum"}},
+			expected: []string{
+				`{__name__="http_requests_total:1m_by_pod_sum", pod="a"} 15`,
+				`{__name__="http_requests_total:1m_by_pod_sum", pod="b"} 7`,
+			},
+			expectedNextInterval: []string{
+				`{__name__="http_requests_total:1m_by_pod_sum", pod="a"} 15`,
+				`{__name__="http_requests_total:1m_by_pod_sum", pod="b"} 7`,
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			a := newStreamAggregator(tc.rule, nil, now)
+			add := func(series labels.Labels, samples ...mimirpb.Sample) {
+				a.add(a.outputLabels(series), series, samples, now)
+			}
+
+			add(labels.FromStrings(labels.MetricName, "http_requests_total", "job", "api", "pod", "a"), mimirpb.Sample{TimestampMs: 1, Value: 1}, mimirpb.Sample{TimestampMs: 2, Value: 5})
+			add(labels.FromStrings(labels.MetricName, "http_requests_total", "job", "api", "pod", "b"), mimirpb.Sample{TimestampMs: 2, Value: 7})
+			add(labels.FromStrings(labels.MetricName, "http_requests_total", "job", "web", "pod", "a"), mimirpb.Sample{TimestampMs: 2, Value: 10})
+
+			// Nothing is flushed before the end of the interval.
+			assert.Empty(t, a.flush(now.Add(29*time.Second)))
+
+			out := a.flush(now.Add(30 * time.Second))
+			for _, ts := range out {
+				require.Len(t, ts.Samples, 1)
+				assert.Equal(t, now.Add(30*time.Second).UnixMilli(), ts.Samples[0].TimestampMs)
+			}
+			assert.Equal(t, tc.expected, formatStreamAggregationOutput(out))
+
+			// The series which didn't receive samples in the next interval are still aggregated.
+			assert.Equal(t, tc.expectedNextInterval, formatStreamAggregationOutput(a.flush(now.Add(90*time.Second))))
+
+			// The stale series are removed.
+			assert.NotEmpty(t, a.flush(now.Add(streamAggregationSeriesStaleness)))
+			assert.Empty(t, a.flush(now.Add(streamAggregationSeriesStaleness+time.Minute)))
+		})
+	}
+}
+
+func TestStreamAggregators(t *testing.T) {
+	limits := validation.Limits{}
+	flagext.DefaultValues(&limits)
+	limits.StreamAggregationRules = []*validation.StreamAggregationRule{
+		{Match: "http_requests_total", Interval: model.Duration(time.Minute), Without: []string{"pod"}, Outputs: []string{"sum"}, DropInput: true},
+		{Match: `{job="api"}`, Interval: model.Duration(time.Minute), By: []string{"job"}, Outputs: []string{"count"}},
+	}
+	overrides, err := validation.NewOverrides(limits, nil)
+	require.NoError(t, err)
+
+	var pushed []string
+	push := func(ctx context.Context, req *mimirpb.WriteRequest) error {
+		userID, err := tenant.TenantID(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "user", userID)
+		pushed = append(pushed, formatStreamAggregationOutput(req.Timeseries)...)
+		return nil
+	}
+	forward := func(context.Context, ring.InstanceDesc, *mimirpb.WriteRequest) error {
+		require.FailNow(t, "no input series should be forwarded without the distributors ring")
+		return nil
+	}
+
+	reg := prometheus.NewPedanticRegistry()
+	s := newStreamAggregators(overrides, "distributor-1", nil, push, forward, log.NewNopLogger(), reg)
+
+	now := time.Date(2023, 1, 1, 10, 0, 30, 0, time.UTC)
+	series := []mimirpb.PreallocTimeseries{
+		makeWriteRequestTimeseries([]mimirpb.LabelAdapter{{Name: labels.MetricName, Value: "http_requests_total"}, {Name: "job", Value: "api"}, {Name: "pod", Value: "a"}}, 1, 1),
+		makeWriteRequestTimeseries([]mimirpb.LabelAdapter{{Name: labels.MetricName, Value: "http_requests_total"}, {Name: "job", Value: "api"}, {Name: "pod", Value: "b"}}, 1, 2),
+		makeWriteRequestTimeseries([]mimirpb.LabelAdapter{{Name: labels.MetricName, Value: "up"}, {Name: "job", Value: "api"}}, 1, 1),
+		makeWriteRequestTimeseries([]mimirpb.LabelAdapter{{Name: labels.MetricName, Value: "up"}, {Name: "job", Value: "web"}}, 1, 1),
+	}
+
+	// Only the input of the rules dropping it is dropped.
+	assert.Equal(t, []int{0, 1}, s.aggregate("user", series, now))
+
+	s.flush(context.Background(), now)
+	assert.Empty(t, pushed)
+
+	s.flush(context.Background(), now.Add(30*time.Second))
+	assert.Equal(t, []string{
+		`{__name__="http_requests_total:1m_by_job_count", job="api"} 2`,
+		`{__name__="http_requests_total:1m_without_pod_sum", job="api"} 3`,
+		`{__name__="up:1m_by_job_count", job="api"} 1`,
+	}, pushed)
+
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+		# HELP cortex_distributor_stream_aggregated_samples_total The total number of samples aggregated by the stream aggregation rules.
+		# TYPE cortex_distributor_stream_aggregated_samples_total counter
+		cortex_distributor_stream_aggregated_samples_total{user="user"} 5
+	`), "cortex_distributor_stream_aggregated_samples_total"))
+}
+
+func TestStreamAggregators_ShardingByOutputSeries(t *testing.T) {
+	limits := validation.Limits{}
+	flagext.DefaultValues(&limits)
+	limits.StreamAggregationRules = []*validation.StreamAggregationRule{
+		{Match: "http_requests_total", Interval: model.Duration(time.Minute), By: []string{"job"}, Outputs: []string{"sum"}, DropInput: true},
+	}
+	overrides, err := validation.NewOverrides(limits, nil)
+	require.NoError(t, err)
+
+	// Each output series is owned by the distributor whose ID is the value of its job label.
+	aggregationRing := &mockStreamAggregationRing{owners: map[uint32]ring.InstanceDesc{}}
+	for _, job := range []string{"distributor-1", "distributor-2"} {
+		token := streamAggregationToken("user", ":1m_by_job", labels.FromStrings(labels.MetricName, "http_requests_total", "job", job))
+		aggregationRing.owners[token] = ring.InstanceDesc{Id: job, Addr: job + ":9095"}
+	}
+
+	now := time.Date(2023, 1, 1, 10, 0, 30, 0, time.UTC)
+	series := func(job, pod string, value float64) mimirpb.PreallocTimeseries {
+		return makeWriteRequestTimeseries([]mimirpb.LabelAdapter{{Name: labels.MetricName, Value: "http_requests_total"}, {Name: "job", Value: job}, {Name: "pod", Value: pod}}, 1, value)
+	}
+
+	pushed := map[string][]string{}
+	forwarded := map[string][]mimirpb.PreallocTimeseries{}
+	distributors := map[string]*streamAggregators{}
+	for _, instanceID := range []string{"distributor-1", "distributor-2"} {
+		instanceID := instanceID
+		push := func(_ context.Context, req *mimirpb.WriteRequest) error {
+			pushed[instanceID] = append(pushed[instanceID], formatStreamAggregationOutput(req.Timeseries)...)
+			return nil
+		}
+		forward := func(ctx context.Context, inst ring.InstanceDesc, req *mimirpb.WriteRequest) error {
+			userID, err := tenant.TenantID(ctx)
+			require.NoError(t, err)
+			require.Equal(t, "user", userID)
+			forwarded[inst.Id] = append(forwarded[inst.Id], req.Timeseries...)
+			return nil
+		}
+		distributors[instanceID] = newStreamAggregators(overrides, instanceID, aggregationRing, push, forward, log.NewNopLogger(), prometheus.NewPedanticRegistry())
+	}
+
+	// Each distributor receives samples of the series of both output series, and drops all of them.
+	assert.Equal(t, []int{0, 1}, distributors["distributor-1"].aggregate("user", []mimirpb.PreallocTimeseries{series("distributor-1", "a", 1), series("distributor-2", "a", 2)}, now))
+	assert.Equal(t, []int{0, 1}, distributors["distributor-2"].aggregate("user", []mimirpb.PreallocTimeseries{series("distributor-1", "b", 3), series("distributor-2", "b", 4)}, now))
+
+	// The series of the output owned by the other distributor are forwarded to it.
+	for _, d := range distributors {
+		d.forwardInputs(context.Background())
+	}
+	require.Len(t, forwarded["distributor-1"], 1)
+	require.Len(t, forwarded["distributor-2"], 1)
+	distributors["distributor-1"].aggregateForwarded("user", forwarded["distributor-1"], now)
+	distributors["distributor-2"].aggregateForwarded("user", forwarded["distributor-2"], now)
+
+	// The forwarded series aren't forwarded again.
+	forwarded = map[string][]mimirpb.PreallocTimeseries{}
+	for _, d := range distributors {
+		d.forwardInputs(context.Background())
+	}
+	assert.Empty(t, forwarded)
+
+	// Each output series is pushed by a single distributor, with the samples received by all of them.
+	for _, d := range distributors {
+		d.flush(context.Background(), now.Add(30*time.Second))
+	}
+	assert.Equal(t, map[string][]string{
+		"distributor-1": {`{__name__="http_requests_total:1m_by_job_sum", job="distributor-1"} 4`},
+		"distributor-2": {`{__name__="http_requests_total:1m_by_job_sum", job="distributor-2"} 6`},
+	}, pushed)
+}
+
+type mockStreamAggregationRing struct {
+	owners map[uint32]ring.InstanceDesc
+}
+
+func (r *mockStreamAggregationRing) Get(key uint32, _ ring.Operation, bufDescs []ring.InstanceDesc, _, _ []string) (ring.ReplicationSet, error) {
+	owner, ok := r.owners[key]
+	if !ok {
+		return ring.ReplicationSet{}, ring.ErrEmptyRing
+	}
+	return ring.ReplicationSet{Instances: append(bufDescs, owner)}, nil
+}
+
+// formatStreamAggregationOutput returns the output series with the value of their sample, sorted.
+func formatStreamAggregationOutput(series []mimirpb.PreallocTimeseries) []string {
+	out := make([]string, 0, len(series))
+	for _, ts := range series {
+		out = append(out, mimirpb.FromLabelAdaptersToLabels(ts.Labels).String()+" "+model.SampleValue(ts.Samples[0].Value).String())
+	}
+	sort.Strings(out)
+	return out
+}
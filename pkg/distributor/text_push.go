@@ -0,0 +1,211 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package distributor
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/middleware"
+	"github.com/grafana/dskit/tenant"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+	"github.com/grafana/mimir/pkg/util"
+	"github.com/grafana/mimir/pkg/util/log"
+	"github.com/grafana/mimir/pkg/util/validation"
+)
+
+// textLineParser parses a line of a text-based push format, like the InfluxDB line protocol,
+// and appends the resulting series to dst. If the line fails to be parsed, it returns the number
+// of samples of the line, which are discarded.
+type textLineParser func(line []byte, now time.Time, dst []mimirpb.PreallocTimeseries) ([]mimirpb.PreallocTimeseries, int, error)
+
+// textPushHandler is a http.Handler which accepts requests of a text-based push format, made of
+// one sample (or a few) per line. The lines failing to be parsed are discarded, and the request
+// is rejected only if none of its lines can be parsed. The discarded samples, rather than lines,
+// are tracked in the discarded samples counter.
+func textPushHandler(
+	maxRecvMsgSize int,
+	sourceIPs *middleware.SourceIPExtractor,
+	limits *validation.Overrides,
+	format string,
+	discardedDueToParseError *prometheus.CounterVec,
+	newLineParser func(r *http.Request) (textLineParser, error),
+	push PushFunc,
+) http.Handler {
+	return handler(maxRecvMsgSize, sourceIPs, false, limits, push, func(ctx context.Context, r *http.Request, maxRecvMsgSize int, _ []byte, req *mimirpb.PreallocWriteRequest) ([]byte, error) {
+		parseLine, err := newLineParser(r)
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := readTextPushBody(r, maxRecvMsgSize)
+		if err != nil {
+			return body, err
+		}
+
+		tenantID, err := tenant.TenantID(ctx)
+		if err != nil {
+			return body, err
+		}
+
+		var (
+			now       = time.Now()
+			failed    int
+			discarded int
+			firstErr  error
+		)
+
+		req.Timeseries = mimirpb.PreallocTimeseriesSliceFromPool()
+		for _, line := range bytes.Split(body, []byte{'\n'}) {
+			line = bytes.TrimSpace(line)
+			if len(line) == 0 || line[0] == '#' {
+				continue
+			}
+
+			var lineDiscarded int
+			if req.Timeseries, lineDiscarded, err = parseLine(line, now, req.Timeseries); err != nil {
+				failed++
+				discarded += lineDiscarded
+				if firstErr == nil {
+					firstErr = err
+				}
+			}
+		}
+
+		if failed > 0 {
+			discardedDueToParseError.WithLabelValues(tenantID, "").Add(float64(discarded)) // Group is empty here as the lines couldn't be parsed
+
+			if len(req.Timeseries) == 0 {
+				return body, httpgrpc.Errorf(http.StatusBadRequest, "the request has been rejected because none of its %d %s lines could be parsed, the first error is: %s", failed, format, firstErr)
+			}
+			level.Warn(log.WithContext(ctx, log.Logger)).Log("msg", "discarded lines which could not be parsed", "format", format, "lines", failed, "samples", discarded, "err", firstErr)
+		}
+
+		return body, nil
+	})
+}
+
+// readTextPushBody reads the request body, which can be gzip-compressed.
+func readTextPushBody(r *http.Request, maxRecvMsgSize int) ([]byte, error) {
+	if r.ContentLength > int64(maxRecvMsgSize) {
+		return nil, httpgrpc.Errorf(http.StatusRequestEntityTooLarge, distributorMaxWriteMessageSizeErr{actual: int(r.ContentLength), limit: maxRecvMsgSize}.Error())
+	}
+
+	reader := r.Body
+	switch contentEncoding := r.Header.Get("Content-Encoding"); contentEncoding {
+	case "gzip":
+		gr, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		reader = gr
+	case "":
+		// No compression.
+	default:
+		return nil, httpgrpc.Errorf(http.StatusUnsupportedMediaType, "unsupported compression: %s. Only \"gzip\" or no compression supported", contentEncoding)
+	}
+
+	// Protect against a large input.
+	body, err := io.ReadAll(http.MaxBytesReader(nil, reader, int64(maxRecvMsgSize)))
+	if err != nil {
+		if util.IsRequestBodyTooLarge(err) {
+			return nil, httpgrpc.Errorf(http.StatusRequestEntityTooLarge, distributorMaxWriteMessageSizeErr{actual: -1, limit: maxRecvMsgSize}.Error())
+		}
+		return nil, err
+	}
+	return body, r.Body.Close()
+}
+
+// newTextPushSeries returns a series with a single sample, with the input labels sorted by name.
+func newTextPushSeries(labels []mimirpb.LabelAdapter, value float64, timestampMs int64) mimirpb.PreallocTimeseries {
+	sort.Slice(labels, func(i, j int) bool { return labels[i].Name < labels[j].Name })
+
+	ts := mimirpb.TimeseriesFromPool()
+	ts.Labels = append(ts.Labels, labels...)
+	ts.Samples = append(ts.Samples, mimirpb.Sample{Value: value, TimestampMs: timestampMs})
+	return mimirpb.PreallocTimeseries{TimeSeries: ts}
+}
+
+// sanitizeMetricName replaces the characters not allowed in Prometheus metric names with underscores.
+func sanitizeMetricName(name string) string {
+	return sanitizeName(name, true)
+}
+
+// sanitizeLabelName replaces the characters not allowed in Prometheus label names with underscores.
+func sanitizeLabelName(name string) string {
+	return sanitizeName(name, false)
+}
+
+func sanitizeName(name string, allowColons bool) string {
+	if name == "" {
+		return name
+	}
+
+	valid := func(i int, r rune) bool {
+		return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || r == '_' || (allowColons && r == ':') || (r >= '0' && r <= '9' && i > 0)
+	}
+
+	sanitized := true
+	for i, r := range name {
+		if !valid(i, r) {
+			sanitized = false
+			break
+		}
+	}
+	if sanitized {
+		return name
+	}
+
+	var b strings.Builder
+	b.Grow(len(name) + 1)
+	for i, r := range name {
+		switch {
+		case valid(i, r):
+			b.WriteRune(r)
+		case i == 0 && r >= '0' && r <= '9':
+			// Names can't start with a digit, so we prefix them with an underscore.
+			b.WriteRune('_')
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+// noContentOnSuccess makes the handler respond with 204 No Content, rather than 200 OK, to successful requests,
+// for the clients which expect it.
+func noContentOnSuccess(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := &headerTrackingResponseWriter{ResponseWriter: w}
+		h.ServeHTTP(rw, r)
+		if !rw.written {
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+}
+
+type headerTrackingResponseWriter struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (w *headerTrackingResponseWriter) WriteHeader(statusCode int) {
+	w.written = true
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *headerTrackingResponseWriter) Write(b []byte) (int, error) {
+	w.written = true
+	return w.ResponseWriter.Write(b)
+}
@@ -8,13 +8,16 @@ package distributor
 import (
 	"errors"
 	"fmt"
+	"math"
 	"strings"
 	"time"
 	"unicode/utf8"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/common/model"
 
+	"github.com/grafana/mimir/pkg/costattribution"
 	"github.com/grafana/mimir/pkg/mimirpb"
 	"github.com/grafana/mimir/pkg/util/extract"
 	"github.com/grafana/mimir/pkg/util/globalerror"
@@ -29,15 +32,17 @@ const (
 
 var (
 	// Discarded series / samples reasons.
-	reasonMissingMetricName         = globalerror.MissingMetricName.LabelValue()
-	reasonInvalidMetricName         = globalerror.InvalidMetricName.LabelValue()
-	reasonMaxLabelNamesPerSeries    = globalerror.MaxLabelNamesPerSeries.LabelValue()
-	reasonInvalidLabel              = globalerror.SeriesInvalidLabel.LabelValue()
-	reasonLabelNameTooLong          = globalerror.SeriesLabelNameTooLong.LabelValue()
-	reasonLabelValueTooLong         = globalerror.SeriesLabelValueTooLong.LabelValue()
-	reasonMaxNativeHistogramBuckets = globalerror.MaxNativeHistogramBuckets.LabelValue()
-	reasonDuplicateLabelNames       = globalerror.SeriesWithDuplicateLabelNames.LabelValue()
-	reasonTooFarInFuture            = globalerror.SampleTooFarInFuture.LabelValue()
+	reasonMissingMetricName                   = globalerror.MissingMetricName.LabelValue()
+	reasonInvalidMetricName                   = globalerror.InvalidMetricName.LabelValue()
+	reasonMaxLabelNamesPerSeries              = globalerror.MaxLabelNamesPerSeries.LabelValue()
+	reasonInvalidLabel                        = globalerror.SeriesInvalidLabel.LabelValue()
+	reasonLabelNameTooLong                    = globalerror.SeriesLabelNameTooLong.LabelValue()
+	reasonLabelValueTooLong                   = globalerror.SeriesLabelValueTooLong.LabelValue()
+	reasonMaxNativeHistogramBuckets           = globalerror.MaxNativeHistogramBuckets.LabelValue()
+	reasonInvalidNativeHistogramSchema        = globalerror.InvalidNativeHistogramSchema.LabelValue()
+	reasonInvalidNativeHistogramCustomBuckets = globalerror.InvalidNativeHistogramCustomBuckets.LabelValue()
+	reasonDuplicateLabelNames                 = globalerror.SeriesWithDuplicateLabelNames.LabelValue()
+	reasonTooFarInFuture                      = globalerror.SampleTooFarInFuture.LabelValue()
 
 	// Discarded exemplars reasons.
 	reasonExemplarLabelsMissing    = globalerror.ExemplarLabelsMissing.LabelValue()
@@ -58,6 +63,10 @@ var (
 	// reasonTooManyHAClusters is one of the reasons for discarding samples.
 	reasonTooManyHAClusters = "too_many_ha_clusters"
 
+	// reasonMetricRateLimited is the reason for discarding the samples of series exceeding the
+	// rate limit of the series matching a selector.
+	reasonMetricRateLimited = globalerror.MetricIngestionRateLimited.LabelValue()
+
 	labelNameTooLongMsgFormat = globalerror.SeriesLabelNameTooLong.MessageWithPerTenantLimitConfig(
 		"received a series whose label name length exceeds the limit, label: '%.200s' series: '%.200s'",
 		validation.MaxLabelNameLengthFlag,
@@ -78,6 +87,12 @@ var (
 		"received a native histogram sample with too many buckets, timestamp: %%d series: %%s, buckets: %%d, limit: %%d (%s)",
 		globalerror.MaxNativeHistogramBuckets,
 	)
+	invalidNativeHistogramSchemaMsgFormat = globalerror.InvalidNativeHistogramSchema.Message(
+		fmt.Sprintf("received a native histogram sample with an unsupported schema, timestamp: %%d series: %%s, schema: %%d, supported schemas: [%d, %d] and %d for custom buckets", nativeHistogramMinSchema, nativeHistogramMaxSchema, mimirpb.CustomBucketsSchema),
+	)
+	invalidNativeHistogramCustomBucketsMsgFormat = globalerror.InvalidNativeHistogramCustomBuckets.Message(
+		"received a native histogram sample with invalid custom buckets, timestamp: %d series: %s, reason: %s",
+	)
 	sampleTimestampTooNewMsgFormat = globalerror.SampleTooFarInFuture.MessageWithPerTenantLimitConfig(
 		"received a sample whose timestamp is too far in the future, timestamp: %d series: '%.200s'",
 		validation.CreationGracePeriodFlag,
@@ -103,23 +118,35 @@ var (
 	)
 )
 
+const (
+	// nativeHistogramMinSchema and nativeHistogramMaxSchema are the lowest and highest exponential schemas supported by
+	// the TSDB. The only other supported schema is the one of the native histograms with custom buckets.
+	nativeHistogramMinSchema = -4
+	nativeHistogramMaxSchema = 8
+)
+
 // sampleValidationConfig helps with getting required config to validate sample.
 type sampleValidationConfig interface {
 	CreationGracePeriod(userID string) time.Duration
 	MaxNativeHistogramBuckets(userID string) int
+	ReduceNativeHistogramOverMaxBuckets(userID string) bool
 }
 
 // sampleValidationMetrics is a collection of metrics used during sample validation.
 type sampleValidationMetrics struct {
-	missingMetricName         *prometheus.CounterVec
-	invalidMetricName         *prometheus.CounterVec
-	maxLabelNamesPerSeries    *prometheus.CounterVec
-	invalidLabel              *prometheus.CounterVec
-	labelNameTooLong          *prometheus.CounterVec
-	labelValueTooLong         *prometheus.CounterVec
-	maxNativeHistogramBuckets *prometheus.CounterVec
-	duplicateLabelNames       *prometheus.CounterVec
-	tooFarInFuture            *prometheus.CounterVec
+	missingMetricName                   *prometheus.CounterVec
+	invalidMetricName                   *prometheus.CounterVec
+	maxLabelNamesPerSeries              *prometheus.CounterVec
+	invalidLabel                        *prometheus.CounterVec
+	labelNameTooLong                    *prometheus.CounterVec
+	labelValueTooLong                   *prometheus.CounterVec
+	maxNativeHistogramBuckets           *prometheus.CounterVec
+	invalidNativeHistogramSchema        *prometheus.CounterVec
+	invalidNativeHistogramCustomBuckets *prometheus.CounterVec
+	duplicateLabelNames                 *prometheus.CounterVec
+	tooFarInFuture                      *prometheus.CounterVec
+
+	reducedResolutionHistograms *prometheus.CounterVec
 }
 
 func (m *sampleValidationMetrics) deleteUserMetrics(userID string) {
@@ -131,8 +158,11 @@ func (m *sampleValidationMetrics) deleteUserMetrics(userID string) {
 	m.labelNameTooLong.DeletePartialMatch(filter)
 	m.labelValueTooLong.DeletePartialMatch(filter)
 	m.maxNativeHistogramBuckets.DeletePartialMatch(filter)
+	m.invalidNativeHistogramSchema.DeletePartialMatch(filter)
+	m.invalidNativeHistogramCustomBuckets.DeletePartialMatch(filter)
 	m.duplicateLabelNames.DeletePartialMatch(filter)
 	m.tooFarInFuture.DeletePartialMatch(filter)
+	m.reducedResolutionHistograms.DeleteLabelValues(userID)
 }
 
 func (m *sampleValidationMetrics) deleteUserMetricsForGroup(userID, group string) {
@@ -143,21 +173,30 @@ func (m *sampleValidationMetrics) deleteUserMetricsForGroup(userID, group string
 	m.labelNameTooLong.DeleteLabelValues(userID, group)
 	m.labelValueTooLong.DeleteLabelValues(userID, group)
 	m.maxNativeHistogramBuckets.DeleteLabelValues(userID, group)
+	m.invalidNativeHistogramSchema.DeleteLabelValues(userID, group)
+	m.invalidNativeHistogramCustomBuckets.DeleteLabelValues(userID, group)
 	m.duplicateLabelNames.DeleteLabelValues(userID, group)
 	m.tooFarInFuture.DeleteLabelValues(userID, group)
 }
 
 func newSampleValidationMetrics(r prometheus.Registerer) *sampleValidationMetrics {
 	return &sampleValidationMetrics{
-		missingMetricName:         validation.DiscardedSamplesCounter(r, reasonMissingMetricName),
-		invalidMetricName:         validation.DiscardedSamplesCounter(r, reasonInvalidMetricName),
-		maxLabelNamesPerSeries:    validation.DiscardedSamplesCounter(r, reasonMaxLabelNamesPerSeries),
-		invalidLabel:              validation.DiscardedSamplesCounter(r, reasonInvalidLabel),
-		labelNameTooLong:          validation.DiscardedSamplesCounter(r, reasonLabelNameTooLong),
-		labelValueTooLong:         validation.DiscardedSamplesCounter(r, reasonLabelValueTooLong),
-		maxNativeHistogramBuckets: validation.DiscardedSamplesCounter(r, reasonMaxNativeHistogramBuckets),
-		duplicateLabelNames:       validation.DiscardedSamplesCounter(r, reasonDuplicateLabelNames),
-		tooFarInFuture:            validation.DiscardedSamplesCounter(r, reasonTooFarInFuture),
+		missingMetricName:                   validation.DiscardedSamplesCounter(r, reasonMissingMetricName),
+		invalidMetricName:                   validation.DiscardedSamplesCounter(r, reasonInvalidMetricName),
+		maxLabelNamesPerSeries:              validation.DiscardedSamplesCounter(r, reasonMaxLabelNamesPerSeries),
+		invalidLabel:                        validation.DiscardedSamplesCounter(r, reasonInvalidLabel),
+		labelNameTooLong:                    validation.DiscardedSamplesCounter(r, reasonLabelNameTooLong),
+		labelValueTooLong:                   validation.DiscardedSamplesCounter(r, reasonLabelValueTooLong),
+		maxNativeHistogramBuckets:           validation.DiscardedSamplesCounter(r, reasonMaxNativeHistogramBuckets),
+		invalidNativeHistogramSchema:        validation.DiscardedSamplesCounter(r, reasonInvalidNativeHistogramSchema),
+		invalidNativeHistogramCustomBuckets: validation.DiscardedSamplesCounter(r, reasonInvalidNativeHistogramCustomBuckets),
+		duplicateLabelNames:                 validation.DiscardedSamplesCounter(r, reasonDuplicateLabelNames),
+		tooFarInFuture:                      validation.DiscardedSamplesCounter(r, reasonTooFarInFuture),
+
+		reducedResolutionHistograms: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_distributor_reduced_resolution_histogram_samples_total",
+			Help: "The total number of native histogram samples whose resolution was reduced to fit the maximum number of buckets.",
+		}, []string{"user"}),
 	}
 }
 
@@ -194,9 +233,10 @@ func newExemplarValidationMetrics(r prometheus.Registerer) *exemplarValidationMe
 // validateSample returns an err if the sample is invalid.
 // The returned error may retain the provided series labels.
 // It uses the passed 'now' time to measure the relative time of the sample.
-func validateSample(m *sampleValidationMetrics, now model.Time, cfg sampleValidationConfig, userID, group string, ls []mimirpb.LabelAdapter, s mimirpb.Sample) error {
+func validateSample(m *sampleValidationMetrics, now model.Time, cfg sampleValidationConfig, userID, group string, cat *costattribution.Tracker, ls []mimirpb.LabelAdapter, s mimirpb.Sample) error {
 	if model.Time(s.TimestampMs) > now.Add(cfg.CreationGracePeriod(userID)) {
 		m.tooFarInFuture.WithLabelValues(userID, group).Inc()
+		cat.IncrementDiscardedSamples(mimirpb.FromLabelAdaptersToLabels(ls), 1, reasonTooFarInFuture, now.Time())
 		unsafeMetricName, _ := extract.UnsafeMetricNameFromLabelAdapters(ls)
 		return fmt.Errorf(sampleTimestampTooNewMsgFormat, s.TimestampMs, unsafeMetricName)
 	}
@@ -207,29 +247,93 @@ func validateSample(m *sampleValidationMetrics, now model.Time, cfg sampleValida
 // validateSampleHistogram returns an err if the sample is invalid.
 // The returned error may retain the provided series labels.
 // It uses the passed 'now' time to measure the relative time of the sample.
-func validateSampleHistogram(m *sampleValidationMetrics, now model.Time, cfg sampleValidationConfig, userID, group string, ls []mimirpb.LabelAdapter, s mimirpb.Histogram) error {
+// If enabled for the tenant, the resolution of a sample exceeding the maximum
+// number of buckets is reduced in place until it fits the limit.
+func validateSampleHistogram(m *sampleValidationMetrics, now model.Time, cfg sampleValidationConfig, userID, group string, cat *costattribution.Tracker, ls []mimirpb.LabelAdapter, s *mimirpb.Histogram) error {
 	if model.Time(s.Timestamp) > now.Add(cfg.CreationGracePeriod(userID)) {
 		m.tooFarInFuture.WithLabelValues(userID, group).Inc()
+		cat.IncrementDiscardedSamples(mimirpb.FromLabelAdaptersToLabels(ls), 1, reasonTooFarInFuture, now.Time())
 		unsafeMetricName, _ := extract.UnsafeMetricNameFromLabelAdapters(ls)
 		return fmt.Errorf(sampleTimestampTooNewMsgFormat, s.Timestamp, unsafeMetricName)
 	}
 
-	if bucketLimit := cfg.MaxNativeHistogramBuckets(userID); bucketLimit > 0 {
-		var bucketCount int
-		if s.IsFloatHistogram() {
-			bucketCount = len(s.GetNegativeCounts()) + len(s.GetPositiveCounts())
-		} else {
-			bucketCount = len(s.GetNegativeDeltas()) + len(s.GetPositiveDeltas())
+	if s.UsesCustomBuckets() {
+		if reason := nativeHistogramCustomBucketsError(s); reason != "" {
+			m.invalidNativeHistogramCustomBuckets.WithLabelValues(userID, group).Inc()
+			cat.IncrementDiscardedSamples(mimirpb.FromLabelAdaptersToLabels(ls), 1, reasonInvalidNativeHistogramCustomBuckets, now.Time())
+			return fmt.Errorf(invalidNativeHistogramCustomBucketsMsgFormat, s.Timestamp, mimirpb.FromLabelAdaptersToLabels(ls).String(), reason)
 		}
-		if bucketCount > bucketLimit {
-			m.maxNativeHistogramBuckets.WithLabelValues(userID, group).Inc()
-			return fmt.Errorf(maxNativeHistogramBucketsMsgFormat, s.Timestamp, mimirpb.FromLabelAdaptersToLabels(ls).String(), bucketCount, bucketLimit)
+	} else if len(s.CustomValues) > 0 {
+		m.invalidNativeHistogramCustomBuckets.WithLabelValues(userID, group).Inc()
+		cat.IncrementDiscardedSamples(mimirpb.FromLabelAdaptersToLabels(ls), 1, reasonInvalidNativeHistogramCustomBuckets, now.Time())
+		return fmt.Errorf(invalidNativeHistogramCustomBucketsMsgFormat, s.Timestamp, mimirpb.FromLabelAdaptersToLabels(ls).String(), fmt.Sprintf("custom values with exponential schema %d", s.Schema))
+	} else if s.Schema < nativeHistogramMinSchema || s.Schema > nativeHistogramMaxSchema {
+		m.invalidNativeHistogramSchema.WithLabelValues(userID, group).Inc()
+		cat.IncrementDiscardedSamples(mimirpb.FromLabelAdaptersToLabels(ls), 1, reasonInvalidNativeHistogramSchema, now.Time())
+		return fmt.Errorf(invalidNativeHistogramSchemaMsgFormat, s.Timestamp, mimirpb.FromLabelAdaptersToLabels(ls).String(), s.Schema)
+	}
+
+	if bucketLimit := cfg.MaxNativeHistogramBuckets(userID); bucketLimit > 0 {
+		if bucketCount := s.BucketCount(); bucketCount > bucketLimit {
+			if !cfg.ReduceNativeHistogramOverMaxBuckets(userID) {
+				m.maxNativeHistogramBuckets.WithLabelValues(userID, group).Inc()
+				cat.IncrementDiscardedSamples(mimirpb.FromLabelAdaptersToLabels(ls), 1, reasonMaxNativeHistogramBuckets, now.Time())
+				return fmt.Errorf(maxNativeHistogramBucketsMsgFormat, s.Timestamp, mimirpb.FromLabelAdaptersToLabels(ls).String(), bucketCount, bucketLimit)
+			}
+
+			// Reduce a copy, so that the sample is left untouched if it can't fit the limit even at the lowest schema.
+			// The resolution of the native histograms with custom buckets can't be reduced.
+			reduced := *s
+			for bucketCount > bucketLimit {
+				var err error
+				if bucketCount, err = reduced.ReduceResolution(); err != nil {
+					m.maxNativeHistogramBuckets.WithLabelValues(userID, group).Inc()
+					cat.IncrementDiscardedSamples(mimirpb.FromLabelAdaptersToLabels(ls), 1, reasonMaxNativeHistogramBuckets, now.Time())
+					return fmt.Errorf(maxNativeHistogramBucketsMsgFormat, s.Timestamp, mimirpb.FromLabelAdaptersToLabels(ls).String(), s.BucketCount(), bucketLimit)
+				}
+			}
+			*s = reduced
+			m.reducedResolutionHistograms.WithLabelValues(userID).Inc()
 		}
 	}
 
 	return nil
 }
 
+// nativeHistogramCustomBucketsError returns why the custom buckets of the native histogram are invalid, or an empty
+// string if they're valid. The custom values must be finite and strictly increasing, and the histogram can only have
+// positive buckets, up to the one above the last custom value.
+func nativeHistogramCustomBucketsError(h *mimirpb.Histogram) string {
+	if len(h.CustomValues) == 0 {
+		return "no custom values"
+	}
+	for i, v := range h.CustomValues {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Sprintf("custom value %g is not finite", v)
+		}
+		if i > 0 && v <= h.CustomValues[i-1] {
+			return fmt.Sprintf("custom values are not strictly increasing at %g", v)
+		}
+	}
+	if len(h.NegativeSpans) > 0 || len(h.NegativeDeltas) > 0 || len(h.NegativeCounts) > 0 {
+		return "negative buckets"
+	}
+
+	// The bucket index is the index of its upper bound in the custom values, and the last bucket is the +Inf one.
+	numBuckets := int64(len(h.CustomValues)) + 1
+	next := int64(0)
+	for _, span := range h.PositiveSpans {
+		if span.Offset < 0 {
+			return fmt.Sprintf("negative span offset %d", span.Offset)
+		}
+		next += int64(span.Offset) + int64(span.Length)
+	}
+	if next > numBuckets {
+		return fmt.Sprintf("bucket index %d out of the %d custom buckets", next-1, numBuckets)
+	}
+	return ""
+}
+
 // validateExemplar returns an error if the exemplar is invalid.
 // The returned error may retain the provided series labels.
 func validateExemplar(m *exemplarValidationMetrics, userID string, ls []mimirpb.LabelAdapter, e mimirpb.Exemplar) error {
@@ -296,21 +400,24 @@ type labelValidationConfig interface {
 
 // validateLabels returns an err if the labels are invalid.
 // The returned error may retain the provided series labels.
-func validateLabels(m *sampleValidationMetrics, cfg labelValidationConfig, userID, group string, ls []mimirpb.LabelAdapter, skipLabelNameValidation bool) error {
+func validateLabels(m *sampleValidationMetrics, cfg labelValidationConfig, userID, group string, cat *costattribution.Tracker, ls []mimirpb.LabelAdapter, skipLabelNameValidation bool) error {
 	unsafeMetricName, err := extract.UnsafeMetricNameFromLabelAdapters(ls)
 	if err != nil {
 		m.missingMetricName.WithLabelValues(userID, group).Inc()
+		cat.IncrementDiscardedSamples(mimirpb.FromLabelAdaptersToLabels(ls), 1, reasonMissingMetricName, time.Now())
 		return errors.New(noMetricNameMsgFormat)
 	}
 
 	if !model.IsValidMetricName(model.LabelValue(unsafeMetricName)) {
 		m.invalidMetricName.WithLabelValues(userID, group).Inc()
+		cat.IncrementDiscardedSamples(mimirpb.FromLabelAdaptersToLabels(ls), 1, reasonInvalidMetricName, time.Now())
 		return fmt.Errorf(invalidMetricNameMsgFormat, unsafeMetricName)
 	}
 
 	numLabelNames := len(ls)
 	if numLabelNames > cfg.MaxLabelNamesPerSeries(userID) {
 		m.maxLabelNamesPerSeries.WithLabelValues(userID, group).Inc()
+		cat.IncrementDiscardedSamples(mimirpb.FromLabelAdaptersToLabels(ls), 1, reasonMaxLabelNamesPerSeries, time.Now())
 		metric, ellipsis := getMetricAndEllipsis(ls)
 		return fmt.Errorf(tooManyLabelsMsgFormat, len(ls), cfg.MaxLabelNamesPerSeries(userID), metric, ellipsis)
 	}
@@ -321,15 +428,19 @@ func validateLabels(m *sampleValidationMetrics, cfg labelValidationConfig, userI
 	for _, l := range ls {
 		if !skipLabelNameValidation && !model.LabelName(l.Name).IsValid() {
 			m.invalidLabel.WithLabelValues(userID, group).Inc()
+			cat.IncrementDiscardedSamples(mimirpb.FromLabelAdaptersToLabels(ls), 1, reasonInvalidLabel, time.Now())
 			return fmt.Errorf(invalidLabelMsgFormat, l.Name, formatLabelSet(ls))
 		} else if len(l.Name) > maxLabelNameLength {
 			m.labelNameTooLong.WithLabelValues(userID, group).Inc()
+			cat.IncrementDiscardedSamples(mimirpb.FromLabelAdaptersToLabels(ls), 1, reasonLabelNameTooLong, time.Now())
 			return fmt.Errorf(labelNameTooLongMsgFormat, l.Name, formatLabelSet(ls))
 		} else if len(l.Value) > maxLabelValueLength {
 			m.labelValueTooLong.WithLabelValues(userID, group).Inc()
+			cat.IncrementDiscardedSamples(mimirpb.FromLabelAdaptersToLabels(ls), 1, reasonLabelValueTooLong, time.Now())
 			return fmt.Errorf(labelValueTooLongMsgFormat, l.Value, formatLabelSet(ls))
 		} else if lastLabelName == l.Name {
 			m.duplicateLabelNames.WithLabelValues(userID, group).Inc()
+			cat.IncrementDiscardedSamples(mimirpb.FromLabelAdaptersToLabels(ls), 1, reasonDuplicateLabelNames, time.Now())
 			return fmt.Errorf(duplicateLabelMsgFormat, l.Name, formatLabelSet(ls))
 		}
 
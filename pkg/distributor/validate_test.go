@@ -8,6 +8,7 @@ package distributor
 import (
 	"errors"
 	"fmt"
+	"math"
 	"strings"
 	"testing"
 	"time"
@@ -16,10 +17,12 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/histogram"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/mimir/pkg/mimirpb"
+	"github.com/grafana/mimir/pkg/util/test"
 	"github.com/grafana/mimir/pkg/util/validation"
 )
 
@@ -148,7 +151,7 @@ func TestValidateLabels(t *testing.T) {
 			nil,
 		},
 	} {
-		err := validateLabels(s, cfg, userID, "custom label", mimirpb.FromMetricsToLabelAdapters(c.metric), c.skipLabelNameValidation)
+		err := validateLabels(s, cfg, userID, "custom label", nil, mimirpb.FromMetricsToLabelAdapters(c.metric), c.skipLabelNameValidation)
 		assert.Equal(t, c.err, err, "wrong error")
 	}
 
@@ -348,7 +351,7 @@ func TestValidateLabelDuplication(t *testing.T) {
 
 	userID := "testUser"
 
-	actual := validateLabels(newSampleValidationMetrics(nil), cfg, userID, "", []mimirpb.LabelAdapter{
+	actual := validateLabels(newSampleValidationMetrics(nil), cfg, userID, "", nil, []mimirpb.LabelAdapter{
 		{Name: model.MetricNameLabel, Value: "a"},
 		{Name: model.MetricNameLabel, Value: "b"},
 	}, false)
@@ -364,7 +367,7 @@ func TestValidateLabelDuplication(t *testing.T) {
 	)
 	assert.Equal(t, expected, actual)
 
-	actual = validateLabels(newSampleValidationMetrics(nil), cfg, userID, "", []mimirpb.LabelAdapter{
+	actual = validateLabels(newSampleValidationMetrics(nil), cfg, userID, "", nil, []mimirpb.LabelAdapter{
 		{Name: model.MetricNameLabel, Value: "a"},
 		{Name: "a", Value: "a"},
 		{Name: "a", Value: "a"},
@@ -384,7 +387,8 @@ func TestValidateLabelDuplication(t *testing.T) {
 }
 
 type sampleValidationCfg struct {
-	maxNativeHistogramBuckets int
+	maxNativeHistogramBuckets           int
+	reduceNativeHistogramOverMaxBuckets bool
 }
 
 func (c sampleValidationCfg) CreationGracePeriod(_ string) time.Duration {
@@ -395,6 +399,10 @@ func (c sampleValidationCfg) MaxNativeHistogramBuckets(_ string) int {
 	return c.maxNativeHistogramBuckets
 }
 
+func (c sampleValidationCfg) ReduceNativeHistogramOverMaxBuckets(_ string) bool {
+	return c.reduceNativeHistogramOverMaxBuckets
+}
+
 func TestMaxNativeHistorgramBuckets(t *testing.T) {
 	// All will have 2 buckets, one negative and one positive
 	testCases := map[string]mimirpb.Histogram{
@@ -513,9 +521,9 @@ func TestMaxNativeHistorgramBuckets(t *testing.T) {
 				var cfg sampleValidationCfg
 				cfg.maxNativeHistogramBuckets = limit
 
-				err := validateSampleHistogram(metrics, model.Now(), cfg, "user-1", "group-1", []mimirpb.LabelAdapter{
+				err := validateSampleHistogram(metrics, model.Now(), cfg, "user-1", "group-1", nil, []mimirpb.LabelAdapter{
 					{Name: model.MetricNameLabel, Value: "a"},
-					{Name: "a", Value: "a"}}, h)
+					{Name: "a", Value: "a"}}, &h)
 
 				if limit == 1 {
 					require.Error(t, err)
@@ -533,6 +541,222 @@ func TestMaxNativeHistorgramBuckets(t *testing.T) {
 	`), "cortex_discarded_samples_total"))
 }
 
+func TestReduceNativeHistogramOverMaxBuckets(t *testing.T) {
+	testCases := map[string]struct {
+		histogram            *histogram.Histogram
+		bucketLimit          int
+		expectedSchema       int32
+		expectedBucketCount  int
+		expectedError        bool
+		expectedReducedCount int
+	}{
+		"histogram within the limit is left untouched": {
+			histogram:           test.GenerateTestHistogram(0),
+			bucketLimit:         8,
+			expectedSchema:      1,
+			expectedBucketCount: 8,
+		},
+		"histogram exceeding the limit is reduced once": {
+			histogram:            test.GenerateTestHistogram(0),
+			bucketLimit:          7,
+			expectedSchema:       0,
+			expectedBucketCount:  6,
+			expectedReducedCount: 1,
+		},
+		"histogram exceeding the limit is reduced until it fits": {
+			histogram:            test.GenerateTestHistogram(0),
+			bucketLimit:          4,
+			expectedSchema:       -1,
+			expectedBucketCount:  4,
+			expectedReducedCount: 1,
+		},
+		"histogram not fitting the limit at the lowest schema is rejected": {
+			histogram:     test.GenerateTestHistogram(0),
+			bucketLimit:   1,
+			expectedError: true,
+		},
+	}
+
+	labels := []mimirpb.LabelAdapter{{Name: model.MetricNameLabel, Value: "a"}}
+	for testName, testCase := range testCases {
+		for _, float := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s float=%v", testName, float), func(t *testing.T) {
+				registry := prometheus.NewRegistry()
+				metrics := newSampleValidationMetrics(registry)
+				cfg := sampleValidationCfg{maxNativeHistogramBuckets: testCase.bucketLimit, reduceNativeHistogramOverMaxBuckets: true}
+
+				var hist mimirpb.Histogram
+				if float {
+					hist = mimirpb.FromFloatHistogramToHistogramProto(1000, testCase.histogram.ToFloat())
+				} else {
+					hist = mimirpb.FromHistogramToHistogramProto(1000, testCase.histogram)
+				}
+				original := hist
+
+				err := validateSampleHistogram(metrics, model.Now(), cfg, "user-1", "group-1", nil, labels, &hist)
+				if testCase.expectedError {
+					require.Error(t, err)
+					require.Equal(t, original, hist)
+					return
+				}
+				require.NoError(t, err)
+				require.Equal(t, testCase.expectedSchema, hist.Schema)
+				require.Equal(t, testCase.expectedBucketCount, hist.BucketCount())
+
+				// The reduced histogram must have the same buckets as the original one converted to the lower schema.
+				var expected, actual *histogram.FloatHistogram
+				if float {
+					expected = mimirpb.FromFloatHistogramProtoToFloatHistogram(&original)
+					actual = mimirpb.FromFloatHistogramProtoToFloatHistogram(&hist)
+				} else {
+					expected = mimirpb.FromHistogramProtoToFloatHistogram(&original)
+					actual = mimirpb.FromHistogramProtoToFloatHistogram(&hist)
+				}
+				require.Equal(t, expected.CopyToSchema(testCase.expectedSchema).String(), actual.String())
+
+				expectedMetrics := ""
+				if testCase.expectedReducedCount > 0 {
+					expectedMetrics = fmt.Sprintf(`
+						# HELP cortex_distributor_reduced_resolution_histogram_samples_total The total number of native histogram samples whose resolution was reduced to fit the maximum number of buckets.
+						# TYPE cortex_distributor_reduced_resolution_histogram_samples_total counter
+						cortex_distributor_reduced_resolution_histogram_samples_total{user="user-1"} %d
+					`, testCase.expectedReducedCount)
+				}
+				require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expectedMetrics), "cortex_distributor_reduced_resolution_histogram_samples_total"))
+			})
+		}
+	}
+}
+
+func TestInvalidNativeHistogramSchema(t *testing.T) {
+	testCases := map[string]struct {
+		schema        int32
+		expectedError error
+	}{
+		"a valid schema causes no error": {
+			schema:        3,
+			expectedError: nil,
+		},
+		"the lowest exponential schema causes no error": {
+			schema:        -4,
+			expectedError: nil,
+		},
+		"the highest exponential schema causes no error": {
+			schema:        8,
+			expectedError: nil,
+		},
+		"a schema lower than the lowest exponential schema causes an error": {
+			schema:        -5,
+			expectedError: fmt.Errorf(invalidNativeHistogramSchemaMsgFormat, 1000, `{__name__="a"}`, -5),
+		},
+		"a schema higher than the highest exponential schema causes an error": {
+			schema:        9,
+			expectedError: fmt.Errorf(invalidNativeHistogramSchemaMsgFormat, 1000, `{__name__="a"}`, 9),
+		},
+	}
+
+	registry := prometheus.NewRegistry()
+	metrics := newSampleValidationMetrics(registry)
+	labels := []mimirpb.LabelAdapter{{Name: model.MetricNameLabel, Value: "a"}}
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			hist := mimirpb.FromHistogramToHistogramProto(1000, test.GenerateTestHistogram(0))
+			hist.Schema = testCase.schema
+			err := validateSampleHistogram(metrics, model.Now(), sampleValidationCfg{}, "user-1", "group-1", nil, labels, &hist)
+			require.Equal(t, testCase.expectedError, err)
+		})
+	}
+
+	require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+			# HELP cortex_discarded_samples_total The total number of samples that were discarded.
+			# TYPE cortex_discarded_samples_total counter
+			cortex_discarded_samples_total{group="group-1",reason="invalid_native_histogram_schema",user="user-1"} 2
+	`), "cortex_discarded_samples_total"))
+}
+
+func TestInvalidNativeHistogramCustomBuckets(t *testing.T) {
+	nhcb := func(customValues []float64, spans ...mimirpb.BucketSpan) mimirpb.Histogram {
+		var deltas []int64
+		for _, s := range spans {
+			for i := uint32(0); i < s.Length; i++ {
+				deltas = append(deltas, 1)
+			}
+		}
+		return mimirpb.Histogram{
+			Count:          &mimirpb.Histogram_CountInt{CountInt: 10},
+			Sum:            20,
+			Schema:         mimirpb.CustomBucketsSchema,
+			ZeroCount:      &mimirpb.Histogram_ZeroCountInt{},
+			PositiveSpans:  spans,
+			PositiveDeltas: deltas,
+			CustomValues:   customValues,
+			Timestamp:      1000,
+		}
+	}
+
+	testCases := map[string]struct {
+		hist          mimirpb.Histogram
+		bucketLimit   int
+		expectedError error
+	}{
+		"valid custom buckets cause no error": {
+			hist: nhcb([]float64{1, 2, 5}, mimirpb.BucketSpan{Offset: 0, Length: 2}, mimirpb.BucketSpan{Offset: 1, Length: 1}),
+		},
+		"custom buckets within the bucket limit cause no error": {
+			hist:        nhcb([]float64{1, 2, 5}, mimirpb.BucketSpan{Offset: 0, Length: 4}),
+			bucketLimit: 4,
+		},
+		"custom buckets over the bucket limit cause an error": {
+			hist:          nhcb([]float64{1, 2, 5}, mimirpb.BucketSpan{Offset: 0, Length: 4}),
+			bucketLimit:   3,
+			expectedError: fmt.Errorf(maxNativeHistogramBucketsMsgFormat, 1000, `{__name__="a"}`, 4, 3),
+		},
+		"missing custom values cause an error": {
+			hist:          nhcb(nil, mimirpb.BucketSpan{Offset: 0, Length: 1}),
+			expectedError: fmt.Errorf(invalidNativeHistogramCustomBucketsMsgFormat, 1000, `{__name__="a"}`, "no custom values"),
+		},
+		"custom values that aren't increasing cause an error": {
+			hist:          nhcb([]float64{1, 1}, mimirpb.BucketSpan{Offset: 0, Length: 1}),
+			expectedError: fmt.Errorf(invalidNativeHistogramCustomBucketsMsgFormat, 1000, `{__name__="a"}`, "custom values are not strictly increasing at 1"),
+		},
+		"an infinite custom value causes an error": {
+			hist:          nhcb([]float64{1, math.Inf(1)}, mimirpb.BucketSpan{Offset: 0, Length: 1}),
+			expectedError: fmt.Errorf(invalidNativeHistogramCustomBucketsMsgFormat, 1000, `{__name__="a"}`, "custom value +Inf is not finite"),
+		},
+		"a bucket above the +Inf one causes an error": {
+			hist:          nhcb([]float64{1, 2}, mimirpb.BucketSpan{Offset: 1, Length: 3}),
+			expectedError: fmt.Errorf(invalidNativeHistogramCustomBucketsMsgFormat, 1000, `{__name__="a"}`, "bucket index 3 out of the 3 custom buckets"),
+		},
+		"custom values with an exponential schema cause an error": {
+			hist: func() mimirpb.Histogram {
+				h := mimirpb.FromHistogramToHistogramProto(1000, test.GenerateTestHistogram(0))
+				h.CustomValues = []float64{1, 2}
+				return h
+			}(),
+			expectedError: fmt.Errorf(invalidNativeHistogramCustomBucketsMsgFormat, 1000, `{__name__="a"}`, "custom values with exponential schema 1"),
+		},
+	}
+
+	registry := prometheus.NewRegistry()
+	metrics := newSampleValidationMetrics(registry)
+	labels := []mimirpb.LabelAdapter{{Name: model.MetricNameLabel, Value: "a"}}
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			hist := testCase.hist
+			cfg := sampleValidationCfg{maxNativeHistogramBuckets: testCase.bucketLimit, reduceNativeHistogramOverMaxBuckets: true}
+			err := validateSampleHistogram(metrics, model.Now(), cfg, "user-1", "group-1", nil, labels, &hist)
+			require.Equal(t, testCase.expectedError, err)
+		})
+	}
+
+	require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+			# HELP cortex_discarded_samples_total The total number of samples that were discarded.
+			# TYPE cortex_discarded_samples_total counter
+			cortex_discarded_samples_total{group="group-1",reason="invalid_native_histogram_custom_buckets",user="user-1"} 5
+			cortex_discarded_samples_total{group="group-1",reason="max_native_histogram_buckets",user="user-1"} 1
+	`), "cortex_discarded_samples_total"))
+}
+
 func tooManyLabelsArgs(series []mimirpb.LabelAdapter, limit int) []any {
 	metric := mimirpb.FromLabelAdaptersToMetric(series).String()
 	ellipsis := ""
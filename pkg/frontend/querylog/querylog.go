@@ -0,0 +1,258 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package querylog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/services"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/thanos-io/objstore"
+	"go.uber.org/atomic"
+
+	"github.com/grafana/mimir/pkg/storage/bucket"
+)
+
+const (
+	// DirName is the name of the directory, in the tenant's bucket prefix, where query logs are uploaded to.
+	DirName = "query-log"
+
+	flushReasonInterval = "interval"
+	flushReasonFull     = "full"
+	flushReasonShutdown = "shutdown"
+)
+
+var errInvalidMaxBatchSize = errors.New("the query log max batch size must be greater than 0")
+
+// Config holds the configuration of the query log.
+type Config struct {
+	Enabled       bool          `yaml:"enabled" category:"experimental"`
+	FlushInterval time.Duration `yaml:"flush_interval" category:"experimental"`
+	MaxBatchSize  int           `yaml:"max_batch_size" category:"experimental"`
+}
+
+func (cfg *Config) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, prefix+"enabled", false, "True to upload a log of the queries received by the query-frontend, including their statistics, to the tenant's prefix of the blocks storage bucket. Queries are uploaded in batches, as gzip-compressed newline-delimited JSON files in the "+DirName+" directory.")
+	f.DurationVar(&cfg.FlushInterval, prefix+"flush-interval", time.Minute, "How frequently the buffered query log entries of each tenant are uploaded to the bucket.")
+	f.IntVar(&cfg.MaxBatchSize, prefix+"max-batch-size", 1000, "Maximum number of query log entries of a tenant uploaded in a single file. The entries are uploaded as soon as the batch is full. Entries received while twice this number of entries is buffered for a tenant are discarded.")
+}
+
+func (cfg *Config) Validate() error {
+	if cfg.Enabled && cfg.MaxBatchSize <= 0 {
+		return errInvalidMaxBatchSize
+	}
+	return nil
+}
+
+// Entry is a query log entry.
+type Entry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Tenant    string    `json:"tenant"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+
+	Query string `json:"query,omitempty"`
+	Start string `json:"start,omitempty"`
+	End   string `json:"end,omitempty"`
+	Step  string `json:"step,omitempty"`
+	Time  string `json:"time,omitempty"`
+
+	Status            string  `json:"status"`
+	StatusCode        int     `json:"status_code,omitempty"`
+	Error             string  `json:"error,omitempty"`
+	ResponseTime      float64 `json:"response_time_seconds"`
+	ResponseSizeBytes int64   `json:"response_size_bytes"`
+
+	WallTime             float64 `json:"query_wall_time_seconds"`
+	FetchedSeriesCount   uint64  `json:"fetched_series_count"`
+	FetchedChunkBytes    uint64  `json:"fetched_chunk_bytes"`
+	FetchedChunksCount   uint64  `json:"fetched_chunks_count"`
+	FetchedIndexBytes    uint64  `json:"fetched_index_bytes"`
+	ShardedQueries       uint32  `json:"sharded_queries"`
+	SplitQueries         uint32  `json:"split_queries"`
+	EstimatedSeriesCount uint64  `json:"estimated_series_count"`
+	QueueTime            float64 `json:"queue_time_seconds"`
+}
+
+// Uploader buffers the query log entries of each tenant and periodically uploads them
+// to the tenant's prefix of the bucket.
+type Uploader struct {
+	services.Service
+
+	cfg         Config
+	bkt         objstore.Bucket
+	cfgProvider bucket.TenantConfigProvider
+	instanceID  string
+	logger      log.Logger
+
+	// Used to make the names of the objects uploaded by this instance unique.
+	lastSeq atomic.Uint64
+
+	mtx     sync.Mutex
+	entries map[string][]Entry
+
+	// Tenants whose batch is full, and must be uploaded right away.
+	fullCh chan string
+
+	appendedEntries  prometheus.Counter
+	discardedEntries *prometheus.CounterVec
+	uploads          *prometheus.CounterVec
+	uploadFailures   prometheus.Counter
+}
+
+// NewUploader makes a new Uploader. The cfgProvider can be nil.
+func NewUploader(cfg Config, bkt objstore.Bucket, cfgProvider bucket.TenantConfigProvider, logger log.Logger, reg prometheus.Registerer) *Uploader {
+	instanceID, err := os.Hostname()
+	if err != nil {
+		instanceID = "query-frontend"
+	}
+
+	u := &Uploader{
+		cfg:         cfg,
+		bkt:         bkt,
+		cfgProvider: cfgProvider,
+		instanceID:  instanceID,
+		logger:      logger,
+		entries:     map[string][]Entry{},
+		fullCh:      make(chan string, 16),
+
+		appendedEntries: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_query_frontend_query_log_entries_total",
+			Help: "Total number of query log entries appended to the query log.",
+		}),
+		discardedEntries: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_query_frontend_query_log_entries_discarded_total",
+			Help: "Total number of query log entries discarded because too many entries were buffered or the upload failed.",
+		}, []string{"reason"}),
+		uploads: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_query_frontend_query_log_uploads_total",
+			Help: "Total number of query log files uploaded to the bucket.",
+		}, []string{"reason"}),
+		uploadFailures: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_query_frontend_query_log_upload_failures_total",
+			Help: "Total number of query log files which failed to be uploaded to the bucket.",
+		}),
+	}
+
+	u.Service = services.NewBasicService(nil, u.running, u.stopping)
+	return u
+}
+
+// Append adds the entry to the query log of the entry's tenant.
+func (u *Uploader) Append(entry Entry) {
+	u.appendedEntries.Inc()
+
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+
+	buffered := len(u.entries[entry.Tenant])
+	if buffered >= 2*u.cfg.MaxBatchSize {
+		// The uploads can't keep up, so we discard the entry to bound memory utilization.
+		u.discardedEntries.WithLabelValues("too-many-buffered").Inc()
+		return
+	}
+
+	u.entries[entry.Tenant] = append(u.entries[entry.Tenant], entry)
+
+	if buffered+1 == u.cfg.MaxBatchSize {
+		select {
+		case u.fullCh <- entry.Tenant:
+		default:
+			// The tenant will be uploaded at the next interval anyway.
+		}
+	}
+}
+
+func (u *Uploader) running(ctx context.Context) error {
+	ticker := time.NewTicker(u.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			u.flushAll(ctx, flushReasonInterval)
+		case tenantID := <-u.fullCh:
+			u.flush(ctx, tenantID, flushReasonFull)
+		}
+	}
+}
+
+func (u *Uploader) stopping(_ error) error {
+	u.flushAll(context.Background(), flushReasonShutdown)
+	return nil
+}
+
+func (u *Uploader) flushAll(ctx context.Context, reason string) {
+	u.mtx.Lock()
+	tenantIDs := make([]string, 0, len(u.entries))
+	for tenantID := range u.entries {
+		tenantIDs = append(tenantIDs, tenantID)
+	}
+	u.mtx.Unlock()
+
+	for _, tenantID := range tenantIDs {
+		u.flush(ctx, tenantID, reason)
+	}
+}
+
+// flush uploads the buffered entries of the tenant, in batches of at most MaxBatchSize entries.
+func (u *Uploader) flush(ctx context.Context, tenantID, reason string) {
+	u.mtx.Lock()
+	entries := u.entries[tenantID]
+	delete(u.entries, tenantID)
+	u.mtx.Unlock()
+
+	for len(entries) > 0 {
+		batch := entries[:min(len(entries), u.cfg.MaxBatchSize)]
+		entries = entries[len(batch):]
+
+		if err := u.upload(ctx, tenantID, batch); err != nil {
+			level.Warn(u.logger).Log("msg", "failed to upload query log", "user", tenantID, "entries", len(batch), "err", err)
+			u.uploadFailures.Inc()
+			u.discardedEntries.WithLabelValues("upload-failed").Add(float64(len(batch)))
+			continue
+		}
+
+		u.uploads.WithLabelValues(reason).Inc()
+	}
+}
+
+func (u *Uploader) upload(ctx context.Context, tenantID string, entries []Entry) error {
+	buf := bytes.Buffer{}
+	gz := gzip.NewWriter(&buf)
+	enc := json.NewEncoder(gz)
+	for _, entry := range entries {
+		if err := enc.Encode(entry); err != nil {
+			return errors.Wrap(err, "encode query log entry")
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return errors.Wrap(err, "compress query log")
+	}
+
+	userBkt := bucket.NewUserBucketClient(tenantID, u.bkt, u.cfgProvider)
+	return errors.Wrap(userBkt.Upload(ctx, ObjectName(entries[0].Timestamp, u.instanceID, u.lastSeq.Inc()), &buf), "upload query log")
+}
+
+// ObjectName returns the name of the query log object, relative to the tenant's bucket prefix, holding
+// a batch of entries starting at the input time and uploaded by the input query-frontend instance, with the
+// input sequence number. Objects are partitioned by day, to make it easier to select the time range to analyse.
+func ObjectName(firstEntry time.Time, instanceID string, seq uint64) string {
+	firstEntry = firstEntry.UTC()
+	return path.Join(DirName, firstEntry.Format("2006-01-02"), fmt.Sprintf("%s-%s-%d.json.gz", firstEntry.Format("20060102T150405.000Z"), instanceID, seq))
+}
@@ -0,0 +1,133 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package querylog
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/services"
+	"github.com/prometheus/client_golang/prometheus"
+	promtest "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	assert.NoError(t, (&Config{Enabled: false, MaxBatchSize: 0}).Validate())
+	assert.NoError(t, (&Config{Enabled: true, MaxBatchSize: 1}).Validate())
+	assert.Equal(t, errInvalidMaxBatchSize, (&Config{Enabled: true, MaxBatchSize: 0}).Validate())
+}
+
+func TestObjectName(t *testing.T) {
+	ts := time.Date(2023, 10, 5, 12, 30, 15, 123*int(time.Millisecond), time.FixedZone("CEST", 2*60*60))
+	assert.Equal(t, "query-log/2023-10-05/20231005T103015.123Z-frontend-1-42.json.gz", ObjectName(ts, "frontend-1", 42))
+}
+
+func TestUploader(t *testing.T) {
+	ts := time.Date(2023, 10, 5, 12, 0, 0, 0, time.UTC)
+
+	t.Run("should upload the buffered entries of each tenant on shutdown", func(t *testing.T) {
+		bkt := objstore.NewInMemBucket()
+		reg := prometheus.NewPedanticRegistry()
+		u := NewUploader(Config{Enabled: true, FlushInterval: time.Hour, MaxBatchSize: 10}, bkt, nil, log.NewNopLogger(), reg)
+		require.NoError(t, services.StartAndAwaitRunning(context.Background(), u))
+
+		u.Append(Entry{Timestamp: ts, Tenant: "user-1", Query: "up"})
+		u.Append(Entry{Timestamp: ts.Add(time.Second), Tenant: "user-2", Query: "sum(up)"})
+		u.Append(Entry{Timestamp: ts.Add(2 * time.Second), Tenant: "user-1", Query: "count(up)"})
+
+		require.NoError(t, services.StopAndAwaitTerminated(context.Background(), u))
+
+		assert.Equal(t, []string{"up", "count(up)"}, queriesOf(t, readEntries(t, bkt, "user-1")))
+		assert.Equal(t, []string{"sum(up)"}, queriesOf(t, readEntries(t, bkt, "user-2")))
+
+		assert.NoError(t, promtest.GatherAndCompare(reg, strings.NewReader(`
+			# HELP cortex_query_frontend_query_log_entries_total Total number of query log entries appended to the query log.
+			# TYPE cortex_query_frontend_query_log_entries_total counter
+			cortex_query_frontend_query_log_entries_total 3
+
+			# HELP cortex_query_frontend_query_log_uploads_total Total number of query log files uploaded to the bucket.
+			# TYPE cortex_query_frontend_query_log_uploads_total counter
+			cortex_query_frontend_query_log_uploads_total{reason="shutdown"} 2
+		`), "cortex_query_frontend_query_log_entries_total", "cortex_query_frontend_query_log_uploads_total"))
+	})
+
+	t.Run("should upload the entries of a tenant as soon as the batch is full", func(t *testing.T) {
+		bkt := objstore.NewInMemBucket()
+		u := NewUploader(Config{Enabled: true, FlushInterval: time.Hour, MaxBatchSize: 2}, bkt, nil, log.NewNopLogger(), nil)
+		require.NoError(t, services.StartAndAwaitRunning(context.Background(), u))
+		t.Cleanup(func() {
+			require.NoError(t, services.StopAndAwaitTerminated(context.Background(), u))
+		})
+
+		u.Append(Entry{Timestamp: ts, Tenant: "user-1", Query: "up"})
+		u.Append(Entry{Timestamp: ts, Tenant: "user-1", Query: "sum(up)"})
+
+		require.Eventually(t, func() bool {
+			return len(bkt.Objects()) == 1
+		}, time.Second, 10*time.Millisecond)
+		assert.Equal(t, []string{"up", "sum(up)"}, queriesOf(t, readEntries(t, bkt, "user-1")))
+	})
+
+	t.Run("should discard entries when too many entries are buffered for a tenant", func(t *testing.T) {
+		bkt := objstore.NewInMemBucket()
+		reg := prometheus.NewPedanticRegistry()
+		u := NewUploader(Config{Enabled: true, FlushInterval: time.Hour, MaxBatchSize: 2}, bkt, nil, log.NewNopLogger(), reg)
+
+		// The service isn't running, so the entries are buffered until it's stopped.
+		for i := 0; i < 5; i++ {
+			u.Append(Entry{Timestamp: ts, Tenant: "user-1", Query: "up"})
+		}
+		require.NoError(t, services.StartAndAwaitRunning(context.Background(), u))
+		require.NoError(t, services.StopAndAwaitTerminated(context.Background(), u))
+
+		assert.Len(t, readEntries(t, bkt, "user-1"), 4)
+		assert.Len(t, bkt.Objects(), 2)
+		assert.Equal(t, float64(1), promtest.ToFloat64(u.discardedEntries.WithLabelValues("too-many-buffered")))
+	})
+}
+
+func readEntries(t *testing.T, bkt *objstore.InMemBucket, tenantID string) []Entry {
+	var entries []Entry
+
+	require.NoError(t, bkt.Iter(context.Background(), tenantID+"/"+DirName+"/", func(name string) error {
+		r, err := bkt.Get(context.Background(), name)
+		require.NoError(t, err)
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		require.NoError(t, err)
+
+		scanner := bufio.NewScanner(gz)
+		for scanner.Scan() {
+			var entry Entry
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+			entries = append(entries, entry)
+		}
+		require.NoError(t, scanner.Err())
+		return nil
+	}, objstore.WithRecursiveIter))
+
+	return entries
+}
+
+func queriesOf(t *testing.T, entries []Entry) []string {
+	t.Helper()
+
+	queries := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		queries = append(queries, entry.Query)
+	}
+	return queries
+}
@@ -20,6 +20,7 @@ const (
 	cardinalityLabelNamesQueryCachePrefix   = "cn:"
 	cardinalityLabelValuesQueryCachePrefix  = "cv:"
 	cardinalityActiveSeriesQueryCachePrefix = "ca:"
+	cardinalityBlocksQueryCachePrefix       = "cb:"
 )
 
 func newCardinalityQueryCacheRoundTripper(cache cache.Cache, limits Limits, next http.RoundTripper, logger log.Logger, reg prometheus.Registerer) http.RoundTripper {
@@ -70,6 +71,16 @@ func (c *cardinalityQueryCache) parseRequest(path string, values url.Values) (*g
 			cacheKey:       parsed.String(),
 			cacheKeyPrefix: cardinalityActiveSeriesQueryCachePrefix,
 		}, nil
+	case strings.HasSuffix(path, cardinalityBlocksPathSuffix):
+		parsed, err := cardinality.DecodeBlocksRequestFromValues(values)
+		if err != nil {
+			return nil, err
+		}
+
+		return &genericQueryRequest{
+			cacheKey:       parsed.String(),
+			cacheKeyPrefix: cardinalityBlocksQueryCachePrefix,
+		}, nil
 	default:
 		return nil, errors.New("unknown cardinality API endpoint")
 	}
@@ -118,6 +118,12 @@ func TestCardinalityQueryCache_RoundTrip(t *testing.T) {
 			cacheKey:       "user-1:metric_1\x01metric_2\x00job=\"test\"\x00inmemory\x00100",
 			hashedCacheKey: cardinalityLabelValuesQueryCachePrefix + cacheHashKey("user-1:metric_1\x01metric_2\x00job=\"test\"\x00inmemory\x00100"),
 		},
+		"blocks request": {
+			reqPath:        "/prometheus/api/v1/cardinality/blocks",
+			reqData:        url.Values{"selector": []string{`{job="test"}`}, "start": []string{"1000"}, "end": []string{"2000"}, "limit": []string{"100"}},
+			cacheKey:       "user-1:job=\"test\"\x001000000\x002000000\x00100",
+			hashedCacheKey: cardinalityBlocksQueryCachePrefix + cacheHashKey("user-1:job=\"test\"\x001000000\x002000000\x00100"),
+		},
 	})
 }
 
@@ -8,6 +8,7 @@ package querymiddleware
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -382,6 +383,10 @@ func (c prometheusCodec) DecodeResponse(ctx context.Context, r *http.Response, _
 
 	log := spanlogger.FromContext(ctx, logger)
 
+	if body, ok := r.Body.(chunkedResponseBody); ok && r.Header.Get("Content-Type") == mimirpb.QueryResponseMimeType {
+		return c.decodeChunkedResponse(r, body, log)
+	}
+
 	buf, err := readResponseBody(r)
 	if err != nil {
 		log.Error(err)
@@ -416,6 +421,80 @@ func (c prometheusCodec) DecodeResponse(ctx context.Context, r *http.Response, _
 	return resp, nil
 }
 
+// chunkedResponseBody is a response body which is received in chunks, each of them being a
+// standalone encoded response, like the query results streamed back from queriers.
+type chunkedResponseBody interface {
+	io.ReadCloser
+	NextChunk() ([]byte, error)
+}
+
+// decodeChunkedResponse decodes a protobuf response received in chunks, merging the series of each chunk
+// into the response as soon as the chunk is received, so that the whole encoded response is never buffered.
+func (c prometheusCodec) decodeChunkedResponse(r *http.Response, body chunkedResponseBody, log *spanlogger.SpanLogger) (Response, error) {
+	defer body.Close() // nolint:errcheck
+
+	formatter := protobufFormatter{}
+
+	var (
+		resp     *PrometheusResponse
+		size     int
+		chunks   int
+		duration time.Duration
+	)
+
+	for {
+		chunk, err := body.NextChunk()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			log.Error(err)
+			return nil, apierror.Newf(apierror.TypeInternal, "error decoding response with status %d: %v", r.StatusCode, err)
+		}
+
+		start := time.Now()
+		chunkResp, err := formatter.DecodeResponse(chunk)
+		if err != nil {
+			return nil, apierror.Newf(apierror.TypeInternal, "error decoding response: %v", err)
+		}
+		duration += time.Since(start)
+		size += len(chunk)
+		chunks++
+
+		if resp == nil {
+			resp = chunkResp
+			continue
+		}
+
+		if resp.Data == nil || chunkResp.Data == nil || resp.Data.ResultType != model.ValMatrix.String() || chunkResp.Data.ResultType != model.ValMatrix.String() {
+			return nil, apierror.Newf(apierror.TypeInternal, "error decoding response: only matrix responses can be received in multiple chunks")
+		}
+		resp.Data.Result = append(resp.Data.Result, chunkResp.Data.Result...)
+		resp.Warnings = append(resp.Warnings, chunkResp.Warnings...)
+	}
+
+	log.LogFields(otlog.String("message", "ParseQueryRangeResponse"),
+		otlog.Int("status_code", r.StatusCode),
+		otlog.Int("bytes", size),
+		otlog.Int("chunks", chunks))
+
+	if resp == nil {
+		return nil, apierror.Newf(apierror.TypeInternal, "error decoding response: empty response")
+	}
+
+	c.metrics.duration.WithLabelValues(operationDecode, formatter.Name()).Observe(duration.Seconds())
+	c.metrics.size.WithLabelValues(operationDecode, formatter.Name()).Observe(float64(size))
+
+	if resp.Status == statusError {
+		return nil, apierror.New(apierror.Type(resp.ErrorType), resp.Error)
+	}
+
+	for h, hv := range r.Header {
+		resp.Headers = append(resp.Headers, &PrometheusResponseHeader{Name: h, Values: hv})
+	}
+	return resp, nil
+}
+
 func findFormatter(contentType string) formatter {
 	for _, f := range knownFormats {
 		if f.ContentType().String() == contentType {
@@ -9,6 +9,7 @@ package querymiddleware
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"math/rand"
@@ -310,6 +311,74 @@ func TestPrometheusCodec_DecodeResponse_ContentTypeHandling(t *testing.T) {
 	}
 }
 
+func TestPrometheusCodec_DecodeResponse_ChunkedBody(t *testing.T) {
+	expected := mockPrometheusResponse(10, 5)
+
+	// Split the response into batches of series, each of them encoded as a standalone response.
+	var chunks [][]byte
+	for start := 0; start < len(expected.Data.Result); start += 4 {
+		end := min(start+4, len(expected.Data.Result))
+
+		batch := &PrometheusResponse{
+			Status: expected.Status,
+			Data: &PrometheusData{
+				ResultType: expected.Data.ResultType,
+				Result:     expected.Data.Result[start:end],
+			},
+		}
+
+		chunk, err := protobufFormatter{}.EncodeResponse(batch)
+		require.NoError(t, err)
+		chunks = append(chunks, chunk)
+	}
+	require.Len(t, chunks, 3)
+
+	reg := prometheus.NewPedanticRegistry()
+	codec := NewPrometheusCodec(reg, formatProtobuf)
+
+	body := &chunkedBodyMock{chunks: chunks}
+	httpResponse := &http.Response{
+		StatusCode:    200,
+		Header:        http.Header{"Content-Type": []string{mimirpb.QueryResponseMimeType}},
+		Body:          body,
+		ContentLength: -1,
+	}
+
+	resp, err := codec.DecodeResponse(context.Background(), httpResponse, nil, log.NewNopLogger())
+	require.NoError(t, err)
+	require.True(t, body.closed)
+
+	actual := resp.(*PrometheusResponse)
+	require.Equal(t, expected.Status, actual.Status)
+	require.Equal(t, expected.Data, actual.Data)
+	require.Equal(t, []*PrometheusResponseHeader{{Name: "Content-Type", Values: []string{mimirpb.QueryResponseMimeType}}}, actual.Headers)
+}
+
+// chunkedBodyMock is a response body received in chunks.
+type chunkedBodyMock struct {
+	chunks [][]byte
+	closed bool
+}
+
+func (b *chunkedBodyMock) NextChunk() ([]byte, error) {
+	if len(b.chunks) == 0 {
+		return nil, io.EOF
+	}
+
+	chunk := b.chunks[0]
+	b.chunks = b.chunks[1:]
+	return chunk, nil
+}
+
+func (b *chunkedBodyMock) Read([]byte) (int, error) {
+	return 0, errors.New("the body should be read chunk by chunk")
+}
+
+func (b *chunkedBodyMock) Close() error {
+	b.closed = true
+	return nil
+}
+
 func TestMergeAPIResponses(t *testing.T) {
 	codec := newTestPrometheusCodec()
 
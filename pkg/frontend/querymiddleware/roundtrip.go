@@ -34,6 +34,7 @@ const (
 	cardinalityLabelNamesPathSuffix   = "/api/v1/cardinality/label_names"
 	cardinalityLabelValuesPathSuffix  = "/api/v1/cardinality/label_values"
 	cardinalityActiveSeriesPathSuffix = "/api/v1/cardinality/active_series"
+	cardinalityBlocksPathSuffix       = "/api/v1/cardinality/blocks"
 	labelNamesPathSuffix              = "/api/v1/labels"
 
 	// DefaultDeprecatedCacheUnalignedRequests is the default value for the deprecated querier frontend config DeprecatedCacheUnalignedRequests
@@ -377,7 +378,8 @@ func isInstantQuery(path string) bool {
 func isCardinalityQuery(path string) bool {
 	return strings.HasSuffix(path, cardinalityLabelNamesPathSuffix) ||
 		strings.HasSuffix(path, cardinalityLabelValuesPathSuffix) ||
-		strings.HasSuffix(path, cardinalityActiveSeriesPathSuffix)
+		strings.HasSuffix(path, cardinalityActiveSeriesPathSuffix) ||
+		strings.HasSuffix(path, cardinalityBlocksPathSuffix)
 }
 
 func isLabelsQuery(path string) bool {
@@ -0,0 +1,437 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/concurrency"
+	"github.com/grafana/dskit/dns"
+	"github.com/grafana/dskit/tenant"
+	"github.com/grafana/dskit/user"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/atomic"
+
+	"github.com/grafana/mimir/pkg/util"
+)
+
+const (
+	// activeQueriesLocalParam is the parameter of the active queries API requests which must only be served
+	// by the query-frontend receiving them, used when fanning out the requests to the other query-frontends.
+	activeQueriesLocalParam = "local"
+
+	activeQueriesPeersTimeout     = 10 * time.Second
+	activeQueriesPeersConcurrency = 16
+)
+
+// errQueryKilled is the cause of the cancellation of the queries killed through the active queries API.
+var errQueryKilled = errors.New("query has been killed")
+
+type activeQueryContextKey int
+
+const activeQueryKey activeQueryContextKey = 0
+
+// ActiveQueries keeps track of the queries in-flight in the query-frontend.
+type ActiveQueries struct {
+	// idPrefix makes the IDs of the queries unique across query-frontends.
+	idPrefix string
+	lastID   atomic.Uint64
+
+	// peers are the query-frontends to which the active queries API requests are fanned out. Nil if the
+	// API only covers the queries in-flight in this query-frontend.
+	peers *activeQueriesPeers
+
+	mtx     sync.Mutex
+	queries map[string]*ActiveQuery
+}
+
+func NewActiveQueries() *ActiveQueries {
+	return &ActiveQueries{
+		idPrefix: strconv.FormatUint(rand.Uint64(), 36),
+		queries:  map[string]*ActiveQuery{},
+	}
+}
+
+// ActiveQuery is a query in-flight in the query-frontend. A query can be split into multiple sub-queries,
+// each of them being enqueued and executed by a querier.
+type ActiveQuery struct {
+	ID        string
+	Tenant    string
+	Path      string
+	Params    url.Values
+	StartedAt time.Time
+
+	cancel context.CancelCauseFunc
+
+	mtx          sync.Mutex
+	lastSubQuery uint64
+	subQueries   map[uint64]*ActiveSubQuery
+}
+
+// ActiveSubQuery is a sub-query of an ActiveQuery.
+type ActiveSubQuery struct {
+	id    uint64
+	query *ActiveQuery
+
+	// The following fields are guarded by the query mutex.
+	enqueuedAt time.Time
+	queueTime  time.Duration
+	querierID  string
+}
+
+// Add starts tracking a query and returns a context carrying it. The query is canceled when killed.
+// The caller must call Delete once the query is completed.
+func (a *ActiveQueries) Add(ctx context.Context, tenantID string, r *http.Request, params url.Values) (context.Context, *ActiveQuery) {
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	q := &ActiveQuery{
+		ID:         a.idPrefix + "-" + strconv.FormatUint(a.lastID.Inc(), 10),
+		Tenant:     tenantID,
+		Path:       r.URL.Path,
+		Params:     params,
+		StartedAt:  time.Now(),
+		cancel:     cancel,
+		subQueries: map[uint64]*ActiveSubQuery{},
+	}
+
+	a.mtx.Lock()
+	a.queries[q.ID] = q
+	a.mtx.Unlock()
+
+	return context.WithValue(ctx, activeQueryKey, q), q
+}
+
+// Delete stops tracking the query.
+func (a *ActiveQueries) Delete(q *ActiveQuery) {
+	a.mtx.Lock()
+	delete(a.queries, q.ID)
+	a.mtx.Unlock()
+
+	q.cancel(context.Canceled)
+}
+
+// Kill cancels the query with the input ID, if it belongs to the tenant. It returns false if there's no such query.
+func (a *ActiveQueries) Kill(tenantID, id string) bool {
+	a.mtx.Lock()
+	q, ok := a.queries[id]
+	a.mtx.Unlock()
+
+	if !ok || q.Tenant != tenantID {
+		return false
+	}
+
+	q.cancel(errQueryKilled)
+	return true
+}
+
+// List returns the queries of the tenant, sorted by start time.
+func (a *ActiveQueries) List(tenantID string) []*ActiveQuery {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	var queries []*ActiveQuery
+	for _, q := range a.queries {
+		if q.Tenant == tenantID {
+			queries = append(queries, q)
+		}
+	}
+
+	sort.Slice(queries, func(i, j int) bool {
+		return queries[i].StartedAt.Before(queries[j].StartedAt)
+	})
+	return queries
+}
+
+// ActiveQueryFromContext returns the ActiveQuery carried by the context, or nil if the query isn't tracked.
+func ActiveQueryFromContext(ctx context.Context) *ActiveQuery {
+	q, _ := ctx.Value(activeQueryKey).(*ActiveQuery)
+	return q
+}
+
+// IsQueryKilled returns whether the query carried by the context has been killed.
+func IsQueryKilled(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), errQueryKilled)
+}
+
+// AddSubQuery starts tracking a sub-query, which has just been enqueued. Safe to call on a nil ActiveQuery.
+func (q *ActiveQuery) AddSubQuery() *ActiveSubQuery {
+	if q == nil {
+		return nil
+	}
+
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	q.lastSubQuery++
+	s := &ActiveSubQuery{id: q.lastSubQuery, query: q, enqueuedAt: time.Now()}
+	q.subQueries[s.id] = s
+	return s
+}
+
+// Started records the querier which dequeued the sub-query. Safe to call on a nil ActiveSubQuery.
+func (s *ActiveSubQuery) Started(querierID string, queueTime time.Duration) {
+	if s == nil {
+		return
+	}
+
+	s.query.mtx.Lock()
+	defer s.query.mtx.Unlock()
+
+	s.querierID = querierID
+	s.queueTime = queueTime
+}
+
+// Done stops tracking the sub-query. Safe to call on a nil ActiveSubQuery.
+func (s *ActiveSubQuery) Done() {
+	if s == nil {
+		return
+	}
+
+	s.query.mtx.Lock()
+	defer s.query.mtx.Unlock()
+
+	delete(s.query.subQueries, s.id)
+}
+
+type activeQueriesResponse struct {
+	Status   string              `json:"status"`
+	Data     []activeQueryStatus `json:"data"`
+	Warnings []string            `json:"warnings,omitempty"`
+}
+
+type activeQueryStatus struct {
+	ID         string                 `json:"id"`
+	Tenant     string                 `json:"tenant"`
+	Path       string                 `json:"path"`
+	Query      string                 `json:"query,omitempty"`
+	Start      string                 `json:"start,omitempty"`
+	End        string                 `json:"end,omitempty"`
+	Step       string                 `json:"step,omitempty"`
+	Time       string                 `json:"time,omitempty"`
+	StartedAt  time.Time              `json:"started_at"`
+	Duration   string                 `json:"duration"`
+	SubQueries []activeSubQueryStatus `json:"sub_queries"`
+}
+
+type activeSubQueryStatus struct {
+	EnqueuedAt time.Time `json:"enqueued_at"`
+	// QueueTime and QuerierID are empty while the sub-query is still queued.
+	QueueTime string `json:"queue_time,omitempty"`
+	QuerierID string `json:"querier_id,omitempty"`
+}
+
+func (q *ActiveQuery) status(now time.Time) activeQueryStatus {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	status := activeQueryStatus{
+		ID:         q.ID,
+		Tenant:     q.Tenant,
+		Path:       q.Path,
+		Query:      q.Params.Get("query"),
+		Start:      q.Params.Get("start"),
+		End:        q.Params.Get("end"),
+		Step:       q.Params.Get("step"),
+		Time:       q.Params.Get("time"),
+		StartedAt:  q.StartedAt,
+		Duration:   now.Sub(q.StartedAt).String(),
+		SubQueries: make([]activeSubQueryStatus, 0, len(q.subQueries)),
+	}
+
+	subQueries := make([]*ActiveSubQuery, 0, len(q.subQueries))
+	for _, s := range q.subQueries {
+		subQueries = append(subQueries, s)
+	}
+	sort.Slice(subQueries, func(i, j int) bool {
+		return subQueries[i].id < subQueries[j].id
+	})
+
+	for _, s := range subQueries {
+		subStatus := activeSubQueryStatus{EnqueuedAt: s.enqueuedAt, QuerierID: s.querierID}
+		if s.querierID != "" {
+			subStatus.QueueTime = s.queueTime.String()
+		}
+		status.SubQueries = append(status.SubQueries, subStatus)
+	}
+
+	return status
+}
+
+// ServeHTTP lists the in-flight queries of the tenant, or kills the query with the "id" parameter
+// when the request method is DELETE. When peers are configured, the request is fanned out to all the
+// query-frontends, unless the "local" parameter is set.
+func (a *ActiveQueries) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	tenantIDs, err := tenant.TenantIDs(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	tenantID := tenant.JoinTenantIDs(tenantIDs)
+
+	peers := a.peers
+	if local, _ := strconv.ParseBool(r.FormValue(activeQueriesLocalParam)); local {
+		peers = nil
+	}
+
+	if r.Method == http.MethodDelete {
+		id := r.FormValue("id")
+		if id == "" {
+			http.Error(w, "missing query id", http.StatusBadRequest)
+			return
+		}
+		if !a.Kill(tenantID, id) && !peers.kill(r, id) {
+			http.Error(w, "query not found", http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	now := time.Now()
+	resp := activeQueriesResponse{Status: "success", Data: []activeQueryStatus{}}
+	for _, q := range a.List(tenantID) {
+		resp.Data = append(resp.Data, q.status(now))
+	}
+
+	if peers != nil {
+		data, warnings := peers.list(r)
+		resp.Warnings = warnings
+
+		// The peers include this query-frontend, so its queries are listed twice.
+		seen := make(map[string]struct{}, len(resp.Data))
+		for _, q := range resp.Data {
+			seen[q.ID] = struct{}{}
+		}
+		for _, q := range data {
+			if _, ok := seen[q.ID]; !ok {
+				seen[q.ID] = struct{}{}
+				resp.Data = append(resp.Data, q)
+			}
+		}
+		sort.SliceStable(resp.Data, func(i, j int) bool {
+			return resp.Data[i].StartedAt.Before(resp.Data[j].StartedAt)
+		})
+	}
+
+	util.WriteJSONResponse(w, resp)
+}
+
+// activeQueriesPeers fans out the active queries API requests to the query-frontends.
+type activeQueriesPeers struct {
+	addresses []string
+	provider  *dns.Provider
+	client    *http.Client
+	logger    log.Logger
+}
+
+func newActiveQueriesPeers(addresses string, logger log.Logger, reg prometheus.Registerer) *activeQueriesPeers {
+	return &activeQueriesPeers{
+		addresses: strings.Split(addresses, ","),
+		provider:  dns.NewProvider(logger, prometheus.WrapRegistererWithPrefix("cortex_", prometheus.WrapRegistererWith(prometheus.Labels{"component": "query-frontend-active-queries"}, reg)), dns.GolangResolverType),
+		client:    &http.Client{Timeout: activeQueriesPeersTimeout},
+		logger:    logger,
+	}
+}
+
+// list returns the queries listed by all the peers, and a warning for each peer which failed to list them.
+func (p *activeQueriesPeers) list(r *http.Request) ([]activeQueryStatus, []string) {
+	var (
+		mtx      sync.Mutex
+		data     []activeQueryStatus
+		warnings []string
+	)
+
+	p.forEach(r, func(addr string, resp *http.Response, err error) {
+		var peerResp activeQueriesResponse
+		if err == nil && resp.StatusCode != http.StatusOK {
+			err = fmt.Errorf("unexpected status code %d", resp.StatusCode)
+		}
+		if err == nil {
+			err = json.NewDecoder(resp.Body).Decode(&peerResp)
+		}
+
+		mtx.Lock()
+		defer mtx.Unlock()
+
+		if err != nil {
+			level.Warn(p.logger).Log("msg", "failed to list the active queries of a query-frontend", "addr", addr, "err", err)
+			warnings = append(warnings, fmt.Sprintf("failed to list the active queries of the query-frontend %s: %s", addr, err))
+			return
+		}
+		data = append(data, peerResp.Data...)
+	})
+
+	return data, warnings
+}
+
+// kill kills the query with the input ID in the peer where it's running. It returns false if no peer killed it.
+func (p *activeQueriesPeers) kill(r *http.Request, id string) bool {
+	if p == nil {
+		return false
+	}
+
+	killed := atomic.NewBool(false)
+	p.forEach(r, func(addr string, resp *http.Response, err error) {
+		if err != nil {
+			level.Warn(p.logger).Log("msg", "failed to kill an active query in a query-frontend", "addr", addr, "id", id, "err", err)
+			return
+		}
+		if resp.StatusCode == http.StatusNoContent {
+			killed.Store(true)
+		}
+	})
+
+	return killed.Load()
+}
+
+// forEach sends the input request to every peer, with the local parameter set, and calls f with each response.
+func (p *activeQueriesPeers) forEach(r *http.Request, f func(addr string, resp *http.Response, err error)) {
+	ctx, cancel := context.WithTimeout(r.Context(), activeQueriesPeersTimeout)
+	defer cancel()
+
+	if err := p.provider.Resolve(ctx, p.addresses); err != nil {
+		level.Warn(p.logger).Log("msg", "failed to resolve the query-frontends addresses", "err", err)
+	}
+
+	query := r.URL.Query()
+	query.Set(activeQueriesLocalParam, "true")
+
+	addresses := p.provider.Addresses()
+	_ = concurrency.ForEachJob(ctx, len(addresses), activeQueriesPeersConcurrency, func(ctx context.Context, idx int) error {
+		addr := addresses[idx]
+
+		u := url.URL{Scheme: "http", Host: addr, Path: r.URL.Path, RawQuery: query.Encode()}
+		req, err := http.NewRequestWithContext(ctx, r.Method, u.String(), nil)
+		if err == nil {
+			err = user.InjectOrgIDIntoHTTPRequest(ctx, req)
+		}
+		if err != nil {
+			f(addr, nil, err)
+			return nil
+		}
+
+		resp, err := p.client.Do(req)
+		if err != nil {
+			f(addr, nil, err)
+			return nil
+		}
+		defer resp.Body.Close()
+
+		f(addr, resp, nil)
+		return nil
+	})
+}
@@ -0,0 +1,187 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/middleware"
+	"github.com/grafana/dskit/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActiveQueries(t *testing.T) {
+	queries := NewActiveQueries()
+
+	ctx1, q1 := queries.Add(context.Background(), "user-1", httptest.NewRequest("GET", "/api/v1/query_range", nil), map[string][]string{"query": {"up"}})
+	_, q2 := queries.Add(context.Background(), "user-2", httptest.NewRequest("GET", "/api/v1/query", nil), map[string][]string{"query": {"sum(up)"}})
+
+	require.Same(t, q1, ActiveQueryFromContext(ctx1))
+	require.Nil(t, ActiveQueryFromContext(context.Background()))
+
+	// Each tenant can only see its own queries.
+	require.Equal(t, []*ActiveQuery{q1}, queries.List("user-1"))
+	require.Equal(t, []*ActiveQuery{q2}, queries.List("user-2"))
+	require.Empty(t, queries.List("user-3"))
+
+	// Each tenant can only kill its own queries.
+	require.False(t, queries.Kill("user-2", q1.ID))
+	require.NoError(t, ctx1.Err())
+	require.False(t, queries.Kill("user-1", "unknown"))
+
+	require.True(t, queries.Kill("user-1", q1.ID))
+	require.ErrorIs(t, ctx1.Err(), context.Canceled)
+	require.True(t, IsQueryKilled(ctx1))
+
+	queries.Delete(q1)
+	queries.Delete(q2)
+	require.Empty(t, queries.List("user-1"))
+	require.Empty(t, queries.List("user-2"))
+}
+
+func TestActiveQueries_ServeHTTP(t *testing.T) {
+	queries := NewActiveQueries()
+
+	ctx, q := queries.Add(context.Background(), "user-1", httptest.NewRequest("GET", "/api/v1/query_range", nil), map[string][]string{
+		"query": {"up"},
+		"start": {"10"},
+		"end":   {"20"},
+		"step":  {"5"},
+	})
+
+	queued := q.AddSubQuery()
+	running := q.AddSubQuery()
+	running.Started("querier-1", 2*time.Second)
+	done := q.AddSubQuery()
+	done.Done()
+
+	t.Run("GET should list the tenant queries", func(t *testing.T) {
+		resp := httptest.NewRecorder()
+		queries.ServeHTTP(resp, httptest.NewRequest("GET", "/api/v1/status/active_queries", nil).WithContext(user.InjectOrgID(context.Background(), "user-1")))
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		actual := activeQueriesResponse{}
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &actual))
+		require.Equal(t, "success", actual.Status)
+		require.Len(t, actual.Data, 1)
+
+		status := actual.Data[0]
+		assert.Equal(t, q.ID, status.ID)
+		assert.Equal(t, "user-1", status.Tenant)
+		assert.Equal(t, "/api/v1/query_range", status.Path)
+		assert.Equal(t, "up", status.Query)
+		assert.Equal(t, "10", status.Start)
+		assert.Equal(t, "20", status.End)
+		assert.Equal(t, "5", status.Step)
+
+		require.Len(t, status.SubQueries, 2)
+		assert.Equal(t, queued.enqueuedAt.UTC(), status.SubQueries[0].EnqueuedAt.UTC())
+		assert.Empty(t, status.SubQueries[0].QuerierID)
+		assert.Empty(t, status.SubQueries[0].QueueTime)
+		assert.Equal(t, "querier-1", status.SubQueries[1].QuerierID)
+		assert.Equal(t, "2s", status.SubQueries[1].QueueTime)
+	})
+
+	t.Run("GET should not list other tenants queries", func(t *testing.T) {
+		resp := httptest.NewRecorder()
+		queries.ServeHTTP(resp, httptest.NewRequest("GET", "/api/v1/status/active_queries", nil).WithContext(user.InjectOrgID(context.Background(), "user-2")))
+		require.Equal(t, http.StatusOK, resp.Code)
+		require.JSONEq(t, `{"status":"success","data":[]}`, resp.Body.String())
+	})
+
+	t.Run("DELETE should return 404 for other tenants queries", func(t *testing.T) {
+		resp := httptest.NewRecorder()
+		queries.ServeHTTP(resp, httptest.NewRequest("DELETE", "/api/v1/status/active_queries?id="+q.ID, nil).WithContext(user.InjectOrgID(context.Background(), "user-2")))
+		require.Equal(t, http.StatusNotFound, resp.Code)
+		require.NoError(t, ctx.Err())
+	})
+
+	t.Run("DELETE should kill the query", func(t *testing.T) {
+		resp := httptest.NewRecorder()
+		queries.ServeHTTP(resp, httptest.NewRequest("DELETE", "/api/v1/status/active_queries?id="+q.ID, nil).WithContext(user.InjectOrgID(context.Background(), "user-1")))
+		require.Equal(t, http.StatusNoContent, resp.Code)
+		require.True(t, IsQueryKilled(ctx))
+	})
+}
+
+func TestHandler_ShouldCancelKilledQueries(t *testing.T) {
+	started := make(chan struct{})
+	roundTripper := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		close(started)
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	})
+
+	handler := NewHandler(HandlerConfig{}, nil, roundTripper, log.NewNopLogger(), nil, nil, nil)
+
+	go func() {
+		<-started
+
+		queries := handler.ActiveQueries().List("user-1")
+		if assert.Len(t, queries, 1) {
+			assert.Equal(t, "up", queries[0].Params.Get("query"))
+			assert.True(t, handler.ActiveQueries().Kill("user-1", queries[0].ID))
+		}
+	}()
+
+	req := httptest.NewRequest("GET", "/api/v1/query?query=up", nil).WithContext(user.InjectOrgID(context.Background(), "user-1"))
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+
+	require.Equal(t, StatusClientClosedRequest, resp.Code)
+	require.Contains(t, resp.Body.String(), errQueryKilled.Error())
+	require.Empty(t, handler.ActiveQueries().List("user-1"))
+}
+
+func TestActiveQueries_ServeHTTP_ShouldFanOutToPeers(t *testing.T) {
+	local := NewActiveQueries()
+	remote := NewActiveQueries()
+
+	_, localQuery := local.Add(context.Background(), "user-1", httptest.NewRequest("GET", "/api/v1/query", nil), map[string][]string{"query": {"up"}})
+	remoteCtx, remoteQuery := remote.Add(context.Background(), "user-1", httptest.NewRequest("GET", "/api/v1/query", nil), map[string][]string{"query": {"sum(up)"}})
+	require.NotEqual(t, localQuery.ID, remoteQuery.ID)
+
+	localServer := httptest.NewServer(middleware.AuthenticateUser.Wrap(local))
+	t.Cleanup(localServer.Close)
+	remoteServer := httptest.NewServer(middleware.AuthenticateUser.Wrap(remote))
+	t.Cleanup(remoteServer.Close)
+
+	// The peers include the local query-frontend, and a query-frontend which can't be reached.
+	addresses := []string{localServer.Listener.Addr().String(), remoteServer.Listener.Addr().String(), "localhost:1"}
+	local.peers = newActiveQueriesPeers(strings.Join(addresses, ","), log.NewNopLogger(), nil)
+
+	t.Run("GET should list the tenant queries of all the query-frontends", func(t *testing.T) {
+		resp := httptest.NewRecorder()
+		local.ServeHTTP(resp, httptest.NewRequest("GET", "/api/v1/status/active_queries", nil).WithContext(user.InjectOrgID(context.Background(), "user-1")))
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		actual := activeQueriesResponse{}
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &actual))
+		require.Len(t, actual.Data, 2)
+		assert.Equal(t, localQuery.ID, actual.Data[0].ID)
+		assert.Equal(t, remoteQuery.ID, actual.Data[1].ID)
+
+		require.Len(t, actual.Warnings, 1)
+		assert.Contains(t, actual.Warnings[0], "localhost:1")
+	})
+
+	t.Run("DELETE should kill the query in the query-frontend running it", func(t *testing.T) {
+		resp := httptest.NewRecorder()
+		local.ServeHTTP(resp, httptest.NewRequest("DELETE", "/api/v1/status/active_queries?id="+remoteQuery.ID, nil).WithContext(user.InjectOrgID(context.Background(), "user-2")))
+		require.Equal(t, http.StatusNotFound, resp.Code)
+		require.NoError(t, remoteCtx.Err())
+
+		resp = httptest.NewRecorder()
+		local.ServeHTTP(resp, httptest.NewRequest("DELETE", "/api/v1/status/active_queries?id="+remoteQuery.ID, nil).WithContext(user.InjectOrgID(context.Background(), "user-1")))
+		require.Equal(t, http.StatusNoContent, resp.Code)
+		require.True(t, IsQueryKilled(remoteCtx))
+	})
+}
@@ -28,10 +28,13 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promauto"
 
 	apierror "github.com/grafana/mimir/pkg/api/error"
+	"github.com/grafana/mimir/pkg/frontend/querylog"
 	querier_stats "github.com/grafana/mimir/pkg/querier/stats"
+	"github.com/grafana/mimir/pkg/scheduler/queue"
 	"github.com/grafana/mimir/pkg/util"
 	"github.com/grafana/mimir/pkg/util/activitytracker"
 	util_log "github.com/grafana/mimir/pkg/util/log"
+	"github.com/grafana/mimir/pkg/util/validation"
 )
 
 const (
@@ -52,6 +55,7 @@ type HandlerConfig struct {
 	LogQueryRequestHeaders flagext.StringSliceCSV `yaml:"log_query_request_headers" category:"advanced"`
 	MaxBodySize            int64                  `yaml:"max_body_size" category:"advanced"`
 	QueryStatsEnabled      bool                   `yaml:"query_stats_enabled" category:"advanced"`
+	ActiveQueriesPeers     string                 `yaml:"active_queries_peers" category:"experimental"`
 }
 
 func (cfg *HandlerConfig) RegisterFlags(f *flag.FlagSet) {
@@ -59,15 +63,26 @@ func (cfg *HandlerConfig) RegisterFlags(f *flag.FlagSet) {
 	f.Var(&cfg.LogQueryRequestHeaders, "query-frontend.log-query-request-headers", "Comma-separated list of request header names to include in query logs. Applies to both query stats and slow queries logs.")
 	f.Int64Var(&cfg.MaxBodySize, "query-frontend.max-body-size", 10*1024*1024, "Max body size for downstream prometheus.")
 	f.BoolVar(&cfg.QueryStatsEnabled, "query-frontend.query-stats-enabled", true, "False to disable query statistics tracking. When enabled, a message with some statistics is logged for every query.")
+	f.StringVar(&cfg.ActiveQueriesPeers, "query-frontend.active-queries-peers", "", "Comma-separated list of the HTTP server addresses of the query-frontends, in host:port format, to which the active queries API requests are fanned out, so that they cover the queries in-flight in every query-frontend. Each address can use DNS service discovery, with the dns+ or dnssrv+ prefix. When empty, the active queries API only covers the queries in-flight in the query-frontend serving the request.")
+}
+
+// Limits are the per-tenant limits applied by the Handler.
+type Limits interface {
+	// QueryPriorityHeaderEnabled returns whether the query priority request header is honored for the tenant.
+	QueryPriorityHeaderEnabled(userID string) bool
 }
 
 // Handler accepts queries and forwards them to RoundTripper. It can wait on in-flight requests and log slow queries,
 // all other logic is inside the RoundTripper.
 type Handler struct {
 	cfg          HandlerConfig
+	limits       Limits
 	log          log.Logger
 	roundTripper http.RoundTripper
 	at           *activitytracker.ActivityTracker
+	queryLog     *querylog.Uploader
+
+	activeQueries *ActiveQueries
 
 	// Metrics.
 	querySeconds    *prometheus.CounterVec
@@ -83,16 +98,25 @@ type Handler struct {
 	cond             *sync.Cond
 }
 
-// NewHandler creates a new frontend handler.
-func NewHandler(cfg HandlerConfig, roundTripper http.RoundTripper, log log.Logger, reg prometheus.Registerer, at *activitytracker.ActivityTracker) *Handler {
+// NewHandler creates a new frontend handler. The queryLog can be nil. If limits is nil, the query priority
+// request header is ignored.
+func NewHandler(cfg HandlerConfig, limits Limits, roundTripper http.RoundTripper, log log.Logger, reg prometheus.Registerer, at *activitytracker.ActivityTracker, queryLog *querylog.Uploader) *Handler {
 	h := &Handler{
 		cfg:          cfg,
+		limits:       limits,
 		log:          log,
 		roundTripper: roundTripper,
 		at:           at,
+		queryLog:     queryLog,
+
+		activeQueries: NewActiveQueries(),
 	}
 	h.cond = sync.NewCond(&h.mtx)
 
+	if cfg.ActiveQueriesPeers != "" {
+		h.activeQueries.peers = newActiveQueriesPeers(cfg.ActiveQueriesPeers, log, reg)
+	}
+
 	if cfg.QueryStatsEnabled {
 		h.querySeconds = promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
 			Name: "cortex_query_seconds_total",
@@ -134,6 +158,11 @@ func NewHandler(cfg HandlerConfig, roundTripper http.RoundTripper, log log.Logge
 	return h
 }
 
+// ActiveQueries returns the queries in-flight in the handler.
+func (f *Handler) ActiveQueries() *ActiveQueries {
+	return f.activeQueries
+}
+
 // Stop makes f enter stopped mode and wait on in-flight requests.
 func (f *Handler) Stop() {
 	f.mtx.Lock()
@@ -174,6 +203,10 @@ func (f *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		r = r.WithContext(ctx)
 	}
 
+	// Keep track of the query priority in the context, so that it's propagated
+	// to the requests the query is split into.
+	r = r.WithContext(queue.ContextWithQueryPriority(r.Context(), f.queryPriority(r)))
+
 	// Ensure to close the request body reader.
 	defer func() { _ = r.Body.Close() }()
 
@@ -189,15 +222,27 @@ func (f *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	activityIndex := f.at.Insert(func() string { return httpRequestActivity(r, params) })
 	defer f.at.Delete(activityIndex)
 
+	if tenantIDs, err := tenant.TenantIDs(r.Context()); err == nil {
+		ctx, activeQuery := f.activeQueries.Add(r.Context(), tenant.JoinTenantIDs(tenantIDs), r, params)
+		defer f.activeQueries.Delete(activeQuery)
+		r = r.WithContext(ctx)
+	}
+
 	startTime := time.Now()
 	resp, err := f.roundTripper.RoundTrip(r)
 	queryResponseTime := time.Since(startTime)
 
 	if err != nil {
-		writeError(w, err)
+		if IsQueryKilled(r.Context()) {
+			writeError(w, apierror.New(apierror.TypeCanceled, errQueryKilled.Error()))
+		} else {
+			writeError(w, err)
+		}
 		f.reportQueryStats(r, params, queryResponseTime, 0, stats, err)
+		f.appendQueryLog(r, params, queryResponseTime, 0, stats, 0, err)
 		return
 	}
+	defer resp.Body.Close() // nolint:errcheck
 
 	hs := w.Header()
 	for h, vs := range resp.Header {
@@ -218,9 +263,28 @@ func (f *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if f.cfg.QueryStatsEnabled {
 		f.reportQueryStats(r, params, queryResponseTime, queryResponseSize, stats, nil)
 	}
+	f.appendQueryLog(r, params, queryResponseTime, queryResponseSize, stats, resp.StatusCode, nil)
 }
 
 // reportSlowQuery reports slow queries.
+// queryPriority returns the priority class of the query. The priority request header is only honored if
+// enabled for all the tenants of the query, otherwise it's removed from the request to not be propagated
+// downstream, and the query has the normal priority.
+func (f *Handler) queryPriority(r *http.Request) string {
+	if r.Header.Get(queue.QueryPriorityHeader) == "" {
+		return queue.NormalPriority
+	}
+
+	if f.limits != nil {
+		if tenantIDs, err := tenant.TenantIDs(r.Context()); err == nil && validation.AllTrueBooleansPerTenant(tenantIDs, f.limits.QueryPriorityHeaderEnabled) {
+			return queue.QueryPriorityFromHTTPHeader(r.Header)
+		}
+	}
+
+	r.Header.Del(queue.QueryPriorityHeader)
+	return queue.NormalPriority
+}
+
 func (f *Handler) reportSlowQuery(r *http.Request, queryString url.Values, queryResponseTime time.Duration) {
 	logMessage := append([]interface{}{
 		"msg", "slow query detected",
@@ -237,6 +301,62 @@ func (f *Handler) reportSlowQuery(r *http.Request, queryString url.Values, query
 	level.Info(util_log.WithContext(r.Context(), f.log)).Log(logMessage...)
 }
 
+// appendQueryLog appends the query to the query log of each of the query tenants.
+func (f *Handler) appendQueryLog(r *http.Request, queryString url.Values, queryResponseTime time.Duration, queryResponseSizeBytes int64, stats *querier_stats.Stats, statusCode int, queryErr error) {
+	if f.queryLog == nil {
+		return
+	}
+
+	tenantIDs, err := tenant.TenantIDs(r.Context())
+	if err != nil {
+		return
+	}
+
+	entry := querylog.Entry{
+		Timestamp:            time.Now().Add(-queryResponseTime),
+		Method:               r.Method,
+		Path:                 r.URL.Path,
+		Query:                queryString.Get("query"),
+		Start:                queryString.Get("start"),
+		End:                  queryString.Get("end"),
+		Step:                 queryString.Get("step"),
+		Time:                 queryString.Get("time"),
+		Status:               "success",
+		StatusCode:           statusCode,
+		ResponseTime:         queryResponseTime.Seconds(),
+		ResponseSizeBytes:    queryResponseSizeBytes,
+		WallTime:             stats.LoadWallTime().Seconds(),
+		FetchedSeriesCount:   stats.LoadFetchedSeries(),
+		FetchedChunkBytes:    stats.LoadFetchedChunkBytes(),
+		FetchedChunksCount:   stats.LoadFetchedChunks(),
+		FetchedIndexBytes:    stats.LoadFetchedIndexBytes(),
+		ShardedQueries:       stats.LoadShardedQueries(),
+		SplitQueries:         stats.LoadSplitQueries(),
+		EstimatedSeriesCount: stats.GetEstimatedSeriesCount(),
+		QueueTime:            stats.LoadQueueTime().Seconds(),
+	}
+	if queryErr != nil {
+		entry.Status = queryErrorStatus(queryErr)
+		entry.Error = queryErr.Error()
+	} else if statusCode/100 != 2 {
+		entry.Status = "failed"
+	}
+
+	for _, tenantID := range tenantIDs {
+		entry.Tenant = tenantID
+		f.queryLog.Append(entry)
+	}
+}
+
+func queryErrorStatus(queryErr error) string {
+	if errors.Is(queryErr, context.Canceled) {
+		return "canceled"
+	} else if errors.Is(queryErr, context.DeadlineExceeded) {
+		return "timeout"
+	}
+	return "failed"
+}
+
 func (f *Handler) reportQueryStats(r *http.Request, queryString url.Values, queryResponseTime time.Duration, queryResponseSizeBytes int64, stats *querier_stats.Stats, queryErr error) {
 	tenantIDs, err := tenant.TenantIDs(r.Context())
 	if err != nil {
@@ -285,15 +405,8 @@ func (f *Handler) reportQueryStats(r *http.Request, queryString url.Values, quer
 	}
 
 	if queryErr != nil {
-		logStatus := "failed"
-		if errors.Is(queryErr, context.Canceled) {
-			logStatus = "canceled"
-		} else if errors.Is(queryErr, context.DeadlineExceeded) {
-			logStatus = "timeout"
-		}
-
 		logMessage = append(logMessage,
-			"status", logStatus,
+			"status", queryErrorStatus(queryErr),
 			"err", queryErr)
 	} else {
 		logMessage = append(logMessage,
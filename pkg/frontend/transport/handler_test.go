@@ -6,7 +6,9 @@
 package transport
 
 import (
+	"compress/gzip"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -22,6 +24,7 @@ import (
 	"github.com/go-kit/log/level"
 	"github.com/grafana/dskit/concurrency"
 	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/services"
 	"github.com/grafana/dskit/test"
 	"github.com/grafana/dskit/user"
 	"github.com/pkg/errors"
@@ -29,8 +32,11 @@ import (
 	promtest "github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
 	"go.uber.org/atomic"
 
+	"github.com/grafana/mimir/pkg/frontend/querylog"
+	"github.com/grafana/mimir/pkg/scheduler/queue"
 	"github.com/grafana/mimir/pkg/util/activitytracker"
 )
 
@@ -149,7 +155,7 @@ func TestHandler_ServeHTTP(t *testing.T) {
 			t.Cleanup(func() { require.NoError(t, at.Close()) })
 
 			logger := &testLogger{}
-			handler := NewHandler(tt.cfg, roundTripper, logger, reg, at)
+			handler := NewHandler(tt.cfg, nil, roundTripper, logger, reg, at, nil)
 
 			req := tt.request().WithContext(user.InjectOrgID(context.Background(), "12345"))
 			resp := httptest.NewRecorder()
@@ -245,7 +251,7 @@ func TestHandler_FailedRoundTrip(t *testing.T) {
 			reg := prometheus.NewPedanticRegistry()
 			logs := &concurrency.SyncBuffer{}
 			logger := log.NewLogfmtLogger(logs)
-			handler := NewHandler(test.cfg, roundTripper, logger, reg, nil)
+			handler := NewHandler(test.cfg, nil, roundTripper, logger, reg, nil, nil)
 
 			ctx := user.InjectOrgID(context.Background(), "12345")
 			req := httptest.NewRequest("GET", test.path, nil)
@@ -276,6 +282,122 @@ func TestHandler_FailedRoundTrip(t *testing.T) {
 	}
 }
 
+func TestHandler_QueryLog(t *testing.T) {
+	roundTripper := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Query().Get("query") == "fail" {
+			return nil, httpgrpc.Errorf(http.StatusBadRequest, "invalid query")
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader("{}")),
+		}, nil
+	})
+
+	bkt := objstore.NewInMemBucket()
+	queryLog := querylog.NewUploader(querylog.Config{Enabled: true, FlushInterval: time.Hour, MaxBatchSize: 10}, bkt, nil, log.NewNopLogger(), nil)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), queryLog))
+
+	handler := NewHandler(HandlerConfig{MaxBodySize: 1024, QueryStatsEnabled: true}, nil, roundTripper, log.NewNopLogger(), nil, nil, queryLog)
+
+	for _, query := range []string{"up", "fail"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/query?time=42&query="+query, nil)
+		req = req.WithContext(user.InjectOrgID(context.Background(), "user-1"))
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), queryLog))
+
+	var entries []querylog.Entry
+	require.NoError(t, bkt.Iter(context.Background(), "user-1/"+querylog.DirName+"/", func(name string) error {
+		r, err := bkt.Get(context.Background(), name)
+		require.NoError(t, err)
+		gz, err := gzip.NewReader(r)
+		require.NoError(t, err)
+
+		dec := json.NewDecoder(gz)
+		for dec.More() {
+			var entry querylog.Entry
+			require.NoError(t, dec.Decode(&entry))
+			entries = append(entries, entry)
+		}
+		return nil
+	}, objstore.WithRecursiveIter))
+
+	require.Len(t, entries, 2)
+	assert.Equal(t, "user-1", entries[0].Tenant)
+	assert.Equal(t, "/api/v1/query", entries[0].Path)
+	assert.Equal(t, "up", entries[0].Query)
+	assert.Equal(t, "42", entries[0].Time)
+	assert.Equal(t, "success", entries[0].Status)
+	assert.Equal(t, http.StatusOK, entries[0].StatusCode)
+
+	assert.Equal(t, "user-1", entries[1].Tenant)
+	assert.Equal(t, "fail", entries[1].Query)
+	assert.Equal(t, "failed", entries[1].Status)
+	assert.Contains(t, entries[1].Error, "invalid query")
+}
+
+func TestHandler_QueryPriority(t *testing.T) {
+	tests := map[string]struct {
+		limits           Limits
+		header           string
+		expectedPriority string
+		expectedHeader   string
+	}{
+		"should honor the priority header if enabled for the tenant": {
+			limits:           mockLimits{queryPriorityHeaderEnabled: true},
+			header:           queue.HighPriority,
+			expectedPriority: queue.HighPriority,
+			expectedHeader:   queue.HighPriority,
+		},
+		"should ignore and remove the priority header if not enabled for the tenant": {
+			limits:           mockLimits{queryPriorityHeaderEnabled: false},
+			header:           queue.HighPriority,
+			expectedPriority: queue.NormalPriority,
+		},
+		"should ignore and remove the priority header if there are no limits": {
+			header:           queue.HighPriority,
+			expectedPriority: queue.NormalPriority,
+		},
+		"should use the normal priority if the request has no priority header": {
+			limits:           mockLimits{queryPriorityHeaderEnabled: true},
+			expectedPriority: queue.NormalPriority,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			var actualPriority, actualHeader string
+			roundTripper := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				actualPriority = queue.QueryPriorityFromContext(req.Context())
+				actualHeader = req.Header.Get(queue.QueryPriorityHeader)
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("{}"))}, nil
+			})
+
+			handler := NewHandler(HandlerConfig{MaxBodySize: 1024}, testData.limits, roundTripper, log.NewNopLogger(), nil, nil, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/query?query=up", nil)
+			if testData.header != "" {
+				req.Header.Set(queue.QueryPriorityHeader, testData.header)
+			}
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, req.WithContext(user.InjectOrgID(context.Background(), "user-1")))
+
+			require.Equal(t, http.StatusOK, resp.Code)
+			assert.Equal(t, testData.expectedPriority, actualPriority)
+			assert.Equal(t, testData.expectedHeader, actualHeader)
+		})
+	}
+}
+
+type mockLimits struct {
+	queryPriorityHeaderEnabled bool
+}
+
+func (m mockLimits) QueryPriorityHeaderEnabled(string) bool {
+	return m.queryPriorityHeaderEnabled
+}
+
 // Test Handler.Stop.
 func TestHandler_Stop(t *testing.T) {
 	const (
@@ -297,7 +419,7 @@ func TestHandler_Stop(t *testing.T) {
 	reg := prometheus.NewPedanticRegistry()
 	cfg := HandlerConfig{MaxBodySize: 1024}
 	logger := &testLogger{}
-	handler := NewHandler(cfg, roundTripper, logger, reg, nil)
+	handler := NewHandler(cfg, nil, roundTripper, logger, reg, nil, nil)
 
 	var wg sync.WaitGroup
 	for i := 0; i < requests; i++ {
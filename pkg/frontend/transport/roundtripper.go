@@ -20,6 +20,13 @@ type GrpcRoundTripper interface {
 	RoundTripGRPC(context.Context, *httpgrpc.HTTPRequest) (*httpgrpc.HTTPResponse, error)
 }
 
+// GrpcStreamingRoundTripper is a GrpcRoundTripper which can also return the HTTP response body as a stream.
+// When the returned body is not nil, the response body must be read from it instead of the HTTP response.
+type GrpcStreamingRoundTripper interface {
+	GrpcRoundTripper
+	RoundTripGRPCStreaming(context.Context, *httpgrpc.HTTPRequest) (*httpgrpc.HTTPResponse, io.ReadCloser, error)
+}
+
 func AdaptGrpcRoundTripperToHTTPRoundTripper(r GrpcRoundTripper) http.RoundTripper {
 	return &grpcRoundTripperAdapter{roundTripper: r}
 }
@@ -44,7 +51,15 @@ func (a *grpcRoundTripperAdapter) RoundTrip(r *http.Request) (*http.Response, er
 		return nil, err
 	}
 
-	resp, err := a.roundTripper.RoundTripGRPC(r.Context(), req)
+	var (
+		resp *httpgrpc.HTTPResponse
+		body io.ReadCloser
+	)
+	if streaming, ok := a.roundTripper.(GrpcStreamingRoundTripper); ok {
+		resp, body, err = streaming.RoundTripGRPCStreaming(r.Context(), req)
+	} else {
+		resp, err = a.roundTripper.RoundTripGRPC(r.Context(), req)
+	}
 	if err != nil {
 		var ok bool
 		if resp, ok = httpgrpc.HTTPResponseFromError(err); !ok {
@@ -58,6 +73,10 @@ func (a *grpcRoundTripperAdapter) RoundTrip(r *http.Request) (*http.Response, er
 		Header:        http.Header{},
 		ContentLength: int64(len(resp.Body)),
 	}
+	if body != nil {
+		httpResp.Body = body
+		httpResp.ContentLength = -1
+	}
 	for _, h := range resp.Headers {
 		httpResp.Header[h.Key] = h.Values
 	}
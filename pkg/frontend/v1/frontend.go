@@ -22,6 +22,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 
+	"github.com/grafana/mimir/pkg/frontend/transport"
 	"github.com/grafana/mimir/pkg/frontend/v1/frontendv1pb"
 	"github.com/grafana/mimir/pkg/querier/stats"
 	"github.com/grafana/mimir/pkg/scheduler/queue"
@@ -79,6 +80,9 @@ type request struct {
 	queueSpan   opentracing.Span
 	originalCtx context.Context
 
+	// Used to keep track of the sub-query in the query-frontend active queries. Nil if not tracked.
+	activeSubQuery *transport.ActiveSubQuery
+
 	request  *httpgrpc.HTTPRequest
 	err      chan error
 	response chan *httpgrpc.HTTPResponse
@@ -111,7 +115,7 @@ func New(cfg Config, limits Limits, log log.Logger, registerer prometheus.Regist
 		Help: "Time spent by requests waiting to join the queue or be rejected.",
 	})
 
-	f.requestQueue = queue.NewRequestQueue(log, cfg.MaxOutstandingPerTenant, cfg.QuerierForgetDelay, f.queueLength, f.discardedRequests, enqueueDuration)
+	f.requestQueue = queue.NewRequestQueue(log, cfg.MaxOutstandingPerTenant, false, 0, cfg.QuerierForgetDelay, f.queueLength, f.discardedRequests, enqueueDuration)
 	f.activeUsers = util.NewActiveUsersCleanupWithDefaultValues(f.cleanupInactiveUserMetrics)
 
 	var err error
@@ -176,6 +180,8 @@ func (f *Frontend) RoundTripGRPC(ctx context.Context, req *httpgrpc.HTTPRequest)
 		request:     req,
 		originalCtx: ctx,
 
+		activeSubQuery: transport.ActiveQueryFromContext(ctx).AddSubQuery(),
+
 		// Buffer of 1 to ensure response can be written by the server side
 		// of the Process stream, even if this goroutine goes away due to
 		// client context cancellation.
@@ -183,6 +189,8 @@ func (f *Frontend) RoundTripGRPC(ctx context.Context, req *httpgrpc.HTTPRequest)
 		response: make(chan *httpgrpc.HTTPResponse, 1),
 	}
 
+	defer request.activeSubQuery.Done()
+
 	if err := f.queueRequest(ctx, &request); err != nil {
 		return nil, err
 	}
@@ -223,6 +231,7 @@ func (f *Frontend) Process(server frontendv1pb.Frontend_ProcessServer) error {
 		queueTime := time.Since(req.enqueueTime)
 		f.queueDuration.Observe(queueTime.Seconds())
 		req.queueSpan.Finish()
+		req.activeSubQuery.Started(querierID, queueTime)
 
 		/*
 		  We want to dequeue the next unexpired request from the chosen tenant queue.
@@ -336,7 +345,7 @@ func (f *Frontend) queueRequest(ctx context.Context, req *request) error {
 	joinedTenantID := tenant.JoinTenantIDs(tenantIDs)
 	f.activeUsers.UpdateUserTimestamp(joinedTenantID, now)
 
-	err = f.requestQueue.EnqueueRequestToDispatcher(joinedTenantID, req, maxQueriers, nil)
+	err = f.requestQueue.EnqueueRequestToDispatcher(joinedTenantID, req, maxQueriers, 1, queue.QueryPriorityFromContext(ctx), nil)
 	if errors.Is(err, queue.ErrTooManyRequests) {
 		return errTooManyRequest
 	}
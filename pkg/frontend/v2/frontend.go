@@ -9,6 +9,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"math/rand"
 	"net"
 	"net/http"
@@ -30,8 +31,10 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"go.uber.org/atomic"
 
+	"github.com/grafana/mimir/pkg/frontend/transport"
 	"github.com/grafana/mimir/pkg/frontend/v2/frontendv2pb"
 	"github.com/grafana/mimir/pkg/querier/stats"
+	"github.com/grafana/mimir/pkg/scheduler/queue"
 	"github.com/grafana/mimir/pkg/scheduler/schedulerdiscovery"
 	"github.com/grafana/mimir/pkg/util/httpgrpcutil"
 	"github.com/grafana/mimir/pkg/util/spanlogger"
@@ -52,6 +55,8 @@ type Config struct {
 	Addr string `yaml:"address" category:"advanced"`
 	Port int    `category:"advanced"`
 
+	ResponseStreamingEnabled bool `yaml:"response_streaming_enabled" category:"experimental"`
+
 	// This configuration is injected internally.
 	QuerySchedulerDiscovery schedulerdiscovery.Config `yaml:"-"`
 }
@@ -67,6 +72,8 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet, logger log.Logger) {
 	f.StringVar(&cfg.Addr, "query-frontend.instance-addr", "", "IP address to advertise to the querier (via scheduler) (default is auto-detected from network interfaces).")
 	f.IntVar(&cfg.Port, "query-frontend.instance-port", 0, "Port to advertise to querier (via scheduler) (defaults to server.grpc-listen-port).")
 
+	f.BoolVar(&cfg.ResponseStreamingEnabled, "query-frontend.response-streaming-enabled", false, "True to allow queriers to stream large query results back to the query-frontend in multiple messages, instead of a single one. Streamed range query results are decoded and merged incrementally by the query-frontend.")
+
 	cfg.GRPCClientConfig.RegisterFlagsWithPrefix("query-frontend.grpc-client-config", f)
 }
 
@@ -105,8 +112,12 @@ type frontendRequest struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
-	enqueue  chan enqueueResult
-	response chan *frontendv2pb.QueryResultRequest
+	// Used to keep track of the sub-query in the query-frontend active queries. Nil if not tracked.
+	activeSubQuery *transport.ActiveSubQuery
+
+	enqueue        chan enqueueResult
+	response       chan *frontendv2pb.QueryResultRequest
+	streamResponse chan *queryResultStream
 }
 
 type enqueueStatus int
@@ -186,13 +197,37 @@ func (f *Frontend) stopping(_ error) error {
 
 // RoundTripGRPC round trips a proto (instead of an HTTP request).
 func (f *Frontend) RoundTripGRPC(ctx context.Context, req *httpgrpc.HTTPRequest) (*httpgrpc.HTTPResponse, error) {
+	resp, body, err := f.roundTripGRPC(ctx, req, false)
+	if err != nil || body == nil {
+		return resp, err
+	}
+
+	// The querier streamed the response back even if we didn't ask for it, so we buffer it.
+	defer body.Close() // nolint:errcheck
+
+	buf, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = buf
+	return resp, nil
+}
+
+// RoundTripGRPCStreaming is like RoundTripGRPC, but if response streaming is enabled the querier
+// may stream the HTTP response body back, in which case the returned body is not nil and the
+// response body must be read from it. The caller is responsible for closing the returned body.
+func (f *Frontend) RoundTripGRPCStreaming(ctx context.Context, req *httpgrpc.HTTPRequest) (*httpgrpc.HTTPResponse, io.ReadCloser, error) {
+	return f.roundTripGRPC(ctx, req, f.cfg.ResponseStreamingEnabled)
+}
+
+func (f *Frontend) roundTripGRPC(ctx context.Context, req *httpgrpc.HTTPRequest, streaming bool) (*httpgrpc.HTTPResponse, io.ReadCloser, error) {
 	if s := f.State(); s != services.Running {
-		return nil, fmt.Errorf("frontend not running: %v", s)
+		return nil, nil, fmt.Errorf("frontend not running: %v", s)
 	}
 
 	tenantIDs, err := tenant.TenantIDs(ctx)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	userID := tenant.JoinTenantIDs(tenantIDs)
 
@@ -201,13 +236,19 @@ func (f *Frontend) RoundTripGRPC(ctx context.Context, req *httpgrpc.HTTPRequest)
 	if tracer != nil && span != nil {
 		carrier := (*httpgrpcutil.HttpgrpcHeadersCarrier)(req)
 		if err := tracer.Inject(span.Context(), opentracing.HTTPHeaders, carrier); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
+	// Propagate the query priority to the query-scheduler, since it's lost when a query is split.
+	queue.InjectQueryPriorityIntoHTTPGRPCRequest(ctx, req)
+
+	if streaming {
+		req.Headers = append(req.Headers, &httpgrpc.Header{Key: frontendv2pb.ResponseStreamingHeader, Values: []string{"true"}})
+	}
+
 	spanLogger := spanlogger.FromContext(ctx, f.log)
 	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
 
 	freq := &frontendRequest{
 		queryID:      f.lastQueryID.Inc(),
@@ -220,12 +261,28 @@ func (f *Frontend) RoundTripGRPC(ctx context.Context, req *httpgrpc.HTTPRequest)
 
 		// Buffer of 1 to ensure response or error can be written to the channel
 		// even if this goroutine goes away due to client context cancellation.
-		enqueue:  make(chan enqueueResult, 1),
-		response: make(chan *frontendv2pb.QueryResultRequest, 1),
+		enqueue:        make(chan enqueueResult, 1),
+		response:       make(chan *frontendv2pb.QueryResultRequest, 1),
+		streamResponse: make(chan *queryResultStream, 1),
+
+		activeSubQuery: transport.ActiveQueryFromContext(ctx).AddSubQuery(),
 	}
 
 	f.requests.put(freq)
-	defer f.requests.delete(freq.queryID)
+
+	// The request is released when the response has been received, unless the response is streamed,
+	// in which case it's released once the whole response body has been read or the body is closed.
+	cleanup := func() {
+		f.requests.delete(freq.queryID)
+		freq.activeSubQuery.Done()
+		cancel()
+	}
+	streamed := false
+	defer func() {
+		if !streamed {
+			cleanup()
+		}
+	}()
 
 	retries := f.cfg.WorkerConcurrency + 1 // To make sure we hit at least two different schedulers.
 
@@ -236,7 +293,7 @@ enqueueAgain:
 	select {
 	case <-ctx.Done():
 		spanLogger.DebugLog("msg", "request context cancelled while enqueuing request, aborting", "err", ctx.Err())
-		return nil, ctx.Err()
+		return nil, nil, ctx.Err()
 
 	case f.requestsCh <- freq:
 		// Enqueued, let's wait for response.
@@ -254,7 +311,7 @@ enqueueAgain:
 
 		spanLogger.DebugLog("msg", "enqueuing request failed, retries are exhausted, aborting")
 
-		return nil, httpgrpc.Errorf(http.StatusInternalServerError, "failed to enqueue request")
+		return nil, nil, httpgrpc.Errorf(http.StatusInternalServerError, "failed to enqueue request")
 	}
 
 	spanLogger.DebugLog("msg", "request enqueued successfully, waiting for response")
@@ -272,7 +329,7 @@ enqueueAgain:
 				level.Warn(spanLogger).Log("msg", "failed to send cancellation request to scheduler, queue full")
 			}
 		}
-		return nil, ctx.Err()
+		return nil, nil, ctx.Err()
 
 	case resp := <-freq.response:
 		spanLogger.DebugLog("msg", "received response")
@@ -282,7 +339,13 @@ enqueueAgain:
 			stats.Merge(resp.Stats) // Safe if stats is nil.
 		}
 
-		return resp.HttpResponse, nil
+		return resp.HttpResponse, nil, nil
+
+	case resp := <-freq.streamResponse:
+		spanLogger.DebugLog("msg", "received streamed response")
+
+		streamed = true
+		return resp.response, newQueryResultStreamBody(ctx, resp, cleanup), nil
 	}
 }
 
@@ -309,6 +372,85 @@ func (f *Frontend) QueryResult(ctx context.Context, qrReq *frontendv2pb.QueryRes
 	return &frontendv2pb.QueryResultResponse{}, nil
 }
 
+// QueryResultStream receives the result of a query streamed back by a querier, and hands the
+// received body chunks over to the goroutine waiting for the query result. It also records the
+// querier which is executing the query, as soon as the stream is opened.
+func (f *Frontend) QueryResultStream(stream frontendv2pb.FrontendForQuerier_QueryResultStreamServer) error {
+	tenantIDs, err := tenant.TenantIDs(stream.Context())
+	if err != nil {
+		return err
+	}
+	userID := tenant.JoinTenantIDs(tenantIDs)
+
+	msg, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	// Same as QueryResult, we verify the user to avoid leaking query results between users.
+	req := f.requests.get(msg.QueryID)
+	if req == nil || req.userID != userID {
+		return stream.SendAndClose(&frontendv2pb.QueryResultResponse{})
+	}
+
+	// The stream is opened once the querier starts executing the query, so the first message only
+	// tells which querier is executing it.
+	if msg.HttpResponse == nil {
+		req.activeSubQuery.Started(msg.QuerierID, time.Duration(msg.QueueTimeNanos))
+
+		msg, err = stream.Recv()
+		if err != nil {
+			return err
+		}
+	}
+
+	res := &queryResultStream{
+		response: msg.HttpResponse,
+		chunks:   make(chan []byte, queryResultStreamBufferSize),
+	}
+	select {
+	case req.streamResponse <- res:
+		// Should always be possible, unless the query result is sent multiple times with the same queryID.
+	default:
+		level.Warn(f.log).Log("msg", "failed to write query result stream to the response channel", "queryID", msg.QueryID, "user", userID)
+		return stream.SendAndClose(&frontendv2pb.QueryResultResponse{})
+	}
+
+	// Closing the chunks channel signals the end of the stream to the reader, so err and stats
+	// must be set before closing it.
+	defer close(res.chunks)
+
+	for {
+		if msg.Stats != nil {
+			res.stats = msg.Stats
+		}
+
+		if len(msg.BodyChunk) > 0 {
+			if req.ctx.Err() != nil {
+				// The query has been canceled or the response body closed, there's no point receiving the rest of the stream.
+				res.err = req.ctx.Err()
+				return res.err
+			}
+
+			select {
+			case res.chunks <- msg.BodyChunk:
+			case <-req.ctx.Done():
+				res.err = req.ctx.Err()
+				return res.err
+			}
+		}
+
+		msg, err = stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(&frontendv2pb.QueryResultResponse{})
+		}
+		if err != nil {
+			res.err = err
+			return err
+		}
+	}
+}
+
 // CheckReady determines if the query frontend is ready.  Function parameters/return
 // chosen to match the same method in the ingester
 func (f *Frontend) CheckReady(_ context.Context) error {
@@ -7,8 +7,11 @@ package v2
 
 import (
 	"context"
+	"encoding/json"
+	"io"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"runtime"
 	"strconv"
@@ -33,6 +36,7 @@ import (
 	"google.golang.org/grpc/keepalive"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/grafana/mimir/pkg/frontend/transport"
 	"github.com/grafana/mimir/pkg/frontend/v2/frontendv2pb"
 	"github.com/grafana/mimir/pkg/querier/stats"
 	"github.com/grafana/mimir/pkg/scheduler/schedulerdiscovery"
@@ -183,6 +187,215 @@ func TestFrontend_ShouldTrackPerRequestMetrics(t *testing.T) {
 	assert.Empty(t, metricsMap["cortex_query_frontend_enqueue_duration_seconds"])
 }
 
+func TestFrontend_ResponseStreaming(t *testing.T) {
+	const userID = "test"
+
+	chunks := []string{"first chunk,", "second chunk,", "third chunk"}
+
+	f, ms := setupFrontend(t, nil, func(f *Frontend, msg *schedulerpb.FrontendToScheduler) *schedulerpb.SchedulerToFrontend {
+		go sendStreamedResponseWithDelay(f, 100*time.Millisecond, userID, msg.QueryID, &httpgrpc.HTTPResponse{Code: 200}, chunks, &stats.Stats{FetchedSeriesCount: 10})
+
+		return &schedulerpb.SchedulerToFrontend{Status: schedulerpb.OK}
+	})
+	f.cfg.ResponseStreamingEnabled = true
+
+	t.Run("RoundTripGRPCStreaming() should return the streamed body", func(t *testing.T) {
+		reqStats, ctx := stats.ContextWithEmptyStats(user.InjectOrgID(context.Background(), userID))
+
+		resp, body, err := f.RoundTripGRPCStreaming(ctx, &httpgrpc.HTTPRequest{})
+		require.NoError(t, err)
+		require.NotNil(t, body)
+		require.Equal(t, int32(200), resp.Code)
+
+		for _, expected := range chunks {
+			chunk, err := body.(*queryResultStreamBody).NextChunk()
+			require.NoError(t, err)
+			require.Equal(t, expected, string(chunk))
+		}
+
+		_, err = body.(*queryResultStreamBody).NextChunk()
+		require.Equal(t, io.EOF, err)
+		require.NoError(t, body.Close())
+
+		// Stats are merged once the whole body has been read.
+		require.Equal(t, uint64(10), reqStats.LoadFetchedSeries())
+		require.Equal(t, 0, f.requests.count())
+	})
+
+	t.Run("RoundTripGRPC() should buffer the streamed body", func(t *testing.T) {
+		resp, err := f.RoundTripGRPC(user.InjectOrgID(context.Background(), userID), &httpgrpc.HTTPRequest{})
+		require.NoError(t, err)
+		require.Equal(t, int32(200), resp.Code)
+		require.Equal(t, strings.Join(chunks, ""), string(resp.Body))
+		require.Equal(t, 0, f.requests.count())
+	})
+
+	// The streaming header is only set on the requests sent with response streaming.
+	ms.checkWithLock(func() {
+		require.Len(t, ms.msgs, 2)
+		require.Equal(t, []*httpgrpc.Header{{Key: frontendv2pb.ResponseStreamingHeader, Values: []string{"true"}}}, ms.msgs[0].HttpRequest.Headers)
+		require.Empty(t, ms.msgs[1].HttpRequest.Headers)
+	})
+}
+
+func TestFrontend_ResponseStreaming_ShouldReleaseRequestWhenBodyIsClosed(t *testing.T) {
+	const userID = "test"
+
+	streamDone := make(chan error, 1)
+
+	f, _ := setupFrontend(t, nil, func(f *Frontend, msg *schedulerpb.FrontendToScheduler) *schedulerpb.SchedulerToFrontend {
+		go func() {
+			// The stream never ends, so the frontend must stop receiving it once the body is closed.
+			stream := newMockQueryResultStream(user.InjectOrgID(context.Background(), userID), msg.QueryID, &httpgrpc.HTTPResponse{Code: 200}, nil, nil)
+			stream.endless = true
+			streamDone <- f.QueryResultStream(stream)
+		}()
+
+		return &schedulerpb.SchedulerToFrontend{Status: schedulerpb.OK}
+	})
+	f.cfg.ResponseStreamingEnabled = true
+
+	_, body, err := f.RoundTripGRPCStreaming(user.InjectOrgID(context.Background(), userID), &httpgrpc.HTTPRequest{})
+	require.NoError(t, err)
+	require.NotNil(t, body)
+
+	_, err = body.(*queryResultStreamBody).NextChunk()
+	require.NoError(t, err)
+	require.NoError(t, body.Close())
+
+	select {
+	case err := <-streamDone:
+		require.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		require.Fail(t, "the frontend didn't stop receiving the stream")
+	}
+	require.Equal(t, 0, f.requests.count())
+}
+
+func sendStreamedResponseWithDelay(f *Frontend, delay time.Duration, userID string, queryID uint64, resp *httpgrpc.HTTPResponse, chunks []string, queryStats *stats.Stats) {
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+
+	ctx := user.InjectOrgID(context.Background(), userID)
+	_ = f.QueryResultStream(newMockQueryResultStream(ctx, queryID, resp, chunks, queryStats))
+}
+
+// mockQueryResultStream is a frontendv2pb.FrontendForQuerier_QueryResultStreamServer sending
+// the response as a querier would do.
+type mockQueryResultStream struct {
+	grpc.ServerStream
+
+	ctx     context.Context
+	msgs    []*frontendv2pb.QueryResultStreamRequest
+	endless bool
+
+	// beforeSecondMessage, if set, is called before returning the second message of the stream.
+	beforeSecondMessage func()
+	received            int
+}
+
+func newMockQueryResultStream(ctx context.Context, queryID uint64, resp *httpgrpc.HTTPResponse, chunks []string, queryStats *stats.Stats) *mockQueryResultStream {
+	msgs := []*frontendv2pb.QueryResultStreamRequest{{QueryID: queryID, HttpResponse: resp}}
+	for _, chunk := range chunks {
+		msgs = append(msgs, &frontendv2pb.QueryResultStreamRequest{BodyChunk: []byte(chunk)})
+	}
+	msgs[len(msgs)-1].Stats = queryStats
+
+	return &mockQueryResultStream{ctx: ctx, msgs: msgs}
+}
+
+func (s *mockQueryResultStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *mockQueryResultStream) Recv() (*frontendv2pb.QueryResultStreamRequest, error) {
+	if s.endless && len(s.msgs) == 0 {
+		return &frontendv2pb.QueryResultStreamRequest{BodyChunk: []byte("more")}, nil
+	}
+	if len(s.msgs) == 0 {
+		return nil, io.EOF
+	}
+
+	if s.received == 1 && s.beforeSecondMessage != nil {
+		s.beforeSecondMessage()
+	}
+
+	msg := s.msgs[0]
+	s.msgs = s.msgs[1:]
+	s.received++
+	return msg, nil
+}
+
+func (s *mockQueryResultStream) SendAndClose(*frontendv2pb.QueryResultResponse) error {
+	return nil
+}
+
+func TestFrontend_ShouldTrackActiveSubQueries(t *testing.T) {
+	const userID = "test"
+
+	activeQueries := transport.NewActiveQueries()
+	ctx, activeQuery := activeQueries.Add(user.InjectOrgID(context.Background(), userID), userID, httptest.NewRequest("GET", "/api/v1/query", nil), nil)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	f, _ := setupFrontend(t, nil, func(f *Frontend, msg *schedulerpb.FrontendToScheduler) *schedulerpb.SchedulerToFrontend {
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+
+			// The querier opens the stream as soon as it starts executing the query, and sends the response once done.
+			stream := newMockQueryResultStream(user.InjectOrgID(context.Background(), userID), msg.QueryID, &httpgrpc.HTTPResponse{Code: 200}, nil, nil)
+			stream.msgs = append([]*frontendv2pb.QueryResultStreamRequest{{
+				QueryID:        msg.QueryID,
+				QuerierID:      "querier-1",
+				QueueTimeNanos: time.Second.Nanoseconds(),
+			}}, stream.msgs...)
+			stream.beforeSecondMessage = func() {
+				close(started)
+				<-release
+			}
+
+			_ = f.QueryResultStream(stream)
+		}()
+
+		return &schedulerpb.SchedulerToFrontend{Status: schedulerpb.OK}
+	})
+
+	go func() {
+		<-started
+
+		subQueries := listActiveSubQueries(t, activeQueries, userID)
+		if assert.Len(t, subQueries, 1) {
+			assert.Equal(t, "querier-1", subQueries[0]["querier_id"])
+			assert.Equal(t, "1s", subQueries[0]["queue_time"])
+		}
+		close(release)
+	}()
+
+	resp, err := f.RoundTripGRPC(ctx, &httpgrpc.HTTPRequest{})
+	require.NoError(t, err)
+	require.Equal(t, int32(200), resp.Code)
+	require.Empty(t, listActiveSubQueries(t, activeQueries, userID))
+
+	activeQueries.Delete(activeQuery)
+}
+
+func listActiveSubQueries(t *testing.T, activeQueries *transport.ActiveQueries, userID string) []map[string]interface{} {
+	resp := httptest.NewRecorder()
+	activeQueries.ServeHTTP(resp, httptest.NewRequest("GET", "/api/v1/status/active_queries", nil).WithContext(user.InjectOrgID(context.Background(), userID)))
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	var list struct {
+		Data []struct {
+			SubQueries []map[string]interface{} `json:"sub_queries"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &list))
+	require.Len(t, list.Data, 1)
+	return list.Data[0].SubQueries
+}
+
 func TestFrontendRetryEnqueue(t *testing.T) {
 	// Frontend uses worker concurrency to compute number of retries. We use one less failure.
 	failures := atomic.NewInt64(testFrontendWorkerConcurrency - 1)
@@ -4,6 +4,7 @@
 package frontendv2pb
 
 import (
+	bytes "bytes"
 	context "context"
 	fmt "fmt"
 	_ "github.com/gogo/protobuf/gogoproto"
@@ -125,36 +126,130 @@ func (m *QueryResultResponse) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_QueryResultResponse proto.InternalMessageInfo
 
+// QueryResultStreamRequest is a message of the QueryResultStream stream. The stream is opened once the
+// querier starts executing the query: the first message carries the queryID, the querierID and the time
+// spent by the query in the queue. The next one carries the HTTP response status code and headers, the
+// following ones carry the chunks of the HTTP response body, and the last one carries the query stats.
+type QueryResultStreamRequest struct {
+	QueryID        uint64                 `protobuf:"varint,1,opt,name=queryID,proto3" json:"queryID,omitempty"`
+	HttpResponse   *httpgrpc.HTTPResponse `protobuf:"bytes,2,opt,name=httpResponse,proto3" json:"httpResponse,omitempty"`
+	BodyChunk      []byte                 `protobuf:"bytes,3,opt,name=bodyChunk,proto3" json:"bodyChunk,omitempty"`
+	Stats          *stats.Stats           `protobuf:"bytes,4,opt,name=stats,proto3" json:"stats,omitempty"`
+	QuerierID      string                 `protobuf:"bytes,5,opt,name=querierID,proto3" json:"querierID,omitempty"`
+	QueueTimeNanos int64                  `protobuf:"varint,6,opt,name=queueTimeNanos,proto3" json:"queueTimeNanos,omitempty"`
+}
+
+func (m *QueryResultStreamRequest) Reset()      { *m = QueryResultStreamRequest{} }
+func (*QueryResultStreamRequest) ProtoMessage() {}
+func (*QueryResultStreamRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eca3873955a29cfe, []int{2}
+}
+func (m *QueryResultStreamRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *QueryResultStreamRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_QueryResultStreamRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *QueryResultStreamRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_QueryResultStreamRequest.Merge(m, src)
+}
+func (m *QueryResultStreamRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *QueryResultStreamRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_QueryResultStreamRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_QueryResultStreamRequest proto.InternalMessageInfo
+
+func (m *QueryResultStreamRequest) GetQueryID() uint64 {
+	if m != nil {
+		return m.QueryID
+	}
+	return 0
+}
+
+func (m *QueryResultStreamRequest) GetHttpResponse() *httpgrpc.HTTPResponse {
+	if m != nil {
+		return m.HttpResponse
+	}
+	return nil
+}
+
+func (m *QueryResultStreamRequest) GetBodyChunk() []byte {
+	if m != nil {
+		return m.BodyChunk
+	}
+	return nil
+}
+
+func (m *QueryResultStreamRequest) GetStats() *stats.Stats {
+	if m != nil {
+		return m.Stats
+	}
+	return nil
+}
+
+func (m *QueryResultStreamRequest) GetQuerierID() string {
+	if m != nil {
+		return m.QuerierID
+	}
+	return ""
+}
+
+func (m *QueryResultStreamRequest) GetQueueTimeNanos() int64 {
+	if m != nil {
+		return m.QueueTimeNanos
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*QueryResultRequest)(nil), "frontendv2pb.QueryResultRequest")
 	proto.RegisterType((*QueryResultResponse)(nil), "frontendv2pb.QueryResultResponse")
+	proto.RegisterType((*QueryResultStreamRequest)(nil), "frontendv2pb.QueryResultStreamRequest")
 }
 
 func init() { proto.RegisterFile("frontend.proto", fileDescriptor_eca3873955a29cfe) }
 
 var fileDescriptor_eca3873955a29cfe = []byte{
-	// 333 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x91, 0xbf, 0x4e, 0x02, 0x41,
-	0x10, 0xc6, 0x77, 0xfd, 0x9b, 0x2c, 0xc4, 0x62, 0xfd, 0x93, 0x0b, 0xc5, 0x04, 0xaf, 0xa2, 0xba,
-	0x35, 0x98, 0x58, 0x58, 0x12, 0x43, 0xb4, 0x93, 0x93, 0xca, 0xee, 0x80, 0xe5, 0x38, 0x91, 0xdb,
-	0x63, 0x77, 0xcf, 0x84, 0xce, 0x27, 0x30, 0x3e, 0x86, 0x8f, 0x62, 0x49, 0x49, 0x29, 0x4b, 0x63,
-	0xc9, 0x23, 0x98, 0xbb, 0x05, 0xc2, 0x45, 0x63, 0xb3, 0x99, 0xc9, 0x7c, 0xbf, 0xcc, 0x37, 0xdf,
-	0x92, 0xa3, 0xbe, 0x14, 0xb1, 0xe6, 0x71, 0xcf, 0x4b, 0xa4, 0xd0, 0x82, 0x96, 0xd7, 0xfd, 0x4b,
-	0x3d, 0xe9, 0x54, 0x4e, 0x42, 0x11, 0x8a, 0x7c, 0xc0, 0xb2, 0xca, 0x6a, 0x2a, 0x17, 0x61, 0xa4,
-	0x07, 0x69, 0xc7, 0xeb, 0x8a, 0x11, 0x0b, 0x65, 0xd0, 0x0f, 0xe2, 0x80, 0xf5, 0xd4, 0x30, 0xd2,
-	0x6c, 0xa0, 0x75, 0x12, 0xca, 0xa4, 0xbb, 0x29, 0x56, 0xc4, 0xd5, 0x1f, 0xc4, 0x28, 0x1a, 0x45,
-	0x92, 0x25, 0xc3, 0x90, 0x8d, 0x53, 0x2e, 0x23, 0x2e, 0x99, 0xd2, 0x81, 0x56, 0xf6, 0xb5, 0x9c,
-	0xfb, 0x86, 0x09, 0x6d, 0xa5, 0x5c, 0x4e, 0x7c, 0xae, 0xd2, 0x67, 0xed, 0xf3, 0x71, 0xca, 0x95,
-	0xa6, 0x0e, 0x39, 0xcc, 0x98, 0xc9, 0xdd, 0x8d, 0x83, 0xab, 0xb8, 0xb6, 0xe7, 0xaf, 0x5b, 0x7a,
-	0x4d, 0xca, 0xd9, 0x6a, 0x9f, 0xab, 0x44, 0xc4, 0x8a, 0x3b, 0x3b, 0x55, 0x5c, 0x2b, 0xd5, 0xcf,
-	0xbc, 0x8d, 0x9f, 0xdb, 0x76, 0xfb, 0x7e, 0x3d, 0xf5, 0x0b, 0x5a, 0xea, 0x92, 0xfd, 0x7c, 0xb7,
-	0xb3, 0x9b, 0x43, 0x65, 0xcf, 0x3a, 0x79, 0xc8, 0x5e, 0xdf, 0x8e, 0xdc, 0x53, 0x72, 0x5c, 0xf0,
-	0x63, 0xd1, 0xfa, 0x13, 0xa1, 0xcd, 0x55, 0x6e, 0x4d, 0x21, 0x5b, 0xf6, 0x1e, 0xda, 0x26, 0xa5,
-	0x2d, 0x31, 0xad, 0x7a, 0xdb, 0xd9, 0x7a, 0xbf, 0xef, 0xaa, 0x9c, 0xff, 0xa3, 0xb0, 0x9b, 0x5c,
-	0xd4, 0x68, 0x4c, 0xe7, 0x80, 0x66, 0x73, 0x40, 0xcb, 0x39, 0xe0, 0x57, 0x03, 0xf8, 0xc3, 0x00,
-	0xfe, 0x34, 0x80, 0xa7, 0x06, 0xf0, 0x97, 0x01, 0xfc, 0x6d, 0x00, 0x2d, 0x0d, 0xe0, 0xf7, 0x05,
-	0xa0, 0xe9, 0x02, 0xd0, 0x6c, 0x01, 0xe8, 0xb1, 0xf0, 0xaf, 0x9d, 0x83, 0x3c, 0xde, 0xcb, 0x9f,
-	0x00, 0x00, 0x00, 0xff, 0xff, 0xef, 0x5d, 0x91, 0xa7, 0xfe, 0x01, 0x00, 0x00,
+	// 427 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x53, 0xbb, 0x8e, 0xd3, 0x40,
+	0x14, 0xf5, 0x65, 0x1f, 0x68, 0x67, 0xad, 0x95, 0x18, 0x1e, 0xb2, 0x22, 0x34, 0x32, 0x2e, 0x56,
+	0xae, 0x6c, 0x14, 0x24, 0x0a, 0xca, 0x65, 0xb5, 0x62, 0x1b, 0xc4, 0xce, 0xba, 0xa2, 0xb3, 0x93,
+	0x89, 0x63, 0x05, 0x7b, 0x9c, 0x79, 0x20, 0xa5, 0xe3, 0x0b, 0x10, 0x9f, 0xc1, 0xa7, 0x50, 0xa1,
+	0x94, 0x29, 0x89, 0xd3, 0x50, 0xa6, 0xa4, 0x44, 0x7e, 0xe4, 0x61, 0x42, 0x10, 0xcd, 0x36, 0xa3,
+	0x99, 0x73, 0xef, 0x99, 0x7b, 0xe6, 0xdc, 0x3b, 0xe8, 0x6c, 0x20, 0x78, 0xa6, 0x58, 0xd6, 0xf7,
+	0x72, 0xc1, 0x15, 0xc7, 0xe6, 0xea, 0xfc, 0xb1, 0x9b, 0x47, 0x9d, 0x47, 0x31, 0x8f, 0x79, 0x15,
+	0xf0, 0xcb, 0x5d, 0x9d, 0xd3, 0x79, 0x1e, 0x27, 0x6a, 0xa8, 0x23, 0xaf, 0xc7, 0x53, 0x3f, 0x16,
+	0xe1, 0x20, 0xcc, 0x42, 0xbf, 0x2f, 0x47, 0x89, 0xf2, 0x87, 0x4a, 0xe5, 0xb1, 0xc8, 0x7b, 0xeb,
+	0x4d, 0xc3, 0x78, 0xf9, 0x17, 0x46, 0x9a, 0xa4, 0x89, 0xf0, 0xf3, 0x51, 0xec, 0x8f, 0x35, 0x13,
+	0x09, 0x13, 0xbe, 0x54, 0xa1, 0x92, 0xf5, 0x5a, 0xf3, 0x9c, 0xcf, 0x80, 0xf0, 0x8d, 0x66, 0x62,
+	0x42, 0x99, 0xd4, 0x1f, 0x14, 0x65, 0x63, 0xcd, 0xa4, 0xc2, 0x16, 0xba, 0x5f, 0x72, 0x26, 0xd7,
+	0x97, 0x16, 0xd8, 0xe0, 0x1e, 0xd2, 0xd5, 0x11, 0xbf, 0x42, 0x66, 0x59, 0x9a, 0x32, 0x99, 0xf3,
+	0x4c, 0x32, 0xeb, 0x9e, 0x0d, 0xee, 0x69, 0xf7, 0x89, 0xb7, 0xd6, 0xf3, 0x26, 0x08, 0xde, 0xad,
+	0xa2, 0xb4, 0x95, 0x8b, 0x1d, 0x74, 0x54, 0xd5, 0xb6, 0x0e, 0x2a, 0x92, 0xe9, 0xd5, 0x4a, 0x6e,
+	0xcb, 0x95, 0xd6, 0x21, 0xe7, 0x31, 0x7a, 0xd8, 0xd2, 0x53, 0x53, 0x9d, 0x5f, 0x80, 0xac, 0x2d,
+	0xfc, 0x56, 0x09, 0x16, 0xa6, 0x77, 0xab, 0xf6, 0x29, 0x3a, 0x89, 0x78, 0x7f, 0xf2, 0x7a, 0xa8,
+	0xb3, 0x51, 0xa5, 0xd8, 0xa4, 0x1b, 0x60, 0xf3, 0x96, 0xc3, 0xbd, 0x6f, 0x29, 0x6f, 0x68, 0x9c,
+	0xbf, 0xbe, 0xb4, 0x8e, 0x6c, 0x70, 0x4f, 0xe8, 0x06, 0xc0, 0xe7, 0xe8, 0x6c, 0xac, 0x99, 0x66,
+	0x41, 0x92, 0xb2, 0xb7, 0x61, 0xc6, 0xa5, 0x75, 0x6c, 0x83, 0x7b, 0x40, 0xff, 0x40, 0xbb, 0xdf,
+	0x01, 0xe1, 0xab, 0x66, 0x66, 0xae, 0xb8, 0xb8, 0xa9, 0x2f, 0xc0, 0x01, 0x3a, 0xdd, 0x32, 0x04,
+	0xdb, 0xde, 0xf6, 0x5c, 0x79, 0xbb, 0x3d, 0xed, 0x3c, 0xfb, 0x47, 0x46, 0xe3, 0xb2, 0x81, 0x23,
+	0xf4, 0x60, 0xc7, 0x66, 0x7c, 0xbe, 0x97, 0xd9, 0xea, 0xc3, 0x7f, 0x55, 0x70, 0xe1, 0xe2, 0x62,
+	0x3a, 0x27, 0xc6, 0x6c, 0x4e, 0x8c, 0xe5, 0x9c, 0xc0, 0xa7, 0x82, 0xc0, 0xd7, 0x82, 0xc0, 0xb7,
+	0x82, 0xc0, 0xb4, 0x20, 0xf0, 0xa3, 0x20, 0xf0, 0xb3, 0x20, 0xc6, 0xb2, 0x20, 0xf0, 0x65, 0x41,
+	0x8c, 0xe9, 0x82, 0x18, 0xb3, 0x05, 0x31, 0xde, 0xb7, 0xfe, 0x4d, 0x74, 0x5c, 0x8d, 0xef, 0x8b,
+	0xdf, 0x03, 0x00, 0x77, 0x88, 0x32, 0x05, 0x5e, 0x03, 0x00, 0x00,
 }
 
 func (this *QueryResultRequest) Equal(that interface{}) bool {
@@ -208,6 +303,45 @@ func (this *QueryResultResponse) Equal(that interface{}) bool {
 	}
 	return true
 }
+func (this *QueryResultStreamRequest) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*QueryResultStreamRequest)
+	if !ok {
+		that2, ok := that.(QueryResultStreamRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.QueryID != that1.QueryID {
+		return false
+	}
+	if !this.HttpResponse.Equal(that1.HttpResponse) {
+		return false
+	}
+	if !bytes.Equal(this.BodyChunk, that1.BodyChunk) {
+		return false
+	}
+	if !this.Stats.Equal(that1.Stats) {
+		return false
+	}
+	if this.QuerierID != that1.QuerierID {
+		return false
+	}
+	if this.QueueTimeNanos != that1.QueueTimeNanos {
+		return false
+	}
+	return true
+}
 func (this *QueryResultRequest) GoString() string {
 	if this == nil {
 		return "nil"
@@ -233,6 +367,25 @@ func (this *QueryResultResponse) GoString() string {
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
+func (this *QueryResultStreamRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 10)
+	s = append(s, "&frontendv2pb.QueryResultStreamRequest{")
+	s = append(s, "QueryID: "+fmt.Sprintf("%#v", this.QueryID)+",\n")
+	if this.HttpResponse != nil {
+		s = append(s, "HttpResponse: "+fmt.Sprintf("%#v", this.HttpResponse)+",\n")
+	}
+	s = append(s, "BodyChunk: "+fmt.Sprintf("%#v", this.BodyChunk)+",\n")
+	if this.Stats != nil {
+		s = append(s, "Stats: "+fmt.Sprintf("%#v", this.Stats)+",\n")
+	}
+	s = append(s, "QuerierID: "+fmt.Sprintf("%#v", this.QuerierID)+",\n")
+	s = append(s, "QueueTimeNanos: "+fmt.Sprintf("%#v", this.QueueTimeNanos)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
 func valueToGoStringFrontend(v interface{}, typ string) string {
 	rv := reflect.ValueOf(v)
 	if rv.IsNil() {
@@ -255,6 +408,7 @@ const _ = grpc.SupportPackageIsVersion4
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type FrontendForQuerierClient interface {
 	QueryResult(ctx context.Context, in *QueryResultRequest, opts ...grpc.CallOption) (*QueryResultResponse, error)
+	QueryResultStream(ctx context.Context, opts ...grpc.CallOption) (FrontendForQuerier_QueryResultStreamClient, error)
 }
 
 type frontendForQuerierClient struct {
@@ -274,9 +428,44 @@ func (c *frontendForQuerierClient) QueryResult(ctx context.Context, in *QueryRes
 	return out, nil
 }
 
+func (c *frontendForQuerierClient) QueryResultStream(ctx context.Context, opts ...grpc.CallOption) (FrontendForQuerier_QueryResultStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_FrontendForQuerier_serviceDesc.Streams[0], "/frontendv2pb.FrontendForQuerier/QueryResultStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &frontendForQuerierQueryResultStreamClient{stream}
+	return x, nil
+}
+
+type FrontendForQuerier_QueryResultStreamClient interface {
+	Send(*QueryResultStreamRequest) error
+	CloseAndRecv() (*QueryResultResponse, error)
+	grpc.ClientStream
+}
+
+type frontendForQuerierQueryResultStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *frontendForQuerierQueryResultStreamClient) Send(m *QueryResultStreamRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *frontendForQuerierQueryResultStreamClient) CloseAndRecv() (*QueryResultResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(QueryResultResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // FrontendForQuerierServer is the server API for FrontendForQuerier service.
 type FrontendForQuerierServer interface {
 	QueryResult(context.Context, *QueryResultRequest) (*QueryResultResponse, error)
+	QueryResultStream(FrontendForQuerier_QueryResultStreamServer) error
 }
 
 // UnimplementedFrontendForQuerierServer can be embedded to have forward compatible implementations.
@@ -286,6 +475,9 @@ type UnimplementedFrontendForQuerierServer struct {
 func (*UnimplementedFrontendForQuerierServer) QueryResult(ctx context.Context, req *QueryResultRequest) (*QueryResultResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method QueryResult not implemented")
 }
+func (*UnimplementedFrontendForQuerierServer) QueryResultStream(srv FrontendForQuerier_QueryResultStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method QueryResultStream not implemented")
+}
 
 func RegisterFrontendForQuerierServer(s *grpc.Server, srv FrontendForQuerierServer) {
 	s.RegisterService(&_FrontendForQuerier_serviceDesc, srv)
@@ -309,6 +501,32 @@ func _FrontendForQuerier_QueryResult_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _FrontendForQuerier_QueryResultStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(FrontendForQuerierServer).QueryResultStream(&frontendForQuerierQueryResultStreamServer{stream})
+}
+
+type FrontendForQuerier_QueryResultStreamServer interface {
+	SendAndClose(*QueryResultResponse) error
+	Recv() (*QueryResultStreamRequest, error)
+	grpc.ServerStream
+}
+
+type frontendForQuerierQueryResultStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *frontendForQuerierQueryResultStreamServer) SendAndClose(m *QueryResultResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *frontendForQuerierQueryResultStreamServer) Recv() (*QueryResultStreamRequest, error) {
+	m := new(QueryResultStreamRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 var _FrontendForQuerier_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "frontendv2pb.FrontendForQuerier",
 	HandlerType: (*FrontendForQuerierServer)(nil),
@@ -318,7 +536,13 @@ var _FrontendForQuerier_serviceDesc = grpc.ServiceDesc{
 			Handler:    _FrontendForQuerier_QueryResult_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "QueryResultStream",
+			Handler:       _FrontendForQuerier_QueryResultStream_Handler,
+			ClientStreams: true,
+		},
+	},
 	Metadata: "frontend.proto",
 }
 
@@ -397,6 +621,77 @@ func (m *QueryResultResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *QueryResultStreamRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *QueryResultStreamRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *QueryResultStreamRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.QueueTimeNanos != 0 {
+		i = encodeVarintFrontend(dAtA, i, uint64(m.QueueTimeNanos))
+		i--
+		dAtA[i] = 0x30
+	}
+	if len(m.QuerierID) > 0 {
+		i -= len(m.QuerierID)
+		copy(dAtA[i:], m.QuerierID)
+		i = encodeVarintFrontend(dAtA, i, uint64(len(m.QuerierID)))
+		i--
+		dAtA[i] = 0x2a
+	}
+	if m.Stats != nil {
+		{
+			size, err := m.Stats.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintFrontend(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x22
+	}
+	if len(m.BodyChunk) > 0 {
+		i -= len(m.BodyChunk)
+		copy(dAtA[i:], m.BodyChunk)
+		i = encodeVarintFrontend(dAtA, i, uint64(len(m.BodyChunk)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if m.HttpResponse != nil {
+		{
+			size, err := m.HttpResponse.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintFrontend(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x12
+	}
+	if m.QueryID != 0 {
+		i = encodeVarintFrontend(dAtA, i, uint64(m.QueryID))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
 func encodeVarintFrontend(dAtA []byte, offset int, v uint64) int {
 	offset -= sovFrontend(v)
 	base := offset
@@ -437,6 +732,37 @@ func (m *QueryResultResponse) Size() (n int) {
 	return n
 }
 
+func (m *QueryResultStreamRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.QueryID != 0 {
+		n += 1 + sovFrontend(uint64(m.QueryID))
+	}
+	if m.HttpResponse != nil {
+		l = m.HttpResponse.Size()
+		n += 1 + l + sovFrontend(uint64(l))
+	}
+	l = len(m.BodyChunk)
+	if l > 0 {
+		n += 1 + l + sovFrontend(uint64(l))
+	}
+	if m.Stats != nil {
+		l = m.Stats.Size()
+		n += 1 + l + sovFrontend(uint64(l))
+	}
+	l = len(m.QuerierID)
+	if l > 0 {
+		n += 1 + l + sovFrontend(uint64(l))
+	}
+	if m.QueueTimeNanos != 0 {
+		n += 1 + sovFrontend(uint64(m.QueueTimeNanos))
+	}
+	return n
+}
+
 func sovFrontend(x uint64) (n int) {
 	return (math_bits.Len64(x|1) + 6) / 7
 }
@@ -464,6 +790,21 @@ func (this *QueryResultResponse) String() string {
 	}, "")
 	return s
 }
+func (this *QueryResultStreamRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&QueryResultStreamRequest{`,
+		`QueryID:` + fmt.Sprintf("%v", this.QueryID) + `,`,
+		`HttpResponse:` + strings.Replace(fmt.Sprintf("%v", this.HttpResponse), "HTTPResponse", "httpgrpc.HTTPResponse", 1) + `,`,
+		`BodyChunk:` + fmt.Sprintf("%v", this.BodyChunk) + `,`,
+		`Stats:` + strings.Replace(fmt.Sprintf("%v", this.Stats), "Stats", "stats.Stats", 1) + `,`,
+		`QuerierID:` + fmt.Sprintf("%v", this.QuerierID) + `,`,
+		`QueueTimeNanos:` + fmt.Sprintf("%v", this.QueueTimeNanos) + `,`,
+		`}`,
+	}, "")
+	return s
+}
 func valueToStringFrontend(v interface{}) string {
 	rv := reflect.ValueOf(v)
 	if rv.IsNil() {
@@ -669,6 +1010,235 @@ func (m *QueryResultResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *QueryResultStreamRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowFrontend
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: QueryResultStreamRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: QueryResultStreamRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field QueryID", wireType)
+			}
+			m.QueryID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFrontend
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.QueryID |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field HttpResponse", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFrontend
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthFrontend
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthFrontend
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.HttpResponse == nil {
+				m.HttpResponse = &httpgrpc.HTTPResponse{}
+			}
+			if err := m.HttpResponse.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BodyChunk", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFrontend
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthFrontend
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthFrontend
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.BodyChunk = append(m.BodyChunk[:0], dAtA[iNdEx:postIndex]...)
+			if m.BodyChunk == nil {
+				m.BodyChunk = []byte{}
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Stats", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFrontend
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthFrontend
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthFrontend
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Stats == nil {
+				m.Stats = &stats.Stats{}
+			}
+			if err := m.Stats.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field QuerierID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFrontend
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthFrontend
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthFrontend
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.QuerierID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field QueueTimeNanos", wireType)
+			}
+			m.QueueTimeNanos = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFrontend
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.QueueTimeNanos |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipFrontend(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthFrontend
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthFrontend
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipFrontend(dAtA []byte) (n int, err error) {
 	l := len(dAtA)
 	iNdEx := 0
@@ -0,0 +1,7 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package frontendv2pb
+
+// ResponseStreamingHeader is the HTTP request header set by the query-frontend to signal the querier
+// that it can stream the HTTP response body back with QueryResultStream.
+const ResponseStreamingHeader = "X-Mimir-Response-Streaming"
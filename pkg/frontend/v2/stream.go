@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package v2
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/grafana/dskit/httpgrpc"
+
+	"github.com/grafana/mimir/pkg/querier/stats"
+)
+
+// queryResultStreamBufferSize is the number of body chunks received from the querier that can be
+// buffered before the querier is blocked waiting for the frontend to consume them.
+const queryResultStreamBufferSize = 1
+
+// queryResultStream is the result of a query streamed back by a querier with QueryResultStream.
+type queryResultStream struct {
+	response *httpgrpc.HTTPResponse
+	chunks   chan []byte
+
+	// err and stats can be read only once chunks has been closed.
+	err   error
+	stats *stats.Stats
+}
+
+// queryResultStreamBody is the io.ReadCloser exposing the body of a streamed query result.
+// Besides Read, it exposes NextChunk, which allows callers to decode the body chunk by chunk
+// instead of buffering the whole body.
+type queryResultStreamBody struct {
+	ctx     context.Context
+	stream  *queryResultStream
+	current []byte
+
+	cleanupOnce sync.Once
+	cleanup     func()
+
+	err error
+}
+
+func newQueryResultStreamBody(ctx context.Context, stream *queryResultStream, cleanup func()) *queryResultStreamBody {
+	return &queryResultStreamBody{
+		ctx:     ctx,
+		stream:  stream,
+		cleanup: cleanup,
+	}
+}
+
+// NextChunk returns the next chunk of the body as sent by the querier, or io.EOF once the
+// whole body has been read.
+func (b *queryResultStreamBody) NextChunk() ([]byte, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	select {
+	case chunk, ok := <-b.stream.chunks:
+		if ok {
+			return chunk, nil
+		}
+
+		if b.stream.err != nil {
+			b.err = b.stream.err
+		} else {
+			b.err = io.EOF
+
+			if stats.ShouldTrackHTTPGRPCResponse(b.stream.response) {
+				stats.FromContext(b.ctx).Merge(b.stream.stats) // Safe if stats is nil.
+			}
+		}
+
+	case <-b.ctx.Done():
+		b.err = b.ctx.Err()
+	}
+
+	// The stream is over, so we can release the request.
+	b.release()
+	return nil, b.err
+}
+
+func (b *queryResultStreamBody) Read(p []byte) (int, error) {
+	for len(b.current) == 0 {
+		chunk, err := b.NextChunk()
+		if err != nil {
+			return 0, err
+		}
+		b.current = chunk
+	}
+
+	n := copy(p, b.current)
+	b.current = b.current[n:]
+	return n, nil
+}
+
+func (b *queryResultStreamBody) Close() error {
+	b.release()
+	return nil
+}
+
+func (b *queryResultStreamBody) release() {
+	b.cleanupOnce.Do(b.cleanup)
+}
@@ -16,6 +16,8 @@ import (
 	"github.com/prometheus/prometheus/tsdb/chunks"
 	"github.com/prometheus/prometheus/util/zeropool"
 	"go.uber.org/atomic"
+
+	"github.com/grafana/mimir/pkg/costattribution"
 )
 
 const (
@@ -42,9 +44,10 @@ type ActiveSeries struct {
 	stripes [numStripes]seriesStripe
 	deleted deletedSeries
 
-	// matchersMutex protects matchers and lastMatchersUpdate.
+	// matchersMutex protects matchers, costAttribution and lastMatchersUpdate.
 	matchersMutex      sync.RWMutex
 	matchers           *Matchers
+	costAttribution    *costattribution.Tracker
 	lastMatchersUpdate time.Time
 
 	// The duration after which series become inactive.
@@ -54,7 +57,8 @@ type ActiveSeries struct {
 
 // seriesStripe holds a subset of the series timestamps for a single tenant.
 type seriesStripe struct {
-	matchers *Matchers
+	matchers        *Matchers
+	costAttribution *costattribution.Tracker
 
 	deleted *deletedSeries
 
@@ -78,16 +82,18 @@ type seriesEntry struct {
 	nanos                     *atomic.Int64        // Unix timestamp in nanoseconds. Needs to be a pointer because we don't store pointers to entries in the stripe.
 	matches                   preAllocDynamicSlice //  Index of the matcher matching
 	numNativeHistogramBuckets int                  // Number of buckets in native histogram series, -1 if not a native histogram.
+	attribution               string               // Key of the series cost attribution, empty if cost attribution is disabled.
 
 	deleted bool // This series was marked as deleted, so before purging we need to remove the refence to it from the deletedSeries.
 }
 
-func NewActiveSeries(asm *Matchers, timeout time.Duration) *ActiveSeries {
-	c := &ActiveSeries{matchers: asm, timeout: timeout}
+// NewActiveSeries makes a new ActiveSeries. The cost attribution tracker can be nil, if cost attribution is disabled.
+func NewActiveSeries(asm *Matchers, timeout time.Duration, cat *costattribution.Tracker) *ActiveSeries {
+	c := &ActiveSeries{matchers: asm, costAttribution: cat, timeout: timeout}
 
 	// Stripes are pre-allocated so that we only read on them and no lock is required.
 	for i := 0; i < numStripes; i++ {
-		c.stripes[i].reinitialize(asm, &c.deleted)
+		c.stripes[i].reinitialize(asm, cat, &c.deleted)
 	}
 
 	return c
@@ -104,12 +110,32 @@ func (c *ActiveSeries) ReloadMatchers(asm *Matchers, now time.Time) {
 	defer c.matchersMutex.Unlock()
 
 	for i := 0; i < numStripes; i++ {
-		c.stripes[i].reinitialize(asm, &c.deleted)
+		c.stripes[i].reinitialize(asm, c.costAttribution, &c.deleted)
 	}
 	c.matchers = asm
 	c.lastMatchersUpdate = now
 }
 
+// CurrentCostAttributionTracker returns the cost attribution tracker the active series are attributed to.
+func (c *ActiveSeries) CurrentCostAttributionTracker() *costattribution.Tracker {
+	c.matchersMutex.RLock()
+	defer c.matchersMutex.RUnlock()
+	return c.costAttribution
+}
+
+// ReloadCostAttribution replaces the cost attribution tracker. Like when reloading the matchers, all
+// the series are forgotten, so that the new tracker only accounts for the series created from now on.
+func (c *ActiveSeries) ReloadCostAttribution(cat *costattribution.Tracker, now time.Time) {
+	c.matchersMutex.Lock()
+	defer c.matchersMutex.Unlock()
+
+	for i := 0; i < numStripes; i++ {
+		c.stripes[i].reinitialize(c.matchers, cat, &c.deleted)
+	}
+	c.costAttribution = cat
+	c.lastMatchersUpdate = now
+}
+
 func (c *ActiveSeries) CurrentConfig() CustomTrackersConfig {
 	c.matchersMutex.RLock()
 	defer c.matchersMutex.RUnlock()
@@ -359,6 +385,7 @@ func (s *seriesStripe) findAndUpdateOrCreateEntryForSeries(ref storage.SeriesRef
 		nanos:                     atomic.NewInt64(nowNanos),
 		matches:                   matches,
 		numNativeHistogramBuckets: numNativeHistogramBuckets,
+		attribution:               s.costAttribution.IncrementActiveSeries(series, time.Unix(0, nowNanos)),
 	}
 
 	s.refs[ref] = e
@@ -371,6 +398,7 @@ func (s *seriesStripe) clear() {
 	defer s.mu.Unlock()
 
 	s.oldestEntryTs.Store(0)
+	s.decrementCostAttribution()
 	s.refs = map[storage.SeriesRef]seriesEntry{}
 	s.active = 0
 	s.activeNativeHistograms = 0
@@ -382,13 +410,15 @@ func (s *seriesStripe) clear() {
 	}
 }
 
-// Reinitialize assigns new matchers and corresponding size activeMatching slices.
-func (s *seriesStripe) reinitialize(asm *Matchers, deleted *deletedSeries) {
+// Reinitialize assigns new matchers and corresponding size activeMatching slices, and a new cost attribution tracker.
+func (s *seriesStripe) reinitialize(asm *Matchers, cat *costattribution.Tracker, deleted *deletedSeries) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	s.deleted = deleted
 	s.oldestEntryTs.Store(0)
+	s.decrementCostAttribution()
+	s.costAttribution = cat
 	s.refs = map[storage.SeriesRef]seriesEntry{}
 	s.active = 0
 	s.activeNativeHistograms = 0
@@ -423,6 +453,7 @@ func (s *seriesStripe) purge(keepUntil time.Time) {
 			if entry.deleted {
 				s.deleted.purge(ref)
 			}
+			s.costAttribution.DecrementActiveSeries(entry.attribution)
 			delete(s.refs, ref)
 			continue
 		}
@@ -484,10 +515,22 @@ func (s *seriesStripe) remove(ref storage.SeriesRef) {
 		}
 	}
 
+	s.costAttribution.DecrementActiveSeries(entry.attribution)
 	s.deleted.purge(ref)
 	delete(s.refs, ref)
 }
 
+// decrementCostAttribution removes all the series of the stripe from the cost attribution.
+// It must be called while holding the write lock, before forgetting the series.
+func (s *seriesStripe) decrementCostAttribution() {
+	if s.costAttribution == nil {
+		return
+	}
+	for _, entry := range s.refs {
+		s.costAttribution.DecrementActiveSeries(entry.attribution)
+	}
+}
+
 func resizeAndClear(l int, prev []uint32) []uint32 {
 	if cap(prev) < l {
 		if l == 0 {
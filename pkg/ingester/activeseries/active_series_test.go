@@ -9,15 +9,20 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/storage"
 	"github.com/prometheus/prometheus/tsdb/chunks"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/atomic"
+
+	"github.com/grafana/mimir/pkg/costattribution"
 )
 
 const DefaultTimeout = 5 * time.Minute
@@ -29,7 +34,7 @@ func TestActiveSeries_UpdateSeries_NoMatchers(t *testing.T) {
 	ref4, ls4 := storage.SeriesRef(4), labels.FromStrings("a", "4")
 	ref5 := storage.SeriesRef(5) // will be used for ls1 again.
 
-	c := NewActiveSeries(&Matchers{}, DefaultTimeout)
+	c := NewActiveSeries(&Matchers{}, DefaultTimeout, nil)
 	valid := c.Purge(time.Now())
 	assert.True(t, valid)
 	allActive, activeMatching, allActiveHistograms, activeMatchingHistograms, allActiveBuckets, activeMatchingBuckets := c.ActiveWithMatchers()
@@ -194,7 +199,7 @@ func TestActiveSeries_ContainsRef(t *testing.T) {
 	for ttl := 1; ttl <= len(series); ttl++ {
 		t.Run(fmt.Sprintf("ttl: %d", ttl), func(t *testing.T) {
 			mockedTime := time.Unix(int64(ttl), 0)
-			c := NewActiveSeries(&Matchers{}, DefaultTimeout)
+			c := NewActiveSeries(&Matchers{}, DefaultTimeout, nil)
 
 			// Update each series with a different timestamp according to each index
 			for i := 0; i < len(series); i++ {
@@ -219,6 +224,50 @@ func TestActiveSeries_ContainsRef(t *testing.T) {
 	}
 }
 
+func TestActiveSeries_CostAttribution(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	manager := costattribution.NewManager(DefaultTimeout, costAttributionLimits{"team"}, reg)
+	c := NewActiveSeries(&Matchers{}, DefaultTimeout, manager.Tracker("user-1"))
+
+	now := time.Now()
+	c.UpdateSeries(labels.FromStrings("a", "1", "team", "a"), storage.SeriesRef(1), now.Add(-2*DefaultTimeout), -1)
+	c.UpdateSeries(labels.FromStrings("a", "2", "team", "a"), storage.SeriesRef(2), now, -1)
+	c.UpdateSeries(labels.FromStrings("a", "3", "team", "b"), storage.SeriesRef(3), now, -1)
+	// Updating an existing series doesn't attribute it again.
+	c.UpdateSeries(labels.FromStrings("a", "3", "team", "b"), storage.SeriesRef(3), now, -1)
+
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+		# HELP cortex_ingester_attributed_active_series The number of active series, attributed by the tenant's cost attribution labels.
+		# TYPE cortex_ingester_attributed_active_series gauge
+		cortex_ingester_attributed_active_series{team="a",user="user-1"} 2
+		cortex_ingester_attributed_active_series{team="b",user="user-1"} 1
+	`)))
+
+	c.Purge(now)
+
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+		# HELP cortex_ingester_attributed_active_series The number of active series, attributed by the tenant's cost attribution labels.
+		# TYPE cortex_ingester_attributed_active_series gauge
+		cortex_ingester_attributed_active_series{team="a",user="user-1"} 1
+		cortex_ingester_attributed_active_series{team="b",user="user-1"} 1
+	`)))
+
+	// Disabling cost attribution removes the series from the previous tracker.
+	c.ReloadCostAttribution(nil, now)
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+		# HELP cortex_ingester_attributed_active_series The number of active series, attributed by the tenant's cost attribution labels.
+		# TYPE cortex_ingester_attributed_active_series gauge
+		cortex_ingester_attributed_active_series{team="a",user="user-1"} 0
+		cortex_ingester_attributed_active_series{team="b",user="user-1"} 0
+	`)))
+}
+
+type costAttributionLimits []string
+
+func (l costAttributionLimits) CostAttributionLabels(string) []string { return l }
+
+func (l costAttributionLimits) MaxCostAttributionCardinalityPerUser(string) int { return 0 }
+
 func TestActiveSeries_UpdateSeries_WithMatchers(t *testing.T) {
 	ref1, ls1 := storage.SeriesRef(1), labels.FromStrings("a", "1")
 	ref2, ls2 := storage.SeriesRef(2), labels.FromStrings("a", "2")
@@ -229,7 +278,7 @@ func TestActiveSeries_UpdateSeries_WithMatchers(t *testing.T) {
 
 	asm := NewMatchers(mustNewCustomTrackersConfigFromMap(t, map[string]string{"foo": `{a=~"2|3|4"}`}))
 
-	c := NewActiveSeries(asm, DefaultTimeout)
+	c := NewActiveSeries(asm, DefaultTimeout, nil)
 	valid := c.Purge(time.Now())
 	assert.True(t, valid)
 	allActive, activeMatching, allActiveHistograms, activeMatchingHistograms, allActiveBuckets, activeMatchingBuckets := c.ActiveWithMatchers()
@@ -455,7 +504,7 @@ func TestActiveSeries_ShouldCorrectlyHandleHashCollisions(t *testing.T) {
 	ls1, ls2 := labelsWithHashCollision()
 	ref1, ref2 := storage.SeriesRef(1), storage.SeriesRef(2)
 
-	c := NewActiveSeries(&Matchers{}, DefaultTimeout)
+	c := NewActiveSeries(&Matchers{}, DefaultTimeout, nil)
 	c.UpdateSeries(ls1, ref1, time.Now(), -1)
 	c.UpdateSeries(ls2, ref2, time.Now(), -1)
 
@@ -483,7 +532,7 @@ func TestActiveSeries_Purge_NoMatchers(t *testing.T) {
 	for ttl := 1; ttl <= len(series); ttl++ {
 		t.Run(fmt.Sprintf("ttl: %d", ttl), func(t *testing.T) {
 			mockedTime := time.Unix(int64(ttl), 0)
-			c := NewActiveSeries(&Matchers{}, DefaultTimeout)
+			c := NewActiveSeries(&Matchers{}, DefaultTimeout, nil)
 
 			for i := 0; i < len(series); i++ {
 				c.UpdateSeries(series[i], refs[i], time.Unix(int64(i), 0), -1)
@@ -529,7 +578,7 @@ func TestActiveSeries_Purge_WithMatchers(t *testing.T) {
 		t.Run(fmt.Sprintf("ttl=%d", ttl), func(t *testing.T) {
 			mockedTime := time.Unix(int64(ttl), 0)
 
-			c := NewActiveSeries(asm, 5*time.Minute)
+			c := NewActiveSeries(asm, 5*time.Minute, nil)
 
 			exp := len(series) - ttl
 			expMatchingSeries := 0
@@ -561,7 +610,7 @@ func TestActiveSeries_PurgeOpt(t *testing.T) {
 	ref1, ref2 := storage.SeriesRef(1), storage.SeriesRef(2)
 
 	currentTime := time.Now()
-	c := NewActiveSeries(&Matchers{}, 59*time.Second)
+	c := NewActiveSeries(&Matchers{}, 59*time.Second, nil)
 
 	c.UpdateSeries(ls1, ref1, currentTime.Add(-2*time.Minute), -1)
 	c.UpdateSeries(ls2, ref2, currentTime, -1)
@@ -597,7 +646,7 @@ func TestActiveSeries_ReloadSeriesMatchers(t *testing.T) {
 	asm := NewMatchers(mustNewCustomTrackersConfigFromMap(t, map[string]string{"foo": `{a=~.*}`}))
 
 	currentTime := time.Now()
-	c := NewActiveSeries(asm, DefaultTimeout)
+	c := NewActiveSeries(asm, DefaultTimeout, nil)
 
 	valid := c.Purge(currentTime)
 	assert.True(t, valid)
@@ -663,7 +712,7 @@ func TestActiveSeries_ReloadSeriesMatchers_LessMatchers(t *testing.T) {
 	}))
 
 	currentTime := time.Now()
-	c := NewActiveSeries(asm, DefaultTimeout)
+	c := NewActiveSeries(asm, DefaultTimeout, nil)
 	valid := c.Purge(currentTime)
 	assert.True(t, valid)
 	allActive, activeMatching, _, _, _, _ := c.ActiveWithMatchers()
@@ -702,7 +751,7 @@ func TestActiveSeries_ReloadSeriesMatchers_SameSizeNewLabels(t *testing.T) {
 
 	currentTime := time.Now()
 
-	c := NewActiveSeries(asm, DefaultTimeout)
+	c := NewActiveSeries(asm, DefaultTimeout, nil)
 	valid := c.Purge(currentTime)
 	assert.True(t, valid)
 	allActive, activeMatching, _, _, _, _ := c.ActiveWithMatchers()
@@ -755,7 +804,7 @@ func benchmarkActiveSeriesUpdateSeriesConcurrency(b *testing.B, numSeries, numGo
 	var (
 		// Run the active series tracker with an active timeout = 0 so that the Purge() will always
 		// purge the series.
-		c           = NewActiveSeries(&Matchers{}, 0)
+		c           = NewActiveSeries(&Matchers{}, 0, nil)
 		updateGroup = &sync.WaitGroup{}
 		purgeGroup  = &sync.WaitGroup{}
 		start       = make(chan struct{})
@@ -893,7 +942,7 @@ func BenchmarkActiveSeries_UpdateSeries(b *testing.B) {
 
 			b.ResetTimer()
 			for i := 0; i < b.N; i++ {
-				c := NewActiveSeries(asm, DefaultTimeout)
+				c := NewActiveSeries(asm, DefaultTimeout, nil)
 				for round := 0; round <= tt.nRounds; round++ {
 					for ix := 0; ix < tt.nSeries; ix++ {
 						c.UpdateSeries(series[ix], refs[ix], time.Unix(0, now), -1)
@@ -918,7 +967,7 @@ func benchmarkPurge(b *testing.B, twice bool) {
 	const numExpiresSeries = numSeries / 25
 
 	currentTime := time.Now()
-	c := NewActiveSeries(&Matchers{}, DefaultTimeout)
+	c := NewActiveSeries(&Matchers{}, DefaultTimeout, nil)
 
 	series := [numSeries]labels.Labels{}
 	refs := [numSeries]storage.SeriesRef{}
@@ -221,6 +221,10 @@ func newSampleTimestampTooFarInFutureError(timestamp model.Time, labels []mimirp
 	return newSampleError(globalerror.SampleTooFarInFuture, "received a sample whose timestamp is too far in the future", timestamp, labels)
 }
 
+func newNativeHistogramCustomBucketsUnsupportedError(timestamp model.Time, labels []mimirpb.LabelAdapter) sampleError {
+	return newSampleError(globalerror.InvalidNativeHistogramSchema, "the native histogram sample has been rejected because native histograms with custom buckets can't be stored yet", timestamp, labels)
+}
+
 func newSampleOutOfOrderError(timestamp model.Time, labels []mimirpb.LabelAdapter) sampleError {
 	return newSampleError(globalerror.SampleOutOfOrder, "the sample has been rejected because another sample with a more recent timestamp has already been ingested and out-of-order samples are not allowed", timestamp, labels)
 }
@@ -510,15 +514,16 @@ func (e tsdbUnavailableError) errorCause() mimirpb.ErrorCause {
 var _ ingesterError = tsdbUnavailableError{}
 
 type ingesterErrSamplers struct {
-	sampleTimestampTooOld             *log.Sampler
-	sampleTimestampTooOldOOOEnabled   *log.Sampler
-	sampleTimestampTooFarInFuture     *log.Sampler
-	sampleOutOfOrder                  *log.Sampler
-	sampleDuplicateTimestamp          *log.Sampler
-	maxSeriesPerMetricLimitExceeded   *log.Sampler
-	maxMetadataPerMetricLimitExceeded *log.Sampler
-	maxSeriesPerUserLimitExceeded     *log.Sampler
-	maxMetadataPerUserLimitExceeded   *log.Sampler
+	sampleTimestampTooOld                   *log.Sampler
+	sampleTimestampTooOldOOOEnabled         *log.Sampler
+	sampleTimestampTooFarInFuture           *log.Sampler
+	nativeHistogramCustomBucketsUnsupported *log.Sampler
+	sampleOutOfOrder                        *log.Sampler
+	sampleDuplicateTimestamp                *log.Sampler
+	maxSeriesPerMetricLimitExceeded         *log.Sampler
+	maxMetadataPerMetricLimitExceeded       *log.Sampler
+	maxSeriesPerUserLimitExceeded           *log.Sampler
+	maxMetadataPerUserLimitExceeded         *log.Sampler
 }
 
 func newIngesterErrSamplers(freq int64) ingesterErrSamplers {
@@ -532,6 +537,7 @@ func newIngesterErrSamplers(freq int64) ingesterErrSamplers {
 		log.NewSampler(freq),
 		log.NewSampler(freq),
 		log.NewSampler(freq),
+		log.NewSampler(freq),
 	}
 }
 
@@ -52,6 +52,7 @@ import (
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc/codes"
 
+	"github.com/grafana/mimir/pkg/costattribution"
 	"github.com/grafana/mimir/pkg/ingester/activeseries"
 	"github.com/grafana/mimir/pkg/ingester/client"
 	"github.com/grafana/mimir/pkg/mimirpb"
@@ -95,13 +96,14 @@ const (
 	instanceIngestionRateTickInterval = time.Second
 
 	// Reasons for discarding samples
-	reasonSampleOutOfOrder     = "sample-out-of-order"
-	reasonSampleTooOld         = "sample-too-old"
-	reasonSampleTooFarInFuture = "sample-too-far-in-future"
-	reasonNewValueForTimestamp = "new-value-for-timestamp"
-	reasonSampleOutOfBounds    = "sample-out-of-bounds"
-	reasonPerUserSeriesLimit   = "per_user_series_limit"
-	reasonPerMetricSeriesLimit = "per_metric_series_limit"
+	reasonSampleOutOfOrder             = "sample-out-of-order"
+	reasonSampleTooOld                 = "sample-too-old"
+	reasonSampleTooFarInFuture         = "sample-too-far-in-future"
+	reasonInvalidNativeHistogramSchema = "invalid-native-histogram-schema"
+	reasonNewValueForTimestamp         = "new-value-for-timestamp"
+	reasonSampleOutOfBounds            = "sample-out-of-bounds"
+	reasonPerUserSeriesLimit           = "per_user_series_limit"
+	reasonPerMetricSeriesLimit         = "per_metric_series_limit"
 
 	replicationFactorStatsName             = "ingester_replication_factor"
 	ringStoreStatsName                     = "ingester_ring_store"
@@ -183,6 +185,8 @@ type Config struct {
 	ErrorSampleRate int64 `yaml:"error_sample_rate" json:"error_sample_rate" category:"experimental"`
 
 	ReturnOnlyGRPCErrors bool `yaml:"return_only_grpc_errors" json:"return_only_grpc_errors" category:"experimental"`
+
+	SeriesChurnTrackingWindow time.Duration `yaml:"series_churn_tracking_window" category:"experimental"`
 }
 
 // RegisterFlags adds the flags required to config this to the given FlagSet
@@ -202,6 +206,7 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet, logger log.Logger) {
 	f.BoolVar(&cfg.LimitInflightRequestsUsingGrpcMethodLimiter, "ingester.limit-inflight-requests-using-grpc-method-limiter", false, "Use experimental method of limiting push requests.")
 	f.Int64Var(&cfg.ErrorSampleRate, "ingester.error-sample-rate", 0, "Each error will be logged once in this many times. Use 0 to log all of them.")
 	f.BoolVar(&cfg.ReturnOnlyGRPCErrors, "ingester.return-only-grpc-errors", false, "When enabled only gRPC errors will be returned by the ingester.")
+	f.DurationVar(&cfg.SeriesChurnTrackingWindow, "ingester.series-churn-tracking-window", 0, "Window over which each ingester tracks the number of series created and removed per tenant and metric name, with a 1m resolution. The metric names with the most created series are listed by the /ingester/tsdb/<tenant>/churn page of each ingester, which only accounts for the series owned by that ingester. 0 to disable.")
 }
 
 func (cfg *Config) Validate() error {
@@ -209,6 +214,10 @@ func (cfg *Config) Validate() error {
 		return fmt.Errorf("error sample rate cannot be a negative number")
 	}
 
+	if cfg.SeriesChurnTrackingWindow < 0 {
+		return fmt.Errorf("series churn tracking window cannot be a negative duration")
+	}
+
 	return cfg.IngesterRing.Validate()
 }
 
@@ -260,8 +269,9 @@ type Ingester struct {
 	// Metrics shared across all per-tenant shippers.
 	shipperMetrics *shipperMetrics
 
-	subservices  *services.Manager
-	activeGroups *util.ActiveGroupsCleanupService
+	subservices     *services.Manager
+	activeGroups    *util.ActiveGroupsCleanupService
+	costAttribution *costattribution.Manager
 
 	tsdbMetrics *tsdbMetrics
 
@@ -341,7 +351,7 @@ func newIngester(cfg Config, limits *validation.Overrides, registerer prometheus
 }
 
 // New returns an Ingester that uses Mimir block storage.
-func New(cfg Config, limits *validation.Overrides, activeGroupsCleanupService *util.ActiveGroupsCleanupService, registerer prometheus.Registerer, logger log.Logger) (*Ingester, error) {
+func New(cfg Config, limits *validation.Overrides, activeGroupsCleanupService *util.ActiveGroupsCleanupService, costAttribution *costattribution.Manager, registerer prometheus.Registerer, logger log.Logger) (*Ingester, error) {
 	i, err := newIngester(cfg, limits, registerer, logger)
 	if err != nil {
 		return nil, err
@@ -349,6 +359,7 @@ func New(cfg Config, limits *validation.Overrides, activeGroupsCleanupService *u
 	i.ingestionRate = util_math.NewEWMARate(0.2, instanceIngestionRateTickInterval)
 	i.metrics = newIngesterMetrics(registerer, cfg.ActiveSeriesMetrics.Enabled, i.getInstanceLimits, i.ingestionRate, &i.inflightPushRequests, &i.inflightPushRequestsBytes)
 	i.activeGroups = activeGroupsCleanupService
+	i.costAttribution = costAttribution
 
 	if registerer != nil {
 		promauto.With(registerer).NewGaugeFunc(prometheus.GaugeOpts{
@@ -572,9 +583,11 @@ func (i *Ingester) updateLoop(ctx context.Context) error {
 			i.ingestionRate.Tick()
 		case <-rateUpdateTicker.C:
 			i.tsdbsMtx.RLock()
+			overflowDeadline := time.Now().Add(-overflowMemberStaleness).UnixMilli()
 			for _, db := range i.tsdbs {
 				db.ingestedAPISamples.Tick()
 				db.ingestedRuleSamples.Tick()
+				db.overflowSeries.purge(overflowDeadline)
 			}
 			i.tsdbsMtx.RUnlock()
 		case <-tsdbUpdateTicker.C:
@@ -609,6 +622,9 @@ func (i *Ingester) updateActiveSeries(now time.Time) {
 		if newMatchersConfig.String() != userDB.activeSeries.CurrentConfig().String() {
 			i.replaceMatchers(activeseries.NewMatchers(newMatchersConfig), userDB, now)
 		}
+		if cat := i.costAttribution.Tracker(userID); cat != userDB.activeSeries.CurrentCostAttributionTracker() {
+			userDB.activeSeries.ReloadCostAttribution(cat, now)
+		}
 		valid := userDB.activeSeries.Purge(now)
 		if !valid {
 			// Active series config has been reloaded, exposing loading metric until MetricsIdleTimeout passes.
@@ -758,17 +774,21 @@ type extendedAppender interface {
 }
 
 type pushStats struct {
-	succeededSamplesCount     int
-	failedSamplesCount        int
-	succeededExemplarsCount   int
-	failedExemplarsCount      int
-	sampleOutOfBoundsCount    int
-	sampleOutOfOrderCount     int
-	sampleTooOldCount         int
-	sampleTooFarInFutureCount int
-	newValueForTimestampCount int
-	perUserSeriesLimitCount   int
-	perMetricSeriesLimitCount int
+	succeededSamplesCount             int
+	failedSamplesCount                int
+	succeededExemplarsCount           int
+	failedExemplarsCount              int
+	sampleOutOfBoundsCount            int
+	sampleOutOfOrderCount             int
+	sampleTooOldCount                 int
+	sampleTooFarInFutureCount         int
+	invalidNativeHistogramSchemaCount int
+	newValueForTimestampCount         int
+	perUserSeriesLimitCount           int
+	perMetricSeriesLimitCount         int
+
+	perUserSeriesLimitOverflowCount   int
+	perMetricSeriesLimitOverflowCount int
 }
 
 // StartPushRequest checks if ingester can start push request, and increments relevant counters.
@@ -915,7 +935,7 @@ func (i *Ingester) PushWithCleanup(ctx context.Context, req *mimirpb.WriteReques
 
 	minAppendTime, minAppendTimeAvailable := db.Head().AppendableMinValidTime()
 
-	err = i.pushSamplesToAppender(userID, req.Timeseries, app, startAppend, &stats, updateFirstPartial, activeSeries, i.limits.OutOfOrderTimeWindow(userID), minAppendTimeAvailable, minAppendTime)
+	err = i.pushSamplesToAppender(userID, req.Timeseries, app, startAppend, &stats, updateFirstPartial, activeSeries, db.overflowSeries, i.limits.OutOfOrderTimeWindow(userID), minAppendTimeAvailable, minAppendTime)
 	if err != nil {
 		if err := app.Rollback(); err != nil {
 			level.Warn(i.logger).Log("msg", "failed to rollback appender on error", "user", userID, "err", err)
@@ -983,6 +1003,9 @@ func (i *Ingester) updateMetricsFromPushStats(userID string, group string, stats
 	if stats.sampleTooFarInFutureCount > 0 {
 		discarded.sampleTooFarInFuture.WithLabelValues(userID, group).Add(float64(stats.sampleTooFarInFutureCount))
 	}
+	if stats.invalidNativeHistogramSchemaCount > 0 {
+		discarded.invalidNativeHistogramSchema.WithLabelValues(userID, group).Add(float64(stats.invalidNativeHistogramSchemaCount))
+	}
 	if stats.newValueForTimestampCount > 0 {
 		discarded.newValueForTimestamp.WithLabelValues(userID, group).Add(float64(stats.newValueForTimestampCount))
 	}
@@ -992,6 +1015,12 @@ func (i *Ingester) updateMetricsFromPushStats(userID string, group string, stats
 	if stats.perMetricSeriesLimitCount > 0 {
 		discarded.perMetricSeriesLimit.WithLabelValues(userID, group).Add(float64(stats.perMetricSeriesLimitCount))
 	}
+	if stats.perUserSeriesLimitOverflowCount > 0 {
+		i.metrics.seriesLimitOverflowSamples.WithLabelValues(userID, reasonPerUserSeriesLimit).Add(float64(stats.perUserSeriesLimitOverflowCount))
+	}
+	if stats.perMetricSeriesLimitOverflowCount > 0 {
+		i.metrics.seriesLimitOverflowSamples.WithLabelValues(userID, reasonPerMetricSeriesLimit).Add(float64(stats.perMetricSeriesLimitOverflowCount))
+	}
 	if stats.succeededSamplesCount > 0 {
 		i.ingestionRate.Add(int64(stats.succeededSamplesCount))
 
@@ -1008,7 +1037,13 @@ func (i *Ingester) updateMetricsFromPushStats(userID string, group string, stats
 // must be of type softError.
 func (i *Ingester) pushSamplesToAppender(userID string, timeseries []mimirpb.PreallocTimeseries, app extendedAppender, startAppend time.Time,
 	stats *pushStats, updateFirstPartial func(sampler *util_log.Sampler, errFn softErrorFunction), activeSeries *activeseries.ActiveSeries,
-	outOfOrderWindow time.Duration, minAppendTimeAvailable bool, minAppendTime int64) error {
+	overflowSeries *overflowSeries, outOfOrderWindow time.Duration, minAppendTimeAvailable bool, minAppendTime int64) error {
+
+	// Attributes the discarded samples of a series, if cost attribution is enabled for the tenant.
+	costAttribution := i.costAttribution.Tracker(userID)
+	attributeDiscarded := func(labels []mimirpb.LabelAdapter, count int, reason string) {
+		costAttribution.IncrementDiscardedSamples(mimirpb.FromLabelAdaptersToLabels(labels), float64(count), reason, startAppend)
+	}
 
 	// Return true if handled as soft error, and we can ingest more series.
 	handleAppendError := func(err error, timestamp int64, labels []mimirpb.LabelAdapter) bool {
@@ -1022,6 +1057,7 @@ func (i *Ingester) pushSamplesToAppender(userID string, timeseries []mimirpb.Pre
 		switch cause := errors.Cause(err); cause {
 		case storage.ErrOutOfBounds:
 			stats.sampleOutOfBoundsCount++
+			attributeDiscarded(labels, 1, reasonSampleOutOfBounds)
 			updateFirstPartial(i.errorSamplers.sampleTimestampTooOld, func() softError {
 				return newSampleTimestampTooOldError(model.Time(timestamp), labels)
 			})
@@ -1029,6 +1065,7 @@ func (i *Ingester) pushSamplesToAppender(userID string, timeseries []mimirpb.Pre
 
 		case storage.ErrOutOfOrderSample:
 			stats.sampleOutOfOrderCount++
+			attributeDiscarded(labels, 1, reasonSampleOutOfOrder)
 			updateFirstPartial(i.errorSamplers.sampleOutOfOrder, func() softError {
 				return newSampleOutOfOrderError(model.Time(timestamp), labels)
 			})
@@ -1036,6 +1073,7 @@ func (i *Ingester) pushSamplesToAppender(userID string, timeseries []mimirpb.Pre
 
 		case storage.ErrTooOldSample:
 			stats.sampleTooOldCount++
+			attributeDiscarded(labels, 1, reasonSampleTooOld)
 			updateFirstPartial(i.errorSamplers.sampleTimestampTooOldOOOEnabled, func() softError {
 				return newSampleTimestampTooOldOOOEnabledError(model.Time(timestamp), labels, outOfOrderWindow)
 			})
@@ -1043,13 +1081,23 @@ func (i *Ingester) pushSamplesToAppender(userID string, timeseries []mimirpb.Pre
 
 		case globalerror.SampleTooFarInFuture:
 			stats.sampleTooFarInFutureCount++
+			attributeDiscarded(labels, 1, reasonSampleTooFarInFuture)
 			updateFirstPartial(i.errorSamplers.sampleTimestampTooFarInFuture, func() softError {
 				return newSampleTimestampTooFarInFutureError(model.Time(timestamp), labels)
 			})
 			return true
 
+		case globalerror.InvalidNativeHistogramSchema:
+			stats.invalidNativeHistogramSchemaCount++
+			attributeDiscarded(labels, 1, reasonInvalidNativeHistogramSchema)
+			updateFirstPartial(i.errorSamplers.nativeHistogramCustomBucketsUnsupported, func() softError {
+				return newNativeHistogramCustomBucketsUnsupportedError(model.Time(timestamp), labels)
+			})
+			return true
+
 		case storage.ErrDuplicateSampleForTimestamp:
 			stats.newValueForTimestampCount++
+			attributeDiscarded(labels, 1, reasonNewValueForTimestamp)
 			updateFirstPartial(i.errorSamplers.sampleDuplicateTimestamp, func() softError {
 				return newSampleDuplicateTimestampError(model.Time(timestamp), labels)
 			})
@@ -1057,6 +1105,7 @@ func (i *Ingester) pushSamplesToAppender(userID string, timeseries []mimirpb.Pre
 
 		case globalerror.MaxSeriesPerUser:
 			stats.perUserSeriesLimitCount++
+			attributeDiscarded(labels, 1, reasonPerUserSeriesLimit)
 			updateFirstPartial(i.errorSamplers.maxSeriesPerUserLimitExceeded, func() softError {
 				return newPerUserSeriesLimitReachedError(i.limiter.limits.MaxGlobalSeriesPerUser(userID))
 			})
@@ -1064,6 +1113,7 @@ func (i *Ingester) pushSamplesToAppender(userID string, timeseries []mimirpb.Pre
 
 		case globalerror.MaxSeriesPerMetric:
 			stats.perMetricSeriesLimitCount++
+			attributeDiscarded(labels, 1, reasonPerMetricSeriesLimit)
 			updateFirstPartial(i.errorSamplers.maxSeriesPerMetricLimitExceeded, func() softError {
 				return newPerMetricSeriesLimitReachedError(i.limiter.limits.MaxGlobalSeriesPerMetric(userID), mimirpb.FromLabelAdaptersToLabelsWithCopy(labels))
 			})
@@ -1076,8 +1126,55 @@ func (i *Ingester) pushSamplesToAppender(userID string, timeseries []mimirpb.Pre
 	var (
 		nativeHistogramsIngestionEnabled = i.limits.NativeHistogramsIngestionEnabled(userID)
 		maxTimestampMs                   = startAppend.Add(i.limits.CreationGracePeriod(userID)).UnixMilli()
+		seriesLimitOverflowEnabled       = i.limits.SeriesLimitOverflowEnabled(userID)
+		seriesLimitOverflowDropLabels    = i.limits.SeriesLimitOverflowDropLabels(userID)
+		seriesLimitOverflowMaxSeries     = i.limits.SeriesLimitOverflowMaxSeries(userID)
+		seriesLimitOverflowMaxAggregated = i.limits.SeriesLimitOverflowMaxAggregatedSeries(userID)
 	)
 
+	// The overflow series updated by the request, with the latest timestamp and the number of samples aggregated into them.
+	type overflowUpdate struct {
+		ts                   int64
+		perUserSeriesLimit   int
+		perMetricSeriesLimit int
+	}
+	var overflowUpdates map[*overflowAggregate]*overflowUpdate
+
+	// Aggregates the float sample of a series which can't be created because of the series limits into its
+	// overflow series, if enabled for the tenant. Returns true if the sample has been aggregated, or false if it
+	// has to be discarded, including when the overflow series limits have been reached too.
+	addToOverflowSeries := func(err error, s mimirpb.Sample, series labels.Labels, seriesHash uint64) bool {
+		if !seriesLimitOverflowEnabled {
+			return false
+		}
+
+		//nolint:errorlint // We don't expect the cause error to be wrapped.
+		cause := errors.Cause(err)
+		if cause != globalerror.MaxSeriesPerUser && cause != globalerror.MaxSeriesPerMetric {
+			return false
+		}
+
+		a, ok := overflowSeries.add(series, seriesHash, seriesLimitOverflowDropLabels, seriesLimitOverflowMaxSeries, seriesLimitOverflowMaxAggregated, s.TimestampMs, s.Value)
+		if !ok {
+			return false
+		}
+		if overflowUpdates == nil {
+			overflowUpdates = map[*overflowAggregate]*overflowUpdate{}
+		}
+		u := overflowUpdates[a]
+		if u == nil {
+			u = &overflowUpdate{}
+			overflowUpdates[a] = u
+		}
+		u.ts = util_math.Max(u.ts, s.TimestampMs)
+		if cause == globalerror.MaxSeriesPerUser {
+			u.perUserSeriesLimit++
+		} else {
+			u.perMetricSeriesLimit++
+		}
+		return true
+	}
+
 	var builder labels.ScratchBuilder
 	var nonCopiedLabels labels.Labels
 	for _, ts := range timeseries {
@@ -1096,6 +1193,7 @@ func (i *Ingester) pushSamplesToAppender(userID string, timeseries []mimirpb.Pre
 
 				stats.failedSamplesCount += len(ts.Samples) + len(ts.Histograms)
 				stats.sampleOutOfBoundsCount += len(ts.Samples) + len(ts.Histograms)
+				attributeDiscarded(ts.Labels, len(ts.Samples)+len(ts.Histograms), reasonSampleOutOfBounds)
 
 				var firstTimestamp int64
 				if len(ts.Samples) > 0 {
@@ -1117,6 +1215,7 @@ func (i *Ingester) pushSamplesToAppender(userID string, timeseries []mimirpb.Pre
 
 				stats.failedSamplesCount += len(ts.Samples)
 				stats.sampleOutOfBoundsCount += len(ts.Samples)
+				attributeDiscarded(ts.Labels, len(ts.Samples), reasonSampleOutOfBounds)
 
 				firstTimestamp := ts.Samples[0].TimestampMs
 
@@ -1137,6 +1236,10 @@ func (i *Ingester) pushSamplesToAppender(userID string, timeseries []mimirpb.Pre
 		// To find out if any sample was added to this series, we keep old value.
 		oldSucceededSamplesCount := stats.succeededSamplesCount
 
+		if ts.CreatedTimestamp > 0 && ts.CreatedTimestamp <= maxTimestampMs {
+			ref, copiedLabels = appendCreatedTimestampZeroSample(app, ref, copiedLabels, nonCopiedLabels, ts.TimeSeries, nativeHistogramsIngestionEnabled)
+		}
+
 		for _, s := range ts.Samples {
 			var err error
 
@@ -1163,6 +1266,10 @@ func (i *Ingester) pushSamplesToAppender(userID string, timeseries []mimirpb.Pre
 				}
 			}
 
+			if addToOverflowSeries(err, s, nonCopiedLabels, hash) {
+				continue
+			}
+
 			// If it's a soft error it will be returned back to the distributor later as a 400.
 			if handleAppendError(err, s.TimestampMs, ts.Labels) {
 				continue
@@ -1186,6 +1293,12 @@ func (i *Ingester) pushSamplesToAppender(userID string, timeseries []mimirpb.Pre
 					continue
 				}
 
+				// The TSDB can't store the custom values, and it would interpret the buckets as exponential ones.
+				if h.UsesCustomBuckets() {
+					handleAppendError(globalerror.InvalidNativeHistogramSchema, h.Timestamp, ts.Labels)
+					continue
+				}
+
 				if h.IsFloatHistogram() {
 					fh = mimirpb.FromFloatHistogramProtoToFloatHistogram(&h)
 				} else {
@@ -1275,9 +1388,72 @@ func (i *Ingester) pushSamplesToAppender(userID string, timeseries []mimirpb.Pre
 			}
 		}
 	}
+
+	// The sums of the overflow series are appended once per request, at the latest timestamp of the samples aggregated
+	// into them, so that the samples with the same timestamp of all the aggregated series are part of the same sum.
+	for a, u := range overflowUpdates {
+		ref, err := a.appendSum(app, u.ts)
+		if err != nil {
+			overflowLabels := mimirpb.FromLabelsToLabelAdapters(a.labels)
+			for n := 0; n < u.perUserSeriesLimit+u.perMetricSeriesLimit; n++ {
+				if !handleAppendError(err, u.ts, overflowLabels) {
+					return err
+				}
+			}
+			continue
+		}
+
+		stats.succeededSamplesCount += u.perUserSeriesLimit + u.perMetricSeriesLimit
+		stats.perUserSeriesLimitOverflowCount += u.perUserSeriesLimit
+		stats.perMetricSeriesLimitOverflowCount += u.perMetricSeriesLimit
+		if activeSeries != nil {
+			activeSeries.UpdateSeries(a.labels, ref, startAppend, -1)
+		}
+	}
 	return nil
 }
 
+// appendCreatedTimestampZeroSample appends a zero sample at the created timestamp of the series, before its
+// first sample, like Prometheus does when ingesting created timestamps. Since clients send the created timestamp
+// along with every sample of a series, appending the zero sample is expected to fail once the series already has
+// samples after the created timestamp, so append errors are ignored and the zero sample isn't tracked in the stats.
+// It returns the series reference and labels, which are set once the series has been created by the zero sample.
+func appendCreatedTimestampZeroSample(app extendedAppender, ref storage.SeriesRef, copiedLabels, nonCopiedLabels labels.Labels, ts *mimirpb.TimeSeries, nativeHistogramsIngestionEnabled bool) (storage.SeriesRef, labels.Labels) {
+	var (
+		ih  *histogram.Histogram
+		fh  *histogram.FloatHistogram
+		err error
+	)
+
+	switch {
+	case len(ts.Samples) > 0 && ts.CreatedTimestamp < ts.Samples[0].TimestampMs:
+	case nativeHistogramsIngestionEnabled && len(ts.Samples) == 0 && len(ts.Histograms) > 0 && ts.CreatedTimestamp < ts.Histograms[0].Timestamp:
+		if ts.Histograms[0].IsFloatHistogram() {
+			fh = &histogram.FloatHistogram{}
+		} else {
+			ih = &histogram.Histogram{}
+		}
+	default:
+		return ref, copiedLabels
+	}
+
+	if ref == 0 {
+		// Copy the label set because both TSDB and the active series tracker may retain it.
+		copiedLabels = mimirpb.CopyLabels(nonCopiedLabels)
+	}
+
+	var newRef storage.SeriesRef
+	if ih != nil || fh != nil {
+		newRef, err = app.AppendHistogram(ref, copiedLabels, ts.CreatedTimestamp, ih, fh)
+	} else {
+		newRef, err = app.Append(ref, copiedLabels, ts.CreatedTimestamp, 0)
+	}
+	if err == nil && ref == 0 {
+		return newRef, copiedLabels
+	}
+	return ref, copiedLabels
+}
+
 func (i *Ingester) QueryExemplars(ctx context.Context, req *client.ExemplarQueryRequest) (*client.ExemplarQueryResponse, error) {
 	if err := i.checkRunning(); err != nil {
 		return nil, err
@@ -2203,8 +2379,10 @@ func (i *Ingester) createTSDB(userID string, walReplayConcurrency int) (*userTSD
 
 	userDB := &userTSDB{
 		userID:              userID,
-		activeSeries:        activeseries.NewActiveSeries(activeseries.NewMatchers(matchersConfig), i.cfg.ActiveSeriesMetrics.IdleTimeout),
+		activeSeries:        activeseries.NewActiveSeries(activeseries.NewMatchers(matchersConfig), i.cfg.ActiveSeriesMetrics.IdleTimeout, i.costAttribution.Tracker(userID)),
 		seriesInMetric:      newMetricCounter(i.limiter, i.cfg.getIgnoreSeriesLimitForMetricNamesMap()),
+		overflowSeries:      newOverflowSeries(),
+		seriesChurn:         newSeriesChurn(i.cfg.SeriesChurnTrackingWindow),
 		ingestedAPISamples:  util_math.NewEWMARate(0.2, i.cfg.RateUpdatePeriod),
 		ingestedRuleSamples: util_math.NewEWMARate(0.2, i.cfg.RateUpdatePeriod),
 		instanceLimitsFn:    i.getInstanceLimits,
@@ -2286,6 +2464,11 @@ func (i *Ingester) createTSDB(userID string, walReplayConcurrency int) (*userTSD
 
 	// Create a new shipper for this database
 	if i.cfg.BlocksStorageConfig.TSDB.IsBlocksShippingEnabled() {
+		var shipperExemplars storage.ExemplarQueryable
+		if i.cfg.BlocksStorageConfig.TSDB.ShipExemplarsEnabled {
+			shipperExemplars = db
+		}
+
 		userDB.shipper = newShipper(
 			userLogger,
 			i.limits,
@@ -2294,6 +2477,7 @@ func (i *Ingester) createTSDB(userID string, walReplayConcurrency int) (*userTSD
 			udir,
 			bucket.NewUserBucketClient(userID, i.bucket, i.limits),
 			block.ReceiveSource,
+			shipperExemplars,
 		)
 
 		// Initialise the shipper blocks cache.
@@ -827,6 +827,73 @@ func TestIngester_Push(t *testing.T) {
 				cortex_ingester_tsdb_head_max_timestamp_seconds ` + fmt.Sprintf("%g", float64(now.UnixMilli())/1000) + `
 			`,
 		},
+		"should soft fail on native histograms with custom buckets in a write request": {
+			nativeHistograms: true,
+			reqs: []*mimirpb.WriteRequest{
+				{
+					Timeseries: []mimirpb.PreallocTimeseries{
+						{
+							TimeSeries: &mimirpb.TimeSeries{
+								Labels: metricLabelAdapters,
+								Histograms: []mimirpb.Histogram{
+									mimirpb.FromHistogramToHistogramProto(now.UnixMilli(), util_test.GenerateTestHistogram(0)),
+									func() mimirpb.Histogram {
+										h := mimirpb.FromHistogramToHistogramProto(now.UnixMilli()+1, util_test.GenerateTestHistogram(1))
+										h.Schema = mimirpb.CustomBucketsSchema
+										h.NegativeSpans, h.NegativeDeltas = nil, nil
+										h.CustomValues = []float64{1, 2, 3, 4, 5}
+										return h
+									}()},
+							},
+						},
+					},
+				},
+			},
+			expectedErr: newErrorWithStatus(wrapOrAnnotateWithUser(newNativeHistogramCustomBucketsUnsupportedError(model.Time(now.UnixMilli()+1), metricLabelAdapters), userID), codes.FailedPrecondition),
+			expectedIngested: model.Matrix{
+				&model.SampleStream{Metric: metricLabelSet, Histograms: []model.SampleHistogramPair{
+					{Timestamp: model.Time(now.UnixMilli()), Histogram: mimirpb.FromHistogramToPromHistogram(util_test.GenerateTestGaugeHistogram(0))},
+				}},
+			},
+			expectedMetrics: `
+				# HELP cortex_ingester_ingested_samples_total The total number of samples ingested per user.
+				# TYPE cortex_ingester_ingested_samples_total counter
+				cortex_ingester_ingested_samples_total{user="test"} 1
+				# HELP cortex_ingester_ingested_samples_failures_total The total number of samples that errored on ingestion per user.
+				# TYPE cortex_ingester_ingested_samples_failures_total counter
+				cortex_ingester_ingested_samples_failures_total{user="test"} 1
+				# HELP cortex_ingester_memory_users The current number of users in memory.
+				# TYPE cortex_ingester_memory_users gauge
+				cortex_ingester_memory_users 1
+				# HELP cortex_ingester_memory_series The current number of series in memory.
+				# TYPE cortex_ingester_memory_series gauge
+				cortex_ingester_memory_series 1
+				# HELP cortex_ingester_memory_series_created_total The total number of series that were created per user.
+				# TYPE cortex_ingester_memory_series_created_total counter
+				cortex_ingester_memory_series_created_total{user="test"} 1
+				# HELP cortex_ingester_memory_series_removed_total The total number of series that were removed per user.
+				# TYPE cortex_ingester_memory_series_removed_total counter
+				cortex_ingester_memory_series_removed_total{user="test"} 0
+				# HELP cortex_discarded_samples_total The total number of samples that were discarded.
+				# TYPE cortex_discarded_samples_total counter
+				cortex_discarded_samples_total{group="",reason="invalid-native-histogram-schema",user="test"} 1
+				# HELP cortex_ingester_active_series Number of currently active series per user.
+				# TYPE cortex_ingester_active_series gauge
+				cortex_ingester_active_series{user="test"} 1
+				# HELP cortex_ingester_active_native_histogram_buckets Number of currently active native histogram buckets per user.
+				# TYPE cortex_ingester_active_native_histogram_buckets gauge
+				cortex_ingester_active_native_histogram_buckets{user="test"} 4
+				# HELP cortex_ingester_active_native_histogram_series Number of currently active native histogram series per user.
+				# TYPE cortex_ingester_active_native_histogram_series gauge
+				cortex_ingester_active_native_histogram_series{user="test"} 1
+				# HELP cortex_ingester_tsdb_head_min_timestamp_seconds Minimum timestamp of the head block across all tenants.
+				# TYPE cortex_ingester_tsdb_head_min_timestamp_seconds gauge
+				cortex_ingester_tsdb_head_min_timestamp_seconds ` + fmt.Sprintf("%g", float64(now.UnixMilli())/1000) + `
+				# HELP cortex_ingester_tsdb_head_max_timestamp_seconds Maximum timestamp of the head block across all tenants.
+				# TYPE cortex_ingester_tsdb_head_max_timestamp_seconds gauge
+				cortex_ingester_tsdb_head_max_timestamp_seconds ` + fmt.Sprintf("%g", float64(now.UnixMilli())/1000) + `
+			`,
+		},
 		"should soft fail on some exemplars with timestamp too far in future in a write request": {
 			maxExemplars: 1,
 			reqs: []*mimirpb.WriteRequest{
@@ -4028,7 +4095,7 @@ func prepareIngesterWithBlockStorageAndOverrides(t testing.TB, ingesterCfg Confi
 	// Disable TSDB head compaction jitter to have predictable tests.
 	ingesterCfg.BlocksStorageConfig.TSDB.HeadCompactionIntervalJitterEnabled = false
 
-	ingester, err := New(ingesterCfg, overrides, nil, registerer, noDebugNoopLogger{})
+	ingester, err := New(ingesterCfg, overrides, nil, nil, registerer, noDebugNoopLogger{})
 	if err != nil {
 		return nil, err
 	}
@@ -4222,7 +4289,7 @@ func TestIngester_OpenExistingTSDBOnStartup(t *testing.T) {
 			// setup the tsdbs dir
 			testData.setup(t, tempDir)
 
-			ingester, err := New(ingesterCfg, overrides, nil, nil, log.NewNopLogger())
+			ingester, err := New(ingesterCfg, overrides, nil, nil, nil, log.NewNopLogger())
 			require.NoError(t, err)
 
 			startErr := services.StartAndAwaitRunning(context.Background(), ingester)
@@ -5362,7 +5429,7 @@ func TestHeadCompactionOnStartup(t *testing.T) {
 	ingesterCfg.BlocksStorageConfig.Bucket.S3.Endpoint = "localhost"
 	ingesterCfg.BlocksStorageConfig.TSDB.Retention = 2 * 24 * time.Hour // Make sure that no newly created blocks are deleted.
 
-	ingester, err := New(ingesterCfg, overrides, nil, nil, log.NewNopLogger())
+	ingester, err := New(ingesterCfg, overrides, nil, nil, nil, log.NewNopLogger())
 	require.NoError(t, err)
 	require.NoError(t, services.StartAndAwaitRunning(context.Background(), ingester))
 
@@ -6691,6 +6758,45 @@ func TestIngester_Push_SeriesWithBlankLabel(t *testing.T) {
 	assert.Equal(t, expected, res)
 }
 
+func TestIngester_Push_ShouldIngestZeroSampleAtCreatedTimestamp(t *testing.T) {
+	ing, err := prepareIngesterWithBlocksStorageAndLimits(t, defaultIngesterTestConfig(t), defaultLimitsTestConfig(), "", nil)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), ing))
+	defer services.StopAndAwaitTerminated(context.Background(), ing) //nolint:errcheck
+
+	// Wait until the ingester is healthy
+	test.Poll(t, 100*time.Millisecond, 1, func() interface{} {
+		return ing.lifecycler.HealthyInstancesCount()
+	})
+
+	ctx := user.InjectOrgID(context.Background(), userID)
+	lbls := [][]mimirpb.LabelAdapter{{{Name: labels.MetricName, Value: "testmetric"}}}
+
+	// Clients send the created timestamp along with every sample, so the zero sample is appended only once.
+	for _, s := range []mimirpb.Sample{{TimestampMs: 1000, Value: 5}, {TimestampMs: 2000, Value: 6}} {
+		req := mimirpb.ToWriteRequest(lbls, []mimirpb.Sample{s}, nil, nil, mimirpb.API)
+		req.Timeseries[0].CreatedTimestamp = 500
+
+		_, err = ing.Push(ctx, req)
+		require.NoError(t, err)
+	}
+
+	res, _, err := runTestQuery(ctx, t, ing, labels.MatchEqual, labels.MetricName, "testmetric")
+	require.NoError(t, err)
+
+	expected := model.Matrix{
+		{
+			Metric: model.Metric{labels.MetricName: "testmetric"},
+			Values: []model.SamplePair{
+				{Timestamp: 500, Value: 0},
+				{Timestamp: 1000, Value: 5},
+				{Timestamp: 2000, Value: 6},
+			},
+		},
+	}
+	assert.Equal(t, expected, res)
+}
+
 func TestIngesterUserLimitExceeded(t *testing.T) {
 	limits := defaultLimitsTestConfig()
 	limits.MaxGlobalSeriesPerUser = 1
@@ -6899,6 +7005,72 @@ func TestIngesterMetricLimitExceeded(t *testing.T) {
 	testLimits()
 }
 
+func TestIngester_SeriesLimitOverflow(t *testing.T) {
+	limits := defaultLimitsTestConfig()
+	limits.MaxGlobalSeriesPerMetric = 1
+	limits.SeriesLimitOverflowEnabled = true
+
+	cfg := defaultIngesterTestConfig(t)
+	// Set RF=1 here to ensure the series limit is actually set to 1 instead of 3.
+	cfg.IngesterRing.ReplicationFactor = 1
+
+	reg := prometheus.NewPedanticRegistry()
+	ing, err := prepareIngesterWithBlocksStorageAndLimits(t, cfg, limits, "", reg)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), ing))
+	defer services.StopAndAwaitTerminated(context.Background(), ing) //nolint:errcheck
+
+	// Wait until it's healthy
+	test.Poll(t, time.Second, 1, func() interface{} {
+		return ing.lifecycler.HealthyInstancesCount()
+	})
+
+	ctx := user.InjectOrgID(context.Background(), "1")
+	series := func(foo string) []mimirpb.LabelAdapter {
+		return []mimirpb.LabelAdapter{{Name: labels.MetricName, Value: "testmetric_total"}, {Name: "foo", Value: foo}}
+	}
+	gauge := func(foo string) []mimirpb.LabelAdapter {
+		return []mimirpb.LabelAdapter{{Name: labels.MetricName, Value: "testgauge"}, {Name: "foo", Value: foo}}
+	}
+
+	// The first series is within the limit, while the others are aggregated into the overflow series.
+	_, err = ing.Push(ctx, mimirpb.ToWriteRequest([][]mimirpb.LabelAdapter{series("bar")}, []mimirpb.Sample{{TimestampMs: 1000, Value: 1}}, nil, nil, mimirpb.API))
+	require.NoError(t, err)
+	_, err = ing.Push(ctx, mimirpb.ToWriteRequest([][]mimirpb.LabelAdapter{series("biz"), series("baz")}, []mimirpb.Sample{{TimestampMs: 1000, Value: 3}, {TimestampMs: 1000, Value: 4}}, nil, nil, mimirpb.API))
+	require.NoError(t, err)
+	_, err = ing.Push(ctx, mimirpb.ToWriteRequest([][]mimirpb.LabelAdapter{series("biz")}, []mimirpb.Sample{{TimestampMs: 2000, Value: 5}}, nil, nil, mimirpb.API))
+	require.NoError(t, err)
+
+	// The gauges exceeding the limits are discarded.
+	_, err = ing.Push(ctx, mimirpb.ToWriteRequest([][]mimirpb.LabelAdapter{gauge("bar")}, []mimirpb.Sample{{TimestampMs: 1000, Value: 1}}, nil, nil, mimirpb.API))
+	require.NoError(t, err)
+	_, err = ing.Push(ctx, mimirpb.ToWriteRequest([][]mimirpb.LabelAdapter{gauge("biz")}, []mimirpb.Sample{{TimestampMs: 1000, Value: 2}}, nil, nil, mimirpb.API))
+	require.Error(t, err)
+
+	res, _, err := runTestQuery(ctx, t, ing, labels.MatchEqual, model.MetricNameLabel, "testmetric_total")
+	require.NoError(t, err)
+	assert.Equal(t, model.Matrix{
+		{
+			Metric: mimirpb.FromLabelAdaptersToMetric(series("bar")),
+			Values: []model.SamplePair{{Timestamp: 1000, Value: 1}},
+		},
+		{
+			// The overflow series is a counter of the increases of the aggregated series.
+			Metric: model.Metric{model.MetricNameLabel: "testmetric_total", validation.SeriesLimitOverflowLabel: "true"},
+			Values: []model.SamplePair{{Timestamp: 1000, Value: 0}, {Timestamp: 2000, Value: 2}},
+		},
+	}, res)
+
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+		# HELP cortex_ingester_series_limit_overflow_samples_total The total number of samples of series exceeding the series limits which have been aggregated into overflow series, per limit.
+		# TYPE cortex_ingester_series_limit_overflow_samples_total counter
+		cortex_ingester_series_limit_overflow_samples_total{reason="per_metric_series_limit",user="1"} 3
+		# HELP cortex_discarded_samples_total The total number of samples that were discarded.
+		# TYPE cortex_discarded_samples_total counter
+		cortex_discarded_samples_total{group="",reason="per_metric_series_limit",user="1"} 1
+	`), "cortex_ingester_series_limit_overflow_samples_total", "cortex_discarded_samples_total"))
+}
+
 // Construct a set of realistic-looking samples, all with slightly different label sets
 func benchmarkData(nSeries int) (allLabels [][]mimirpb.LabelAdapter, allSamples []mimirpb.Sample) {
 	// Real example from Kubernetes' embedded cAdvisor metrics, lightly obfuscated.
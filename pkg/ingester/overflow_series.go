@@ -0,0 +1,217 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package ingester
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"go.uber.org/atomic"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+	"github.com/grafana/mimir/pkg/util/validation"
+)
+
+// overflowMemberStaleness is how long a series aggregated into an overflow series is tracked after it stops receiving
+// samples. It matches the default PromQL lookback delta.
+const overflowMemberStaleness = 5 * time.Minute
+
+// overflowSeriesLabelValue is the value of the validation.SeriesLimitOverflowLabel label of the overflow series.
+const overflowSeriesLabelValue = "true"
+
+// counterMetricNameSuffixes are the suffixes of the names of the counter metrics, by the Prometheus naming conventions.
+var counterMetricNameSuffixes = []string{"_total", "_count", "_sum", "_bucket"}
+
+// overflowSeries aggregates the float samples of the counter series which can't be created because of the series
+// limits into overflow series. An overflow series is a counter of the increases of the series aggregated into it, so
+// that the rates over a metric stay correct even when some of its series exceed the limits. Since the increases of
+// gauges are meaningless, only the series whose metric name has the suffix of a counter are aggregated.
+//
+// The overflow series have the same labels in all the ingesters, so that queries deduplicate their replicas like the
+// replicas of any other series. Each ingester only aggregates the series it received, so the replicas only have the
+// same samples when the series aggregated into an overflow series are owned by the same ingesters.
+type overflowSeries struct {
+	mtx        sync.RWMutex
+	aggregates map[uint64]*overflowAggregate // Keyed by the hash of the overflow series labels.
+
+	numMembers atomic.Int64 // The number of series aggregated into all the overflow series.
+}
+
+// overflowAggregate is an overflow series, with the latest values of the series aggregated into it.
+type overflowAggregate struct {
+	labels labels.Labels
+
+	mtx     sync.Mutex
+	deleted bool // Whether the overflow series has been purged, in which case it must not be updated anymore.
+	ref     storage.SeriesRef
+	sum     float64 // The sum of the increases of the aggregated series.
+	lastTs  int64
+	members map[uint64]*overflowMember // Keyed by the hash of the labels of the aggregated series.
+}
+
+type overflowMember struct {
+	value float64
+	ts    int64
+}
+
+func newOverflowSeries() *overflowSeries {
+	return &overflowSeries{aggregates: map[uint64]*overflowAggregate{}}
+}
+
+// overflowSeriesLabels returns the labels of the overflow series the series is aggregated into.
+func overflowSeriesLabels(series labels.Labels, dropLabels []string) labels.Labels {
+	if len(dropLabels) == 0 {
+		return labels.FromStrings(labels.MetricName, series.Get(labels.MetricName), validation.SeriesLimitOverflowLabel, overflowSeriesLabelValue)
+	}
+
+	b := labels.NewBuilder(series)
+	b.Del(dropLabels...)
+	b.Set(validation.SeriesLimitOverflowLabel, overflowSeriesLabelValue)
+	return b.Labels()
+}
+
+// isCounterSeries returns whether the metric name of the series has the suffix of a counter.
+func isCounterSeries(series labels.Labels) bool {
+	name := series.Get(labels.MetricName)
+	for _, suffix := range counterMetricNameSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isOverflowSeries returns whether the series is one of the overflow series, which aren't subject to the series limits.
+func (o *overflowSeries) isOverflowSeries(series labels.Labels) bool {
+	if !series.Has(validation.SeriesLimitOverflowLabel) {
+		return false
+	}
+
+	o.mtx.RLock()
+	defer o.mtx.RUnlock()
+
+	a, ok := o.aggregates[series.Hash()]
+	return ok && labels.Equal(a.labels, series)
+}
+
+// add aggregates the sample of the input series, identified by seriesHash, into its overflow series, which is returned.
+// The updated sum of the overflow series must then be appended with appendSum. It returns false, without aggregating
+// the sample, if the series isn't a counter, or if it would exceed maxSeries overflow series or maxMembers aggregated
+// series, 0 meaning no limit.
+func (o *overflowSeries) add(series labels.Labels, seriesHash uint64, dropLabels []string, maxSeries, maxMembers int, ts int64, value float64) (*overflowAggregate, bool) {
+	if !isCounterSeries(series) {
+		return nil, false
+	}
+	lbls := overflowSeriesLabels(series, dropLabels)
+
+	var a *overflowAggregate
+	for {
+		if a = o.getOrCreate(lbls, maxSeries); a == nil {
+			return nil, false
+		}
+		a.mtx.Lock()
+		if !a.deleted {
+			break
+		}
+		a.mtx.Unlock()
+	}
+	defer a.mtx.Unlock()
+
+	m := a.members[seriesHash]
+	switch {
+	case m == nil:
+		if maxMembers > 0 && o.numMembers.Load() >= int64(maxMembers) {
+			return nil, false
+		}
+		// The increase of the series before its first aggregated sample isn't known, so the sample is its baseline.
+		a.members[seriesHash] = &overflowMember{value: value, ts: ts}
+		o.numMembers.Inc()
+	case ts > m.ts:
+		if value >= m.value {
+			a.sum += value - m.value
+		} else {
+			// The series has been reset.
+			a.sum += value
+		}
+		m.value, m.ts = value, ts
+	}
+	return a, true
+}
+
+// appendSum appends the sum of the overflow series at ts to app, and returns the reference of the overflow series.
+// If the overflow series already has a sample at or after ts, the sum is only reflected by the next appended sample.
+func (a *overflowAggregate) appendSum(app storage.Appender, ts int64) (storage.SeriesRef, error) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	if ts <= a.lastTs {
+		return a.ref, nil
+	}
+
+	ref, err := app.Append(a.ref, a.labels, ts, a.sum)
+	if err != nil {
+		return a.ref, err
+	}
+	a.ref, a.lastTs = ref, ts
+	return a.ref, nil
+}
+
+// getOrCreate returns the overflow series with the input labels, creating it unless there are already maxSeries
+// overflow series, in which case it returns nil.
+func (o *overflowSeries) getOrCreate(lbls labels.Labels, maxSeries int) *overflowAggregate {
+	hash := lbls.Hash()
+
+	o.mtx.RLock()
+	a := o.aggregates[hash]
+	o.mtx.RUnlock()
+	if a != nil {
+		return a
+	}
+
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	if a = o.aggregates[hash]; a == nil {
+		if maxSeries > 0 && len(o.aggregates) >= maxSeries {
+			return nil
+		}
+		// Copy the labels, as they could reference a request buffer.
+		a = &overflowAggregate{labels: mimirpb.CopyLabels(lbls), members: map[uint64]*overflowMember{}}
+		o.aggregates[hash] = a
+	}
+	return a
+}
+
+// purge stops tracking the series aggregated into the overflow series without samples since the deadline, and
+// removes the overflow series without aggregated series. The increases of the purged series stay in the sums.
+func (o *overflowSeries) purge(deadline int64) {
+	o.mtx.RLock()
+	aggregates := make(map[uint64]*overflowAggregate, len(o.aggregates))
+	for hash, a := range o.aggregates {
+		aggregates[hash] = a
+	}
+	o.mtx.RUnlock()
+
+	// The lock of the overflow series can't be taken while holding o.mtx, because appending to an overflow
+	// series, while holding its lock, can check whether a series is an overflow series.
+	for hash, a := range aggregates {
+		a.mtx.Lock()
+		for memberHash, m := range a.members {
+			if m.ts < deadline {
+				delete(a.members, memberHash)
+				o.numMembers.Dec()
+			}
+		}
+		if len(a.members) == 0 {
+			a.deleted = true
+
+			o.mtx.Lock()
+			delete(o.aggregates, hash)
+			o.mtx.Unlock()
+		}
+		a.mtx.Unlock()
+	}
+}
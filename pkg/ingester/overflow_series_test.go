@@ -0,0 +1,138 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package ingester
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverflowSeriesLabels(t *testing.T) {
+	series := labels.FromStrings(labels.MetricName, "http_requests_total", "job", "api", "path", "/users/1", "user_agent", "curl")
+
+	assert.Equal(t, labels.FromStrings(labels.MetricName, "http_requests_total", "__mimir_overflow__", "true"), overflowSeriesLabels(series, nil))
+	assert.Equal(t, labels.FromStrings(labels.MetricName, "http_requests_total", "__mimir_overflow__", "true", "job", "api"), overflowSeriesLabels(series, []string{"path", "user_agent"}))
+}
+
+func TestIsCounterSeries(t *testing.T) {
+	for name, expected := range map[string]bool{
+		"http_requests_total":                    true,
+		"http_request_duration_seconds_count":    true,
+		"http_request_duration_seconds_sum":      true,
+		"http_request_duration_seconds_bucket":   true,
+		"process_resident_memory_bytes":          false,
+		"http_requests_in_flight":                false,
+		"http_request_duration_seconds_totalish": false,
+	} {
+		assert.Equal(t, expected, isCounterSeries(labels.FromStrings(labels.MetricName, name)), name)
+	}
+}
+
+func TestOverflowSeries(t *testing.T) {
+	o := newOverflowSeries()
+	app := &overflowTestAppender{}
+
+	series1 := labels.FromStrings(labels.MetricName, "requests_total", "instance", "1")
+	series2 := labels.FromStrings(labels.MetricName, "requests_total", "instance", "2")
+	overflowLabels := overflowSeriesLabels(series1, nil)
+
+	add := func(series labels.Labels, ts int64, value float64) *overflowAggregate {
+		a, ok := o.add(series, series.Hash(), nil, 0, 0, ts, value)
+		require.True(t, ok)
+		return a
+	}
+
+	// The first samples of the series are their baselines.
+	a := add(series1, 1000, 10)
+	require.Same(t, a, add(series2, 1000, 20))
+	assert.True(t, o.isOverflowSeries(overflowLabels))
+	assert.False(t, o.isOverflowSeries(series1))
+
+	ref, err := a.appendSum(app, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, storage.SeriesRef(1), ref)
+
+	// An older sample of a series doesn't change the sum, and the sum isn't appended again for the same timestamp.
+	add(series1, 500, 100)
+	_, err = a.appendSum(app, 1000)
+	require.NoError(t, err)
+
+	add(series1, 2000, 15)
+	add(series2, 2000, 22)
+	_, err = a.appendSum(app, 2000)
+	require.NoError(t, err)
+
+	// The reset of a series adds its new value to the sum.
+	add(series1, 3000, 3)
+	_, err = a.appendSum(app, 3000)
+	require.NoError(t, err)
+
+	// The increases of the series without samples since the deadline stay in the sum.
+	o.purge(2500)
+	assert.Equal(t, int64(1), o.numMembers.Load())
+	add(series1, 4000, 4)
+	_, err = a.appendSum(app, 4000)
+	require.NoError(t, err)
+
+	assert.Equal(t, []overflowTestSample{
+		{labels: overflowLabels, ts: 1000, value: 0},
+		{labels: overflowLabels, ts: 2000, value: 7},
+		{labels: overflowLabels, ts: 3000, value: 10},
+		{labels: overflowLabels, ts: 4000, value: 11},
+	}, app.samples)
+
+	// The overflow series without aggregated series are removed.
+	o.purge(5000)
+	assert.False(t, o.isOverflowSeries(overflowLabels))
+	assert.Equal(t, int64(0), o.numMembers.Load())
+	assert.NotSame(t, a, add(series1, 6000, 1))
+
+	// Gauges aren't aggregated.
+	_, ok := o.add(labels.FromStrings(labels.MetricName, "temperature_celsius"), 0, nil, 0, 0, 6000, 1)
+	assert.False(t, ok)
+}
+
+func TestOverflowSeries_Limits(t *testing.T) {
+	o := newOverflowSeries()
+	dropLabels := []string{"instance"}
+
+	series := func(job, instance string) labels.Labels {
+		return labels.FromStrings(labels.MetricName, "requests_total", "instance", instance, "job", job)
+	}
+	add := func(series labels.Labels) bool {
+		_, ok := o.add(series, series.Hash(), dropLabels, 2, 3, 1000, 1)
+		return ok
+	}
+
+	// The series of a third job would create a third overflow series.
+	assert.True(t, add(series("a", "1")))
+	assert.True(t, add(series("b", "1")))
+	assert.False(t, add(series("c", "1")))
+
+	// A fourth series would be aggregated into the existing overflow series, but there are already 3 aggregated series.
+	assert.True(t, add(series("a", "2")))
+	assert.False(t, add(series("a", "3")))
+
+	// The series already aggregated are still aggregated.
+	assert.True(t, add(series("a", "1")))
+}
+
+type overflowTestSample struct {
+	labels labels.Labels
+	ts     int64
+	value  float64
+}
+
+type overflowTestAppender struct {
+	storage.Appender
+	samples []overflowTestSample
+}
+
+func (a *overflowTestAppender) Append(_ storage.SeriesRef, l labels.Labels, t int64, v float64) (storage.SeriesRef, error) {
+	a.samples = append(a.samples, overflowTestSample{labels: l, ts: t, value: v})
+	return 1, nil
+}
@@ -0,0 +1,147 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package ingester
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// seriesChurnBucketDuration is the resolution of the series churn tracking.
+const seriesChurnBucketDuration = time.Minute
+
+// seriesChurn tracks the number of series created and removed per metric name over a sliding window, to find
+// the metrics driving the series churn of a tenant. The series churn is tracked by each ingester for the series
+// it owns, so it's only meant to debug a single ingester.
+type seriesChurn struct {
+	mtx     sync.Mutex
+	buckets []seriesChurnBucket // Ring buffer of per-minute buckets, indexed by the minute modulo its length.
+}
+
+type seriesChurnBucket struct {
+	minute  int64 // Unix minute of the bucket, used to detect expired buckets.
+	created map[string]int
+	removed map[string]int
+}
+
+// MetricSeriesChurn is the number of series of a metric created and removed over a period.
+type MetricSeriesChurn struct {
+	MetricName    string `json:"metric_name"`
+	CreatedSeries int    `json:"created_series"`
+	RemovedSeries int    `json:"removed_series"`
+}
+
+// newSeriesChurn returns a seriesChurn tracking the series churn over the window, or nil if the window is 0, in which
+// case the series churn isn't tracked.
+func newSeriesChurn(window time.Duration) *seriesChurn {
+	if window <= 0 {
+		return nil
+	}
+
+	n := int((window + seriesChurnBucketDuration - 1) / seriesChurnBucketDuration)
+	return &seriesChurn{buckets: make([]seriesChurnBucket, n)}
+}
+
+func (c *seriesChurn) created(metricName string, now time.Time) {
+	if c == nil {
+		return
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	b := c.bucket(now)
+	if b.created == nil {
+		b.created = map[string]int{}
+	}
+	b.created[metricName]++
+}
+
+func (c *seriesChurn) removed(metricNames []string, now time.Time) {
+	if c == nil || len(metricNames) == 0 {
+		return
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	b := c.bucket(now)
+	if b.removed == nil {
+		b.removed = map[string]int{}
+	}
+	for _, metricName := range metricNames {
+		b.removed[metricName]++
+	}
+}
+
+// bucket returns the bucket of now, resetting it if it holds an expired minute. Must be called with c.mtx held.
+func (c *seriesChurn) bucket(now time.Time) *seriesChurnBucket {
+	minute := now.Unix() / int64(seriesChurnBucketDuration/time.Second)
+	b := &c.buckets[minute%int64(len(c.buckets))]
+	if b.minute != minute {
+		*b = seriesChurnBucket{minute: minute}
+	}
+	return b
+}
+
+// forEachBucket calls f with the buckets of the last period, capped to the tracked window. Must be called with c.mtx held.
+func (c *seriesChurn) forEachBucket(now time.Time, period time.Duration, f func(b *seriesChurnBucket)) {
+	minute := now.Unix() / int64(seriesChurnBucketDuration/time.Second)
+	n := int64((period + seriesChurnBucketDuration - 1) / seriesChurnBucketDuration)
+	n = min(max(n, 1), int64(len(c.buckets)))
+
+	for i := range c.buckets {
+		b := &c.buckets[i]
+		if b.minute > minute-n && b.minute <= minute {
+			f(b)
+		}
+	}
+}
+
+// topMetrics returns the limit metric names with the most series created over the last period, capped to the
+// tracked window, sorted by the number of created series. If limit is 0, all the metric names are returned.
+func (c *seriesChurn) topMetrics(now time.Time, period time.Duration, limit int) []MetricSeriesChurn {
+	if c == nil {
+		return nil
+	}
+
+	c.mtx.Lock()
+	byMetric := map[string]*MetricSeriesChurn{}
+	get := func(metricName string) *MetricSeriesChurn {
+		m := byMetric[metricName]
+		if m == nil {
+			m = &MetricSeriesChurn{MetricName: metricName}
+			byMetric[metricName] = m
+		}
+		return m
+	}
+	c.forEachBucket(now, period, func(b *seriesChurnBucket) {
+		for metricName, n := range b.created {
+			get(metricName).CreatedSeries += n
+		}
+		for metricName, n := range b.removed {
+			get(metricName).RemovedSeries += n
+		}
+	})
+	c.mtx.Unlock()
+
+	result := make([]MetricSeriesChurn, 0, len(byMetric))
+	for _, m := range byMetric {
+		result = append(result, *m)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].CreatedSeries != result[j].CreatedSeries {
+			return result[i].CreatedSeries > result[j].CreatedSeries
+		}
+		if result[i].RemovedSeries != result[j].RemovedSeries {
+			return result[i].RemovedSeries > result[j].RemovedSeries
+		}
+		return result[i].MetricName < result[j].MetricName
+	})
+
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package ingester
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeriesChurn(t *testing.T) {
+	now := time.Date(2023, 1, 1, 10, 0, 30, 0, time.UTC)
+
+	c := newSeriesChurn(5 * time.Minute)
+
+	c.created("up", now.Add(-10*time.Minute)) // Out of the window.
+	c.created("up", now.Add(-4*time.Minute))
+	c.created("http_requests_total", now.Add(-2*time.Minute))
+	c.created("http_requests_total", now.Add(-time.Minute))
+	c.created("http_requests_total", now)
+	c.removed([]string{"up", "up", "node_cpu_seconds_total"}, now)
+
+	assert.Equal(t, []MetricSeriesChurn{
+		{MetricName: "http_requests_total", CreatedSeries: 3},
+		{MetricName: "up", CreatedSeries: 1, RemovedSeries: 2},
+		{MetricName: "node_cpu_seconds_total", RemovedSeries: 1},
+	}, c.topMetrics(now, 10*time.Minute, 0))
+
+	// The period is rounded up to the bucket duration.
+	assert.Equal(t, []MetricSeriesChurn{
+		{MetricName: "http_requests_total", CreatedSeries: 2},
+		{MetricName: "up", RemovedSeries: 2},
+		{MetricName: "node_cpu_seconds_total", RemovedSeries: 1},
+	}, c.topMetrics(now, 90*time.Second, 0))
+
+	assert.Equal(t, []MetricSeriesChurn{
+		{MetricName: "http_requests_total", CreatedSeries: 3},
+	}, c.topMetrics(now, 10*time.Minute, 1))
+
+	// The expired buckets are ignored, even before being reused.
+	assert.Empty(t, c.topMetrics(now.Add(10*time.Minute), 10*time.Minute, 0))
+}
+
+func TestSeriesChurn_Disabled(t *testing.T) {
+	c := newSeriesChurn(0)
+	require.Nil(t, c)
+
+	c.created("up", time.Now())
+	c.removed([]string{"up"}, time.Now())
+
+	assert.Nil(t, c.topMetrics(time.Now(), time.Minute, 0))
+}
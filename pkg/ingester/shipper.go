@@ -22,6 +22,8 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
 	"github.com/prometheus/prometheus/tsdb/fileutil"
 	"github.com/thanos-io/objstore"
 
@@ -69,11 +71,15 @@ type shipper struct {
 	metrics     *shipperMetrics
 	bucket      objstore.Bucket
 	source      block.SourceType
+
+	// exemplars is the storage of the exemplars shipped with the blocks, or nil if they're not shipped.
+	exemplars storage.ExemplarQueryable
 }
 
 // newShipper creates a new uploader that detects new TSDB blocks in dir and uploads them to
 // remote if necessary. It attaches the Thanos metadata section in each meta JSON file.
 // If uploadCompacted is enabled, it also uploads compacted blocks which are already in filesystem.
+// If exemplars is not nil, the exemplars of each block time range are stored in the block before uploading it.
 func newShipper(
 	logger log.Logger,
 	cfgProvider ShipperConfigProvider,
@@ -82,6 +88,7 @@ func newShipper(
 	dir string,
 	bucket objstore.Bucket,
 	source block.SourceType,
+	exemplars storage.ExemplarQueryable,
 ) *shipper {
 	if logger == nil {
 		logger = log.NewNopLogger()
@@ -95,6 +102,7 @@ func newShipper(
 		bucket:      bucket,
 		metrics:     metrics,
 		source:      source,
+		exemplars:   exemplars,
 	}
 }
 
@@ -197,10 +205,34 @@ func (s *shipper) upload(ctx context.Context, meta *block.Meta) error {
 		meta.Thanos.Labels[mimir_tsdb.OutOfOrderExternalLabel] = mimir_tsdb.OutOfOrderExternalLabelValue
 	}
 
+	if s.exemplars != nil {
+		if err := s.writeExemplars(ctx, blockDir, meta); err != nil {
+			return errors.Wrap(err, "write block exemplars")
+		}
+	}
+
 	// Upload block with custom metadata.
 	return block.Upload(ctx, s.logger, s.bucket, blockDir, meta)
 }
 
+// writeExemplars writes, in the block directory, the exemplars of the block time range which are still held in memory.
+// The exemplars of the block time range are written even if the block is an out-of-order one, because they're
+// deduplicated when compacted and queried.
+func (s *shipper) writeExemplars(ctx context.Context, blockDir string, meta *block.Meta) error {
+	q, err := s.exemplars.ExemplarQuerier(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The block max time is exclusive, while the exemplars querier one is inclusive.
+	res, err := q.Select(meta.MinTime, meta.MaxTime-1, []*labels.Matcher{labels.MustNewMatcher(labels.MatchRegexp, labels.MetricName, ".+")})
+	if err != nil {
+		return err
+	}
+
+	return block.WriteExemplarsFile(s.logger, blockDir, res)
+}
+
 // blockMetasFromOldest returns the block meta of each block found in dir
 // sorted by minTime asc.
 func (s *shipper) blockMetasFromOldest() (metas []*block.Meta, _ error) {
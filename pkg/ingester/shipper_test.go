@@ -20,6 +20,8 @@ import (
 	"github.com/grafana/dskit/concurrency"
 	"github.com/oklog/ulid"
 	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/tsdb"
 	"github.com/stretchr/testify/require"
 	"github.com/thanos-io/objstore"
@@ -51,7 +53,7 @@ func TestShipper(t *testing.T) {
 	logger := log.NewLogfmtLogger(logs)
 	overrides, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
 	require.NoError(t, err)
-	s := newShipper(logger, overrides, "", newShipperMetrics(nil), blocksDir, bkt, block.TestSource)
+	s := newShipper(logger, overrides, "", newShipperMetrics(nil), blocksDir, bkt, block.TestSource, nil)
 
 	t.Run("no shipper file yet", func(t *testing.T) {
 		// No shipper file = nothing is reported as shipped.
@@ -193,7 +195,7 @@ func TestShipper_DeceivingUploadErrors(t *testing.T) {
 	logger := log.NewLogfmtLogger(os.Stderr)
 	overrides, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
 	require.NoError(t, err)
-	s := newShipper(logger, overrides, "", newShipperMetrics(nil), blocksDir, bkt, block.TestSource)
+	s := newShipper(logger, overrides, "", newShipperMetrics(nil), blocksDir, bkt, block.TestSource, nil)
 
 	// Create and upload a block
 	id1 := ulid.MustNew(1, nil)
@@ -259,7 +261,7 @@ func TestIterBlockMetas(t *testing.T) {
 	}.WriteToDir(log.NewNopLogger(), path.Join(dir, id3.String())))
 	overrides, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
 	require.NoError(t, err)
-	shipper := newShipper(nil, overrides, "", newShipperMetrics(nil), dir, nil, block.TestSource)
+	shipper := newShipper(nil, overrides, "", newShipperMetrics(nil), dir, nil, block.TestSource, nil)
 	metas, err := shipper.blockMetasFromOldest()
 	require.NoError(t, err)
 	require.Equal(t, sort.SliceIsSorted(metas, func(i, j int) bool {
@@ -273,7 +275,7 @@ func TestShipperAddsSegmentFiles(t *testing.T) {
 	inmemory := objstore.NewInMemBucket()
 	overrides, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
 	require.NoError(t, err)
-	s := newShipper(nil, overrides, "", newShipperMetrics(nil), dir, inmemory, block.TestSource)
+	s := newShipper(nil, overrides, "", newShipperMetrics(nil), dir, inmemory, block.TestSource, nil)
 
 	id := ulid.MustNew(1, nil)
 	blockDir := path.Join(dir, id.String())
@@ -306,6 +308,56 @@ func TestShipperAddsSegmentFiles(t *testing.T) {
 	require.Equal(t, []string{segmentFile}, meta.Thanos.SegmentFiles)
 }
 
+func TestShipper_ShouldStoreTheBlockExemplars(t *testing.T) {
+	dir := t.TempDir()
+	bkt := objstore.NewInMemBucket()
+
+	exemplars, err := tsdb.NewCircularExemplarStorage(10, tsdb.NewExemplarMetrics(nil))
+	require.NoError(t, err)
+
+	series := labels.FromStrings(labels.MetricName, "series")
+	for _, ts := range []int64{500, 1000, 1500, 2000} {
+		require.NoError(t, exemplars.AddExemplar(series, exemplar.Exemplar{Labels: labels.FromStrings("trace_id", fmt.Sprint(ts)), Value: 1, Ts: ts, HasTs: true}))
+	}
+
+	overrides, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
+	require.NoError(t, err)
+	s := newShipper(nil, overrides, "", newShipperMetrics(nil), dir, bkt, block.TestSource, exemplars)
+
+	id := ulid.MustNew(1, nil)
+	createBlock(t, dir, id, block.Meta{
+		BlockMeta: tsdb.BlockMeta{
+			ULID:    id,
+			MinTime: 1000,
+			MaxTime: 2000,
+			Version: 1,
+			Stats:   tsdb.BlockStats{NumSamples: 100},
+		},
+	})
+
+	uploaded, err := s.Sync(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, uploaded)
+
+	meta, err := block.DownloadMeta(context.Background(), log.NewNopLogger(), bkt, id)
+	require.NoError(t, err)
+	require.True(t, meta.HasExemplars())
+
+	rc, err := bkt.Get(context.Background(), path.Join(id.String(), block.ExemplarsFilename))
+	require.NoError(t, err)
+	actual, err := block.ReadExemplars(rc)
+	require.NoError(t, err)
+
+	// Only the exemplars in the block time range are stored, the block max time being exclusive.
+	require.Equal(t, []exemplar.QueryResult{{
+		SeriesLabels: series,
+		Exemplars: []exemplar.Exemplar{
+			{Labels: labels.FromStrings("trace_id", "1000"), Value: 1, Ts: 1000},
+			{Labels: labels.FromStrings("trace_id", "1500"), Value: 1, Ts: 1500},
+		},
+	}}, actual)
+}
+
 func TestShipper_AddOOOLabel(t *testing.T) {
 	for _, tc := range []struct {
 		name                      string
@@ -417,7 +469,7 @@ func TestShipper_AddOOOLabel(t *testing.T) {
 			}
 			overrides, err := validation.NewOverrides(defaultLimitsTestConfig(), validation.NewMockTenantLimits(tenantLimits))
 			require.NoError(t, err)
-			s := newShipper(logger, overrides, "", newShipperMetrics(nil), blocksDir, bkt, block.TestSource)
+			s := newShipper(logger, overrides, "", newShipperMetrics(nil), blocksDir, bkt, block.TestSource, nil)
 
 			createBlock(t, blocksDir, tc.meta.ULID, tc.meta)
 
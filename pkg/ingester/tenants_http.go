@@ -8,9 +8,11 @@ import (
 	"html/template"
 	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/tsdb"
 	"golang.org/x/exp/slices"
 
@@ -52,6 +54,13 @@ type tenantTSDBHeadPageContent struct {
 	MaxOOOTime             string
 }
 
+type tenantSeriesChurnPageContent struct {
+	Now     time.Time
+	Tenant  string
+	Period  string
+	Metrics []MetricSeriesChurn
+}
+
 type tenantTSDBBlockPageContent struct {
 	ID         string
 	MinTime    string
@@ -66,6 +75,12 @@ type tenantTSDBBlockPageContent struct {
 var tenantTSDBPageHTML string
 var tenantTSDBTemplate = template.Must(template.New("webpage").Parse(tenantTSDBPageHTML))
 
+//go:embed tenant_series_churn.gohtml
+var tenantSeriesChurnPageHTML string
+var tenantSeriesChurnTemplate = template.Must(template.New("webpage").Parse(tenantSeriesChurnPageHTML))
+
+const defaultSeriesChurnMetricsLimit = 20
+
 func (i *Ingester) TenantsHandler(w http.ResponseWriter, req *http.Request) {
 	tenants := i.getTSDBUsers()
 	slices.Sort(tenants)
@@ -158,6 +173,60 @@ func (i *Ingester) TenantTSDBHandler(w http.ResponseWriter, req *http.Request) {
 	util.RenderHTTPResponse(w, c, tenantTSDBTemplate, req)
 }
 
+// TenantSeriesChurnHandler lists the metric names of the tenant with the most series created over the last minutes,
+// which default to the series churn tracking window. It's a debug page of this ingester, only accounting for the
+// series it owns.
+func (i *Ingester) TenantSeriesChurnHandler(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	tenant := vars["tenant"]
+	if tenant == "" {
+		util.WriteTextResponse(w, "Tenant ID can't be empty")
+		return
+	}
+
+	if i.cfg.SeriesChurnTrackingWindow <= 0 {
+		w.WriteHeader(http.StatusNotFound)
+		util.WriteTextResponse(w, "Series churn tracking is disabled, configure -ingester.series-churn-tracking-window to enable it")
+		return
+	}
+
+	period := i.cfg.SeriesChurnTrackingWindow
+	if v := req.FormValue("minutes"); v != "" {
+		minutes, err := strconv.Atoi(v)
+		if err != nil || minutes <= 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			util.WriteTextResponse(w, "minutes must be a positive integer")
+			return
+		}
+		period = min(time.Duration(minutes)*time.Minute, period)
+	}
+
+	limit := defaultSeriesChurnMetricsLimit
+	if v := req.FormValue("limit"); v != "" {
+		var err error
+		if limit, err = strconv.Atoi(v); err != nil || limit < 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			util.WriteTextResponse(w, "limit must be a non-negative integer")
+			return
+		}
+	}
+
+	db := i.getTSDB(tenant)
+	if db == nil {
+		w.WriteHeader(http.StatusNotFound)
+		util.WriteTextResponse(w, "TSDB not found for tenant "+tenant)
+		return
+	}
+
+	now := time.Now()
+	util.RenderHTTPResponse(w, tenantSeriesChurnPageContent{
+		Now:     now,
+		Tenant:  tenant,
+		Period:  model.Duration(period).String(),
+		Metrics: db.seriesChurn.topMetrics(now, period, limit),
+	}, tenantSeriesChurnTemplate, req)
+}
+
 func formatMillisTime(t int64) string {
 	switch t {
 	case 0:
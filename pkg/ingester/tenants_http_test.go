@@ -4,6 +4,7 @@ package ingester
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -13,6 +14,8 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/grafana/dskit/services"
 	"github.com/grafana/dskit/test"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/require"
 )
 
@@ -70,3 +73,71 @@ func TestIngester_TenantsHandlers(t *testing.T) {
 		require.Contains(t, rec.Body.String(), "TSDB not found for tenant unknown")
 	})
 }
+
+func TestIngester_TenantSeriesChurnHandler(t *testing.T) {
+	ctx := context.Background()
+	cfg := defaultIngesterTestConfig(t)
+	cfg.SeriesChurnTrackingWindow = 10 * time.Minute
+
+	i, err := prepareIngesterWithBlocksStorage(t, cfg, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, services.StartAndAwaitRunning(ctx, i))
+	defer services.StopAndAwaitTerminated(ctx, i) //nolint:errcheck
+
+	// Wait until it's healthy
+	test.Poll(t, 1*time.Second, 1, func() interface{} {
+		return i.lifecycler.HealthyInstancesCount()
+	})
+
+	now := time.Now().UnixMilli()
+	pushSingleSampleAtTime(t, i, now)
+	for _, pod := range []string{"a", "b"} {
+		req, _, _, _ := mockWriteRequest(t, labels.FromStrings(labels.MetricName, "http_requests_total", "pod", pod), 1, now)
+		_, err := i.Push(user.InjectOrgID(ctx, userID), req)
+		require.NoError(t, err)
+	}
+
+	t.Run("series churn", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "/churn?minutes=5", nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept", "application/json")
+
+		req = mux.SetURLVars(req, map[string]string{"tenant": userID})
+		i.TenantSeriesChurnHandler(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var content tenantSeriesChurnPageContent
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &content))
+		require.Equal(t, "5m", content.Period)
+		require.Equal(t, []MetricSeriesChurn{
+			{MetricName: "http_requests_total", CreatedSeries: 2},
+			{MetricName: "test", CreatedSeries: 1},
+		}, content.Metrics)
+	})
+
+	t.Run("series churn with invalid limit", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "/churn?limit=-1", nil)
+		require.NoError(t, err)
+
+		req = mux.SetURLVars(req, map[string]string{"tenant": userID})
+		i.TenantSeriesChurnHandler(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("series churn for unknown tenant", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "/churn", nil)
+		require.NoError(t, err)
+
+		req = mux.SetURLVars(req, map[string]string{"tenant": "unknown"})
+		i.TenantSeriesChurnHandler(rec, req)
+
+		require.Equal(t, http.StatusNotFound, rec.Code)
+		require.Contains(t, rec.Body.String(), "TSDB not found for tenant unknown")
+	})
+}
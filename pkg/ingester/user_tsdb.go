@@ -87,6 +87,8 @@ type userTSDB struct {
 	userID         string
 	activeSeries   *activeseries.ActiveSeries
 	seriesInMetric *metricCounter
+	overflowSeries *overflowSeries
+	seriesChurn    *seriesChurn
 	limiter        *Limiter
 
 	instanceSeriesCount *atomic.Int64 // Shared across all userTSDB instances created by ingester.
@@ -273,6 +275,11 @@ func (u *userTSDB) PreCreation(metric labels.Labels) error {
 		}
 	}
 
+	// The overflow series aggregate the series exceeding the series limits, so they're not subject to them.
+	if u.overflowSeries.isOverflowSeries(metric) {
+		return nil
+	}
+
 	// Total series limit.
 	if !u.limiter.IsWithinMaxSeriesPerUser(u.userID, int(u.Head().NumSeries())) {
 		return globalerror.MaxSeriesPerUser
@@ -299,11 +306,20 @@ func (u *userTSDB) PostCreation(metric labels.Labels) {
 		return
 	}
 	u.seriesInMetric.increaseSeriesForMetric(metricName)
+
+	// The series created while replaying the WAL, before the TSDB is opened, aren't churn.
+	if u.db != nil {
+		u.seriesChurn.created(metricName, time.Now())
+	}
 }
 
 func (u *userTSDB) PostDeletion(metrics map[chunks.HeadSeriesRef]labels.Labels) {
 	u.instanceSeriesCount.Sub(int64(len(metrics)))
 
+	var removedMetricNames []string
+	if u.seriesChurn != nil {
+		removedMetricNames = make([]string, 0, len(metrics))
+	}
 	for _, lbls := range metrics {
 		metricName, err := extract.MetricNameFromLabels(lbls)
 		if err != nil {
@@ -311,7 +327,11 @@ func (u *userTSDB) PostDeletion(metrics map[chunks.HeadSeriesRef]labels.Labels)
 			continue
 		}
 		u.seriesInMetric.decreaseSeriesForMetric(metricName)
+		if removedMetricNames != nil {
+			removedMetricNames = append(removedMetricNames, metricName)
+		}
 	}
+	u.seriesChurn.removed(removedMetricNames, time.Now())
 
 	u.activeSeries.PostDeletion(metrics)
 }
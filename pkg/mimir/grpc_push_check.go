@@ -80,7 +80,7 @@ func (g *grpcInflightMethodLimiter) RPCCallStarting(ctx context.Context, methodN
 		httpMethod := getSingleMetadata(md, httpgrpc.MetadataMethod)
 		httpURL := getSingleMetadata(md, httpgrpc.MetadataURL)
 
-		if httpMethod == http.MethodPost && (strings.HasSuffix(httpURL, api.PrometheusPushEndpoint) || strings.HasSuffix(httpURL, api.OTLPPushEndpoint)) {
+		if httpMethod == http.MethodPost && isPushEndpoint(httpURL) {
 			dist := g.getDistributor()
 			if dist == nil {
 				return ctx, errNoDistributor
@@ -131,3 +131,13 @@ func getSingleMetadata(md metadata.MD, key string) string {
 	}
 	return val[0]
 }
+
+// isPushEndpoint returns whether the URL is one of the distributor push endpoints. The URL can have
+// a query string, which is used to pass parameters to the InfluxDB endpoint.
+func isPushEndpoint(httpURL string) bool {
+	path, _, _ := strings.Cut(httpURL, "?")
+	return strings.HasSuffix(path, api.PrometheusPushEndpoint) ||
+		strings.HasSuffix(path, api.OTLPPushEndpoint) ||
+		strings.HasSuffix(path, api.InfluxPushEndpoint) ||
+		strings.HasSuffix(path, api.GraphitePushEndpoint)
+}
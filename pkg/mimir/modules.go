@@ -16,7 +16,6 @@ import (
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/grafana/dskit/dns"
-	httpgrpc_server "github.com/grafana/dskit/httpgrpc/server"
 	"github.com/grafana/dskit/kv/memberlist"
 	"github.com/grafana/dskit/modules"
 	"github.com/grafana/dskit/ring"
@@ -38,9 +37,11 @@ import (
 	"github.com/grafana/mimir/pkg/alertmanager/alertstore"
 	"github.com/grafana/mimir/pkg/api"
 	"github.com/grafana/mimir/pkg/compactor"
+	"github.com/grafana/mimir/pkg/costattribution"
 	"github.com/grafana/mimir/pkg/distributor"
 	"github.com/grafana/mimir/pkg/flusher"
 	"github.com/grafana/mimir/pkg/frontend"
+	"github.com/grafana/mimir/pkg/frontend/querylog"
 	"github.com/grafana/mimir/pkg/frontend/querymiddleware"
 	"github.com/grafana/mimir/pkg/frontend/transport"
 	"github.com/grafana/mimir/pkg/ingester"
@@ -74,6 +75,7 @@ const (
 	OverridesExporter          string = "overrides-exporter"
 	Server                     string = "server"
 	ActiveGroupsCleanupService string = "active-groups-cleanup-service"
+	CostAttributionService     string = "cost-attribution-service"
 	Distributor                string = "distributor"
 	DistributorService         string = "distributor-service"
 	Ingester                   string = "ingester"
@@ -88,6 +90,7 @@ const (
 	Ruler                      string = "ruler"
 	AlertManager               string = "alertmanager"
 	Compactor                  string = "compactor"
+	CompactorScheduler         string = "compactor-scheduler"
 	StoreGateway               string = "store-gateway"
 	MemberlistKV               string = "memberlist-kv"
 	QueryScheduler             string = "query-scheduler"
@@ -219,6 +222,7 @@ func (t *Mimir) initVault() (services.Service, error) {
 	t.Cfg.Ruler.QueryFrontend.GRPCClientConfig.TLS.Reader = t.Vault
 	t.Cfg.Alertmanager.AlertmanagerClient.GRPCClientConfig.TLS.Reader = t.Vault
 	t.Cfg.QueryScheduler.GRPCClientConfig.TLS.Reader = t.Vault
+	t.Cfg.Compactor.Scheduler.GRPCClientConfig.TLS.Reader = t.Vault
 
 	// Update the Server
 	updateServerTLSCfgFunc := func(vault *vault.Vault, tlsConfig *server.TLSConfig) error {
@@ -449,7 +453,7 @@ func (t *Mimir) initDistributorService() (serv services.Service, err error) {
 	t.Cfg.Distributor.MinimizeIngesterRequests = t.Cfg.Querier.MinimizeIngesterRequests
 	t.Cfg.Distributor.MinimiseIngesterRequestsHedgingDelay = t.Cfg.Querier.MinimiseIngesterRequestsHedgingDelay
 
-	t.Distributor, err = distributor.New(t.Cfg.Distributor, t.Cfg.IngesterClient, t.Overrides, t.ActiveGroupsCleanup, t.Ring, canJoinDistributorsRing, t.Registerer, util_log.Logger)
+	t.Distributor, err = distributor.New(t.Cfg.Distributor, t.Cfg.IngesterClient, t.Overrides, t.ActiveGroupsCleanup, t.CostAttribution, t.Ring, canJoinDistributorsRing, t.Registerer, util_log.Logger)
 	if err != nil {
 		return
 	}
@@ -559,6 +563,9 @@ func (t *Mimir) initQuerier() (serv services.Service, err error) {
 	t.Cfg.Worker.MaxConcurrentRequests = t.Cfg.Querier.EngineConfig.MaxConcurrent
 	t.Cfg.Worker.QuerySchedulerDiscovery = t.Cfg.QueryScheduler.ServiceDiscovery
 
+	// The blocks cardinality analysis is only available when the store queryable supports it.
+	blocksCardinality, _ := t.StoreQueryable.(querier.BlocksCardinalityQueryable)
+
 	// Create an internal HTTP handler that is configured with the Prometheus API routes and points
 	// to a Prometheus API struct instantiated with the Mimir Queryable.
 	internalQuerierRouter := api.NewQuerierHandler(
@@ -568,6 +575,7 @@ func (t *Mimir) initQuerier() (serv services.Service, err error) {
 		t.MetadataSupplier,
 		t.QuerierEngine,
 		t.Distributor,
+		blocksCardinality,
 		t.Registerer,
 		util_log.Logger,
 		t.Overrides,
@@ -609,7 +617,7 @@ func (t *Mimir) initQuerier() (serv services.Service, err error) {
 		return nil, nil
 	}
 
-	return querier_worker.NewQuerierWorker(t.Cfg.Worker, httpgrpc_server.NewServer(internalQuerierRouter), util_log.Logger, t.Registerer)
+	return querier_worker.NewQuerierWorker(t.Cfg.Worker, querier_worker.NewHTTPRequestHandler(internalQuerierRouter), util_log.Logger, t.Registerer)
 }
 
 func (t *Mimir) initStoreQueryable() (services.Service, error) {
@@ -628,6 +636,11 @@ func (t *Mimir) initActiveGroupsCleanupService() (services.Service, error) {
 	return t.ActiveGroupsCleanup, nil
 }
 
+func (t *Mimir) initCostAttributionService() (services.Service, error) {
+	t.CostAttribution = costattribution.NewManager(t.Cfg.Ingester.ActiveSeriesMetrics.IdleTimeout, t.Overrides, t.Registerer)
+	return t.CostAttribution, nil
+}
+
 func (t *Mimir) tsdbIngesterConfig() {
 	t.Cfg.Ingester.BlocksStorageConfig = t.Cfg.BlocksStorage
 }
@@ -638,7 +651,7 @@ func (t *Mimir) initIngesterService() (serv services.Service, err error) {
 	t.Cfg.Ingester.InstanceLimitsFn = ingesterInstanceLimits(t.RuntimeConfig)
 	t.tsdbIngesterConfig()
 
-	t.Ingester, err = ingester.New(t.Cfg.Ingester, t.Overrides, t.ActiveGroupsCleanup, t.Registerer, util_log.Logger)
+	t.Ingester, err = ingester.New(t.Cfg.Ingester, t.Overrides, t.ActiveGroupsCleanup, t.CostAttribution, t.Registerer, util_log.Logger)
 	if err != nil {
 		return
 	}
@@ -712,8 +725,17 @@ func (t *Mimir) initQueryFrontend() (serv services.Service, err error) {
 	// Wrap roundtripper into Tripperware.
 	roundTripper = t.QueryFrontendTripperware(roundTripper)
 
-	handler := transport.NewHandler(t.Cfg.Frontend.Handler, roundTripper, util_log.Logger, t.Registerer, t.ActivityTracker)
-	t.API.RegisterQueryFrontendHandler(handler, t.BuildInfoHandler)
+	var queryLog *querylog.Uploader
+	if t.Cfg.Frontend.QueryLog.Enabled {
+		bucketClient, err := bucket.NewClient(context.Background(), t.Cfg.BlocksStorage.Bucket, "query-frontend-query-log", util_log.Logger, t.Registerer)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create the query log bucket client")
+		}
+		queryLog = querylog.NewUploader(t.Cfg.Frontend.QueryLog, bucketClient, t.Overrides, util_log.Logger, t.Registerer)
+	}
+
+	handler := transport.NewHandler(t.Cfg.Frontend.Handler, t.Overrides, roundTripper, util_log.Logger, t.Registerer, t.ActivityTracker, queryLog)
+	t.API.RegisterQueryFrontendHandler(handler, t.BuildInfoHandler, handler.ActiveQueries())
 
 	var frontendSvc services.Service
 	if frontendV1 != nil {
@@ -727,6 +749,12 @@ func (t *Mimir) initQueryFrontend() (serv services.Service, err error) {
 
 	w := services.NewFailureWatcher()
 	return services.NewBasicService(func(_ context.Context) error {
+		if queryLog != nil {
+			w.WatchService(queryLog)
+			if err := services.StartAndAwaitRunning(context.Background(), queryLog); err != nil {
+				return err
+			}
+		}
 		if frontendSvc != nil {
 			w.WatchService(frontendSvc)
 			// Note that we pass an independent context to the service, since we want to
@@ -745,7 +773,13 @@ func (t *Mimir) initQueryFrontend() (serv services.Service, err error) {
 		handler.Stop()
 
 		if frontendSvc != nil {
-			return services.StopAndAwaitTerminated(context.Background(), frontendSvc)
+			if err := services.StopAndAwaitTerminated(context.Background(), frontendSvc); err != nil {
+				return err
+			}
+		}
+		if queryLog != nil {
+			// Stopped last, to upload the entries of the queries which completed while stopping.
+			return services.StopAndAwaitTerminated(context.Background(), queryLog)
 		}
 		return nil
 	}), nil
@@ -902,6 +936,16 @@ func (t *Mimir) initCompactor() (serv services.Service, err error) {
 	return t.Compactor, nil
 }
 
+func (t *Mimir) initCompactorScheduler() (services.Service, error) {
+	s, err := compactor.NewScheduler(t.Cfg.Compactor, t.Cfg.BlocksStorage, t.Overrides, util_log.Logger, t.Registerer)
+	if err != nil {
+		return nil, errors.Wrap(err, "compactor-scheduler init")
+	}
+
+	t.API.RegisterCompactorScheduler(s)
+	return s, nil
+}
+
 func (t *Mimir) initStoreGateway() (serv services.Service, err error) {
 	t.Cfg.StoreGateway.ShardingRing.ListenPort = t.Cfg.Server.GRPCListenPort
 
@@ -1004,6 +1048,7 @@ func (t *Mimir) setupModuleManager() error {
 	mm.RegisterModule(Overrides, t.initOverrides, modules.UserInvisibleModule)
 	mm.RegisterModule(OverridesExporter, t.initOverridesExporter)
 	mm.RegisterModule(ActiveGroupsCleanupService, t.initActiveGroupsCleanupService, modules.UserInvisibleModule)
+	mm.RegisterModule(CostAttributionService, t.initCostAttributionService, modules.UserInvisibleModule)
 	mm.RegisterModule(Distributor, t.initDistributor)
 	mm.RegisterModule(DistributorService, t.initDistributorService, modules.UserInvisibleModule)
 	mm.RegisterModule(Ingester, t.initIngester)
@@ -1018,6 +1063,7 @@ func (t *Mimir) setupModuleManager() error {
 	mm.RegisterModule(Ruler, t.initRuler)
 	mm.RegisterModule(AlertManager, t.initAlertManager)
 	mm.RegisterModule(Compactor, t.initCompactor)
+	mm.RegisterModule(CompactorScheduler, t.initCompactorScheduler)
 	mm.RegisterModule(StoreGateway, t.initStoreGateway)
 	mm.RegisterModule(QueryScheduler, t.initQueryScheduler)
 	mm.RegisterModule(TenantFederation, t.initTenantFederation, modules.UserInvisibleModule)
@@ -1037,9 +1083,10 @@ func (t *Mimir) setupModuleManager() error {
 		Ring:                     {API, RuntimeConfig, MemberlistKV, Vault},
 		Overrides:                {RuntimeConfig},
 		OverridesExporter:        {Overrides, MemberlistKV, Vault},
-		Distributor:              {DistributorService, API, ActiveGroupsCleanupService, Vault},
+		Distributor:              {DistributorService, API, ActiveGroupsCleanupService, CostAttributionService, Vault},
 		DistributorService:       {Ring, Overrides, Vault},
-		Ingester:                 {IngesterService, API, ActiveGroupsCleanupService, Vault},
+		Ingester:                 {IngesterService, API, ActiveGroupsCleanupService, CostAttributionService, Vault},
+		CostAttributionService:   {Overrides},
 		IngesterService:          {Overrides, RuntimeConfig, MemberlistKV},
 		Flusher:                  {Overrides, API},
 		Queryable:                {Overrides, DistributorService, Ring, API, StoreQueryable, MemberlistKV},
@@ -1052,6 +1099,7 @@ func (t *Mimir) setupModuleManager() error {
 		RulerStorage:             {Overrides},
 		AlertManager:             {API, MemberlistKV, Overrides, Vault},
 		Compactor:                {API, MemberlistKV, Overrides, Vault},
+		CompactorScheduler:       {API, Overrides, Vault},
 		StoreGateway:             {API, Overrides, MemberlistKV, Vault},
 		TenantFederation:         {Queryable},
 		Write:                    {Distributor, Ingester},
@@ -349,6 +349,7 @@ func TestInitVault(t *testing.T) {
 	require.NotNil(t, mimir.Cfg.Ruler.QueryFrontend.GRPCClientConfig.TLS.Reader)
 	require.NotNil(t, mimir.Cfg.Alertmanager.AlertmanagerClient.GRPCClientConfig.TLS.Reader)
 	require.NotNil(t, mimir.Cfg.QueryScheduler.GRPCClientConfig.TLS.Reader)
+	require.NotNil(t, mimir.Cfg.Compactor.Scheduler.GRPCClientConfig.TLS.Reader)
 
 	// Check Server
 	require.Empty(t, mimir.Cfg.Server.HTTPTLSConfig.TLSCertPath)
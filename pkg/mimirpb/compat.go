@@ -206,16 +206,72 @@ func FromFloatHistogramProtoToFloatHistogram(hp *Histogram) *histogram.FloatHist
 	}
 }
 
+// FromHistogramProtoToPromHistogram converts a Histogram to model.SampleHistogram. The buckets of the native
+// histograms with custom buckets are bounded by their custom values.
 func FromHistogramProtoToPromHistogram(hp *Histogram) *model.SampleHistogram {
 	if hp == nil {
 		return nil
 	}
+	if hp.UsesCustomBuckets() {
+		return fromCustomBucketsHistogramProtoToPromHistogram(hp)
+	}
 	if hp.IsFloatHistogram() {
 		return FromFloatHistogramToPromHistogram(FromFloatHistogramProtoToFloatHistogram(hp))
 	}
 	return FromHistogramToPromHistogram(FromHistogramProtoToHistogram(hp))
 }
 
+// fromCustomBucketsHistogramProtoToPromHistogram converts a native histogram with custom buckets to
+// model.SampleHistogram. The bucket with index i covers the values in (custom_values[i-1], custom_values[i]],
+// the first bucket starts from -Inf and the bucket following the last custom value ends at +Inf.
+func fromCustomBucketsHistogramProtoToPromHistogram(hp *Histogram) *model.SampleHistogram {
+	var counts []float64
+	if hp.IsFloatHistogram() {
+		counts = hp.GetPositiveCounts()
+	} else {
+		counts = deltasToCounts(hp.GetPositiveDeltas())
+	}
+
+	bound := func(idx int32) float64 {
+		switch {
+		case idx < 0:
+			return math.Inf(-1)
+		case int(idx) >= len(hp.CustomValues):
+			return math.Inf(1)
+		default:
+			return hp.CustomValues[idx]
+		}
+	}
+
+	buckets := make([]*model.HistogramBucket, 0, len(counts))
+	bucketIdx, countIdx := int32(0), 0
+	for _, span := range hp.GetPositiveSpans() {
+		bucketIdx += span.Offset
+		for j := uint32(0); j < span.Length && countIdx < len(counts); j++ {
+			if count := counts[countIdx]; count != 0 {
+				buckets = append(buckets, &model.HistogramBucket{
+					Boundaries: 0, // Inclusive only on upper end AKA left open.
+					Lower:      model.FloatString(bound(bucketIdx - 1)),
+					Upper:      model.FloatString(bound(bucketIdx)),
+					Count:      model.FloatString(count),
+				})
+			}
+			bucketIdx++
+			countIdx++
+		}
+	}
+
+	count := hp.GetCountFloat()
+	if !hp.IsFloatHistogram() {
+		count = float64(hp.GetCountInt())
+	}
+	return &model.SampleHistogram{
+		Count:   model.FloatString(count),
+		Sum:     model.FloatString(hp.Sum),
+		Buckets: buckets,
+	}
+}
+
 func fromSpansProtoToSpans(s []BucketSpan) []histogram.Span {
 	if len(s) == 0 {
 		return nil
@@ -0,0 +1,317 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package mimirpb
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/prometheus/common/model"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Field numbers of the Prometheus Remote-Write 2.0 io.prometheus.write.v2.Request message, and of its sub-messages.
+const (
+	rw2RequestSymbolsField    = 4
+	rw2RequestTimeseriesField = 5
+
+	rw2TimeseriesLabelsRefsField       = 1
+	rw2TimeseriesSamplesField          = 2
+	rw2TimeseriesHistogramsField       = 3
+	rw2TimeseriesExemplarsField        = 4
+	rw2TimeseriesMetadataField         = 5
+	rw2TimeseriesCreatedTimestampField = 6
+
+	rw2ExemplarLabelsRefsField = 1
+	rw2ExemplarValueField      = 2
+	rw2ExemplarTimestampField  = 3
+
+	rw2MetadataTypeField    = 1
+	rw2MetadataHelpRefField = 3
+	rw2MetadataUnitRefField = 4
+)
+
+// PreallocWriteRequestRW2 unmarshals a Prometheus Remote-Write 2.0 request into the wrapped PreallocWriteRequest.
+//
+// The series labels are resolved from the request symbols table without copying them: like the labels of
+// a Remote-Write 1.0 request, they reference the input data slice, which must be retained as long as the
+// request is used. Samples, histograms and exemplars are decoded straight into the pooled series.
+//
+// The metadata of each series is converted to a WriteRequest metadata entry, while the series created
+// timestamp is carried in the series, for the ingesters to ingest a zero sample at the created timestamp.
+// The Remote-Write 2.0 Histogram message is wire compatible with our Histogram, including the custom values
+// of the native histograms with custom buckets.
+type PreallocWriteRequestRW2 struct {
+	*PreallocWriteRequest
+}
+
+// Unmarshal implements proto.Unmarshaler.
+func (p PreallocWriteRequestRW2) Unmarshal(dAtA []byte) error {
+	p.Timeseries = PreallocTimeseriesSliceFromPool()
+
+	// The symbols table could come after the series in the message, so it's read upfront.
+	var symbols []string
+	err := rw2RangeFields(dAtA, func(num protowire.Number, typ protowire.Type, v []byte, _ uint64) error {
+		if num != rw2RequestSymbolsField {
+			return nil
+		}
+		if typ != protowire.BytesType {
+			return fmt.Errorf("proto: wrong wireType = %d for field Symbols", typ)
+		}
+		symbols = append(symbols, yoloString(v))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(symbols) > 0 && symbols[0] != "" {
+		return fmt.Errorf("the first symbol of a remote-write 2.0 request must be an empty string")
+	}
+
+	dec := rw2Decoder{symbols: symbols}
+	return rw2RangeFields(dAtA, func(num protowire.Number, typ protowire.Type, v []byte, _ uint64) error {
+		if num != rw2RequestTimeseriesField {
+			return nil
+		}
+		if typ != protowire.BytesType {
+			return fmt.Errorf("proto: wrong wireType = %d for field Timeseries", typ)
+		}
+
+		ts := PreallocTimeseries{TimeSeries: TimeseriesFromPool()}
+		// Append the series before decoding it, so that it's returned to the pool on cleanup even if decoding fails.
+		p.Timeseries = append(p.Timeseries, ts)
+		return dec.decodeTimeseries(v, ts.TimeSeries, &p.WriteRequest)
+	})
+}
+
+type rw2Decoder struct {
+	symbols []string
+
+	// refs is reused across series to hold the labels references being decoded.
+	refs []uint32
+
+	// lastMetadataFamily is the metric family of the last metadata added to the request. Metadata is
+	// usually repeated for all the series of a metric family, which are likely sent one after the other.
+	lastMetadataFamily string
+}
+
+func (d *rw2Decoder) decodeTimeseries(dAtA []byte, ts *TimeSeries, req *WriteRequest) error {
+	var (
+		metadata    MetricMetadata
+		hasMetadata bool
+		err         error
+	)
+
+	d.refs = d.refs[:0]
+	err = rw2RangeFields(dAtA, func(num protowire.Number, typ protowire.Type, v []byte, n uint64) error {
+		switch num {
+		case rw2TimeseriesLabelsRefsField:
+			d.refs, err = rw2AppendRefs(d.refs, typ, v, n)
+			return err
+		case rw2TimeseriesSamplesField:
+			if typ != protowire.BytesType {
+				return fmt.Errorf("proto: wrong wireType = %d for field Samples", typ)
+			}
+			ts.Samples = append(ts.Samples, Sample{})
+			return ts.Samples[len(ts.Samples)-1].Unmarshal(v)
+		case rw2TimeseriesHistogramsField:
+			if typ != protowire.BytesType {
+				return fmt.Errorf("proto: wrong wireType = %d for field Histograms", typ)
+			}
+			ts.Histograms = append(ts.Histograms, Histogram{})
+			return ts.Histograms[len(ts.Histograms)-1].Unmarshal(v)
+		case rw2TimeseriesExemplarsField:
+			if typ != protowire.BytesType {
+				return fmt.Errorf("proto: wrong wireType = %d for field Exemplars", typ)
+			}
+			ex, err := d.decodeExemplar(v)
+			if err != nil {
+				return err
+			}
+			ts.Exemplars = append(ts.Exemplars, ex)
+			return nil
+		case rw2TimeseriesMetadataField:
+			if typ != protowire.BytesType {
+				return fmt.Errorf("proto: wrong wireType = %d for field Metadata", typ)
+			}
+			hasMetadata = true
+			return d.decodeMetadata(v, &metadata)
+		case rw2TimeseriesCreatedTimestampField:
+			if typ != protowire.VarintType {
+				return fmt.Errorf("proto: wrong wireType = %d for field CreatedTimestamp", typ)
+			}
+			ts.CreatedTimestamp = int64(n)
+			return nil
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	ts.Labels, err = d.appendLabels(ts.Labels, d.refs)
+	if err != nil {
+		return err
+	}
+
+	if hasMetadata && (metadata.Type != UNKNOWN || metadata.Help != "" || metadata.Unit != "") {
+		for _, l := range ts.Labels {
+			if l.Name == model.MetricNameLabel {
+				metadata.MetricFamilyName = l.Value
+				break
+			}
+		}
+		if metadata.MetricFamilyName != "" && metadata.MetricFamilyName != d.lastMetadataFamily {
+			req.Metadata = append(req.Metadata, &metadata)
+			d.lastMetadataFamily = metadata.MetricFamilyName
+		}
+	}
+
+	return nil
+}
+
+func (d *rw2Decoder) decodeExemplar(dAtA []byte) (Exemplar, error) {
+	var (
+		ex   Exemplar
+		refs []uint32
+		err  error
+	)
+
+	err = rw2RangeFields(dAtA, func(num protowire.Number, typ protowire.Type, v []byte, n uint64) error {
+		switch num {
+		case rw2ExemplarLabelsRefsField:
+			refs, err = rw2AppendRefs(refs, typ, v, n)
+			return err
+		case rw2ExemplarValueField:
+			if typ != protowire.Fixed64Type {
+				return fmt.Errorf("proto: wrong wireType = %d for field Value", typ)
+			}
+			ex.Value = math.Float64frombits(n)
+		case rw2ExemplarTimestampField:
+			if typ != protowire.VarintType {
+				return fmt.Errorf("proto: wrong wireType = %d for field Timestamp", typ)
+			}
+			ex.TimestampMs = int64(n)
+		}
+		return nil
+	})
+	if err != nil {
+		return ex, err
+	}
+
+	ex.Labels, err = d.appendLabels(nil, refs)
+	return ex, err
+}
+
+func (d *rw2Decoder) decodeMetadata(dAtA []byte, metadata *MetricMetadata) error {
+	return rw2RangeFields(dAtA, func(num protowire.Number, typ protowire.Type, _ []byte, n uint64) error {
+		switch num {
+		case rw2MetadataTypeField:
+			if typ != protowire.VarintType {
+				return fmt.Errorf("proto: wrong wireType = %d for field Type", typ)
+			}
+			// The Remote-Write 2.0 metric types have the same values as ours.
+			metadata.Type = MetricMetadata_MetricType(n)
+		case rw2MetadataHelpRefField, rw2MetadataUnitRefField:
+			if typ != protowire.VarintType {
+				return fmt.Errorf("proto: wrong wireType = %d for field %d", typ, num)
+			}
+			symbol, err := d.symbol(n)
+			if err != nil {
+				return err
+			}
+			if num == rw2MetadataHelpRefField {
+				metadata.Help = symbol
+			} else {
+				metadata.Unit = symbol
+			}
+		}
+		return nil
+	})
+}
+
+// appendLabels resolves the labels references, which are pairs of name and value symbols, and appends the labels to dst.
+func (d *rw2Decoder) appendLabels(dst []LabelAdapter, refs []uint32) ([]LabelAdapter, error) {
+	if len(refs)%2 != 0 {
+		return dst, fmt.Errorf("the labels references of a remote-write 2.0 series must be pairs of name and value symbols, got %d references", len(refs))
+	}
+	for i := 0; i < len(refs); i += 2 {
+		name, err := d.symbol(uint64(refs[i]))
+		if err != nil {
+			return dst, err
+		}
+		value, err := d.symbol(uint64(refs[i+1]))
+		if err != nil {
+			return dst, err
+		}
+		dst = append(dst, LabelAdapter{Name: name, Value: value})
+	}
+	return dst, nil
+}
+
+func (d *rw2Decoder) symbol(ref uint64) (string, error) {
+	if ref >= uint64(len(d.symbols)) {
+		return "", fmt.Errorf("symbol reference %d is out of range, the remote-write 2.0 request has %d symbols", ref, len(d.symbols))
+	}
+	return d.symbols[ref], nil
+}
+
+// rw2AppendRefs appends the references of a repeated uint32 field, which can be either packed or not, to dst.
+func rw2AppendRefs(dst []uint32, typ protowire.Type, v []byte, n uint64) ([]uint32, error) {
+	switch typ {
+	case protowire.VarintType:
+		return append(dst, uint32(n)), nil
+	case protowire.BytesType:
+		for len(v) > 0 {
+			ref, l := protowire.ConsumeVarint(v)
+			if l < 0 {
+				return dst, protowire.ParseError(l)
+			}
+			dst = append(dst, uint32(ref))
+			v = v[l:]
+		}
+		return dst, nil
+	default:
+		return dst, fmt.Errorf("proto: wrong wireType = %d for field LabelsRefs", typ)
+	}
+}
+
+// rw2RangeFields calls f for each field of the protobuf message. Length-delimited field values are passed as
+// a sub-slice of dAtA, while varint and fixed-size field values are passed as an integer.
+func rw2RangeFields(dAtA []byte, f func(num protowire.Number, typ protowire.Type, v []byte, n uint64) error) error {
+	for len(dAtA) > 0 {
+		num, typ, l := protowire.ConsumeTag(dAtA)
+		if l < 0 {
+			return protowire.ParseError(l)
+		}
+		dAtA = dAtA[l:]
+
+		var (
+			v []byte
+			n uint64
+		)
+		switch typ {
+		case protowire.VarintType:
+			n, l = protowire.ConsumeVarint(dAtA)
+		case protowire.Fixed64Type:
+			n, l = protowire.ConsumeFixed64(dAtA)
+		case protowire.Fixed32Type:
+			var n32 uint32
+			n32, l = protowire.ConsumeFixed32(dAtA)
+			n = uint64(n32)
+		case protowire.BytesType:
+			v, l = protowire.ConsumeBytes(dAtA)
+		default:
+			l = protowire.ConsumeFieldValue(num, typ, dAtA)
+		}
+		if l < 0 {
+			return protowire.ParseError(l)
+		}
+		dAtA = dAtA[l:]
+
+		if err := f(num, typ, v, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package mimirpb
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestPreallocWriteRequestRW2_Unmarshal(t *testing.T) {
+	symbols := []string{"", "__name__", "http_requests_total", "job", "api", "trace_id", "1234", "Total number of HTTP requests.", "requests", "http_request_duration_seconds"}
+
+	hist := Histogram{
+		Count:          &Histogram_CountInt{CountInt: 3},
+		Sum:            1.5,
+		Schema:         1,
+		ZeroThreshold:  0.001,
+		ZeroCount:      &Histogram_ZeroCountInt{ZeroCountInt: 1},
+		PositiveSpans:  []BucketSpan{{Offset: 0, Length: 2}},
+		PositiveDeltas: []int64{1, 0},
+		Timestamp:      2000,
+	}
+
+	t.Run("should decode series, samples, histograms, exemplars and metadata", func(t *testing.T) {
+		data := marshalRW2Request(symbols, []rw2TestSeries{
+			{
+				labelsRefs:       []uint32{1, 2, 3, 4},
+				samples:          []Sample{{Value: 1, TimestampMs: 1000}, {Value: 2, TimestampMs: 2000}},
+				exemplars:        []rw2TestExemplar{{labelsRefs: []uint32{5, 6}, value: 2, timestamp: 2000}},
+				metadata:         &rw2TestMetadata{typ: COUNTER, helpRef: 7, unitRef: 8},
+				createdTimestamp: 500,
+			},
+			{
+				labelsRefs: []uint32{1, 9, 3, 4},
+				histograms: []Histogram{hist},
+				metadata:   &rw2TestMetadata{typ: HISTOGRAM},
+			},
+		}, true)
+
+		req := &PreallocWriteRequest{}
+		require.NoError(t, PreallocWriteRequestRW2{PreallocWriteRequest: req}.Unmarshal(data))
+		t.Cleanup(func() { ReuseSlice(req.Timeseries) })
+
+		require.Len(t, req.Timeseries, 2)
+
+		assert.Equal(t, []LabelAdapter{{Name: "__name__", Value: "http_requests_total"}, {Name: "job", Value: "api"}}, req.Timeseries[0].Labels)
+		assert.Equal(t, []Sample{{Value: 1, TimestampMs: 1000}, {Value: 2, TimestampMs: 2000}}, req.Timeseries[0].Samples)
+		assert.Empty(t, req.Timeseries[0].Histograms)
+		assert.Equal(t, []Exemplar{{Labels: []LabelAdapter{{Name: "trace_id", Value: "1234"}}, Value: 2, TimestampMs: 2000}}, req.Timeseries[0].Exemplars)
+		assert.Equal(t, int64(500), req.Timeseries[0].CreatedTimestamp)
+
+		assert.Equal(t, []LabelAdapter{{Name: "__name__", Value: "http_request_duration_seconds"}, {Name: "job", Value: "api"}}, req.Timeseries[1].Labels)
+		assert.Empty(t, req.Timeseries[1].Samples)
+		assert.Equal(t, []Histogram{hist}, req.Timeseries[1].Histograms)
+		assert.Zero(t, req.Timeseries[1].CreatedTimestamp)
+
+		assert.Equal(t, []*MetricMetadata{
+			{Type: COUNTER, MetricFamilyName: "http_requests_total", Help: "Total number of HTTP requests.", Unit: "requests"},
+			{Type: HISTOGRAM, MetricFamilyName: "http_request_duration_seconds"},
+		}, req.Metadata)
+	})
+
+	t.Run("should decode native histograms with custom buckets", func(t *testing.T) {
+		nhcb := Histogram{
+			Count:          &Histogram_CountInt{CountInt: 3},
+			Sum:            1.5,
+			Schema:         CustomBucketsSchema,
+			PositiveSpans:  []BucketSpan{{Offset: 0, Length: 3}},
+			PositiveDeltas: []int64{1, 0, -1},
+			CustomValues:   []float64{0.1, 1},
+			Timestamp:      2000,
+		}
+		data := marshalRW2Request(symbols, []rw2TestSeries{
+			{labelsRefs: []uint32{1, 9}, histograms: []Histogram{nhcb}},
+		}, true)
+
+		req := &PreallocWriteRequest{}
+		require.NoError(t, PreallocWriteRequestRW2{PreallocWriteRequest: req}.Unmarshal(data))
+		t.Cleanup(func() { ReuseSlice(req.Timeseries) })
+
+		require.Len(t, req.Timeseries, 1)
+		assert.Equal(t, []Histogram{nhcb}, req.Timeseries[0].Histograms)
+	})
+
+	t.Run("should decode the symbols table even if it comes after the series", func(t *testing.T) {
+		data := marshalRW2Request(symbols, []rw2TestSeries{
+			{labelsRefs: []uint32{1, 2}, samples: []Sample{{Value: 1, TimestampMs: 1000}}},
+		}, false)
+
+		req := &PreallocWriteRequest{}
+		require.NoError(t, PreallocWriteRequestRW2{PreallocWriteRequest: req}.Unmarshal(data))
+		t.Cleanup(func() { ReuseSlice(req.Timeseries) })
+
+		require.Len(t, req.Timeseries, 1)
+		assert.Equal(t, []LabelAdapter{{Name: "__name__", Value: "http_requests_total"}}, req.Timeseries[0].Labels)
+	})
+
+	t.Run("should add the metadata of a metric family only once", func(t *testing.T) {
+		data := marshalRW2Request(symbols, []rw2TestSeries{
+			{labelsRefs: []uint32{1, 2, 3, 4}, metadata: &rw2TestMetadata{typ: COUNTER}},
+			{labelsRefs: []uint32{1, 2, 3, 6}, metadata: &rw2TestMetadata{typ: COUNTER}},
+		}, true)
+
+		req := &PreallocWriteRequest{}
+		require.NoError(t, PreallocWriteRequestRW2{PreallocWriteRequest: req}.Unmarshal(data))
+		t.Cleanup(func() { ReuseSlice(req.Timeseries) })
+
+		assert.Equal(t, []*MetricMetadata{{Type: COUNTER, MetricFamilyName: "http_requests_total"}}, req.Metadata)
+	})
+
+	for name, testCase := range map[string]struct {
+		symbols     []string
+		series      []rw2TestSeries
+		expectedErr string
+	}{
+		"out of range label reference": {
+			symbols:     symbols,
+			series:      []rw2TestSeries{{labelsRefs: []uint32{1, 100}}},
+			expectedErr: "symbol reference 100 is out of range",
+		},
+		"odd number of label references": {
+			symbols:     symbols,
+			series:      []rw2TestSeries{{labelsRefs: []uint32{1, 2, 3}}},
+			expectedErr: "must be pairs of name and value symbols",
+		},
+		"out of range metadata reference": {
+			symbols:     symbols,
+			series:      []rw2TestSeries{{labelsRefs: []uint32{1, 2}, metadata: &rw2TestMetadata{helpRef: 100}}},
+			expectedErr: "symbol reference 100 is out of range",
+		},
+		"first symbol not empty": {
+			symbols:     []string{"__name__", "up"},
+			series:      []rw2TestSeries{{labelsRefs: []uint32{0, 1}}},
+			expectedErr: "the first symbol of a remote-write 2.0 request must be an empty string",
+		},
+	} {
+		t.Run("should fail on "+name, func(t *testing.T) {
+			req := &PreallocWriteRequest{}
+			err := PreallocWriteRequestRW2{PreallocWriteRequest: req}.Unmarshal(marshalRW2Request(testCase.symbols, testCase.series, true))
+			t.Cleanup(func() { ReuseSlice(req.Timeseries) })
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), testCase.expectedErr)
+		})
+	}
+
+	t.Run("should fail on truncated data", func(t *testing.T) {
+		data := marshalRW2Request(symbols, []rw2TestSeries{{labelsRefs: []uint32{1, 2}}}, true)
+
+		req := &PreallocWriteRequest{}
+		err := PreallocWriteRequestRW2{PreallocWriteRequest: req}.Unmarshal(data[:len(data)-1])
+		t.Cleanup(func() { ReuseSlice(req.Timeseries) })
+
+		require.Error(t, err)
+	})
+}
+
+type rw2TestSeries struct {
+	labelsRefs       []uint32
+	samples          []Sample
+	histograms       []Histogram
+	exemplars        []rw2TestExemplar
+	metadata         *rw2TestMetadata
+	createdTimestamp int64
+}
+
+type rw2TestExemplar struct {
+	labelsRefs []uint32
+	value      float64
+	timestamp  int64
+}
+
+type rw2TestMetadata struct {
+	typ              MetricMetadata_MetricType
+	helpRef, unitRef uint32
+}
+
+// marshalRW2Request encodes a Remote-Write 2.0 request, with the symbols table either before or after the series.
+func marshalRW2Request(symbols []string, series []rw2TestSeries, symbolsFirst bool) []byte {
+	var symbolsData []byte
+	for _, s := range symbols {
+		symbolsData = protowire.AppendTag(symbolsData, rw2RequestSymbolsField, protowire.BytesType)
+		symbolsData = protowire.AppendString(symbolsData, s)
+	}
+
+	var seriesData []byte
+	for _, s := range series {
+		var ts []byte
+		ts = protowire.AppendTag(ts, rw2TimeseriesLabelsRefsField, protowire.BytesType)
+		ts = protowire.AppendBytes(ts, appendPackedRefs(nil, s.labelsRefs))
+		for _, sample := range s.samples {
+			data, _ := sample.Marshal()
+			ts = protowire.AppendTag(ts, rw2TimeseriesSamplesField, protowire.BytesType)
+			ts = protowire.AppendBytes(ts, data)
+		}
+		for _, h := range s.histograms {
+			data, _ := h.Marshal()
+			ts = protowire.AppendTag(ts, rw2TimeseriesHistogramsField, protowire.BytesType)
+			ts = protowire.AppendBytes(ts, data)
+		}
+		for _, ex := range s.exemplars {
+			var data []byte
+			data = protowire.AppendTag(data, rw2ExemplarLabelsRefsField, protowire.BytesType)
+			data = protowire.AppendBytes(data, appendPackedRefs(nil, ex.labelsRefs))
+			data = protowire.AppendTag(data, rw2ExemplarValueField, protowire.Fixed64Type)
+			data = protowire.AppendFixed64(data, math.Float64bits(ex.value))
+			data = protowire.AppendTag(data, rw2ExemplarTimestampField, protowire.VarintType)
+			data = protowire.AppendVarint(data, uint64(ex.timestamp))
+			ts = protowire.AppendTag(ts, rw2TimeseriesExemplarsField, protowire.BytesType)
+			ts = protowire.AppendBytes(ts, data)
+		}
+		if s.metadata != nil {
+			var data []byte
+			data = protowire.AppendTag(data, rw2MetadataTypeField, protowire.VarintType)
+			data = protowire.AppendVarint(data, uint64(s.metadata.typ))
+			data = protowire.AppendTag(data, rw2MetadataHelpRefField, protowire.VarintType)
+			data = protowire.AppendVarint(data, uint64(s.metadata.helpRef))
+			data = protowire.AppendTag(data, rw2MetadataUnitRefField, protowire.VarintType)
+			data = protowire.AppendVarint(data, uint64(s.metadata.unitRef))
+			ts = protowire.AppendTag(ts, rw2TimeseriesMetadataField, protowire.BytesType)
+			ts = protowire.AppendBytes(ts, data)
+		}
+		if s.createdTimestamp != 0 {
+			ts = protowire.AppendTag(ts, rw2TimeseriesCreatedTimestampField, protowire.VarintType)
+			ts = protowire.AppendVarint(ts, uint64(s.createdTimestamp))
+		}
+
+		seriesData = protowire.AppendTag(seriesData, rw2RequestTimeseriesField, protowire.BytesType)
+		seriesData = protowire.AppendBytes(seriesData, ts)
+	}
+
+	if symbolsFirst {
+		return append(symbolsData, seriesData...)
+	}
+	return append(seriesData, symbolsData...)
+}
+
+func appendPackedRefs(dst []byte, refs []uint32) []byte {
+	for _, ref := range refs {
+		dst = protowire.AppendVarint(dst, uint64(ref))
+	}
+	return dst
+}
@@ -626,6 +626,42 @@ func TestFromFloatHistogramToPromHistogram(t *testing.T) {
 	}
 }
 
+func TestFromHistogramProtoToPromHistogram_CustomBuckets(t *testing.T) {
+	expected := model.SampleHistogram{
+		Count: 6,
+		Sum:   4.5,
+		Buckets: model.HistogramBuckets{
+			{Boundaries: 0, Lower: model.FloatString(math.Inf(-1)), Upper: 0.1, Count: 1},
+			{Boundaries: 0, Lower: 1, Upper: 10, Count: 3},
+			{Boundaries: 0, Lower: 10, Upper: model.FloatString(math.Inf(1)), Count: 2},
+		},
+	}
+
+	t.Run("integer histogram", func(t *testing.T) {
+		h := Histogram{
+			Count:          &Histogram_CountInt{CountInt: 6},
+			Sum:            4.5,
+			Schema:         CustomBucketsSchema,
+			PositiveSpans:  []BucketSpan{{Offset: 0, Length: 1}, {Offset: 1, Length: 2}},
+			PositiveDeltas: []int64{1, 2, -1},
+			CustomValues:   []float64{0.1, 1, 10},
+		}
+		require.Equal(t, expected, *FromHistogramProtoToPromHistogram(&h))
+	})
+
+	t.Run("float histogram", func(t *testing.T) {
+		h := Histogram{
+			Count:          &Histogram_CountFloat{CountFloat: 6},
+			Sum:            4.5,
+			Schema:         CustomBucketsSchema,
+			PositiveSpans:  []BucketSpan{{Offset: 0, Length: 4}},
+			PositiveCounts: []float64{1, 0, 3, 2},
+			CustomValues:   []float64{0.1, 1, 10},
+		}
+		require.Equal(t, expected, *FromHistogramProtoToPromHistogram(&h))
+	})
+}
+
 // Check that Prometheus and Mimir SampleHistogram types converted
 // into each other with unsafe.Pointer are compatible
 func TestPrometheusSampleHistogramInSyncWithMimirPbSampleHistogram(t *testing.T) {
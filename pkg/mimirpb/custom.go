@@ -4,9 +4,17 @@ package mimirpb
 
 import (
 	"bytes"
+	"fmt"
 	"math"
 )
 
+// minHistogramSchema is the lowest exponential schema a native histogram can be reduced to.
+const minHistogramSchema = -4
+
+// CustomBucketsSchema is the schema of the native histograms with custom buckets (NHCB), whose bucket
+// boundaries are their custom values rather than exponential boundaries.
+const CustomBucketsSchema = -53
+
 // MinTimestamp returns the minimum timestamp (milliseconds) among all series
 // in the WriteRequest. Returns math.MaxInt64 if the request is empty.
 func (m *WriteRequest) MinTimestamp() int64 {
@@ -44,6 +52,95 @@ func (h Histogram) IsGauge() bool {
 	return h.ResetHint == Histogram_GAUGE
 }
 
+// UsesCustomBuckets returns whether the histogram is a native histogram with custom buckets.
+func (h Histogram) UsesCustomBuckets() bool {
+	return h.Schema == CustomBucketsSchema
+}
+
+// BucketCount returns the number of positive and negative buckets of the histogram.
+// Native histograms with custom buckets only have positive buckets.
+func (h Histogram) BucketCount() int {
+	if h.IsFloatHistogram() {
+		return len(h.GetNegativeCounts()) + len(h.GetPositiveCounts())
+	}
+	return len(h.GetNegativeDeltas()) + len(h.GetPositiveDeltas())
+}
+
+// ReduceResolution reduces the schema of the histogram by one, merging each pair of
+// adjacent buckets into a single bucket. It returns the resulting number of buckets,
+// or an error if the histogram is already at the lowest schema.
+func (h *Histogram) ReduceResolution() (int, error) {
+	if h.Schema <= minHistogramSchema {
+		return 0, fmt.Errorf("cannot reduce the resolution of a native histogram with schema %d", h.Schema)
+	}
+
+	targetSchema := h.Schema - 1
+	if h.IsFloatHistogram() {
+		h.PositiveSpans, h.PositiveCounts = reduceResolution(h.PositiveSpans, h.PositiveCounts, h.Schema, targetSchema, false)
+		h.NegativeSpans, h.NegativeCounts = reduceResolution(h.NegativeSpans, h.NegativeCounts, h.Schema, targetSchema, false)
+	} else {
+		h.PositiveSpans, h.PositiveDeltas = reduceResolution(h.PositiveSpans, h.PositiveDeltas, h.Schema, targetSchema, true)
+		h.NegativeSpans, h.NegativeDeltas = reduceResolution(h.NegativeSpans, h.NegativeDeltas, h.Schema, targetSchema, true)
+	}
+	h.Schema = targetSchema
+
+	return h.BucketCount(), nil
+}
+
+// reduceResolution merges the buckets of originSchema into the buckets of the lower targetSchema.
+// If deltaBuckets is true, the input and output buckets are delta-encoded, otherwise they're absolute counts.
+func reduceResolution[B int64 | float64](spans []BucketSpan, buckets []B, originSchema, targetSchema int32, deltaBuckets bool) ([]BucketSpan, []B) {
+	var (
+		targetSpans   []BucketSpan
+		targetBuckets []B
+		bucketIdx     int32
+		bucketPos     int
+		lastCount     B
+		lastTargetIdx int32
+	)
+
+	for _, span := range spans {
+		bucketIdx += span.Offset
+		for j := uint32(0); j < span.Length; j++ {
+			count := buckets[bucketPos]
+			bucketPos++
+			if deltaBuckets {
+				count += lastCount
+				lastCount = count
+			}
+
+			// The bucket with index i at schema s covers the buckets with index ((i-1) >> (s-t)) + 1 at schema t.
+			targetIdx := ((bucketIdx - 1) >> (originSchema - targetSchema)) + 1
+			bucketIdx++
+
+			switch {
+			case len(targetSpans) == 0:
+				targetSpans = append(targetSpans, BucketSpan{Offset: targetIdx, Length: 1})
+				targetBuckets = append(targetBuckets, count)
+			case targetIdx == lastTargetIdx:
+				targetBuckets[len(targetBuckets)-1] += count
+			case targetIdx == lastTargetIdx+1:
+				targetSpans[len(targetSpans)-1].Length++
+				targetBuckets = append(targetBuckets, count)
+			default:
+				targetSpans = append(targetSpans, BucketSpan{Offset: targetIdx - lastTargetIdx - 1, Length: 1})
+				targetBuckets = append(targetBuckets, count)
+			}
+			lastTargetIdx = targetIdx
+		}
+	}
+
+	if deltaBuckets {
+		var prev B
+		for i, count := range targetBuckets {
+			targetBuckets[i] = count - prev
+			prev = count
+		}
+	}
+
+	return targetSpans, targetBuckets
+}
+
 // UnsafeByteSlice is an alternative to the default handling of []byte values in protobuf messages.
 // Unlike the default protobuf implementation, when unmarshalling, UnsafeByteSlice holds a reference to the
 // subslice of the original protobuf-encoded bytes, rather than copying them from the encoded buffer to a second slice.
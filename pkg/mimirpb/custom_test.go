@@ -86,3 +86,46 @@ func TestIsFloatHistogram(t *testing.T) {
 		})
 	}
 }
+
+func TestHistogram_ReduceResolution(t *testing.T) {
+	h := &histogram.Histogram{
+		Schema:          0,
+		Count:           21,
+		PositiveSpans:   []histogram.Span{{Offset: -2, Length: 3}, {Offset: 2, Length: 2}},
+		PositiveBuckets: []int64{1, 1, -1, 3, 1},
+		NegativeSpans:   []histogram.Span{{Offset: 1, Length: 1}},
+		NegativeBuckets: []int64{4},
+	}
+
+	t.Run("integer histogram", func(t *testing.T) {
+		hp := FromHistogramToHistogramProto(0, h)
+		bucketCount, err := hp.ReduceResolution()
+		require.NoError(t, err)
+		require.Equal(t, 4, bucketCount)
+		require.Equal(t, int32(-1), hp.Schema)
+		// Buckets -2, -1, 0 and 3, 4 merge into -1, 0 and 2.
+		require.Equal(t, []BucketSpan{{Offset: -1, Length: 2}, {Offset: 1, Length: 1}}, hp.PositiveSpans)
+		require.Equal(t, []int64{1, 2, 6}, hp.PositiveDeltas)
+		require.Equal(t, []BucketSpan{{Offset: 1, Length: 1}}, hp.NegativeSpans)
+		require.Equal(t, []int64{4}, hp.NegativeDeltas)
+	})
+
+	t.Run("float histogram", func(t *testing.T) {
+		hp := FromFloatHistogramToHistogramProto(0, h.ToFloat())
+		bucketCount, err := hp.ReduceResolution()
+		require.NoError(t, err)
+		require.Equal(t, 4, bucketCount)
+		require.Equal(t, int32(-1), hp.Schema)
+		require.Equal(t, []BucketSpan{{Offset: -1, Length: 2}, {Offset: 1, Length: 1}}, hp.PositiveSpans)
+		require.Equal(t, []float64{1, 3, 9}, hp.PositiveCounts)
+		require.Equal(t, []BucketSpan{{Offset: 1, Length: 1}}, hp.NegativeSpans)
+		require.Equal(t, []float64{4}, hp.NegativeCounts)
+	})
+
+	t.Run("lowest schema", func(t *testing.T) {
+		hp := FromHistogramToHistogramProto(0, h)
+		hp.Schema = -4
+		_, err := hp.ReduceResolution()
+		require.Error(t, err)
+	})
+}
@@ -368,6 +368,9 @@ type TimeSeries struct {
 	Samples    []Sample    `protobuf:"bytes,2,rep,name=samples,proto3" json:"samples"`
 	Exemplars  []Exemplar  `protobuf:"bytes,3,rep,name=exemplars,proto3" json:"exemplars"`
 	Histograms []Histogram `protobuf:"bytes,4,rep,name=histograms,proto3" json:"histograms"`
+	// Timestamp, in milliseconds, when the series was created, if known. The field number
+	// matches the one of the Prometheus Remote-Write 2.0 TimeSeries message.
+	CreatedTimestamp int64 `protobuf:"varint,6,opt,name=created_timestamp,json=createdTimestamp,proto3" json:"created_timestamp,omitempty"`
 }
 
 func (m *TimeSeries) Reset()      { *m = TimeSeries{} }
@@ -423,6 +426,13 @@ func (m *TimeSeries) GetHistograms() []Histogram {
 	return nil
 }
 
+func (m *TimeSeries) GetCreatedTimestamp() int64 {
+	if m != nil {
+		return m.CreatedTimestamp
+	}
+	return 0
+}
+
 type LabelPair struct {
 	Name  []byte `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
 	Value []byte `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
@@ -721,6 +731,11 @@ type Histogram struct {
 	ResetHint      Histogram_ResetHint `protobuf:"varint,14,opt,name=reset_hint,json=resetHint,proto3,enum=cortexpb.Histogram_ResetHint" json:"reset_hint,omitempty"`
 	// timestamp is in ms format
 	Timestamp int64 `protobuf:"varint,15,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// custom_values are the upper boundaries of the buckets of the native
+	// histograms with custom buckets (schema -53), in increasing order.
+	// The field number matches the one of the Prometheus Remote-Write 2.0
+	// Histogram message.
+	CustomValues []float64 `protobuf:"fixed64,16,rep,packed,name=custom_values,json=customValues,proto3" json:"custom_values,omitempty"`
 }
 
 func (m *Histogram) Reset()      { *m = Histogram{} }
@@ -904,6 +919,13 @@ func (m *Histogram) GetTimestamp() int64 {
 	return 0
 }
 
+func (m *Histogram) GetCustomValues() []float64 {
+	if m != nil {
+		return m.CustomValues
+	}
+	return nil
+}
+
 // XXX_OneofWrappers is for the internal use of the proto package.
 func (*Histogram) XXX_OneofWrappers() []interface{} {
 	return []interface{}{
@@ -1882,128 +1904,131 @@ func init() {
 func init() { proto.RegisterFile("mimir.proto", fileDescriptor_86d4d7485f544059) }
 
 var fileDescriptor_86d4d7485f544059 = []byte{
-	// 1931 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x58, 0xcf, 0x73, 0xe3, 0x58,
-	0x11, 0xb6, 0x6c, 0xf9, 0x87, 0x3a, 0x76, 0xa2, 0xbc, 0x0d, 0xb3, 0xde, 0xd4, 0x8e, 0x93, 0x11,
-	0xc5, 0x12, 0xa6, 0x20, 0x43, 0xed, 0xc2, 0x6c, 0xed, 0xd6, 0x50, 0x8b, 0x6c, 0x6b, 0x26, 0xce,
-	0xc6, 0x76, 0xf6, 0x49, 0x9e, 0x65, 0xb8, 0xa8, 0x14, 0xe7, 0x25, 0x51, 0xad, 0x65, 0x19, 0x49,
-	0x9e, 0x9d, 0x70, 0xe2, 0x02, 0x45, 0x71, 0xe2, 0xc2, 0x85, 0xe2, 0xc6, 0x85, 0xbf, 0x80, 0x7f,
-	0x80, 0xcb, 0x54, 0x51, 0x54, 0xcd, 0x71, 0xe1, 0x30, 0xc5, 0x64, 0x2e, 0x7b, 0xdc, 0x03, 0x27,
-	0x4e, 0xd4, 0xeb, 0xa7, 0x1f, 0x96, 0x93, 0xc0, 0x00, 0x73, 0x53, 0x77, 0x7f, 0xaf, 0xf5, 0xe9,
-	0xbd, 0xaf, 0x5b, 0x2d, 0xc1, 0x8a, 0xe7, 0x7a, 0x6e, 0xb0, 0x3b, 0x0b, 0xfc, 0xc8, 0x27, 0xb5,
-	0xb1, 0x1f, 0x44, 0xec, 0xc9, 0xec, 0x68, 0xf3, 0x3b, 0xa7, 0x6e, 0x74, 0x36, 0x3f, 0xda, 0x1d,
-	0xfb, 0xde, 0x9d, 0x53, 0xff, 0xd4, 0xbf, 0x83, 0x80, 0xa3, 0xf9, 0x09, 0x5a, 0x68, 0xe0, 0x95,
-	0x58, 0xa8, 0xfd, 0xb1, 0x08, 0xf5, 0x4f, 0x03, 0x37, 0x62, 0x94, 0xfd, 0x64, 0xce, 0xc2, 0x88,
-	0x1c, 0x02, 0x44, 0xae, 0xc7, 0x42, 0x16, 0xb8, 0x2c, 0x6c, 0x4a, 0xdb, 0xa5, 0x9d, 0x95, 0x77,
-	0x37, 0x76, 0x93, 0xf4, 0xbb, 0x96, 0xeb, 0x31, 0x13, 0x63, 0xed, 0xcd, 0xa7, 0xcf, 0xb7, 0x0a,
-	0x7f, 0x7b, 0xbe, 0x45, 0x0e, 0x03, 0xe6, 0x4c, 0x26, 0xfe, 0xd8, 0x4a, 0xd7, 0xd1, 0x85, 0x1c,
-	0xe4, 0x03, 0xa8, 0x98, 0xfe, 0x3c, 0x18, 0xb3, 0x66, 0x71, 0x5b, 0xda, 0x59, 0x7d, 0xf7, 0x56,
-	0x96, 0x6d, 0xf1, 0xce, 0xbb, 0x02, 0x64, 0x4c, 0xe7, 0x1e, 0x8d, 0x17, 0x90, 0x0f, 0xa1, 0xe6,
-	0xb1, 0xc8, 0x39, 0x76, 0x22, 0xa7, 0x59, 0x42, 0x2a, 0xcd, 0x6c, 0x71, 0x9f, 0x45, 0x81, 0x3b,
-	0xee, 0xc7, 0xf1, 0xb6, 0xfc, 0xf4, 0xf9, 0x96, 0x44, 0x53, 0x3c, 0xb9, 0x07, 0x9b, 0xe1, 0x67,
-	0xee, 0xcc, 0x9e, 0x38, 0x47, 0x6c, 0x62, 0x4f, 0x1d, 0x8f, 0xd9, 0x8f, 0x9d, 0x89, 0x7b, 0xec,
-	0x44, 0xae, 0x3f, 0x6d, 0x7e, 0x59, 0xdd, 0x96, 0x76, 0x6a, 0xf4, 0x4d, 0x0e, 0x39, 0xe0, 0x88,
-	0x81, 0xe3, 0xb1, 0x87, 0x69, 0x5c, 0xdb, 0x02, 0xc8, 0xf8, 0x90, 0x2a, 0x94, 0xf4, 0xc3, 0x9e,
-	0x5a, 0x20, 0x35, 0x90, 0xe9, 0xe8, 0xc0, 0x50, 0x25, 0x6d, 0x0d, 0x1a, 0x31, 0xfb, 0x70, 0xe6,
-	0x4f, 0x43, 0xa6, 0x7d, 0x04, 0xeb, 0xe8, 0x30, 0x82, 0xc0, 0x0f, 0xba, 0x2c, 0x72, 0xdc, 0x49,
-	0x48, 0x6e, 0x43, 0xb9, 0xe3, 0xcc, 0x43, 0xd6, 0x94, 0xf0, 0xd1, 0x17, 0x36, 0x12, 0x61, 0x18,
-	0xa3, 0x02, 0xa2, 0xfd, 0x43, 0x02, 0xc8, 0xb6, 0x97, 0xe8, 0x50, 0x41, 0xea, 0xc9, 0x21, 0xbc,
-	0x91, 0xad, 0x45, 0xc2, 0x87, 0x8e, 0x1b, 0xb4, 0x37, 0xe2, 0x33, 0xa8, 0xa3, 0x4b, 0x3f, 0x76,
-	0x66, 0x11, 0x0b, 0x68, 0xbc, 0x90, 0x7c, 0x17, 0xaa, 0xa1, 0xe3, 0xcd, 0x26, 0x2c, 0x6c, 0x16,
-	0x31, 0x87, 0x9a, 0xe5, 0x30, 0x31, 0x80, 0xbb, 0x56, 0xa0, 0x09, 0x8c, 0xdc, 0x05, 0x85, 0x3d,
-	0x61, 0xde, 0x6c, 0xe2, 0x04, 0x61, 0xbc, 0xe3, 0x64, 0x81, 0x73, 0x1c, 0x8a, 0x57, 0x65, 0x50,
-	0xf2, 0x01, 0xc0, 0x99, 0x1b, 0x46, 0xfe, 0x69, 0xe0, 0x78, 0x61, 0x53, 0x5e, 0x26, 0xbc, 0x97,
-	0xc4, 0xe2, 0x95, 0x0b, 0x60, 0xed, 0xfb, 0xa0, 0xa4, 0xcf, 0x43, 0x08, 0xc8, 0xfc, 0xa4, 0x70,
-	0xbb, 0xea, 0x14, 0xaf, 0xc9, 0x06, 0x94, 0x1f, 0x3b, 0x93, 0xb9, 0x90, 0x4f, 0x9d, 0x0a, 0x43,
-	0xd3, 0xa1, 0x22, 0x1e, 0x81, 0xdc, 0x82, 0x3a, 0xaa, 0x2d, 0x72, 0xbc, 0x99, 0xed, 0x85, 0x08,
-	0x2b, 0xd1, 0x95, 0xd4, 0xd7, 0x0f, 0xb3, 0x14, 0x3c, 0xaf, 0x94, 0xa4, 0xf8, 0x6d, 0x11, 0x56,
-	0xf3, 0x22, 0x22, 0xef, 0x83, 0x1c, 0x9d, 0xcf, 0x92, 0xe3, 0xfa, 0xfa, 0x75, 0x62, 0x8b, 0x4d,
-	0xeb, 0x7c, 0xc6, 0x28, 0x2e, 0x20, 0xdf, 0x06, 0xe2, 0xa1, 0xcf, 0x3e, 0x71, 0x3c, 0x77, 0x72,
-	0x8e, 0x82, 0x43, 0x2a, 0x0a, 0x55, 0x45, 0xe4, 0x3e, 0x06, 0xb8, 0xce, 0xf8, 0x63, 0x9e, 0xb1,
-	0xc9, 0xac, 0x29, 0x63, 0x1c, 0xaf, 0xb9, 0x6f, 0x3e, 0x75, 0xa3, 0x66, 0x59, 0xf8, 0xf8, 0xb5,
-	0x76, 0x0e, 0x90, 0xdd, 0x89, 0xac, 0x40, 0x75, 0x34, 0xf8, 0x78, 0x30, 0xfc, 0x74, 0xa0, 0x16,
-	0xb8, 0xd1, 0x19, 0x8e, 0x06, 0x96, 0x41, 0x55, 0x89, 0x28, 0x50, 0x7e, 0xa0, 0x8f, 0x1e, 0x18,
-	0x6a, 0x91, 0x34, 0x40, 0xd9, 0xeb, 0x99, 0xd6, 0xf0, 0x01, 0xd5, 0xfb, 0x6a, 0x89, 0x10, 0x58,
-	0xc5, 0x48, 0xe6, 0x93, 0xf9, 0x52, 0x73, 0xd4, 0xef, 0xeb, 0xf4, 0x91, 0x5a, 0xe6, 0x8a, 0xee,
-	0x0d, 0xee, 0x0f, 0xd5, 0x0a, 0xa9, 0x43, 0xcd, 0xb4, 0x74, 0xcb, 0x30, 0x0d, 0x4b, 0xad, 0x6a,
-	0x1f, 0x43, 0x45, 0xdc, 0xfa, 0x35, 0x08, 0x51, 0xfb, 0x85, 0x04, 0xb5, 0x44, 0x3c, 0xaf, 0x43,
-	0xd8, 0x39, 0x49, 0x24, 0xe7, 0x79, 0x49, 0x08, 0xa5, 0x4b, 0x42, 0xd0, 0xfe, 0x5c, 0x06, 0x25,
-	0x15, 0x23, 0xb9, 0x09, 0xca, 0xd8, 0x9f, 0x4f, 0x23, 0xdb, 0x9d, 0x46, 0x78, 0xe4, 0xf2, 0x5e,
-	0x81, 0xd6, 0xd0, 0xd5, 0x9b, 0x46, 0xe4, 0x16, 0xac, 0x88, 0xf0, 0xc9, 0xc4, 0x77, 0x22, 0x71,
-	0xaf, 0xbd, 0x02, 0x05, 0x74, 0xde, 0xe7, 0x3e, 0xa2, 0x42, 0x29, 0x9c, 0x7b, 0x78, 0x27, 0x89,
-	0xf2, 0x4b, 0x72, 0x03, 0x2a, 0xe1, 0xf8, 0x8c, 0x79, 0x0e, 0x1e, 0xee, 0x3a, 0x8d, 0x2d, 0xf2,
-	0x0d, 0x58, 0xfd, 0x29, 0x0b, 0x7c, 0x3b, 0x3a, 0x0b, 0x58, 0x78, 0xe6, 0x4f, 0x8e, 0xf1, 0xa0,
-	0x25, 0xda, 0xe0, 0x5e, 0x2b, 0x71, 0x92, 0x77, 0x62, 0x58, 0xc6, 0xab, 0x82, 0xbc, 0x24, 0x5a,
-	0xe7, 0xfe, 0x4e, 0xc2, 0xed, 0x36, 0xa8, 0x0b, 0x38, 0x41, 0xb0, 0x8a, 0x04, 0x25, 0xba, 0x9a,
-	0x22, 0x05, 0x49, 0x1d, 0x56, 0xa7, 0xec, 0xd4, 0x89, 0xdc, 0xc7, 0xcc, 0x0e, 0x67, 0xce, 0x34,
-	0x6c, 0xd6, 0x96, 0xdb, 0x7a, 0x7b, 0x3e, 0xfe, 0x8c, 0x45, 0xe6, 0xcc, 0x99, 0xc6, 0x15, 0xda,
-	0x48, 0x56, 0x70, 0x5f, 0x48, 0xbe, 0x09, 0x6b, 0x69, 0x8a, 0x63, 0x36, 0x89, 0x9c, 0xb0, 0xa9,
-	0x6c, 0x97, 0x76, 0x08, 0x4d, 0x33, 0x77, 0xd1, 0x9b, 0x03, 0x22, 0xb7, 0xb0, 0x09, 0xdb, 0xa5,
-	0x1d, 0x29, 0x03, 0x22, 0x31, 0xde, 0xde, 0x56, 0x67, 0x7e, 0xe8, 0x2e, 0x90, 0x5a, 0xf9, 0xcf,
-	0xa4, 0x92, 0x15, 0x29, 0xa9, 0x34, 0x45, 0x4c, 0xaa, 0x2e, 0x48, 0x25, 0xee, 0x8c, 0x54, 0x0a,
-	0x8c, 0x49, 0x35, 0x04, 0xa9, 0xc4, 0x1d, 0x93, 0xba, 0x07, 0x10, 0xb0, 0x90, 0x45, 0xf6, 0x19,
-	0xdf, 0xf9, 0x55, 0x6c, 0x02, 0x37, 0xaf, 0x68, 0x63, 0xbb, 0x94, 0xa3, 0xf6, 0xdc, 0x69, 0x44,
-	0x95, 0x20, 0xb9, 0x24, 0x6f, 0x83, 0x92, 0x6a, 0xad, 0xb9, 0x86, 0xe2, 0xcb, 0x1c, 0xda, 0x87,
-	0xa0, 0xa4, 0xab, 0xf2, 0xa5, 0x5c, 0x85, 0xd2, 0x23, 0xc3, 0x54, 0x25, 0x52, 0x81, 0xe2, 0x60,
-	0xa8, 0x16, 0xb3, 0x72, 0x2e, 0x6d, 0xca, 0xbf, 0xfc, 0x7d, 0x4b, 0x6a, 0x57, 0xa1, 0x8c, 0xbc,
-	0xdb, 0x75, 0x80, 0xec, 0xd8, 0xb5, 0xbf, 0xc8, 0xb0, 0x8a, 0x47, 0x9c, 0x49, 0x3a, 0x04, 0x82,
-	0x31, 0x16, 0xd8, 0x4b, 0x4f, 0xd2, 0x68, 0x1b, 0xff, 0x7c, 0xbe, 0xa5, 0x2f, 0x8c, 0x07, 0xb3,
-	0xc0, 0xf7, 0x58, 0x74, 0xc6, 0xe6, 0xe1, 0xe2, 0xa5, 0xe7, 0x1f, 0xb3, 0xc9, 0x9d, 0xb4, 0x41,
-	0xef, 0x76, 0x44, 0xba, 0xec, 0x89, 0xd5, 0xf1, 0x92, 0xe7, 0xff, 0xd5, 0xfc, 0xcd, 0xc5, 0x87,
-	0x12, 0x2a, 0xa6, 0x4a, 0xaa, 0x61, 0x5e, 0xec, 0x22, 0x12, 0x17, 0x3b, 0x1a, 0x57, 0x54, 0xde,
-	0x6b, 0x50, 0xd4, 0x6b, 0xa8, 0x94, 0x6f, 0x81, 0x9a, 0xb2, 0x38, 0x42, 0x6c, 0x22, 0xb6, 0x54,
-	0x83, 0x22, 0x05, 0x42, 0xd3, 0xbb, 0x25, 0x50, 0x51, 0x2c, 0x69, 0x0d, 0xc5, 0xd0, 0x7d, 0xb9,
-	0x26, 0xa9, 0xc5, 0x7d, 0xb9, 0x56, 0x51, 0xab, 0xfb, 0x72, 0x4d, 0x51, 0x61, 0x5f, 0xae, 0xd5,
-	0xd5, 0xc6, 0xbe, 0x5c, 0x5b, 0x53, 0x55, 0x9a, 0x75, 0x31, 0xba, 0xd4, 0x3d, 0xe8, 0x72, 0xd9,
-	0xd2, 0xe5, 0x92, 0x59, 0x94, 0xe8, 0x3d, 0x80, 0xec, 0xf1, 0xf8, 0xa9, 0xfa, 0x27, 0x27, 0x21,
-	0x13, 0xad, 0x71, 0x9d, 0xc6, 0x16, 0xf7, 0x4f, 0xd8, 0xf4, 0x34, 0x3a, 0xc3, 0x03, 0x69, 0xd0,
-	0xd8, 0xd2, 0xe6, 0x40, 0xf2, 0x62, 0xc4, 0x37, 0xfa, 0x2b, 0xbc, 0x9d, 0xef, 0x81, 0x92, 0xca,
-	0x0d, 0xef, 0x95, 0x1b, 0xf3, 0xf2, 0x39, 0xe3, 0x31, 0x2f, 0x5b, 0xa0, 0x4d, 0x61, 0x4d, 0x0c,
-	0x02, 0x59, 0x11, 0xa4, 0x8a, 0x91, 0xae, 0x50, 0x4c, 0x31, 0x53, 0xcc, 0x7b, 0x50, 0x4d, 0xf6,
-	0x5d, 0xcc, 0x3a, 0x6f, 0x5d, 0x35, 0xb2, 0x20, 0x82, 0x26, 0x48, 0x2d, 0x84, 0xb5, 0xa5, 0x18,
-	0x69, 0x01, 0x1c, 0xf9, 0xf3, 0xe9, 0xb1, 0x13, 0xcf, 0xcc, 0xd2, 0x4e, 0x99, 0x2e, 0x78, 0x38,
-	0x9f, 0x89, 0xff, 0x39, 0x0b, 0x12, 0x05, 0xa3, 0xc1, 0xbd, 0xf3, 0xd9, 0x8c, 0x05, 0xb1, 0x86,
-	0x85, 0x91, 0x71, 0x97, 0x17, 0xb8, 0x6b, 0x13, 0x78, 0x63, 0xe9, 0x21, 0x71, 0x73, 0x73, 0x1d,
-	0xa7, 0xb8, 0xd4, 0x71, 0xc8, 0xfb, 0x97, 0xf7, 0xf5, 0xad, 0xe5, 0x01, 0x30, 0xcd, 0xb7, 0xb8,
-	0xa5, 0x7f, 0x92, 0xa1, 0xf1, 0xc9, 0x9c, 0x05, 0xe7, 0xc9, 0x70, 0x4b, 0xee, 0x42, 0x25, 0x8c,
-	0x9c, 0x68, 0x1e, 0xc6, 0x93, 0x51, 0x2b, 0xcb, 0x93, 0x03, 0xee, 0x9a, 0x88, 0xa2, 0x31, 0x9a,
-	0xfc, 0x10, 0x80, 0xf1, 0x41, 0xd7, 0xc6, 0xa9, 0xea, 0xd2, 0xfc, 0x9f, 0x5f, 0x8b, 0x23, 0x31,
-	0xce, 0x54, 0x0a, 0x4b, 0x2e, 0xf9, 0x7e, 0xa0, 0x81, 0xbb, 0xa4, 0x50, 0x61, 0x90, 0x5d, 0xce,
-	0x27, 0x70, 0xa7, 0xa7, 0xb8, 0x4d, 0xb9, 0x02, 0x35, 0xd1, 0xdf, 0x75, 0x22, 0x67, 0xaf, 0x40,
-	0x63, 0x14, 0xc7, 0x3f, 0x66, 0xe3, 0xc8, 0x0f, 0xb0, 0x03, 0xe5, 0xf0, 0x0f, 0xd1, 0x9f, 0xe0,
-	0x05, 0x0a, 0xf3, 0x8f, 0x9d, 0x89, 0x13, 0xe0, 0xeb, 0x37, 0x9f, 0x1f, 0xfd, 0x69, 0x7e, 0xb4,
-	0x38, 0xde, 0x73, 0xa2, 0xc0, 0x7d, 0x82, 0xed, 0x2b, 0x87, 0xef, 0xa3, 0x3f, 0xc1, 0x0b, 0x14,
-	0xd9, 0x84, 0xda, 0xe7, 0x4e, 0x30, 0x75, 0xa7, 0xa7, 0xa2, 0xc5, 0x28, 0x34, 0xb5, 0xb5, 0x77,
-	0xa0, 0x22, 0x76, 0x91, 0xbf, 0x07, 0x0c, 0x4a, 0x87, 0x54, 0x8c, 0x7b, 0xe6, 0xa8, 0xd3, 0x31,
-	0x4c, 0x53, 0x95, 0xc4, 0x4b, 0x41, 0xfb, 0x8d, 0x04, 0x4a, 0xba, 0x65, 0x7c, 0x8e, 0x1b, 0x0c,
-	0x07, 0x86, 0x80, 0x5a, 0xbd, 0xbe, 0x31, 0x1c, 0x59, 0xaa, 0xc4, 0x87, 0xba, 0x8e, 0x3e, 0xe8,
-	0x18, 0x07, 0x46, 0x57, 0x0c, 0x87, 0xc6, 0x8f, 0x8c, 0xce, 0xc8, 0xea, 0x0d, 0x07, 0x6a, 0x89,
-	0x07, 0xdb, 0x7a, 0xd7, 0xee, 0xea, 0x96, 0xae, 0xca, 0xdc, 0xea, 0xf1, 0x79, 0x72, 0xa0, 0x1f,
-	0xa8, 0x65, 0xb2, 0x06, 0x2b, 0xa3, 0x81, 0xfe, 0x50, 0xef, 0x1d, 0xe8, 0xed, 0x03, 0x43, 0xad,
-	0xf0, 0xb5, 0x83, 0xa1, 0x65, 0xdf, 0x1f, 0x8e, 0x06, 0x5d, 0xb5, 0xca, 0x07, 0x4b, 0x6e, 0xea,
-	0x9d, 0x8e, 0x71, 0x68, 0x21, 0xa4, 0x16, 0xbf, 0xac, 0x2a, 0x20, 0xf3, 0x19, 0x59, 0x33, 0x00,
-	0xb2, 0xb3, 0xc8, 0x8f, 0xe0, 0xca, 0x75, 0x23, 0xdb, 0xe5, 0xee, 0xa0, 0xfd, 0x5c, 0x02, 0xc8,
-	0xce, 0x88, 0xdc, 0xcd, 0xbe, 0x69, 0xc4, 0xf8, 0x78, 0x63, 0xf9, 0x28, 0xaf, 0xfe, 0xb2, 0xf9,
-	0x28, 0xf7, 0x85, 0x52, 0x5c, 0x2e, 0x77, 0xb1, 0xf4, 0xdf, 0x7d, 0xa7, 0xd8, 0x50, 0x5f, 0xcc,
-	0xcf, 0xdb, 0xa0, 0x98, 0xeb, 0x91, 0x87, 0x42, 0x63, 0xeb, 0x7f, 0x9f, 0x4d, 0x7f, 0x25, 0xc1,
-	0xda, 0x12, 0x8d, 0x6b, 0x6f, 0x92, 0x6b, 0x99, 0xc5, 0x57, 0x68, 0x99, 0x85, 0x85, 0xfa, 0x7e,
-	0x15, 0x32, 0xfc, 0xf0, 0x52, 0xa1, 0x5f, 0xfd, 0xfd, 0xf4, 0x2a, 0x87, 0xd7, 0x06, 0xc8, 0xf4,
-	0x4f, 0xbe, 0x07, 0x95, 0xdc, 0x7f, 0x85, 0x1b, 0xcb, 0x55, 0x12, 0xff, 0x59, 0x10, 0x84, 0x63,
-	0xac, 0xf6, 0x3b, 0x09, 0xea, 0x8b, 0xe1, 0x6b, 0x37, 0xe5, 0xbf, 0xff, 0xdc, 0x6d, 0xe7, 0x44,
-	0x21, 0xde, 0x01, 0x6f, 0x5f, 0xb7, 0x8f, 0xf8, 0x5d, 0x72, 0x49, 0x17, 0xb7, 0xff, 0x2a, 0x01,
-	0x64, 0x1f, 0xf3, 0x64, 0x1d, 0x1a, 0xf1, 0x64, 0x67, 0x77, 0xf4, 0x91, 0xc9, 0x0b, 0x72, 0x13,
-	0x6e, 0x50, 0xe3, 0xf0, 0xa0, 0xd7, 0xd1, 0x4d, 0xbb, 0xdb, 0xeb, 0xda, 0xbc, 0x6e, 0xfa, 0xba,
-	0xd5, 0xd9, 0x53, 0x25, 0xf2, 0x35, 0x58, 0xb7, 0x86, 0x43, 0xbb, 0xaf, 0x0f, 0x1e, 0xd9, 0x9d,
-	0x83, 0x91, 0x69, 0x19, 0xd4, 0x54, 0x8b, 0xb9, 0xca, 0x2c, 0xf1, 0x04, 0xbd, 0xc1, 0x03, 0xc3,
-	0xe4, 0x65, 0x6b, 0x53, 0xdd, 0x32, 0xec, 0x83, 0x5e, 0xbf, 0x67, 0x19, 0x5d, 0x55, 0x26, 0x4d,
-	0xd8, 0xa0, 0xc6, 0x27, 0x23, 0xc3, 0xb4, 0xf2, 0x91, 0x32, 0xaf, 0xd0, 0xde, 0xc0, 0xb4, 0x78,
-	0xf5, 0x0b, 0xaf, 0x5a, 0x21, 0x6f, 0xc2, 0x1b, 0xa6, 0x41, 0x1f, 0xf6, 0x3a, 0x86, 0xbd, 0x58,
-	0xdd, 0x55, 0xb2, 0x01, 0xaa, 0x65, 0x76, 0xdb, 0x39, 0x6f, 0xad, 0xfd, 0x83, 0x67, 0x2f, 0x5a,
-	0x85, 0x2f, 0x5e, 0xb4, 0x0a, 0x5f, 0xbd, 0x68, 0x49, 0x3f, 0xbb, 0x68, 0x49, 0x7f, 0xb8, 0x68,
-	0x49, 0x4f, 0x2f, 0x5a, 0xd2, 0xb3, 0x8b, 0x96, 0xf4, 0xf7, 0x8b, 0x96, 0xf4, 0xe5, 0x45, 0xab,
-	0xf0, 0xd5, 0x45, 0x4b, 0xfa, 0xf5, 0xcb, 0x56, 0xe1, 0xd9, 0xcb, 0x56, 0xe1, 0x8b, 0x97, 0xad,
-	0xc2, 0x8f, 0xab, 0xf8, 0x67, 0x6a, 0x76, 0x74, 0x54, 0xc1, 0x7f, 0x4c, 0xef, 0xfd, 0x2b, 0x00,
-	0x00, 0xff, 0xff, 0x30, 0x35, 0x96, 0x64, 0xab, 0x12, 0x00, 0x00,
+	// 1971 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x58, 0xcf, 0x73, 0xdc, 0x48,
+	0xf5, 0x1f, 0xcd, 0x68, 0x7e, 0xe8, 0x79, 0xc6, 0x6e, 0x77, 0xf2, 0xcd, 0x6a, 0x5d, 0x9b, 0x89,
+	0xa3, 0xad, 0xef, 0x62, 0x02, 0x38, 0xd4, 0x2e, 0x64, 0x6b, 0xb7, 0x42, 0x2d, 0x9a, 0x19, 0x25,
+	0x1e, 0xaf, 0x67, 0xc6, 0xdb, 0xd2, 0x78, 0x09, 0x17, 0x95, 0x3c, 0x6e, 0xdb, 0xaa, 0x95, 0x46,
+	0x83, 0xa4, 0xc9, 0xc6, 0x9c, 0xb8, 0x40, 0x51, 0x9c, 0xb8, 0x70, 0xa1, 0xb8, 0x50, 0x5c, 0xf8,
+	0x0b, 0xf8, 0x07, 0xb8, 0xe4, 0x42, 0x55, 0x8e, 0x0b, 0x87, 0x14, 0x71, 0x2e, 0x7b, 0xcc, 0x99,
+	0x13, 0xd5, 0xdd, 0xfa, 0x31, 0x1a, 0xdb, 0x10, 0x20, 0x37, 0xbd, 0xf7, 0x3e, 0xfd, 0xf4, 0xd1,
+	0xeb, 0xcf, 0x6b, 0x3d, 0x09, 0x56, 0x7c, 0xd7, 0x77, 0xc3, 0xed, 0x59, 0x18, 0xc4, 0x01, 0x6e,
+	0x4c, 0x82, 0x30, 0xa6, 0x4f, 0x66, 0x87, 0x1b, 0xdf, 0x39, 0x71, 0xe3, 0xd3, 0xf9, 0xe1, 0xf6,
+	0x24, 0xf0, 0xef, 0x9e, 0x04, 0x27, 0xc1, 0x5d, 0x0e, 0x38, 0x9c, 0x1f, 0x73, 0x8b, 0x1b, 0xfc,
+	0x4a, 0x2c, 0xd4, 0xfe, 0x54, 0x86, 0xe6, 0xe7, 0xa1, 0x1b, 0x53, 0x42, 0x7f, 0x32, 0xa7, 0x51,
+	0x8c, 0xf7, 0x01, 0x62, 0xd7, 0xa7, 0x11, 0x0d, 0x5d, 0x1a, 0xa9, 0xd2, 0x66, 0x65, 0x6b, 0xe5,
+	0xfd, 0xeb, 0xdb, 0x69, 0xfa, 0x6d, 0xcb, 0xf5, 0xa9, 0xc9, 0x63, 0x9d, 0x8d, 0xa7, 0xcf, 0x6f,
+	0x95, 0xfe, 0xf6, 0xfc, 0x16, 0xde, 0x0f, 0xa9, 0xe3, 0x79, 0xc1, 0xc4, 0xca, 0xd6, 0x91, 0x85,
+	0x1c, 0xf8, 0x23, 0xa8, 0x99, 0xc1, 0x3c, 0x9c, 0x50, 0xb5, 0xbc, 0x29, 0x6d, 0xad, 0xbe, 0x7f,
+	0x3b, 0xcf, 0xb6, 0x78, 0xe7, 0x6d, 0x01, 0x32, 0xa6, 0x73, 0x9f, 0x24, 0x0b, 0xf0, 0xc7, 0xd0,
+	0xf0, 0x69, 0xec, 0x1c, 0x39, 0xb1, 0xa3, 0x56, 0x38, 0x15, 0x35, 0x5f, 0x3c, 0xa0, 0x71, 0xe8,
+	0x4e, 0x06, 0x49, 0xbc, 0x23, 0x3f, 0x7d, 0x7e, 0x4b, 0x22, 0x19, 0x1e, 0xdf, 0x87, 0x8d, 0xe8,
+	0x0b, 0x77, 0x66, 0x7b, 0xce, 0x21, 0xf5, 0xec, 0xa9, 0xe3, 0x53, 0xfb, 0xb1, 0xe3, 0xb9, 0x47,
+	0x4e, 0xec, 0x06, 0x53, 0xf5, 0xeb, 0xfa, 0xa6, 0xb4, 0xd5, 0x20, 0x6f, 0x31, 0xc8, 0x1e, 0x43,
+	0x0c, 0x1d, 0x9f, 0x1e, 0x64, 0x71, 0xed, 0x16, 0x40, 0xce, 0x07, 0xd7, 0xa1, 0xa2, 0xef, 0xf7,
+	0x51, 0x09, 0x37, 0x40, 0x26, 0xe3, 0x3d, 0x03, 0x49, 0xda, 0x1a, 0xb4, 0x12, 0xf6, 0xd1, 0x2c,
+	0x98, 0x46, 0x54, 0xfb, 0x04, 0xd6, 0xb9, 0xc3, 0x08, 0xc3, 0x20, 0xec, 0xd1, 0xd8, 0x71, 0xbd,
+	0x08, 0xdf, 0x81, 0x6a, 0xd7, 0x99, 0x47, 0x54, 0x95, 0xf8, 0xa3, 0x2f, 0x14, 0x92, 0xc3, 0x78,
+	0x8c, 0x08, 0x88, 0xf6, 0xfb, 0x32, 0x40, 0x5e, 0x5e, 0xac, 0x43, 0x8d, 0x53, 0x4f, 0x37, 0xe1,
+	0x5a, 0xbe, 0x96, 0x13, 0xde, 0x77, 0xdc, 0xb0, 0x73, 0x3d, 0xd9, 0x83, 0x26, 0x77, 0xe9, 0x47,
+	0xce, 0x2c, 0xa6, 0x21, 0x49, 0x16, 0xe2, 0xef, 0x42, 0x3d, 0x72, 0xfc, 0x99, 0x47, 0x23, 0xb5,
+	0xcc, 0x73, 0xa0, 0x3c, 0x87, 0xc9, 0x03, 0xbc, 0x6a, 0x25, 0x92, 0xc2, 0xf0, 0x3d, 0x50, 0xe8,
+	0x13, 0xea, 0xcf, 0x3c, 0x27, 0x8c, 0x92, 0x8a, 0xe3, 0x05, 0xce, 0x49, 0x28, 0x59, 0x95, 0x43,
+	0xf1, 0x47, 0x00, 0xa7, 0x6e, 0x14, 0x07, 0x27, 0xa1, 0xe3, 0x47, 0xaa, 0xbc, 0x4c, 0x78, 0x27,
+	0x8d, 0x25, 0x2b, 0x17, 0xc0, 0xf8, 0x5b, 0xb0, 0x3e, 0x09, 0xa9, 0x13, 0xd3, 0x23, 0x9b, 0x8b,
+	0x26, 0x76, 0xfc, 0x99, 0x5a, 0xdb, 0x94, 0xb6, 0x2a, 0x04, 0x25, 0x01, 0x2b, 0xf5, 0x6b, 0xdf,
+	0x07, 0x25, 0x7b, 0x78, 0x8c, 0x41, 0x66, 0xdb, 0xca, 0x6b, 0xdb, 0x24, 0xfc, 0x1a, 0x5f, 0x87,
+	0xea, 0x63, 0xc7, 0x9b, 0x0b, 0xad, 0x35, 0x89, 0x30, 0x34, 0x1d, 0x6a, 0xe2, 0x79, 0xf1, 0x6d,
+	0x68, 0x66, 0x77, 0xb1, 0xfd, 0x88, 0xc3, 0x2a, 0x64, 0x25, 0xf3, 0x0d, 0xa2, 0x3c, 0x05, 0xcb,
+	0x2b, 0xa5, 0x29, 0x7e, 0x5b, 0x86, 0xd5, 0xa2, 0xe2, 0xf0, 0x87, 0x20, 0xc7, 0x67, 0xb3, 0x74,
+	0x6f, 0xdf, 0xbd, 0x4a, 0x99, 0x89, 0x69, 0x9d, 0xcd, 0x28, 0xe1, 0x0b, 0xf0, 0xb7, 0x01, 0xfb,
+	0xdc, 0x67, 0x1f, 0x3b, 0xbe, 0xeb, 0x9d, 0x71, 0x75, 0x72, 0x2a, 0x0a, 0x41, 0x22, 0xf2, 0x80,
+	0x07, 0x98, 0x28, 0xd9, 0x63, 0x9e, 0x52, 0x6f, 0xa6, 0xca, 0x3c, 0xce, 0xaf, 0x99, 0x6f, 0x3e,
+	0x75, 0x63, 0xb5, 0x2a, 0x7c, 0xec, 0x5a, 0x3b, 0x03, 0xc8, 0xef, 0x84, 0x57, 0xa0, 0x3e, 0x1e,
+	0x7e, 0x3a, 0x1c, 0x7d, 0x3e, 0x44, 0x25, 0x66, 0x74, 0x47, 0xe3, 0xa1, 0x65, 0x10, 0x24, 0x61,
+	0x05, 0xaa, 0x0f, 0xf5, 0xf1, 0x43, 0x03, 0x95, 0x71, 0x0b, 0x94, 0x9d, 0xbe, 0x69, 0x8d, 0x1e,
+	0x12, 0x7d, 0x80, 0x2a, 0x18, 0xc3, 0x2a, 0x8f, 0xe4, 0x3e, 0x99, 0x2d, 0x35, 0xc7, 0x83, 0x81,
+	0x4e, 0x1e, 0xa1, 0x2a, 0x93, 0x7f, 0x7f, 0xf8, 0x60, 0x84, 0x6a, 0xb8, 0x09, 0x0d, 0xd3, 0xd2,
+	0x2d, 0xc3, 0x34, 0x2c, 0x54, 0xd7, 0x3e, 0x85, 0x9a, 0xb8, 0xf5, 0x1b, 0x50, 0xad, 0xf6, 0x0b,
+	0x09, 0x1a, 0xa9, 0xd2, 0xde, 0x44, 0x17, 0x14, 0x24, 0x91, 0xee, 0xe7, 0x05, 0x21, 0x54, 0x2e,
+	0x08, 0x41, 0x7b, 0x55, 0x05, 0x25, 0x53, 0x2e, 0xbe, 0x09, 0xca, 0x24, 0x98, 0x4f, 0x63, 0xdb,
+	0x9d, 0xc6, 0x7c, 0xcb, 0xe5, 0x9d, 0x12, 0x69, 0x70, 0x57, 0x7f, 0x1a, 0xe3, 0xdb, 0xb0, 0x22,
+	0xc2, 0xc7, 0x5e, 0xe0, 0xc4, 0xe2, 0x5e, 0x3b, 0x25, 0x02, 0xdc, 0xf9, 0x80, 0xf9, 0x30, 0x82,
+	0x4a, 0x34, 0xf7, 0xf9, 0x9d, 0x24, 0xc2, 0x2e, 0xf1, 0x0d, 0xa8, 0x45, 0x93, 0x53, 0xea, 0x3b,
+	0x7c, 0x73, 0xd7, 0x49, 0x62, 0xe1, 0xff, 0x87, 0xd5, 0x9f, 0xd2, 0x30, 0xb0, 0xe3, 0xd3, 0x90,
+	0x46, 0xa7, 0x81, 0x77, 0xc4, 0x37, 0x5a, 0x22, 0x2d, 0xe6, 0xb5, 0x52, 0x27, 0x7e, 0x2f, 0x81,
+	0xe5, 0xbc, 0x6a, 0x9c, 0x97, 0x44, 0x9a, 0xcc, 0xdf, 0x4d, 0xb9, 0xdd, 0x01, 0xb4, 0x80, 0x13,
+	0x04, 0xeb, 0x9c, 0xa0, 0x44, 0x56, 0x33, 0xa4, 0x20, 0xa9, 0xc3, 0xea, 0x94, 0x9e, 0x38, 0xb1,
+	0xfb, 0x98, 0xda, 0xd1, 0xcc, 0x99, 0x46, 0x6a, 0x63, 0xf9, 0x1d, 0xd0, 0x99, 0x4f, 0xbe, 0xa0,
+	0xb1, 0x39, 0x73, 0xa6, 0x49, 0x3b, 0xb7, 0xd2, 0x15, 0xcc, 0x17, 0xe1, 0x6f, 0xc0, 0x5a, 0x96,
+	0xe2, 0x88, 0x7a, 0xb1, 0x13, 0xa9, 0xca, 0x66, 0x65, 0x0b, 0x93, 0x2c, 0x73, 0x8f, 0x7b, 0x0b,
+	0x40, 0xce, 0x2d, 0x52, 0x61, 0xb3, 0xb2, 0x25, 0xe5, 0x40, 0x4e, 0x8c, 0x9d, 0x85, 0xab, 0xb3,
+	0x20, 0x72, 0x17, 0x48, 0xad, 0xfc, 0x7b, 0x52, 0xe9, 0x8a, 0x8c, 0x54, 0x96, 0x22, 0x21, 0xd5,
+	0x14, 0xa4, 0x52, 0x77, 0x4e, 0x2a, 0x03, 0x26, 0xa4, 0x5a, 0x82, 0x54, 0xea, 0x4e, 0x48, 0xdd,
+	0x07, 0x08, 0x69, 0x44, 0x63, 0xfb, 0x94, 0x55, 0x7e, 0x95, 0x1f, 0x02, 0x37, 0x2f, 0x39, 0xf3,
+	0xb6, 0x09, 0x43, 0xed, 0xb8, 0xd3, 0x98, 0x28, 0x61, 0x7a, 0x89, 0xdf, 0x01, 0x25, 0x3f, 0xee,
+	0xd6, 0xb8, 0xf8, 0x72, 0x07, 0x7e, 0x17, 0x5a, 0x93, 0x79, 0x14, 0x07, 0xbe, 0xcd, 0xd5, 0x1a,
+	0xa9, 0x88, 0x53, 0x68, 0x0a, 0xe7, 0x01, 0xf7, 0x69, 0x1f, 0x83, 0x92, 0xa5, 0x2e, 0xf6, 0x7b,
+	0x1d, 0x2a, 0x8f, 0x0c, 0x13, 0x49, 0xb8, 0x06, 0xe5, 0xe1, 0x08, 0x95, 0xf3, 0x9e, 0xaf, 0x6c,
+	0xc8, 0xbf, 0xfc, 0x43, 0x5b, 0xea, 0xd4, 0xa1, 0xca, 0x1f, 0xae, 0xd3, 0x04, 0xc8, 0xb5, 0xa1,
+	0xfd, 0x45, 0x86, 0x55, 0xae, 0x83, 0x5c, 0xf7, 0x11, 0x60, 0x1e, 0xa3, 0xa1, 0xbd, 0xf4, 0xb8,
+	0xad, 0x8e, 0xf1, 0x8f, 0xe7, 0xb7, 0xf4, 0x85, 0x81, 0x63, 0x16, 0x06, 0x3e, 0x8d, 0x4f, 0xe9,
+	0x3c, 0x5a, 0xbc, 0xf4, 0x83, 0x23, 0xea, 0xdd, 0xcd, 0x8e, 0xfc, 0xed, 0xae, 0x48, 0x97, 0x97,
+	0x05, 0x4d, 0x96, 0x3c, 0xff, 0x6b, 0x63, 0xdc, 0x5c, 0x7c, 0x28, 0x21, 0x75, 0xa2, 0x64, 0x42,
+	0x67, 0x27, 0x82, 0x88, 0x24, 0x27, 0x02, 0x37, 0x2e, 0x69, 0xcf, 0x37, 0x20, 0xbb, 0x37, 0xd0,
+	0x4e, 0xdf, 0x04, 0x94, 0xb1, 0x38, 0xe4, 0xd8, 0x54, 0x91, 0x99, 0x50, 0x45, 0x0a, 0x0e, 0xcd,
+	0xee, 0x96, 0x42, 0x45, 0x47, 0x65, 0x8d, 0x96, 0x40, 0x77, 0xe5, 0x86, 0x84, 0xca, 0xbb, 0x72,
+	0xa3, 0x86, 0xea, 0xbb, 0x72, 0x43, 0x41, 0xb0, 0x2b, 0x37, 0x9a, 0xa8, 0xb5, 0x2b, 0x37, 0xd6,
+	0x10, 0x22, 0xf9, 0x51, 0x47, 0x96, 0x8e, 0x18, 0xb2, 0xdc, 0xdb, 0x64, 0xb9, 0xaf, 0x16, 0x74,
+	0xac, 0xdd, 0x07, 0xc8, 0x1f, 0x8f, 0xed, 0x6a, 0x70, 0x7c, 0x1c, 0x51, 0x71, 0x7e, 0xae, 0x93,
+	0xc4, 0x62, 0x7e, 0x8f, 0x4e, 0x4f, 0xe2, 0x53, 0xbe, 0x21, 0x2d, 0x92, 0x58, 0xda, 0x1c, 0x70,
+	0x51, 0x8c, 0xfc, 0xb5, 0xff, 0x1a, 0xaf, 0xf0, 0xfb, 0xa0, 0x64, 0x72, 0xe3, 0xf7, 0x2a, 0x0c,
+	0x8e, 0xc5, 0x9c, 0xc9, 0xe0, 0x98, 0x2f, 0xd0, 0xa6, 0xb0, 0x26, 0xa6, 0x85, 0xbc, 0x09, 0x32,
+	0xc5, 0x48, 0x97, 0x28, 0xa6, 0x9c, 0x2b, 0xe6, 0x03, 0xa8, 0xa7, 0x75, 0x17, 0xd3, 0xd3, 0xdb,
+	0x97, 0x0d, 0x41, 0x1c, 0x41, 0x52, 0xa4, 0x16, 0xc1, 0xda, 0x52, 0x0c, 0xb7, 0x01, 0x0e, 0x83,
+	0xf9, 0xf4, 0xc8, 0x49, 0xa6, 0x70, 0x69, 0xab, 0x4a, 0x16, 0x3c, 0x8c, 0x8f, 0x17, 0x7c, 0x49,
+	0xc3, 0x54, 0xc1, 0xdc, 0x60, 0xde, 0xf9, 0x6c, 0x46, 0xc3, 0x44, 0xc3, 0xc2, 0xc8, 0xb9, 0xcb,
+	0x0b, 0xdc, 0x35, 0x0f, 0xae, 0x2d, 0x3d, 0x24, 0x2f, 0x6e, 0xe1, 0x58, 0x2a, 0x2f, 0x1f, 0x4b,
+	0x1f, 0x5e, 0xac, 0xeb, 0xdb, 0xcb, 0x23, 0x65, 0x96, 0x6f, 0xb1, 0xa4, 0x7f, 0x96, 0xa1, 0xf5,
+	0xd9, 0x9c, 0x86, 0x67, 0xe9, 0xb8, 0x8c, 0xef, 0x41, 0x2d, 0x8a, 0x9d, 0x78, 0x1e, 0x25, 0xe3,
+	0x53, 0x3b, 0xcf, 0x53, 0x00, 0x6e, 0x9b, 0x1c, 0x45, 0x12, 0x34, 0xfe, 0x21, 0x00, 0x65, 0xa3,
+	0xb3, 0xcd, 0x47, 0xaf, 0x0b, 0x5f, 0x14, 0xc5, 0xb5, 0x7c, 0xc8, 0xe6, 0x83, 0x97, 0x42, 0xd3,
+	0x4b, 0x56, 0x0f, 0x6e, 0xf0, 0x2a, 0x29, 0x44, 0x18, 0x78, 0x9b, 0xf1, 0x09, 0xdd, 0xe9, 0x09,
+	0x2f, 0x53, 0xa1, 0x41, 0x4d, 0xee, 0xef, 0x39, 0xb1, 0xb3, 0x53, 0x22, 0x09, 0x8a, 0xe1, 0x1f,
+	0xd3, 0x49, 0x1c, 0x84, 0x6a, 0x75, 0x19, 0x7f, 0xc0, 0xfd, 0x29, 0x5e, 0xa0, 0x78, 0xfe, 0x89,
+	0xe3, 0x39, 0xa1, 0x5a, 0x5b, 0xc6, 0x9b, 0xdc, 0x9f, 0xe5, 0xe7, 0x16, 0xc3, 0xfb, 0x4e, 0x1c,
+	0xba, 0x4f, 0xd4, 0xfa, 0x32, 0x7e, 0xc0, 0xfd, 0x29, 0x5e, 0xa0, 0xf0, 0x06, 0x34, 0xbe, 0x74,
+	0xc2, 0xa9, 0x3b, 0x3d, 0x11, 0x47, 0x8c, 0x42, 0x32, 0x5b, 0x7b, 0x0f, 0x6a, 0xa2, 0x8a, 0xec,
+	0x3d, 0x60, 0x10, 0x32, 0x22, 0x62, 0x26, 0x34, 0xc7, 0xdd, 0xae, 0x61, 0x9a, 0x48, 0x12, 0x2f,
+	0x05, 0xed, 0x37, 0x12, 0x28, 0x59, 0xc9, 0xd8, 0xb0, 0x37, 0x1c, 0x0d, 0x0d, 0x01, 0xb5, 0xfa,
+	0x03, 0x63, 0x34, 0xb6, 0x90, 0xc4, 0x26, 0xbf, 0xae, 0x3e, 0xec, 0x1a, 0x7b, 0x46, 0x4f, 0x4c,
+	0x90, 0xc6, 0x8f, 0x8c, 0xee, 0xd8, 0xea, 0x8f, 0x86, 0xa8, 0xc2, 0x82, 0x1d, 0xbd, 0x67, 0xf7,
+	0x74, 0x4b, 0x47, 0x32, 0xb3, 0xfa, 0x6c, 0xe8, 0x1c, 0xea, 0x7b, 0xa8, 0x8a, 0xd7, 0x60, 0x65,
+	0x3c, 0xd4, 0x0f, 0xf4, 0xfe, 0x9e, 0xde, 0xd9, 0x33, 0x50, 0x8d, 0xad, 0x1d, 0x8e, 0x2c, 0xfb,
+	0xc1, 0x68, 0x3c, 0xec, 0xa1, 0x3a, 0x9b, 0x3e, 0x99, 0xa9, 0x77, 0xbb, 0xc6, 0xbe, 0xc5, 0x21,
+	0x8d, 0xe4, 0x65, 0x55, 0x03, 0x99, 0x0d, 0xd2, 0x9a, 0x01, 0x90, 0xef, 0x45, 0x71, 0x4e, 0x57,
+	0xae, 0x9a, 0xeb, 0x2e, 0x9e, 0x0e, 0xda, 0xcf, 0x25, 0x80, 0x7c, 0x8f, 0xf0, 0xbd, 0xfc, 0x2b,
+	0x49, 0xcc, 0x98, 0x37, 0x96, 0xb7, 0xf2, 0xf2, 0x6f, 0xa5, 0x4f, 0x0a, 0xdf, 0x3c, 0xe5, 0xe5,
+	0x76, 0x17, 0x4b, 0xff, 0xc5, 0x97, 0x8f, 0x66, 0x43, 0x73, 0x31, 0x3f, 0x3b, 0x06, 0xc5, 0xf0,
+	0xcf, 0x79, 0x28, 0x24, 0xb1, 0xfe, 0xfb, 0x01, 0xf6, 0x57, 0x12, 0xac, 0x2d, 0xd1, 0xb8, 0xf2,
+	0x26, 0x85, 0x23, 0xb3, 0xfc, 0x1a, 0x47, 0x66, 0x69, 0xa1, 0xbf, 0x5f, 0x87, 0x0c, 0xdb, 0xbc,
+	0x4c, 0xe8, 0x97, 0x7f, 0x64, 0xbd, 0xce, 0xe6, 0x75, 0x00, 0x72, 0xfd, 0xe3, 0xef, 0x41, 0xad,
+	0xf0, 0xa7, 0xe2, 0xc6, 0x72, 0x97, 0x24, 0xff, 0x2a, 0x04, 0xe1, 0x04, 0xab, 0xfd, 0x4e, 0x82,
+	0xe6, 0x62, 0xf8, 0xca, 0xa2, 0xfc, 0xe7, 0x1f, 0xd0, 0x9d, 0x82, 0x28, 0xc4, 0x3b, 0xe0, 0x9d,
+	0xab, 0xea, 0xc8, 0x3f, 0x5e, 0x2e, 0xe8, 0xe2, 0xce, 0x5f, 0x25, 0x80, 0xfc, 0xf7, 0x00, 0x5e,
+	0x87, 0x56, 0x32, 0xd9, 0xd9, 0x5d, 0x7d, 0x6c, 0xb2, 0x86, 0xdc, 0x80, 0x1b, 0xc4, 0xd8, 0xdf,
+	0xeb, 0x77, 0x75, 0xd3, 0xee, 0xf5, 0x7b, 0x36, 0xeb, 0x9b, 0x81, 0x6e, 0x75, 0x77, 0x90, 0x84,
+	0xff, 0x0f, 0xd6, 0xad, 0xd1, 0xc8, 0x1e, 0xe8, 0xc3, 0x47, 0x76, 0x77, 0x6f, 0x6c, 0x5a, 0x06,
+	0x31, 0x51, 0xb9, 0xd0, 0x99, 0x15, 0x96, 0xa0, 0x3f, 0x7c, 0x68, 0x98, 0xac, 0x6d, 0x6d, 0xa2,
+	0x5b, 0x86, 0xbd, 0xd7, 0x1f, 0xf4, 0x2d, 0xa3, 0x87, 0x64, 0xac, 0xc2, 0x75, 0x62, 0x7c, 0x36,
+	0x36, 0x4c, 0xab, 0x18, 0xa9, 0xb2, 0x0e, 0xed, 0x0f, 0x4d, 0x8b, 0x75, 0xbf, 0xf0, 0xa2, 0x1a,
+	0x7e, 0x0b, 0xae, 0x99, 0x06, 0x39, 0xe8, 0x77, 0x0d, 0x7b, 0xb1, 0xbb, 0xeb, 0xf8, 0x3a, 0x20,
+	0xcb, 0xec, 0x75, 0x0a, 0xde, 0x46, 0xe7, 0x07, 0xcf, 0x5e, 0xb4, 0x4b, 0x5f, 0xbd, 0x68, 0x97,
+	0x5e, 0xbd, 0x68, 0x4b, 0x3f, 0x3b, 0x6f, 0x4b, 0x7f, 0x3c, 0x6f, 0x4b, 0x4f, 0xcf, 0xdb, 0xd2,
+	0xb3, 0xf3, 0xb6, 0xf4, 0xf7, 0xf3, 0xb6, 0xf4, 0xf5, 0x79, 0xbb, 0xf4, 0xea, 0xbc, 0x2d, 0xfd,
+	0xfa, 0x65, 0xbb, 0xf4, 0xec, 0x65, 0xbb, 0xf4, 0xd5, 0xcb, 0x76, 0xe9, 0xc7, 0x75, 0xfe, 0xaf,
+	0x6b, 0x76, 0x78, 0x58, 0xe3, 0x7f, 0xad, 0x3e, 0xf8, 0xe7, 0x00, 0x2a, 0x66, 0x1b, 0x73, 0xfd,
+	0x12, 0x00, 0x00,
 }
 
 func (x ErrorCause) String() string {
@@ -2187,6 +2212,9 @@ func (this *TimeSeries) Equal(that interface{}) bool {
 			return false
 		}
 	}
+	if this.CreatedTimestamp != that1.CreatedTimestamp {
+		return false
+	}
 	return true
 }
 func (this *LabelPair) Equal(that interface{}) bool {
@@ -2440,6 +2468,14 @@ func (this *Histogram) Equal(that interface{}) bool {
 	if this.Timestamp != that1.Timestamp {
 		return false
 	}
+	if len(this.CustomValues) != len(that1.CustomValues) {
+		return false
+	}
+	for i := range this.CustomValues {
+		if this.CustomValues[i] != that1.CustomValues[i] {
+			return false
+		}
+	}
 	return true
 }
 func (this *Histogram_CountInt) Equal(that interface{}) bool {
@@ -3174,7 +3210,7 @@ func (this *TimeSeries) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 8)
+	s := make([]string, 0, 9)
 	s = append(s, "&mimirpb.TimeSeries{")
 	s = append(s, "Labels: "+fmt.Sprintf("%#v", this.Labels)+",\n")
 	if this.Samples != nil {
@@ -3198,6 +3234,7 @@ func (this *TimeSeries) GoString() string {
 		}
 		s = append(s, "Histograms: "+fmt.Sprintf("%#v", vs)+",\n")
 	}
+	s = append(s, "CreatedTimestamp: "+fmt.Sprintf("%#v", this.CreatedTimestamp)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -3262,7 +3299,7 @@ func (this *Histogram) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 19)
+	s := make([]string, 0, 20)
 	s = append(s, "&mimirpb.Histogram{")
 	if this.Count != nil {
 		s = append(s, "Count: "+fmt.Sprintf("%#v", this.Count)+",\n")
@@ -3293,6 +3330,7 @@ func (this *Histogram) GoString() string {
 	s = append(s, "PositiveCounts: "+fmt.Sprintf("%#v", this.PositiveCounts)+",\n")
 	s = append(s, "ResetHint: "+fmt.Sprintf("%#v", this.ResetHint)+",\n")
 	s = append(s, "Timestamp: "+fmt.Sprintf("%#v", this.Timestamp)+",\n")
+	s = append(s, "CustomValues: "+fmt.Sprintf("%#v", this.CustomValues)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -3727,6 +3765,11 @@ func (m *TimeSeries) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.CreatedTimestamp != 0 {
+		i = encodeVarintMimir(dAtA, i, uint64(m.CreatedTimestamp))
+		i--
+		dAtA[i] = 0x30
+	}
 	if len(m.Histograms) > 0 {
 		for iNdEx := len(m.Histograms) - 1; iNdEx >= 0; iNdEx-- {
 			{
@@ -4011,6 +4054,18 @@ func (m *Histogram) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.CustomValues) > 0 {
+		for iNdEx := len(m.CustomValues) - 1; iNdEx >= 0; iNdEx-- {
+			f1 := math.Float64bits(float64(m.CustomValues[iNdEx]))
+			i -= 8
+			encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(f1))
+		}
+		i = encodeVarintMimir(dAtA, i, uint64(len(m.CustomValues)*8))
+		i--
+		dAtA[i] = 0x1
+		i--
+		dAtA[i] = 0x82
+	}
 	if m.Timestamp != 0 {
 		i = encodeVarintMimir(dAtA, i, uint64(m.Timestamp))
 		i--
@@ -4023,30 +4078,30 @@ func (m *Histogram) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	}
 	if len(m.PositiveCounts) > 0 {
 		for iNdEx := len(m.PositiveCounts) - 1; iNdEx >= 0; iNdEx-- {
-			f1 := math.Float64bits(float64(m.PositiveCounts[iNdEx]))
+			f2 := math.Float64bits(float64(m.PositiveCounts[iNdEx]))
 			i -= 8
-			encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(f1))
+			encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(f2))
 		}
 		i = encodeVarintMimir(dAtA, i, uint64(len(m.PositiveCounts)*8))
 		i--
 		dAtA[i] = 0x6a
 	}
 	if len(m.PositiveDeltas) > 0 {
-		var j2 int
-		dAtA4 := make([]byte, len(m.PositiveDeltas)*10)
+		var j3 int
+		dAtA5 := make([]byte, len(m.PositiveDeltas)*10)
 		for _, num := range m.PositiveDeltas {
-			x3 := (uint64(num) << 1) ^ uint64((num >> 63))
-			for x3 >= 1<<7 {
-				dAtA4[j2] = uint8(uint64(x3)&0x7f | 0x80)
-				j2++
-				x3 >>= 7
-			}
-			dAtA4[j2] = uint8(x3)
-			j2++
-		}
-		i -= j2
-		copy(dAtA[i:], dAtA4[:j2])
-		i = encodeVarintMimir(dAtA, i, uint64(j2))
+			x4 := (uint64(num) << 1) ^ uint64((num >> 63))
+			for x4 >= 1<<7 {
+				dAtA5[j3] = uint8(uint64(x4)&0x7f | 0x80)
+				j3++
+				x4 >>= 7
+			}
+			dAtA5[j3] = uint8(x4)
+			j3++
+		}
+		i -= j3
+		copy(dAtA[i:], dAtA5[:j3])
+		i = encodeVarintMimir(dAtA, i, uint64(j3))
 		i--
 		dAtA[i] = 0x62
 	}
@@ -4066,30 +4121,30 @@ func (m *Histogram) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	}
 	if len(m.NegativeCounts) > 0 {
 		for iNdEx := len(m.NegativeCounts) - 1; iNdEx >= 0; iNdEx-- {
-			f5 := math.Float64bits(float64(m.NegativeCounts[iNdEx]))
+			f6 := math.Float64bits(float64(m.NegativeCounts[iNdEx]))
 			i -= 8
-			encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(f5))
+			encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(f6))
 		}
 		i = encodeVarintMimir(dAtA, i, uint64(len(m.NegativeCounts)*8))
 		i--
 		dAtA[i] = 0x52
 	}
 	if len(m.NegativeDeltas) > 0 {
-		var j6 int
-		dAtA8 := make([]byte, len(m.NegativeDeltas)*10)
+		var j7 int
+		dAtA9 := make([]byte, len(m.NegativeDeltas)*10)
 		for _, num := range m.NegativeDeltas {
-			x7 := (uint64(num) << 1) ^ uint64((num >> 63))
-			for x7 >= 1<<7 {
-				dAtA8[j6] = uint8(uint64(x7)&0x7f | 0x80)
-				j6++
-				x7 >>= 7
-			}
-			dAtA8[j6] = uint8(x7)
-			j6++
-		}
-		i -= j6
-		copy(dAtA[i:], dAtA8[:j6])
-		i = encodeVarintMimir(dAtA, i, uint64(j6))
+			x8 := (uint64(num) << 1) ^ uint64((num >> 63))
+			for x8 >= 1<<7 {
+				dAtA9[j7] = uint8(uint64(x8)&0x7f | 0x80)
+				j7++
+				x8 >>= 7
+			}
+			dAtA9[j7] = uint8(x8)
+			j7++
+		}
+		i -= j7
+		copy(dAtA[i:], dAtA9[:j7])
+		i = encodeVarintMimir(dAtA, i, uint64(j7))
 		i--
 		dAtA[i] = 0x4a
 	}
@@ -4218,9 +4273,9 @@ func (m *FloatHistogram) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	}
 	if len(m.PositiveBuckets) > 0 {
 		for iNdEx := len(m.PositiveBuckets) - 1; iNdEx >= 0; iNdEx-- {
-			f9 := math.Float64bits(float64(m.PositiveBuckets[iNdEx]))
+			f10 := math.Float64bits(float64(m.PositiveBuckets[iNdEx]))
 			i -= 8
-			encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(f9))
+			encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(f10))
 		}
 		i = encodeVarintMimir(dAtA, i, uint64(len(m.PositiveBuckets)*8))
 		i--
@@ -4242,9 +4297,9 @@ func (m *FloatHistogram) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	}
 	if len(m.NegativeBuckets) > 0 {
 		for iNdEx := len(m.NegativeBuckets) - 1; iNdEx >= 0; iNdEx-- {
-			f10 := math.Float64bits(float64(m.NegativeBuckets[iNdEx]))
+			f11 := math.Float64bits(float64(m.NegativeBuckets[iNdEx]))
 			i -= 8
-			encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(f10))
+			encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(f11))
 		}
 		i = encodeVarintMimir(dAtA, i, uint64(len(m.NegativeBuckets)*8))
 		i--
@@ -5038,6 +5093,9 @@ func (m *TimeSeries) Size() (n int) {
 			n += 1 + l + sovMimir(uint64(l))
 		}
 	}
+	if m.CreatedTimestamp != 0 {
+		n += 1 + sovMimir(uint64(m.CreatedTimestamp))
+	}
 	return n
 }
 
@@ -5192,6 +5250,9 @@ func (m *Histogram) Size() (n int) {
 	if m.Timestamp != 0 {
 		n += 1 + sovMimir(uint64(m.Timestamp))
 	}
+	if len(m.CustomValues) > 0 {
+		n += 2 + sovMimir(uint64(len(m.CustomValues)*8)) + len(m.CustomValues)*8
+	}
 	return n
 }
 
@@ -5643,6 +5704,7 @@ func (this *TimeSeries) String() string {
 		`Samples:` + repeatedStringForSamples + `,`,
 		`Exemplars:` + repeatedStringForExemplars + `,`,
 		`Histograms:` + repeatedStringForHistograms + `,`,
+		`CreatedTimestamp:` + fmt.Sprintf("%v", this.CreatedTimestamp) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -5732,6 +5794,7 @@ func (this *Histogram) String() string {
 		`PositiveCounts:` + fmt.Sprintf("%v", this.PositiveCounts) + `,`,
 		`ResetHint:` + fmt.Sprintf("%v", this.ResetHint) + `,`,
 		`Timestamp:` + fmt.Sprintf("%v", this.Timestamp) + `,`,
+		`CustomValues:` + fmt.Sprintf("%v", this.CustomValues) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -6484,6 +6547,25 @@ func (m *TimeSeries) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CreatedTimestamp", wireType)
+			}
+			m.CreatedTimestamp = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMimir
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.CreatedTimestamp |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMimir(dAtA[iNdEx:])
@@ -7588,6 +7670,60 @@ func (m *Histogram) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 16:
+			if wireType == 1 {
+				var v uint64
+				if (iNdEx + 8) > l {
+					return io.ErrUnexpectedEOF
+				}
+				v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+				iNdEx += 8
+				v2 := float64(math.Float64frombits(v))
+				m.CustomValues = append(m.CustomValues, v2)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowMimir
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= int(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthMimir
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex < 0 {
+					return ErrInvalidLengthMimir
+				}
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				var elementCount int
+				elementCount = packedLen / 8
+				if elementCount != 0 && len(m.CustomValues) == 0 {
+					m.CustomValues = make([]float64, 0, elementCount)
+				}
+				for iNdEx < postIndex {
+					var v uint64
+					if (iNdEx + 8) > l {
+						return io.ErrUnexpectedEOF
+					}
+					v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+					iNdEx += 8
+					v2 := float64(math.Float64frombits(v))
+					m.CustomValues = append(m.CustomValues, v2)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field CustomValues", wireType)
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMimir(dAtA[iNdEx:])
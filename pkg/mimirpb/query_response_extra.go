@@ -13,6 +13,13 @@ const QueryResponseMimeType = QueryResponseMimeTypeType + "/" + QueryResponseMim
 const QueryResponseMimeTypeType = "application"
 const QueryResponseMimeTypeSubType = "vnd.mimir.queryresponse+protobuf"
 
+// QueryResponseStreamMimeType is the MIME type of a query response encoded as a sequence of length-delimited
+// QueryResponse messages, each of them holding a batch of the series of a matrix. The first message carries
+// the response warnings. Queriers use it to stream large responses back to the query-frontend without
+// having to decode and re-encode them.
+const QueryResponseStreamMimeType = QueryResponseMimeTypeType + "/" + QueryResponseStreamMimeTypeSubType
+const QueryResponseStreamMimeTypeSubType = "vnd.mimir.queryresponse-stream+protobuf"
+
 func (s QueryResponse_Status) ToPrometheusString() (string, error) {
 	switch s {
 	case QueryResponse_SUCCESS:
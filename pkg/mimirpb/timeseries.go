@@ -440,6 +440,7 @@ func ReuseTimeseries(ts *TimeSeries) {
 	ts.Labels = ts.Labels[:0]
 	ts.Samples = ts.Samples[:0]
 	ts.Histograms = ts.Histograms[:0]
+	ts.CreatedTimestamp = 0
 
 	ClearExemplars(ts)
 	timeSeriesPool.Put(ts)
@@ -531,6 +532,8 @@ func DeepCopyTimeseries(dst, src PreallocTimeseries, keepExemplars bool) Preallo
 	// do not keep histograms
 	dstTs.Histograms = nil
 
+	dstTs.CreatedTimestamp = srcTs.CreatedTimestamp
+
 	return dst
 }
 
@@ -18,6 +18,7 @@ import (
 	"github.com/thanos-io/objstore"
 
 	"github.com/grafana/mimir/pkg/storage/bucket"
+	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
 	"github.com/grafana/mimir/pkg/storage/tsdb/bucketindex"
 	"github.com/grafana/mimir/pkg/util/globalerror"
 )
@@ -114,6 +115,30 @@ func (f *BucketIndexBlocksFinder) GetBlocks(ctx context.Context, userID string,
 	return blocks, matchingDeletionMarks, nil
 }
 
+// GetSeriesDeletionRequests implements SeriesDeletionRequestsProvider.
+func (f *BucketIndexBlocksFinder) GetSeriesDeletionRequests(ctx context.Context, userID string, minT, maxT int64) ([]*mimir_tsdb.SeriesDeletionRequest, error) {
+	if f.State() != services.Running {
+		return nil, errBucketIndexBlocksFinderNotRunning
+	}
+
+	idx, err := f.loader.GetIndex(ctx, userID)
+	if errors.Is(err, bucketindex.ErrIndexNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var requests []*mimir_tsdb.SeriesDeletionRequest
+	for _, req := range idx.SeriesDeletionRequests {
+		if req.Overlaps(minT, maxT) {
+			requests = append(requests, req)
+		}
+	}
+
+	return requests, nil
+}
+
 func newBucketIndexTooOldError(updatedAt time.Time, maxStalePeriod time.Duration) error {
 	return errors.New(globalerror.BucketIndexTooOld.Message(fmt.Sprintf("the bucket index is too old. It was last updated at %s, which exceeds the maximum allowed staleness period of %v", updatedAt.UTC().Format(time.RFC3339Nano), maxStalePeriod)))
 }
@@ -19,6 +19,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/thanos-io/objstore"
 
+	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
 	"github.com/grafana/mimir/pkg/storage/tsdb/bucketindex"
 	mimir_testutil "github.com/grafana/mimir/pkg/storage/tsdb/testutil"
 )
@@ -204,6 +205,36 @@ func TestBucketIndexBlocksFinder_GetBlocks_BucketIndexIsTooOld(t *testing.T) {
 	require.EqualError(t, err, newBucketIndexTooOldError(idx.GetUpdatedAt(), finder.cfg.MaxStalePeriod).Error())
 }
 
+func TestBucketIndexBlocksFinder_GetSeriesDeletionRequests(t *testing.T) {
+	const userID = "user-1"
+
+	ctx := context.Background()
+	bkt, _ := mimir_testutil.PrepareFilesystemBucket(t)
+
+	req1 := &mimir_tsdb.SeriesDeletionRequest{RequestID: ulid.MustNew(1, nil).String(), Selectors: []string{"foo"}, StartTime: 10, EndTime: 20}
+	req2 := &mimir_tsdb.SeriesDeletionRequest{RequestID: ulid.MustNew(2, nil).String(), Selectors: []string{"bar"}, StartTime: 30, EndTime: 40}
+
+	require.NoError(t, bucketindex.WriteIndex(ctx, bkt, userID, nil, &bucketindex.Index{
+		Version:                bucketindex.IndexVersion3,
+		SeriesDeletionRequests: []*mimir_tsdb.SeriesDeletionRequest{req1, req2},
+		UpdatedAt:              time.Now().Unix(),
+	}))
+
+	finder := prepareBucketIndexBlocksFinder(t, bkt)
+
+	requests, err := finder.GetSeriesDeletionRequests(ctx, userID, 0, 100)
+	require.NoError(t, err)
+	assert.Equal(t, []*mimir_tsdb.SeriesDeletionRequest{req1, req2}, requests)
+
+	requests, err = finder.GetSeriesDeletionRequests(ctx, userID, 25, 35)
+	require.NoError(t, err)
+	assert.Equal(t, []*mimir_tsdb.SeriesDeletionRequest{req2}, requests)
+
+	requests, err = finder.GetSeriesDeletionRequests(ctx, "user-2", 0, 100)
+	require.NoError(t, err)
+	assert.Empty(t, requests)
+}
+
 func prepareBucketIndexBlocksFinder(t testing.TB, bkt objstore.Bucket) *BucketIndexBlocksFinder {
 	ctx := context.Background()
 	cfg := BucketIndexBlocksFinderConfig{
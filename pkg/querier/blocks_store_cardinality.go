@@ -0,0 +1,270 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package querier
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/services"
+	"github.com/grafana/dskit/tenant"
+	"github.com/oklog/ulid"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/labels"
+	"golang.org/x/sync/errgroup"
+	grpc_metadata "google.golang.org/grpc/metadata"
+
+	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
+	"github.com/grafana/mimir/pkg/storegateway"
+	"github.com/grafana/mimir/pkg/storegateway/storegatewaypb"
+	"github.com/grafana/mimir/pkg/storegateway/storepb"
+	"github.com/grafana/mimir/pkg/util"
+	"github.com/grafana/mimir/pkg/util/spanlogger"
+)
+
+// BlocksCardinalityQueryable analyses the cardinality of the series stored in the blocks.
+type BlocksCardinalityQueryable interface {
+	// BlocksCardinality returns the number of series of the tenant's blocks matching the matchers in the time range,
+	// and the limit metric names, label names and label name-value pairs with the most series.
+	BlocksCardinality(ctx context.Context, minT, maxT int64, matchers []*labels.Matcher, limit int) (*BlocksCardinalityResult, error)
+}
+
+// BlocksCardinalityResult is the cardinality of the series stored in the blocks.
+type BlocksCardinalityResult struct {
+	SeriesCount uint64
+	Metrics     []storegatewaypb.CardinalityItem
+	LabelNames  []storegatewaypb.CardinalityItem
+	LabelValues []storegatewaypb.CardinalityItem
+}
+
+// BlocksCardinality implements BlocksCardinalityQueryable.
+func (q *BlocksStoreQueryable) BlocksCardinality(ctx context.Context, minT, maxT int64, matchers []*labels.Matcher, limit int) (*BlocksCardinalityResult, error) {
+	if s := q.State(); s != services.Running {
+		return nil, errors.Errorf("BlocksStoreQueryable is not running: %v", s)
+	}
+
+	querier := &blocksStoreQuerier{
+		minT:            minT,
+		maxT:            maxT,
+		finder:          q.finder,
+		stores:          q.stores,
+		metrics:         q.metrics,
+		limits:          q.limits,
+		consistency:     q.consistency,
+		logger:          q.logger,
+		queryStoreAfter: q.queryStoreAfter,
+	}
+	return querier.cardinality(ctx, matchers, limit)
+}
+
+func (q *blocksStoreQuerier) cardinality(ctx context.Context, matchers []*labels.Matcher, limit int) (*BlocksCardinalityResult, error) {
+	spanLog, ctx := spanlogger.NewWithLogger(ctx, q.logger, "blocksStoreQuerier.cardinality")
+	defer spanLog.Span.Finish()
+
+	tenantID, err := tenant.TenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	minT, maxT := q.minT, q.maxT
+
+	spanLog.DebugLog("start", util.TimeFromMillis(minT).UTC().String(), "end",
+		util.TimeFromMillis(maxT).UTC().String(), "matchers", util.MatchersStringer(matchers))
+
+	// The cardinality is computed from the label values postings, like the label values are, so it's subject to
+	// the same limit.
+	maxQueryLength := q.limits.MaxLabelsQueryLength(tenantID)
+	if maxQueryLength != 0 {
+		minT = clampMinTime(spanLog, minT, maxT, -maxQueryLength, "max label query length")
+	}
+
+	var (
+		resBlocks         []storegatewaypb.BlocksCardinality
+		convertedMatchers = convertMatchersToLabelMatcher(matchers)
+	)
+
+	queryF := func(clients map[BlocksStoreClient][]ulid.ULID, minT, maxT, resolution int64) ([]ulid.ULID, error) {
+		// The cardinality of the downsampled blocks is the one of the series holding the average.
+		blocks, queriedBlocks, err := q.fetchCardinalityFromStores(ctx, clients, minT, maxT, tenantID, withDownsampleAggregateMatcher(convertedMatchers, resolution, ""), limit)
+		if err != nil {
+			return nil, err
+		}
+
+		resBlocks = append(resBlocks, blocks...)
+		return queriedBlocks, nil
+	}
+
+	if err := q.queryWithConsistencyCheck(ctx, spanLog, minT, maxT, mimir_tsdb.ResolutionRaw, tenantID, nil, nil, queryF); err != nil {
+		return nil, err
+	}
+
+	return mergeBlocksCardinality(resBlocks, limit), nil
+}
+
+func (q *blocksStoreQuerier) fetchCardinalityFromStores(
+	ctx context.Context,
+	clients map[BlocksStoreClient][]ulid.ULID,
+	minT int64,
+	maxT int64,
+	tenantID string,
+	matchers []storepb.LabelMatcher,
+	limit int,
+) ([]storegatewaypb.BlocksCardinality, []ulid.ULID, error) {
+	var (
+		reqCtx        = grpc_metadata.AppendToOutgoingContext(ctx, storegateway.GrpcContextMetadataTenantID, tenantID)
+		g, gCtx       = errgroup.WithContext(reqCtx)
+		mtx           = sync.Mutex{}
+		blocks        []storegatewaypb.BlocksCardinality
+		queriedBlocks = []ulid.ULID(nil)
+		spanLog       = spanlogger.FromContext(ctx, q.logger)
+	)
+
+	// Concurrently fetch the cardinality from all clients.
+	for c, blockIDs := range clients {
+		// Change variables scope since it will be used in a goroutine.
+		c := c
+		blockIDs := blockIDs
+
+		g.Go(func() error {
+			req := &storegatewaypb.CardinalityRequest{
+				MinTime:  minT,
+				MaxTime:  maxT,
+				Matchers: matchers,
+				Limit:    int32(limit),
+				BlockIds: convertULIDsToString(blockIDs),
+			}
+
+			resp, err := c.Cardinality(gCtx, req)
+			if err != nil {
+				if shouldStopQueryFunc(err) {
+					return err
+				}
+
+				level.Warn(spanLog).Log("msg", "failed to fetch cardinality", "remote", c.RemoteAddress(), "err", err)
+				return nil
+			}
+
+			myQueriedBlocks := make([]ulid.ULID, 0, len(resp.QueriedBlocks))
+			for _, id := range resp.QueriedBlocks {
+				blockID, err := ulid.Parse(id)
+				if err != nil {
+					return errors.Wrapf(err, "failed to parse queried block IDs from %s", c.RemoteAddress())
+				}
+				myQueriedBlocks = append(myQueriedBlocks, blockID)
+			}
+
+			spanLog.DebugLog("msg", "received cardinality from store-gateway",
+				"instance", c,
+				"requested blocks", strings.Join(convertULIDsToString(blockIDs), " "),
+				"queried blocks", strings.Join(convertULIDsToString(myQueriedBlocks), " "))
+
+			// Store the result.
+			mtx.Lock()
+			blocks = append(blocks, resp.Blocks...)
+			queriedBlocks = append(queriedBlocks, myQueriedBlocks...)
+			mtx.Unlock()
+
+			return nil
+		})
+	}
+
+	// Wait until all client requests complete.
+	if err := g.Wait(); err != nil {
+		return nil, nil, err
+	}
+
+	return blocks, queriedBlocks, nil
+}
+
+// mergeBlocksCardinality merges the cardinality of the blocks received from the store-gateways. The series counts of
+// the blocks with the same time range and compactor shard are merged taking the highest ones, because they're the
+// replicas of the same series, like the blocks of different ingesters not compacted yet, or the same blocks queried
+// from different store-gateways. The series counts of the compactor shards of the same time range are then summed,
+// because they hold different series. Blocks with different time ranges mostly hold the same series, so the highest
+// series count across time ranges is taken.
+// The result is approximate: each store-gateway only returns its top items, and the series of the blocks with
+// different time ranges overlap only partially.
+func mergeBlocksCardinality(blocks []storegatewaypb.BlocksCardinality, limit int) *BlocksCardinalityResult {
+	type timeRange struct{ minTime, maxTime int64 }
+	type shardKey struct {
+		timeRange
+		shardID string
+	}
+	type itemKey struct{ labelName, labelValue string }
+
+	var (
+		shardSeriesCount = map[shardKey]uint64{}
+		shardMetrics     = map[shardKey]map[itemKey]uint64{}
+		shardLabelNames  = map[shardKey]map[itemKey]uint64{}
+		shardLabelValues = map[shardKey]map[itemKey]uint64{}
+	)
+
+	maxShardItems := func(dst map[shardKey]map[itemKey]uint64, k shardKey, items []storegatewaypb.CardinalityItem) {
+		if dst[k] == nil {
+			dst[k] = map[itemKey]uint64{}
+		}
+		for _, item := range items {
+			ik := itemKey{item.LabelName, item.LabelValue}
+			dst[k][ik] = max(dst[k][ik], item.SeriesCount)
+		}
+	}
+
+	for _, b := range blocks {
+		k := shardKey{timeRange: timeRange{minTime: b.MinTime, maxTime: b.MaxTime}, shardID: b.ShardId}
+		shardSeriesCount[k] = max(shardSeriesCount[k], b.SeriesCount)
+		maxShardItems(shardMetrics, k, b.Metrics)
+		maxShardItems(shardLabelNames, k, b.LabelNames)
+		maxShardItems(shardLabelValues, k, b.LabelValues)
+	}
+
+	// Sum the compactor shards of each time range.
+	seriesCount := map[timeRange]uint64{}
+	for k, count := range shardSeriesCount {
+		seriesCount[k.timeRange] += count
+	}
+
+	sumShardItems := func(src map[shardKey]map[itemKey]uint64) map[timeRange]map[itemKey]uint64 {
+		dst := map[timeRange]map[itemKey]uint64{}
+		for k, items := range src {
+			if dst[k.timeRange] == nil {
+				dst[k.timeRange] = map[itemKey]uint64{}
+			}
+			for ik, count := range items {
+				dst[k.timeRange][ik] += count
+			}
+		}
+		return dst
+	}
+
+	maxItems := func(src map[timeRange]map[itemKey]uint64) []storegatewaypb.CardinalityItem {
+		counts := map[itemKey]uint64{}
+		for _, items := range src {
+			for k, count := range items {
+				counts[k] = max(counts[k], count)
+			}
+		}
+
+		result := make([]storegatewaypb.CardinalityItem, 0, len(counts))
+		for k, count := range counts {
+			result = append(result, storegatewaypb.CardinalityItem{LabelName: k.labelName, LabelValue: k.labelValue, SeriesCount: count})
+		}
+		storegatewaypb.SortCardinalityItems(result)
+
+		if limit > 0 && len(result) > limit {
+			result = result[:limit]
+		}
+		return result
+	}
+
+	result := &BlocksCardinalityResult{
+		Metrics:     maxItems(sumShardItems(shardMetrics)),
+		LabelNames:  maxItems(sumShardItems(shardLabelNames)),
+		LabelValues: maxItems(sumShardItems(shardLabelValues)),
+	}
+	for _, count := range seriesCount {
+		result.SeriesCount = max(result.SeriesCount, count)
+	}
+	return result
+}
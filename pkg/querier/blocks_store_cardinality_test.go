@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package querier
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/grafana/mimir/pkg/storegateway/storegatewaypb"
+)
+
+func TestMergeBlocksCardinality(t *testing.T) {
+	metric := func(name string, count uint64) storegatewaypb.CardinalityItem {
+		return storegatewaypb.CardinalityItem{LabelName: labels.MetricName, LabelValue: name, SeriesCount: count}
+	}
+	labelName := func(name string, count uint64) storegatewaypb.CardinalityItem {
+		return storegatewaypb.CardinalityItem{LabelName: name, SeriesCount: count}
+	}
+	labelValue := func(name, value string, count uint64) storegatewaypb.CardinalityItem {
+		return storegatewaypb.CardinalityItem{LabelName: name, LabelValue: value, SeriesCount: count}
+	}
+
+	blocks := []storegatewaypb.BlocksCardinality{
+		// Two compactor shards of the same time range, received from different store-gateways.
+		{
+			MinTime:     0,
+			MaxTime:     10,
+			ShardId:     "1_of_2",
+			SeriesCount: 3,
+			Metrics:     []storegatewaypb.CardinalityItem{metric("up", 2), metric("http_requests_total", 1)},
+			LabelNames:  []storegatewaypb.CardinalityItem{labelName("job", 3)},
+			LabelValues: []storegatewaypb.CardinalityItem{labelValue("job", "a", 2), labelValue("job", "b", 1)},
+		},
+		{
+			MinTime:     0,
+			MaxTime:     10,
+			ShardId:     "2_of_2",
+			SeriesCount: 4,
+			Metrics:     []storegatewaypb.CardinalityItem{metric("http_requests_total", 3), metric("up", 1)},
+			LabelNames:  []storegatewaypb.CardinalityItem{labelName("job", 4), labelName("pod", 3)},
+			LabelValues: []storegatewaypb.CardinalityItem{labelValue("job", "a", 4)},
+		},
+		// The same compactor shard, received from another store-gateway.
+		{
+			MinTime:     0,
+			MaxTime:     10,
+			ShardId:     "1_of_2",
+			SeriesCount: 3,
+			Metrics:     []storegatewaypb.CardinalityItem{metric("up", 2), metric("http_requests_total", 1)},
+			LabelNames:  []storegatewaypb.CardinalityItem{labelName("job", 3)},
+			LabelValues: []storegatewaypb.CardinalityItem{labelValue("job", "a", 2), labelValue("job", "b", 1)},
+		},
+		// Another time range, mostly holding the same series.
+		{
+			MinTime:     10,
+			MaxTime:     20,
+			SeriesCount: 5,
+			Metrics:     []storegatewaypb.CardinalityItem{metric("up", 5)},
+			LabelNames:  []storegatewaypb.CardinalityItem{labelName("job", 5)},
+			LabelValues: []storegatewaypb.CardinalityItem{labelValue("job", "b", 5)},
+		},
+		// Not split blocks of the same time range, like the blocks of different ingesters replicas.
+		{
+			MinTime:     10,
+			MaxTime:     20,
+			SeriesCount: 4,
+			Metrics:     []storegatewaypb.CardinalityItem{metric("up", 4)},
+			LabelNames:  []storegatewaypb.CardinalityItem{labelName("job", 4)},
+			LabelValues: []storegatewaypb.CardinalityItem{labelValue("job", "b", 4)},
+		},
+	}
+
+	t.Run("no limit", func(t *testing.T) {
+		assert.Equal(t, &BlocksCardinalityResult{
+			SeriesCount: 7,
+			Metrics:     []storegatewaypb.CardinalityItem{metric("up", 5), metric("http_requests_total", 4)},
+			LabelNames:  []storegatewaypb.CardinalityItem{labelName("job", 7), labelName("pod", 3)},
+			LabelValues: []storegatewaypb.CardinalityItem{labelValue("job", "a", 6), labelValue("job", "b", 5)},
+		}, mergeBlocksCardinality(blocks, 0))
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		assert.Equal(t, &BlocksCardinalityResult{
+			SeriesCount: 7,
+			Metrics:     []storegatewaypb.CardinalityItem{metric("up", 5)},
+			LabelNames:  []storegatewaypb.CardinalityItem{labelName("job", 7)},
+			LabelValues: []storegatewaypb.CardinalityItem{labelValue("job", "a", 6)},
+		}, mergeBlocksCardinality(blocks, 1))
+	})
+
+	t.Run("no blocks", func(t *testing.T) {
+		assert.Equal(t, &BlocksCardinalityResult{
+			Metrics:     []storegatewaypb.CardinalityItem{},
+			LabelNames:  []storegatewaypb.CardinalityItem{},
+			LabelValues: []storegatewaypb.CardinalityItem{},
+		}, mergeBlocksCardinality(nil, 10))
+	})
+}
@@ -0,0 +1,204 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package querier
+
+import (
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"golang.org/x/exp/slices"
+
+	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
+	"github.com/grafana/mimir/pkg/storage/tsdb/bucketindex"
+	"github.com/grafana/mimir/pkg/storegateway/storepb"
+)
+
+// rawResolutionFuncs are the PromQL functions which can't be computed from the series of the downsampled blocks:
+// the count and the changes of the samples are lost, and averaging the samples of a counter hides its resets.
+var rawResolutionFuncs = map[string]bool{
+	"count_over_time": true,
+	"changes":         true,
+	"resets":          true,
+	"rate":            true,
+	"irate":           true,
+	"increase":        true,
+}
+
+// maxResolutionForHints returns the coarsest resolution of the blocks which can be queried for the select hints.
+// The resolution must be no coarser than the query step, to get a sample per step, and than half the range of
+// the range selectors, or of the lookback delta for the instant selectors, to get at least two samples in it.
+func maxResolutionForHints(sp *storage.SelectHints, lookbackDelta time.Duration) int64 {
+	if sp == nil || rawResolutionFuncs[sp.Func] {
+		return mimir_tsdb.ResolutionRaw
+	}
+
+	window := sp.Range
+	if window == 0 {
+		window = lookbackDelta.Milliseconds()
+	}
+
+	maxResolution := window / 2
+	if sp.Step > 0 && sp.Step < maxResolution {
+		maxResolution = sp.Step
+	}
+	return maxResolution
+}
+
+// downsampleAggregateForFunc returns the aggregate of the downsampled series to query for the PromQL function
+// of the select hints. The series holding the average are queried for the functions which can't be computed
+// from an aggregate.
+func downsampleAggregateForFunc(fn string) string {
+	switch fn {
+	case "min_over_time":
+		return mimir_tsdb.DownsampleAggregateMin
+	case "max_over_time":
+		return mimir_tsdb.DownsampleAggregateMax
+	case "sum_over_time":
+		return mimir_tsdb.DownsampleAggregateSum
+	default:
+		return ""
+	}
+}
+
+// selectBlocksByResolution returns the blocks to query in the time range, preferring the coarsest resolution
+// no coarser than maxResolution. The time ranges not covered by the blocks with the preferred resolution
+// are filled with the blocks with finer resolutions, and finally with coarser resolutions, like when the raw
+// blocks have already been deleted by the retention. The input order of the blocks is preserved.
+func selectBlocksByResolution(blocks bucketindex.Blocks, minT, maxT, maxResolution int64) bucketindex.Blocks {
+	byResolution := map[int64]bucketindex.Blocks{}
+	for _, b := range blocks {
+		byResolution[b.Resolution] = append(byResolution[b.Resolution], b)
+	}
+
+	if len(byResolution) <= 1 {
+		return blocks
+	}
+
+	var (
+		gaps     = []inclusiveTimeRange{{minT, maxT}}
+		selected = map[*bucketindex.Block]struct{}{}
+	)
+
+	for _, resolution := range resolutionsByPreference(maxResolution) {
+		if len(gaps) == 0 {
+			break
+		}
+
+		var covered []inclusiveTimeRange
+		for _, b := range byResolution[resolution] {
+			r := inclusiveTimeRange{b.MinTime, b.MaxTime - 1}
+			if !r.overlapsAny(gaps) {
+				continue
+			}
+
+			selected[b] = struct{}{}
+			covered = append(covered, r)
+		}
+
+		for _, r := range covered {
+			gaps = r.subtractFrom(gaps)
+		}
+	}
+
+	out := make(bucketindex.Blocks, 0, len(selected))
+	for _, b := range blocks {
+		if _, ok := selected[b]; ok {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// resolutionsByPreference returns the resolutions no coarser than maxResolution, from the coarsest, followed
+// by the coarser ones, from the finest.
+func resolutionsByPreference(maxResolution int64) []int64 {
+	var finer, coarser []int64
+	for _, resolution := range mimir_tsdb.DownsampleResolutions {
+		if resolution <= maxResolution {
+			finer = append([]int64{resolution}, finer...)
+		} else {
+			coarser = append(coarser, resolution)
+		}
+	}
+	return append(finer, coarser...)
+}
+
+// groupBlocksByResolution returns the blocks grouped by resolution, from the finest.
+func groupBlocksByResolution(blocks bucketindex.Blocks) ([]int64, map[int64]bucketindex.Blocks) {
+	groups := map[int64]bucketindex.Blocks{}
+	var resolutions []int64
+	for _, b := range blocks {
+		if _, ok := groups[b.Resolution]; !ok {
+			resolutions = append(resolutions, b.Resolution)
+		}
+		groups[b.Resolution] = append(groups[b.Resolution], b)
+	}
+	slices.Sort(resolutions)
+	return resolutions, groups
+}
+
+// inclusiveTimeRange is a time range whose both ends are inclusive.
+type inclusiveTimeRange struct {
+	minT, maxT int64
+}
+
+func (r inclusiveTimeRange) overlapsAny(others []inclusiveTimeRange) bool {
+	for _, o := range others {
+		if r.minT <= o.maxT && o.minT <= r.maxT {
+			return true
+		}
+	}
+	return false
+}
+
+// subtractFrom returns the input time ranges without their intersection with r.
+func (r inclusiveTimeRange) subtractFrom(ranges []inclusiveTimeRange) []inclusiveTimeRange {
+	var out []inclusiveTimeRange
+	for _, o := range ranges {
+		if r.maxT < o.minT || o.maxT < r.minT {
+			out = append(out, o)
+			continue
+		}
+		if o.minT < r.minT {
+			out = append(out, inclusiveTimeRange{o.minT, r.minT - 1})
+		}
+		if r.maxT < o.maxT {
+			out = append(out, inclusiveTimeRange{r.maxT + 1, o.maxT})
+		}
+	}
+	return out
+}
+
+// withDownsampleAggregateMatcher returns the input matchers with the one selecting the series holding the
+// aggregate of the blocks with the input resolution. The matchers are returned unchanged for the raw blocks.
+func withDownsampleAggregateMatcher(matchers []storepb.LabelMatcher, resolution int64, aggregate string) []storepb.LabelMatcher {
+	if resolution == mimir_tsdb.ResolutionRaw {
+		return matchers
+	}
+	return append(slices.Clone(matchers), storepb.LabelMatcher{Type: storepb.LabelMatcher_EQ, Name: mimir_tsdb.DownsampleAggregateLabel, Value: aggregate})
+}
+
+// dropDownsampleAggregateLabelSeriesSet is a storage.SeriesSet removing the downsampling aggregate label from
+// the series, so that the series holding an aggregate are merged with the raw series. Removing the same label
+// from all series doesn't change their order.
+type dropDownsampleAggregateLabelSeriesSet struct {
+	storage.SeriesSet
+}
+
+func (s dropDownsampleAggregateLabelSeriesSet) At() storage.Series {
+	series := s.SeriesSet.At()
+	return relabeledSeries{
+		Series: series,
+		lset:   labels.NewBuilder(series.Labels()).Del(mimir_tsdb.DownsampleAggregateLabel).Labels(),
+	}
+}
+
+type relabeledSeries struct {
+	storage.Series
+	lset labels.Labels
+}
+
+func (s relabeledSeries) Labels() labels.Labels {
+	return s.lset
+}
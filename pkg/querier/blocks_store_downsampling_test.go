@@ -0,0 +1,155 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package querier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/mimir/pkg/storage/series"
+	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
+	"github.com/grafana/mimir/pkg/storage/tsdb/bucketindex"
+)
+
+func TestMaxResolutionForHints(t *testing.T) {
+	const lookbackDelta = 5 * time.Minute
+
+	tests := map[string]struct {
+		hints    *storage.SelectHints
+		expected int64
+	}{
+		"no hints": {
+			hints:    nil,
+			expected: mimir_tsdb.ResolutionRaw,
+		},
+		"instant selector": {
+			hints:    &storage.SelectHints{Step: time.Hour.Milliseconds()},
+			expected: (lookbackDelta / 2).Milliseconds(),
+		},
+		"range selector with a range longer than twice the step": {
+			hints:    &storage.SelectHints{Step: time.Hour.Milliseconds(), Range: 24 * time.Hour.Milliseconds(), Func: "avg_over_time"},
+			expected: time.Hour.Milliseconds(),
+		},
+		"range selector with a range shorter than twice the step": {
+			hints:    &storage.SelectHints{Step: time.Hour.Milliseconds(), Range: time.Hour.Milliseconds(), Func: "avg_over_time"},
+			expected: (30 * time.Minute).Milliseconds(),
+		},
+		"range selector in an instant query": {
+			hints:    &storage.SelectHints{Range: 24 * time.Hour.Milliseconds(), Func: "max_over_time"},
+			expected: 12 * time.Hour.Milliseconds(),
+		},
+		"count_over_time": {
+			hints:    &storage.SelectHints{Step: time.Hour.Milliseconds(), Range: 24 * time.Hour.Milliseconds(), Func: "count_over_time"},
+			expected: mimir_tsdb.ResolutionRaw,
+		},
+		"rate": {
+			hints:    &storage.SelectHints{Step: time.Hour.Milliseconds(), Range: 24 * time.Hour.Milliseconds(), Func: "rate"},
+			expected: mimir_tsdb.ResolutionRaw,
+		},
+		"increase": {
+			hints:    &storage.SelectHints{Step: time.Hour.Milliseconds(), Range: 24 * time.Hour.Milliseconds(), Func: "increase"},
+			expected: mimir_tsdb.ResolutionRaw,
+		},
+		"resets": {
+			hints:    &storage.SelectHints{Step: time.Hour.Milliseconds(), Range: 24 * time.Hour.Milliseconds(), Func: "resets"},
+			expected: mimir_tsdb.ResolutionRaw,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, maxResolutionForHints(tc.hints, lookbackDelta))
+		})
+	}
+}
+
+func TestDownsampleAggregateForFunc(t *testing.T) {
+	assert.Equal(t, mimir_tsdb.DownsampleAggregateMin, downsampleAggregateForFunc("min_over_time"))
+	assert.Equal(t, mimir_tsdb.DownsampleAggregateMax, downsampleAggregateForFunc("max_over_time"))
+	assert.Equal(t, mimir_tsdb.DownsampleAggregateSum, downsampleAggregateForFunc("sum_over_time"))
+	assert.Equal(t, "", downsampleAggregateForFunc("avg_over_time"))
+	assert.Equal(t, "", downsampleAggregateForFunc(""))
+}
+
+func TestSelectBlocksByResolution(t *testing.T) {
+	const hour = int64(time.Hour / time.Millisecond)
+
+	var (
+		// Raw blocks of the last 2 days, the first of which has been downsampled too.
+		raw1 = &bucketindex.Block{ID: ulid.MustNew(1, nil), MinTime: 48 * hour, MaxTime: 72 * hour}
+		raw2 = &bucketindex.Block{ID: ulid.MustNew(2, nil), MinTime: 72 * hour, MaxTime: 96 * hour}
+		// Blocks downsampled to 5 minutes of the last 3 days, the first of which has been downsampled to 1 hour too.
+		res5m1 = &bucketindex.Block{ID: ulid.MustNew(3, nil), MinTime: 24 * hour, MaxTime: 48 * hour, Resolution: mimir_tsdb.Resolution5m}
+		res5m2 = &bucketindex.Block{ID: ulid.MustNew(4, nil), MinTime: 48 * hour, MaxTime: 72 * hour, Resolution: mimir_tsdb.Resolution5m}
+		// Blocks downsampled to 1 hour of all days.
+		res1h1 = &bucketindex.Block{ID: ulid.MustNew(5, nil), MinTime: 0, MaxTime: 24 * hour, Resolution: mimir_tsdb.Resolution1h}
+		res1h2 = &bucketindex.Block{ID: ulid.MustNew(6, nil), MinTime: 24 * hour, MaxTime: 48 * hour, Resolution: mimir_tsdb.Resolution1h}
+	)
+
+	blocks := bucketindex.Blocks{raw2, raw1, res5m2, res5m1, res1h2, res1h1}
+
+	tests := map[string]struct {
+		blocks        bucketindex.Blocks
+		minT, maxT    int64
+		maxResolution int64
+		expected      bucketindex.Blocks
+	}{
+		"only raw blocks": {
+			blocks:        bucketindex.Blocks{raw2, raw1},
+			minT:          0,
+			maxT:          96 * hour,
+			maxResolution: mimir_tsdb.Resolution1h,
+			expected:      bucketindex.Blocks{raw2, raw1},
+		},
+		"raw resolution falls back to coarser resolutions for the time range not covered by the raw blocks": {
+			blocks:        blocks,
+			minT:          0,
+			maxT:          96 * hour,
+			maxResolution: mimir_tsdb.ResolutionRaw,
+			expected:      bucketindex.Blocks{raw2, raw1, res5m1, res1h1},
+		},
+		"5m resolution falls back to raw resolution for the most recent blocks": {
+			blocks:        blocks,
+			minT:          24 * hour,
+			maxT:          96 * hour,
+			maxResolution: mimir_tsdb.Resolution5m,
+			expected:      bucketindex.Blocks{raw2, res5m2, res5m1},
+		},
+		"1h resolution": {
+			blocks:        blocks,
+			minT:          0,
+			maxT:          96 * hour,
+			maxResolution: 2 * mimir_tsdb.Resolution1h,
+			expected:      bucketindex.Blocks{raw2, res5m2, res1h2, res1h1},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, selectBlocksByResolution(tc.blocks, tc.minT, tc.maxT, tc.maxResolution))
+		})
+	}
+}
+
+func TestDropDownsampleAggregateLabelSeriesSet(t *testing.T) {
+	set := dropDownsampleAggregateLabelSeriesSet{SeriesSet: series.LabelsToSeriesSet([]labels.Labels{
+		labels.FromStrings(mimir_tsdb.DownsampleAggregateLabel, mimir_tsdb.DownsampleAggregateMax, labels.MetricName, "series_1"),
+		labels.FromStrings(mimir_tsdb.DownsampleAggregateLabel, mimir_tsdb.DownsampleAggregateMax, labels.MetricName, "series_2"),
+	})}
+
+	var actual []labels.Labels
+	for set.Next() {
+		actual = append(actual, set.At().Labels())
+	}
+	require.NoError(t, set.Err())
+	assert.Equal(t, []labels.Labels{
+		labels.FromStrings(labels.MetricName, "series_1"),
+		labels.FromStrings(labels.MetricName, "series_2"),
+	}, actual)
+}
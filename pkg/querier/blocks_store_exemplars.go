@@ -0,0 +1,178 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package querier
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/services"
+	"github.com/grafana/dskit/tenant"
+	"github.com/oklog/ulid"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"golang.org/x/sync/errgroup"
+	grpc_metadata "google.golang.org/grpc/metadata"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
+	"github.com/grafana/mimir/pkg/storage/tsdb/block"
+	"github.com/grafana/mimir/pkg/storegateway"
+	"github.com/grafana/mimir/pkg/storegateway/storegatewaypb"
+	"github.com/grafana/mimir/pkg/util"
+	"github.com/grafana/mimir/pkg/util/spanlogger"
+)
+
+// ExemplarQuerier implements storage.ExemplarQueryable. The exemplars are read by the store-gateways from the
+// exemplars files of the blocks.
+func (q *BlocksStoreQueryable) ExemplarQuerier(ctx context.Context) (storage.ExemplarQuerier, error) {
+	if s := q.State(); s != services.Running {
+		return nil, errors.Errorf("BlocksStoreQueryable is not running: %v", s)
+	}
+
+	return &blocksStoreExemplarQuerier{ctx: ctx, queryable: q}, nil
+}
+
+type blocksStoreExemplarQuerier struct {
+	ctx       context.Context
+	queryable *BlocksStoreQueryable
+}
+
+// Select implements storage.ExemplarQuerier.
+func (q *blocksStoreExemplarQuerier) Select(start, end int64, matchers ...[]*labels.Matcher) ([]exemplar.QueryResult, error) {
+	querier := &blocksStoreQuerier{
+		minT:            start,
+		maxT:            end,
+		finder:          q.queryable.finder,
+		stores:          q.queryable.stores,
+		metrics:         q.queryable.metrics,
+		limits:          q.queryable.limits,
+		consistency:     q.queryable.consistency,
+		logger:          q.queryable.logger,
+		queryStoreAfter: q.queryable.queryStoreAfter,
+	}
+	return querier.exemplars(q.ctx, matchers)
+}
+
+func (q *blocksStoreQuerier) exemplars(ctx context.Context, matcherSets [][]*labels.Matcher) ([]exemplar.QueryResult, error) {
+	spanLog, ctx := spanlogger.NewWithLogger(ctx, q.logger, "blocksStoreQuerier.exemplars")
+	defer spanLog.Span.Finish()
+
+	tenantID, err := tenant.TenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	minT, maxT := q.minT, q.maxT
+
+	spanLog.DebugLog("start", util.TimeFromMillis(minT).UTC().String(), "end", util.TimeFromMillis(maxT).UTC().String(), "matcher sets", len(matcherSets))
+
+	convertedMatchers := make([]storegatewaypb.ExemplarMatchers, 0, len(matcherSets))
+	for _, matchers := range matcherSets {
+		convertedMatchers = append(convertedMatchers, storegatewaypb.ExemplarMatchers{Matchers: convertMatchersToLabelMatcher(matchers)})
+	}
+
+	var resSets [][]exemplar.QueryResult
+
+	queryF := func(clients map[BlocksStoreClient][]ulid.ULID, minT, maxT, _ int64) ([]ulid.ULID, error) {
+		sets, queriedBlocks, err := q.fetchExemplarsFromStores(ctx, clients, minT, maxT, tenantID, convertedMatchers)
+		if err != nil {
+			return nil, err
+		}
+
+		resSets = append(resSets, sets...)
+		return queriedBlocks, nil
+	}
+
+	if err := q.queryWithConsistencyCheck(ctx, spanLog, minT, maxT, mimir_tsdb.ResolutionRaw, tenantID, nil, nil, queryF); err != nil {
+		return nil, err
+	}
+
+	return block.MergeExemplars(resSets...), nil
+}
+
+func (q *blocksStoreQuerier) fetchExemplarsFromStores(
+	ctx context.Context,
+	clients map[BlocksStoreClient][]ulid.ULID,
+	minT int64,
+	maxT int64,
+	tenantID string,
+	matchers []storegatewaypb.ExemplarMatchers,
+) ([][]exemplar.QueryResult, []ulid.ULID, error) {
+	var (
+		reqCtx        = grpc_metadata.AppendToOutgoingContext(ctx, storegateway.GrpcContextMetadataTenantID, tenantID)
+		g, gCtx       = errgroup.WithContext(reqCtx)
+		mtx           = sync.Mutex{}
+		sets          [][]exemplar.QueryResult
+		queriedBlocks = []ulid.ULID(nil)
+		spanLog       = spanlogger.FromContext(ctx, q.logger)
+	)
+
+	// Concurrently fetch the exemplars from all clients.
+	for c, blockIDs := range clients {
+		// Change variables scope since it will be used in a goroutine.
+		c := c
+		blockIDs := blockIDs
+
+		g.Go(func() error {
+			req := &storegatewaypb.ExemplarsRequest{
+				MinTime:  minT,
+				MaxTime:  maxT,
+				Matchers: matchers,
+				BlockIds: convertULIDsToString(blockIDs),
+			}
+
+			resp, err := c.Exemplars(gCtx, req)
+			if err != nil {
+				if shouldStopQueryFunc(err) {
+					return err
+				}
+
+				level.Warn(spanLog).Log("msg", "failed to fetch exemplars", "remote", c.RemoteAddress(), "err", err)
+				return nil
+			}
+
+			myQueriedBlocks := make([]ulid.ULID, 0, len(resp.QueriedBlocks))
+			for _, id := range resp.QueriedBlocks {
+				blockID, err := ulid.Parse(id)
+				if err != nil {
+					return errors.Wrapf(err, "failed to parse queried block IDs from %s", c.RemoteAddress())
+				}
+				myQueriedBlocks = append(myQueriedBlocks, blockID)
+			}
+
+			spanLog.DebugLog("msg", "received exemplars from store-gateway",
+				"instance", c,
+				"series", len(resp.Timeseries),
+				"requested blocks", strings.Join(convertULIDsToString(blockIDs), " "),
+				"queried blocks", strings.Join(convertULIDsToString(myQueriedBlocks), " "))
+
+			set := make([]exemplar.QueryResult, 0, len(resp.Timeseries))
+			for _, ts := range resp.Timeseries {
+				set = append(set, exemplar.QueryResult{
+					SeriesLabels: mimirpb.FromLabelAdaptersToLabels(ts.Labels),
+					Exemplars:    mimirpb.FromExemplarProtosToExemplars(ts.Exemplars),
+				})
+			}
+
+			// Store the result.
+			mtx.Lock()
+			sets = append(sets, set)
+			queriedBlocks = append(queriedBlocks, myQueriedBlocks...)
+			mtx.Unlock()
+
+			return nil
+		})
+	}
+
+	// Wait until all client requests complete.
+	if err := g.Wait(); err != nil {
+		return nil, nil, err
+	}
+
+	return sets, queriedBlocks, nil
+}
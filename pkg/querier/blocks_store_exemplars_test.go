@@ -0,0 +1,192 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package querier
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/user"
+	"github.com/oklog/ulid"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+	"github.com/grafana/mimir/pkg/storage/tsdb/bucketindex"
+	"github.com/grafana/mimir/pkg/storegateway/storegatewaypb"
+)
+
+func TestBlocksStoreQuerier_Exemplars(t *testing.T) {
+	const (
+		minT = int64(10)
+		maxT = int64(20)
+	)
+
+	var (
+		block1  = ulid.MustNew(1, nil)
+		block2  = ulid.MustNew(2, nil)
+		series1 = labels.FromStrings(labels.MetricName, "metric_1")
+		series2 = labels.FromStrings(labels.MetricName, "metric_2")
+		trace1  = labels.FromStrings("trace_id", "1")
+		trace2  = labels.FromStrings("trace_id", "2")
+	)
+
+	exemplarsResponse := func(series labels.Labels, exemplars []exemplar.Exemplar, queriedBlocks ...ulid.ULID) *storegatewaypb.ExemplarsResponse {
+		return &storegatewaypb.ExemplarsResponse{
+			Timeseries: []mimirpb.TimeSeries{{
+				Labels:    mimirpb.FromLabelsToLabelAdapters(series),
+				Exemplars: mimirpb.FromExemplarsToExemplarProtos(exemplars),
+			}},
+			QueriedBlocks: convertULIDsToString(queriedBlocks),
+		}
+	}
+
+	tests := map[string]struct {
+		finderResult      bucketindex.Blocks
+		storeSetResponses []interface{}
+		expected          []exemplar.QueryResult
+		expectedErr       string
+	}{
+		"no block in the storage matching the query time range": {
+			finderResult: nil,
+			expected:     []exemplar.QueryResult{},
+		},
+		"multiple store-gateway instances hold the required blocks with overlapping exemplars": {
+			finderResult: bucketindex.Blocks{{ID: block1}, {ID: block2}},
+			storeSetResponses: []interface{}{
+				map[BlocksStoreClient][]ulid.ULID{
+					&storeGatewayClientMock{
+						remoteAddr:              "1.1.1.1",
+						mockedExemplarsResponse: exemplarsResponse(series1, []exemplar.Exemplar{{Labels: trace1, Value: 1, Ts: 10}}, block1),
+					}: {block1},
+					&storeGatewayClientMock{
+						remoteAddr:              "2.2.2.2",
+						mockedExemplarsResponse: exemplarsResponse(series1, []exemplar.Exemplar{{Labels: trace1, Value: 1, Ts: 10}, {Labels: trace2, Value: 2, Ts: 15}}, block2),
+					}: {block2},
+				},
+			},
+			expected: []exemplar.QueryResult{
+				{SeriesLabels: series1, Exemplars: []exemplar.Exemplar{{Labels: trace1, Value: 1, Ts: 10}, {Labels: trace2, Value: 2, Ts: 15}}},
+			},
+		},
+		"a store-gateway instance fails and the missing block is queried from another instance": {
+			finderResult: bucketindex.Blocks{{ID: block1}, {ID: block2}},
+			storeSetResponses: []interface{}{
+				map[BlocksStoreClient][]ulid.ULID{
+					&storeGatewayClientMock{
+						remoteAddr:              "1.1.1.1",
+						mockedExemplarsResponse: exemplarsResponse(series1, []exemplar.Exemplar{{Labels: trace1, Value: 1, Ts: 10}}, block1),
+					}: {block1},
+					&storeGatewayClientMock{
+						remoteAddr:         "2.2.2.2",
+						mockedExemplarsErr: errors.New("failed to query exemplars"),
+					}: {block2},
+				},
+				map[BlocksStoreClient][]ulid.ULID{
+					&storeGatewayClientMock{
+						remoteAddr:              "3.3.3.3",
+						mockedExemplarsResponse: exemplarsResponse(series2, []exemplar.Exemplar{{Labels: trace2, Value: 2, Ts: 15}}, block2),
+					}: {block2},
+				},
+			},
+			expected: []exemplar.QueryResult{
+				{SeriesLabels: series1, Exemplars: []exemplar.Exemplar{{Labels: trace1, Value: 1, Ts: 10}}},
+				{SeriesLabels: series2, Exemplars: []exemplar.Exemplar{{Labels: trace2, Value: 2, Ts: 15}}},
+			},
+		},
+		"a block is not queried by any store-gateway instance": {
+			finderResult: bucketindex.Blocks{{ID: block1}, {ID: block2}},
+			storeSetResponses: []interface{}{
+				map[BlocksStoreClient][]ulid.ULID{
+					&storeGatewayClientMock{
+						remoteAddr:              "1.1.1.1",
+						mockedExemplarsResponse: exemplarsResponse(series1, []exemplar.Exemplar{{Labels: trace1, Value: 1, Ts: 10}}, block1),
+					}: {block1, block2},
+				},
+				errors.New("no store-gateway remaining after exclude"),
+			},
+			expectedErr: "failed to fetch some blocks",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := user.InjectOrgID(context.Background(), "user-1")
+
+			finder := &blocksFinderMock{}
+			finder.On("GetBlocks", mock.Anything, "user-1", minT, maxT).Return(testData.finderResult, map[ulid.ULID]*bucketindex.BlockDeletionMark(nil), nil)
+
+			q := &blocksStoreQuerier{
+				minT:        minT,
+				maxT:        maxT,
+				finder:      finder,
+				stores:      &blocksStoreSetMock{mockedResponses: testData.storeSetResponses},
+				consistency: NewBlocksConsistency(0, 0, log.NewNopLogger(), nil),
+				logger:      log.NewNopLogger(),
+				metrics:     newBlocksStoreQueryableMetrics(prometheus.NewPedanticRegistry()),
+				limits:      &blocksStoreLimitsMock{},
+			}
+
+			actual, err := q.exemplars(ctx, [][]*labels.Matcher{{labels.MustNewMatcher(labels.MatchRegexp, labels.MetricName, "metric_.+")}})
+			if testData.expectedErr != "" {
+				require.ErrorContains(t, err, testData.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, testData.expected, actual)
+		})
+	}
+}
+
+func TestMergeExemplarQueryable(t *testing.T) {
+	series := labels.FromStrings(labels.MetricName, "metric")
+	trace1 := labels.FromStrings("trace_id", "1")
+	trace2 := labels.FromStrings("trace_id", "2")
+
+	queryable := newMergeExemplarQueryable(
+		exemplarQueryableMock{result: []exemplar.QueryResult{{SeriesLabels: series, Exemplars: []exemplar.Exemplar{{Labels: trace1, Value: 1, Ts: 10}}}}},
+		exemplarQueryableMock{result: []exemplar.QueryResult{{SeriesLabels: series, Exemplars: []exemplar.Exemplar{{Labels: trace2, Value: 2, Ts: 5}, {Labels: trace1, Value: 1, Ts: 10}}}}},
+	)
+
+	querier, err := queryable.ExemplarQuerier(context.Background())
+	require.NoError(t, err)
+
+	actual, err := querier.Select(0, 20, []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, labels.MetricName, "metric")})
+	require.NoError(t, err)
+	require.Equal(t, []exemplar.QueryResult{
+		{SeriesLabels: series, Exemplars: []exemplar.Exemplar{{Labels: trace2, Value: 2, Ts: 5}, {Labels: trace1, Value: 1, Ts: 10}}},
+	}, actual)
+
+	t.Run("error", func(t *testing.T) {
+		queryable := newMergeExemplarQueryable(
+			exemplarQueryableMock{},
+			exemplarQueryableMock{err: errors.New("failed to select exemplars")},
+		)
+
+		querier, err := queryable.ExemplarQuerier(context.Background())
+		require.NoError(t, err)
+
+		_, err = querier.Select(0, 20, []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, labels.MetricName, "metric")})
+		require.EqualError(t, err, "failed to select exemplars")
+	})
+}
+
+type exemplarQueryableMock struct {
+	result []exemplar.QueryResult
+	err    error
+}
+
+func (m exemplarQueryableMock) ExemplarQuerier(context.Context) (storage.ExemplarQuerier, error) {
+	return m, nil
+}
+
+func (m exemplarQueryableMock) Select(int64, int64, ...[]*labels.Matcher) ([]exemplar.QueryResult, error) {
+	return m.result, m.err
+}
@@ -106,6 +106,7 @@ type blocksStoreQueryableMetrics struct {
 	blocksFound                                       prometheus.Counter
 	blocksQueried                                     prometheus.Counter
 	blocksWithCompactorShardButIncompatibleQueryShard prometheus.Counter
+	blocksSkippedByIndexStats                         prometheus.Counter
 }
 
 func newBlocksStoreQueryableMetrics(reg prometheus.Registerer) *blocksStoreQueryableMetrics {
@@ -135,6 +136,10 @@ func newBlocksStoreQueryableMetrics(reg prometheus.Registerer) *blocksStoreQuery
 			Name: "cortex_querier_blocks_with_compactor_shard_but_incompatible_query_shard_total",
 			Help: "Blocks that couldn't be checked for query and compactor sharding optimization due to incompatible shard counts.",
 		}),
+		blocksSkippedByIndexStats: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_querier_blocks_skipped_by_index_stats_total",
+			Help: "Number of blocks not queried because their index statistics show that they can't contain the series matching the query.",
+		}),
 	}
 }
 
@@ -148,6 +153,7 @@ type BlocksStoreQueryable struct {
 	consistency              *BlocksConsistency
 	logger                   log.Logger
 	queryStoreAfter          time.Duration
+	lookbackDelta            time.Duration
 	metrics                  *blocksStoreQueryableMetrics
 	limits                   BlocksStoreLimits
 	streamingChunksBatchSize uint64
@@ -163,6 +169,7 @@ func NewBlocksStoreQueryable(
 	consistency *BlocksConsistency,
 	limits BlocksStoreLimits,
 	queryStoreAfter time.Duration,
+	lookbackDelta time.Duration,
 	streamingChunksBatchSize uint64,
 	logger log.Logger,
 	reg prometheus.Registerer,
@@ -177,6 +184,7 @@ func NewBlocksStoreQueryable(
 		finder:                   finder,
 		consistency:              consistency,
 		queryStoreAfter:          queryStoreAfter,
+		lookbackDelta:            lookbackDelta,
 		logger:                   logger,
 		subservices:              manager,
 		subservicesWatcher:       services.NewFailureWatcher(),
@@ -269,7 +277,7 @@ func NewBlocksStoreQueryableFromConfig(querierCfg Config, gatewayCfg storegatewa
 		streamingBufferSize = 0
 	}
 
-	return NewBlocksStoreQueryable(stores, finder, consistency, limits, querierCfg.QueryStoreAfter, streamingBufferSize, logger, reg)
+	return NewBlocksStoreQueryable(stores, finder, consistency, limits, querierCfg.QueryStoreAfter, querierCfg.EngineConfig.LookbackDelta, streamingBufferSize, logger, reg)
 }
 
 func (q *BlocksStoreQueryable) starting(ctx context.Context) error {
@@ -314,9 +322,19 @@ func (q *BlocksStoreQueryable) Querier(mint, maxt int64) (storage.Querier, error
 		consistency:              q.consistency,
 		logger:                   q.logger,
 		queryStoreAfter:          q.queryStoreAfter,
+		lookbackDelta:            q.lookbackDelta,
 	}, nil
 }
 
+// GetSeriesDeletionRequests implements SeriesDeletionRequestsProvider. Series deletion requests
+// are only available when the blocks finder is based on the bucket index.
+func (q *BlocksStoreQueryable) GetSeriesDeletionRequests(ctx context.Context, userID string, minT, maxT int64) ([]*mimir_tsdb.SeriesDeletionRequest, error) {
+	if p, ok := q.finder.(SeriesDeletionRequestsProvider); ok {
+		return p.GetSeriesDeletionRequests(ctx, userID, minT, maxT)
+	}
+	return nil, nil
+}
+
 type blocksStoreQuerier struct {
 	minT, maxT               int64
 	finder                   BlocksFinder
@@ -330,6 +348,9 @@ type blocksStoreQuerier struct {
 	// If set, the querier manipulates the max time to not be greater than
 	// "now - queryStoreAfter" so that most recent blocks are not queried.
 	queryStoreAfter time.Duration
+
+	// The engine lookback delta, used to select the resolution of the blocks queried by the instant selectors.
+	lookbackDelta time.Duration
 }
 
 // Select implements storage.Querier interface.
@@ -369,8 +390,9 @@ func (q *blocksStoreQuerier) LabelNames(ctx context.Context, matchers ...*labels
 		convertedMatchers = convertMatchersToLabelMatcher(matchers)
 	)
 
-	queryF := func(clients map[BlocksStoreClient][]ulid.ULID, minT, maxT int64) ([]ulid.ULID, error) {
-		nameSets, warnings, queriedBlocks, err := q.fetchLabelNamesFromStore(ctx, clients, minT, maxT, tenantID, convertedMatchers)
+	queryF := func(clients map[BlocksStoreClient][]ulid.ULID, minT, maxT, resolution int64) ([]ulid.ULID, error) {
+		// The label names of the downsampled blocks are the ones of the series holding the average.
+		nameSets, warnings, queriedBlocks, err := q.fetchLabelNamesFromStore(ctx, clients, minT, maxT, tenantID, withDownsampleAggregateMatcher(convertedMatchers, resolution, ""))
 		if err != nil {
 			return nil, err
 		}
@@ -381,7 +403,7 @@ func (q *blocksStoreQuerier) LabelNames(ctx context.Context, matchers ...*labels
 		return queriedBlocks, nil
 	}
 
-	if err := q.queryWithConsistencyCheck(ctx, spanLog, minT, maxT, tenantID, nil, queryF); err != nil {
+	if err := q.queryWithConsistencyCheck(ctx, spanLog, minT, maxT, mimir_tsdb.ResolutionRaw, tenantID, nil, matchers, queryF); err != nil {
 		return nil, nil, err
 	}
 
@@ -413,8 +435,14 @@ func (q *blocksStoreQuerier) LabelValues(ctx context.Context, name string, match
 		resWarnings  annotations.Annotations
 	)
 
-	queryF := func(clients map[BlocksStoreClient][]ulid.ULID, minT, maxT int64) ([]ulid.ULID, error) {
-		valueSets, warnings, queriedBlocks, err := q.fetchLabelValuesFromStore(ctx, name, clients, minT, maxT, tenantID, matchers...)
+	queryF := func(clients map[BlocksStoreClient][]ulid.ULID, minT, maxT, resolution int64) ([]ulid.ULID, error) {
+		blockMatchers := matchers
+		if resolution != mimir_tsdb.ResolutionRaw {
+			// The label values of the downsampled blocks are the ones of the series holding the average.
+			blockMatchers = append(slices.Clone(matchers), labels.MustNewMatcher(labels.MatchEqual, mimir_tsdb.DownsampleAggregateLabel, ""))
+		}
+
+		valueSets, warnings, queriedBlocks, err := q.fetchLabelValuesFromStore(ctx, name, clients, minT, maxT, tenantID, blockMatchers...)
 		if err != nil {
 			return nil, err
 		}
@@ -425,7 +453,7 @@ func (q *blocksStoreQuerier) LabelValues(ctx context.Context, name string, match
 		return queriedBlocks, nil
 	}
 
-	if err := q.queryWithConsistencyCheck(ctx, spanLog, minT, maxT, tenantID, nil, queryF); err != nil {
+	if err := q.queryWithConsistencyCheck(ctx, spanLog, minT, maxT, mimir_tsdb.ResolutionRaw, tenantID, nil, matchers, queryF); err != nil {
 		return nil, nil, err
 	}
 
@@ -451,17 +479,27 @@ func (q *blocksStoreQuerier) selectSorted(ctx context.Context, sp *storage.Selec
 		queryLimiter      = limiter.QueryLimiterFromContextWithFallback(ctx)
 	)
 
-	shard, _, err := sharding.ShardFromMatchers(matchers)
+	shard, seriesMatchers, err := sharding.RemoveShardFromMatchers(matchers)
 	if err != nil {
 		return storage.ErrSeriesSet(err)
 	}
 
-	queryF := func(clients map[BlocksStoreClient][]ulid.ULID, minT, maxT int64) ([]ulid.ULID, error) {
-		seriesSets, queriedBlocks, warnings, startStreamingChunks, chunkEstimator, err := q.fetchSeriesFromStores(ctx, sp, clients, minT, maxT, tenantID, convertedMatchers)
+	// The downsampled blocks are queried for the series holding the aggregate matching the PromQL function.
+	maxResolution := maxResolutionForHints(sp, q.lookbackDelta)
+	aggregate := downsampleAggregateForFunc(sp.Func)
+
+	queryF := func(clients map[BlocksStoreClient][]ulid.ULID, minT, maxT, resolution int64) ([]ulid.ULID, error) {
+		seriesSets, queriedBlocks, warnings, startStreamingChunks, chunkEstimator, err := q.fetchSeriesFromStores(ctx, sp, clients, minT, maxT, tenantID, withDownsampleAggregateMatcher(convertedMatchers, resolution, aggregate))
 		if err != nil {
 			return nil, err
 		}
 
+		if resolution != mimir_tsdb.ResolutionRaw && aggregate != "" {
+			for i, set := range seriesSets {
+				seriesSets[i] = dropDownsampleAggregateLabelSeriesSet{SeriesSet: set}
+			}
+		}
+
 		resSeriesSets = append(resSeriesSets, seriesSets...)
 		resWarnings.Merge(warnings)
 		streamStarters = append(streamStarters, startStreamingChunks)
@@ -470,7 +508,7 @@ func (q *blocksStoreQuerier) selectSorted(ctx context.Context, sp *storage.Selec
 		return queriedBlocks, nil
 	}
 
-	err = q.queryWithConsistencyCheck(ctx, spanLog, minT, maxT, tenantID, shard, queryF)
+	err = q.queryWithConsistencyCheck(ctx, spanLog, minT, maxT, maxResolution, tenantID, shard, seriesMatchers, queryF)
 	if err != nil {
 		return storage.ErrSeriesSet(err)
 	}
@@ -506,10 +544,15 @@ func (q *blocksStoreQuerier) selectSorted(ctx context.Context, sp *storage.Selec
 		resWarnings)
 }
 
-type queryFunc func(clients map[BlocksStoreClient][]ulid.ULID, minT, maxT int64) ([]ulid.ULID, error)
+// queryFunc queries the blocks with the input resolution from the store-gateway clients.
+type queryFunc func(clients map[BlocksStoreClient][]ulid.ULID, minT, maxT, resolution int64) ([]ulid.ULID, error)
 
+// queryWithConsistencyCheck queries the blocks in the time range, preferring the coarsest resolution no coarser
+// than maxResolution, and checks that all of them have been queried. The blocks with different resolutions are
+// queried separately. The blocks whose index stats show that they can't contain series matching all the
+// input matchers are skipped.
 func (q *blocksStoreQuerier) queryWithConsistencyCheck(
-	ctx context.Context, spanLog *spanlogger.SpanLogger, minT, maxT int64, tenantID string, shard *sharding.ShardSelector, queryF queryFunc,
+	ctx context.Context, spanLog *spanlogger.SpanLogger, minT, maxT, maxResolution int64, tenantID string, shard *sharding.ShardSelector, matchers []*labels.Matcher, queryF queryFunc,
 ) error {
 	now := time.Now()
 
@@ -546,10 +589,37 @@ func (q *blocksStoreQuerier) queryWithConsistencyCheck(
 		knownBlocks = result
 	}
 
+	knownBlocks = selectBlocksByResolution(knownBlocks, minT, maxT, maxResolution)
+
+	if len(matchers) > 0 {
+		result := filterBlocksByIndexStats(knownBlocks, matchers)
+		if skipped := len(knownBlocks) - len(result); skipped > 0 {
+			spanLog.DebugLog("msg", "skipped blocks that can't contain the matching series according to their index stats", "skipped", skipped)
+			q.metrics.blocksSkippedByIndexStats.Add(float64(skipped))
+		}
+
+		knownBlocks = result
+	}
+
 	q.metrics.blocksQueried.Add(float64(len(knownBlocks)))
 
 	spanLog.DebugLog("msg", "found blocks to query", "expected", knownBlocks.String())
 
+	resolutions, blocksByResolution := groupBlocksByResolution(knownBlocks)
+	for _, resolution := range resolutions {
+		if err := q.queryBlocksWithConsistencyCheck(ctx, spanLog, blocksByResolution[resolution], knownDeletionMarks, minT, maxT, resolution, tenantID, queryF); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// queryBlocksWithConsistencyCheck queries the input blocks, which have the same resolution, retrying the
+// blocks not queried on other store-gateway instances.
+func (q *blocksStoreQuerier) queryBlocksWithConsistencyCheck(
+	ctx context.Context, spanLog *spanlogger.SpanLogger, knownBlocks bucketindex.Blocks, knownDeletionMarks map[ulid.ULID]*bucketindex.BlockDeletionMark, minT, maxT, resolution int64, tenantID string, queryF queryFunc,
+) error {
 	var (
 		// At the beginning the list of blocks to query are all known blocks.
 		remainingBlocks = knownBlocks.GetULIDs()
@@ -577,7 +647,7 @@ func (q *blocksStoreQuerier) queryWithConsistencyCheck(
 
 		// Fetch series from stores. If an error occur we do not retry because retries
 		// are only meant to cover missing blocks.
-		queriedBlocks, err := queryF(clients, minT, maxT)
+		queriedBlocks, err := queryF(clients, minT, maxT, resolution)
 		if err != nil {
 			return err
 		}
@@ -606,7 +676,7 @@ func (q *blocksStoreQuerier) queryWithConsistencyCheck(
 	}
 
 	// We've not been able to query all expected blocks after all retries.
-	level.Warn(util_log.WithContext(ctx, spanLog)).Log("msg", "failed consistency check", "err", err)
+	level.Warn(util_log.WithContext(ctx, spanLog)).Log("msg", "failed consistency check", "resolution", resolution)
 	return newStoreConsistencyCheckFailedError(remainingBlocks)
 }
 
@@ -618,6 +688,18 @@ func newStoreConsistencyCheckFailedError(remainingBlocks []ulid.ULID) error {
 	return fmt.Errorf("%v. The failed blocks are: %s", globalerror.StoreConsistencyCheckFailed.Message("failed to fetch some blocks"), strings.Join(convertULIDsToString(remainingBlocks), " "))
 }
 
+// filterBlocksByIndexStats removes the blocks whose index stats show that they can't contain series matching
+// all the input matchers. The blocks without index stats are kept.
+func filterBlocksByIndexStats(blocks bucketindex.Blocks, matchers []*labels.Matcher) bucketindex.Blocks {
+	result := make(bucketindex.Blocks, 0, len(blocks))
+	for _, b := range blocks {
+		if b.IndexStats.MayContain(matchers) {
+			result = append(result, b)
+		}
+	}
+	return result
+}
+
 // filterBlocksByShard removes blocks that can be safely ignored when using query sharding.
 // We know that block can be safely ignored, if it was compacted using split-and-merge
 // compactor, and it has a valid compactor shard ID. We exploit the fact that split-and-merge
@@ -37,6 +37,7 @@ import (
 	"github.com/grafana/mimir/pkg/mimirpb"
 	"github.com/grafana/mimir/pkg/querier/stats"
 	"github.com/grafana/mimir/pkg/storage/sharding"
+	"github.com/grafana/mimir/pkg/storage/tsdb/block"
 	"github.com/grafana/mimir/pkg/storage/tsdb/bucketindex"
 	"github.com/grafana/mimir/pkg/storegateway/hintspb"
 	"github.com/grafana/mimir/pkg/storegateway/storegatewaypb"
@@ -674,6 +675,37 @@ func TestBlocksStoreQuerier_Select(t *testing.T) {
 					cortex_querier_storegateway_refetches_per_query_count 1
 			`,
 		},
+		"blocks whose index stats show they can't contain the metric are filtered out": {
+			finderResult: bucketindex.Blocks{
+				{ID: block1, IndexStats: &block.IndexStats{MetricNames: []block.MetricNameStats{{Name: "other_metric", NumSeries: 1}}, NumMetricNames: 1, NumLabelNames: 5}},
+				{ID: block2, IndexStats: &block.IndexStats{MetricNames: []block.MetricNameStats{{Name: metricName, NumSeries: 1}}, NumMetricNames: 1, NumLabelNames: 5}},
+				{ID: block3, IndexStats: &block.IndexStats{MetricNames: []block.MetricNameStats{{Name: "other_metric", NumSeries: 1}}, NumMetricNames: 2, NumLabelNames: 5}},
+				{ID: block4},
+			},
+			// The query shard doesn't filter the blocks without compactor shard, but it must not be
+			// checked against the index stats.
+			queryShardID: "2_of_4",
+			storeSetResponses: []interface{}{
+				map[BlocksStoreClient][]ulid.ULID{
+					&storeGatewayClientMock{remoteAddr: "1.1.1.1", mockedSeriesResponses: []*storepb.SeriesResponse{
+						mockSeriesResponse(metricNameLabel, minT, 1),
+						mockSeriesResponse(metricNameLabel, minT+1, 2),
+						mockHintsResponse(block2, block3, block4),
+					}}: {block2, block3, block4}, // block1 doesn't have the metric.
+				},
+			},
+			limits:       &blocksStoreLimitsMock{},
+			queryLimiter: noOpQueryLimiter,
+			expectedSeries: []seriesResult{
+				{
+					lbls: metricNameLabel,
+					values: []valueResult{
+						{t: minT, v: 1},
+						{t: minT + 1, v: 2},
+					},
+				},
+			},
+		},
 		"all blocks are queried if shards don't match": {
 			finderResult: bucketindex.Blocks{
 				{ID: block1, CompactorShardID: "1_of_4"},
@@ -1865,7 +1897,7 @@ func TestBlocksStoreQuerier_PromQLExecution(t *testing.T) {
 
 					// Instantiate the querier that will be executed to run the query.
 					logger := log.NewNopLogger()
-					queryable, err := NewBlocksStoreQueryable(stores, finder, NewBlocksConsistency(0, 0, logger, nil), &blocksStoreLimitsMock{}, 0, 0, logger, nil)
+					queryable, err := NewBlocksStoreQueryable(stores, finder, NewBlocksConsistency(0, 0, logger, nil), &blocksStoreLimitsMock{}, 0, 0, 0, logger, nil)
 					require.NoError(t, err)
 					require.NoError(t, services.StartAndAwaitRunning(context.Background(), queryable))
 					defer services.StopAndAwaitTerminated(context.Background(), queryable) // nolint:errcheck
@@ -1993,6 +2025,40 @@ func TestFilterBlocksByShard(t *testing.T) {
 	}
 }
 
+func TestFilterBlocksByIndexStats(t *testing.T) {
+	upOnly := &block.IndexStats{MetricNames: []block.MetricNameStats{{Name: "up", NumSeries: 1}}, NumMetricNames: 1, NumLabelNames: 5}
+	upAndMore := &block.IndexStats{MetricNames: []block.MetricNameStats{{Name: "up", NumSeries: 1}}, NumMetricNames: 10, NumLabelNames: 5}
+	requestsOnly := &block.IndexStats{MetricNames: []block.MetricNameStats{{Name: "requests_total", NumSeries: 1}}, NumMetricNames: 1, NumLabelNames: 5}
+
+	block1 := &bucketindex.Block{ID: ulid.MustNew(1, nil), IndexStats: upOnly}
+	block2 := &bucketindex.Block{ID: ulid.MustNew(2, nil), IndexStats: upAndMore}
+	block3 := &bucketindex.Block{ID: ulid.MustNew(3, nil), IndexStats: requestsOnly}
+	block4 := &bucketindex.Block{ID: ulid.MustNew(4, nil)}
+	allBlocks := bucketindex.Blocks{block1, block2, block3, block4}
+
+	for name, testcase := range map[string]struct {
+		matchers       []*labels.Matcher
+		expectedBlocks bucketindex.Blocks
+	}{
+		"metric name": {
+			matchers:       []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, labels.MetricName, "up")},
+			expectedBlocks: bucketindex.Blocks{block1, block2, block4},
+		},
+		"metric name not in any block stats": {
+			matchers:       []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, labels.MetricName, "errors_total")},
+			expectedBlocks: bucketindex.Blocks{block2, block4},
+		},
+		"matcher matching the empty metric name": {
+			matchers:       []*labels.Matcher{labels.MustNewMatcher(labels.MatchNotEqual, labels.MetricName, "up")},
+			expectedBlocks: allBlocks,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, testcase.expectedBlocks, filterBlocksByIndexStats(allBlocks, testcase.matchers))
+		})
+	}
+}
+
 type blocksStoreSetMock struct {
 	services.Service
 
@@ -2036,6 +2102,10 @@ type storeGatewayClientMock struct {
 	mockedLabelNamesErr       error
 	mockedLabelValuesResponse *storepb.LabelValuesResponse
 	mockedLabelValuesErr      error
+	mockedCardinalityResponse *storegatewaypb.CardinalityResponse
+	mockedCardinalityErr      error
+	mockedExemplarsResponse   *storegatewaypb.ExemplarsResponse
+	mockedExemplarsErr        error
 }
 
 func (m *storeGatewayClientMock) Series(ctx context.Context, _ *storepb.SeriesRequest, _ ...grpc.CallOption) (storegatewaypb.StoreGateway_SeriesClient, error) {
@@ -2055,6 +2125,14 @@ func (m *storeGatewayClientMock) LabelValues(context.Context, *storepb.LabelValu
 	return m.mockedLabelValuesResponse, m.mockedLabelValuesErr
 }
 
+func (m *storeGatewayClientMock) Cardinality(context.Context, *storegatewaypb.CardinalityRequest, ...grpc.CallOption) (*storegatewaypb.CardinalityResponse, error) {
+	return m.mockedCardinalityResponse, m.mockedCardinalityErr
+}
+
+func (m *storeGatewayClientMock) Exemplars(context.Context, *storegatewaypb.ExemplarsRequest, ...grpc.CallOption) (*storegatewaypb.ExemplarsResponse, error) {
+	return m.mockedExemplarsResponse, m.mockedExemplarsErr
+}
+
 func (m *storeGatewayClientMock) RemoteAddress() string {
 	return m.remoteAddr
 }
@@ -2131,6 +2209,16 @@ func (m *cancelerStoreGatewayClientMock) LabelValues(ctx context.Context, _ *sto
 	return nil, ctx.Err()
 }
 
+func (m *cancelerStoreGatewayClientMock) Cardinality(ctx context.Context, _ *storegatewaypb.CardinalityRequest, _ ...grpc.CallOption) (*storegatewaypb.CardinalityResponse, error) {
+	m.cancel()
+	return nil, ctx.Err()
+}
+
+func (m *cancelerStoreGatewayClientMock) Exemplars(ctx context.Context, _ *storegatewaypb.ExemplarsRequest, _ ...grpc.CallOption) (*storegatewaypb.ExemplarsResponse, error) {
+	m.cancel()
+	return nil, ctx.Err()
+}
+
 func (m *cancelerStoreGatewayClientMock) RemoteAddress() string {
 	return m.remoteAddr
 }
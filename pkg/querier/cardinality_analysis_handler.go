@@ -110,6 +110,43 @@ func ActiveSeriesCardinalityHandler(distributor Distributor, limits *validation.
 	})
 }
 
+// BlocksCardinalityHandler creates handler for blocks cardinality endpoint.
+func BlocksCardinalityHandler(blocks BlocksCardinalityQueryable, limits *validation.Overrides) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		// Guarantee request's context is for a single tenant id
+		tenantID, err := tenant.TenantID(ctx)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if !limits.CardinalityAnalysisEnabled(tenantID) {
+			http.Error(w, fmt.Sprintf("cardinality analysis is disabled for the tenant: %v", tenantID), http.StatusBadRequest)
+			return
+		}
+
+		if blocks == nil {
+			http.Error(w, "blocks cardinality analysis is not available", http.StatusNotImplemented)
+			return
+		}
+
+		req, err := cardinality.DecodeBlocksRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		res, err := blocks.BlocksCardinality(ctx, req.Start, req.End, req.Matchers, req.Limit)
+		if err != nil {
+			respondFromError(err, w)
+			return
+		}
+
+		util.WriteJSONResponse(w, toBlocksCardinalityResponse(res))
+	})
+}
+
 func respondFromError(err error, w http.ResponseWriter) {
 	httpResp, ok := httpgrpc.HTTPResponseFromError(errors.Cause(err))
 	if !ok {
@@ -241,3 +278,45 @@ type labelValuesCardinalityResponse struct {
 type activeSeriesResponse struct {
 	Data []labels.Labels `json:"data"`
 }
+
+func toBlocksCardinalityResponse(res *BlocksCardinalityResult) *blocksCardinalityResponse {
+	resp := &blocksCardinalityResponse{
+		SeriesCountTotal: res.SeriesCount,
+		Metrics:          make([]blocksCardinalityMetric, 0, len(res.Metrics)),
+		LabelNames:       make([]blocksCardinalityLabelName, 0, len(res.LabelNames)),
+		LabelValues:      make([]blocksCardinalityLabelValue, 0, len(res.LabelValues)),
+	}
+	for _, item := range res.Metrics {
+		resp.Metrics = append(resp.Metrics, blocksCardinalityMetric{MetricName: item.LabelValue, SeriesCount: item.SeriesCount})
+	}
+	for _, item := range res.LabelNames {
+		resp.LabelNames = append(resp.LabelNames, blocksCardinalityLabelName{LabelName: item.LabelName, SeriesCount: item.SeriesCount})
+	}
+	for _, item := range res.LabelValues {
+		resp.LabelValues = append(resp.LabelValues, blocksCardinalityLabelValue{LabelName: item.LabelName, LabelValue: item.LabelValue, SeriesCount: item.SeriesCount})
+	}
+	return resp
+}
+
+type blocksCardinalityMetric struct {
+	MetricName  string `json:"metric_name"`
+	SeriesCount uint64 `json:"series_count"`
+}
+
+type blocksCardinalityLabelName struct {
+	LabelName   string `json:"label_name"`
+	SeriesCount uint64 `json:"series_count"`
+}
+
+type blocksCardinalityLabelValue struct {
+	LabelName   string `json:"label_name"`
+	LabelValue  string `json:"label_value"`
+	SeriesCount uint64 `json:"series_count"`
+}
+
+type blocksCardinalityResponse struct {
+	SeriesCountTotal uint64                        `json:"series_count_total"`
+	Metrics          []blocksCardinalityMetric     `json:"metrics"`
+	LabelNames       []blocksCardinalityLabelName  `json:"label_names"`
+	LabelValues      []blocksCardinalityLabelValue `json:"label_values"`
+}
@@ -0,0 +1,63 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package querier
+
+import (
+	"context"
+
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/grafana/mimir/pkg/storage/tsdb/block"
+)
+
+// mergeExemplarQueryable queries the exemplars from multiple queryables, like the ingesters and the store-gateways,
+// and merges them removing the duplicated exemplars.
+type mergeExemplarQueryable struct {
+	queryables []storage.ExemplarQueryable
+}
+
+func newMergeExemplarQueryable(queryables ...storage.ExemplarQueryable) storage.ExemplarQueryable {
+	return &mergeExemplarQueryable{queryables: queryables}
+}
+
+func (m *mergeExemplarQueryable) ExemplarQuerier(ctx context.Context) (storage.ExemplarQuerier, error) {
+	queriers := make([]storage.ExemplarQuerier, 0, len(m.queryables))
+	for _, q := range m.queryables {
+		querier, err := q.ExemplarQuerier(ctx)
+		if err != nil {
+			return nil, err
+		}
+		queriers = append(queriers, querier)
+	}
+	return &mergeExemplarQuerier{queriers: queriers}, nil
+}
+
+type mergeExemplarQuerier struct {
+	queriers []storage.ExemplarQuerier
+}
+
+// Select implements storage.ExemplarQuerier.
+func (m *mergeExemplarQuerier) Select(start, end int64, matchers ...[]*labels.Matcher) ([]exemplar.QueryResult, error) {
+	sets := make([][]exemplar.QueryResult, len(m.queriers))
+
+	g := errgroup.Group{}
+	for i, q := range m.queriers {
+		i, q := i, q
+		g.Go(func() error {
+			res, err := q.Select(start, end, matchers...)
+			if err != nil {
+				return err
+			}
+			sets[i] = res
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return block.MergeExemplars(sets...), nil
+}
@@ -59,6 +59,8 @@ type Config struct {
 	MinimizeIngesterRequests                       bool          `yaml:"minimize_ingester_requests" category:"experimental"` // Enabled by default as of Mimir 2.11, remove altogether in 2.12.
 	MinimiseIngesterRequestsHedgingDelay           time.Duration `yaml:"minimize_ingester_requests_hedging_delay" category:"advanced"`
 
+	ExemplarsFromStoreGatewaysEnabled bool `yaml:"exemplars_from_store_gateways_enabled" category:"experimental"`
+
 	// PromQL engine config.
 	EngineConfig engine.Config `yaml:",inline"`
 }
@@ -93,6 +95,8 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.BoolVar(&cfg.MinimizeIngesterRequests, minimiseIngesterRequestsFlagName, true, "If true, when querying ingesters, only the minimum required ingesters required to reach quorum will be queried initially, with other ingesters queried only if needed due to failures from the initial set of ingesters. Enabling this option reduces resource consumption for the happy path at the cost of increased latency for the unhappy path.")
 	f.DurationVar(&cfg.MinimiseIngesterRequestsHedgingDelay, minimiseIngesterRequestsFlagName+"-hedging-delay", 3*time.Second, "Delay before initiating requests to further ingesters when request minimization is enabled and the initially selected set of ingesters have not all responded. Ignored if -"+minimiseIngesterRequestsFlagName+" is not enabled.")
 
+	f.BoolVar(&cfg.ExemplarsFromStoreGatewaysEnabled, "querier.exemplars-from-store-gateways-enabled", false, "True to query the exemplars from the store-gateways, in addition to the ingesters. The store-gateways serve the exemplars stored in the blocks shipped by the ingesters with -blocks-storage.tsdb.ship-exemplars-enabled.")
+
 	// Why 256 series / ingester/store-gateway?
 	// Based on our testing, 256 series / ingester was a good balance between memory consumption and the CPU overhead of managing a batch of series.
 	f.Uint64Var(&cfg.StreamingChunksPerIngesterSeriesBufferSize, "querier.streaming-chunks-per-ingester-buffer-size", 256, "Number of series to buffer per ingester when streaming chunks from ingesters.")
@@ -160,7 +164,13 @@ func New(cfg Config, limits *validation.Overrides, distributor Distributor, stor
 	distributorQueryable := newDistributorQueryable(distributor, iteratorFunc, limits, queryMetrics, logger)
 
 	queryable := newQueryable(distributorQueryable, storeQueryable, iteratorFunc, cfg, limits, queryMetrics, logger)
+	if provider, ok := storeQueryable.(SeriesDeletionRequestsProvider); ok {
+		queryable = newSeriesDeletionQueryable(queryable, provider, logger)
+	}
 	exemplarQueryable := newDistributorExemplarQueryable(distributor, logger)
+	if storeExemplarQueryable, ok := storeQueryable.(storage.ExemplarQueryable); ok && cfg.ExemplarsFromStoreGatewaysEnabled {
+		exemplarQueryable = newMergeExemplarQueryable(exemplarQueryable, storeExemplarQueryable)
+	}
 
 	lazyQueryable := storage.QueryableFunc(func(minT int64, maxT int64) (storage.Querier, error) {
 		querier, err := queryable.Querier(minT, maxT)
@@ -0,0 +1,161 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package querier
+
+import (
+	"context"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/tenant"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"github.com/prometheus/prometheus/tsdb/tombstones"
+
+	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
+	"github.com/grafana/mimir/pkg/util/spanlogger"
+)
+
+// SeriesDeletionRequestsProvider returns the series deletion requests of a tenant.
+type SeriesDeletionRequestsProvider interface {
+	// GetSeriesDeletionRequests returns the series deletion requests for userID whose
+	// time range overlaps minT and maxT (milliseconds, both included).
+	GetSeriesDeletionRequests(ctx context.Context, userID string, minT, maxT int64) ([]*mimir_tsdb.SeriesDeletionRequest, error)
+}
+
+// newSeriesDeletionQueryable returns a queryable filtering out samples deleted by
+// series deletion requests. Deletion requests are applied at query time until the
+// compactor has rewritten all blocks without the deleted samples, and also
+// after that, so that queries don't return deleted samples from blocks which are
+// marked for deletion but still queried.
+func newSeriesDeletionQueryable(next storage.Queryable, provider SeriesDeletionRequestsProvider, logger log.Logger) storage.Queryable {
+	return storage.QueryableFunc(func(minT, maxT int64) (storage.Querier, error) {
+		q, err := next.Querier(minT, maxT)
+		if err != nil {
+			return nil, err
+		}
+
+		return &seriesDeletionQuerier{
+			Querier:  q,
+			provider: provider,
+			minT:     minT,
+			maxT:     maxT,
+			logger:   logger,
+		}, nil
+	})
+}
+
+type seriesDeletionQuerier struct {
+	storage.Querier
+
+	provider   SeriesDeletionRequestsProvider
+	minT, maxT int64
+	logger     log.Logger
+}
+
+// seriesDeletion is a parsed series deletion request selector.
+type seriesDeletion struct {
+	matchers []*labels.Matcher
+	interval tombstones.Interval
+}
+
+func (q *seriesDeletionQuerier) Select(ctx context.Context, sortSeries bool, hints *storage.SelectHints, matchers ...*labels.Matcher) storage.SeriesSet {
+	userID, err := tenant.TenantID(ctx)
+	if err != nil {
+		return storage.ErrSeriesSet(err)
+	}
+
+	minT, maxT := q.minT, q.maxT
+	if hints != nil {
+		minT, maxT = hints.Start, hints.End
+	}
+
+	requests, err := q.provider.GetSeriesDeletionRequests(ctx, userID, minT, maxT)
+	if err != nil {
+		return storage.ErrSeriesSet(errors.Wrap(err, "get series deletion requests"))
+	}
+
+	set := q.Querier.Select(ctx, sortSeries, hints, matchers...)
+	if len(requests) == 0 {
+		return set
+	}
+
+	deletions := make([]seriesDeletion, 0, len(requests))
+	for _, req := range requests {
+		selectors, err := req.Matchers()
+		if err != nil {
+			// Requests are validated when created, so this should never happen.
+			level.Warn(spanlogger.FromContext(ctx, q.logger)).Log("msg", "skipping invalid series deletion request", "request_id", req.RequestID, "err", err)
+			continue
+		}
+
+		for _, ms := range selectors {
+			deletions = append(deletions, seriesDeletion{
+				matchers: ms,
+				interval: tombstones.Interval{Mint: req.StartTime, Maxt: req.EndTime},
+			})
+		}
+	}
+
+	return &seriesDeletionSeriesSet{SeriesSet: set, deletions: deletions}
+}
+
+type seriesDeletionSeriesSet struct {
+	storage.SeriesSet
+
+	deletions []seriesDeletion
+	curr      storage.Series
+}
+
+func (s *seriesDeletionSeriesSet) Next() bool {
+	if !s.SeriesSet.Next() {
+		return false
+	}
+
+	s.curr = s.SeriesSet.At()
+
+	var intervals tombstones.Intervals
+	lbls := s.curr.Labels()
+	for _, d := range s.deletions {
+		if matchesAll(d.matchers, lbls) {
+			intervals = intervals.Add(d.interval)
+		}
+	}
+
+	if len(intervals) > 0 {
+		s.curr = &seriesWithDeletions{Series: s.curr, intervals: intervals}
+	}
+
+	return true
+}
+
+func (s *seriesDeletionSeriesSet) At() storage.Series {
+	return s.curr
+}
+
+func matchesAll(matchers []*labels.Matcher, lbls labels.Labels) bool {
+	for _, m := range matchers {
+		if !m.Matches(lbls.Get(m.Name)) {
+			return false
+		}
+	}
+	return true
+}
+
+// seriesWithDeletions is a series whose samples within the deletion intervals are skipped.
+type seriesWithDeletions struct {
+	storage.Series
+
+	intervals tombstones.Intervals
+}
+
+func (s *seriesWithDeletions) Iterator(it chunkenc.Iterator) chunkenc.Iterator {
+	if del, ok := it.(*tsdb.DeletedIterator); ok {
+		it = del.Iter
+	}
+
+	return &tsdb.DeletedIterator{Iter: s.Series.Iterator(it), Intervals: s.intervals}
+}
@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package querier
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/mimir/pkg/storage/series"
+	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
+)
+
+type mockSeriesDeletionRequestsProvider struct {
+	requests []*mimir_tsdb.SeriesDeletionRequest
+}
+
+func (m *mockSeriesDeletionRequestsProvider) GetSeriesDeletionRequests(_ context.Context, _ string, minT, maxT int64) ([]*mimir_tsdb.SeriesDeletionRequest, error) {
+	var out []*mimir_tsdb.SeriesDeletionRequest
+	for _, req := range m.requests {
+		if req.Overlaps(minT, maxT) {
+			out = append(out, req)
+		}
+	}
+	return out, nil
+}
+
+type mockSeriesQuerier struct {
+	storage.Querier
+	series []storage.Series
+}
+
+func (m *mockSeriesQuerier) Select(_ context.Context, _ bool, _ *storage.SelectHints, _ ...*labels.Matcher) storage.SeriesSet {
+	return series.NewConcreteSeriesSetFromSortedSeries(m.series)
+}
+
+func TestSeriesDeletionQueryable(t *testing.T) {
+	samples := func(ts ...int64) []model.SamplePair {
+		out := make([]model.SamplePair, 0, len(ts))
+		for _, t := range ts {
+			out = append(out, model.SamplePair{Timestamp: model.Time(t), Value: model.SampleValue(t)})
+		}
+		return out
+	}
+
+	next := storage.QueryableFunc(func(_, _ int64) (storage.Querier, error) {
+		return &mockSeriesQuerier{series: []storage.Series{
+			series.NewConcreteSeries(labels.FromStrings(labels.MetricName, "bar", "pod", "1"), samples(10, 20, 30, 40, 50), nil),
+			series.NewConcreteSeries(labels.FromStrings(labels.MetricName, "foo", "pod", "1"), samples(10, 20, 30, 40, 50), nil),
+			series.NewConcreteSeries(labels.FromStrings(labels.MetricName, "foo", "pod", "2"), samples(10, 20, 30, 40, 50), nil),
+		}}, nil
+	})
+
+	for name, tc := range map[string]struct {
+		requests []*mimir_tsdb.SeriesDeletionRequest
+		minT     int64
+		maxT     int64
+		expected map[string][]int64
+	}{
+		"no deletion requests": {
+			minT: 0, maxT: 100,
+			expected: map[string][]int64{
+				`{__name__="bar", pod="1"}`: {10, 20, 30, 40, 50},
+				`{__name__="foo", pod="1"}`: {10, 20, 30, 40, 50},
+				`{__name__="foo", pod="2"}`: {10, 20, 30, 40, 50},
+			},
+		},
+		"deletion request not overlapping the query time range": {
+			requests: []*mimir_tsdb.SeriesDeletionRequest{{Selectors: []string{`foo`}, StartTime: 200, EndTime: 300}},
+			minT:     0, maxT: 100,
+			expected: map[string][]int64{
+				`{__name__="bar", pod="1"}`: {10, 20, 30, 40, 50},
+				`{__name__="foo", pod="1"}`: {10, 20, 30, 40, 50},
+				`{__name__="foo", pod="2"}`: {10, 20, 30, 40, 50},
+			},
+		},
+		"deletion request matching a single series": {
+			requests: []*mimir_tsdb.SeriesDeletionRequest{{Selectors: []string{`foo{pod="2"}`}, StartTime: 20, EndTime: 40}},
+			minT:     0, maxT: 100,
+			expected: map[string][]int64{
+				`{__name__="bar", pod="1"}`: {10, 20, 30, 40, 50},
+				`{__name__="foo", pod="1"}`: {10, 20, 30, 40, 50},
+				`{__name__="foo", pod="2"}`: {10, 50},
+			},
+		},
+		"multiple selectors and overlapping deletion requests": {
+			requests: []*mimir_tsdb.SeriesDeletionRequest{
+				{Selectors: []string{`foo`, `{pod="1"}`}, StartTime: 0, EndTime: 20},
+				{Selectors: []string{`foo`}, StartTime: 15, EndTime: 30},
+			},
+			minT: 0, maxT: 100,
+			expected: map[string][]int64{
+				`{__name__="bar", pod="1"}`: {30, 40, 50},
+				`{__name__="foo", pod="1"}`: {40, 50},
+				`{__name__="foo", pod="2"}`: {40, 50},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			queryable := newSeriesDeletionQueryable(next, &mockSeriesDeletionRequestsProvider{requests: tc.requests}, log.NewNopLogger())
+
+			q, err := queryable.Querier(tc.minT, tc.maxT)
+			require.NoError(t, err)
+
+			ctx := user.InjectOrgID(context.Background(), "user-1")
+			set := q.Select(ctx, true, nil, labels.MustNewMatcher(labels.MatchRegexp, labels.MetricName, ".+"))
+
+			actual := map[string][]int64{}
+			var it chunkenc.Iterator
+			for set.Next() {
+				s := set.At()
+				it = s.Iterator(it)
+
+				ts := []int64{}
+				for it.Next() != chunkenc.ValNone {
+					t, _ := it.At()
+					ts = append(ts, t)
+				}
+				require.NoError(t, it.Err())
+				actual[s.Labels().String()] = ts
+			}
+			require.NoError(t, set.Err())
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
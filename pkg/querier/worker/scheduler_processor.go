@@ -194,7 +194,20 @@ func (sp *schedulerProcessor) runRequest(ctx context.Context, logger log.Logger,
 		stats.AddQueueTime(queueTime)
 	}
 
-	response, err := sp.handler.Handle(ctx, request)
+	var (
+		response *httpgrpc.HTTPResponse
+		err      error
+	)
+	if h, ok := sp.handler.(StreamingRequestHandler); ok && getHeader(request.Headers, frontendv2pb.ResponseStreamingHeader) != "" {
+		// Large responses are streamed back while they're written, if the frontend allows it.
+		var done bool
+		response, done = sp.runStreamingRequest(ctx, logger, h, queryID, frontendAddress, request, stats, queueTime)
+		if done {
+			return
+		}
+	} else {
+		response, err = sp.handler.Handle(ctx, request)
+	}
 	if err != nil {
 		var ok bool
 		response, ok = httpgrpc.HTTPResponseFromError(err)
@@ -245,12 +258,77 @@ func (sp *schedulerProcessor) runRequest(ctx context.Context, logger log.Logger,
 	}
 }
 
+// runStreamingRequest runs the request, sending the response back to the frontend through a QueryResultStream,
+// which is opened before running the request to let the frontend know which querier is executing the query.
+// If the stream can't be opened, it returns the response, which must be sent to the frontend as usual.
+// It returns true if there's nothing left to send to the frontend.
+func (sp *schedulerProcessor) runStreamingRequest(ctx context.Context, logger log.Logger, handler StreamingRequestHandler, queryID uint64, frontendAddress string, request *httpgrpc.HTTPRequest, stats *querier_stats.Stats, queueTime time.Duration) (*httpgrpc.HTTPResponse, bool) {
+	// Canceling the stream context lets the frontend know that the stream failed.
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := sp.openQueryResultStream(streamCtx, logger, frontendAddress, &frontendv2pb.QueryResultStreamRequest{
+		QueryID:        queryID,
+		QuerierID:      sp.querierID,
+		QueueTimeNanos: queueTime.Nanoseconds(),
+	})
+	if err != nil {
+		level.Warn(logger).Log("msg", "failed to open query result stream to frontend, the result will be sent once the query completes", "err", err, "frontend", frontendAddress)
+		stream = nil
+	}
+
+	w := newStreamingResponseWriter(responseStreamingChunkSize, sp.maxMessageSize, stream)
+	if err := handler.HandleStreaming(ctx, acceptStreamedQueryResponse(request), w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+
+	response, err := w.finish(stats)
+	if err != nil {
+		level.Error(logger).Log("msg", "error streaming query result to frontend", "err", err, "frontend", frontendAddress)
+		return nil, true
+	}
+	return response, response == nil
+}
+
+// openQueryResultStream opens a QueryResultStream to the frontend and sends the first message. The stream
+// can be retried until the first message has been sent, because the frontend didn't receive anything yet.
+func (sp *schedulerProcessor) openQueryResultStream(ctx context.Context, logger log.Logger, frontendAddress string, first *frontendv2pb.QueryResultStreamRequest) (frontendv2pb.FrontendForQuerier_QueryResultStreamClient, error) {
+	var retries int
+
+	for {
+		c, err := sp.frontendPool.GetClientFor(frontendAddress)
+		if err != nil {
+			return nil, err
+		}
+
+		stream, err := c.(frontendv2pb.FrontendForQuerierClient).QueryResultStream(ctx)
+		if err == nil {
+			if err = stream.Send(first); err == nil {
+				return stream, nil
+			}
+		}
+		if retries >= maxNotifyFrontendRetries {
+			return nil, err
+		}
+
+		// If the used connection returned and error, remove it from the pool and retry.
+		level.Warn(logger).Log("msg", "retrying to stream query result to frontend", "err", err, "frontend", frontendAddress, "retries", retries)
+
+		sp.frontendPool.RemoveClientFor(frontendAddress)
+		retries++
+	}
+}
+
 func (sp *schedulerProcessor) createFrontendClient(addr string) (client.PoolClient, error) {
 	opts, err := sp.grpcConfig.DialOption([]grpc.UnaryClientInterceptor{
 		otgrpc.OpenTracingClientInterceptor(opentracing.GlobalTracer()),
 		middleware.ClientUserHeaderInterceptor,
 		middleware.UnaryClientInstrumentInterceptor(sp.frontendClientRequestDuration),
-	}, nil)
+	}, []grpc.StreamClientInterceptor{
+		otgrpc.OpenTracingStreamClientInterceptor(opentracing.GlobalTracer()),
+		middleware.StreamClientUserHeaderInterceptor,
+		middleware.StreamClientInstrumentInterceptor(sp.frontendClientRequestDuration),
+	})
 
 	if err != nil {
 		return nil, err
@@ -0,0 +1,315 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"strings"
+
+	"github.com/grafana/dskit/httpgrpc"
+	httpgrpc_server "github.com/grafana/dskit/httpgrpc/server"
+
+	"github.com/grafana/mimir/pkg/frontend/v2/frontendv2pb"
+	"github.com/grafana/mimir/pkg/mimirpb"
+	querier_stats "github.com/grafana/mimir/pkg/querier/stats"
+)
+
+// responseStreamingChunkSize is the target size of the body chunks of the responses streamed back to the frontend.
+const responseStreamingChunkSize = 1024 * 1024
+
+var errIncompleteQueryResponseStream = errors.New("the query response stream ended with an incomplete message")
+
+// StreamingRequestHandler is a RequestHandler which can also write the HTTP response to a http.ResponseWriter
+// while the response is being produced, so that large responses can be streamed back to the frontend.
+type StreamingRequestHandler interface {
+	RequestHandler
+
+	HandleStreaming(ctx context.Context, r *httpgrpc.HTTPRequest, w http.ResponseWriter) error
+}
+
+type httpRequestHandler struct {
+	*httpgrpc_server.Server
+
+	handler http.Handler
+}
+
+// NewHTTPRequestHandler returns a StreamingRequestHandler serving the requests with the given HTTP handler.
+func NewHTTPRequestHandler(handler http.Handler) StreamingRequestHandler {
+	return &httpRequestHandler{
+		Server:  httpgrpc_server.NewServer(handler),
+		handler: handler,
+	}
+}
+
+func (h *httpRequestHandler) HandleStreaming(ctx context.Context, r *httpgrpc.HTTPRequest, w http.ResponseWriter) error {
+	req, err := http.NewRequestWithContext(ctx, r.Method, r.Url, bytes.NewReader(r.Body))
+	if err != nil {
+		return err
+	}
+	for _, h := range r.Headers {
+		req.Header[h.Key] = h.Values
+	}
+	req.RequestURI = r.Url
+	req.ContentLength = int64(len(r.Body))
+
+	h.handler.ServeHTTP(w, req)
+	return nil
+}
+
+// acceptStreamedQueryResponse returns a copy of the request accepting protobuf query responses encoded as
+// a sequence of messages (see mimirpb.QueryResponseStreamMimeType) instead of a single message.
+func acceptStreamedQueryResponse(request *httpgrpc.HTTPRequest) *httpgrpc.HTTPRequest {
+	accept := getHeader(request.Headers, "Accept")
+	if !strings.Contains(accept, mimirpb.QueryResponseMimeType) {
+		return request
+	}
+
+	headers := make([]*httpgrpc.Header, 0, len(request.Headers))
+	for _, h := range request.Headers {
+		if textproto.CanonicalMIMEHeaderKey(h.Key) != "Accept" {
+			headers = append(headers, h)
+		}
+	}
+	headers = append(headers, &httpgrpc.Header{
+		Key:    "Accept",
+		Values: []string{strings.Replace(accept, mimirpb.QueryResponseMimeType, mimirpb.QueryResponseStreamMimeType, 1)},
+	})
+
+	streamingRequest := *request
+	streamingRequest.Headers = headers
+	return &streamingRequest
+}
+
+// streamingResponseWriter is a http.ResponseWriter sending the response to the frontend through a
+// QueryResultStream while it's being written. Once more than chunkSize bytes of a successful response have
+// been written, the response body is sent in chunks which the frontend can decode one at a time: each message
+// of a protobuf query response stream is sent as a chunk, while any other body is sent in chunks of at most
+// chunkSize bytes. Any other response is sent in a single message once written. If there's no stream, the
+// whole response is buffered and must be sent to the frontend with QueryResult once written.
+type streamingResponseWriter struct {
+	chunkSize      int
+	maxMessageSize int
+
+	// stream is the QueryResultStream to the frontend, which has already been opened. Nil if the stream
+	// couldn't be opened.
+	stream frontendv2pb.FrontendForQuerier_QueryResultStreamClient
+
+	header http.Header
+	code   int
+
+	// framed is whether the body is a sequence of length-delimited query response messages.
+	framed bool
+
+	// partial is the beginning of a length-delimited message whose end hasn't been written yet.
+	partial []byte
+
+	// body is the part of the body which hasn't been sent yet. When the body is framed, it's a single message.
+	body []byte
+
+	headerSent   bool
+	tooLargeSize int
+	err          error
+}
+
+func newStreamingResponseWriter(chunkSize, maxMessageSize int, stream frontendv2pb.FrontendForQuerier_QueryResultStreamClient) *streamingResponseWriter {
+	return &streamingResponseWriter{
+		chunkSize:      chunkSize,
+		maxMessageSize: maxMessageSize,
+		stream:         stream,
+		header:         http.Header{},
+	}
+}
+
+func (w *streamingResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *streamingResponseWriter) WriteHeader(code int) {
+	if w.code != 0 {
+		return
+	}
+
+	w.code = code
+	w.framed = code/100 == 2 && w.header.Get("Content-Type") == mimirpb.QueryResponseStreamMimeType
+}
+
+func (w *streamingResponseWriter) Write(p []byte) (int, error) {
+	if w.code == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.err != nil {
+		return 0, w.err
+	}
+	if w.tooLargeSize > 0 {
+		// The response is going to be replaced by an error, so there's no point keeping it.
+		w.tooLargeSize += len(p)
+		return len(p), nil
+	}
+
+	// The body of unsuccessful responses is never split into chunks.
+	if w.code/100 != 2 {
+		w.body = append(w.body, p...)
+		return len(p), nil
+	}
+
+	if !w.framed {
+		for rest := p; len(rest) > 0; {
+			n := min(w.chunkSize, len(rest))
+			if err := w.writeChunk(rest[:n]); err != nil {
+				return 0, err
+			}
+			rest = rest[n:]
+		}
+		return len(p), nil
+	}
+
+	buf := p
+	if len(w.partial) > 0 {
+		w.partial = append(w.partial, p...)
+		buf = w.partial
+	}
+
+	for {
+		size, n := binary.Uvarint(buf)
+		if n < 0 {
+			w.err = fmt.Errorf("invalid length of query response message")
+			return 0, w.err
+		}
+		if n == 0 || uint64(len(buf)-n) < size {
+			break
+		}
+
+		if err := w.writeChunk(buf[n : n+int(size)]); err != nil {
+			return 0, err
+		}
+		buf = buf[n+int(size):]
+	}
+
+	// The chunks sent to the frontend don't retain the written bytes, so only the incomplete message has to be kept.
+	w.partial = append(w.partial[:0], buf...)
+	return len(p), nil
+}
+
+func (w *streamingResponseWriter) writeChunk(chunk []byte) error {
+	if !w.headerSent {
+		// Small responses are sent to the frontend in a single message once written.
+		buffer := w.stream == nil || ((!w.framed || len(w.body) == 0) && len(w.body)+len(chunk) <= w.chunkSize)
+
+		// Until the body is sent, a response which can't be sent can still be replaced by an error.
+		if (buffer && len(w.body)+len(chunk) >= w.maxMessageSize) || len(w.body) >= w.maxMessageSize || len(chunk) >= w.maxMessageSize {
+			w.tooLargeSize = len(w.body) + len(chunk)
+			w.body = nil
+			return nil
+		}
+
+		if buffer {
+			w.body = append(w.body, chunk...)
+			return nil
+		}
+
+		w.err = w.stream.Send(&frontendv2pb.QueryResultStreamRequest{
+			HttpResponse: &httpgrpc.HTTPResponse{Code: int32(w.code), Headers: w.responseHeaders()},
+			BodyChunk:    w.body,
+		})
+		if w.err != nil {
+			return w.err
+		}
+		w.headerSent = true
+		w.body = nil
+	}
+
+	if len(chunk) >= w.maxMessageSize {
+		w.err = fmt.Errorf("response chunk larger than the max message size (%d vs %d)", len(chunk), w.maxMessageSize)
+		return w.err
+	}
+
+	w.err = w.stream.Send(&frontendv2pb.QueryResultStreamRequest{BodyChunk: chunk})
+	return w.err
+}
+
+// finish sends the rest of the response and the query stats through the stream, and closes it. If there's
+// no stream, it returns the response, which must be sent to the frontend with QueryResult. When an error is
+// returned, the stream context should be canceled so that the frontend doesn't wait for the rest of the response.
+func (w *streamingResponseWriter) finish(stats *querier_stats.Stats) (*httpgrpc.HTTPResponse, error) {
+	if w.stream == nil {
+		return w.response(), nil
+	}
+	if w.err != nil {
+		return nil, w.err
+	}
+
+	msg := &frontendv2pb.QueryResultStreamRequest{Stats: stats}
+	if w.headerSent {
+		if len(w.partial) > 0 {
+			return nil, errIncompleteQueryResponseStream
+		}
+	} else {
+		resp := w.response()
+		msg.HttpResponse = &httpgrpc.HTTPResponse{Code: resp.Code, Headers: resp.Headers}
+		msg.BodyChunk = resp.Body
+	}
+
+	if err := w.stream.Send(msg); err != nil {
+		return nil, err
+	}
+
+	_, err := w.stream.CloseAndRecv()
+	return nil, err
+}
+
+// response returns the written response, when its body hasn't been sent yet.
+func (w *streamingResponseWriter) response() *httpgrpc.HTTPResponse {
+	if w.tooLargeSize > 0 {
+		return &httpgrpc.HTTPResponse{
+			Code: http.StatusRequestEntityTooLarge,
+			Body: []byte(fmt.Sprintf("response larger than the max message size (%d vs %d)", w.tooLargeSize, w.maxMessageSize)),
+		}
+	}
+
+	if len(w.partial) > 0 {
+		return &httpgrpc.HTTPResponse{
+			Code: http.StatusInternalServerError,
+			Body: []byte(errIncompleteQueryResponseStream.Error()),
+		}
+	}
+
+	code := w.code
+	if code == 0 {
+		code = http.StatusOK
+	}
+
+	return &httpgrpc.HTTPResponse{
+		Code:    int32(code),
+		Headers: w.responseHeaders(),
+		Body:    w.body,
+	}
+}
+
+// responseHeaders returns the response headers sent to the frontend. The messages of a protobuf query
+// response stream are sent to the frontend as standalone protobuf query responses.
+func (w *streamingResponseWriter) responseHeaders() []*httpgrpc.Header {
+	headers := make([]*httpgrpc.Header, 0, len(w.header))
+	for k, vs := range w.header {
+		if w.framed && k == "Content-Type" {
+			vs = []string{mimirpb.QueryResponseMimeType}
+		}
+		headers = append(headers, &httpgrpc.Header{Key: k, Values: vs})
+	}
+	return headers
+}
+
+func getHeader(headers []*httpgrpc.Header, name string) string {
+	key := textproto.CanonicalMIMEHeaderKey(name)
+	for _, h := range headers {
+		if textproto.CanonicalMIMEHeaderKey(h.Key) == key && len(h.Values) > 0 {
+			return h.Values[0]
+		}
+	}
+	return ""
+}
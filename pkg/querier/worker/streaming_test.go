@@ -0,0 +1,270 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package worker
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/grafana/mimir/pkg/frontend/v2/frontendv2pb"
+	"github.com/grafana/mimir/pkg/mimirpb"
+	querier_stats "github.com/grafana/mimir/pkg/querier/stats"
+)
+
+func TestAcceptStreamedQueryResponse(t *testing.T) {
+	t.Run("protobuf query responses should be accepted as a stream of messages", func(t *testing.T) {
+		request := &httpgrpc.HTTPRequest{Headers: []*httpgrpc.Header{
+			{Key: "Accept", Values: []string{mimirpb.QueryResponseMimeType + ",application/json"}},
+			{Key: "X-Scope-OrgID", Values: []string{"user-1"}},
+		}}
+
+		actual := acceptStreamedQueryResponse(request)
+		require.Equal(t, mimirpb.QueryResponseStreamMimeType+",application/json", getHeader(actual.Headers, "Accept"))
+		require.Equal(t, "user-1", getHeader(actual.Headers, "X-Scope-OrgID"))
+
+		// The original request must not be modified.
+		require.Equal(t, mimirpb.QueryResponseMimeType+",application/json", getHeader(request.Headers, "Accept"))
+	})
+
+	t.Run("requests not accepting protobuf query responses should be left untouched", func(t *testing.T) {
+		request := &httpgrpc.HTTPRequest{Headers: []*httpgrpc.Header{{Key: "Accept", Values: []string{"application/json"}}}}
+		require.Same(t, request, acceptStreamedQueryResponse(request))
+	})
+}
+
+func TestStreamingResponseWriter(t *testing.T) {
+	const chunkSize = 100
+
+	encodeStream := func(t *testing.T, messages ...mimirpb.QueryResponse) []byte {
+		var buf []byte
+		for _, m := range messages {
+			b, err := m.Marshal()
+			require.NoError(t, err)
+			buf = binary.AppendUvarint(buf, uint64(len(b)))
+			buf = append(buf, b...)
+		}
+		return buf
+	}
+
+	matrixBatch := func(from, to int) mimirpb.QueryResponse {
+		m := mimirpb.QueryResponse{
+			Status: mimirpb.QueryResponse_SUCCESS,
+			Data:   &mimirpb.QueryResponse_Matrix{Matrix: &mimirpb.MatrixData{}},
+		}
+		for i := from; i < to; i++ {
+			m.GetMatrix().Series = append(m.GetMatrix().Series, mimirpb.MatrixSeries{
+				Metric:  []string{"__name__", fmt.Sprintf("series_%d", i)},
+				Samples: []mimirpb.Sample{{TimestampMs: 1, Value: 1}, {TimestampMs: 2, Value: 2}},
+			})
+		}
+		return m
+	}
+
+	t.Run("small protobuf response should be sent in a single message as a standalone response", func(t *testing.T) {
+		stream := &queryResultStreamClientMock{}
+		w := newStreamingResponseWriter(chunkSize, 1000, stream)
+
+		batch := matrixBatch(0, 1)
+		w.Header().Set("Content-Type", mimirpb.QueryResponseStreamMimeType)
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write(encodeStream(t, batch))
+		require.NoError(t, err)
+		require.Empty(t, stream.sent)
+
+		stats := &querier_stats.Stats{FetchedSeriesCount: 1}
+		resp, err := w.finish(stats)
+		require.NoError(t, err)
+		require.Nil(t, resp)
+		require.True(t, stream.closed)
+
+		require.Len(t, stream.sent, 1)
+		require.Equal(t, int32(http.StatusOK), stream.sent[0].HttpResponse.Code)
+		require.Equal(t, mimirpb.QueryResponseMimeType, getHeader(stream.sent[0].HttpResponse.Headers, "Content-Type"))
+		require.Equal(t, stats, stream.sent[0].Stats)
+
+		actual := mimirpb.QueryResponse{}
+		require.NoError(t, actual.Unmarshal(stream.sent[0].BodyChunk))
+		require.Equal(t, batch, actual)
+	})
+
+	t.Run("response should be returned when there's no stream", func(t *testing.T) {
+		w := newStreamingResponseWriter(chunkSize, 1000, nil)
+
+		batches := []mimirpb.QueryResponse{matrixBatch(0, 3), matrixBatch(3, 6)}
+		w.Header().Set("Content-Type", mimirpb.QueryResponseStreamMimeType)
+		_, err := w.Write(encodeStream(t, batches[0]))
+		require.NoError(t, err)
+
+		resp, err := w.finish(nil)
+		require.NoError(t, err)
+		require.Equal(t, int32(http.StatusOK), resp.Code)
+		require.Equal(t, mimirpb.QueryResponseMimeType, getHeader(resp.Headers, "Content-Type"))
+
+		actual := mimirpb.QueryResponse{}
+		require.NoError(t, actual.Unmarshal(resp.Body))
+		require.Equal(t, batches[0], actual)
+	})
+
+	t.Run("large protobuf response should be streamed one message at a time", func(t *testing.T) {
+		stream := &queryResultStreamClientMock{}
+		w := newStreamingResponseWriter(chunkSize, 1000, stream)
+
+		batches := []mimirpb.QueryResponse{matrixBatch(0, 3), matrixBatch(3, 6), matrixBatch(6, 7)}
+		body := encodeStream(t, batches...)
+
+		w.Header().Set("Content-Type", mimirpb.QueryResponseStreamMimeType)
+		w.WriteHeader(http.StatusOK)
+
+		// Write the body in small pieces, so that messages are split across writes.
+		for rest := body; len(rest) > 0; {
+			n := min(7, len(rest))
+			_, err := w.Write(rest[:n])
+			require.NoError(t, err)
+			rest = rest[n:]
+		}
+
+		stats := &querier_stats.Stats{FetchedSeriesCount: 7}
+		resp, err := w.finish(stats)
+		require.NoError(t, err)
+		require.Nil(t, resp)
+		require.True(t, stream.closed)
+
+		require.Equal(t, int32(http.StatusOK), stream.sent[0].HttpResponse.Code)
+		require.Equal(t, mimirpb.QueryResponseMimeType, getHeader(stream.sent[0].HttpResponse.Headers, "Content-Type"))
+
+		var chunks [][]byte
+		for _, msg := range stream.sent {
+			if len(msg.BodyChunk) > 0 {
+				chunks = append(chunks, msg.BodyChunk)
+			}
+		}
+		require.Len(t, chunks, len(batches))
+		for i, batch := range batches {
+			actual := mimirpb.QueryResponse{}
+			require.NoError(t, actual.Unmarshal(chunks[i]))
+			require.Equal(t, batch, actual)
+		}
+		require.Equal(t, stats, stream.sent[len(stream.sent)-1].Stats)
+	})
+
+	t.Run("protobuf response messages should be sent as soon as they're written", func(t *testing.T) {
+		stream := &queryResultStreamClientMock{}
+		w := newStreamingResponseWriter(chunkSize, 1000, stream)
+
+		batches := []mimirpb.QueryResponse{matrixBatch(0, 3), matrixBatch(3, 6), matrixBatch(6, 9)}
+		w.Header().Set("Content-Type", mimirpb.QueryResponseStreamMimeType)
+		w.WriteHeader(http.StatusOK)
+
+		for i, batch := range batches {
+			_, err := w.Write(encodeStream(t, batch))
+			require.NoError(t, err)
+
+			// Each message is sent before the next one is written.
+			var chunks [][]byte
+			for _, msg := range stream.sent {
+				if len(msg.BodyChunk) > 0 {
+					chunks = append(chunks, msg.BodyChunk)
+				}
+			}
+			require.Len(t, chunks, i+1)
+
+			actual := mimirpb.QueryResponse{}
+			require.NoError(t, actual.Unmarshal(chunks[i]))
+			require.Equal(t, batch, actual)
+		}
+
+		_, err := w.finish(nil)
+		require.NoError(t, err)
+		require.True(t, stream.closed)
+	})
+
+	t.Run("large non-protobuf response should be streamed in chunks of bytes", func(t *testing.T) {
+		stream := &queryResultStreamClientMock{}
+		w := newStreamingResponseWriter(chunkSize, 1000, stream)
+
+		body := bytes.Repeat([]byte("a"), 250)
+		w.Header().Set("Content-Type", "application/json")
+		_, err := w.Write(body)
+		require.NoError(t, err)
+
+		_, err = w.finish(nil)
+		require.NoError(t, err)
+
+		var actual []byte
+		for _, msg := range stream.sent {
+			require.LessOrEqual(t, len(msg.BodyChunk), chunkSize)
+			actual = append(actual, msg.BodyChunk...)
+		}
+		require.Equal(t, body, actual)
+	})
+
+	t.Run("unsuccessful response should be sent in a single message", func(t *testing.T) {
+		stream := &queryResultStreamClientMock{}
+		w := newStreamingResponseWriter(chunkSize, 1000, stream)
+
+		body := bytes.Repeat([]byte("a"), 250)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write(body)
+		require.NoError(t, err)
+
+		_, err = w.finish(nil)
+		require.NoError(t, err)
+		require.Len(t, stream.sent, 1)
+		require.Equal(t, int32(http.StatusInternalServerError), stream.sent[0].HttpResponse.Code)
+		require.Equal(t, body, stream.sent[0].BodyChunk)
+	})
+
+	t.Run("response too large to be sent before the body is streamed should be replaced by an error", func(t *testing.T) {
+		stream := &queryResultStreamClientMock{}
+		w := newStreamingResponseWriter(chunkSize, 50, stream)
+
+		w.Header().Set("Content-Type", mimirpb.QueryResponseStreamMimeType)
+		_, err := w.Write(encodeStream(t, matrixBatch(0, 3)))
+		require.NoError(t, err)
+
+		_, err = w.finish(nil)
+		require.NoError(t, err)
+		require.Len(t, stream.sent, 1)
+		require.Equal(t, int32(http.StatusRequestEntityTooLarge), stream.sent[0].HttpResponse.Code)
+	})
+
+	t.Run("incomplete protobuf response stream should fail", func(t *testing.T) {
+		stream := &queryResultStreamClientMock{}
+		w := newStreamingResponseWriter(chunkSize, 1000, stream)
+
+		body := encodeStream(t, matrixBatch(0, 3), matrixBatch(3, 6))
+		w.Header().Set("Content-Type", mimirpb.QueryResponseStreamMimeType)
+		_, err := w.Write(body[:len(body)-1])
+		require.NoError(t, err)
+
+		_, err = w.finish(nil)
+		require.ErrorIs(t, err, errIncompleteQueryResponseStream)
+		require.False(t, stream.closed)
+	})
+}
+
+type queryResultStreamClientMock struct {
+	grpc.ClientStream
+
+	sent   []*frontendv2pb.QueryResultStreamRequest
+	closed bool
+}
+
+func (m *queryResultStreamClientMock) Send(msg *frontendv2pb.QueryResultStreamRequest) error {
+	// The sent chunk must not be retained by the writer, so we copy it like gRPC does when serializing it.
+	msg.BodyChunk = bytes.Clone(msg.BodyChunk)
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func (m *queryResultStreamClientMock) CloseAndRecv() (*frontendv2pb.QueryResultResponse, error) {
+	m.closed = true
+	return &frontendv2pb.QueryResultResponse{}, nil
+}
@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package queue
+
+import (
+	"context"
+	"net/http"
+	"net/textproto"
+
+	"github.com/grafana/dskit/httpgrpc"
+)
+
+const (
+	// QueryPriorityHeader is the HTTP header used by clients to set the priority class of a query.
+	QueryPriorityHeader = "X-Mimir-Query-Priority"
+
+	// HighPriority is the priority class of queries which must not queue behind other queries
+	// of the same tenant, such as rule evaluations.
+	HighPriority = "high"
+
+	// NormalPriority is the priority class of all the other queries.
+	NormalPriority = "normal"
+)
+
+type priorityContextKey int
+
+const queryPriorityKey priorityContextKey = 0
+
+// ParseQueryPriority returns the priority class for the input value. Any value
+// other than HighPriority is considered NormalPriority.
+func ParseQueryPriority(value string) string {
+	if value == HighPriority {
+		return HighPriority
+	}
+	return NormalPriority
+}
+
+// QueryPriorityFromHTTPHeader returns the priority class set in the HTTP request header.
+func QueryPriorityFromHTTPHeader(header http.Header) string {
+	return ParseQueryPriority(header.Get(QueryPriorityHeader))
+}
+
+// QueryPriorityFromHTTPGRPCRequest returns the priority class set in the httpgrpc request header.
+func QueryPriorityFromHTTPGRPCRequest(req *httpgrpc.HTTPRequest) string {
+	if req == nil {
+		return NormalPriority
+	}
+
+	key := textproto.CanonicalMIMEHeaderKey(QueryPriorityHeader)
+	for _, h := range req.Headers {
+		if textproto.CanonicalMIMEHeaderKey(h.Key) == key && len(h.Values) > 0 {
+			return ParseQueryPriority(h.Values[0])
+		}
+	}
+	return NormalPriority
+}
+
+// ContextWithQueryPriority returns a new context carrying the query priority class, so that it
+// can be propagated to the requests the query is split into.
+func ContextWithQueryPriority(ctx context.Context, priority string) context.Context {
+	return context.WithValue(ctx, queryPriorityKey, ParseQueryPriority(priority))
+}
+
+// QueryPriorityFromContext returns the query priority class carried by the context,
+// or NormalPriority if the context doesn't carry any.
+func QueryPriorityFromContext(ctx context.Context) string {
+	if priority, ok := ctx.Value(queryPriorityKey).(string); ok {
+		return priority
+	}
+	return NormalPriority
+}
+
+// InjectQueryPriorityIntoHTTPGRPCRequest sets the query priority class carried by the context
+// into the httpgrpc request header, unless the request already has one.
+func InjectQueryPriorityIntoHTTPGRPCRequest(ctx context.Context, req *httpgrpc.HTTPRequest) {
+	priority, ok := ctx.Value(queryPriorityKey).(string)
+	if !ok {
+		return
+	}
+
+	key := textproto.CanonicalMIMEHeaderKey(QueryPriorityHeader)
+	for _, h := range req.Headers {
+		if textproto.CanonicalMIMEHeaderKey(h.Key) == key {
+			return
+		}
+	}
+	req.Headers = append(req.Headers, &httpgrpc.Header{Key: key, Values: []string{priority}})
+}
@@ -0,0 +1,42 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package queue
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryPriority(t *testing.T) {
+	assert.Equal(t, HighPriority, ParseQueryPriority("high"))
+	assert.Equal(t, NormalPriority, ParseQueryPriority("normal"))
+	assert.Equal(t, NormalPriority, ParseQueryPriority("unknown"))
+	assert.Equal(t, NormalPriority, ParseQueryPriority(""))
+
+	assert.Equal(t, HighPriority, QueryPriorityFromHTTPHeader(http.Header{"X-Mimir-Query-Priority": {"high"}}))
+	assert.Equal(t, NormalPriority, QueryPriorityFromHTTPHeader(http.Header{}))
+
+	assert.Equal(t, NormalPriority, QueryPriorityFromContext(context.Background()))
+	ctx := ContextWithQueryPriority(context.Background(), HighPriority)
+	assert.Equal(t, HighPriority, QueryPriorityFromContext(ctx))
+
+	// The priority carried by the context is injected in requests without a priority.
+	req := &httpgrpc.HTTPRequest{}
+	InjectQueryPriorityIntoHTTPGRPCRequest(ctx, req)
+	assert.Equal(t, HighPriority, QueryPriorityFromHTTPGRPCRequest(req))
+
+	// The priority set in the request is preserved.
+	req = &httpgrpc.HTTPRequest{Headers: []*httpgrpc.Header{{Key: "x-mimir-query-priority", Values: []string{"normal"}}}}
+	InjectQueryPriorityIntoHTTPGRPCRequest(ctx, req)
+	assert.Len(t, req.Headers, 1)
+	assert.Equal(t, NormalPriority, QueryPriorityFromHTTPGRPCRequest(req))
+
+	// Nothing is injected if the context doesn't carry a priority.
+	req = &httpgrpc.HTTPRequest{}
+	InjectQueryPriorityIntoHTTPGRPCRequest(context.Background(), req)
+	assert.Empty(t, req.Headers)
+}
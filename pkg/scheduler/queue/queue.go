@@ -61,14 +61,19 @@ type RequestQueue struct {
 	log log.Logger
 
 	maxOutstandingPerTenant int
+	useCostBasedFairQueuing bool
 	forgetDelay             time.Duration
 
+	// Fraction of the connected querier workers reserved to high priority requests.
+	highPriorityReservedQuerierCapacity float64
+
 	connectedQuerierWorkers *atomic.Int32
 
 	stopRequested              chan struct{} // Written to by stop() to wake up dispatcherLoop() in response to a stop request.
 	stopCompleted              chan struct{} // Closed by dispatcherLoop() after a stop is requested and the dispatcher has stopped.
 	querierOperations          chan querierOperation
 	requestsToEnqueue          chan requestToEnqueue
+	requestCosts               chan requestCost
 	nextRequestForQuerierCalls chan *nextRequestForQuerierCall
 
 	queueLength       *prometheus.GaugeVec   // Per user and reason.
@@ -95,13 +100,22 @@ type requestToEnqueue struct {
 	tenantID    TenantID
 	req         Request
 	maxQueriers int
+	weight      float64
+	priority    string
 	successFn   func()
 	processed   chan error
 }
 
+type requestCost struct {
+	tenantID TenantID
+	cost     float64
+}
+
 func NewRequestQueue(
 	log log.Logger,
 	maxOutstandingPerTenant int,
+	useCostBasedFairQueuing bool,
+	highPriorityReservedQuerierCapacity float64,
 	forgetDelay time.Duration,
 	queueLength *prometheus.GaugeVec,
 	discardedRequests *prometheus.CounterVec,
@@ -110,7 +124,11 @@ func NewRequestQueue(
 	q := &RequestQueue{
 		log:                     log,
 		maxOutstandingPerTenant: maxOutstandingPerTenant,
+		useCostBasedFairQueuing: useCostBasedFairQueuing,
 		forgetDelay:             forgetDelay,
+
+		highPriorityReservedQuerierCapacity: highPriorityReservedQuerierCapacity,
+
 		connectedQuerierWorkers: atomic.NewInt32(0),
 		queueLength:             queueLength,
 		discardedRequests:       discardedRequests,
@@ -122,6 +140,7 @@ func NewRequestQueue(
 		// These channels must not be buffered so that we can detect when dispatcherLoop() has finished.
 		querierOperations:          make(chan querierOperation),
 		requestsToEnqueue:          make(chan requestToEnqueue),
+		requestCosts:               make(chan requestCost),
 		nextRequestForQuerierCalls: make(chan *nextRequestForQuerierCall),
 	}
 
@@ -139,7 +158,7 @@ func (q *RequestQueue) starting(_ context.Context) error {
 
 func (q *RequestQueue) dispatcherLoop() {
 	stopping := false
-	queueBroker := newQueueBroker(q.maxOutstandingPerTenant, q.forgetDelay)
+	queueBroker := newQueueBroker(q.maxOutstandingPerTenant, q.useCostBasedFairQueuing, q.forgetDelay)
 	waitingGetNextRequestForQuerierCalls := list.New()
 
 	for {
@@ -183,8 +202,12 @@ func (q *RequestQueue) dispatcherLoop() {
 			if err == nil {
 				needToDispatchQueries = true
 			}
+		case c := <-q.requestCosts:
+			queueBroker.observeRequestCost(c.tenantID, c.cost)
 		case call := <-q.nextRequestForQuerierCalls:
-			if !q.tryDispatchRequestToQuerier(queueBroker, call) {
+			// The querier worker making the call is idle, in addition to the ones with a waiting call.
+			highPriorityOnly := q.isIdleCapacityReserved(waitingGetNextRequestForQuerierCalls.Len() + 1)
+			if !q.tryDispatchRequestToQuerier(queueBroker, call, highPriorityOnly) {
 				// No requests available for this querier connection right now. Add it to the list to try later.
 				waitingGetNextRequestForQuerierCalls.PushBack(call)
 			}
@@ -197,7 +220,8 @@ func (q *RequestQueue) dispatcherLoop() {
 				call := currentElement.Value.(*nextRequestForQuerierCall)
 				nextElement := currentElement.Next() // We have to capture the next element before calling Remove(), as Remove() clears it.
 
-				if q.tryDispatchRequestToQuerier(queueBroker, call) {
+				highPriorityOnly := q.isIdleCapacityReserved(waitingGetNextRequestForQuerierCalls.Len())
+				if q.tryDispatchRequestToQuerier(queueBroker, call, highPriorityOnly) {
 					waitingGetNextRequestForQuerierCalls.Remove(currentElement)
 				}
 
@@ -232,8 +256,9 @@ func (q *RequestQueue) enqueueRequestToBroker(broker *queueBroker, r requestToEn
 	tr := tenantRequest{
 		tenantID: r.tenantID,
 		req:      r.req,
+		priority: r.priority,
 	}
-	err := broker.enqueueRequestBack(&tr, r.maxQueriers)
+	err := broker.enqueueRequestBack(&tr, r.maxQueriers, r.weight)
 	if err != nil {
 		if errors.Is(err, ErrTooManyRequests) {
 			q.discardedRequests.WithLabelValues(string(r.tenantID)).Inc()
@@ -250,10 +275,22 @@ func (q *RequestQueue) enqueueRequestToBroker(broker *queueBroker, r requestToEn
 	return nil
 }
 
+// isIdleCapacityReserved returns whether the given number of idle querier workers is within the capacity
+// reserved to high priority requests, in which case normal priority requests must not be dispatched.
+func (q *RequestQueue) isIdleCapacityReserved(idleQuerierWorkers int) bool {
+	if q.highPriorityReservedQuerierCapacity <= 0 {
+		return false
+	}
+
+	reserved := int(q.highPriorityReservedQuerierCapacity * float64(q.connectedQuerierWorkers.Load()))
+	return idleQuerierWorkers <= reserved
+}
+
 // tryDispatchRequestToQuerier finds and forwards a request to a waiting GetNextRequestForQuerier call, if a suitable request is available.
+// If highPriorityOnly is true, only high priority requests are forwarded.
 // Returns true if call should be removed from the list of waiting calls (eg. because a request has been forwarded to it), false otherwise.
-func (q *RequestQueue) tryDispatchRequestToQuerier(broker *queueBroker, call *nextRequestForQuerierCall) bool {
-	req, tenant, idx, err := broker.dequeueRequestForQuerier(call.lastUserIndex.last, call.querierID)
+func (q *RequestQueue) tryDispatchRequestToQuerier(broker *queueBroker, call *nextRequestForQuerierCall, highPriorityOnly bool) bool {
+	req, tenant, idx, err := broker.dequeueRequestForQuerier(call.lastUserIndex.last, call.querierID, highPriorityOnly)
 	if err != nil {
 		// If this querier has told us it's shutting down, terminate GetNextRequestForQuerier with an error now...
 		call.sendError(err)
@@ -278,7 +315,7 @@ func (q *RequestQueue) tryDispatchRequestToQuerier(broker *queueBroker, call *ne
 		q.queueLength.WithLabelValues(string(tenant.tenantID)).Dec()
 	} else {
 		// should never error; any item previously in the queue already passed validation
-		err := broker.enqueueRequestFront(req, tenant.maxQueriers)
+		err := broker.enqueueRequestFront(req, tenant.maxQueriers, tenant.weight)
 		if err != nil {
 			level.Error(q.log).Log(
 				"msg", "failed to re-enqueue query request after dequeue",
@@ -292,10 +329,14 @@ func (q *RequestQueue) tryDispatchRequestToQuerier(broker *queueBroker, call *ne
 // EnqueueRequestToDispatcher handles a request from the query frontend and submits it to the initial dispatcher queue
 //
 // maxQueries is tenant-specific value to compute which queriers should handle requests for this tenant.
-// It is passed to each EnqueueRequestToDispatcher, because it can change between calls.
+// weight is the tenant-specific share of querier time used when cost-based fair queuing is enabled.
+// They are passed to each EnqueueRequestToDispatcher, because they can change between calls.
+//
+// priority is the priority class of the request; high priority requests of a tenant are dispatched
+// before its normal priority requests, and can use the querier capacity reserved to them.
 //
 // If request is successfully enqueued, successFn is called before any querier can receive the request.
-func (q *RequestQueue) EnqueueRequestToDispatcher(tenantID string, req Request, maxQueriers int, successFn func()) error {
+func (q *RequestQueue) EnqueueRequestToDispatcher(tenantID string, req Request, maxQueriers int, weight float64, priority string, successFn func()) error {
 	start := time.Now()
 	defer func() {
 		q.enqueueDuration.Observe(time.Since(start).Seconds())
@@ -305,6 +346,8 @@ func (q *RequestQueue) EnqueueRequestToDispatcher(tenantID string, req Request,
 		tenantID:    TenantID(tenantID),
 		req:         req,
 		maxQueriers: maxQueriers,
+		weight:      weight,
+		priority:    priority,
 		successFn:   successFn,
 		processed:   make(chan error),
 	}
@@ -317,6 +360,19 @@ func (q *RequestQueue) EnqueueRequestToDispatcher(tenantID string, req Request,
 	}
 }
 
+// ObserveRequestCost records the cost of a request of the tenant processed by a querier, so that
+// the tenant is charged for it when cost-based fair queuing is enabled. It's a no-op otherwise.
+func (q *RequestQueue) ObserveRequestCost(tenantID string, cost float64) {
+	if !q.useCostBasedFairQueuing {
+		return
+	}
+
+	select {
+	case q.requestCosts <- requestCost{tenantID: TenantID(tenantID), cost: cost}:
+	case <-q.stopCompleted:
+	}
+}
+
 // GetNextRequestForQuerier find next user queue and takes the next request off of it. Will block if there are no requests.
 // By passing user index from previous call of this method, querier guarantees that it iterates over all users fairly.
 // If querier finds that request from the user is already expired, it can get a request for the same user by using UserIndex.ReuseLastUser.
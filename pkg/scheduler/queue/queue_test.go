@@ -42,7 +42,7 @@ func BenchmarkConcurrentQueueOperations(b *testing.B) {
 							queueLength := promauto.With(nil).NewGaugeVec(prometheus.GaugeOpts{}, []string{"user"})
 							discardedRequests := promauto.With(nil).NewCounterVec(prometheus.CounterOpts{}, []string{"user"})
 							enqueueDuration := promauto.With(nil).NewHistogram(prometheus.HistogramOpts{})
-							queue := NewRequestQueue(log.NewNopLogger(), 100, 0, queueLength, discardedRequests, enqueueDuration)
+							queue := NewRequestQueue(log.NewNopLogger(), 100, false, 0, 0, queueLength, discardedRequests, enqueueDuration)
 
 							start := make(chan struct{})
 							producersAndConsumers, ctx := errgroup.WithContext(context.Background())
@@ -76,7 +76,7 @@ func BenchmarkConcurrentQueueOperations(b *testing.B) {
 
 								for i := 0; i < requestCount; i++ {
 									for {
-										err := queue.EnqueueRequestToDispatcher(strconv.Itoa(tenantID), req, maxQueriers, func() {})
+										err := queue.EnqueueRequestToDispatcher(strconv.Itoa(tenantID), req, maxQueriers, 1, NormalPriority, func() {})
 										if err == nil {
 											break
 										}
@@ -145,7 +145,7 @@ func BenchmarkConcurrentQueueOperations(b *testing.B) {
 func TestRequestQueue_GetNextRequestForQuerier_ShouldGetRequestAfterReshardingBecauseQuerierHasBeenForgotten(t *testing.T) {
 	const forgetDelay = 3 * time.Second
 
-	queue := NewRequestQueue(log.NewNopLogger(), 1, forgetDelay,
+	queue := NewRequestQueue(log.NewNopLogger(), 1, false, 0, forgetDelay,
 		promauto.With(nil).NewGaugeVec(prometheus.GaugeOpts{}, []string{"user"}),
 		promauto.With(nil).NewCounterVec(prometheus.CounterOpts{}, []string{"user"}),
 		promauto.With(nil).NewHistogram(prometheus.HistogramOpts{}))
@@ -175,7 +175,7 @@ func TestRequestQueue_GetNextRequestForQuerier_ShouldGetRequestAfterReshardingBe
 
 	// Enqueue a request from an user which would be assigned to querier-1.
 	// NOTE: "user-1" hash falls in the querier-1 shard.
-	require.NoError(t, queue.EnqueueRequestToDispatcher("user-1", "request", 1, nil))
+	require.NoError(t, queue.EnqueueRequestToDispatcher("user-1", "request", 1, 1, NormalPriority, nil))
 
 	startTime := time.Now()
 	querier2wg.Wait()
@@ -189,7 +189,7 @@ func TestRequestQueue_GetNextRequestForQuerier_ShouldReturnAfterContextCancelled
 	const forgetDelay = 3 * time.Second
 	const querierID = "querier-1"
 
-	queue := NewRequestQueue(log.NewNopLogger(), 1, forgetDelay,
+	queue := NewRequestQueue(log.NewNopLogger(), 1, false, 0, forgetDelay,
 		promauto.With(nil).NewGaugeVec(prometheus.GaugeOpts{}, []string{"user"}),
 		promauto.With(nil).NewCounterVec(prometheus.CounterOpts{}, []string{"user"}),
 		promauto.With(nil).NewHistogram(prometheus.HistogramOpts{}))
@@ -223,7 +223,7 @@ func TestRequestQueue_GetNextRequestForQuerier_ShouldReturnImmediatelyIfQuerierI
 	const forgetDelay = 3 * time.Second
 	const querierID = "querier-1"
 
-	queue := NewRequestQueue(log.NewNopLogger(), 1, forgetDelay,
+	queue := NewRequestQueue(log.NewNopLogger(), 1, false, 0, forgetDelay,
 		promauto.With(nil).NewGaugeVec(prometheus.GaugeOpts{}, []string{"user"}),
 		promauto.With(nil).NewCounterVec(prometheus.CounterOpts{}, []string{"user"}),
 		promauto.With(nil).NewHistogram(prometheus.HistogramOpts{}))
@@ -245,14 +245,14 @@ func TestRequestQueue_tryDispatchRequestToQuerier_ShouldReEnqueueAfterFailedSend
 	const forgetDelay = 3 * time.Second
 	const querierID = "querier-1"
 
-	queue := NewRequestQueue(log.NewNopLogger(), 1, forgetDelay,
+	queue := NewRequestQueue(log.NewNopLogger(), 1, false, 0, forgetDelay,
 		promauto.With(nil).NewGaugeVec(prometheus.GaugeOpts{}, []string{"user"}),
 		promauto.With(nil).NewCounterVec(prometheus.CounterOpts{}, []string{"user"}),
 		promauto.With(nil).NewHistogram(prometheus.HistogramOpts{}))
 
 	// bypassing queue dispatcher loop for direct usage of the queueBroker and
 	// passing a nextRequestForQuerierCall for a canceled querier connection
-	queueBroker := newQueueBroker(queue.maxOutstandingPerTenant, queue.forgetDelay)
+	queueBroker := newQueueBroker(queue.maxOutstandingPerTenant, queue.useCostBasedFairQueuing, queue.forgetDelay)
 	queueBroker.addQuerierConnection(querierID)
 
 	tenantMaxQueriers := 0 // no sharding
@@ -262,7 +262,7 @@ func TestRequestQueue_tryDispatchRequestToQuerier_ShouldReEnqueueAfterFailedSend
 	}
 
 	require.Nil(t, queueBroker.tenantQueuesTree.getNode(QueuePath{"tenant-1"}))
-	require.NoError(t, queueBroker.enqueueRequestBack(&tr, tenantMaxQueriers))
+	require.NoError(t, queueBroker.enqueueRequestBack(&tr, tenantMaxQueriers, 1))
 	require.False(t, queueBroker.tenantQueuesTree.getNode(QueuePath{"tenant-1"}).IsEmpty())
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -276,7 +276,71 @@ func TestRequestQueue_tryDispatchRequestToQuerier_ShouldReEnqueueAfterFailedSend
 
 	// send to querier will fail but method returns true,
 	// indicating not to re-submit a request for nextRequestForQuerierCall for the querier
-	require.True(t, queue.tryDispatchRequestToQuerier(queueBroker, call))
+	require.True(t, queue.tryDispatchRequestToQuerier(queueBroker, call, false))
 	// assert request was re-enqueued for tenant after failed send
 	require.False(t, queueBroker.tenantQueuesTree.getNode(QueuePath{"tenant-1"}).IsEmpty())
 }
+
+func TestRequestQueue_GetNextRequestForQuerier_ShouldReserveQuerierCapacityToHighPriorityRequests(t *testing.T) {
+	const querierID = "querier-1"
+
+	queue := NewRequestQueue(log.NewNopLogger(), 100, false, 0.5, 0,
+		promauto.With(nil).NewGaugeVec(prometheus.GaugeOpts{}, []string{"user"}),
+		promauto.With(nil).NewCounterVec(prometheus.CounterOpts{}, []string{"user"}),
+		promauto.With(nil).NewHistogram(prometheus.HistogramOpts{}))
+
+	ctx := context.Background()
+	require.NoError(t, services.StartAndAwaitRunning(ctx, queue))
+	t.Cleanup(func() {
+		require.NoError(t, services.StopAndAwaitTerminated(ctx, queue))
+	})
+
+	// Two querier workers are connected, so one is reserved to high priority requests.
+	queue.RegisterQuerierConnection(querierID)
+	queue.RegisterQuerierConnection(querierID)
+
+	require.NoError(t, queue.EnqueueRequestToDispatcher("user-1", "normal-1", 0, 1, NormalPriority, nil))
+
+	getNextRequest := func(ctx context.Context) chan Request {
+		ch := make(chan Request, 1)
+		go func() {
+			req, _, err := queue.GetNextRequestForQuerier(ctx, FirstUser(), querierID)
+			if err == nil {
+				ch <- req
+			}
+			close(ch)
+		}()
+		return ch
+	}
+
+	// The only idle querier worker is reserved, so the normal priority request is not dispatched to it...
+	worker1Ctx, cancelWorker1 := context.WithCancel(ctx)
+	t.Cleanup(cancelWorker1)
+
+	worker1 := getNextRequest(worker1Ctx)
+	select {
+	case req := <-worker1:
+		require.Failf(t, "unexpected request dispatched", "request: %v", req)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// ...while a high priority request is.
+	require.NoError(t, queue.EnqueueRequestToDispatcher("user-2", "high-1", 0, 1, HighPriority, nil))
+	require.Equal(t, "high-1", <-worker1)
+
+	// Once both querier workers are idle, the normal priority request is dispatched to one of them.
+	worker1 = getNextRequest(worker1Ctx)
+	time.Sleep(20 * time.Millisecond) // Wait for GetNextRequestForQuerier to be waiting for a query.
+
+	worker2 := getNextRequest(ctx)
+	select {
+	case req := <-worker2:
+		require.Equal(t, "normal-1", req)
+	case <-time.After(time.Second):
+		require.Fail(t, "gave up waiting for the normal priority request to be dispatched")
+	}
+
+	cancelWorker1()
+	_, ok := <-worker1
+	require.False(t, ok)
+}
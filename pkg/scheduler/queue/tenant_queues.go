@@ -7,6 +7,7 @@ package queue
 
 import (
 	"errors"
+	"math"
 	"math/rand"
 	"sort"
 	"time"
@@ -39,6 +40,15 @@ func (s querierIDSlice) Search(x QuerierID) int {
 type tenantRequest struct {
 	tenantID TenantID
 	req      Request
+
+	// priority class of the request; requests are queued in a child queue
+	// of the tenant queue for each priority class.
+	priority string
+}
+
+// queuePath returns the path of the request's queue in the tenant queues tree.
+func (r *tenantRequest) queuePath() QueuePath {
+	return QueuePath{string(r.tenantID), ParseQueryPriority(r.priority)}
 }
 
 type querierConn struct {
@@ -84,6 +94,39 @@ type tenantQuerierAssignments struct {
 	// If tenant querier ID set is not nil, only those queriers can handle the tenant's requests,
 	// Tenant querier ID is set to nil if sharding is off or available queriers <= tenant's maxQueriers.
 	tenantQuerierIDs map[TenantID]map[QuerierID]struct{}
+
+	// When enabled, the next tenant for a querier is the one with the lowest accumulated
+	// cost of dequeued requests, normalized by the tenant's weight, instead of round-robin.
+	useCostBasedFairQueuing bool
+
+	// Accumulated cost of the tenants' requests, tracked independently of whether tenants have queued
+	// requests, so that a tenant running one request at a time is charged for it too.
+	tenantCosts map[TenantID]*tenantCost
+
+	// now returns the current time, used to decay the tenants' costs. Overridden in tests.
+	now func() time.Time
+}
+
+// costDecayHalfLife is the time it takes for the accumulated cost of a tenant to halve, so that
+// the fair share of each tenant is based on the cost of its recent requests.
+const costDecayHalfLife = time.Minute
+
+// tenantCost is the exponentially decayed cost of a tenant's requests, divided by the tenant's weight.
+type tenantCost struct {
+	normalizedCost float64
+	updatedAt      time.Time
+
+	// weight of the tenant when its last request was enqueued.
+	weight float64
+}
+
+// decayed returns the normalized cost at the given time.
+func (c *tenantCost) decayed(now time.Time) float64 {
+	elapsed := now.Sub(c.updatedAt)
+	if elapsed <= 0 {
+		return c.normalizedCost
+	}
+	return c.normalizedCost * math.Exp2(-float64(elapsed)/float64(costDecayHalfLife))
 }
 
 type queueTenant struct {
@@ -96,6 +139,10 @@ type queueTenant struct {
 
 	// points up to tenant order to enable efficient removal
 	orderIndex int
+
+	// weight of the tenant for cost-based fair queuing: a tenant with weight 2
+	// gets twice the share of querier time of a tenant with weight 1.
+	weight float64
 }
 
 // queueBroker encapsulates access to tenant queues for pending requests
@@ -108,16 +155,19 @@ type queueBroker struct {
 	maxTenantQueueSize int
 }
 
-func newQueueBroker(maxTenantQueueSize int, forgetDelay time.Duration) *queueBroker {
+func newQueueBroker(maxTenantQueueSize int, useCostBasedFairQueuing bool, forgetDelay time.Duration) *queueBroker {
 	return &queueBroker{
 		tenantQueuesTree: NewTreeQueue("root", maxTenantQueueSize),
 		tenantQuerierAssignments: tenantQuerierAssignments{
-			queriersByID:       map[QuerierID]*querierConn{},
-			querierIDsSorted:   nil,
-			querierForgetDelay: forgetDelay,
-			tenantIDOrder:      nil,
-			tenantsByID:        map[TenantID]*queueTenant{},
-			tenantQuerierIDs:   map[TenantID]map[QuerierID]struct{}{},
+			queriersByID:            map[QuerierID]*querierConn{},
+			querierIDsSorted:        nil,
+			querierForgetDelay:      forgetDelay,
+			tenantIDOrder:           nil,
+			tenantsByID:             map[TenantID]*queueTenant{},
+			tenantQuerierIDs:        map[TenantID]map[QuerierID]struct{}{},
+			useCostBasedFairQueuing: useCostBasedFairQueuing,
+			tenantCosts:             map[TenantID]*tenantCost{},
+			now:                     time.Now,
 		},
 		maxTenantQueueSize: maxTenantQueueSize,
 	}
@@ -130,14 +180,18 @@ func (qb *queueBroker) isEmpty() bool {
 // enqueueRequestBack is the standard interface to enqueue requests for dispatch to queriers.
 //
 // Tenants and tenant-querier shuffle sharding relationships are managed internally as needed.
-func (qb *queueBroker) enqueueRequestBack(request *tenantRequest, tenantMaxQueriers int) error {
-	err := qb.tenantQuerierAssignments.createOrUpdateTenant(request.tenantID, tenantMaxQueriers)
+func (qb *queueBroker) enqueueRequestBack(request *tenantRequest, tenantMaxQueriers int, tenantWeight float64) error {
+	err := qb.tenantQuerierAssignments.createOrUpdateTenant(request.tenantID, tenantMaxQueriers, tenantWeight)
 	if err != nil {
 		return err
 	}
 
-	queuePath := QueuePath{string(request.tenantID)}
-	err = qb.tenantQueuesTree.EnqueueBackByPath(queuePath, request)
+	// requests of all priority classes count towards the max tenant queue size
+	if tenantQueue := qb.tenantQueuesTree.getNode(QueuePath{string(request.tenantID)}); tenantQueue != nil && tenantQueue.ItemCount() >= qb.maxTenantQueueSize {
+		return errors.Join(ErrMaxQueueLengthExceeded, ErrTooManyRequests)
+	}
+
+	err = qb.tenantQueuesTree.EnqueueBackByPath(request.queuePath(), request)
 	if errors.Is(err, ErrMaxQueueLengthExceeded) {
 		return errors.Join(err, ErrTooManyRequests)
 	}
@@ -149,28 +203,47 @@ func (qb *queueBroker) enqueueRequestBack(request *tenantRequest, tenantMaxQueri
 //
 // max tenant queue size checks are skipped even though queue size violations
 // are not expected to occur when re-enqueuing a previously dequeued request.
-func (qb *queueBroker) enqueueRequestFront(request *tenantRequest, tenantMaxQueriers int) error {
-	err := qb.tenantQuerierAssignments.createOrUpdateTenant(request.tenantID, tenantMaxQueriers)
+func (qb *queueBroker) enqueueRequestFront(request *tenantRequest, tenantMaxQueriers int, tenantWeight float64) error {
+	err := qb.tenantQuerierAssignments.createOrUpdateTenant(request.tenantID, tenantMaxQueriers, tenantWeight)
 	if err != nil {
 		return err
 	}
 
-	queuePath := QueuePath{string(request.tenantID)}
-	return qb.tenantQueuesTree.EnqueueFrontByPath(queuePath, request)
+	return qb.tenantQueuesTree.EnqueueFrontByPath(request.queuePath(), request)
 }
 
-func (qb *queueBroker) dequeueRequestForQuerier(lastTenantIndex int, querierID QuerierID) (*tenantRequest, *queueTenant, int, error) {
-	tenant, tenantIndex, err := qb.tenantQuerierAssignments.getNextTenantForQuerier(lastTenantIndex, querierID)
+// dequeueRequestForQuerier dequeues the next request for the querier from the next tenant assigned to it.
+//
+// High priority requests of a tenant are always dequeued before its normal priority requests.
+// If highPriorityOnly is true, only tenants with high priority requests are considered.
+func (qb *queueBroker) dequeueRequestForQuerier(lastTenantIndex int, querierID QuerierID, highPriorityOnly bool) (*tenantRequest, *queueTenant, int, error) {
+	var tenantFilter func(TenantID) bool
+	if highPriorityOnly {
+		tenantFilter = qb.hasHighPriorityRequests
+	}
+
+	tenant, tenantIndex, err := qb.tenantQuerierAssignments.getNextTenantForQuerier(lastTenantIndex, querierID, tenantFilter)
 	if tenant == nil || err != nil {
 		return nil, tenant, tenantIndex, err
 	}
 
 	queuePath := QueuePath{string(tenant.tenantID)}
-	queueElement := qb.tenantQueuesTree.DequeueByPath(queuePath)
+	tenantQueue := qb.tenantQueuesTree.getNode(queuePath)
+	if tenantQueue == nil {
+		// tenant was created but its request was never enqueued
+		qb.tenantQuerierAssignments.removeTenant(tenant.tenantID)
+		return nil, tenant, tenantIndex, nil
+	}
+
+	priority := NormalPriority
+	if qb.hasHighPriorityRequests(tenant.tenantID) {
+		priority = HighPriority
+	}
+	queueElement := tenantQueue.DequeueByPath(QueuePath{priority})
 
-	queueNodeAfterDequeue := qb.tenantQueuesTree.getNode(queuePath)
-	if queueNodeAfterDequeue == nil {
-		// queue node was deleted due to being empty after dequeue
+	if tenantQueue.IsEmpty() {
+		// the tenant queue node is not deleted by dequeuing from its child nodes
+		qb.tenantQueuesTree.deleteNode(queuePath)
 		qb.tenantQuerierAssignments.removeTenant(tenant.tenantID)
 	}
 
@@ -183,6 +256,11 @@ func (qb *queueBroker) dequeueRequestForQuerier(lastTenantIndex int, querierID Q
 	return request, tenant, tenantIndex, nil
 }
 
+func (qb *queueBroker) hasHighPriorityRequests(tenantID TenantID) bool {
+	// empty queue nodes are deleted on dequeue, so the node exists only if it has requests
+	return qb.tenantQueuesTree.getNode(QueuePath{string(tenantID), HighPriority}) != nil
+}
+
 func (qb *queueBroker) addQuerierConnection(querierID QuerierID) {
 	qb.tenantQuerierAssignments.addQuerierConnection(querierID)
 }
@@ -196,20 +274,41 @@ func (qb *queueBroker) notifyQuerierShutdown(querierID QuerierID) {
 }
 
 func (qb *queueBroker) forgetDisconnectedQueriers(now time.Time) int {
+	qb.tenantQuerierAssignments.forgetIdleTenantCosts(now)
 	return qb.tenantQuerierAssignments.forgetDisconnectedQueriers(now)
 }
 
+func (qb *queueBroker) observeRequestCost(tenantID TenantID, cost float64) {
+	qb.tenantQuerierAssignments.observeRequestCost(tenantID, cost)
+}
+
 // getNextTenantForQuerier gets the next tenant in the tenant order assigned to a given querier.
 //
 // The next tenant for the querier is obtained by rotating through the global tenant order
 // starting just after the last tenant the querier received a request for, until a tenant
 // is found that is assigned to the given querier according to the querier shuffle sharding.
 // A newly connected querier provides lastTenantIndex of -1 in order to start at the beginning.
-func (tqa *tenantQuerierAssignments) getNextTenantForQuerier(lastTenantIndex int, querierID QuerierID) (*queueTenant, int, error) {
+//
+// When cost-based fair queuing is enabled, all the tenants assigned to the querier are considered
+// and the one with the lowest normalized cost is selected; ties are broken by the rotation order.
+//
+// If tenantFilter is not nil, tenants for which it returns false are skipped.
+func (tqa *tenantQuerierAssignments) getNextTenantForQuerier(lastTenantIndex int, querierID QuerierID, tenantFilter func(TenantID) bool) (*queueTenant, int, error) {
 	// check if querier is registered and is not shutting down
 	if q := tqa.queriersByID[querierID]; q == nil || q.shuttingDown {
 		return nil, lastTenantIndex, ErrQuerierShuttingDown
 	}
+
+	var (
+		selected     *queueTenant
+		selectedCost float64
+		now          time.Time
+	)
+	selectedIndex := lastTenantIndex
+	if tqa.useCostBasedFairQueuing {
+		now = tqa.now()
+	}
+
 	tenantOrderIndex := lastTenantIndex
 	for iters := 0; iters < len(tqa.tenantIDOrder); iters++ {
 		tenantOrderIndex++
@@ -226,18 +325,38 @@ func (tqa *tenantQuerierAssignments) getNextTenantForQuerier(lastTenantIndex int
 		if tenantID == emptyTenantID {
 			continue
 		}
+		if tenantFilter != nil && !tenantFilter(tenantID) {
+			continue
+		}
 		tenant := tqa.tenantsByID[tenantID]
 
 		tenantQuerierSet := tqa.tenantQuerierIDs[tenantID]
-		if tenantQuerierSet == nil {
-			// tenant can use all queriers
-			return tenant, tenantOrderIndex, nil
-		} else if _, ok := tenantQuerierSet[querierID]; ok {
-			// tenant is assigned this querier
+		if tenantQuerierSet != nil {
+			if _, ok := tenantQuerierSet[querierID]; !ok {
+				// tenant is not assigned this querier
+				continue
+			}
+		}
+
+		// tenant can use all queriers or is assigned this querier
+		if !tqa.useCostBasedFairQueuing {
 			return tenant, tenantOrderIndex, nil
 		}
+
+		cost := 0.0
+		if c := tqa.tenantCosts[tenantID]; c != nil {
+			cost = c.decayed(now)
+		}
+		if selected == nil || cost < selectedCost {
+			selected = tenant
+			selectedCost = cost
+			selectedIndex = tenantOrderIndex
+		}
 	}
 
+	if selected != nil {
+		return selected, selectedIndex, nil
+	}
 	return nil, lastTenantIndex, nil
 }
 
@@ -253,7 +372,7 @@ func (tqa *tenantQuerierAssignments) getTenant(tenantID TenantID) (*queueTenant,
 //
 // New tenants are added to the tenant order list and tenant-querier shards are shuffled if needed.
 // Existing tenants have the tenant-querier shards shuffled only if their maxQueriers has changed.
-func (tqa *tenantQuerierAssignments) createOrUpdateTenant(tenantID TenantID, maxQueriers int) error {
+func (tqa *tenantQuerierAssignments) createOrUpdateTenant(tenantID TenantID, maxQueriers int, weight float64) error {
 	if tenantID == emptyTenantID {
 		// empty tenantID is not allowed; "" is used for free spot
 		return ErrInvalidTenantID
@@ -263,6 +382,10 @@ func (tqa *tenantQuerierAssignments) createOrUpdateTenant(tenantID TenantID, max
 		maxQueriers = 0
 	}
 
+	if weight <= 0 {
+		weight = 1
+	}
+
 	tenant := tqa.tenantsByID[tenantID]
 
 	if tenant == nil {
@@ -294,6 +417,11 @@ func (tqa *tenantQuerierAssignments) createOrUpdateTenant(tenantID TenantID, max
 	}
 
 	// tenant now either retrieved or created
+	tenant.weight = weight
+	if tqa.useCostBasedFairQueuing {
+		tqa.getOrCreateTenantCost(tenantID).weight = weight
+	}
+
 	if tenant.maxQueriers != maxQueriers {
 		// tenant queriers need to be computed/recomputed;
 		// either this is a new tenant with sharding enabled,
@@ -304,6 +432,38 @@ func (tqa *tenantQuerierAssignments) createOrUpdateTenant(tenantID TenantID, max
 	return nil
 }
 
+func (tqa *tenantQuerierAssignments) getOrCreateTenantCost(tenantID TenantID) *tenantCost {
+	c := tqa.tenantCosts[tenantID]
+	if c == nil {
+		c = &tenantCost{updatedAt: tqa.now(), weight: 1}
+		tqa.tenantCosts[tenantID] = c
+	}
+	return c
+}
+
+// observeRequestCost adds the cost of a request processed by a querier to the tenant's normalized cost.
+// The cost is tracked even if the tenant has no more queued requests, and decays over time.
+func (tqa *tenantQuerierAssignments) observeRequestCost(tenantID TenantID, cost float64) {
+	if !tqa.useCostBasedFairQueuing || tenantID == emptyTenantID || cost <= 0 {
+		return
+	}
+
+	c := tqa.getOrCreateTenantCost(tenantID)
+	now := tqa.now()
+	c.normalizedCost = c.decayed(now) + cost/c.weight
+	c.updatedAt = now
+}
+
+// forgetIdleTenantCosts removes the cost of the tenants with no queued requests whose cost has decayed
+// to a negligible value, which is the same as the cost of a tenant which never sent requests.
+func (tqa *tenantQuerierAssignments) forgetIdleTenantCosts(now time.Time) {
+	for tenantID, c := range tqa.tenantCosts {
+		if tqa.tenantsByID[tenantID] == nil && now.Sub(c.updatedAt) > 20*costDecayHalfLife {
+			delete(tqa.tenantCosts, tenantID)
+		}
+	}
+}
+
 func (tqa *tenantQuerierAssignments) addQuerierConnection(querierID QuerierID) {
 	querier := tqa.queriersByID[querierID]
 	if querier != nil {
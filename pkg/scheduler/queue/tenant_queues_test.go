@@ -19,14 +19,14 @@ import (
 )
 
 func TestQueues(t *testing.T) {
-	qb := newQueueBroker(0, 0)
+	qb := newQueueBroker(0, false, 0)
 	assert.NotNil(t, qb)
 	assert.NoError(t, isConsistent(qb))
 
 	qb.addQuerierConnection("querier-1")
 	qb.addQuerierConnection("querier-2")
 
-	req, tenant, lastTenantIndex, err := qb.dequeueRequestForQuerier(-1, "querier-1")
+	req, tenant, lastTenantIndex, err := qb.dequeueRequestForQuerier(-1, "querier-1", false)
 	assert.Nil(t, req)
 	assert.Nil(t, tenant)
 	assert.NoError(t, err)
@@ -72,13 +72,92 @@ func TestQueues(t *testing.T) {
 	qb.removeTenantQueue("four")
 	assert.NoError(t, isConsistent(qb))
 
-	req, _, _, err = qb.dequeueRequestForQuerier(lastTenantIndex, "querier-1")
+	req, _, _, err = qb.dequeueRequestForQuerier(lastTenantIndex, "querier-1", false)
 	assert.Nil(t, req)
 	assert.NoError(t, err)
 }
 
+func TestQueues_CostBasedFairQueuing(t *testing.T) {
+	now := time.Now()
+	qb := newQueueBroker(100, true, 0)
+	qb.tenantQuerierAssignments.now = func() time.Time { return now }
+	qb.addQuerierConnection("querier-1")
+
+	enqueue := func(tenantID TenantID, weight float64, count int) {
+		for i := 0; i < count; i++ {
+			req := &tenantRequest{tenantID: tenantID, req: fmt.Sprintf("%s-%d", tenantID, i)}
+			require.NoError(t, qb.enqueueRequestBack(req, 0, weight))
+		}
+	}
+
+	enqueue("one", 1, 10)
+	enqueue("two", 2, 10)
+	require.NoError(t, isConsistent(qb))
+
+	// Tenants with the same cost are dequeued in round-robin order.
+	lastTenantIndex := -1
+	var dequeued []TenantID
+	for i := 0; i < 2; i++ {
+		_, tenant, idx, err := qb.dequeueRequestForQuerier(lastTenantIndex, "querier-1", false)
+		require.NoError(t, err)
+		lastTenantIndex = idx
+		dequeued = append(dequeued, tenant.tenantID)
+	}
+	assert.Equal(t, []TenantID{"one", "two"}, dequeued)
+
+	// Each request costs 1, so tenant "two" (weight 2) accumulates cost at half the rate
+	// of tenant "one" and gets twice the number of requests dequeued.
+	qb.observeRequestCost("one", 1)
+	qb.observeRequestCost("two", 1)
+
+	dequeued = nil
+	for i := 0; i < 6; i++ {
+		_, tenant, idx, err := qb.dequeueRequestForQuerier(lastTenantIndex, "querier-1", false)
+		require.NoError(t, err)
+		lastTenantIndex = idx
+		dequeued = append(dequeued, tenant.tenantID)
+		qb.observeRequestCost(tenant.tenantID, 1)
+	}
+	assert.Equal(t, []TenantID{"two", "one", "two", "two", "one", "two"}, dequeued)
+	assert.Equal(t, 3.0, qb.tenantQuerierAssignments.tenantCosts["one"].decayed(now))
+	assert.Equal(t, 2.5, qb.tenantQuerierAssignments.tenantCosts["two"].decayed(now))
+	assert.NoError(t, isConsistent(qb))
+}
+
+func TestQueues_CostBasedFairQueuing_ShouldChargeTenantsWithNoQueuedRequests(t *testing.T) {
+	now := time.Now()
+	qb := newQueueBroker(100, true, 0)
+	qb.tenantQuerierAssignments.now = func() time.Time { return now }
+	qb.addQuerierConnection("querier-1")
+
+	// Tenant "heavy" runs one expensive request at a time, so it's removed from the queue
+	// each time its only request is dequeued.
+	require.NoError(t, qb.enqueueRequestBack(&tenantRequest{tenantID: "heavy", req: "heavy-1"}, 0, 1))
+	_, tenant, lastTenantIndex, err := qb.dequeueRequestForQuerier(-1, "querier-1", false)
+	require.NoError(t, err)
+	require.Equal(t, TenantID("heavy"), tenant.tenantID)
+	qb.observeRequestCost("heavy", 10)
+
+	// Its cost is retained, so the light tenant is dequeued first once both have queued requests.
+	require.NoError(t, qb.enqueueRequestBack(&tenantRequest{tenantID: "heavy", req: "heavy-2"}, 0, 1))
+	require.NoError(t, qb.enqueueRequestBack(&tenantRequest{tenantID: "light", req: "light-1"}, 0, 1))
+	_, tenant, _, err = qb.dequeueRequestForQuerier(lastTenantIndex, "querier-1", false)
+	require.NoError(t, err)
+	assert.Equal(t, TenantID("light"), tenant.tenantID)
+
+	// The cost decays over time.
+	assert.Equal(t, 5.0, qb.tenantQuerierAssignments.tenantCosts["heavy"].decayed(now.Add(costDecayHalfLife)))
+
+	// The cost of idle tenants is eventually forgotten.
+	_, _, _, err = qb.dequeueRequestForQuerier(lastTenantIndex, "querier-1", false)
+	require.NoError(t, err)
+	qb.forgetDisconnectedQueriers(now.Add(time.Hour))
+	assert.Empty(t, qb.tenantQuerierAssignments.tenantCosts)
+	assert.NoError(t, isConsistent(qb))
+}
+
 func TestQueuesOnTerminatingQuerier(t *testing.T) {
-	qb := newQueueBroker(0, 0)
+	qb := newQueueBroker(0, false, 0)
 	assert.NotNil(t, qb)
 	assert.NoError(t, isConsistent(qb))
 
@@ -93,7 +172,7 @@ func TestQueuesOnTerminatingQuerier(t *testing.T) {
 
 	// After notify shutdown for querier-2, it's expected to own no queue.
 	qb.notifyQuerierShutdown("querier-2")
-	tenant, _, err := qb.tenantQuerierAssignments.getNextTenantForQuerier(-1, "querier-2")
+	tenant, _, err := qb.tenantQuerierAssignments.getNextTenantForQuerier(-1, "querier-2", nil)
 	assert.Nil(t, tenant)
 	assert.Equal(t, ErrQuerierShuttingDown, err)
 
@@ -102,13 +181,13 @@ func TestQueuesOnTerminatingQuerier(t *testing.T) {
 
 	// After disconnecting querier-2, it's expected to own no queue.
 	qb.tenantQuerierAssignments.removeQuerier("querier-2")
-	tenant, _, err = qb.tenantQuerierAssignments.getNextTenantForQuerier(-1, "querier-2")
+	tenant, _, err = qb.tenantQuerierAssignments.getNextTenantForQuerier(-1, "querier-2", nil)
 	assert.Nil(t, tenant)
 	assert.Equal(t, ErrQuerierShuttingDown, err)
 }
 
 func TestQueuesWithQueriers(t *testing.T) {
-	qb := newQueueBroker(0, 0)
+	qb := newQueueBroker(0, false, 0)
 	assert.NotNil(t, qb)
 	assert.NoError(t, isConsistent(qb))
 
@@ -122,7 +201,7 @@ func TestQueuesWithQueriers(t *testing.T) {
 		qb.addQuerierConnection(qid)
 
 		// No querier has any queues yet.
-		req, tenant, _, err := qb.dequeueRequestForQuerier(-1, qid)
+		req, tenant, _, err := qb.dequeueRequestForQuerier(-1, qid, false)
 		assert.Nil(t, req)
 		assert.Nil(t, tenant)
 		assert.NoError(t, err)
@@ -149,7 +228,7 @@ func TestQueuesWithQueriers(t *testing.T) {
 
 		lastTenantIndex := -1
 		for {
-			_, newIx, err := qb.tenantQuerierAssignments.getNextTenantForQuerier(lastTenantIndex, qid)
+			_, newIx, err := qb.tenantQuerierAssignments.getNextTenantForQuerier(lastTenantIndex, qid, nil)
 			assert.NoError(t, err)
 			if newIx < lastTenantIndex {
 				break
@@ -187,7 +266,7 @@ func TestQueuesConsistency(t *testing.T) {
 
 	for testName, testData := range tests {
 		t.Run(testName, func(t *testing.T) {
-			qb := newQueueBroker(0, testData.forgetDelay)
+			qb := newQueueBroker(0, false, testData.forgetDelay)
 			assert.NotNil(t, qb)
 			assert.NoError(t, isConsistent(qb))
 
@@ -205,7 +284,7 @@ func TestQueuesConsistency(t *testing.T) {
 					assert.NotNil(t, queue)
 				case 1:
 					querierID := generateQuerier(r)
-					_, tenantIndex, _ := qb.tenantQuerierAssignments.getNextTenantForQuerier(lastTenantIndexes[querierID], querierID)
+					_, tenantIndex, _ := qb.tenantQuerierAssignments.getNextTenantForQuerier(lastTenantIndexes[querierID], querierID, nil)
 					lastTenantIndexes[querierID] = tenantIndex
 				case 2:
 					qb.removeTenantQueue(generateTenant(r))
@@ -238,7 +317,7 @@ func TestQueues_ForgetDelay(t *testing.T) {
 	)
 
 	now := time.Now()
-	qb := newQueueBroker(0, forgetDelay)
+	qb := newQueueBroker(0, false, forgetDelay)
 	assert.NotNil(t, qb)
 	assert.NoError(t, isConsistent(qb))
 
@@ -330,7 +409,7 @@ func TestQueues_ForgetDelay_ShouldCorrectlyHandleQuerierReconnectingBeforeForget
 	)
 
 	now := time.Now()
-	qb := newQueueBroker(0, forgetDelay)
+	qb := newQueueBroker(0, false, forgetDelay)
 	assert.NotNil(t, qb)
 	assert.NoError(t, isConsistent(qb))
 
@@ -416,7 +495,7 @@ func getOrAdd(t *testing.T, qb *queueBroker, tenantID TenantID, maxQueriers int)
 
 // getOrAddTenantQueue is a test utility, not intended for use by consumers of queueBroker
 func (qb *queueBroker) getOrAddTenantQueue(tenantID TenantID, maxQueriers int) (*TreeQueue, error) {
-	err := qb.tenantQuerierAssignments.createOrUpdateTenant(tenantID, maxQueriers)
+	err := qb.tenantQuerierAssignments.createOrUpdateTenant(tenantID, maxQueriers, 1)
 	if err != nil {
 		return nil, err
 	}
@@ -447,7 +526,7 @@ func (qb *queueBroker) removeTenantQueue(tenantID TenantID) bool {
 func confirmOrderForQuerier(t *testing.T, qb *queueBroker, querier QuerierID, lastTenantIndex int, queues ...*list.List) int {
 	for _, queue := range queues {
 		var err error
-		tenant, _, err := qb.tenantQuerierAssignments.getNextTenantForQuerier(lastTenantIndex, querier)
+		tenant, _, err := qb.tenantQuerierAssignments.getNextTenantForQuerier(lastTenantIndex, querier, nil)
 		tenantQueue := qb.getQueue(tenant.tenantID)
 		assert.Equal(t, queue, tenantQueue.localQueue)
 		assert.NoError(t, isConsistent(qb))
@@ -494,7 +573,7 @@ func isConsistent(qb *queueBroker) error {
 		}
 	}
 
-	tenantQueueCount := qb.tenantQueuesTree.NodeCount() - 1 // exclude root node
+	tenantQueueCount := len(qb.tenantQueuesTree.childQueueMap) // priority class queues are children of the tenant queues
 	if tenantCount != tenantQueueCount {
 		return fmt.Errorf("inconsistent number of tenants list and tenant queues")
 	}
@@ -273,7 +273,7 @@ func (q *TreeQueue) deleteNode(childPath QueuePath) bool {
 	delete(parentNode.childQueueMap, childQueueName)
 	for i, name := range parentNode.childQueueOrder {
 		if name == childQueueName {
-			parentNode.childQueueOrder = append(q.childQueueOrder[:i], q.childQueueOrder[i+1:]...)
+			parentNode.childQueueOrder = append(parentNode.childQueueOrder[:i], parentNode.childQueueOrder[i+1:]...)
 			parentNode.wrapIndex(false)
 			break
 		}
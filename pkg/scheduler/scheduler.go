@@ -38,6 +38,8 @@ import (
 	"github.com/grafana/mimir/pkg/util/validation"
 )
 
+var errInvalidHighPriorityReservedQuerierCapacity = errors.New("invalid high priority reserved querier capacity, must be greater than or equal to 0 and less than 1")
+
 // Scheduler is responsible for queueing and dispatching queries to Queriers.
 type Scheduler struct {
 	services.Service
@@ -89,20 +91,27 @@ type connectedFrontend struct {
 }
 
 type Config struct {
-	MaxOutstandingPerTenant int                       `yaml:"max_outstanding_requests_per_tenant"`
-	QuerierForgetDelay      time.Duration             `yaml:"querier_forget_delay" category:"experimental"`
-	GRPCClientConfig        grpcclient.Config         `yaml:"grpc_client_config" doc:"description=This configures the gRPC client used to report errors back to the query-frontend."`
-	ServiceDiscovery        schedulerdiscovery.Config `yaml:",inline"`
+	MaxOutstandingPerTenant             int                       `yaml:"max_outstanding_requests_per_tenant"`
+	QuerierForgetDelay                  time.Duration             `yaml:"querier_forget_delay" category:"experimental"`
+	CostBasedFairQueuingEnabled         bool                      `yaml:"cost_based_fair_queuing_enabled" category:"experimental"`
+	HighPriorityReservedQuerierCapacity float64                   `yaml:"high_priority_reserved_querier_capacity" category:"experimental"`
+	GRPCClientConfig                    grpcclient.Config         `yaml:"grpc_client_config" doc:"description=This configures the gRPC client used to report errors back to the query-frontend."`
+	ServiceDiscovery                    schedulerdiscovery.Config `yaml:",inline"`
 }
 
 func (cfg *Config) RegisterFlags(f *flag.FlagSet, logger log.Logger) {
 	f.IntVar(&cfg.MaxOutstandingPerTenant, "query-scheduler.max-outstanding-requests-per-tenant", 100, "Maximum number of outstanding requests per tenant per query-scheduler. In-flight requests above this limit will fail with HTTP response status code 429.")
 	f.DurationVar(&cfg.QuerierForgetDelay, "query-scheduler.querier-forget-delay", 0, "If a querier disconnects without sending notification about graceful shutdown, the query-scheduler will keep the querier in the tenant's shard until the forget delay has passed. This feature is useful to reduce the blast radius when shuffle-sharding is enabled.")
+	f.BoolVar(&cfg.CostBasedFairQueuingEnabled, "query-scheduler.cost-based-fair-queuing-enabled", false, "When enabled, the query-scheduler dispatches requests of the tenant that has consumed the least querier time, normalized by the tenant's weight, instead of round-robin across tenants.")
+	f.Float64Var(&cfg.HighPriorityReservedQuerierCapacity, "query-scheduler.high-priority-reserved-querier-capacity", 0, "Fraction of the querier workers connected to the query-scheduler reserved to high priority queries, such as rule evaluations. Normal priority queries are not dispatched when they would leave fewer idle querier workers than the reserved ones. The priority of a query is set with the "+queue.QueryPriorityHeader+" request header, if enabled for the tenant with -query-frontend.query-priority-header-enabled. 0 to disable.")
 	cfg.GRPCClientConfig.RegisterFlagsWithPrefix("query-scheduler.grpc-client-config", f)
 	cfg.ServiceDiscovery.RegisterFlags(f, logger)
 }
 
 func (cfg *Config) Validate() error {
+	if cfg.HighPriorityReservedQuerierCapacity < 0 || cfg.HighPriorityReservedQuerierCapacity >= 1 {
+		return errInvalidHighPriorityReservedQuerierCapacity
+	}
 	return cfg.ServiceDiscovery.Validate()
 }
 
@@ -137,7 +146,7 @@ func NewScheduler(cfg Config, limits Limits, log log.Logger, registerer promethe
 		Name: "cortex_query_scheduler_enqueue_duration_seconds",
 		Help: "Time spent by requests waiting to join the queue or be rejected.",
 	})
-	s.requestQueue = queue.NewRequestQueue(s.log, cfg.MaxOutstandingPerTenant, cfg.QuerierForgetDelay, s.queueLength, s.discardedRequests, enqueueDuration)
+	s.requestQueue = queue.NewRequestQueue(s.log, cfg.MaxOutstandingPerTenant, cfg.CostBasedFairQueuingEnabled, cfg.HighPriorityReservedQuerierCapacity, cfg.QuerierForgetDelay, s.queueLength, s.discardedRequests, enqueueDuration)
 
 	s.queueDuration = promauto.With(registerer).NewHistogram(prometheus.HistogramOpts{
 		Name:    "cortex_query_scheduler_queue_duration_seconds",
@@ -187,6 +196,9 @@ func NewScheduler(cfg Config, limits Limits, log log.Logger, registerer promethe
 type Limits interface {
 	// MaxQueriersPerUser returns max queriers to use per tenant, or 0 if shuffle sharding is disabled.
 	MaxQueriersPerUser(user string) int
+
+	// QuerySchedulerTenantWeight returns the weight of the tenant used by cost-based fair queuing.
+	QuerySchedulerTenantWeight(user string) float64
 }
 
 type schedulerRequest struct {
@@ -354,9 +366,11 @@ func (s *Scheduler) enqueueRequest(requestContext context.Context, frontendAddr
 		return err
 	}
 	maxQueriers := validation.SmallestPositiveNonZeroIntPerTenant(tenantIDs, s.limits.MaxQueriersPerUser)
+	weight := validation.SmallestPositiveNonZeroFloat64PerTenant(tenantIDs, s.limits.QuerySchedulerTenantWeight)
+	priority := queue.QueryPriorityFromHTTPGRPCRequest(msg.HttpRequest)
 
 	s.activeUsers.UpdateUserTimestamp(userID, now)
-	return s.requestQueue.EnqueueRequestToDispatcher(userID, req, maxQueriers, func() {
+	return s.requestQueue.EnqueueRequestToDispatcher(userID, req, maxQueriers, weight, priority, func() {
 		shouldCancel = false
 
 		s.pendingRequestsMu.Lock()
@@ -455,6 +469,7 @@ func (s *Scheduler) forwardRequestToQuerier(querier schedulerpb.SchedulerForQuer
 	// monitor the contexts in a select and cancel things appropriately.
 	errCh := make(chan error, 1)
 	go func() {
+		start := time.Now()
 		err := querier.Send(&schedulerpb.SchedulerToQuerier{
 			UserID:          req.userID,
 			QueryID:         req.queryID,
@@ -469,6 +484,11 @@ func (s *Scheduler) forwardRequestToQuerier(querier schedulerpb.SchedulerForQuer
 		}
 
 		_, err = querier.Recv()
+		if err == nil {
+			// The time spent by the querier processing the request is the cost
+			// the tenant is charged for by cost-based fair queuing.
+			s.requestQueue.ObserveRequestCost(req.userID, time.Since(start).Seconds())
+		}
 		errCh <- err
 	}()
 
@@ -7,6 +7,7 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
@@ -562,6 +563,10 @@ func (l limits) MaxQueriersPerUser(_ string) int {
 	return l.queriers
 }
 
+func (l limits) QuerySchedulerTenantWeight(_ string) float64 {
+	return 1
+}
+
 type frontendMock struct {
 	mu   sync.Mutex
 	resp map[uint64]*httpgrpc.HTTPResponse
@@ -575,6 +580,10 @@ func (f *frontendMock) QueryResult(_ context.Context, request *frontendv2pb.Quer
 	return &frontendv2pb.QueryResultResponse{}, nil
 }
 
+func (f *frontendMock) QueryResultStream(frontendv2pb.FrontendForQuerier_QueryResultStreamServer) error {
+	return errors.New("not implemented")
+}
+
 func (f *frontendMock) getRequest(queryID uint64) *httpgrpc.HTTPResponse {
 	f.mu.Lock()
 	defer f.mu.Unlock()
@@ -121,6 +121,15 @@ func Upload(ctx context.Context, logger log.Logger, bkt objstore.Bucket, blockDi
 		return cleanUp(logger, bkt, id, errors.Wrap(err, "upload index"))
 	}
 
+	// The exemplars file is optional.
+	if _, err := os.Stat(filepath.Join(blockDir, ExemplarsFilename)); err == nil {
+		if err := objstore.UploadFile(ctx, logger, bkt, filepath.Join(blockDir, ExemplarsFilename), path.Join(id.String(), ExemplarsFilename)); err != nil {
+			return cleanUp(logger, bkt, id, errors.Wrap(err, "upload exemplars"))
+		}
+	} else if !os.IsNotExist(err) {
+		return cleanUp(logger, bkt, id, errors.Wrapf(err, "stat %v", filepath.Join(blockDir, ExemplarsFilename)))
+	}
+
 	// Meta.json always need to be uploaded as a last item. This will allow to assume block directories without meta file to be pending uploads.
 	if err := bkt.Upload(ctx, path.Join(id.String(), MetaFilename), strings.NewReader(metaEncoded.String())); err != nil {
 		// Don't call cleanUp here. Despite getting error, meta.json may have been uploaded in certain cases,
@@ -284,7 +293,7 @@ func GetSegmentFiles(blockDir string) []string {
 	return result
 }
 
-// GatherFileStats returns File entry for files inside TSDB block (index, chunks, meta.json).
+// GatherFileStats returns File entry for files inside TSDB block (index, chunks, exemplars, meta.json).
 func GatherFileStats(blockDir string) (res []File, _ error) {
 	files, err := os.ReadDir(filepath.Join(blockDir, ChunksDirname))
 	if err != nil {
@@ -313,6 +322,13 @@ func GatherFileStats(blockDir string) (res []File, _ error) {
 	}
 	res = append(res, mf)
 
+	exemplarsFile, err := os.Stat(filepath.Join(blockDir, ExemplarsFilename))
+	if err == nil {
+		res = append(res, File{RelPath: exemplarsFile.Name(), SizeBytes: exemplarsFile.Size()})
+	} else if !os.IsNotExist(err) {
+		return nil, errors.Wrapf(err, "stat %v", filepath.Join(blockDir, ExemplarsFilename))
+	}
+
 	metaFile, err := os.Stat(filepath.Join(blockDir, MetaFilename))
 	if err != nil {
 		return nil, errors.Wrapf(err, "stat %v", filepath.Join(blockDir, MetaFilename))
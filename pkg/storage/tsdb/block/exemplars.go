@@ -0,0 +1,193 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package block
+
+import (
+	"bufio"
+	"cmp"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/runutil"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+)
+
+const (
+	// ExemplarsFilename is the known file holding the exemplars of the block series. The file is optional.
+	ExemplarsFilename = "exemplars"
+
+	// maxExemplarsSeriesSize is the maximum size of the encoded exemplars of a single series in the exemplars file.
+	maxExemplarsSeriesSize = 16 << 20
+)
+
+// HasExemplars returns whether the block has the exemplars file, according to the files listed in its meta.
+func (m Meta) HasExemplars() bool {
+	for _, f := range m.Thanos.Files {
+		if f.RelPath == ExemplarsFilename {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteExemplarsFile writes the exemplars of the series into <dir>/exemplars. The file is a gzip stream of
+// length-delimited mimirpb.TimeSeries, with only the labels and exemplars set, sorted by series labels.
+// No file is written if there are no exemplars.
+func WriteExemplarsFile(logger log.Logger, dir string, series []exemplar.QueryResult) error {
+	series = MergeExemplars(series)
+	if len(series) == 0 {
+		return nil
+	}
+
+	// Make any changes to the file appear atomic.
+	path := filepath.Join(dir, ExemplarsFilename)
+	tmp := path + ".tmp"
+
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	if err := writeExemplars(f, series); err != nil {
+		runutil.CloseWithLogOnErr(logger, f, "close exemplars")
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return renameFile(logger, tmp, path)
+}
+
+func writeExemplars(w io.Writer, series []exemplar.QueryResult) error {
+	gw := gzip.NewWriter(w)
+
+	var buf []byte
+	for _, s := range series {
+		ts := mimirpb.TimeSeries{
+			Labels:    mimirpb.FromLabelsToLabelAdapters(s.SeriesLabels),
+			Exemplars: mimirpb.FromExemplarsToExemplarProtos(s.Exemplars),
+		}
+
+		data, err := ts.Marshal()
+		if err != nil {
+			return errors.Wrap(err, "encode exemplars")
+		}
+
+		buf = binary.AppendUvarint(buf[:0], uint64(len(data)))
+		buf = append(buf, data...)
+		if _, err := gw.Write(buf); err != nil {
+			return errors.Wrap(err, "write exemplars")
+		}
+	}
+
+	return gw.Close()
+}
+
+// ReadExemplarsFromDir reads the exemplars from <dir>/exemplars. No exemplars are returned if the block has no
+// exemplars file.
+func ReadExemplarsFromDir(dir string) ([]exemplar.QueryResult, error) {
+	f, err := os.Open(filepath.Join(dir, ExemplarsFilename))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ReadExemplars(f)
+}
+
+// ReadExemplars reads the exemplars file from the given reader.
+func ReadExemplars(rc io.ReadCloser) (_ []exemplar.QueryResult, err error) {
+	defer runutil.ExhaustCloseWithErrCapture(&err, rc, "close exemplars")
+
+	gr, err := gzip.NewReader(rc)
+	if err != nil {
+		return nil, errors.Wrap(err, "read exemplars")
+	}
+	defer runutil.CloseWithErrCapture(&err, gr, "close exemplars gzip reader")
+
+	var (
+		r      = bufio.NewReader(gr)
+		result []exemplar.QueryResult
+	)
+	for {
+		size, err := binary.ReadUvarint(r)
+		if errors.Is(err, io.EOF) {
+			return result, nil
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "read exemplars")
+		}
+		if size > maxExemplarsSeriesSize {
+			return nil, errors.Errorf("series exemplars size %d exceeds the maximum size of %d bytes", size, maxExemplarsSeriesSize)
+		}
+
+		// The decoded labels reference the buffer, so it's not reused.
+		data := make([]byte, size)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, errors.Wrap(err, "read exemplars")
+		}
+
+		var ts mimirpb.TimeSeries
+		if err := ts.Unmarshal(data); err != nil {
+			return nil, errors.Wrap(err, "decode exemplars")
+		}
+
+		result = append(result, exemplar.QueryResult{
+			SeriesLabels: mimirpb.FromLabelAdaptersToLabels(ts.Labels),
+			Exemplars:    mimirpb.FromExemplarProtosToExemplars(ts.Exemplars),
+		})
+	}
+}
+
+// MergeExemplars merges the exemplars of the same series, sorting the series by labels and their exemplars by
+// timestamp, and removing the duplicated exemplars, like the ones of the blocks of different ingesters replicas.
+func MergeExemplars(sets ...[]exemplar.QueryResult) []exemplar.QueryResult {
+	var all []exemplar.QueryResult
+	for _, s := range sets {
+		all = append(all, s...)
+	}
+	slices.SortStableFunc(all, func(a, b exemplar.QueryResult) int {
+		return labels.Compare(a.SeriesLabels, b.SeriesLabels)
+	})
+
+	result := make([]exemplar.QueryResult, 0, len(all))
+	for _, s := range all {
+		if len(s.Exemplars) == 0 {
+			continue
+		}
+		if n := len(result); n > 0 && labels.Equal(result[n-1].SeriesLabels, s.SeriesLabels) {
+			result[n-1].Exemplars = append(result[n-1].Exemplars, s.Exemplars...)
+			continue
+		}
+		result = append(result, exemplar.QueryResult{SeriesLabels: s.SeriesLabels, Exemplars: slices.Clone(s.Exemplars)})
+	}
+
+	for i := range result {
+		es := result[i].Exemplars
+		slices.SortFunc(es, func(a, b exemplar.Exemplar) int {
+			if c := cmp.Compare(a.Ts, b.Ts); c != 0 {
+				return c
+			}
+			if c := cmp.Compare(a.Value, b.Value); c != 0 {
+				return c
+			}
+			return labels.Compare(a.Labels, b.Labels)
+		})
+		result[i].Exemplars = slices.CompactFunc(es, func(a, b exemplar.Exemplar) bool {
+			return a.Ts == b.Ts && math.Float64bits(a.Value) == math.Float64bits(b.Value) && labels.Equal(a.Labels, b.Labels)
+		})
+	}
+
+	return result
+}
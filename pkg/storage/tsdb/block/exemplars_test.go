@@ -0,0 +1,130 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package block
+
+import (
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+)
+
+func TestWriteAndReadExemplarsFile(t *testing.T) {
+	dir := t.TempDir()
+
+	series := []exemplar.QueryResult{
+		{
+			SeriesLabels: labels.FromStrings("__name__", "b"),
+			Exemplars: []exemplar.Exemplar{
+				{Labels: labels.FromStrings("trace_id", "2"), Value: 2, Ts: 20},
+				{Labels: labels.FromStrings("trace_id", "1"), Value: 1, Ts: 10},
+			},
+		},
+		{
+			SeriesLabels: labels.FromStrings("__name__", "a"),
+			Exemplars: []exemplar.Exemplar{
+				{Labels: labels.FromStrings("trace_id", "3"), Value: 3, Ts: 30},
+			},
+		},
+		{
+			SeriesLabels: labels.FromStrings("__name__", "c"),
+		},
+	}
+	require.NoError(t, WriteExemplarsFile(log.NewNopLogger(), dir, series))
+
+	actual, err := ReadExemplarsFromDir(dir)
+	require.NoError(t, err)
+	require.Equal(t, []exemplar.QueryResult{
+		{
+			SeriesLabels: labels.FromStrings("__name__", "a"),
+			Exemplars: []exemplar.Exemplar{
+				{Labels: labels.FromStrings("trace_id", "3"), Value: 3, Ts: 30},
+			},
+		},
+		{
+			SeriesLabels: labels.FromStrings("__name__", "b"),
+			Exemplars: []exemplar.Exemplar{
+				{Labels: labels.FromStrings("trace_id", "1"), Value: 1, Ts: 10},
+				{Labels: labels.FromStrings("trace_id", "2"), Value: 2, Ts: 20},
+			},
+		},
+	}, actual)
+}
+
+func TestWriteExemplarsFile_ShouldNotWriteTheFileWithoutExemplars(t *testing.T) {
+	dir := t.TempDir()
+
+	require.NoError(t, WriteExemplarsFile(log.NewNopLogger(), dir, []exemplar.QueryResult{{SeriesLabels: labels.FromStrings("__name__", "a")}}))
+
+	_, err := os.Stat(filepath.Join(dir, ExemplarsFilename))
+	require.True(t, os.IsNotExist(err))
+
+	actual, err := ReadExemplarsFromDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, actual)
+}
+
+func TestMergeExemplars(t *testing.T) {
+	a := labels.FromStrings("__name__", "a")
+	b := labels.FromStrings("__name__", "b")
+	trace1 := labels.FromStrings("trace_id", "1")
+	trace2 := labels.FromStrings("trace_id", "2")
+
+	actual := MergeExemplars(
+		[]exemplar.QueryResult{
+			{SeriesLabels: b, Exemplars: []exemplar.Exemplar{{Labels: trace1, Value: 1, Ts: 10}}},
+			{SeriesLabels: a, Exemplars: []exemplar.Exemplar{{Labels: trace1, Value: 1, Ts: 10}, {Labels: trace2, Value: 2, Ts: 20}}},
+		},
+		[]exemplar.QueryResult{
+			// Same exemplars, like the ones of another ingester replica.
+			{SeriesLabels: a, Exemplars: []exemplar.Exemplar{{Labels: trace2, Value: 2, Ts: 20}, {Labels: trace1, Value: 1, Ts: 10}}},
+			// Same labels and value, but different timestamp.
+			{SeriesLabels: b, Exemplars: []exemplar.Exemplar{{Labels: trace1, Value: 1, Ts: 15}}},
+		},
+	)
+
+	require.Equal(t, []exemplar.QueryResult{
+		{SeriesLabels: a, Exemplars: []exemplar.Exemplar{{Labels: trace1, Value: 1, Ts: 10}, {Labels: trace2, Value: 2, Ts: 20}}},
+		{SeriesLabels: b, Exemplars: []exemplar.Exemplar{{Labels: trace1, Value: 1, Ts: 10}, {Labels: trace1, Value: 1, Ts: 15}}},
+	}, actual)
+}
+
+func TestUpload_ShouldUploadTheExemplarsFile(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	bkt := objstore.NewInMemBucket()
+
+	b1, err := CreateBlock(ctx, tmpDir, fiveLabels, 100, 0, 1000, labels.FromStrings("ext1", "val1"))
+	require.NoError(t, err)
+
+	bdir := path.Join(tmpDir, b1.String())
+	require.NoError(t, WriteExemplarsFile(log.NewNopLogger(), bdir, []exemplar.QueryResult{{
+		SeriesLabels: labels.FromStrings("a", "1"),
+		Exemplars:    []exemplar.Exemplar{{Labels: labels.FromStrings("trace_id", "1"), Value: 1, Ts: 100}},
+	}}))
+	require.NoError(t, Upload(ctx, log.NewNopLogger(), bkt, bdir, nil))
+
+	exists, err := bkt.Exists(ctx, path.Join(b1.String(), ExemplarsFilename))
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	meta, err := DownloadMeta(ctx, log.NewNopLogger(), bkt, b1)
+	require.NoError(t, err)
+	require.True(t, meta.HasExemplars())
+
+	rc, err := bkt.Get(ctx, path.Join(b1.String(), ExemplarsFilename))
+	require.NoError(t, err)
+	actual, err := ReadExemplars(rc)
+	require.NoError(t, err)
+	require.Equal(t, []exemplar.QueryResult{{
+		SeriesLabels: labels.FromStrings("a", "1"),
+		Exemplars:    []exemplar.Exemplar{{Labels: labels.FromStrings("trace_id", "1"), Value: 1, Ts: 100}},
+	}}, actual)
+}
@@ -0,0 +1,128 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package block
+
+import (
+	"cmp"
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/grafana/dskit/runutil"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/tsdb/index"
+)
+
+// IndexStats holds statistics about the series in the block index, used to skip the blocks that can't
+// contain the series matching a query without looking up their index.
+type IndexStats struct {
+	// MetricNames are the metric names with the highest number of series, sorted by number of series
+	// in descending order. The list holds all the metric names of the block if its length is NumMetricNames.
+	MetricNames []MetricNameStats `json:"metric_names,omitempty"`
+
+	// NumMetricNames is the number of distinct metric names in the block.
+	NumMetricNames int `json:"num_metric_names"`
+
+	// LabelNames are the label names of the block series, sorted. The list is empty if the block has
+	// more label names than the maximum number of names the statistics have been gathered with.
+	LabelNames []string `json:"label_names,omitempty"`
+
+	// NumLabelNames is the number of distinct label names in the block.
+	NumLabelNames int `json:"num_label_names"`
+}
+
+// MetricNameStats is the number of series of a metric name.
+type MetricNameStats struct {
+	Name      string `json:"name"`
+	NumSeries int    `json:"num_series"`
+}
+
+// GatherIndexStats reads the index of the block in blockDir and returns its statistics, keeping
+// at most maxNames metric names and label names.
+func GatherIndexStats(ctx context.Context, blockDir string, maxNames int) (_ *IndexStats, err error) {
+	r, err := index.NewFileReader(filepath.Join(blockDir, IndexFilename))
+	if err != nil {
+		return nil, errors.Wrap(err, "open index file")
+	}
+	defer runutil.CloseWithErrCapture(&err, r, "gather index stats file reader")
+
+	labelNames, err := r.LabelNames(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "read label names")
+	}
+
+	// The names are cloned because they reference the index file, which is unmapped once closed.
+	stats := &IndexStats{NumLabelNames: len(labelNames)}
+	if len(labelNames) <= maxNames {
+		stats.LabelNames = make([]string, 0, len(labelNames))
+		for _, name := range labelNames {
+			stats.LabelNames = append(stats.LabelNames, strings.Clone(name))
+		}
+	}
+
+	metricNames, err := r.SortedLabelValues(ctx, labels.MetricName)
+	if err != nil {
+		return nil, errors.Wrap(err, "read metric names")
+	}
+
+	stats.NumMetricNames = len(metricNames)
+	stats.MetricNames = make([]MetricNameStats, 0, len(metricNames))
+	for _, name := range metricNames {
+		p, err := r.Postings(ctx, labels.MetricName, name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "read postings of metric %s", name)
+		}
+
+		numSeries := 0
+		for p.Next() {
+			numSeries++
+		}
+		if err := p.Err(); err != nil {
+			return nil, errors.Wrapf(err, "iterate postings of metric %s", name)
+		}
+
+		stats.MetricNames = append(stats.MetricNames, MetricNameStats{Name: strings.Clone(name), NumSeries: numSeries})
+	}
+
+	// The sort is stable to keep the metric names with the same number of series sorted by name.
+	slices.SortStableFunc(stats.MetricNames, func(a, b MetricNameStats) int {
+		return cmp.Compare(b.NumSeries, a.NumSeries)
+	})
+	if len(stats.MetricNames) > maxNames {
+		stats.MetricNames = slices.Clone(stats.MetricNames[:maxNames])
+	}
+
+	return stats, nil
+}
+
+// MayContain returns whether the block may contain series matching all the input matchers.
+// It returns true when the statistics are not enough to tell.
+func (s *IndexStats) MayContain(matchers []*labels.Matcher) bool {
+	if s == nil {
+		return true
+	}
+
+	for _, m := range matchers {
+		// The matcher selects the series without the label too.
+		if m.Matches("") {
+			continue
+		}
+
+		if m.Name == labels.MetricName && len(s.MetricNames) == s.NumMetricNames {
+			if !slices.ContainsFunc(s.MetricNames, func(n MetricNameStats) bool { return m.Matches(n.Name) }) {
+				return false
+			}
+			continue
+		}
+
+		if len(s.LabelNames) == s.NumLabelNames {
+			if _, found := slices.BinarySearch(s.LabelNames, m.Name); !found {
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,125 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package block
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGatherIndexStats(t *testing.T) {
+	dir := t.TempDir()
+
+	id, err := CreateBlock(context.Background(), dir, []labels.Labels{
+		labels.FromStrings(labels.MetricName, "b", "pod", "1"),
+		labels.FromStrings(labels.MetricName, "b", "pod", "2"),
+		labels.FromStrings(labels.MetricName, "c", "pod", "1"),
+		labels.FromStrings(labels.MetricName, "a", "pod", "1", "job", "api"),
+		labels.FromStrings(labels.MetricName, "a", "pod", "2", "job", "api"),
+		labels.FromStrings(labels.MetricName, "a", "pod", "3", "job", "api"),
+	}, 10, 0, 1000, labels.EmptyLabels())
+	require.NoError(t, err)
+
+	t.Run("all names", func(t *testing.T) {
+		stats, err := GatherIndexStats(context.Background(), filepath.Join(dir, id.String()), 10)
+		require.NoError(t, err)
+		assert.Equal(t, &IndexStats{
+			MetricNames:    []MetricNameStats{{Name: "a", NumSeries: 3}, {Name: "b", NumSeries: 2}, {Name: "c", NumSeries: 1}},
+			NumMetricNames: 3,
+			LabelNames:     []string{labels.MetricName, "job", "pod"},
+			NumLabelNames:  3,
+		}, stats)
+	})
+
+	t.Run("top names", func(t *testing.T) {
+		stats, err := GatherIndexStats(context.Background(), filepath.Join(dir, id.String()), 2)
+		require.NoError(t, err)
+		assert.Equal(t, &IndexStats{
+			MetricNames:    []MetricNameStats{{Name: "a", NumSeries: 3}, {Name: "b", NumSeries: 2}},
+			NumMetricNames: 3,
+			NumLabelNames:  3,
+		}, stats)
+	})
+}
+
+func TestIndexStats_MayContain(t *testing.T) {
+	complete := &IndexStats{
+		MetricNames:    []MetricNameStats{{Name: "up", NumSeries: 2}, {Name: "requests_total", NumSeries: 1}},
+		NumMetricNames: 2,
+		LabelNames:     []string{labels.MetricName, "job", "pod"},
+		NumLabelNames:  3,
+	}
+	partial := &IndexStats{
+		MetricNames:    []MetricNameStats{{Name: "up", NumSeries: 2}},
+		NumMetricNames: 2,
+		NumLabelNames:  3,
+	}
+
+	tests := map[string]struct {
+		stats    *IndexStats
+		matchers []*labels.Matcher
+		expected bool
+	}{
+		"no stats": {
+			matchers: []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, labels.MetricName, "missing")},
+			expected: true,
+		},
+		"metric name in the block": {
+			stats:    complete,
+			matchers: []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, labels.MetricName, "up")},
+			expected: true,
+		},
+		"metric name not in the block": {
+			stats:    complete,
+			matchers: []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, labels.MetricName, "missing")},
+			expected: false,
+		},
+		"metric name regexp matching a name in the block": {
+			stats:    complete,
+			matchers: []*labels.Matcher{labels.MustNewMatcher(labels.MatchRegexp, labels.MetricName, "requests_.*")},
+			expected: true,
+		},
+		"metric name regexp not matching any name in the block": {
+			stats:    complete,
+			matchers: []*labels.Matcher{labels.MustNewMatcher(labels.MatchRegexp, labels.MetricName, "errors_.*")},
+			expected: false,
+		},
+		"label name not in the block": {
+			stats: complete,
+			matchers: []*labels.Matcher{
+				labels.MustNewMatcher(labels.MatchEqual, labels.MetricName, "up"),
+				labels.MustNewMatcher(labels.MatchEqual, "namespace", "prod"),
+			},
+			expected: false,
+		},
+		"matcher matching the series without the label": {
+			stats: complete,
+			matchers: []*labels.Matcher{
+				labels.MustNewMatcher(labels.MatchEqual, labels.MetricName, "up"),
+				labels.MustNewMatcher(labels.MatchNotEqual, "namespace", "prod"),
+			},
+			expected: true,
+		},
+		"metric name not in the partial list": {
+			stats:    partial,
+			matchers: []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, labels.MetricName, "missing")},
+			expected: true,
+		},
+		"label name with the label names not tracked": {
+			stats:    partial,
+			matchers: []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "namespace", "prod")},
+			expected: true,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, testData.expected, testData.stats.MayContain(testData.matchers))
+		})
+	}
+}
@@ -73,6 +73,13 @@ type ThanosMeta struct {
 	// Useful to avoid API call to get size of each file, as well as for debugging purposes.
 	// Optional, added in v0.17.0.
 	Files []File `json:"files,omitempty"`
+
+	// SeriesDeletionRequests is the list of IDs of the series deletion requests which
+	// have been applied to this block by the compactor. Optional.
+	SeriesDeletionRequests []string `json:"series_deletion_requests,omitempty"`
+
+	// IndexStats are the statistics about the series in the block index, gathered by the compactor. Optional.
+	IndexStats *IndexStats `json:"index_stats,omitempty"`
 }
 
 type Matchers []*labels.Matcher
@@ -24,6 +24,7 @@ const (
 	IndexCompressedFilename = IndexFilename + ".gz"
 	IndexVersion1           = 1
 	IndexVersion2           = 2 // Added CompactorShardID field.
+	IndexVersion3           = 3 // Added NumSeries and IndexStats fields.
 	SegmentsFormatUnknown   = ""
 
 	// SegmentsFormat1Based6Digits defined segments numbered with 6 digits numbers in a sequence starting from number 1
@@ -42,6 +43,9 @@ type Index struct {
 	// List of block deletion marks.
 	BlockDeletionMarks BlockDeletionMarks `json:"block_deletion_marks"`
 
+	// List of series deletion requests, sorted by creation time.
+	SeriesDeletionRequests []*mimir_tsdb.SeriesDeletionRequest `json:"series_deletion_requests,omitempty"`
+
 	// UpdatedAt is a unix timestamp (seconds precision) of when the index has been updated
 	// (written in the storage) the last time.
 	UpdatedAt int64 `json:"updated_at"`
@@ -90,6 +94,17 @@ type Block struct {
 
 	// Block's compactor shard ID, copied from tsdb.CompactorShardIDExternalLabel label.
 	CompactorShardID string `json:"compactor_shard_id,omitempty"`
+
+	// Resolution of the block in milliseconds, copied from the block's meta.json. 0 for the raw blocks,
+	// which haven't been downsampled.
+	Resolution int64 `json:"resolution,omitempty"`
+
+	// NumSeries is the number of series in the block, copied from the block's meta.json.
+	NumSeries uint64 `json:"num_series,omitempty"`
+
+	// IndexStats are the statistics about the series in the block index, copied from the block's meta.json.
+	// Nil if the block has not been compacted with the statistics enabled.
+	IndexStats *block.IndexStats `json:"index_stats,omitempty"`
 }
 
 // Within returns whether the block contains samples within the provided range.
@@ -113,10 +128,13 @@ func (m *Block) ThanosMeta() *block.Meta {
 			MinTime: m.MinTime,
 			MaxTime: m.MaxTime,
 			Version: block.TSDBVersion1,
+			Stats:   tsdb.BlockStats{NumSeries: m.NumSeries},
 		},
 		Thanos: block.ThanosMeta{
 			Version:      block.ThanosVersion1,
 			SegmentFiles: m.thanosMetaSegmentFiles(),
+			Downsample:   block.ThanosDownsample{Resolution: m.Resolution},
+			IndexStats:   m.IndexStats,
 		},
 	}
 }
@@ -153,6 +171,9 @@ func BlockFromThanosMeta(meta block.Meta) *Block {
 		SegmentsFormat:   segmentsFormat,
 		SegmentsNum:      segmentsNum,
 		CompactorShardID: meta.Thanos.Labels[mimir_tsdb.CompactorShardIDExternalLabel],
+		Resolution:       meta.Thanos.Downsample.Resolution,
+		NumSeries:        meta.Stats.NumSeries,
+		IndexStats:       meta.Thanos.IndexStats,
 	}
 }
 
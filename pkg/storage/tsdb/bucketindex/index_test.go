@@ -272,6 +272,54 @@ func TestBlockFromThanosMeta(t *testing.T) {
 				CompactorShardID: "some weird value",
 			},
 		},
+		"meta.json of a downsampled block": {
+			meta: block.Meta{
+				BlockMeta: tsdb.BlockMeta{
+					ULID:    blockID,
+					MinTime: 10,
+					MaxTime: 20,
+				},
+				Thanos: block.ThanosMeta{
+					Downsample: block.ThanosDownsample{Resolution: mimir_tsdb.Resolution5m},
+				},
+			},
+			expected: Block{
+				ID:         blockID,
+				MinTime:    10,
+				MaxTime:    20,
+				Resolution: mimir_tsdb.Resolution5m,
+			},
+		},
+		"meta.json with index stats": {
+			meta: block.Meta{
+				BlockMeta: tsdb.BlockMeta{
+					ULID:    blockID,
+					MinTime: 10,
+					MaxTime: 20,
+					Stats:   tsdb.BlockStats{NumSeries: 3},
+				},
+				Thanos: block.ThanosMeta{
+					IndexStats: &block.IndexStats{
+						MetricNames:    []block.MetricNameStats{{Name: "up", NumSeries: 3}},
+						NumMetricNames: 1,
+						LabelNames:     []string{"__name__", "job"},
+						NumLabelNames:  2,
+					},
+				},
+			},
+			expected: Block{
+				ID:        blockID,
+				MinTime:   10,
+				MaxTime:   20,
+				NumSeries: 3,
+				IndexStats: &block.IndexStats{
+					MetricNames:    []block.MetricNameStats{{Name: "up", NumSeries: 3}},
+					NumMetricNames: 1,
+					LabelNames:     []string{"__name__", "job"},
+					NumLabelNames:  2,
+				},
+			},
+		},
 	}
 
 	for testName, testData := range tests {
@@ -378,6 +426,48 @@ func TestBlock_ThanosMeta(t *testing.T) {
 				},
 			},
 		},
+		"downsampled block": {
+			block: Block{
+				ID:         blockID,
+				MinTime:    10,
+				MaxTime:    20,
+				Resolution: mimir_tsdb.Resolution1h,
+			},
+			expected: &block.Meta{
+				BlockMeta: tsdb.BlockMeta{
+					ULID:    blockID,
+					MinTime: 10,
+					MaxTime: 20,
+					Version: block.TSDBVersion1,
+				},
+				Thanos: block.ThanosMeta{
+					Version:    block.ThanosVersion1,
+					Downsample: block.ThanosDownsample{Resolution: mimir_tsdb.Resolution1h},
+				},
+			},
+		},
+		"block with index stats": {
+			block: Block{
+				ID:         blockID,
+				MinTime:    10,
+				MaxTime:    20,
+				NumSeries:  3,
+				IndexStats: &block.IndexStats{MetricNames: []block.MetricNameStats{{Name: "up", NumSeries: 3}}, NumMetricNames: 1},
+			},
+			expected: &block.Meta{
+				BlockMeta: tsdb.BlockMeta{
+					ULID:    blockID,
+					MinTime: 10,
+					MaxTime: 20,
+					Version: block.TSDBVersion1,
+					Stats:   tsdb.BlockStats{NumSeries: 3},
+				},
+				Thanos: block.ThanosMeta{
+					Version:    block.ThanosVersion1,
+					IndexStats: &block.IndexStats{MetricNames: []block.MetricNameStats{{Name: "up", NumSeries: 3}}, NumMetricNames: 1},
+				},
+			},
+		},
 	}
 
 	for testName, testData := range tests {
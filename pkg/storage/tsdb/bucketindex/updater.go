@@ -20,6 +20,7 @@ import (
 	"github.com/thanos-io/objstore"
 
 	"github.com/grafana/mimir/pkg/storage/bucket"
+	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
 	"github.com/grafana/mimir/pkg/storage/tsdb/block"
 )
 
@@ -50,7 +51,7 @@ func (w *Updater) UpdateIndex(ctx context.Context, old *Index) (*Index, map[ulid
 	var oldBlockDeletionMarks []*BlockDeletionMark
 
 	// Use the old index if provided, and it is using the latest version format.
-	if old != nil && old.Version == IndexVersion2 {
+	if old != nil && old.Version == IndexVersion3 {
 		oldBlocks = old.Blocks
 		oldBlockDeletionMarks = old.BlockDeletionMarks
 	}
@@ -65,11 +66,19 @@ func (w *Updater) UpdateIndex(ctx context.Context, old *Index) (*Index, map[ulid
 		return nil, nil, err
 	}
 
+	// Series deletion requests are mutable (the compactor updates them once processed)
+	// and there are usually just a few of them, so we always read them all.
+	seriesDeletionRequests, err := mimir_tsdb.ListSeriesDeletionRequests(ctx, w.bkt)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	return &Index{
-		Version:            IndexVersion2,
-		Blocks:             blocks,
-		BlockDeletionMarks: blockDeletionMarks,
-		UpdatedAt:          time.Now().Unix(),
+		Version:                IndexVersion3,
+		Blocks:                 blocks,
+		BlockDeletionMarks:     blockDeletionMarks,
+		SeriesDeletionRequests: seriesDeletionRequests,
+		UpdatedAt:              time.Now().Unix(),
 	}, partials, nil
 }
 
@@ -167,7 +167,7 @@ func TestUpdater_UpdateIndex_NoTenantInTheBucket(t *testing.T) {
 		idx, partials, err := w.UpdateIndex(ctx, oldIdx)
 
 		require.NoError(t, err)
-		assert.Equal(t, IndexVersion2, idx.Version)
+		assert.Equal(t, IndexVersion3, idx.Version)
 		assert.InDelta(t, time.Now().Unix(), idx.UpdatedAt, 2)
 		assert.Len(t, idx.Blocks, 0)
 		assert.Len(t, idx.BlockDeletionMarks, 0)
@@ -175,6 +175,41 @@ func TestUpdater_UpdateIndex_NoTenantInTheBucket(t *testing.T) {
 	}
 }
 
+func TestUpdater_UpdateIndex_ShouldIncludeSeriesDeletionRequests(t *testing.T) {
+	const userID = "user-1"
+
+	bkt, _ := testutil.PrepareFilesystemBucket(t)
+
+	ctx := context.Background()
+	logger := log.NewNopLogger()
+	userBkt := bucket.NewUserBucketClient(userID, bkt, nil)
+
+	block1 := block.MockStorageBlockWithExtLabels(t, bkt, userID, 10, 20, nil)
+	req := mimir_tsdb.NewSeriesDeletionRequest([]string{`{__name__="foo"}`}, 10, 15, time.Now())
+	require.NoError(t, mimir_tsdb.WriteSeriesDeletionRequest(ctx, userBkt, req))
+
+	w := NewUpdater(bkt, userID, nil, logger)
+	returnedIdx, _, err := w.UpdateIndex(ctx, nil)
+	require.NoError(t, err)
+	assertBucketIndexEqual(t, returnedIdx, bkt, userID, []block.Meta{block1}, []*block.DeletionMark{})
+	assert.Equal(t, []*mimir_tsdb.SeriesDeletionRequest{req}, returnedIdx.SeriesDeletionRequests)
+
+	// Requests are mutable, so changes must be picked up even if the old index is passed.
+	req.ProcessedAt = time.Now().Unix()
+	require.NoError(t, mimir_tsdb.WriteSeriesDeletionRequest(ctx, userBkt, req))
+
+	returnedIdx, _, err = w.UpdateIndex(ctx, returnedIdx)
+	require.NoError(t, err)
+	assert.Equal(t, []*mimir_tsdb.SeriesDeletionRequest{req}, returnedIdx.SeriesDeletionRequests)
+
+	// Cancelled requests are removed from the index.
+	require.NoError(t, mimir_tsdb.DeleteSeriesDeletionRequest(ctx, userBkt, req.RequestID))
+
+	returnedIdx, _, err = w.UpdateIndex(ctx, returnedIdx)
+	require.NoError(t, err)
+	assert.Empty(t, returnedIdx.SeriesDeletionRequests)
+}
+
 func TestUpdater_UpdateIndexFromVersion1ToVersion2(t *testing.T) {
 	const userID = "user-1"
 
@@ -238,7 +273,7 @@ func getBlockUploadedAt(t testing.TB, bkt objstore.Bucket, userID string, blockI
 }
 
 func assertBucketIndexEqual(t testing.TB, idx *Index, bkt objstore.Bucket, userID string, expectedBlocks []block.Meta, expectedDeletionMarks []*block.DeletionMark) {
-	assert.Equal(t, IndexVersion2, idx.Version)
+	assert.Equal(t, IndexVersion3, idx.Version)
 	assert.InDelta(t, time.Now().Unix(), idx.UpdatedAt, 2)
 
 	// Build the list of expected block index entries.
@@ -107,6 +107,9 @@ const (
 	maxBucketSizeBytesFlag      = "blocks-storage.bucket-store.chunk-pool-max-bucket-size-bytes"
 	seriesSelectionStrategyFlag = "blocks-storage.bucket-store.series-selection-strategy"
 	bucketIndexFlagPrefix       = "blocks-storage.bucket-store.bucket-index."
+
+	CardinalityMaxLabelValuesPerLabelFlag = "blocks-storage.bucket-store.cardinality-max-label-values-per-label"
+	CardinalityMaxPostingsBytesFlag       = "blocks-storage.bucket-store.cardinality-max-postings-bytes"
 )
 
 // Validation errors
@@ -196,6 +199,7 @@ type TSDBConfig struct {
 	Retention                 time.Duration `yaml:"retention_period"`
 	ShipInterval              time.Duration `yaml:"ship_interval" category:"advanced"`
 	ShipConcurrency           int           `yaml:"ship_concurrency" category:"advanced"`
+	ShipExemplarsEnabled      bool          `yaml:"ship_exemplars_enabled" category:"experimental"`
 	HeadCompactionInterval    time.Duration `yaml:"head_compaction_interval" category:"advanced"`
 	HeadCompactionConcurrency int           `yaml:"head_compaction_concurrency" category:"advanced"`
 	HeadCompactionIdleTimeout time.Duration `yaml:"head_compaction_idle_timeout" category:"advanced"`
@@ -274,6 +278,7 @@ func (cfg *TSDBConfig) RegisterFlags(f *flag.FlagSet) {
 	f.DurationVar(&cfg.Retention, "blocks-storage.tsdb.retention-period", 13*time.Hour, "TSDB blocks retention in the ingester before a block is removed. If shipping is enabled, the retention will be relative to the time when the block was uploaded to storage. If shipping is disabled then its relative to the creation time of the block. This should be larger than the -blocks-storage.tsdb.block-ranges-period, -querier.query-store-after and large enough to give store-gateways and queriers enough time to discover newly uploaded blocks.")
 	f.DurationVar(&cfg.ShipInterval, "blocks-storage.tsdb.ship-interval", 1*time.Minute, "How frequently the TSDB blocks are scanned and new ones are shipped to the storage. 0 means shipping is disabled.")
 	f.IntVar(&cfg.ShipConcurrency, "blocks-storage.tsdb.ship-concurrency", 10, "Maximum number of tenants concurrently shipping blocks to the storage.")
+	f.BoolVar(&cfg.ShipExemplarsEnabled, "blocks-storage.tsdb.ship-exemplars-enabled", false, "True to store, in the blocks shipped to the storage, the exemplars of the blocks time range held in memory by the ingester. The compactor keeps the exemplars when compacting the blocks, and the queriers can query them from the store-gateways.")
 
 	// This cache is only used when querying compacted blocks. The default cache size is enough to store the hashes for
 	// all series in all queryable blocks, assuming 2M series per ingester (and default retention):
@@ -418,6 +423,10 @@ type BucketStoreConfig struct {
 	SelectionStrategies         struct {
 		WorstCaseSeriesPreference float64 `yaml:"worst_case_series_preference" category:"experimental"`
 	} `yaml:"series_selection_strategies"`
+
+	// Controls the postings read by the blocks cardinality analysis.
+	CardinalityMaxLabelValuesPerLabel int    `yaml:"cardinality_max_label_values_per_label" category:"experimental"`
+	CardinalityMaxPostingsBytes       uint64 `yaml:"cardinality_max_postings_bytes" category:"experimental"`
 }
 
 const (
@@ -461,6 +470,8 @@ func (cfg *BucketStoreConfig) RegisterFlags(f *flag.FlagSet) {
 	f.Uint64Var(&cfg.PartitionerMaxGapBytes, "blocks-storage.bucket-store.partitioner-max-gap-bytes", DefaultPartitionerMaxGapSize, "Max size - in bytes - of a gap for which the partitioner aggregates together two bucket GET object requests.")
 	f.IntVar(&cfg.StreamingBatchSize, "blocks-storage.bucket-store.batch-series-size", 5000, "This option controls how many series to fetch per batch. The batch size must be greater than 0.")
 	f.StringVar(&cfg.SeriesSelectionStrategyName, seriesSelectionStrategyFlag, WorstCasePostingsStrategy, "This option controls the strategy to selection of series and deferring application of matchers. A more aggressive strategy will fetch less posting lists at the cost of more series. This is useful when querying large blocks in which many series share the same label name and value. Supported values (most aggressive to least aggressive): "+strings.Join(validSeriesSelectionStrategies, ", ")+".")
+	f.IntVar(&cfg.CardinalityMaxLabelValuesPerLabel, CardinalityMaxLabelValuesPerLabelFlag, 100000, "Maximum number of values of a label of a block whose postings are read to analyse the cardinality of the block. The cardinality analysis of a block fails if any of its labels has more values. 0 to disable the limit.")
+	f.Uint64Var(&cfg.CardinalityMaxPostingsBytes, CardinalityMaxPostingsBytesFlag, uint64(1*units.Gibibyte), "Maximum size - in bytes - of the postings of a block read to analyse the cardinality of the block. The cardinality analysis of a block fails if its postings are bigger. 0 to disable the limit.")
 	f.Float64Var(&cfg.SelectionStrategies.WorstCaseSeriesPreference, "blocks-storage.bucket-store.series-selection-strategies.worst-case-series-preference", 0.75, "This option is only used when "+seriesSelectionStrategyFlag+"="+WorstCasePostingsStrategy+". Increasing the series preference results in fetching more series than postings. Must be a positive floating point number.")
 }
 
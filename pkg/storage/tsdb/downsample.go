@@ -0,0 +1,31 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package tsdb
+
+import (
+	"time"
+)
+
+const (
+	// ResolutionRaw is the resolution of the blocks which haven't been downsampled.
+	ResolutionRaw = int64(0)
+
+	// Resolution5m is the resolution, in milliseconds, of the blocks downsampled to 5 minutes.
+	Resolution5m = int64(5 * time.Minute / time.Millisecond)
+
+	// Resolution1h is the resolution, in milliseconds, of the blocks downsampled to 1 hour.
+	Resolution1h = int64(time.Hour / time.Millisecond)
+
+	// DownsampleAggregateLabel is the label added to the series of the downsampled blocks holding an
+	// aggregate of the samples of each downsampling window. The series without this label hold the
+	// average of the samples of each window, and have the same labels as the raw series.
+	DownsampleAggregateLabel = "__aggregate__"
+
+	DownsampleAggregateMin   = "min"
+	DownsampleAggregateMax   = "max"
+	DownsampleAggregateSum   = "sum"
+	DownsampleAggregateCount = "count"
+)
+
+// DownsampleResolutions is the list of the supported resolutions, from the finest to the coarsest.
+var DownsampleResolutions = []int64{ResolutionRaw, Resolution5m, Resolution1h}
@@ -0,0 +1,231 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package tsdb
+
+import (
+	"bytes"
+	"context"
+	crypto_rand "crypto/rand"
+	"encoding/json"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/oklog/ulid"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/thanos-io/objstore"
+
+	util_log "github.com/grafana/mimir/pkg/util/log"
+)
+
+// SeriesDeletionRequestsPath is the location of series deletion requests, relative to user-specific prefix.
+const SeriesDeletionRequestsPath = "markers/series-deletion-requests"
+
+var ErrInvalidSeriesDeletionRequestID = errors.New("invalid series deletion request ID")
+
+// SeriesDeletionRequestState is the state of a series deletion request, computed from
+// its timestamps and the configured deletion delay.
+type SeriesDeletionRequestState string
+
+const (
+	// SeriesDeletionRequestPending means the request is within the deletion delay and can still be cancelled.
+	// Matching samples are already filtered out at query time.
+	SeriesDeletionRequestPending SeriesDeletionRequestState = "pending"
+
+	// SeriesDeletionRequestProcessing means the deletion delay has elapsed and the compactor is rewriting blocks.
+	SeriesDeletionRequestProcessing SeriesDeletionRequestState = "processing"
+
+	// SeriesDeletionRequestProcessed means all blocks in the storage have been rewritten without the matching samples.
+	// The compactor keeps applying the request to the blocks overlapping its time range uploaded later on.
+	SeriesDeletionRequestProcessed SeriesDeletionRequestState = "processed"
+)
+
+// SeriesDeletionRequest is a request to delete all samples of the series matching
+// any of the selectors within the time range.
+type SeriesDeletionRequest struct {
+	RequestID string `json:"request_id"`
+
+	// Selectors are PromQL series selectors. A series is deleted if it matches any of them.
+	Selectors []string `json:"selectors"`
+
+	// StartTime and EndTime specify the time range of samples to delete (millis precision, both inclusive).
+	StartTime int64 `json:"start_time"`
+	EndTime   int64 `json:"end_time"`
+
+	// CreatedAt is a unix timestamp (seconds precision) of when the request has been created.
+	CreatedAt int64 `json:"created_at"`
+
+	// ProcessedAt is a unix timestamp (seconds precision) of when the compactor found no more blocks
+	// to rewrite for this request, or 0 if the request hasn't been processed yet.
+	ProcessedAt int64 `json:"processed_at,omitempty"`
+
+	// UnaffectedBlocks is the list of IDs of the blocks overlapping the request time range which the
+	// compactor found not to contain any matching sample, so that they're not checked again.
+	UnaffectedBlocks []string `json:"unaffected_blocks,omitempty"`
+}
+
+// NewSeriesDeletionRequest returns a new series deletion request with a newly generated ID.
+func NewSeriesDeletionRequest(selectors []string, startTime, endTime int64, createdAt time.Time) *SeriesDeletionRequest {
+	return &SeriesDeletionRequest{
+		RequestID: ulid.MustNew(ulid.Timestamp(createdAt), crypto_rand.Reader).String(),
+		Selectors: selectors,
+		StartTime: startTime,
+		EndTime:   endTime,
+		CreatedAt: createdAt.Unix(),
+	}
+}
+
+func (r *SeriesDeletionRequest) GetCreatedAt() time.Time {
+	return time.Unix(r.CreatedAt, 0)
+}
+
+// Validate checks that the request has at least one valid selector and a valid time range.
+func (r *SeriesDeletionRequest) Validate() error {
+	if len(r.Selectors) == 0 {
+		return errors.New("at least one series selector must be provided")
+	}
+	if r.EndTime < r.StartTime {
+		return errors.New("end time must be greater than or equal to start time")
+	}
+	_, err := r.Matchers()
+	return err
+}
+
+// Matchers parses the request selectors. The returned slice has one set of matchers per selector.
+func (r *SeriesDeletionRequest) Matchers() ([][]*labels.Matcher, error) {
+	out := make([][]*labels.Matcher, 0, len(r.Selectors))
+	for _, s := range r.Selectors {
+		ms, err := parser.ParseMetricSelector(s)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse series selector %q", s)
+		}
+		out = append(out, ms)
+	}
+	return out, nil
+}
+
+// Overlaps returns whether the request time range overlaps the provided range.
+// Input minT and maxT are both inclusive.
+func (r *SeriesDeletionRequest) Overlaps(minT, maxT int64) bool {
+	return r.StartTime <= maxT && minT <= r.EndTime
+}
+
+// State returns the state of the request at the given time.
+func (r *SeriesDeletionRequest) State(now time.Time, deletionDelay time.Duration) SeriesDeletionRequestState {
+	if r.ProcessedAt > 0 {
+		return SeriesDeletionRequestProcessed
+	}
+	if now.Sub(r.GetCreatedAt()) < deletionDelay {
+		return SeriesDeletionRequestPending
+	}
+	return SeriesDeletionRequestProcessing
+}
+
+func seriesDeletionRequestPath(requestID string) (string, error) {
+	if _, err := ulid.ParseStrict(requestID); err != nil {
+		return "", ErrInvalidSeriesDeletionRequestID
+	}
+	return path.Join(SeriesDeletionRequestsPath, requestID+".json"), nil
+}
+
+// WriteSeriesDeletionRequest uploads the series deletion request to the tenant location in the bucket.
+// The input bucket is expected to be scoped to the tenant.
+func WriteSeriesDeletionRequest(ctx context.Context, userBkt objstore.Bucket, req *SeriesDeletionRequest) error {
+	p, err := seriesDeletionRequestPath(req.RequestID)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return errors.Wrap(err, "serialize series deletion request")
+	}
+
+	return errors.Wrap(userBkt.Upload(ctx, p, bytes.NewReader(data)), "upload series deletion request")
+}
+
+// ReadSeriesDeletionRequest returns the series deletion request with the given ID. If it doesn't exist,
+// returns nil request and no error. The input bucket is expected to be scoped to the tenant.
+func ReadSeriesDeletionRequest(ctx context.Context, userBkt objstore.BucketReader, requestID string) (*SeriesDeletionRequest, error) {
+	p, err := seriesDeletionRequestPath(requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := userBkt.Get(ctx, p)
+	if err != nil {
+		if userBkt.IsObjNotFoundErr(err) {
+			return nil, nil
+		}
+
+		return nil, errors.Wrapf(err, "failed to read series deletion request object: %s", p)
+	}
+
+	req := &SeriesDeletionRequest{}
+	err = json.NewDecoder(r).Decode(req)
+
+	// Close reader before dealing with decode error.
+	if closeErr := r.Close(); closeErr != nil {
+		level.Warn(util_log.Logger).Log("msg", "failed to close bucket reader", "err", closeErr)
+	}
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode series deletion request object: %s", p)
+	}
+
+	return req, nil
+}
+
+// DeleteSeriesDeletionRequest removes the series deletion request with the given ID from the bucket.
+// The input bucket is expected to be scoped to the tenant.
+func DeleteSeriesDeletionRequest(ctx context.Context, userBkt objstore.Bucket, requestID string) error {
+	p, err := seriesDeletionRequestPath(requestID)
+	if err != nil {
+		return err
+	}
+
+	return errors.Wrap(userBkt.Delete(ctx, p), "delete series deletion request")
+}
+
+// ListSeriesDeletionRequests returns all series deletion requests of a tenant, sorted by creation time.
+// The input bucket is expected to be scoped to the tenant.
+func ListSeriesDeletionRequests(ctx context.Context, userBkt objstore.BucketReader) ([]*SeriesDeletionRequest, error) {
+	var ids []string
+
+	err := userBkt.Iter(ctx, SeriesDeletionRequestsPath+"/", func(name string) error {
+		id := strings.TrimSuffix(path.Base(name), ".json")
+		if _, err := ulid.ParseStrict(id); err == nil {
+			ids = append(ids, id)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list series deletion requests")
+	}
+
+	var out []*SeriesDeletionRequest
+	for _, id := range ids {
+		req, err := ReadSeriesDeletionRequest(ctx, userBkt, id)
+		if err != nil {
+			return nil, err
+		}
+
+		// The request may have been cancelled between the listing and now.
+		if req != nil {
+			out = append(out, req)
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].CreatedAt != out[j].CreatedAt {
+			return out[i].CreatedAt < out[j].CreatedAt
+		}
+		return out[i].RequestID < out[j].RequestID
+	})
+
+	return out, nil
+}
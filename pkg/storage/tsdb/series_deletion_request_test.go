@@ -0,0 +1,107 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package tsdb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+)
+
+func TestSeriesDeletionRequest_WriteReadListDelete(t *testing.T) {
+	ctx := context.Background()
+	bkt := objstore.NewInMemBucket()
+	now := time.Now()
+
+	first := NewSeriesDeletionRequest([]string{`{__name__="foo"}`}, 10, 20, now.Add(-time.Hour))
+	second := NewSeriesDeletionRequest([]string{`{job="bar"}`, `up`}, 30, 40, now)
+
+	// Write them in reverse order, to check the listing is sorted by creation time.
+	require.NoError(t, WriteSeriesDeletionRequest(ctx, bkt, second))
+	require.NoError(t, WriteSeriesDeletionRequest(ctx, bkt, first))
+
+	actual, err := ReadSeriesDeletionRequest(ctx, bkt, first.RequestID)
+	require.NoError(t, err)
+	assert.Equal(t, first, actual)
+
+	list, err := ListSeriesDeletionRequests(ctx, bkt)
+	require.NoError(t, err)
+	assert.Equal(t, []*SeriesDeletionRequest{first, second}, list)
+
+	require.NoError(t, DeleteSeriesDeletionRequest(ctx, bkt, first.RequestID))
+
+	actual, err = ReadSeriesDeletionRequest(ctx, bkt, first.RequestID)
+	require.NoError(t, err)
+	assert.Nil(t, actual)
+
+	list, err = ListSeriesDeletionRequests(ctx, bkt)
+	require.NoError(t, err)
+	assert.Equal(t, []*SeriesDeletionRequest{second}, list)
+}
+
+func TestSeriesDeletionRequest_InvalidID(t *testing.T) {
+	bkt := objstore.NewInMemBucket()
+
+	_, err := ReadSeriesDeletionRequest(context.Background(), bkt, "../../tenant-deletion-mark")
+	assert.ErrorIs(t, err, ErrInvalidSeriesDeletionRequestID)
+
+	err = DeleteSeriesDeletionRequest(context.Background(), bkt, "foo")
+	assert.ErrorIs(t, err, ErrInvalidSeriesDeletionRequestID)
+}
+
+func TestSeriesDeletionRequest_Validate(t *testing.T) {
+	for name, tc := range map[string]struct {
+		req         SeriesDeletionRequest
+		expectedErr bool
+	}{
+		"valid": {
+			req: SeriesDeletionRequest{Selectors: []string{`{__name__=~"foo.*"}`}, StartTime: 0, EndTime: 10},
+		},
+		"no selectors": {
+			req:         SeriesDeletionRequest{StartTime: 0, EndTime: 10},
+			expectedErr: true,
+		},
+		"invalid selector": {
+			req:         SeriesDeletionRequest{Selectors: []string{`{__name__=`}, StartTime: 0, EndTime: 10},
+			expectedErr: true,
+		},
+		"end before start": {
+			req:         SeriesDeletionRequest{Selectors: []string{`foo`}, StartTime: 10, EndTime: 0},
+			expectedErr: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := tc.req.Validate()
+			if tc.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSeriesDeletionRequest_State(t *testing.T) {
+	now := time.Now()
+	req := NewSeriesDeletionRequest([]string{`foo`}, 0, 10, now.Add(-time.Hour))
+
+	assert.Equal(t, SeriesDeletionRequestPending, req.State(now, 2*time.Hour))
+	assert.Equal(t, SeriesDeletionRequestProcessing, req.State(now, 30*time.Minute))
+
+	req.ProcessedAt = now.Unix()
+	assert.Equal(t, SeriesDeletionRequestProcessed, req.State(now, 2*time.Hour))
+}
+
+func TestSeriesDeletionRequest_Overlaps(t *testing.T) {
+	req := SeriesDeletionRequest{StartTime: 10, EndTime: 20}
+
+	assert.True(t, req.Overlaps(0, 10))
+	assert.True(t, req.Overlaps(15, 16))
+	assert.True(t, req.Overlaps(20, 30))
+	assert.False(t, req.Overlaps(0, 9))
+	assert.False(t, req.Overlaps(21, 30))
+}
@@ -121,6 +121,9 @@ type BucketStore struct {
 
 	// postingsStrategy is a strategy shared among all tenants.
 	postingsStrategy postingsSelectionStrategy
+
+	// cardinalityLimits limit the postings read by the Cardinality() calls.
+	cardinalityLimits cardinalityLimits
 }
 
 type noopCache struct{}
@@ -228,6 +231,10 @@ func NewBucketStore(
 		userID:                      userID,
 		maxSeriesPerBatch:           bucketStoreConfig.StreamingBatchSize,
 		postingsStrategy:            postingsStrategy,
+		cardinalityLimits: cardinalityLimits{
+			maxLabelValuesPerLabel: bucketStoreConfig.CardinalityMaxLabelValuesPerLabel,
+			maxPostingsBytes:       int64(bucketStoreConfig.CardinalityMaxPostingsBytes),
+		},
 	}
 
 	for _, option := range options {
@@ -589,7 +596,7 @@ func (s *BucketStore) Series(req *storepb.SeriesRequest, srv storepb.Store_Serie
 
 	logSeriesRequestToSpan(srv.Context(), s.logger, req.MinTime, req.MaxTime, matchers, reqBlockMatchers, shardSelector, req.StreamingChunksBatchSize)
 
-	blocks, indexReaders, chunkReaders := s.openBlocksForReading(ctx, req.SkipChunks, req.MinTime, req.MaxTime, reqBlockMatchers, stats)
+	blocks, skippedBlocks, indexReaders, chunkReaders := s.openBlocksForReading(ctx, req.SkipChunks, req.MinTime, req.MaxTime, reqBlockMatchers, matchers, stats)
 	// We must keep the readers open until all their data has been sent.
 	for _, r := range indexReaders {
 		defer runutil.CloseWithLogOnErr(s.logger, r, "close block index reader")
@@ -622,6 +629,10 @@ func (s *BucketStore) Series(req *storepb.SeriesRequest, srv storepb.Store_Serie
 	for _, b := range blocks {
 		resHints.AddQueriedBlock(b.meta.ULID)
 	}
+	// The skipped blocks can't contain any matching series, so they're reported as queried.
+	for _, id := range skippedBlocks {
+		resHints.AddQueriedBlock(id)
+	}
 	if err := s.sendHints(srv, resHints); err != nil {
 		return err
 	}
@@ -1261,7 +1272,9 @@ func (s *BucketStore) recordSeriesHashCacheStats(stats *queryStats) {
 	s.metrics.seriesHashCacheHits.Add(float64(stats.seriesHashCacheHits))
 }
 
-func (s *BucketStore) openBlocksForReading(ctx context.Context, skipChunks bool, minT, maxT int64, blockMatchers []*labels.Matcher, stats *safeQueryStats) ([]*bucketBlock, map[ulid.ULID]*bucketIndexReader, map[ulid.ULID]chunkReader) {
+// openBlocksForReading opens the blocks in the time range matching the block matchers. The blocks whose index stats
+// show that they can't contain series matching all the series matchers are not opened, and returned as skipped.
+func (s *BucketStore) openBlocksForReading(ctx context.Context, skipChunks bool, minT, maxT int64, blockMatchers, seriesMatchers []*labels.Matcher, stats *safeQueryStats) ([]*bucketBlock, []ulid.ULID, map[ulid.ULID]*bucketIndexReader, map[ulid.ULID]chunkReader) {
 	span, spanCtx := opentracing.StartSpanFromContext(ctx, "bucket_store_open_blocks_for_reading")
 	defer span.Finish()
 
@@ -1269,7 +1282,16 @@ func (s *BucketStore) openBlocksForReading(ctx context.Context, skipChunks bool,
 	defer s.blocksMx.RUnlock()
 
 	// Find all blocks owned by this store-gateway instance and matching the request.
+	var skippedBlocks []ulid.ULID
 	blocks := s.blockSet.getFor(minT, maxT, blockMatchers)
+	blocks = slices.DeleteFunc(blocks, func(b *bucketBlock) bool {
+		if b.meta.Thanos.IndexStats.MayContain(seriesMatchers) {
+			return false
+		}
+		skippedBlocks = append(skippedBlocks, b.meta.ULID)
+		return true
+	})
+	s.metrics.seriesBlocksSkippedByIndexStats.Add(float64(len(skippedBlocks)))
 
 	indexReaders := make(map[ulid.ULID]*bucketIndexReader, len(blocks))
 	for _, b := range blocks {
@@ -1277,7 +1299,7 @@ func (s *BucketStore) openBlocksForReading(ctx context.Context, skipChunks bool,
 		indexReaders[b.meta.ULID] = b.loadedIndexReader(spanCtx, s.postingsStrategy, stats)
 	}
 	if skipChunks {
-		return blocks, indexReaders, nil
+		return blocks, skippedBlocks, indexReaders, nil
 	}
 
 	chunkReaders := make(map[ulid.ULID]chunkReader, len(blocks))
@@ -1286,7 +1308,7 @@ func (s *BucketStore) openBlocksForReading(ctx context.Context, skipChunks bool,
 		chunkReaders[b.meta.ULID] = b.chunkReader(ctx)
 	}
 
-	return blocks, indexReaders, chunkReaders
+	return blocks, skippedBlocks, indexReaders, chunkReaders
 }
 
 // LabelNames implements the storepb.StoreServer interface.
@@ -1740,8 +1762,8 @@ type bucketBlockSet struct {
 	blocks []*bucketBlock // Blocks sorted by mint, then maxt.
 }
 
-// newBucketBlockSet initializes a new set with the known downsampling windows hard-configured.
-// (Mimir only supports no-downsampling)
+// newBucketBlockSet initializes a new set. The set holds the blocks of all resolutions: the querier
+// selects the resolution of the blocks to query, and sends their IDs in the requests.
 // The set currently does not support arbitrary ranges.
 func newBucketBlockSet() *bucketBlockSet {
 	return &bucketBlockSet{}
@@ -1832,6 +1854,10 @@ type bucketBlock struct {
 	blockLabels labels.Labels
 
 	expandedPostingsPromises sync.Map
+
+	// exemplars are the exemplars of the block, loaded the first time the block is queried for exemplars.
+	exemplarsMtx sync.Mutex
+	exemplars    *blockExemplarsIndex
 }
 
 func newBucketBlock(
@@ -0,0 +1,282 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package storegateway
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/runutil"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb/index"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
+	"github.com/grafana/mimir/pkg/storegateway/storegatewaypb"
+	"github.com/grafana/mimir/pkg/storegateway/storepb"
+)
+
+// blockCardinalityConcurrency is the maximum number of blocks whose cardinality is computed concurrently by a
+// Cardinality request, as it loads the postings of all the label values of the blocks.
+const blockCardinalityConcurrency = 4
+
+// cardinalityLimits are the limits of the postings read to compute the cardinality of a block.
+type cardinalityLimits struct {
+	maxLabelValuesPerLabel int
+	maxPostingsBytes       int64
+}
+
+// blockCardinality is the number of series of a block, or of the blocks with the same time range and compactor shard.
+type blockCardinality struct {
+	seriesCount uint64
+	metrics     map[string]uint64
+	labelNames  map[string]uint64
+	labelValues map[labels.Label]uint64
+}
+
+func newBlockCardinality() *blockCardinality {
+	return &blockCardinality{
+		metrics:     map[string]uint64{},
+		labelNames:  map[string]uint64{},
+		labelValues: map[labels.Label]uint64{},
+	}
+}
+
+// merge merges the series counts of other, whose series are mostly the same as c's ones, like the ones of the
+// not yet compacted blocks of different ingesters replicas, so the highest series counts are taken.
+func (c *blockCardinality) merge(other *blockCardinality) {
+	c.seriesCount = max(c.seriesCount, other.seriesCount)
+	for name, count := range other.metrics {
+		c.metrics[name] = max(c.metrics[name], count)
+	}
+	for name, count := range other.labelNames {
+		c.labelNames[name] = max(c.labelNames[name], count)
+	}
+	for l, count := range other.labelValues {
+		c.labelValues[l] = max(c.labelValues[l], count)
+	}
+}
+
+// Cardinality implements the storegatewaypb.StoreGatewayServer interface.
+func (s *BucketStore) Cardinality(ctx context.Context, req *storegatewaypb.CardinalityRequest) (*storegatewaypb.CardinalityResponse, error) {
+	matchers, err := storepb.MatchersToPromMatchers(req.Matchers...)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, errors.Wrap(err, "translate request labels matchers").Error())
+	}
+
+	var reqBlockIDs map[string]struct{}
+	if len(req.BlockIds) > 0 {
+		reqBlockIDs = make(map[string]struct{}, len(req.BlockIds))
+		for _, id := range req.BlockIds {
+			reqBlockIDs[id] = struct{}{}
+		}
+	}
+
+	// The blocks split by the compactor hold different series, so each shard is returned separately and
+	// summed by the querier, while the other blocks with the same time range are merged.
+	type blocksKey struct {
+		minTime, maxTime int64
+		shardID          string
+	}
+
+	var (
+		stats         = newSafeQueryStats()
+		queriedBlocks []string
+		mtx           sync.Mutex
+		byKey         = map[blocksKey]*blockCardinality{}
+	)
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(blockCardinalityConcurrency)
+
+	s.blocksMx.RLock()
+	var blocks []*bucketBlock
+	for _, b := range s.blocks {
+		if !b.overlapsClosedInterval(req.MinTime, req.MaxTime) {
+			continue
+		}
+		if _, ok := reqBlockIDs[b.meta.ULID.String()]; reqBlockIDs != nil && !ok {
+			continue
+		}
+		blocks = append(blocks, b)
+		queriedBlocks = append(queriedBlocks, b.meta.ULID.String())
+
+		// Register the index reader while holding the lock, so that the block isn't closed until it's used.
+		b.pendingReaders.Add(1)
+	}
+	s.blocksMx.RUnlock()
+
+	for _, b := range blocks {
+		b := b
+		g.Go(func() error {
+			defer b.pendingReaders.Done()
+
+			result, err := blockCardinalityFromPostings(gctx, b, matchers, s.cardinalityLimits, stats)
+			if err != nil {
+				return errors.Wrapf(err, "block %s", b.meta.ULID)
+			}
+
+			mtx.Lock()
+			defer mtx.Unlock()
+
+			key := blocksKey{
+				minTime: b.meta.MinTime,
+				maxTime: b.meta.MaxTime,
+				shardID: b.meta.Thanos.Labels[mimir_tsdb.CompactorShardIDExternalLabel],
+			}
+			if byKey[key] == nil {
+				byKey[key] = result
+				return nil
+			}
+			byKey[key].merge(result)
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		if errors.Is(err, context.Canceled) {
+			return nil, status.Error(codes.Canceled, err.Error())
+		}
+		if _, ok := httpgrpc.HTTPResponseFromError(errors.Cause(err)); ok {
+			// The limit errors are returned as they are, so that the querier doesn't retry them.
+			return nil, errors.Cause(err)
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	resp := &storegatewaypb.CardinalityResponse{QueriedBlocks: queriedBlocks}
+	for k, c := range byKey {
+		resp.Blocks = append(resp.Blocks, storegatewaypb.BlocksCardinality{
+			MinTime:     k.minTime,
+			MaxTime:     k.maxTime,
+			ShardId:     k.shardID,
+			SeriesCount: c.seriesCount,
+			Metrics:     topCardinalityItems(c.metrics, func(name string) (string, string) { return labels.MetricName, name }, int(req.Limit)),
+			LabelNames:  topCardinalityItems(c.labelNames, func(name string) (string, string) { return name, "" }, int(req.Limit)),
+			LabelValues: topCardinalityItems(c.labelValues, func(l labels.Label) (string, string) { return l.Name, l.Value }, int(req.Limit)),
+		})
+	}
+	sort.Slice(resp.Blocks, func(i, j int) bool {
+		if resp.Blocks[i].MinTime != resp.Blocks[j].MinTime {
+			return resp.Blocks[i].MinTime < resp.Blocks[j].MinTime
+		}
+		if resp.Blocks[i].MaxTime != resp.Blocks[j].MaxTime {
+			return resp.Blocks[i].MaxTime < resp.Blocks[j].MaxTime
+		}
+		return resp.Blocks[i].ShardId < resp.Blocks[j].ShardId
+	})
+
+	return resp, nil
+}
+
+// blockCardinalityFromPostings returns the number of series of the block matching the matchers, per metric name,
+// label name and label name-value pair. The series are counted from the postings of the block, without loading them.
+// The metric names are only counted as metrics, and not as label name or label name-value pairs.
+// The postings are read bypassing the index cache, because they're all read once, and an error is returned as soon as
+// a label has more values, or the block has more postings bytes, than allowed by the limits.
+func blockCardinalityFromPostings(ctx context.Context, b *bucketBlock, matchers []*labels.Matcher, limits cardinalityLimits, stats *safeQueryStats) (*blockCardinality, error) {
+	// This index reader selects all the postings, so there are never pending matchers.
+	indexr := b.loadedIndexReader(ctx, selectAllStrategy{}, stats)
+	defer runutil.CloseWithLogOnErr(b.logger, indexr, "close block index reader")
+
+	result := newBlockCardinality()
+
+	var matchersPostings []storage.SeriesRef
+	if len(matchers) > 0 {
+		var err error
+		if matchersPostings, _, err = indexr.ExpandedPostings(ctx, matchers, stats); err != nil {
+			return nil, errors.Wrap(err, "expanded postings")
+		}
+		if len(matchersPostings) == 0 {
+			return result, nil
+		}
+		result.seriesCount = uint64(len(matchersPostings))
+	} else {
+		result.seriesCount = b.meta.Stats.NumSeries
+	}
+
+	names, err := b.indexHeaderReader.LabelNames()
+	if err != nil {
+		return nil, errors.Wrap(err, "label names")
+	}
+
+	postingsBytes := int64(0)
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		offsets, err := b.indexHeaderReader.LabelValuesOffsets(name, "", nil)
+		if err != nil {
+			return nil, errors.Wrapf(err, "label %q values", name)
+		}
+		if limits.maxLabelValuesPerLabel > 0 && len(offsets) > limits.maxLabelValuesPerLabel {
+			return nil, httpgrpc.Errorf(http.StatusUnprocessableEntity, "the label %q of the block %s has %d values, exceeding the limit of %d label values per label for the cardinality analysis (-%s)", name, b.meta.ULID, len(offsets), limits.maxLabelValuesPerLabel, mimir_tsdb.CardinalityMaxLabelValuesPerLabelFlag)
+		}
+		for _, offset := range offsets {
+			postingsBytes += offset.Off.End - offset.Off.Start
+		}
+		if limits.maxPostingsBytes > 0 && postingsBytes > limits.maxPostingsBytes {
+			return nil, httpgrpc.Errorf(http.StatusUnprocessableEntity, "the postings of the block %s exceed the limit of %d bytes of postings per block for the cardinality analysis (-%s)", b.meta.ULID, limits.maxPostingsBytes, mimir_tsdb.CardinalityMaxPostingsBytesFlag)
+		}
+
+		keys := make([]labels.Label, len(offsets))
+		for i, offset := range offsets {
+			keys[i] = labels.Label{Name: name, Value: offset.LabelValue}
+		}
+
+		postings, err := indexr.FetchPostingsBypassingCache(ctx, keys, stats)
+		if err != nil {
+			return nil, errors.Wrapf(err, "label %q postings", name)
+		}
+
+		for i, p := range postings {
+			if matchersPostings != nil {
+				p = index.Intersect(index.NewListPostings(matchersPostings), p)
+			}
+
+			count := uint64(0)
+			for p.Next() {
+				count++
+			}
+			if err := p.Err(); err != nil {
+				return nil, errors.Wrapf(err, "label %q value %q postings", name, keys[i].Value)
+			}
+			if count == 0 {
+				continue
+			}
+
+			if name == labels.MetricName {
+				result.metrics[keys[i].Value] = count
+				continue
+			}
+			result.labelNames[name] += count
+			result.labelValues[keys[i]] = count
+		}
+	}
+
+	return result, nil
+}
+
+// topCardinalityItems returns the limit items with the highest series counts, sorted by series count and then by
+// label name and value. If limit is 0, all the items are returned.
+func topCardinalityItems[K comparable](counts map[K]uint64, toLabel func(K) (string, string), limit int) []storegatewaypb.CardinalityItem {
+	items := make([]storegatewaypb.CardinalityItem, 0, len(counts))
+	for k, count := range counts {
+		name, value := toLabel(k)
+		items = append(items, storegatewaypb.CardinalityItem{LabelName: name, LabelValue: value, SeriesCount: count})
+	}
+	storegatewaypb.SortCardinalityItems(items)
+
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
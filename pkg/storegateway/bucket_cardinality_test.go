@@ -0,0 +1,247 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package storegateway
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/timestamp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
+	"github.com/grafana/mimir/pkg/storegateway/storegatewaypb"
+	"github.com/grafana/mimir/pkg/storegateway/storepb"
+)
+
+func TestBucketStore_Cardinality(t *testing.T) {
+	cfg := defaultPrepareStoreConfig(t)
+	// The first 4 series are stored in a block, and the others in another block with the same time range.
+	cfg.series = []labels.Labels{
+		labels.FromStrings(labels.MetricName, "up", "job", "a", "pod", "1"),
+		labels.FromStrings(labels.MetricName, "up", "job", "a", "pod", "2"),
+		labels.FromStrings(labels.MetricName, "up", "job", "b", "pod", "1"),
+		labels.FromStrings(labels.MetricName, "up", "job", "b", "pod", "2"),
+		labels.FromStrings(labels.MetricName, "http_requests_total", "job", "a", "pod", "1"),
+		labels.FromStrings(labels.MetricName, "http_requests_total", "job", "a", "pod", "2"),
+		labels.FromStrings(labels.MetricName, "http_requests_total", "job", "a", "pod", "3"),
+		labels.FromStrings(labels.MetricName, "process_start_time_seconds", "job", "b"),
+	}
+	s := prepareStoreWithTestBlocks(t, objstore.NewInMemBucket(), cfg)
+
+	// The first block of each time range gets "ext1" external label, and the second one "ext2". Split them by the
+	// compactor shard, so that their series are counted separately.
+	setShardIDs := func(withShardIDs bool) {
+		for _, b := range s.store.blocks {
+			shardID := "2_of_2"
+			if _, ok := b.meta.Thanos.Labels["ext1"]; ok {
+				shardID = "1_of_2"
+			}
+			if withShardIDs {
+				b.meta.Thanos.Labels[mimir_tsdb.CompactorShardIDExternalLabel] = shardID
+			} else {
+				delete(b.meta.Thanos.Labels, mimir_tsdb.CompactorShardIDExternalLabel)
+			}
+		}
+	}
+
+	tests := map[string]struct {
+		req                   *storegatewaypb.CardinalityRequest
+		expectedQueriedBlocks int
+		expectedByShard       map[string]storegatewaypb.BlocksCardinality
+	}{
+		"no matchers": {
+			req:                   &storegatewaypb.CardinalityRequest{MinTime: s.minTime, MaxTime: s.maxTime},
+			expectedQueriedBlocks: 6,
+			expectedByShard: map[string]storegatewaypb.BlocksCardinality{
+				"1_of_2": {
+					SeriesCount: 4,
+					Metrics: []storegatewaypb.CardinalityItem{
+						{LabelName: labels.MetricName, LabelValue: "up", SeriesCount: 4},
+					},
+					LabelNames: []storegatewaypb.CardinalityItem{
+						{LabelName: "job", SeriesCount: 4},
+						{LabelName: "pod", SeriesCount: 4},
+					},
+					LabelValues: []storegatewaypb.CardinalityItem{
+						{LabelName: "job", LabelValue: "a", SeriesCount: 2},
+						{LabelName: "job", LabelValue: "b", SeriesCount: 2},
+						{LabelName: "pod", LabelValue: "1", SeriesCount: 2},
+						{LabelName: "pod", LabelValue: "2", SeriesCount: 2},
+					},
+				},
+				"2_of_2": {
+					SeriesCount: 4,
+					Metrics: []storegatewaypb.CardinalityItem{
+						{LabelName: labels.MetricName, LabelValue: "http_requests_total", SeriesCount: 3},
+						{LabelName: labels.MetricName, LabelValue: "process_start_time_seconds", SeriesCount: 1},
+					},
+					LabelNames: []storegatewaypb.CardinalityItem{
+						{LabelName: "job", SeriesCount: 4},
+						{LabelName: "pod", SeriesCount: 3},
+					},
+					LabelValues: []storegatewaypb.CardinalityItem{
+						{LabelName: "job", LabelValue: "a", SeriesCount: 3},
+						{LabelName: "job", LabelValue: "b", SeriesCount: 1},
+						{LabelName: "pod", LabelValue: "1", SeriesCount: 1},
+						{LabelName: "pod", LabelValue: "2", SeriesCount: 1},
+						{LabelName: "pod", LabelValue: "3", SeriesCount: 1},
+					},
+				},
+			},
+		},
+		"matchers and limit": {
+			req: &storegatewaypb.CardinalityRequest{
+				MinTime:  s.minTime,
+				MaxTime:  s.maxTime,
+				Matchers: []storepb.LabelMatcher{{Type: storepb.LabelMatcher_EQ, Name: "job", Value: "a"}},
+				Limit:    2,
+			},
+			expectedQueriedBlocks: 6,
+			expectedByShard: map[string]storegatewaypb.BlocksCardinality{
+				"1_of_2": {
+					SeriesCount: 2,
+					Metrics: []storegatewaypb.CardinalityItem{
+						{LabelName: labels.MetricName, LabelValue: "up", SeriesCount: 2},
+					},
+					LabelNames: []storegatewaypb.CardinalityItem{
+						{LabelName: "job", SeriesCount: 2},
+						{LabelName: "pod", SeriesCount: 2},
+					},
+					LabelValues: []storegatewaypb.CardinalityItem{
+						{LabelName: "job", LabelValue: "a", SeriesCount: 2},
+						{LabelName: "pod", LabelValue: "1", SeriesCount: 1},
+					},
+				},
+				"2_of_2": {
+					SeriesCount: 3,
+					Metrics: []storegatewaypb.CardinalityItem{
+						{LabelName: labels.MetricName, LabelValue: "http_requests_total", SeriesCount: 3},
+					},
+					LabelNames: []storegatewaypb.CardinalityItem{
+						{LabelName: "job", SeriesCount: 3},
+						{LabelName: "pod", SeriesCount: 3},
+					},
+					LabelValues: []storegatewaypb.CardinalityItem{
+						{LabelName: "job", LabelValue: "a", SeriesCount: 3},
+						{LabelName: "pod", LabelValue: "1", SeriesCount: 1},
+					},
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			setShardIDs(true)
+
+			resp, err := s.store.Cardinality(context.Background(), tc.req)
+			require.NoError(t, err)
+
+			assert.Len(t, resp.QueriedBlocks, tc.expectedQueriedBlocks)
+			// Each time range has a block per compactor shard.
+			require.Len(t, resp.Blocks, 6)
+			for i, b := range resp.Blocks {
+				if i%2 == 0 {
+					if i > 0 {
+						assert.Greater(t, b.MinTime, resp.Blocks[i-1].MinTime)
+					}
+				} else {
+					assert.Equal(t, resp.Blocks[i-1].MinTime, b.MinTime)
+				}
+
+				expected := tc.expectedByShard[b.ShardId]
+				expected.MinTime, expected.MaxTime, expected.ShardId = b.MinTime, b.MaxTime, b.ShardId
+				assert.Equal(t, expected, b)
+			}
+		})
+	}
+
+	t.Run("blocks not split by the compactor", func(t *testing.T) {
+		setShardIDs(false)
+
+		resp, err := s.store.Cardinality(context.Background(), &storegatewaypb.CardinalityRequest{MinTime: s.minTime, MaxTime: s.maxTime})
+		require.NoError(t, err)
+
+		// The blocks of each time range are merged as if they were replicas of the same series.
+		require.Len(t, resp.Blocks, 3)
+		for _, b := range resp.Blocks {
+			assert.Equal(t, storegatewaypb.BlocksCardinality{
+				MinTime:     b.MinTime,
+				MaxTime:     b.MaxTime,
+				SeriesCount: 4,
+				Metrics: []storegatewaypb.CardinalityItem{
+					{LabelName: labels.MetricName, LabelValue: "up", SeriesCount: 4},
+					{LabelName: labels.MetricName, LabelValue: "http_requests_total", SeriesCount: 3},
+					{LabelName: labels.MetricName, LabelValue: "process_start_time_seconds", SeriesCount: 1},
+				},
+				LabelNames: []storegatewaypb.CardinalityItem{
+					{LabelName: "job", SeriesCount: 4},
+					{LabelName: "pod", SeriesCount: 4},
+				},
+				LabelValues: []storegatewaypb.CardinalityItem{
+					{LabelName: "job", LabelValue: "a", SeriesCount: 3},
+					{LabelName: "job", LabelValue: "b", SeriesCount: 2},
+					{LabelName: "pod", LabelValue: "1", SeriesCount: 2},
+					{LabelName: "pod", LabelValue: "2", SeriesCount: 2},
+					{LabelName: "pod", LabelValue: "3", SeriesCount: 1},
+				},
+			}, b)
+		}
+	})
+
+	t.Run("limits", func(t *testing.T) {
+		t.Cleanup(func() { s.store.cardinalityLimits = cardinalityLimits{} })
+
+		for name, limits := range map[string]cardinalityLimits{
+			"label values per label": {maxLabelValuesPerLabel: 2},
+			"postings bytes":         {maxPostingsBytes: 1},
+		} {
+			t.Run(name, func(t *testing.T) {
+				s.store.cardinalityLimits = limits
+
+				_, err := s.store.Cardinality(context.Background(), &storegatewaypb.CardinalityRequest{MinTime: s.minTime, MaxTime: s.maxTime})
+				require.Error(t, err)
+				resp, ok := httpgrpc.HTTPResponseFromError(err)
+				require.True(t, ok)
+				assert.Equal(t, int32(http.StatusUnprocessableEntity), resp.Code)
+			})
+		}
+	})
+
+	t.Run("outside time range", func(t *testing.T) {
+		resp, err := s.store.Cardinality(context.Background(), &storegatewaypb.CardinalityRequest{
+			MinTime: timestamp.FromTime(time.Now().Add(-24 * time.Hour)),
+			MaxTime: timestamp.FromTime(time.Now().Add(-23 * time.Hour)),
+		})
+		require.NoError(t, err)
+		assert.Empty(t, resp.QueriedBlocks)
+		assert.Empty(t, resp.Blocks)
+	})
+
+	t.Run("block IDs", func(t *testing.T) {
+		var blockID string
+		for id := range s.store.blocks {
+			blockID = id.String()
+			break
+		}
+
+		resp, err := s.store.Cardinality(context.Background(), &storegatewaypb.CardinalityRequest{
+			MinTime:  s.minTime,
+			MaxTime:  s.maxTime,
+			BlockIds: []string{blockID},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{blockID}, resp.QueriedBlocks)
+		require.Len(t, resp.Blocks, 1)
+		assert.Empty(t, resp.Blocks[0].ShardId)
+		// Each block holds 4 series.
+		assert.Equal(t, uint64(4), resp.Blocks[0].SeriesCount)
+	})
+}
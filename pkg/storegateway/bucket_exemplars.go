@@ -0,0 +1,242 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package storegateway
+
+import (
+	"context"
+	"path"
+	"slices"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/labels"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+	"github.com/grafana/mimir/pkg/storage/tsdb/block"
+	"github.com/grafana/mimir/pkg/storegateway/storegatewaypb"
+	"github.com/grafana/mimir/pkg/storegateway/storepb"
+)
+
+// blockExemplarsConcurrency is the maximum number of blocks whose exemplars file is read concurrently by an
+// Exemplars request.
+const blockExemplarsConcurrency = 4
+
+// Exemplars implements the storegatewaypb.StoreGatewayServer interface.
+func (s *BucketStore) Exemplars(ctx context.Context, req *storegatewaypb.ExemplarsRequest) (_ *storegatewaypb.ExemplarsResponse, err error) {
+	defer func() {
+		if err == nil {
+			return
+		}
+		code := codes.Internal
+		if st, ok := status.FromError(errors.Cause(err)); ok {
+			code = st.Code()
+		} else if errors.Is(err, context.Canceled) {
+			code = codes.Canceled
+		}
+		err = status.Error(code, err.Error())
+	}()
+
+	matcherSets := make([][]*labels.Matcher, 0, len(req.Matchers))
+	for _, set := range req.Matchers {
+		matchers, err := storepb.MatchersToPromMatchers(set.Matchers...)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, errors.Wrap(err, "translate request labels matchers").Error())
+		}
+		matcherSets = append(matcherSets, matchers)
+	}
+
+	var reqBlockIDs map[string]struct{}
+	if len(req.BlockIds) > 0 {
+		reqBlockIDs = make(map[string]struct{}, len(req.BlockIds))
+		for _, id := range req.BlockIds {
+			reqBlockIDs[id] = struct{}{}
+		}
+	}
+
+	var (
+		queriedBlocks []string
+		blocks        []*bucketBlock
+	)
+
+	s.blocksMx.RLock()
+	for _, b := range s.blocks {
+		if !b.overlapsClosedInterval(req.MinTime, req.MaxTime) {
+			continue
+		}
+		if _, ok := reqBlockIDs[b.meta.ULID.String()]; reqBlockIDs != nil && !ok {
+			continue
+		}
+		queriedBlocks = append(queriedBlocks, b.meta.ULID.String())
+
+		// Blocks without exemplars are queried too, but there's nothing to read.
+		if b.meta.HasExemplars() {
+			blocks = append(blocks, b)
+		}
+	}
+	s.blocksMx.RUnlock()
+
+	var (
+		mtx           sync.Mutex
+		sets          [][]exemplar.QueryResult
+		seriesLimiter = s.seriesLimiterFactory(s.metrics.queriesDropped.WithLabelValues("series"))
+	)
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(blockExemplarsConcurrency)
+
+	for _, b := range blocks {
+		b := b
+		g.Go(func() error {
+			idx, err := b.loadExemplars(gctx)
+			if err != nil {
+				return errors.Wrapf(err, "block %s", b.meta.ULID)
+			}
+
+			res := idx.query(req.MinTime, req.MaxTime, matcherSets)
+			if err := seriesLimiter.Reserve(uint64(len(res))); err != nil {
+				return err
+			}
+
+			mtx.Lock()
+			sets = append(sets, res)
+			mtx.Unlock()
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	resp := &storegatewaypb.ExemplarsResponse{QueriedBlocks: queriedBlocks}
+	for _, series := range block.MergeExemplars(sets...) {
+		resp.Timeseries = append(resp.Timeseries, mimirpb.TimeSeries{
+			Labels:    mimirpb.FromLabelsToLabelAdapters(series.SeriesLabels),
+			Exemplars: mimirpb.FromExemplarsToExemplarProtos(series.Exemplars),
+		})
+	}
+	return resp, nil
+}
+
+// blockExemplarsIndex holds the exemplars of a block, indexed by metric name.
+type blockExemplarsIndex struct {
+	// series are the series of the exemplars file, sorted by labels.
+	series []exemplar.QueryResult
+	// byName are the series of each metric name, sorted by labels.
+	byName map[string][]exemplar.QueryResult
+}
+
+func newBlockExemplarsIndex(series []exemplar.QueryResult) *blockExemplarsIndex {
+	idx := &blockExemplarsIndex{
+		series: series,
+		byName: map[string][]exemplar.QueryResult{},
+	}
+	for _, s := range series {
+		name := s.SeriesLabels.Get(labels.MetricName)
+		idx.byName[name] = append(idx.byName[name], s)
+	}
+	return idx
+}
+
+// query returns the exemplars in the time range of the series matching any of the matchers sets.
+func (idx *blockExemplarsIndex) query(minT, maxT int64, matcherSets [][]*labels.Matcher) []exemplar.QueryResult {
+	var result []exemplar.QueryResult
+	for _, s := range idx.candidates(matcherSets) {
+		if !matchesAnySet(s.SeriesLabels, matcherSets) {
+			continue
+		}
+
+		var exemplars []exemplar.Exemplar
+		for _, e := range s.Exemplars {
+			if e.Ts >= minT && e.Ts <= maxT {
+				exemplars = append(exemplars, e)
+			}
+		}
+		if len(exemplars) > 0 {
+			result = append(result, exemplar.QueryResult{SeriesLabels: s.SeriesLabels, Exemplars: exemplars})
+		}
+	}
+	return result
+}
+
+// candidates returns the series which may match any of the matchers sets: only the series of the metric names
+// selected by the sets are looked up if all of them have an equal matcher on the metric name, while all
+// the series are otherwise.
+func (idx *blockExemplarsIndex) candidates(matcherSets [][]*labels.Matcher) []exemplar.QueryResult {
+	names := make([]string, 0, len(matcherSets))
+	for _, matchers := range matcherSets {
+		name, ok := metricNameFromMatchers(matchers)
+		if !ok {
+			return idx.series
+		}
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	if len(names) == 1 {
+		return idx.byName[names[0]]
+	}
+
+	var result []exemplar.QueryResult
+	for _, name := range names {
+		result = append(result, idx.byName[name]...)
+	}
+	return result
+}
+
+// metricNameFromMatchers returns the metric name selected by an equal matcher, if any.
+func metricNameFromMatchers(matchers []*labels.Matcher) (string, bool) {
+	for _, m := range matchers {
+		if m.Name == labels.MetricName && m.Type == labels.MatchEqual {
+			return m.Value, true
+		}
+	}
+	return "", false
+}
+
+// loadExemplars returns the exemplars of the block. The exemplars file is read from the bucket the first time
+// the block is queried, and kept in memory until the block is unloaded. The file isn't read concurrently by
+// multiple requests, and is read again if it previously failed.
+func (b *bucketBlock) loadExemplars(ctx context.Context) (*blockExemplarsIndex, error) {
+	b.exemplarsMtx.Lock()
+	defer b.exemplarsMtx.Unlock()
+
+	if b.exemplars != nil {
+		return b.exemplars, nil
+	}
+
+	rc, err := b.bkt.Get(ctx, path.Join(b.meta.ULID.String(), block.ExemplarsFilename))
+	if err != nil {
+		return nil, errors.Wrap(err, "get exemplars file")
+	}
+
+	series, err := block.ReadExemplars(rc)
+	if err != nil {
+		return nil, err
+	}
+
+	b.exemplars = newBlockExemplarsIndex(series)
+	return b.exemplars, nil
+}
+
+// matchesAnySet returns whether the labels match all the matchers of any of the sets.
+func matchesAnySet(lbls labels.Labels, matcherSets [][]*labels.Matcher) bool {
+	for _, matchers := range matcherSets {
+		matches := true
+		for _, m := range matchers {
+			if !m.Matches(lbls.Get(m.Name)) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
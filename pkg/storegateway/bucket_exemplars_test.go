@@ -0,0 +1,238 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package storegateway
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+	"go.uber.org/atomic"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+	"github.com/grafana/mimir/pkg/storage/tsdb/block"
+	"github.com/grafana/mimir/pkg/storegateway/storegatewaypb"
+	"github.com/grafana/mimir/pkg/storegateway/storepb"
+)
+
+func TestBucketStore_Exemplars(t *testing.T) {
+	ctx := context.Background()
+	cfg := defaultPrepareStoreConfig(t)
+	// The first 4 series are stored in a block, and the others in another block with the same time range.
+	cfg.series = []labels.Labels{
+		labels.FromStrings(labels.MetricName, "up", "job", "a"),
+		labels.FromStrings(labels.MetricName, "up", "job", "b"),
+		labels.FromStrings(labels.MetricName, "http_requests_total", "job", "a"),
+		labels.FromStrings(labels.MetricName, "http_requests_total", "job", "b"),
+		labels.FromStrings(labels.MetricName, "process_start_time_seconds", "job", "a"),
+		labels.FromStrings(labels.MetricName, "process_start_time_seconds", "job", "b"),
+		labels.FromStrings(labels.MetricName, "process_start_time_seconds", "job", "c"),
+	}
+	bkt := objstore.NewInMemBucket()
+	s := prepareStoreWithTestBlocks(t, bkt, cfg)
+
+	// Sort the blocks by time, to store the exemplars in the first two.
+	var blocks []*bucketBlock
+	for _, b := range s.store.blocks {
+		blocks = append(blocks, b)
+	}
+	sort.Slice(blocks, func(i, j int) bool {
+		if blocks[i].meta.MinTime != blocks[j].meta.MinTime {
+			return blocks[i].meta.MinTime < blocks[j].meta.MinTime
+		}
+		return blocks[i].meta.ULID.Compare(blocks[j].meta.ULID) < 0
+	})
+	require.Len(t, blocks, 6)
+
+	trace1 := labels.FromStrings("trace_id", "1")
+	trace2 := labels.FromStrings("trace_id", "2")
+	firstTs, secondTs := blocks[0].meta.MinTime, blocks[0].meta.MinTime+1
+
+	uploadExemplars := func(b *bucketBlock, series []exemplar.QueryResult) {
+		dir := filepath.Join(t.TempDir(), b.meta.ULID.String())
+		require.NoError(t, os.MkdirAll(dir, 0o750))
+		require.NoError(t, block.WriteExemplarsFile(log.NewNopLogger(), dir, series))
+
+		data, err := os.ReadFile(filepath.Join(dir, block.ExemplarsFilename))
+		require.NoError(t, err)
+		require.NoError(t, bkt.Upload(ctx, path.Join(b.meta.ULID.String(), block.ExemplarsFilename), bytes.NewReader(data)))
+		b.meta.Thanos.Files = append(b.meta.Thanos.Files, block.File{RelPath: block.ExemplarsFilename, SizeBytes: int64(len(data))})
+	}
+
+	// The two blocks have the same time range, and the same exemplar is stored in both of them.
+	uploadExemplars(blocks[0], []exemplar.QueryResult{
+		{SeriesLabels: cfg.series[0], Exemplars: []exemplar.Exemplar{{Labels: trace1, Value: 1, Ts: firstTs}}},
+		{SeriesLabels: cfg.series[2], Exemplars: []exemplar.Exemplar{{Labels: trace2, Value: 2, Ts: secondTs}}},
+	})
+	uploadExemplars(blocks[1], []exemplar.QueryResult{
+		{SeriesLabels: cfg.series[0], Exemplars: []exemplar.Exemplar{{Labels: trace1, Value: 1, Ts: firstTs}, {Labels: trace2, Value: 2, Ts: secondTs}}},
+		{SeriesLabels: cfg.series[1], Exemplars: []exemplar.Exemplar{{Labels: trace1, Value: 3, Ts: secondTs}}},
+	})
+
+	// Count the reads of the exemplars files, which are expected to be read once per block.
+	gets := make([]*atomic.Int64, 2)
+	for i := range gets {
+		gets[i] = &atomic.Int64{}
+		blocks[i].bkt = &countingGetBucketReader{BucketReader: blocks[i].bkt, gets: gets[i]}
+	}
+
+	tests := map[string]struct {
+		req                   *storegatewaypb.ExemplarsRequest
+		expectedQueriedBlocks int
+		expectedSeries        []mimirpb.TimeSeries
+	}{
+		"single matchers set": {
+			req: &storegatewaypb.ExemplarsRequest{
+				MinTime:  s.minTime,
+				MaxTime:  s.maxTime,
+				Matchers: []storegatewaypb.ExemplarMatchers{{Matchers: []storepb.LabelMatcher{{Type: storepb.LabelMatcher_EQ, Name: labels.MetricName, Value: "up"}}}},
+			},
+			expectedQueriedBlocks: 6,
+			expectedSeries: []mimirpb.TimeSeries{
+				{
+					Labels: mimirpb.FromLabelsToLabelAdapters(cfg.series[0]),
+					Exemplars: []mimirpb.Exemplar{
+						{Labels: mimirpb.FromLabelsToLabelAdapters(trace1), Value: 1, TimestampMs: firstTs},
+						{Labels: mimirpb.FromLabelsToLabelAdapters(trace2), Value: 2, TimestampMs: secondTs},
+					},
+				},
+				{
+					Labels:    mimirpb.FromLabelsToLabelAdapters(cfg.series[1]),
+					Exemplars: []mimirpb.Exemplar{{Labels: mimirpb.FromLabelsToLabelAdapters(trace1), Value: 3, TimestampMs: secondTs}},
+				},
+			},
+		},
+		"multiple matchers sets": {
+			req: &storegatewaypb.ExemplarsRequest{
+				MinTime: s.minTime,
+				MaxTime: s.maxTime,
+				Matchers: []storegatewaypb.ExemplarMatchers{
+					{Matchers: []storepb.LabelMatcher{{Type: storepb.LabelMatcher_EQ, Name: labels.MetricName, Value: "http_requests_total"}}},
+					{Matchers: []storepb.LabelMatcher{{Type: storepb.LabelMatcher_EQ, Name: "job", Value: "b"}}},
+				},
+			},
+			expectedQueriedBlocks: 6,
+			expectedSeries: []mimirpb.TimeSeries{
+				{
+					Labels:    mimirpb.FromLabelsToLabelAdapters(cfg.series[2]),
+					Exemplars: []mimirpb.Exemplar{{Labels: mimirpb.FromLabelsToLabelAdapters(trace2), Value: 2, TimestampMs: secondTs}},
+				},
+				{
+					Labels:    mimirpb.FromLabelsToLabelAdapters(cfg.series[1]),
+					Exemplars: []mimirpb.Exemplar{{Labels: mimirpb.FromLabelsToLabelAdapters(trace1), Value: 3, TimestampMs: secondTs}},
+				},
+			},
+		},
+		"time range": {
+			req: &storegatewaypb.ExemplarsRequest{
+				MinTime:  firstTs,
+				MaxTime:  firstTs,
+				Matchers: []storegatewaypb.ExemplarMatchers{{Matchers: []storepb.LabelMatcher{{Type: storepb.LabelMatcher_RE, Name: labels.MetricName, Value: ".+"}}}},
+			},
+			expectedQueriedBlocks: 2,
+			expectedSeries: []mimirpb.TimeSeries{
+				{
+					Labels:    mimirpb.FromLabelsToLabelAdapters(cfg.series[0]),
+					Exemplars: []mimirpb.Exemplar{{Labels: mimirpb.FromLabelsToLabelAdapters(trace1), Value: 1, TimestampMs: firstTs}},
+				},
+			},
+		},
+		"block IDs": {
+			req: &storegatewaypb.ExemplarsRequest{
+				MinTime:  s.minTime,
+				MaxTime:  s.maxTime,
+				Matchers: []storegatewaypb.ExemplarMatchers{{Matchers: []storepb.LabelMatcher{{Type: storepb.LabelMatcher_RE, Name: labels.MetricName, Value: ".+"}}}},
+				BlockIds: []string{blocks[0].meta.ULID.String(), blocks[2].meta.ULID.String()},
+			},
+			expectedQueriedBlocks: 2,
+			expectedSeries: []mimirpb.TimeSeries{
+				{
+					Labels:    mimirpb.FromLabelsToLabelAdapters(cfg.series[2]),
+					Exemplars: []mimirpb.Exemplar{{Labels: mimirpb.FromLabelsToLabelAdapters(trace2), Value: 2, TimestampMs: secondTs}},
+				},
+				{
+					Labels:    mimirpb.FromLabelsToLabelAdapters(cfg.series[0]),
+					Exemplars: []mimirpb.Exemplar{{Labels: mimirpb.FromLabelsToLabelAdapters(trace1), Value: 1, TimestampMs: firstTs}},
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp, err := s.store.Exemplars(ctx, tc.req)
+			require.NoError(t, err)
+			require.Len(t, resp.QueriedBlocks, tc.expectedQueriedBlocks)
+			require.Equal(t, tc.expectedSeries, resp.Timeseries)
+		})
+	}
+
+	t.Run("exemplars files are read once", func(t *testing.T) {
+		for _, g := range gets {
+			require.Equal(t, int64(1), g.Load())
+		}
+	})
+
+	t.Run("max series per query", func(t *testing.T) {
+		s.store.seriesLimiterFactory = newStaticSeriesLimiterFactory(1)
+		t.Cleanup(func() { s.store.seriesLimiterFactory = newStaticSeriesLimiterFactory(0) })
+
+		req := &storegatewaypb.ExemplarsRequest{
+			MinTime:  s.minTime,
+			MaxTime:  s.maxTime,
+			Matchers: []storegatewaypb.ExemplarMatchers{{Matchers: []storepb.LabelMatcher{{Type: storepb.LabelMatcher_EQ, Name: labels.MetricName, Value: "up"}}}},
+		}
+		_, err := s.store.Exemplars(ctx, req)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "the query exceeded the maximum number of series (limit: 1 series)")
+
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		require.Equal(t, codes.Code(http.StatusUnprocessableEntity), st.Code())
+	})
+}
+
+func TestBlockExemplarsIndex_Candidates(t *testing.T) {
+	series := []exemplar.QueryResult{
+		{SeriesLabels: labels.FromStrings(labels.MetricName, "a", "job", "1")},
+		{SeriesLabels: labels.FromStrings(labels.MetricName, "a", "job", "2")},
+		{SeriesLabels: labels.FromStrings(labels.MetricName, "b", "job", "1")},
+	}
+	idx := newBlockExemplarsIndex(series)
+
+	eq := func(name, value string) *labels.Matcher {
+		return labels.MustNewMatcher(labels.MatchEqual, name, value)
+	}
+
+	require.Equal(t, series[:2], idx.candidates([][]*labels.Matcher{{eq(labels.MetricName, "a")}}))
+	require.Equal(t, series[:2], idx.candidates([][]*labels.Matcher{{eq(labels.MetricName, "a")}, {eq(labels.MetricName, "a"), eq("job", "1")}}))
+	require.Equal(t, []exemplar.QueryResult{series[2], series[0], series[1]}, idx.candidates([][]*labels.Matcher{{eq(labels.MetricName, "b")}, {eq(labels.MetricName, "a")}}))
+	require.Empty(t, idx.candidates([][]*labels.Matcher{{eq(labels.MetricName, "c")}}))
+
+	// All the series are candidates if any set doesn't select a metric name.
+	require.Equal(t, series, idx.candidates([][]*labels.Matcher{{eq(labels.MetricName, "a")}, {eq("job", "1")}}))
+	require.Equal(t, series, idx.candidates([][]*labels.Matcher{{labels.MustNewMatcher(labels.MatchRegexp, labels.MetricName, "a")}}))
+}
+
+type countingGetBucketReader struct {
+	objstore.BucketReader
+	gets *atomic.Int64
+}
+
+func (r *countingGetBucketReader) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	r.gets.Inc()
+	return r.BucketReader.Get(ctx, name)
+}
@@ -200,7 +200,7 @@ func (r *bucketIndexReader) expandedPostings(ctx context.Context, ms []*labels.M
 	postingGroups, omittedPostingGroups := r.postingsStrategy.selectPostings(postingGroups)
 	logSelectedPostingGroups(ctx, r.block.logger, r.block.meta.ULID, postingGroups, omittedPostingGroups)
 
-	fetchedPostings, err := r.fetchPostings(ctx, extractLabels(postingGroups), stats)
+	fetchedPostings, err := r.fetchPostings(ctx, extractLabels(postingGroups), true, stats)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "get postings")
 	}
@@ -393,7 +393,18 @@ func toPostingGroups(ms []*labels.Matcher, indexhdr indexheader.Reader) ([]posti
 // It returns one postings for each key, in the same order.
 // If postings for given key is not fetched, entry at given index will be an ErrPostings
 func (r *bucketIndexReader) FetchPostings(ctx context.Context, keys []labels.Label, stats *safeQueryStats) ([]index.Postings, error) {
-	ps, err := r.fetchPostings(ctx, keys, stats)
+	return r.fetchPaddedPostings(ctx, keys, true, stats)
+}
+
+// FetchPostingsBypassingCache is like FetchPostings, but the postings are neither looked up in nor stored to the
+// index cache. It's used to fetch the postings of many label values at once, which would otherwise evict the
+// postings cached for the queries.
+func (r *bucketIndexReader) FetchPostingsBypassingCache(ctx context.Context, keys []labels.Label, stats *safeQueryStats) ([]index.Postings, error) {
+	return r.fetchPaddedPostings(ctx, keys, false, stats)
+}
+
+func (r *bucketIndexReader) fetchPaddedPostings(ctx context.Context, keys []labels.Label, useCache bool, stats *safeQueryStats) ([]index.Postings, error) {
+	ps, err := r.fetchPostings(ctx, keys, useCache, stats)
 	if err != nil {
 		return nil, err
 	}
@@ -416,7 +427,8 @@ func (r *bucketIndexReader) FetchPostings(ctx context.Context, keys []labels.Lab
 // fetchPostings is the version-unaware private implementation of FetchPostings.
 // callers of this method may need to add padding to the results.
 // If postings for given key is not fetched, entry at given index will be nil.
-func (r *bucketIndexReader) fetchPostings(ctx context.Context, keys []labels.Label, stats *safeQueryStats) ([]index.Postings, error) {
+// If useCache is false, the index cache is neither read nor written.
+func (r *bucketIndexReader) fetchPostings(ctx context.Context, keys []labels.Label, useCache bool, stats *safeQueryStats) ([]index.Postings, error) {
 	timer := prometheus.NewTimer(r.block.metrics.postingsFetchDuration)
 	defer timer.ObserveDuration()
 
@@ -425,38 +437,43 @@ func (r *bucketIndexReader) fetchPostings(ctx context.Context, keys []labels.Lab
 	output := make([]index.Postings, len(keys))
 
 	// Fetch postings from the cache with a single call.
-	fromCache := r.block.indexCache.FetchMultiPostings(ctx, r.block.userID, r.block.meta.ULID, keys)
+	var fromCache indexcache.BytesResult
+	if useCache {
+		fromCache = r.block.indexCache.FetchMultiPostings(ctx, r.block.userID, r.block.meta.ULID, keys)
+	}
 
 	// Iterate over all groups and fetch posting from cache.
 	// If we have a miss, mark key to be fetched in `ptrs` slice.
 	// Overlaps are well handled by partitioner, so we don't need to deduplicate keys.
 	for ix, key := range keys {
 		// Get postings for the given key from cache first.
-		if b, _ := fromCache.Next(); b != nil {
-			stats.update(func(stats *queryStats) {
-				stats.postingsTouched++
-				stats.postingsTouchedSizeSum += len(b)
-			})
+		if fromCache != nil {
+			if b, _ := fromCache.Next(); b != nil {
+				stats.update(func(stats *queryStats) {
+					stats.postingsTouched++
+					stats.postingsTouchedSizeSum += len(b)
+				})
 
-			l, cachedLabelsKey, pendingMatchers, err := r.decodePostings(b, stats)
-			if len(pendingMatchers) > 0 {
-				return nil, fmt.Errorf("not expecting matchers on non-expanded postings for %s=%s in block %s, but got %s",
-					key.Name, key.Value, r.block.meta.ULID, util.MatchersStringer(pendingMatchers))
-			}
-			if err == nil && cachedLabelsKey == encodeLabelForPostingsCache(key) {
-				output[ix] = l
-				continue
-			}
+				l, cachedLabelsKey, pendingMatchers, err := r.decodePostings(b, stats)
+				if len(pendingMatchers) > 0 {
+					return nil, fmt.Errorf("not expecting matchers on non-expanded postings for %s=%s in block %s, but got %s",
+						key.Name, key.Value, r.block.meta.ULID, util.MatchersStringer(pendingMatchers))
+				}
+				if err == nil && cachedLabelsKey == encodeLabelForPostingsCache(key) {
+					output[ix] = l
+					continue
+				}
 
-			level.Warn(r.block.logger).Log(
-				"msg", "can't decode cached postings",
-				"err", err,
-				"key", fmt.Sprintf("%+v", key),
-				"labels_key", cachedLabelsKey,
-				"block", r.block.meta.ULID,
-				"bytes_len", len(b),
-				"bytes_head_hex", hex.EncodeToString(b[:util_math.Min(8, len(b))]),
-			)
+				level.Warn(r.block.logger).Log(
+					"msg", "can't decode cached postings",
+					"err", err,
+					"key", fmt.Sprintf("%+v", key),
+					"labels_key", cachedLabelsKey,
+					"block", r.block.meta.ULID,
+					"bytes_len", len(b),
+					"bytes_head_hex", hex.EncodeToString(b[:util_math.Min(8, len(b))]),
+				)
+			}
 		}
 
 		// Cache miss; save pointer for actual posting in index stored in object store.
@@ -527,23 +544,25 @@ func (r *bucketIndexReader) fetchPostings(ctx context.Context, keys []labels.Lab
 				// This can only fail, if postings data was somehow corrupted,
 				// and there is nothing we can do about it.
 				// Errors from corrupted postings will be reported when postings are used.
-				compressions++
-				s := time.Now()
-				bep := newBigEndianPostings(pBytes[4:])
-				dataToCache, err := diffVarintSnappyWithMatchersEncode(bep, bep.length(), encodeLabelForPostingsCache(keys[p.keyID]), nil)
-				compressionTime = time.Since(s)
-				if err == nil {
-					compressedSize = len(dataToCache)
-					r.block.indexCache.StorePostings(r.block.userID, r.block.meta.ULID, keys[p.keyID], dataToCache)
-				} else {
-					compressionErrors = 1
-					level.Warn(r.block.logger).Log(
-						"msg", "couldn't encode postings for cache",
-						"err", err,
-						"user", r.block.userID,
-						"block", r.block.meta.ULID,
-						"label", keys[p.keyID],
-					)
+				if useCache {
+					compressions++
+					s := time.Now()
+					bep := newBigEndianPostings(pBytes[4:])
+					dataToCache, err := diffVarintSnappyWithMatchersEncode(bep, bep.length(), encodeLabelForPostingsCache(keys[p.keyID]), nil)
+					compressionTime = time.Since(s)
+					if err == nil {
+						compressedSize = len(dataToCache)
+						r.block.indexCache.StorePostings(r.block.userID, r.block.meta.ULID, keys[p.keyID], dataToCache)
+					} else {
+						compressionErrors = 1
+						level.Warn(r.block.logger).Log(
+							"msg", "couldn't encode postings for cache",
+							"err", err,
+							"user", r.block.userID,
+							"block", r.block.meta.ULID,
+							"label", keys[p.keyID],
+						)
+					}
 				}
 
 				// Return postings. Truncate first 4 bytes which are length of posting.
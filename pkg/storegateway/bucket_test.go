@@ -1908,6 +1908,40 @@ func TestBucketStore_Series_RequestAndResponseHints(t *testing.T) {
 	}
 }
 
+func TestBucketStore_Series_ShouldSkipBlocksByIndexStats(t *testing.T) {
+	tb, store, _, seriesSet2, block1, block2, cleanup := setupStoreForHintsTest(t, 5000)
+	tb.Cleanup(cleanup)
+
+	// The index stats of block1 show that it doesn't contain the series with the "foo" label.
+	store.blocksMx.Lock()
+	store.blocks[block1].meta.Thanos.IndexStats = &block.IndexStats{LabelNames: []string{"other"}, NumLabelNames: 1}
+	store.blocksMx.Unlock()
+
+	testCase := &seriesCase{
+		Name: "the skipped blocks are returned in the response hints",
+		Req: &storepb.SeriesRequest{
+			MinTime: 0,
+			MaxTime: 3,
+			Matchers: []storepb.LabelMatcher{
+				{Type: storepb.LabelMatcher_EQ, Name: "foo", Value: "bar"},
+			},
+		},
+		ExpectedSeries: seriesSet2,
+		ExpectedHints: hintspb.SeriesResponseHints{
+			QueriedBlocks: []hintspb.Block{
+				{Id: block2.String()},
+				{Id: block1.String()},
+			},
+		},
+	}
+
+	for _, streamingBatchSize := range []int{0, 1, 5} {
+		t.Run(fmt.Sprintf("streamingBatchSize=%d", streamingBatchSize), func(t *testing.T) {
+			runTestServerSeries(tb, store, streamingBatchSize, testCase)
+		})
+	}
+}
+
 func TestBucketStore_Series_ErrorUnmarshallingRequestHints(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -0,0 +1,21 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package storegatewaypb
+
+import (
+	"sort"
+)
+
+// SortCardinalityItems sorts the items by series count, in descending order, and then by label name and value.
+func SortCardinalityItems(items []CardinalityItem) {
+	sort.Slice(items, func(i, j int) bool {
+		switch {
+		case items[i].SeriesCount != items[j].SeriesCount:
+			return items[i].SeriesCount > items[j].SeriesCount
+		case items[i].LabelName != items[j].LabelName:
+			return items[i].LabelName < items[j].LabelName
+		default:
+			return items[i].LabelValue < items[j].LabelValue
+		}
+	})
+}
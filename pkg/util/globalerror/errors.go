@@ -14,24 +14,26 @@ type ID string
 const (
 	errPrefix = "err-mimir-"
 
-	MissingMetricName             ID = "missing-metric-name"
-	InvalidMetricName             ID = "metric-name-invalid"
-	MaxLabelNamesPerSeries        ID = "max-label-names-per-series"
-	MaxNativeHistogramBuckets     ID = "max-native-histogram-buckets"
-	SeriesInvalidLabel            ID = "label-invalid"
-	SeriesLabelNameTooLong        ID = "label-name-too-long"
-	SeriesLabelValueTooLong       ID = "label-value-too-long"
-	SeriesWithDuplicateLabelNames ID = "duplicate-label-names"
-	SeriesLabelsNotSorted         ID = "labels-not-sorted"
-	SampleTooFarInFuture          ID = "too-far-in-future"
-	MaxSeriesPerMetric            ID = "max-series-per-metric"
-	MaxMetadataPerMetric          ID = "max-metadata-per-metric"
-	MaxSeriesPerUser              ID = "max-series-per-user"
-	MaxMetadataPerUser            ID = "max-metadata-per-user"
-	MaxChunksPerQuery             ID = "max-chunks-per-query"
-	MaxSeriesPerQuery             ID = "max-series-per-query"
-	MaxChunkBytesPerQuery         ID = "max-chunks-bytes-per-query"
-	MaxEstimatedChunksPerQuery    ID = "max-estimated-chunks-per-query"
+	MissingMetricName                   ID = "missing-metric-name"
+	InvalidMetricName                   ID = "metric-name-invalid"
+	MaxLabelNamesPerSeries              ID = "max-label-names-per-series"
+	MaxNativeHistogramBuckets           ID = "max-native-histogram-buckets"
+	InvalidNativeHistogramSchema        ID = "invalid-native-histogram-schema"
+	InvalidNativeHistogramCustomBuckets ID = "invalid-native-histogram-custom-buckets"
+	SeriesInvalidLabel                  ID = "label-invalid"
+	SeriesLabelNameTooLong              ID = "label-name-too-long"
+	SeriesLabelValueTooLong             ID = "label-value-too-long"
+	SeriesWithDuplicateLabelNames       ID = "duplicate-label-names"
+	SeriesLabelsNotSorted               ID = "labels-not-sorted"
+	SampleTooFarInFuture                ID = "too-far-in-future"
+	MaxSeriesPerMetric                  ID = "max-series-per-metric"
+	MaxMetadataPerMetric                ID = "max-metadata-per-metric"
+	MaxSeriesPerUser                    ID = "max-series-per-user"
+	MaxMetadataPerUser                  ID = "max-metadata-per-user"
+	MaxChunksPerQuery                   ID = "max-chunks-per-query"
+	MaxSeriesPerQuery                   ID = "max-series-per-query"
+	MaxChunkBytesPerQuery               ID = "max-chunks-bytes-per-query"
+	MaxEstimatedChunksPerQuery          ID = "max-estimated-chunks-per-query"
 
 	DistributorMaxIngestionRate             ID = "distributor-max-ingestion-rate"
 	DistributorMaxInflightPushRequests      ID = "distributor-max-inflight-push-requests"
@@ -57,6 +59,7 @@ const (
 	MaxQueryExpressionSizeBytes ID = "max-query-expression-size-bytes"
 	RequestRateLimited          ID = "tenant-max-request-rate"
 	IngestionRateLimited        ID = "tenant-max-ingestion-rate"
+	MetricIngestionRateLimited  ID = "metric-max-ingestion-rate"
 	TooManyHAClusters           ID = "tenant-too-many-ha-clusters"
 	QueryBlocked                ID = "query-blocked"
 
@@ -0,0 +1,28 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package validation
+
+import (
+	"fmt"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// CompactorRetentionRule is the retention period of the series matching a selector.
+type CompactorRetentionRule struct {
+	// Selector is a series selector, like a metric name or {__name__=~"debug_.*"}.
+	Selector string `yaml:"selector" json:"selector"`
+	// Period is the retention period of the series matching the selector. 0 to keep them forever.
+	Period model.Duration `yaml:"period" json:"period"`
+}
+
+func (r *CompactorRetentionRule) validate() error {
+	if r == nil {
+		return fmt.Errorf("invalid compactor_retention_rules: empty entry")
+	}
+	if _, err := parser.ParseMetricSelector(r.Selector); err != nil {
+		return fmt.Errorf("invalid compactor_retention_rules selector %q: %w", r.Selector, err)
+	}
+	return nil
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/grafana/dskit/flagext"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/relabel"
+	"golang.org/x/exp/slices"
 	"golang.org/x/time/rate"
 	"gopkg.in/yaml.v3"
 
@@ -52,11 +53,35 @@ const (
 	resultsCacheTTLFlag                      = "query-frontend.results-cache-ttl"
 	resultsCacheTTLForOutOfOrderWindowFlag   = "query-frontend.results-cache-ttl-for-out-of-order-time-window"
 	QueryIngestersWithinFlag                 = "querier.query-ingesters-within"
+	OTelDeltaTemporalityHandlingFlag         = "distributor.otel-delta-temporality-handling"
+	OTelExponentialHistogramMaxScaleFlag     = "distributor.otel-exponential-histogram-max-scale"
+	CostAttributionLabelsFlag                = "validation.cost-attribution-labels"
+	SeriesLimitOverflowDropLabelsFlag        = "ingester.series-limit-overflow-drop-labels"
+	compactorRawBlocksRetentionPeriodFlag    = "compactor.raw-blocks-retention-period"
+	compactor5mBlocksRetentionPeriodFlag     = "compactor.5m-blocks-retention-period"
+	compactorDownsampling5mDelayFlag         = "compactor.downsampling-5m-delay"
+	compactorDownsampling1hDelayFlag         = "compactor.downsampling-1h-delay"
+
+	// Supported ways of handling the OTLP metrics with delta aggregation temporality.
+	OTelDeltaTemporalityReject         = "reject"
+	OTelDeltaTemporalityConvertToGauge = "convert-to-gauge"
+
+	// Range of the scales of the OTLP exponential histograms which can be converted to native histograms.
+	OTelExponentialHistogramMinScale = -4
+	OTelExponentialHistogramMaxScale = 8
 
 	// MinCompactorPartialBlockDeletionDelay is the minimum partial blocks deletion delay that can be configured in Mimir.
 	MinCompactorPartialBlockDeletionDelay = 4 * time.Hour
 )
 
+var otelDeltaTemporalityHandlings = []string{OTelDeltaTemporalityReject, OTelDeltaTemporalityConvertToGauge}
+
+// SeriesLimitOverflowLabel is the label added to the overflow series, which aggregate the series exceeding the series limits.
+const SeriesLimitOverflowLabel = "__mimir_overflow__"
+
+// costAttributionReservedLabels are the labels of the cost attribution metrics, which can't be used as cost attribution labels.
+var costAttributionReservedLabels = []string{"user", "reason"}
+
 // LimitError are errors that do not comply with the limits specified.
 type LimitError string
 
@@ -82,15 +107,30 @@ type Limits struct {
 	MaxLabelNamesPerSeries                      int                 `yaml:"max_label_names_per_series" json:"max_label_names_per_series"`
 	MaxMetadataLength                           int                 `yaml:"max_metadata_length" json:"max_metadata_length"`
 	MaxNativeHistogramBuckets                   int                 `yaml:"max_native_histogram_buckets" json:"max_native_histogram_buckets"`
+	ReduceNativeHistogramOverMaxBuckets         bool                `yaml:"reduce_native_histogram_over_max_buckets" json:"reduce_native_histogram_over_max_buckets" category:"experimental"`
 	CreationGracePeriod                         model.Duration      `yaml:"creation_grace_period" json:"creation_grace_period" category:"advanced"`
 	EnforceMetadataMetricName                   bool                `yaml:"enforce_metadata_metric_name" json:"enforce_metadata_metric_name" category:"advanced"`
 	IngestionTenantShardSize                    int                 `yaml:"ingestion_tenant_shard_size" json:"ingestion_tenant_shard_size"`
 	MetricRelabelConfigs                        []*relabel.Config   `yaml:"metric_relabel_configs,omitempty" json:"metric_relabel_configs,omitempty" doc:"nocli|description=List of metric relabel configurations. Note that in most situations, it is more effective to use metrics relabeling directly in the Prometheus server, e.g. remote_write.write_relabel_configs. Labels available during the relabeling phase and cleaned afterwards: __meta_tenant_id" category:"experimental"`
 	ServiceOverloadStatusCodeOnRateLimitEnabled bool                `yaml:"service_overload_status_code_on_rate_limit_enabled" json:"service_overload_status_code_on_rate_limit_enabled" category:"experimental"`
+	// Ingestion rate limits of the series matching a selector.
+	MetricIngestionRateLimits []*MetricIngestionRateLimit `yaml:"metric_ingestion_rate_limits,omitempty" json:"metric_ingestion_rate_limits,omitempty" doc:"nocli|description=List of ingestion rate limits of the series matching a selector, each one with its own rate (in samples per second) and burst size. The samples of the series matching the selector of a rate limit are counted against it, in addition to the tenant ingestion rate limit. When a series matches multiple selectors, only the first matching rate limit applies. Series exceeding their rate limit are discarded, while the other series of the request are ingested." category:"experimental"`
+	// Stream aggregation rules evaluated by the distributors.
+	StreamAggregationRules []*StreamAggregationRule `yaml:"stream_aggregation_rules,omitempty" json:"stream_aggregation_rules,omitempty" doc:"nocli|description=List of stream aggregation rules, evaluated by the distributors on the float samples they receive after metric relabeling. Each rule aggregates the samples of the series matching the match selector, received over the interval, grouping them by or without labels like PromQL aggregations. Supported outputs: sum and count, min and max of the latest values of the series, sum_samples and count_samples of all the samples received over the interval. The latest value of a series is kept until it doesn't receive samples for 5 minutes or the interval, whichever is longer. Output series are named <metric>:<interval>_by_<labels>_<output> or <metric>:<interval>_without_<labels>_<output>. Each output series is aggregated and pushed by a single distributor, found hashing its labels on the distributors ring, to which the other distributors forward its input series. When drop_input is true, the input series are discarded after being aggregated." category:"experimental"`
+	// OTLP ingestion.
+	OTelPromoteResourceAttributes    flagext.StringSliceCSV `yaml:"otel_promote_resource_attributes" json:"otel_promote_resource_attributes" category:"experimental"`
+	OTelMetricSuffixesEnabled        bool                   `yaml:"otel_metric_suffixes_enabled" json:"otel_metric_suffixes_enabled" category:"experimental"`
+	OTelDeltaTemporalityHandling     string                 `yaml:"otel_delta_temporality_handling" json:"otel_delta_temporality_handling" category:"experimental"`
+	OTelExponentialHistogramMaxScale int                    `yaml:"otel_exponential_histogram_max_scale" json:"otel_exponential_histogram_max_scale" category:"experimental"`
 	// Ingester enforced limits.
 	// Series
 	MaxGlobalSeriesPerUser   int `yaml:"max_global_series_per_user" json:"max_global_series_per_user"`
 	MaxGlobalSeriesPerMetric int `yaml:"max_global_series_per_metric" json:"max_global_series_per_metric"`
+	// Series limits overflow
+	SeriesLimitOverflowEnabled             bool                   `yaml:"series_limit_overflow_enabled" json:"series_limit_overflow_enabled" category:"experimental"`
+	SeriesLimitOverflowDropLabels          flagext.StringSliceCSV `yaml:"series_limit_overflow_drop_labels" json:"series_limit_overflow_drop_labels" category:"experimental"`
+	SeriesLimitOverflowMaxSeries           int                    `yaml:"series_limit_overflow_max_series" json:"series_limit_overflow_max_series" category:"experimental"`
+	SeriesLimitOverflowMaxAggregatedSeries int                    `yaml:"series_limit_overflow_max_aggregated_series" json:"series_limit_overflow_max_aggregated_series" category:"experimental"`
 	// Metadata
 	MaxGlobalMetricsWithMetadataPerUser int `yaml:"max_global_metadata_per_user" json:"max_global_metadata_per_user"`
 	MaxGlobalMetadataPerMetric          int `yaml:"max_global_metadata_per_metric" json:"max_global_metadata_per_metric"`
@@ -107,6 +147,10 @@ type Limits struct {
 	// User defined label to give the option of subdividing specific metrics by another label
 	SeparateMetricsGroupLabel string `yaml:"separate_metrics_group_label" json:"separate_metrics_group_label" category:"experimental"`
 
+	// Cost attribution.
+	CostAttributionLabels                flagext.StringSliceCSV `yaml:"cost_attribution_labels" json:"cost_attribution_labels" category:"experimental"`
+	MaxCostAttributionCardinalityPerUser int                    `yaml:"max_cost_attribution_cardinality_per_user" json:"max_cost_attribution_cardinality_per_user" category:"experimental"`
+
 	// Querier enforced limits.
 	MaxChunksPerQuery                    int            `yaml:"max_fetched_chunks_per_query" json:"max_fetched_chunks_per_query"`
 	MaxEstimatedChunksPerQueryMultiplier float64        `yaml:"max_estimated_fetched_chunks_per_query_multiplier" json:"max_estimated_fetched_chunks_per_query_multiplier" category:"experimental"`
@@ -118,6 +162,7 @@ type Limits struct {
 	MaxLabelsQueryLength                 model.Duration `yaml:"max_labels_query_length" json:"max_labels_query_length"`
 	MaxCacheFreshness                    model.Duration `yaml:"max_cache_freshness" json:"max_cache_freshness" category:"advanced"`
 	MaxQueriersPerTenant                 int            `yaml:"max_queriers_per_tenant" json:"max_queriers_per_tenant"`
+	QuerySchedulerTenantWeight           float64        `yaml:"query_scheduler_tenant_weight" json:"query_scheduler_tenant_weight" category:"experimental"`
 	QueryShardingTotalShards             int            `yaml:"query_sharding_total_shards" json:"query_sharding_total_shards"`
 	QueryShardingMaxShardedQueries       int            `yaml:"query_sharding_max_sharded_queries" json:"query_sharding_max_sharded_queries"`
 	QueryShardingMaxRegexpSizeBytes      int            `yaml:"query_sharding_max_regexp_size_bytes" json:"query_sharding_max_regexp_size_bytes"`
@@ -133,6 +178,7 @@ type Limits struct {
 	ResultsCacheForUnalignedQueryEnabled   bool            `yaml:"cache_unaligned_requests" json:"cache_unaligned_requests" category:"advanced"`
 	MaxQueryExpressionSizeBytes            int             `yaml:"max_query_expression_size_bytes" json:"max_query_expression_size_bytes"`
 	BlockedQueries                         []*BlockedQuery `yaml:"blocked_queries,omitempty" json:"blocked_queries,omitempty" doc:"nocli|description=List of queries to block." category:"experimental"`
+	QueryPriorityHeaderEnabled             bool            `yaml:"query_priority_header_enabled" json:"query_priority_header_enabled" category:"experimental"`
 
 	// Cardinality
 	CardinalityAnalysisEnabled                    bool `yaml:"cardinality_analysis_enabled" json:"cardinality_analysis_enabled"`
@@ -152,15 +198,20 @@ type Limits struct {
 	StoreGatewayTenantShardSize int `yaml:"store_gateway_tenant_shard_size" json:"store_gateway_tenant_shard_size"`
 
 	// Compactor.
-	CompactorBlocksRetentionPeriod        model.Duration `yaml:"compactor_blocks_retention_period" json:"compactor_blocks_retention_period"`
-	CompactorSplitAndMergeShards          int            `yaml:"compactor_split_and_merge_shards" json:"compactor_split_and_merge_shards"`
-	CompactorSplitGroups                  int            `yaml:"compactor_split_groups" json:"compactor_split_groups"`
-	CompactorTenantShardSize              int            `yaml:"compactor_tenant_shard_size" json:"compactor_tenant_shard_size"`
-	CompactorPartialBlockDeletionDelay    model.Duration `yaml:"compactor_partial_block_deletion_delay" json:"compactor_partial_block_deletion_delay"`
-	CompactorBlockUploadEnabled           bool           `yaml:"compactor_block_upload_enabled" json:"compactor_block_upload_enabled"`
-	CompactorBlockUploadValidationEnabled bool           `yaml:"compactor_block_upload_validation_enabled" json:"compactor_block_upload_validation_enabled"`
-	CompactorBlockUploadVerifyChunks      bool           `yaml:"compactor_block_upload_verify_chunks" json:"compactor_block_upload_verify_chunks"`
-	CompactorBlockUploadMaxBlockSizeBytes int64          `yaml:"compactor_block_upload_max_block_size_bytes" json:"compactor_block_upload_max_block_size_bytes" category:"advanced"`
+	CompactorBlocksRetentionPeriod        model.Duration            `yaml:"compactor_blocks_retention_period" json:"compactor_blocks_retention_period"`
+	CompactorRetentionRules               []*CompactorRetentionRule `yaml:"compactor_retention_rules,omitempty" json:"compactor_retention_rules,omitempty" doc:"nocli|description=List of retention rules, each one keeping the series matching its selector for its own retention period instead of -compactor.blocks-retention-period. A period of 0 keeps the series forever. When a series matches multiple selectors, only the first matching rule applies. The compactor rewrites the blocks to delete the series exceeding their retention period, and deletes the whole blocks once older than the longest retention period." category:"experimental"`
+	CompactorRawBlocksRetentionPeriod     model.Duration            `yaml:"compactor_raw_blocks_retention_period" json:"compactor_raw_blocks_retention_period" category:"experimental"`
+	Compactor5mBlocksRetentionPeriod      model.Duration            `yaml:"compactor_5m_blocks_retention_period" json:"compactor_5m_blocks_retention_period" category:"experimental"`
+	CompactorDownsampling5mDelay          model.Duration            `yaml:"compactor_downsampling_5m_delay" json:"compactor_downsampling_5m_delay" category:"experimental"`
+	CompactorDownsampling1hDelay          model.Duration            `yaml:"compactor_downsampling_1h_delay" json:"compactor_downsampling_1h_delay" category:"experimental"`
+	CompactorSplitAndMergeShards          int                       `yaml:"compactor_split_and_merge_shards" json:"compactor_split_and_merge_shards"`
+	CompactorSplitGroups                  int                       `yaml:"compactor_split_groups" json:"compactor_split_groups"`
+	CompactorTenantShardSize              int                       `yaml:"compactor_tenant_shard_size" json:"compactor_tenant_shard_size"`
+	CompactorPartialBlockDeletionDelay    model.Duration            `yaml:"compactor_partial_block_deletion_delay" json:"compactor_partial_block_deletion_delay"`
+	CompactorBlockUploadEnabled           bool                      `yaml:"compactor_block_upload_enabled" json:"compactor_block_upload_enabled"`
+	CompactorBlockUploadValidationEnabled bool                      `yaml:"compactor_block_upload_validation_enabled" json:"compactor_block_upload_validation_enabled"`
+	CompactorBlockUploadVerifyChunks      bool                      `yaml:"compactor_block_upload_verify_chunks" json:"compactor_block_upload_verify_chunks"`
+	CompactorBlockUploadMaxBlockSizeBytes int64                     `yaml:"compactor_block_upload_max_block_size_bytes" json:"compactor_block_upload_max_block_size_bytes" category:"advanced"`
 
 	// This config doesn't have a CLI flag registered here because they're registered in
 	// their own original config struct.
@@ -202,13 +253,22 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&l.MaxLabelNamesPerSeries, MaxLabelNamesPerSeriesFlag, 30, "Maximum number of label names per series.")
 	f.IntVar(&l.MaxMetadataLength, MaxMetadataLengthFlag, 1024, "Maximum length accepted for metric metadata. Metadata refers to Metric Name, HELP and UNIT. Longer metadata is dropped except for HELP which is truncated.")
 	f.IntVar(&l.MaxNativeHistogramBuckets, maxNativeHistogramBucketsFlag, 0, "Maximum number of buckets per native histogram sample. 0 to disable the limit.")
+	f.BoolVar(&l.ReduceNativeHistogramOverMaxBuckets, "validation.reduce-native-histogram-over-max-buckets", false, "Whether to reduce the resolution of native histogram samples exceeding the maximum number of buckets, by merging adjacent buckets, until they fit the limit. If disabled, such samples are rejected.")
 	_ = l.CreationGracePeriod.Set("10m")
 	f.Var(&l.CreationGracePeriod, CreationGracePeriodFlag, "Controls how far into the future incoming samples and exemplars are accepted compared to the wall clock. Any sample or exemplar will be rejected if its timestamp is greater than '(now + grace_period)'. This configuration is enforced in the distributor, ingester and query-frontend (to avoid querying too far into the future).")
 	f.BoolVar(&l.EnforceMetadataMetricName, "validation.enforce-metadata-metric-name", true, "Enforce every metadata has a metric name.")
 	f.BoolVar(&l.ServiceOverloadStatusCodeOnRateLimitEnabled, "distributor.service-overload-status-code-on-rate-limit-enabled", false, "If enabled, rate limit errors will be reported to the client with HTTP status code 529 (Service is overloaded). If disabled, status code 429 (Too Many Requests) is used.")
+	f.Var(&l.OTelPromoteResourceAttributes, "distributor.otel-promote-resource-attributes", "Comma-separated list of OTLP resource attributes to promote to labels of the series of the resource. Resource attributes are otherwise only added to the target_info series. Attributes of the data points take precedence over the promoted resource attributes with the same name.")
+	f.BoolVar(&l.OTelMetricSuffixesEnabled, "distributor.otel-metric-suffixes-enabled", false, "Whether to add the unit and type suffixes, such as _seconds and _total, to the names of the metrics ingested through the OTLP endpoint, following the OpenTelemetry to Prometheus translation rules.")
+	f.StringVar(&l.OTelDeltaTemporalityHandling, OTelDeltaTemporalityHandlingFlag, OTelDeltaTemporalityReject, fmt.Sprintf("How to handle the OTLP metrics with delta aggregation temporality. Supported values: %s. With %q, the samples of delta metrics are discarded. With %q, the samples of delta sums are ingested as gauges, while delta histograms are discarded, because they can't be converted to cumulative histograms without state.", strings.Join(otelDeltaTemporalityHandlings, ", "), OTelDeltaTemporalityReject, OTelDeltaTemporalityConvertToGauge))
+	f.IntVar(&l.OTelExponentialHistogramMaxScale, OTelExponentialHistogramMaxScaleFlag, OTelExponentialHistogramMaxScale, fmt.Sprintf("Maximum scale of the OTLP exponential histograms, between %d and %d. Exponential histograms with a greater scale are downscaled, by merging adjacent buckets, before being converted to native histograms. Lower scales reduce the resolution and the number of buckets of the ingested histograms.", OTelExponentialHistogramMinScale, OTelExponentialHistogramMaxScale))
 
 	f.IntVar(&l.MaxGlobalSeriesPerUser, MaxSeriesPerUserFlag, 150000, "The maximum number of in-memory series per tenant, across the cluster before replication. 0 to disable.")
 	f.IntVar(&l.MaxGlobalSeriesPerMetric, MaxSeriesPerMetricFlag, 0, "The maximum number of in-memory series per metric name, across the cluster before replication. 0 to disable.")
+	f.BoolVar(&l.SeriesLimitOverflowEnabled, "ingester.series-limit-overflow-enabled", false, fmt.Sprintf("When enabled, the float samples of the counter series which can't be created because of -%s or -%s are aggregated into an overflow series, labeled %s=\"true\", instead of being discarded. An overflow series is a counter of the increases of the series aggregated into it, so rates over the metric stay correct. Only the series whose metric name ends with _total, _count, _sum or _bucket are considered counters: the samples of the other series are still discarded. Each ingester aggregates the series it received, and queries deduplicate the overflow series of the ingesters like the replicas of any other series. Overflow series don't count towards the series limits.", MaxSeriesPerUserFlag, MaxSeriesPerMetricFlag, SeriesLimitOverflowLabel))
+	f.Var(&l.SeriesLimitOverflowDropLabels, SeriesLimitOverflowDropLabelsFlag, "Comma-separated list of high-cardinality labels to drop from the series aggregated into an overflow series. If empty, all the series of a metric are aggregated into a single overflow series with only the metric name label.")
+	f.IntVar(&l.SeriesLimitOverflowMaxSeries, "ingester.series-limit-overflow-max-series", 1000, "Maximum number of overflow series per tenant in each ingester. The samples of the series which would be aggregated into additional overflow series are discarded. 0 to disable.")
+	f.IntVar(&l.SeriesLimitOverflowMaxAggregatedSeries, "ingester.series-limit-overflow-max-aggregated-series", 100000, "Maximum number of series per tenant aggregated into the overflow series of each ingester at the same time. The aggregated series stop counting towards the limit 5 minutes after their latest sample. The samples of the series exceeding the limit are discarded. 0 to disable.")
 
 	f.IntVar(&l.MaxGlobalMetricsWithMetadataPerUser, MaxMetadataPerUserFlag, 0, "The maximum number of in-memory metrics with metadata per tenant, across the cluster. 0 to disable.")
 	f.IntVar(&l.MaxGlobalMetadataPerMetric, MaxMetadataPerMetricFlag, 0, "The maximum number of metadata per metric, across the cluster. 0 to disable.")
@@ -219,6 +279,8 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	f.BoolVar(&l.OutOfOrderBlocksExternalLabelEnabled, "ingester.out-of-order-blocks-external-label-enabled", false, "Whether the shipper should label out-of-order blocks with an external label before uploading them. Setting this label will compact out-of-order blocks separately from non-out-of-order blocks")
 
 	f.StringVar(&l.SeparateMetricsGroupLabel, "validation.separate-metrics-group-label", "", "Label used to define the group label for metrics separation. For each write request, the group is obtained from the first non-empty group label from the first timeseries in the incoming list of timeseries. Specific distributor and ingester metrics will be further separated adding a 'group' label with group label's value. Currently applies to the following metrics: cortex_discarded_samples_total")
+	f.Var(&l.CostAttributionLabels, CostAttributionLabelsFlag, "Comma-separated list of labels by which the active series, received samples and discarded samples of the tenant are attributed. When set, the distributors and ingesters export the cortex_distributor_received_attributed_samples_total, cortex_discarded_attributed_samples_total and cortex_ingester_attributed_active_series metrics, labeled with the values of these labels.")
+	f.IntVar(&l.MaxCostAttributionCardinalityPerUser, "validation.max-cost-attribution-cardinality-per-user", 10000, "Maximum number of distinct combinations of cost attribution label values tracked for the tenant. Series with further combinations are attributed to the __overflow__ value of all the cost attribution labels. 0 to disable the limit.")
 
 	f.IntVar(&l.MaxChunksPerQuery, MaxChunksPerQueryFlag, 2e6, "Maximum number of chunks that can be fetched in a single query from ingesters and long-term storage. This limit is enforced in the querier, ruler and store-gateway. 0 to disable.")
 	f.Float64Var(&l.MaxEstimatedChunksPerQueryMultiplier, MaxEstimatedChunksPerQueryMultiplierFlag, 0, "Maximum number of chunks estimated to be fetched in a single query from ingesters and long-term storage, as a multiple of -"+MaxChunksPerQueryFlag+". This limit is enforced in the querier. Must be greater than or equal to 1, or 0 to disable.")
@@ -235,6 +297,7 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	f.Var(&l.MaxCacheFreshness, "query-frontend.max-cache-freshness", "Most recent allowed cacheable result per-tenant, to prevent caching very recent results that might still be in flux.")
 
 	f.IntVar(&l.MaxQueriersPerTenant, "query-frontend.max-queriers-per-tenant", 0, "Maximum number of queriers that can handle requests for a single tenant. If set to 0 or value higher than number of available queriers, *all* queriers will handle requests for the tenant. Each frontend (or query-scheduler, if used) will select the same set of queriers for the same tenant (given that all queriers are connected to all frontends / query-schedulers). This option only works with queriers connecting to the query-frontend / query-scheduler, not when using downstream URL.")
+	f.Float64Var(&l.QuerySchedulerTenantWeight, "query-scheduler.tenant-weight", 1, "Weight of the tenant when cost-based fair queuing is enabled in the query-scheduler. A tenant with weight 2 gets twice the share of querier time of a tenant with weight 1 when queriers are saturated. Values lower than or equal to 0 are treated as 1.")
 	f.IntVar(&l.QueryShardingTotalShards, "query-frontend.query-sharding-total-shards", 16, "The amount of shards to use when doing parallelisation via query sharding by tenant. 0 to disable query sharding for tenant. Query sharding implementation will adjust the number of query shards based on compactor shards. This allows querier to not search the blocks which cannot possibly have the series for given query shard.")
 	f.IntVar(&l.QueryShardingMaxShardedQueries, "query-frontend.query-sharding-max-sharded-queries", 128, "The max number of sharded queries that can be run for a given received query. 0 to disable limit.")
 	f.IntVar(&l.QueryShardingMaxRegexpSizeBytes, "query-frontend.query-sharding-max-regexp-size-bytes", 4096, "Disable query sharding for any query containing a regular expression matcher longer than the configured number of bytes. 0 to disable the limit.")
@@ -252,6 +315,10 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	f.BoolVar(&l.RulerSyncRulesOnChangesEnabled, "ruler.sync-rules-on-changes-enabled", true, "True to enable a re-sync of the configured rule groups as soon as they're changed via ruler's config API. This re-sync is in addition of the periodic syncing. When enabled, it may take up to few tens of seconds before a configuration change triggers the re-sync.")
 
 	f.Var(&l.CompactorBlocksRetentionPeriod, "compactor.blocks-retention-period", "Delete blocks containing samples older than the specified retention period. Also used by query-frontend to avoid querying beyond the retention period. 0 to disable.")
+	f.Var(&l.CompactorRawBlocksRetentionPeriod, compactorRawBlocksRetentionPeriodFlag, "Delete the raw blocks, which haven't been downsampled, containing samples older than the specified retention period. It must be greater than the delay of the first enabled downsampling, so that the raw blocks are downsampled before being deleted. 0 to apply -compactor.blocks-retention-period.")
+	f.Var(&l.Compactor5mBlocksRetentionPeriod, compactor5mBlocksRetentionPeriodFlag, "Delete the blocks downsampled to 5 minutes containing samples older than the specified retention period. It must be greater than -"+compactorDownsampling1hDelayFlag+" when the blocks are downsampled to 1 hour. 0 to apply -compactor.blocks-retention-period.")
+	f.Var(&l.CompactorDownsampling5mDelay, compactorDownsampling5mDelayFlag, "Minimum age of the raw blocks, computed from their max time, before the compactor downsamples them to 5 minutes. The downsampled blocks store the minimum, maximum, sum, count and average of the float samples of each 5 minutes window. It should be greater than the largest -compactor.block-ranges, so that the blocks are downsampled once fully compacted. 0 to disable.")
+	f.Var(&l.CompactorDownsampling1hDelay, compactorDownsampling1hDelayFlag, "Minimum age of the blocks, computed from their max time, before the compactor downsamples them to 1 hour. The blocks downsampled to 5 minutes are downsampled to 1 hour if -"+compactorDownsampling5mDelayFlag+" is enabled, otherwise the raw blocks are. 0 to disable.")
 	f.IntVar(&l.CompactorSplitAndMergeShards, "compactor.split-and-merge-shards", 0, "The number of shards to use when splitting blocks. 0 to disable splitting.")
 	f.IntVar(&l.CompactorSplitGroups, "compactor.split-groups", 1, "Number of groups that blocks for splitting should be grouped into. Each group of blocks is then split separately. Number of output split shards is controlled by -compactor.split-and-merge-shards.")
 	f.IntVar(&l.CompactorTenantShardSize, "compactor.compactor-tenant-shard-size", 0, "Max number of compactors that can compact blocks for single tenant. 0 to disable the limit and use all compactors.")
@@ -272,6 +339,7 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	f.Var(&l.ResultsCacheTTLForLabelsQuery, "query-frontend.results-cache-ttl-for-labels-query", "Time to live duration for cached label names and label values query results. The value 0 disables the cache.")
 	f.BoolVar(&l.ResultsCacheForUnalignedQueryEnabled, "query-frontend.cache-unaligned-requests", false, "Cache requests that are not step-aligned.")
 	f.IntVar(&l.MaxQueryExpressionSizeBytes, maxQueryExpressionSizeBytesFlag, 0, "Max size of the raw query, in bytes. 0 to not apply a limit to the size of the query.")
+	f.BoolVar(&l.QueryPriorityHeaderEnabled, "query-frontend.query-priority-header-enabled", false, "Honor the X-Mimir-Query-Priority request header of the queries received by the query-frontend, which the ruler sets on rule evaluation queries. If false, the header is ignored and all queries have the normal priority. Only enable it for tenants whose query clients are trusted, because any query with the header set to 'high' is dispatched before the other queries and can use the querier capacity reserved to high priority queries.")
 
 	// Store-gateway.
 	f.IntVar(&l.StoreGatewayTenantShardSize, "store-gateway.tenant-shard-size", 0, "The tenant's shard size, used when store-gateway sharding is enabled. Value of 0 disables shuffle sharding for the tenant, that is all tenant blocks are sharded across all store-gateway replicas.")
@@ -359,6 +427,67 @@ func (l *Limits) validate() error {
 		return errors.New("invalid value for -" + MaxEstimatedChunksPerQueryMultiplierFlag + ": must be 0 or greater than or equal to 1")
 	}
 
+	if l.OTelDeltaTemporalityHandling != "" && !slices.Contains(otelDeltaTemporalityHandlings, l.OTelDeltaTemporalityHandling) {
+		return fmt.Errorf("invalid value for -%s: supported values are %s", OTelDeltaTemporalityHandlingFlag, strings.Join(otelDeltaTemporalityHandlings, ", "))
+	}
+
+	if l.OTelExponentialHistogramMaxScale < OTelExponentialHistogramMinScale || l.OTelExponentialHistogramMaxScale > OTelExponentialHistogramMaxScale {
+		return fmt.Errorf("invalid value for -%s: must be between %d and %d", OTelExponentialHistogramMaxScaleFlag, OTelExponentialHistogramMinScale, OTelExponentialHistogramMaxScale)
+	}
+
+	for _, name := range l.SeriesLimitOverflowDropLabels {
+		if name == model.MetricNameLabel || name == SeriesLimitOverflowLabel || !model.LabelName(name).IsValid() {
+			return fmt.Errorf("invalid value for -%s: %q is not a valid label name, or it can't be dropped", SeriesLimitOverflowDropLabelsFlag, name)
+		}
+	}
+
+	for _, rule := range l.StreamAggregationRules {
+		if err := rule.validate(); err != nil {
+			return err
+		}
+	}
+
+	if err := l.validateDownsampling(); err != nil {
+		return err
+	}
+
+	for _, r := range l.CompactorRetentionRules {
+		if err := r.validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, rl := range l.MetricIngestionRateLimits {
+		if err := rl.validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range l.CostAttributionLabels {
+		if !model.LabelName(name).IsValid() || slices.Contains(costAttributionReservedLabels, name) {
+			return fmt.Errorf("invalid value for -%s: %q is not a valid label name, or is one of the reserved label names %s", CostAttributionLabelsFlag, name, strings.Join(costAttributionReservedLabels, ", "))
+		}
+	}
+
+	return nil
+}
+
+// validateDownsampling checks that the blocks are downsampled before the blocks they're downsampled from are deleted
+// by the retention.
+func (l *Limits) validateDownsampling() error {
+	firstDelay, firstDelayFlag := l.CompactorDownsampling5mDelay, compactorDownsampling5mDelayFlag
+	if firstDelay <= 0 {
+		firstDelay, firstDelayFlag = l.CompactorDownsampling1hDelay, compactorDownsampling1hDelayFlag
+	}
+
+	if firstDelay > 0 && l.CompactorRawBlocksRetentionPeriod > 0 && l.CompactorRawBlocksRetentionPeriod <= firstDelay {
+		return fmt.Errorf("invalid value for -%s: must be greater than -%s", compactorRawBlocksRetentionPeriodFlag, firstDelayFlag)
+	}
+
+	if l.CompactorDownsampling5mDelay > 0 && l.CompactorDownsampling1hDelay > 0 && l.Compactor5mBlocksRetentionPeriod > 0 && l.Compactor5mBlocksRetentionPeriod <= l.CompactorDownsampling1hDelay {
+		return fmt.Errorf("invalid value for -%s: must be greater than -%s", compactor5mBlocksRetentionPeriodFlag, compactorDownsampling1hDelayFlag)
+	}
+
 	return nil
 }
 
@@ -440,6 +569,16 @@ func (o *Overrides) IngestionBurstSize(userID string) int {
 	return o.getOverridesForUser(userID).IngestionBurstSize
 }
 
+// StreamAggregationRules returns the stream aggregation rules evaluated by the distributors.
+func (o *Overrides) StreamAggregationRules(userID string) []*StreamAggregationRule {
+	return o.getOverridesForUser(userID).StreamAggregationRules
+}
+
+// MetricIngestionRateLimits returns the ingestion rate limits of the series matching a selector.
+func (o *Overrides) MetricIngestionRateLimits(userID string) []*MetricIngestionRateLimit {
+	return o.getOverridesForUser(userID).MetricIngestionRateLimits
+}
+
 // AcceptHASamples returns whether the distributor should track and accept samples from HA replicas for this user.
 func (o *Overrides) AcceptHASamples(userID string) bool {
 	return o.getOverridesForUser(userID).AcceptHASamples
@@ -493,6 +632,12 @@ func (o *Overrides) MaxNativeHistogramBuckets(userID string) int {
 	return o.getOverridesForUser(userID).MaxNativeHistogramBuckets
 }
 
+// ReduceNativeHistogramOverMaxBuckets returns whether to reduce the resolution
+// of native histogram samples exceeding the maximum number of buckets.
+func (o *Overrides) ReduceNativeHistogramOverMaxBuckets(userID string) bool {
+	return o.getOverridesForUser(userID).ReduceNativeHistogramOverMaxBuckets
+}
+
 // CreationGracePeriod is misnamed, and actually returns how far into the future
 // we should accept samples.
 func (o *Overrides) CreationGracePeriod(userID string) time.Duration {
@@ -509,6 +654,28 @@ func (o *Overrides) MaxGlobalSeriesPerMetric(userID string) int {
 	return o.getOverridesForUser(userID).MaxGlobalSeriesPerMetric
 }
 
+// SeriesLimitOverflowEnabled returns whether the samples of the series exceeding the series limits
+// are aggregated into overflow series instead of being discarded.
+func (o *Overrides) SeriesLimitOverflowEnabled(userID string) bool {
+	return o.getOverridesForUser(userID).SeriesLimitOverflowEnabled
+}
+
+// SeriesLimitOverflowDropLabels returns the labels dropped from the series aggregated into overflow series.
+func (o *Overrides) SeriesLimitOverflowDropLabels(userID string) []string {
+	return o.getOverridesForUser(userID).SeriesLimitOverflowDropLabels
+}
+
+// SeriesLimitOverflowMaxSeries returns the maximum number of overflow series per tenant in each ingester.
+func (o *Overrides) SeriesLimitOverflowMaxSeries(userID string) int {
+	return o.getOverridesForUser(userID).SeriesLimitOverflowMaxSeries
+}
+
+// SeriesLimitOverflowMaxAggregatedSeries returns the maximum number of series per tenant aggregated into the
+// overflow series of each ingester.
+func (o *Overrides) SeriesLimitOverflowMaxAggregatedSeries(userID string) int {
+	return o.getOverridesForUser(userID).SeriesLimitOverflowMaxAggregatedSeries
+}
+
 func (o *Overrides) MaxChunksPerQuery(userID string) int {
 	return o.getOverridesForUser(userID).MaxChunksPerQuery
 }
@@ -571,6 +738,31 @@ func (o *Overrides) MaxQueriersPerUser(userID string) int {
 	return o.getOverridesForUser(userID).MaxQueriersPerTenant
 }
 
+// OTelPromoteResourceAttributes returns the OTLP resource attributes to promote to series labels.
+func (o *Overrides) OTelPromoteResourceAttributes(userID string) []string {
+	return o.getOverridesForUser(userID).OTelPromoteResourceAttributes
+}
+
+// OTelMetricSuffixesEnabled returns whether to add the unit and type suffixes to the OTLP metric names.
+func (o *Overrides) OTelMetricSuffixesEnabled(userID string) bool {
+	return o.getOverridesForUser(userID).OTelMetricSuffixesEnabled
+}
+
+// OTelDeltaTemporalityHandling returns how to handle the OTLP metrics with delta aggregation temporality.
+func (o *Overrides) OTelDeltaTemporalityHandling(userID string) string {
+	return o.getOverridesForUser(userID).OTelDeltaTemporalityHandling
+}
+
+// OTelExponentialHistogramMaxScale returns the maximum scale of the OTLP exponential histograms, above which they're downscaled.
+func (o *Overrides) OTelExponentialHistogramMaxScale(userID string) int32 {
+	return int32(o.getOverridesForUser(userID).OTelExponentialHistogramMaxScale)
+}
+
+// QuerySchedulerTenantWeight returns the weight of the tenant used by the query-scheduler cost-based fair queuing.
+func (o *Overrides) QuerySchedulerTenantWeight(userID string) float64 {
+	return o.getOverridesForUser(userID).QuerySchedulerTenantWeight
+}
+
 // MaxQueryParallelism returns the limit to the number of split queries the
 // frontend will process in parallel.
 func (o *Overrides) MaxQueryParallelism(userID string) int {
@@ -642,6 +834,16 @@ func (o *Overrides) OutOfOrderBlocksExternalLabelEnabled(userID string) bool {
 	return o.getOverridesForUser(userID).OutOfOrderBlocksExternalLabelEnabled
 }
 
+// CostAttributionLabels returns the labels by which the series of the tenant are attributed.
+func (o *Overrides) CostAttributionLabels(userID string) []string {
+	return o.getOverridesForUser(userID).CostAttributionLabels
+}
+
+// MaxCostAttributionCardinalityPerUser returns the maximum number of cost attribution label values combinations tracked for the tenant.
+func (o *Overrides) MaxCostAttributionCardinalityPerUser(userID string) int {
+	return o.getOverridesForUser(userID).MaxCostAttributionCardinalityPerUser
+}
+
 // SeparateMetricsGroupLabel returns the custom label used to separate specific metrics
 func (o *Overrides) SeparateMetricsGroupLabel(userID string) string {
 	return o.getOverridesForUser(userID).SeparateMetricsGroupLabel
@@ -667,6 +869,31 @@ func (o *Overrides) CompactorBlocksRetentionPeriod(userID string) time.Duration
 	return time.Duration(o.getOverridesForUser(userID).CompactorBlocksRetentionPeriod)
 }
 
+// CompactorRetentionRules returns the retention periods of the series matching a selector for a given user.
+func (o *Overrides) CompactorRetentionRules(userID string) []*CompactorRetentionRule {
+	return o.getOverridesForUser(userID).CompactorRetentionRules
+}
+
+// CompactorRawBlocksRetentionPeriod returns the retention period of the raw blocks for a given user.
+func (o *Overrides) CompactorRawBlocksRetentionPeriod(userID string) time.Duration {
+	return time.Duration(o.getOverridesForUser(userID).CompactorRawBlocksRetentionPeriod)
+}
+
+// Compactor5mBlocksRetentionPeriod returns the retention period of the blocks downsampled to 5 minutes for a given user.
+func (o *Overrides) Compactor5mBlocksRetentionPeriod(userID string) time.Duration {
+	return time.Duration(o.getOverridesForUser(userID).Compactor5mBlocksRetentionPeriod)
+}
+
+// CompactorDownsampling5mDelay returns the minimum age of the blocks downsampled to 5 minutes for a given user.
+func (o *Overrides) CompactorDownsampling5mDelay(userID string) time.Duration {
+	return time.Duration(o.getOverridesForUser(userID).CompactorDownsampling5mDelay)
+}
+
+// CompactorDownsampling1hDelay returns the minimum age of the blocks downsampled to 1 hour for a given user.
+func (o *Overrides) CompactorDownsampling1hDelay(userID string) time.Duration {
+	return time.Duration(o.getOverridesForUser(userID).CompactorDownsampling1hDelay)
+}
+
 // CompactorSplitAndMergeShards returns the number of shards to use when splitting blocks.
 func (o *Overrides) CompactorSplitAndMergeShards(userID string) int {
 	return o.getOverridesForUser(userID).CompactorSplitAndMergeShards
@@ -880,6 +1107,11 @@ func (o *Overrides) ResultsCacheForUnalignedQueryEnabled(userID string) bool {
 	return o.getOverridesForUser(userID).ResultsCacheForUnalignedQueryEnabled
 }
 
+// QueryPriorityHeaderEnabled returns whether the query-frontend honors the query priority request header for a given tenant.
+func (o *Overrides) QueryPriorityHeaderEnabled(userID string) bool {
+	return o.getOverridesForUser(userID).QueryPriorityHeaderEnabled
+}
+
 func (o *Overrides) getOverridesForUser(userID string) *Limits {
 	if o.tenantLimits != nil {
 		l := o.tenantLimits.ByUserID(userID)
@@ -934,6 +1166,23 @@ func SmallestPositiveNonZeroIntPerTenant(tenantIDs []string, f func(string) int)
 	return *result
 }
 
+// SmallestPositiveNonZeroFloat64PerTenant is returning the minimal positive and
+// non-zero value of the supplied limit function for all given tenants. The method
+// will return 0 only if all inputs have a limit of 0 or an empty tenant list is given.
+func SmallestPositiveNonZeroFloat64PerTenant(tenantIDs []string, f func(string) float64) float64 {
+	var result *float64
+	for _, tenantID := range tenantIDs {
+		v := f(tenantID)
+		if v > 0 && (result == nil || v < *result) {
+			result = &v
+		}
+	}
+	if result == nil {
+		return 0
+	}
+	return *result
+}
+
 // SmallestPositiveNonZeroDurationPerTenant is returning the minimal positive
 // and non-zero value of the supplied limit function for all given tenants. In
 // many limits a value of 0 means unlimited so the method will return 0 only if
@@ -234,6 +234,37 @@ func TestSmallestPositiveNonZeroIntPerTenant(t *testing.T) {
 	}
 }
 
+func TestSmallestPositiveNonZeroFloat64PerTenant(t *testing.T) {
+	tenantLimits := map[string]*Limits{
+		"tenant-a": {
+			QuerySchedulerTenantWeight: 0.5,
+		},
+		"tenant-b": {
+			QuerySchedulerTenantWeight: 2,
+		},
+	}
+
+	defaults := Limits{
+		QuerySchedulerTenantWeight: 0,
+	}
+	ov, err := NewOverrides(defaults, NewMockTenantLimits(tenantLimits))
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		tenantIDs []string
+		expLimit  float64
+	}{
+		{tenantIDs: []string{}, expLimit: 0},
+		{tenantIDs: []string{"tenant-a"}, expLimit: 0.5},
+		{tenantIDs: []string{"tenant-b"}, expLimit: 2},
+		{tenantIDs: []string{"tenant-c"}, expLimit: 0},
+		{tenantIDs: []string{"tenant-a", "tenant-b"}, expLimit: 0.5},
+		{tenantIDs: []string{"tenant-b", "tenant-c"}, expLimit: 2},
+	} {
+		assert.Equal(t, tc.expLimit, SmallestPositiveNonZeroFloat64PerTenant(tc.tenantIDs, ov.QuerySchedulerTenantWeight))
+	}
+}
+
 func TestSmallestPositiveNonZeroDurationPerTenant(t *testing.T) {
 	tenantLimits := map[string]*Limits{
 		"tenant-a": {
@@ -675,6 +706,253 @@ func TestUnmarshalMaxEstimatedChunksPerQuery(t *testing.T) {
 	}
 }
 
+func TestUnmarshalMetricIngestionRateLimits(t *testing.T) {
+	testCases := map[string]struct {
+		cfg         string
+		expectedErr string
+	}{
+		"valid": {
+			cfg: `
+metric_ingestion_rate_limits:
+  - selector: up
+    rate: 10
+    burst: 100
+  - selector: '{__name__=~"http_.+", job="api"}'
+    rate: 0.5
+    burst: 1`,
+		},
+		"invalid selector": {
+			cfg: `
+metric_ingestion_rate_limits:
+  - selector: '{job='
+    rate: 10
+    burst: 100`,
+			expectedErr: `invalid metric_ingestion_rate_limits selector "{job="`,
+		},
+		"missing rate": {
+			cfg: `
+metric_ingestion_rate_limits:
+  - selector: up
+    burst: 100`,
+			expectedErr: `invalid metric_ingestion_rate_limits entry for selector "up": rate and burst must be greater than 0`,
+		},
+		"missing burst": {
+			cfg: `
+metric_ingestion_rate_limits:
+  - selector: up
+    rate: 10`,
+			expectedErr: `invalid metric_ingestion_rate_limits entry for selector "up": rate and burst must be greater than 0`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			limits := Limits{}
+			err := yaml.Unmarshal([]byte(tc.cfg), &limits)
+
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+				require.Len(t, limits.MetricIngestionRateLimits, 2)
+				assert.Equal(t, MetricIngestionRateLimit{Selector: "up", Rate: 10, Burst: 100}, *limits.MetricIngestionRateLimits[0])
+			} else {
+				require.ErrorContains(t, err, tc.expectedErr)
+			}
+		})
+	}
+}
+
+func TestUnmarshalStreamAggregationRules(t *testing.T) {
+	testCases := map[string]struct {
+		cfg         string
+		expectedErr string
+	}{
+		"valid": {
+			cfg: `
+stream_aggregation_rules:
+  - match: http_requests_total
+    interval: 1m
+    without: [pod]
+    outputs: [sum, count]
+    drop_input: true
+  - match: '{job="api"}'
+    interval: 30s
+    by: [job]
+    outputs: [count_samples]`,
+		},
+		"invalid match": {
+			cfg: `
+stream_aggregation_rules:
+  - match: '{job='
+    interval: 1m
+    outputs: [sum]`,
+			expectedErr: `invalid stream_aggregation_rules match "{job="`,
+		},
+		"missing interval": {
+			cfg: `
+stream_aggregation_rules:
+  - match: up
+    outputs: [sum]`,
+			expectedErr: `invalid stream_aggregation_rules entry for match "up": the interval must be greater than 0`,
+		},
+		"both by and without": {
+			cfg: `
+stream_aggregation_rules:
+  - match: up
+    interval: 1m
+    by: [job]
+    without: [pod]
+    outputs: [sum]`,
+			expectedErr: `invalid stream_aggregation_rules entry for match "up": only one of by and without can be set`,
+		},
+		"metric name removed": {
+			cfg: `
+stream_aggregation_rules:
+  - match: up
+    interval: 1m
+    without: [__name__]
+    outputs: [sum]`,
+			expectedErr: `invalid stream_aggregation_rules entry for match "up": "__name__" is not a valid label name to group by or remove`,
+		},
+		"missing outputs": {
+			cfg: `
+stream_aggregation_rules:
+  - match: up
+    interval: 1m`,
+			expectedErr: `invalid stream_aggregation_rules entry for match "up": at least one output must be set`,
+		},
+		"unsupported output": {
+			cfg: `
+stream_aggregation_rules:
+  - match: up
+    interval: 1m
+    outputs: [avg]`,
+			expectedErr: `invalid stream_aggregation_rules entry for match "up": unsupported output "avg"`,
+		},
+		"duplicate output": {
+			cfg: `
+stream_aggregation_rules:
+  - match: up
+    interval: 1m
+    outputs: [sum, sum]`,
+			expectedErr: `invalid stream_aggregation_rules entry for match "up": duplicate output "sum"`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			limits := Limits{}
+			err := yaml.Unmarshal([]byte(tc.cfg), &limits)
+
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+				require.Len(t, limits.StreamAggregationRules, 2)
+				assert.Equal(t, StreamAggregationRule{
+					Match:     "http_requests_total",
+					Interval:  model.Duration(time.Minute),
+					Without:   []string{"pod"},
+					Outputs:   []string{"sum", "count"},
+					DropInput: true,
+				}, *limits.StreamAggregationRules[0])
+			} else {
+				require.ErrorContains(t, err, tc.expectedErr)
+			}
+		})
+	}
+}
+
+func TestUnmarshalDownsampling(t *testing.T) {
+	testCases := map[string]struct {
+		cfg         string
+		expectedErr string
+	}{
+		"disabled": {
+			cfg: `compactor_raw_blocks_retention_period: 1h`,
+		},
+		"raw blocks retention greater than the 5m delay": {
+			cfg: `
+compactor_downsampling_5m_delay: 2d
+compactor_raw_blocks_retention_period: 3d`,
+		},
+		"raw blocks retention not greater than the 5m delay": {
+			cfg: `
+compactor_downsampling_5m_delay: 2d
+compactor_raw_blocks_retention_period: 2d`,
+			expectedErr: "invalid value for -compactor.raw-blocks-retention-period: must be greater than -compactor.downsampling-5m-delay",
+		},
+		"raw blocks retention not greater than the 1h delay": {
+			cfg: `
+compactor_downsampling_1h_delay: 10d
+compactor_raw_blocks_retention_period: 3d`,
+			expectedErr: "invalid value for -compactor.raw-blocks-retention-period: must be greater than -compactor.downsampling-1h-delay",
+		},
+		"5m blocks retention greater than the 1h delay": {
+			cfg: `
+compactor_downsampling_5m_delay: 2d
+compactor_downsampling_1h_delay: 10d
+compactor_raw_blocks_retention_period: 30d
+compactor_5m_blocks_retention_period: 90d`,
+		},
+		"5m blocks retention not greater than the 1h delay": {
+			cfg: `
+compactor_downsampling_5m_delay: 2d
+compactor_downsampling_1h_delay: 10d
+compactor_5m_blocks_retention_period: 5d`,
+			expectedErr: "invalid value for -compactor.5m-blocks-retention-period: must be greater than -compactor.downsampling-1h-delay",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			limits := Limits{}
+			err := yaml.Unmarshal([]byte(tc.cfg), &limits)
+
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tc.expectedErr)
+			}
+		})
+	}
+}
+
+func TestUnmarshalCompactorRetentionRules(t *testing.T) {
+	testCases := map[string]struct {
+		cfg         string
+		expectedErr string
+	}{
+		"valid": {
+			cfg: `
+compactor_retention_rules:
+  - selector: '{__name__=~"debug_.*"}'
+    period: 7d
+  - selector: '{env="prod"}'
+    period: 400d`,
+		},
+		"invalid selector": {
+			cfg: `
+compactor_retention_rules:
+  - selector: '{env='
+    period: 7d`,
+			expectedErr: `invalid compactor_retention_rules selector "{env="`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			limits := Limits{}
+			err := yaml.Unmarshal([]byte(tc.cfg), &limits)
+
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+				require.Len(t, limits.CompactorRetentionRules, 2)
+				assert.Equal(t, CompactorRetentionRule{Selector: `{__name__=~"debug_.*"}`, Period: model.Duration(7 * 24 * time.Hour)}, *limits.CompactorRetentionRules[0])
+			} else {
+				require.ErrorContains(t, err, tc.expectedErr)
+			}
+		})
+	}
+}
+
 type structExtension struct {
 	Foo int `yaml:"foo" json:"foo"`
 }
@@ -0,0 +1,30 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package validation
+
+import (
+	"fmt"
+
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// MetricIngestionRateLimit is the ingestion rate limit of the series matching a selector.
+type MetricIngestionRateLimit struct {
+	// Selector is a series selector, like a metric name or {__name__="up",job="node"}.
+	Selector string  `yaml:"selector" json:"selector"`
+	Rate     float64 `yaml:"rate" json:"rate"`
+	Burst    int     `yaml:"burst" json:"burst"`
+}
+
+func (l *MetricIngestionRateLimit) validate() error {
+	if l == nil {
+		return fmt.Errorf("invalid metric_ingestion_rate_limits: empty entry")
+	}
+	if _, err := parser.ParseMetricSelector(l.Selector); err != nil {
+		return fmt.Errorf("invalid metric_ingestion_rate_limits selector %q: %w", l.Selector, err)
+	}
+	if l.Rate <= 0 || l.Burst <= 0 {
+		return fmt.Errorf("invalid metric_ingestion_rate_limits entry for selector %q: rate and burst must be greater than 0", l.Selector)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql/parser"
+	"golang.org/x/exp/slices"
+)
+
+// Supported outputs of the stream aggregation rules.
+const (
+	StreamAggregationOutputSum          = "sum"
+	StreamAggregationOutputCount        = "count"
+	StreamAggregationOutputMin          = "min"
+	StreamAggregationOutputMax          = "max"
+	StreamAggregationOutputSumSamples   = "sum_samples"
+	StreamAggregationOutputCountSamples = "count_samples"
+)
+
+var streamAggregationOutputs = []string{
+	StreamAggregationOutputSum,
+	StreamAggregationOutputCount,
+	StreamAggregationOutputMin,
+	StreamAggregationOutputMax,
+	StreamAggregationOutputSumSamples,
+	StreamAggregationOutputCountSamples,
+}
+
+// StreamAggregationRule aggregates the float samples of the series matching a selector, received over an interval,
+// into output series.
+type StreamAggregationRule struct {
+	// Match is a series selector, like a metric name or {__name__="up",job="node"}.
+	Match    string         `yaml:"match" json:"match"`
+	Interval model.Duration `yaml:"interval" json:"interval"`
+	// By and Without are the labels to group the series by, or to remove from the series, like in PromQL aggregations.
+	By        []string `yaml:"by,omitempty" json:"by,omitempty"`
+	Without   []string `yaml:"without,omitempty" json:"without,omitempty"`
+	Outputs   []string `yaml:"outputs" json:"outputs"`
+	DropInput bool     `yaml:"drop_input" json:"drop_input"`
+}
+
+func (r *StreamAggregationRule) validate() error {
+	if r == nil {
+		return fmt.Errorf("invalid stream_aggregation_rules: empty entry")
+	}
+	if _, err := parser.ParseMetricSelector(r.Match); err != nil {
+		return fmt.Errorf("invalid stream_aggregation_rules match %q: %w", r.Match, err)
+	}
+	if r.Interval <= 0 {
+		return fmt.Errorf("invalid stream_aggregation_rules entry for match %q: the interval must be greater than 0", r.Match)
+	}
+	if len(r.By) > 0 && len(r.Without) > 0 {
+		return fmt.Errorf("invalid stream_aggregation_rules entry for match %q: only one of by and without can be set", r.Match)
+	}
+	for _, name := range append(slices.Clone(r.By), r.Without...) {
+		if name == model.MetricNameLabel || !model.LabelName(name).IsValid() {
+			return fmt.Errorf("invalid stream_aggregation_rules entry for match %q: %q is not a valid label name to group by or remove", r.Match, name)
+		}
+	}
+	if len(r.Outputs) == 0 {
+		return fmt.Errorf("invalid stream_aggregation_rules entry for match %q: at least one output must be set", r.Match)
+	}
+	for i, output := range r.Outputs {
+		if !slices.Contains(streamAggregationOutputs, output) {
+			return fmt.Errorf("invalid stream_aggregation_rules entry for match %q: unsupported output %q, supported outputs are %s", r.Match, output, strings.Join(streamAggregationOutputs, ", "))
+		}
+		if slices.Contains(r.Outputs[:i], output) {
+			return fmt.Errorf("invalid stream_aggregation_rules entry for match %q: duplicate output %q", r.Match, output)
+		}
+	}
+	return nil
+}
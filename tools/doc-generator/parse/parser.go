@@ -348,6 +348,12 @@ func getFieldCustomType(t reflect.Type) (string, bool) {
 		return "relabel_config...", true
 	case reflect.TypeOf([]*validation.BlockedQuery{}).String():
 		return "blocked_queries_config...", true
+	case reflect.TypeOf([]*validation.MetricIngestionRateLimit{}).String():
+		return "metric_ingestion_rate_limits_config...", true
+	case reflect.TypeOf([]*validation.CompactorRetentionRule{}).String():
+		return "compactor_retention_rules_config...", true
+	case reflect.TypeOf([]*validation.StreamAggregationRule{}).String():
+		return "stream_aggregation_rules_config...", true
 	case reflect.TypeOf(activeseries.CustomTrackersConfig{}).String():
 		return "map of tracker name (string) to matcher (string)", true
 	default:
@@ -430,6 +436,12 @@ func getCustomFieldType(t reflect.Type) (string, bool) {
 		return "relabel_config...", true
 	case reflect.TypeOf([]*validation.BlockedQuery{}).String():
 		return "blocked_queries_config...", true
+	case reflect.TypeOf([]*validation.MetricIngestionRateLimit{}).String():
+		return "metric_ingestion_rate_limits_config...", true
+	case reflect.TypeOf([]*validation.CompactorRetentionRule{}).String():
+		return "compactor_retention_rules_config...", true
+	case reflect.TypeOf([]*validation.StreamAggregationRule{}).String():
+		return "stream_aggregation_rules_config...", true
 	case reflect.TypeOf(activeseries.CustomTrackersConfig{}).String():
 		return "map of tracker name (string) to matcher (string)", true
 	default:
@@ -463,6 +475,12 @@ func ReflectType(typ string) reflect.Type {
 		return reflect.TypeOf([]*relabel.Config{})
 	case "blocked_queries_config...":
 		return reflect.TypeOf([]*validation.BlockedQuery{})
+	case "metric_ingestion_rate_limits_config...":
+		return reflect.TypeOf([]*validation.MetricIngestionRateLimit{})
+	case "compactor_retention_rules_config...":
+		return reflect.TypeOf([]*validation.CompactorRetentionRule{})
+	case "stream_aggregation_rules_config...":
+		return reflect.TypeOf([]*validation.StreamAggregationRule{})
 	case "map of string to float64":
 		return reflect.TypeOf(map[string]float64{})
 	case "list of durations":